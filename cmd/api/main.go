@@ -69,6 +69,8 @@ func main() {
 	}
 	defer infraDeps.Close()
 
+	server.StartWorkers(ctx, cfg, infraDeps, log)
+
 	httpServer := &http.Server{
 		Addr:              cfg.HTTPAddr,
 		Handler:           server.NewRouter(cfg, infraDeps, log),
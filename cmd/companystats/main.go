@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"sarbonNew/internal/companies"
+	"sarbonNew/internal/config"
+)
+
+// Пересобирает журнал company_order_stats по всем рейсам и пересчитывает
+// companies.completed_orders, cancelled_orders, total_revenue.
+func main() {
+	config.LoadDotEnvUp(8)
+
+	timeout := flag.Duration("timeout", 5*time.Minute, "backfill timeout")
+	flag.Parse()
+
+	dbURL := strings.TrimSpace(os.Getenv("DATABASE_URL"))
+	if dbURL == "" {
+		fmt.Fprintln(os.Stderr, "DATABASE_URL is required")
+		os.Exit(2)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	pool, err := pgxpool.New(ctx, dbURL)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "db connect failed:", err)
+		os.Exit(1)
+	}
+	defer pool.Close()
+
+	n, err := companies.NewRepo(pool).BackfillOrderStats(ctx)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "backfill failed:", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("company order stats rows:", n)
+}
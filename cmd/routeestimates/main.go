@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"sarbonNew/internal/cargo"
+	"sarbonNew/internal/config"
+	"sarbonNew/internal/routing"
+)
+
+// Досчитывает cargo.distance_km и duration_minutes для грузов, созданных до оценки маршрута
+// (новые грузы получают оценку при создании и изменении).
+func main() {
+	config.LoadDotEnvUp(8)
+
+	timeout := flag.Duration("timeout", 5*time.Minute, "backfill timeout")
+	flag.Parse()
+
+	cfg, err := config.LoadFromEnv()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "config:", err)
+		os.Exit(2)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	pool, err := pgxpool.New(ctx, cfg.DatabaseURL)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "db connect failed:", err)
+		os.Exit(1)
+	}
+	defer pool.Close()
+
+	routes := routing.NewEstimator(routing.Config{
+		RoadFactor:        cfg.RouteRoadFactor,
+		DefaultSpeedKmh:   cfg.RouteDefaultSpeedKmh,
+		TruckSpeeds:       cfg.RouteTruckSpeeds,
+		CustomsDelay:      cfg.RouteCustomsDelay,
+		DailyDrivingLimit: cfg.RouteDailyDrivingLimit,
+		DailyRest:         cfg.RouteDailyRest,
+	})
+	repo := cargo.NewRepo(pool)
+	ids, err := repo.IDsWithoutRouteEstimate(ctx)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "list cargo failed:", err)
+		os.Exit(1)
+	}
+	n := 0
+	for _, id := range ids {
+		obj, err := repo.GetByID(ctx, id, false)
+		if err != nil || obj == nil {
+			fmt.Fprintln(os.Stderr, "cargo", id, "skipped:", err)
+			continue
+		}
+		points, err := repo.GetRoutePoints(ctx, id)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "cargo", id, "route points:", err)
+			continue
+		}
+		rps := make([]routing.Point, 0, len(points))
+		for _, rp := range points {
+			rps = append(rps, routing.Point{Lat: rp.Lat, Lng: rp.Lng, Type: rp.Type})
+		}
+		est := routes.Estimate(obj.TruckType, rps)
+		if err := repo.SetRouteEstimate(ctx, id, est.DistanceKm, int(est.Total().Minutes())); err != nil {
+			fmt.Fprintln(os.Stderr, "cargo", id, "save estimate:", err)
+			continue
+		}
+		n++
+	}
+
+	fmt.Println("cargo route estimates:", n, "of", len(ids))
+}
@@ -0,0 +1,294 @@
+package cargo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Статусы аукциона.
+const (
+	AuctionOpen      = "OPEN"
+	AuctionClosed    = "CLOSED"    // торги завершены, победителя выбирает владелец (MANUAL или авто-принятие не удалось)
+	AuctionAwarded   = "AWARDED"   // оффер победителя принят, создан рейс
+	AuctionNoWinner  = "NO_WINNER" // нет ставок в пределах резервной цены
+	AuctionCancelled = "CANCELLED"
+)
+
+// Выбор победителя.
+const (
+	WinnerAuto   = "AUTO"
+	WinnerManual = "MANUAL"
+)
+
+var (
+	ErrAuctionExists    = errors.New("cargo: auction already exists")
+	ErrAuctionNotOpen   = errors.New("cargo: auction is not open")
+	ErrBidCurrency      = errors.New("cargo: bid currency differs from auction currency")
+	ErrBidNotLower      = errors.New("cargo: bid must be lower than your previous bid")
+	ErrAuctionNotClosed = errors.New("cargo: auction is not closed")
+)
+
+// Auction — обратный аукцион по грузу (table cargo_auctions). Ставки — офферы груза; выигрывает минимальная цена.
+type Auction struct {
+	ID               uuid.UUID
+	CargoID          uuid.UUID
+	Status           string
+	Currency         string
+	ReservePrice     *float64
+	Sealed           bool
+	AntiSnipeMinutes int
+	WinnerSelection  string
+	StartsAt         time.Time
+	EndsAt           time.Time
+	OriginalEndsAt   time.Time
+	ExtensionsCount  int
+	WinnerOfferID    *uuid.UUID
+	ClosedAt         *time.Time
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// AuctionInput — параметры запуска аукциона.
+type AuctionInput struct {
+	Currency         string
+	ReservePrice     *float64
+	Sealed           bool
+	AntiSnipeMinutes int
+	WinnerSelection  string
+	EndsAt           time.Time
+}
+
+// AuctionSummary — состояние торгов: число ставок и лучшая ставка (только в валюте аукциона).
+type AuctionSummary struct {
+	BidsCount  int
+	BestBid    *float64
+	BestOffer  *uuid.UUID
+	ReserveMet bool
+}
+
+const auctionColumns = `id, cargo_id, status, currency, reserve_price, sealed, anti_snipe_minutes, winner_selection,
+  starts_at, ends_at, original_ends_at, extensions_count, winner_offer_id, closed_at, created_at, updated_at`
+
+func scanAuction(row pgx.Row) (*Auction, error) {
+	var a Auction
+	err := row.Scan(&a.ID, &a.CargoID, &a.Status, &a.Currency, &a.ReservePrice, &a.Sealed, &a.AntiSnipeMinutes, &a.WinnerSelection,
+		&a.StartsAt, &a.EndsAt, &a.OriginalEndsAt, &a.ExtensionsCount, &a.WinnerOfferID, &a.ClosedAt, &a.CreatedAt, &a.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &a, nil
+}
+
+// IsOpen — аукцион принимает ставки.
+func (a *Auction) IsOpen() bool {
+	return a != nil && a.Status == AuctionOpen
+}
+
+// SnipeDeadline — новый срок при ставке в момент now: ставка в последние anti_snipe_minutes продлевает аукцион
+// до now + anti_snipe_minutes; ok = false — продлевать не нужно.
+func (a *Auction) SnipeDeadline(now time.Time) (time.Time, bool) {
+	window := time.Duration(a.AntiSnipeMinutes) * time.Minute
+	if window <= 0 || a.EndsAt.Sub(now) >= window {
+		return time.Time{}, false
+	}
+	return now.Add(window), true
+}
+
+// ReserveMet — ставка price проходит резервную цену (без резерва — любая).
+func (a *Auction) ReserveMet(price float64) bool {
+	return a.ReservePrice == nil || price <= *a.ReservePrice
+}
+
+// StartAuction запускает аукцион по грузу. Отменённый аукцион можно запустить заново.
+func (r *Repo) StartAuction(ctx context.Context, cargoID uuid.UUID, in AuctionInput) (*Auction, error) {
+	a, err := scanAuction(r.pg.QueryRow(ctx, `
+INSERT INTO cargo_auctions (cargo_id, currency, reserve_price, sealed, anti_snipe_minutes, winner_selection, ends_at, original_ends_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+ON CONFLICT (cargo_id) DO UPDATE
+SET status = 'OPEN', currency = EXCLUDED.currency, reserve_price = EXCLUDED.reserve_price, sealed = EXCLUDED.sealed,
+    anti_snipe_minutes = EXCLUDED.anti_snipe_minutes, winner_selection = EXCLUDED.winner_selection,
+    starts_at = now(), ends_at = EXCLUDED.ends_at, original_ends_at = EXCLUDED.ends_at, extensions_count = 0,
+    winner_offer_id = NULL, closed_at = NULL, updated_at = now()
+WHERE cargo_auctions.status = 'CANCELLED'
+RETURNING `+auctionColumns,
+		cargoID, in.Currency, in.ReservePrice, in.Sealed, in.AntiSnipeMinutes, in.WinnerSelection, in.EndsAt.UTC()))
+	if err == nil && a == nil {
+		return nil, ErrAuctionExists
+	}
+	return a, err
+}
+
+// GetAuction returns the auction of a cargo (nil if cargo has none).
+func (r *Repo) GetAuction(ctx context.Context, cargoID uuid.UUID) (*Auction, error) {
+	return scanAuction(r.pg.QueryRow(ctx, `SELECT `+auctionColumns+` FROM cargo_auctions WHERE cargo_id = $1`, cargoID))
+}
+
+// CancelAuction отменяет открытый аукцион; груз возвращается к обычному приёму офферов.
+func (r *Repo) CancelAuction(ctx context.Context, cargoID uuid.UUID) error {
+	tag, err := r.pg.Exec(ctx,
+		`UPDATE cargo_auctions SET status = 'CANCELLED', closed_at = now(), updated_at = now() WHERE cargo_id = $1 AND status = 'OPEN'`, cargoID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrAuctionNotOpen
+	}
+	return nil
+}
+
+// AuctionSummary считает действующие ставки в валюте аукциона.
+func (r *Repo) AuctionSummary(ctx context.Context, a *Auction) (AuctionSummary, error) {
+	var s AuctionSummary
+	err := r.pg.QueryRow(ctx, `
+SELECT COUNT(*), MIN(price),
+       (ARRAY_AGG(id ORDER BY price, updated_at NULLS FIRST, created_at))[1]
+FROM offers
+WHERE cargo_id = $1 AND status = 'PENDING' AND currency = $2 AND (expires_at IS NULL OR expires_at > now())`,
+		a.CargoID, a.Currency).Scan(&s.BidsCount, &s.BestBid, &s.BestOffer)
+	if err != nil {
+		return s, err
+	}
+	s.ReserveMet = s.BestBid != nil && a.ReserveMet(*s.BestBid)
+	return s, nil
+}
+
+// PlaceBid — ставка водителя: создаёт оффер или снижает цену своего PENDING-оффера (новый раунд торга);
+// PENDING-оффер в другой валюте переводится в валюту аукциона без проверки снижения.
+// Ставка в последние anti_snipe_minutes продлевает аукцион до now() + anti_snipe_minutes (время БД).
+func (r *Repo) PlaceBid(ctx context.Context, cargoID, carrierID uuid.UUID, price float64, currency, comment string) (*Offer, *Auction, error) {
+	tx, err := r.pg.Begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback(ctx)
+	a, err := scanAuction(tx.QueryRow(ctx, `SELECT `+auctionColumns+` FROM cargo_auctions WHERE cargo_id = $1 FOR UPDATE`, cargoID))
+	if err != nil {
+		return nil, nil, err
+	}
+	// срок и продление считаются по часам БД, как в DueAuctions и CloseAuction
+	var now time.Time
+	if err := tx.QueryRow(ctx, `SELECT now()::timestamp`).Scan(&now); err != nil {
+		return nil, nil, err
+	}
+	if !a.IsOpen() || !now.Before(a.EndsAt) {
+		return nil, nil, ErrAuctionNotOpen
+	}
+	if currency != a.Currency {
+		return nil, nil, ErrBidCurrency
+	}
+	var offerID uuid.UUID
+	var prev float64
+	var prevCurrency string
+	var rounds int
+	err = tx.QueryRow(ctx, `
+SELECT id, price, currency, rounds_count FROM offers
+WHERE cargo_id = $1 AND carrier_id = $2 AND status = 'PENDING'
+ORDER BY created_at DESC LIMIT 1 FOR UPDATE`, cargoID, carrierID).Scan(&offerID, &prev, &prevCurrency, &rounds)
+	switch {
+	case err == pgx.ErrNoRows:
+		err = tx.QueryRow(ctx, `
+INSERT INTO offers (cargo_id, carrier_id, price, currency, comment, status, rounds_count, last_round_by, created_at)
+VALUES ($1, $2, $3, $4, $5, 'PENDING', 1, 'DRIVER', now()) RETURNING id`,
+			cargoID, carrierID, price, currency, nullStr(comment)).Scan(&offerID)
+		rounds = 0
+	case err != nil:
+		return nil, nil, err
+	case prevCurrency == currency && price >= prev:
+		return nil, nil, ErrBidNotLower
+	default:
+		// оффер до аукциона в другой валюте не сравнивается с новой ставкой — она становится первой в валюте аукциона
+		_, err = tx.Exec(ctx, `
+UPDATE offers SET price = $2, currency = $3, comment = COALESCE($4, comment), rounds_count = $5, updated_at = now() WHERE id = $1`,
+			offerID, price, currency, nullStr(comment), rounds+1)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	_, err = tx.Exec(ctx, `
+INSERT INTO offer_rounds (offer_id, round_no, author_type, author_id, price, currency, comment)
+VALUES ($1, $2, 'DRIVER', $3, $4, $5, $6)`, offerID, rounds+1, carrierID, price, currency, nullStr(comment))
+	if err != nil {
+		return nil, nil, err
+	}
+	if endsAt, ok := a.SnipeDeadline(now); ok {
+		a, err = scanAuction(tx.QueryRow(ctx, `
+UPDATE cargo_auctions SET ends_at = $2, extensions_count = extensions_count + 1, updated_at = now()
+WHERE id = $1 RETURNING `+auctionColumns, a.ID, endsAt))
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, err
+	}
+	offer, err := r.GetOfferByID(ctx, offerID)
+	return offer, a, err
+}
+
+// DueAuctions returns cargo ids of open auctions whose deadline has passed.
+func (r *Repo) DueAuctions(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := r.pg.Query(ctx, `SELECT cargo_id FROM cargo_auctions WHERE status = 'OPEN' AND ends_at <= now() ORDER BY ends_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// CloseAuction завершает торги по истечении срока: CLOSED, если есть ставка в пределах резервной цены, иначе NO_WINNER.
+// Возвращает аукцион и лучшую ставку (nil — победителя нет). Если аукцион уже закрыт — (nil, nil, nil).
+func (r *Repo) CloseAuction(ctx context.Context, cargoID uuid.UUID) (*Auction, *uuid.UUID, error) {
+	tx, err := r.pg.Begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback(ctx)
+	a, err := scanAuction(tx.QueryRow(ctx, `
+SELECT `+auctionColumns+` FROM cargo_auctions
+WHERE cargo_id = $1 AND status = 'OPEN' AND ends_at <= now() FOR UPDATE SKIP LOCKED`, cargoID))
+	if err != nil || a == nil {
+		return nil, nil, err
+	}
+	var best *uuid.UUID
+	err = tx.QueryRow(ctx, `
+SELECT id FROM offers
+WHERE cargo_id = $1 AND status = 'PENDING' AND currency = $2 AND (expires_at IS NULL OR expires_at > now())
+  AND ($3::float8 IS NULL OR price <= $3)
+ORDER BY price, updated_at NULLS FIRST, created_at LIMIT 1`, cargoID, a.Currency, a.ReservePrice).Scan(&best)
+	if err != nil && err != pgx.ErrNoRows {
+		return nil, nil, err
+	}
+	status := AuctionClosed
+	if best == nil {
+		status = AuctionNoWinner
+	}
+	a, err = scanAuction(tx.QueryRow(ctx, `
+UPDATE cargo_auctions SET status = $2, closed_at = now(), updated_at = now() WHERE id = $1 RETURNING `+auctionColumns, a.ID, status))
+	if err != nil {
+		return nil, nil, err
+	}
+	return a, best, tx.Commit(ctx)
+}
+
+// MarkAuctionAwarded фиксирует победителя после принятия оффера (no-op, если у груза нет закрытого аукциона).
+func (r *Repo) MarkAuctionAwarded(ctx context.Context, cargoID, offerID uuid.UUID) error {
+	_, err := r.pg.Exec(ctx, `
+UPDATE cargo_auctions SET status = 'AWARDED', winner_offer_id = $2, closed_at = COALESCE(closed_at, now()), updated_at = now()
+WHERE cargo_id = $1 AND status IN ('CLOSED', 'NO_WINNER')`, cargoID, offerID)
+	return err
+}
@@ -0,0 +1,157 @@
+package cargo
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestAuctionSnipeDeadline(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	a := &Auction{AntiSnipeMinutes: 5, EndsAt: now.Add(3 * time.Minute)}
+	if end, ok := a.SnipeDeadline(now); !ok || !end.Equal(now.Add(5*time.Minute)) {
+		t.Errorf("bid in the last minutes: got %v %v", end, ok)
+	}
+	a.EndsAt = now.Add(5 * time.Minute)
+	if _, ok := a.SnipeDeadline(now); ok {
+		t.Error("bid before the window must not extend")
+	}
+	a.AntiSnipeMinutes, a.EndsAt = 0, now.Add(time.Minute)
+	if _, ok := a.SnipeDeadline(now); ok {
+		t.Error("anti-snipe disabled")
+	}
+}
+
+func TestAuctionReserveMet(t *testing.T) {
+	reserve := 1000.0
+	a := &Auction{ReservePrice: &reserve}
+	if !a.ReserveMet(1000) || a.ReserveMet(1000.01) {
+		t.Error("reserve is the max acceptable price")
+	}
+	if !(&Auction{}).ReserveMet(1e9) {
+		t.Error("no reserve — any bid")
+	}
+}
+
+// Интеграционные тесты аукциона. Запуск с БД: TEST_DATABASE_URL или DATABASE_URL заданы.
+func testPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	connStr := os.Getenv("TEST_DATABASE_URL")
+	if connStr == "" {
+		connStr = os.Getenv("DATABASE_URL")
+	}
+	if connStr == "" {
+		t.Skip("TEST_DATABASE_URL or DATABASE_URL required for integration test")
+	}
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, connStr)
+	if err != nil {
+		t.Fatalf("pgxpool.New: %v", err)
+	}
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		t.Fatalf("pool.Ping: %v", err)
+	}
+	return pool
+}
+
+// startTestAuction создаёт груз в поиске с открытым аукционом в USD.
+func startTestAuction(t *testing.T, pool *pgxpool.Pool, in AuctionInput) uuid.UUID {
+	t.Helper()
+	ctx := context.Background()
+	var cargoID uuid.UUID
+	if err := pool.QueryRow(ctx, `INSERT INTO cargo (weight, volume, truck_type, status) VALUES (10, 20, 'TENT', $1) RETURNING id`,
+		StatusSearchingAll).Scan(&cargoID); err != nil {
+		t.Fatalf("insert cargo: %v", err)
+	}
+	t.Cleanup(func() { _, _ = pool.Exec(context.Background(), `DELETE FROM cargo WHERE id = $1`, cargoID) })
+	in.Currency = "USD"
+	if in.WinnerSelection == "" {
+		in.WinnerSelection = WinnerManual
+	}
+	if _, err := NewRepo(pool).StartAuction(ctx, cargoID, in); err != nil {
+		t.Fatalf("StartAuction: %v", err)
+	}
+	return cargoID
+}
+
+// expireAuction переносит срок аукциона в прошлое, чтобы его можно было закрыть.
+func expireAuction(t *testing.T, pool *pgxpool.Pool, cargoID uuid.UUID) {
+	t.Helper()
+	if _, err := pool.Exec(context.Background(), `UPDATE cargo_auctions SET ends_at = now() - interval '1 second' WHERE cargo_id = $1`, cargoID); err != nil {
+		t.Fatalf("expire auction: %v", err)
+	}
+}
+
+func TestPlaceBid_AntiSnipeExtendsAuction(t *testing.T) {
+	pool := testPool(t)
+	defer pool.Close()
+	ctx := context.Background()
+	repo := NewRepo(pool)
+	cargoID := startTestAuction(t, pool, AuctionInput{AntiSnipeMinutes: 10, EndsAt: time.Now().Add(2 * time.Minute)})
+
+	_, a, err := repo.PlaceBid(ctx, cargoID, uuid.New(), 900, "USD", "")
+	if err != nil {
+		t.Fatalf("PlaceBid: %v", err)
+	}
+	if a.ExtensionsCount != 1 || a.EndsAt.Sub(a.OriginalEndsAt) < 7*time.Minute {
+		t.Errorf("bid in the last minutes must extend the auction: ends %v, original %v, extensions %d", a.EndsAt, a.OriginalEndsAt, a.ExtensionsCount)
+	}
+}
+
+func TestCloseAuction_LowestBidWins(t *testing.T) {
+	pool := testPool(t)
+	defer pool.Close()
+	ctx := context.Background()
+	repo := NewRepo(pool)
+	cargoID := startTestAuction(t, pool, AuctionInput{EndsAt: time.Now().Add(time.Hour)})
+
+	if _, _, err := repo.PlaceBid(ctx, cargoID, uuid.New(), 1200, "USD", ""); err != nil {
+		t.Fatalf("PlaceBid: %v", err)
+	}
+	low, _, err := repo.PlaceBid(ctx, cargoID, uuid.New(), 950, "USD", "")
+	if err != nil {
+		t.Fatalf("PlaceBid: %v", err)
+	}
+	if _, _, err := repo.PlaceBid(ctx, cargoID, uuid.New(), 1100, "USD", ""); err != nil {
+		t.Fatalf("PlaceBid: %v", err)
+	}
+	expireAuction(t, pool, cargoID)
+
+	a, best, err := repo.CloseAuction(ctx, cargoID)
+	if err != nil {
+		t.Fatalf("CloseAuction: %v", err)
+	}
+	if a == nil || a.Status != AuctionClosed {
+		t.Fatalf("status: %+v", a)
+	}
+	if best == nil || *best != low.ID {
+		t.Errorf("winner: got %v, want %v", best, low.ID)
+	}
+}
+
+func TestCloseAuction_ReserveNotMet(t *testing.T) {
+	pool := testPool(t)
+	defer pool.Close()
+	ctx := context.Background()
+	repo := NewRepo(pool)
+	reserve := 800.0
+	cargoID := startTestAuction(t, pool, AuctionInput{ReservePrice: &reserve, EndsAt: time.Now().Add(time.Hour)})
+
+	if _, _, err := repo.PlaceBid(ctx, cargoID, uuid.New(), 950, "USD", ""); err != nil {
+		t.Fatalf("PlaceBid: %v", err)
+	}
+	expireAuction(t, pool, cargoID)
+
+	a, best, err := repo.CloseAuction(ctx, cargoID)
+	if err != nil {
+		t.Fatalf("CloseAuction: %v", err)
+	}
+	if a == nil || a.Status != AuctionNoWinner || best != nil {
+		t.Errorf("bids above reserve cannot win: auction %+v, best %v", a, best)
+	}
+}
@@ -0,0 +1,184 @@
+package cargo
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"sarbonNew/internal/routing"
+)
+
+// maxBackhaulCandidates — сколько грузов максимум отбирается в SQL до точной проверки радиуса.
+const maxBackhaulCandidates = 500
+
+// BackhaulFilter — обратный груз для водителя активного рейса: основная погрузка рядом с основной выгрузкой рейса,
+// груз готов к отгрузке не раньше дня прибытия (ETA), подходит по кузову и вместимости.
+type BackhaulFilter struct {
+	Near      routing.Point // основная выгрузка рейса
+	RadiusKm  float64
+	ReadyFrom time.Time // ETA; грузы без даты готовности (ready_enabled=false) готовы в любой день
+	TruckType string
+	MaxWeight *float64   // грузоподъёмность трейлера водителя, т
+	MaxVolume *float64   // объём кузова, м³
+	CompanyID *uuid.UUID // компания водителя — видит и грузы SEARCHING_COMPANY своей компании
+}
+
+// BackhaulCandidate — груз в поиске с основными точками погрузки и выгрузки и оплатой.
+type BackhaulCandidate struct {
+	Cargo   Cargo
+	Load    RoutePoint
+	Unload  *RoutePoint
+	Payment *Payment
+}
+
+// BackhaulSuggestion — кандидат с оценкой: порожний перегон от выгрузки рейса до погрузки и ставка на км.
+type BackhaulSuggestion struct {
+	BackhaulCandidate
+	DetourKm  float64  // порожний перегон (по дорогам)
+	LoadedKm  *float64 // гружёный путь груза (distance_km или оценка по основным точкам)
+	PriceBase *float64 // цена груза в базовой валюте (для сравнения цен в разных валютах)
+	RatePerKm *float64 // PriceBase / (DetourKm + LoadedKm) — выручка на каждый км с учётом порожнего перегона
+}
+
+// RankBackhaul оставляет грузы в радиусе от точки выгрузки и сортирует: выше ставка на км с учётом перегона,
+// при равной — короче перегон; грузы без цены (запрос цены, нет курса) — в конце по перегону.
+// roadKm — расстояние по дорогам между двумя точками, toBase — пересчёт цены в базовую валюту.
+func RankBackhaul(list []BackhaulCandidate, f BackhaulFilter, roadKm func(a, b routing.Point) float64, toBase func(amount float64, currency string) (float64, bool)) []BackhaulSuggestion {
+	out := make([]BackhaulSuggestion, 0, len(list))
+	for _, c := range list {
+		load := routing.Point{Lat: c.Load.Lat, Lng: c.Load.Lng}
+		if routing.HaversineKm(f.Near, load) > f.RadiusKm {
+			continue
+		}
+		s := BackhaulSuggestion{BackhaulCandidate: c, DetourKm: round1(roadKm(f.Near, load))}
+		if c.Cargo.DistanceKm != nil && *c.Cargo.DistanceKm > 0 {
+			v := round1(*c.Cargo.DistanceKm)
+			s.LoadedKm = &v
+		} else if c.Unload != nil {
+			v := round1(roadKm(load, routing.Point{Lat: c.Unload.Lat, Lng: c.Unload.Lng}))
+			s.LoadedKm = &v
+		}
+		if p := c.Payment; p != nil && !p.PriceRequest && p.TotalAmount != nil && p.TotalCurrency != nil && *p.TotalAmount > 0 {
+			if v, ok := toBase(*p.TotalAmount, *p.TotalCurrency); ok {
+				s.PriceBase = &v
+				if s.LoadedKm != nil && s.DetourKm+*s.LoadedKm > 0 {
+					rate := round2(v / (s.DetourKm + *s.LoadedKm))
+					s.RatePerKm = &rate
+				}
+			}
+		}
+		out = append(out, s)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if (a.RatePerKm != nil) != (b.RatePerKm != nil) {
+			return a.RatePerKm != nil
+		}
+		if a.RatePerKm != nil && *a.RatePerKm != *b.RatePerKm {
+			return *a.RatePerKm > *b.RatePerKm
+		}
+		return a.DetourKm < b.DetourKm
+	})
+	return out
+}
+
+func round1(v float64) float64 { return math.Round(v*10) / 10 }
+
+func round2(v float64) float64 { return math.Round(v*100) / 100 }
+
+// BackhaulCandidates отбирает грузы в поиске по фильтру с основной погрузкой в прямоугольнике вокруг f.Near
+// (точный радиус и ранжирование — RankBackhaul) и подгружает основные точки и оплату.
+func (r *Repo) BackhaulCandidates(ctx context.Context, f BackhaulFilter) ([]BackhaulCandidate, error) {
+	b := routing.BoundingBox(f.Near, f.RadiusKm)
+	day := time.Date(f.ReadyFrom.Year(), f.ReadyFrom.Month(), f.ReadyFrom.Day(), 0, 0, 0, 0, time.UTC)
+	args := []any{b.MinLat, b.MaxLat, b.MinLng, b.MaxLng, day}
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+	conds := []string{
+		"deleted_at IS NULL",
+		"EXISTS (SELECT 1 FROM route_points rp WHERE rp.cargo_id = cargo.id AND rp.is_main_load AND rp.lat BETWEEN $1 AND $2 AND rp.lng BETWEEN $3 AND $4)",
+		"(ready_enabled = false OR ready_at IS NULL OR ready_at >= $5)",
+	}
+	if f.CompanyID != nil {
+		conds = append(conds, "(status = '"+StatusSearchingAll+"' OR (status = '"+StatusSearchingCompany+"' AND company_id = "+arg(*f.CompanyID)+"))")
+	} else {
+		conds = append(conds, "status = '"+StatusSearchingAll+"'")
+	}
+	if f.TruckType != "" {
+		conds = append(conds, "truck_type = "+arg(f.TruckType))
+	}
+	if f.MaxWeight != nil {
+		conds = append(conds, "weight <= "+arg(*f.MaxWeight))
+	}
+	if f.MaxVolume != nil {
+		conds = append(conds, "volume <= "+arg(*f.MaxVolume))
+	}
+	q := `SELECT id, weight, volume, ready_enabled, ready_at, load_comment, truck_type,
+  temp_min, temp_max, adr_enabled, adr_class, loading_types, requirements, shipment_type, belts_count,
+  documents, contact_name, contact_phone, status, created_at, updated_at, deleted_at, moderation_rejection_reason, created_by_type, created_by_id, company_id,
+  distance_km::float8, duration_minutes
+FROM cargo WHERE ` + strings.Join(conds, " AND ") + ` ORDER BY created_at DESC LIMIT ` + arg(maxBackhaulCandidates)
+	rows, err := r.pg.Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	var list []BackhaulCandidate
+	for rows.Next() {
+		c, err := scanCargo(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		list = append(list, BackhaulCandidate{Cargo: *c})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil || len(list) == 0 {
+		return list, err
+	}
+	ids := make([]uuid.UUID, 0, len(list))
+	idx := make(map[uuid.UUID]int, len(list))
+	for i := range list {
+		ids = append(ids, list[i].Cargo.ID)
+		idx[list[i].Cargo.ID] = i
+	}
+	prow, err := r.pg.Query(ctx, `
+SELECT id, cargo_id, type, COALESCE(city_code,''), COALESCE(region_code,''), address, COALESCE(orientir,''), lat, lng, comment, point_order, is_main_load, is_main_unload
+FROM route_points WHERE cargo_id = ANY($1) AND (is_main_load OR is_main_unload)`, ids)
+	if err != nil {
+		return nil, err
+	}
+	for prow.Next() {
+		var rp RoutePoint
+		if err := prow.Scan(&rp.ID, &rp.CargoID, &rp.Type, &rp.CityCode, &rp.RegionCode, &rp.Address, &rp.Orientir, &rp.Lat, &rp.Lng, &rp.Comment, &rp.PointOrder, &rp.IsMainLoad, &rp.IsMainUnload); err != nil {
+			prow.Close()
+			return nil, err
+		}
+		c := &list[idx[rp.CargoID]]
+		if rp.IsMainLoad {
+			c.Load = rp
+		}
+		if rp.IsMainUnload {
+			p := rp
+			c.Unload = &p
+		}
+	}
+	prow.Close()
+	if err := prow.Err(); err != nil {
+		return nil, err
+	}
+	payments, err := r.GetPaymentsByCargoIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		list[i].Payment = payments[list[i].Cargo.ID]
+	}
+	return list, nil
+}
@@ -0,0 +1,60 @@
+package cargo
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+
+	"sarbonNew/internal/routing"
+)
+
+func TestRankBackhaul(t *testing.T) {
+	moscow := routing.Point{Lat: 55.75, Lng: 37.62}
+	roadKm := func(a, b routing.Point) float64 { return routing.HaversineKm(a, b) * 1.2 }
+	toBase := func(amount float64, currency string) (float64, bool) {
+		switch currency {
+		case "USD":
+			return amount, true
+		case "RUB":
+			return amount / 100, true
+		}
+		return 0, false
+	}
+	cand := func(lat, lng float64, distKm float64, amount *float64, currency string) BackhaulCandidate {
+		c := BackhaulCandidate{Cargo: Cargo{ID: uuid.New(), DistanceKm: &distKm}, Load: RoutePoint{Lat: lat, Lng: lng}}
+		if amount != nil {
+			c.Payment = &Payment{TotalAmount: amount, TotalCurrency: &currency}
+		}
+		return c
+	}
+	f := func(v float64) *float64 { return &v }
+
+	inCity := cand(55.76, 37.6, 3000, f(3000), "USD")  // почти без перегона, 1 $/км
+	nearby := cand(55.9, 37.8, 3000, f(400000), "RUB") // ~20 км перегона, дороже: ~1.32 $/км
+	noPrice := cand(55.75, 37.62, 3000, nil, "")       // запрос цены — в конце
+	noRate := cand(55.8, 37.7, 3000, f(5000), "KZT")   // нет курса — как без цены
+	tooFar := cand(54.2, 37.6, 3000, f(9000), "USD")   // Тула, ~170 км — вне радиуса
+	got := RankBackhaul([]BackhaulCandidate{inCity, noRate, tooFar, noPrice, nearby}, BackhaulFilter{Near: moscow, RadiusKm: 100}, roadKm, toBase)
+	if len(got) != 4 {
+		t.Fatalf("radius: got %d", len(got))
+	}
+	if got[0].Cargo.ID != nearby.Cargo.ID || got[1].Cargo.ID != inCity.Cargo.ID {
+		t.Errorf("rate order: %v, %v", got[0].RatePerKm, got[1].RatePerKm)
+	}
+	if got[0].PriceBase == nil || *got[0].PriceBase != 4000 || got[0].DetourKm < 15 || got[0].DetourKm > 30 {
+		t.Errorf("nearby: price %v detour %v", got[0].PriceBase, got[0].DetourKm)
+	}
+	// без ставки — по перегону
+	if got[2].Cargo.ID != noPrice.Cargo.ID || got[3].Cargo.ID != noRate.Cargo.ID || got[2].RatePerKm != nil || got[3].PriceBase != nil {
+		t.Errorf("unpriced order: %+v %+v", got[2].RatePerKm, got[3].PriceBase)
+	}
+
+	// без distance_km гружёный путь оценивается по основным точкам
+	c := cand(55.76, 37.6, 0, f(1000), "USD")
+	c.Cargo.DistanceKm = nil
+	c.Unload = &RoutePoint{Lat: 59.93, Lng: 30.31} // Санкт-Петербург
+	got = RankBackhaul([]BackhaulCandidate{c}, BackhaulFilter{Near: moscow, RadiusKm: 100}, roadKm, toBase)
+	if len(got) != 1 || got[0].LoadedKm == nil || *got[0].LoadedKm < 700 || got[0].RatePerKm == nil {
+		t.Errorf("loaded km: %+v", got)
+	}
+}
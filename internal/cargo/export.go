@@ -0,0 +1,159 @@
+package cargo
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ExportRow — строка отчёта по грузам: груз, главные точки погрузки/выгрузки, оплата, компания, число офферов.
+type ExportRow struct {
+	Cargo
+	LoadCity      *string
+	LoadAddress   *string
+	UnloadCity    *string
+	UnloadAddress *string
+	TotalAmount   *float64
+	TotalCurrency *string
+	CompanyName   *string
+	OffersCount   int
+}
+
+// OfferExportFilter — фильтр отчёта по офферам.
+type OfferExportFilter struct {
+	Status      []string
+	CargoID     *uuid.UUID
+	CreatedFrom string // YYYY-MM-DD
+	CreatedTo   string
+}
+
+// OfferExportRow — строка отчёта по офферам: оффер, водитель, направление груза.
+type OfferExportRow struct {
+	Offer
+	DriverName  *string
+	DriverPhone *string
+	LoadCity    *string
+	UnloadCity  *string
+	TruckType   string
+}
+
+// Count returns number of cargo matching the list filter (without pagination).
+func (r *Repo) Count(ctx context.Context, f ListFilter) (int, error) {
+	where, args := listWhere(f)
+	var n int
+	err := r.pg.QueryRow(ctx, "SELECT COUNT(*) FROM cargo WHERE "+where, args...).Scan(&n)
+	return n, err
+}
+
+// ExportRows проходит по всем грузам фильтра (без пагинации, в порядке f.Sort) и вызывает fn для каждой строки.
+// Строки читаются курсором и не накапливаются в памяти; ошибка fn прерывает выгрузку.
+func (r *Repo) ExportRows(ctx context.Context, f ListFilter, fn func(*ExportRow) error) error {
+	where, args := listWhere(f)
+	q := `SELECT id, weight, volume, ready_enabled, ready_at, load_comment, truck_type,
+  temp_min, temp_max, adr_enabled, adr_class, loading_types, requirements, shipment_type, belts_count,
+  documents, contact_name, contact_phone, status, created_at, updated_at, deleted_at, moderation_rejection_reason, created_by_type, created_by_id, company_id,
+  distance_km::float8, duration_minutes,
+  lp.load_city, lp.load_address, up.unload_city, up.unload_address, pay.total_amount, pay.total_currency, cmp.company_name, oc.offers_count
+FROM cargo
+LEFT JOIN LATERAL (SELECT rp.city_code AS load_city, rp.address AS load_address FROM route_points rp
+  WHERE rp.cargo_id = cargo.id AND rp.is_main_load ORDER BY rp.point_order LIMIT 1) lp ON true
+LEFT JOIN LATERAL (SELECT rp.city_code AS unload_city, rp.address AS unload_address FROM route_points rp
+  WHERE rp.cargo_id = cargo.id AND rp.is_main_unload ORDER BY rp.point_order LIMIT 1) up ON true
+LEFT JOIN LATERAL (SELECT p.total_amount, p.total_currency FROM payments p WHERE p.cargo_id = cargo.id) pay ON true
+LEFT JOIN LATERAL (SELECT co.name AS company_name FROM companies co WHERE co.id = cargo.company_id) cmp ON true
+LEFT JOIN LATERAL (SELECT COUNT(*)::int AS offers_count FROM offers o WHERE o.cargo_id = cargo.id) oc ON true
+WHERE ` + where + ` ORDER BY ` + listOrder(f.Sort)
+	rows, err := r.pg.Query(ctx, q, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var e ExportRow
+		c := &e.Cargo
+		var docBytes []byte
+		err := rows.Scan(
+			&c.ID, &c.Weight, &c.Volume, &c.ReadyEnabled, &c.ReadyAt, &c.LoadComment, &c.TruckType,
+			&c.TempMin, &c.TempMax, &c.ADREnabled, &c.ADRClass, &c.LoadingTypes, &c.Requirements, &c.ShipmentType, &c.BeltsCount,
+			&docBytes, &c.ContactName, &c.ContactPhone, &c.Status, &c.CreatedAt, &c.UpdatedAt, &c.DeletedAt,
+			&c.ModerationRejectionReason, &c.CreatedByType, &c.CreatedByID, &c.CompanyID,
+			&c.DistanceKm, &c.DurationMinutes,
+			&e.LoadCity, &e.LoadAddress, &e.UnloadCity, &e.UnloadAddress, &e.TotalAmount, &e.TotalCurrency, &e.CompanyName, &e.OffersCount,
+		)
+		if err != nil {
+			return err
+		}
+		if len(docBytes) > 0 {
+			c.Documents, _ = DocumentsFromJSON(docBytes)
+		}
+		if err := fn(&e); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func offerExportWhere(f OfferExportFilter) (string, []any) {
+	conds := []string{"true"}
+	var args []any
+	add := func(cond string, v any) {
+		args = append(args, v)
+		conds = append(conds, strings.ReplaceAll(cond, "?", "$"+strconv.Itoa(len(args))))
+	}
+	if len(f.Status) > 0 {
+		add("o.status = ANY(?)", f.Status)
+	}
+	if f.CargoID != nil {
+		add("o.cargo_id = ?", *f.CargoID)
+	}
+	if f.CreatedFrom != "" {
+		add("o.created_at::date >= ?", f.CreatedFrom)
+	}
+	if f.CreatedTo != "" {
+		add("o.created_at::date <= ?", f.CreatedTo)
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+// CountOffers returns number of offers matching the export filter.
+func (r *Repo) CountOffers(ctx context.Context, f OfferExportFilter) (int, error) {
+	where, args := offerExportWhere(f)
+	var n int
+	err := r.pg.QueryRow(ctx, "SELECT COUNT(*) FROM offers o WHERE "+where, args...).Scan(&n)
+	return n, err
+}
+
+// ExportOffers проходит по офферам фильтра (новые первыми) и вызывает fn для каждой строки.
+func (r *Repo) ExportOffers(ctx context.Context, f OfferExportFilter, fn func(*OfferExportRow) error) error {
+	where, args := offerExportWhere(f)
+	rows, err := r.pg.Query(ctx, `
+SELECT o.id, o.cargo_id, o.carrier_id, o.price, o.currency, o.comment, o.status, o.rejection_reason,
+  o.rounds_count, o.last_round_by, o.expires_at, o.created_at, o.updated_at,
+  d.name, d.phone, lp.city_code, up.city_code, c.truck_type
+FROM offers o
+JOIN cargo c ON c.id = o.cargo_id
+LEFT JOIN drivers d ON d.id = o.carrier_id
+LEFT JOIN LATERAL (SELECT rp.city_code FROM route_points rp WHERE rp.cargo_id = o.cargo_id AND rp.is_main_load ORDER BY rp.point_order LIMIT 1) lp ON true
+LEFT JOIN LATERAL (SELECT rp.city_code FROM route_points rp WHERE rp.cargo_id = o.cargo_id AND rp.is_main_unload ORDER BY rp.point_order LIMIT 1) up ON true
+WHERE `+where+` ORDER BY o.created_at DESC`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var e OfferExportRow
+		o := &e.Offer
+		err := rows.Scan(&o.ID, &o.CargoID, &o.CarrierID, &o.Price, &o.Currency, &o.Comment, &o.Status, &o.RejectionReason,
+			&o.RoundsCount, &o.LastRoundBy, &o.ExpiresAt, &o.CreatedAt, &o.UpdatedAt,
+			&e.DriverName, &e.DriverPhone, &e.LoadCity, &e.UnloadCity, &e.TruckType)
+		if err != nil {
+			return err
+		}
+		if err := fn(&e); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -69,6 +69,9 @@ type Cargo struct {
 	CreatedByID   *uuid.UUID
 	// От какой компании груз (опционально; при created_by_type=company совпадает с created_by_id)
 	CompanyID     *uuid.UUID
+	// Оценка маршрута (internal/routing): расстояние по дорогам и время в пути
+	DistanceKm      *float64
+	DurationMinutes *int
 }
 
 // RoutePoint model (table route_points).
@@ -104,6 +107,7 @@ type Payment struct {
 	RemainingAmount   *float64
 	RemainingCurrency *string
 	RemainingType     *string
+	MaxCounterRounds  *int // лимит встречных предложений по офферу (nil — без лимита)
 }
 
 // Offer model (table offers).
@@ -114,9 +118,50 @@ type Offer struct {
 	Price          float64
 	Currency       string
 	Comment        *string
-	Status         string // pending, accepted, rejected
+	Status         string // PENDING, ACCEPTED, REJECTED, EXPIRED, WITHDRAWN
 	RejectionReason *string // optional, when dispatcher rejects
+	RoundsCount    int    // число предложений в торге (1 — только исходный оффер)
+	LastRoundBy    string // DRIVER или SHIPPER — чьё предложение сейчас на столе
+	ExpiresAt      *time.Time // nil — без срока; после истечения фоновая задача переводит в EXPIRED
+	ListingID      *uuid.UUID // оффер создан грузоотправителем по объявлению о свободной машине (truck_listings)
 	CreatedAt      time.Time
+	UpdatedAt      *time.Time
+}
+
+// Статусы оффера.
+const (
+	OfferStatusPending   = "PENDING"
+	OfferStatusAccepted  = "ACCEPTED"
+	OfferStatusRejected  = "REJECTED"
+	OfferStatusExpired   = "EXPIRED"
+	OfferStatusWithdrawn = "WITHDRAWN"
+)
+
+// Стороны торга по офферу.
+const (
+	SideDriver  = "DRIVER"
+	SideShipper = "SHIPPER"
+)
+
+// OfferRound — одно предложение цены в торге (table offer_rounds).
+type OfferRound struct {
+	ID         uuid.UUID
+	OfferID    uuid.UUID
+	RoundNo    int
+	AuthorType string // DRIVER, DISPATCHER, COMPANY, ADMIN
+	AuthorID   *uuid.UUID
+	Price      float64
+	Currency   string
+	Comment    *string
+	CreatedAt  time.Time
+}
+
+// Side возвращает сторону торга автора предложения.
+func (r OfferRound) Side() string {
+	if r.AuthorType == SideDriver {
+		return SideDriver
+	}
+	return SideShipper
 }
 
 // DocumentsToJSON returns JSON bytes for DB insert/update.
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -66,6 +67,9 @@ type CreateParams struct {
 	CreatedByType *string
 	CreatedByID   *uuid.UUID
 	CompanyID     *uuid.UUID
+	// Источник: шаблон и расписание (cargo_templates, cargo_schedules), если груз создан из шаблона
+	TemplateID *uuid.UUID
+	ScheduleID *uuid.UUID
 }
 
 type RoutePointInput struct {
@@ -95,6 +99,7 @@ type PaymentInput struct {
 	RemainingAmount    *float64
 	RemainingCurrency  *string
 	RemainingType      *string
+	MaxCounterRounds   *int
 }
 
 // Create creates cargo, route_points and payment in a transaction.
@@ -105,19 +110,49 @@ func (r *Repo) Create(ctx context.Context, p CreateParams) (uuid.UUID, error) {
 	}
 	defer tx.Rollback(ctx)
 
+	id, err := createTx(ctx, tx, p)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return id, tx.Commit(ctx)
+}
+
+// CreateBatch creates several cargo in one transaction (all or nothing); ids are in the order of list.
+func (r *Repo) CreateBatch(ctx context.Context, list []CreateParams) ([]uuid.UUID, error) {
+	tx, err := r.pg.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	ids := make([]uuid.UUID, 0, len(list))
+	for _, p := range list {
+		id, err := createTx(ctx, tx, p)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, tx.Commit(ctx)
+}
+
+func createTx(ctx context.Context, tx pgx.Tx, p CreateParams) (uuid.UUID, error) {
 	docJSON, _ := DocumentsToJSON(p.Documents)
 	var id uuid.UUID
 	q := `
 INSERT INTO cargo (weight, volume, ready_enabled, ready_at, load_comment, truck_type,
   temp_min, temp_max, adr_enabled, adr_class, loading_types, requirements, shipment_type, belts_count,
-  documents, contact_name, contact_phone, status, created_at, updated_at, deleted_at, created_by_type, created_by_id, company_id)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, COALESCE(NULLIF(TRIM($18),''), 'PENDING_MODERATION'), now(), now(), NULL, $19, $20, $21)
+  documents, contact_name, contact_phone, status, created_at, updated_at, deleted_at, created_by_type, created_by_id, company_id,
+  template_id, schedule_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, COALESCE(NULLIF(TRIM($18),''), 'PENDING_MODERATION'), now(), now(), NULL, $19, $20, $21,
+  $22, $23)
 RETURNING id`
-	err = tx.QueryRow(ctx, q,
+	err := tx.QueryRow(ctx, q,
 		p.Weight, p.Volume, p.ReadyEnabled, p.ReadyAt, p.LoadComment, p.TruckType,
 		p.TempMin, p.TempMax, p.ADREnabled, p.ADRClass, p.LoadingTypes, p.Requirements, p.ShipmentType, p.BeltsCount,
 		docJSON, p.ContactName, p.ContactPhone, p.Status,
 		p.CreatedByType, p.CreatedByID, p.CompanyID,
+		p.TemplateID, p.ScheduleID,
 	).Scan(&id)
 	if err != nil {
 		return uuid.Nil, err
@@ -136,24 +171,25 @@ VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
 	if p.Payment != nil {
 		_, err = tx.Exec(ctx, `
 INSERT INTO payments (cargo_id, is_negotiable, price_request, total_amount, total_currency, with_prepayment, without_prepayment,
-  prepayment_amount, prepayment_currency, prepayment_type, remaining_amount, remaining_currency, remaining_type)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+  prepayment_amount, prepayment_currency, prepayment_type, remaining_amount, remaining_currency, remaining_type, max_counter_rounds)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`,
 			id, p.Payment.IsNegotiable, p.Payment.PriceRequest, p.Payment.TotalAmount, p.Payment.TotalCurrency,
 			p.Payment.WithPrepayment, p.Payment.WithoutPrepayment, p.Payment.PrepaymentAmount, p.Payment.PrepaymentCurrency,
-			p.Payment.PrepaymentType, p.Payment.RemainingAmount, p.Payment.RemainingCurrency, p.Payment.RemainingType)
+			p.Payment.PrepaymentType, p.Payment.RemainingAmount, p.Payment.RemainingCurrency, p.Payment.RemainingType, p.Payment.MaxCounterRounds)
 		if err != nil {
 			return uuid.Nil, err
 		}
 	}
 
-	return id, tx.Commit(ctx)
+	return id, nil
 }
 
 // GetByID returns cargo by id (excluding soft-deleted if needAll=false).
 func (r *Repo) GetByID(ctx context.Context, id uuid.UUID, includeDeleted bool) (*Cargo, error) {
 	q := `SELECT id, weight, volume, ready_enabled, ready_at, load_comment, truck_type,
   temp_min, temp_max, adr_enabled, adr_class, loading_types, requirements, shipment_type, belts_count,
-  documents, contact_name, contact_phone, status, created_at, updated_at, deleted_at, moderation_rejection_reason, created_by_type, created_by_id, company_id
+  documents, contact_name, contact_phone, status, created_at, updated_at, deleted_at, moderation_rejection_reason, created_by_type, created_by_id, company_id,
+  distance_km::float8, duration_minutes
 FROM cargo WHERE id = $1`
 	if !includeDeleted {
 		q += ` AND deleted_at IS NULL`
@@ -183,16 +219,72 @@ FROM route_points WHERE cargo_id = $1 ORDER BY point_order`,
 	return list, rows.Err()
 }
 
+// SetRouteEstimate stores estimated road distance and duration (internal/routing) for a cargo.
+func (r *Repo) SetRouteEstimate(ctx context.Context, cargoID uuid.UUID, distanceKm float64, durationMinutes int) error {
+	_, err := r.pg.Exec(ctx, `UPDATE cargo SET distance_km = $2, duration_minutes = $3 WHERE id = $1`, cargoID, distanceKm, durationMinutes)
+	return err
+}
+
+// IDsWithoutRouteEstimate returns cargo that has at least two route points but no route estimate yet
+// (created before estimates were stored; see cmd/routeestimates).
+func (r *Repo) IDsWithoutRouteEstimate(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := r.pg.Query(ctx, `
+SELECT c.id FROM cargo c
+WHERE c.distance_km IS NULL AND c.deleted_at IS NULL
+  AND (SELECT COUNT(*) FROM route_points rp WHERE rp.cargo_id = c.id) >= 2
+ORDER BY c.created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out = append(out, id)
+	}
+	return out, rows.Err()
+}
+
+// GetPaymentsByCargoIDs returns payments keyed by cargo_id (for lists: price per km).
+func (r *Repo) GetPaymentsByCargoIDs(ctx context.Context, cargoIDs []uuid.UUID) (map[uuid.UUID]*Payment, error) {
+	out := make(map[uuid.UUID]*Payment, len(cargoIDs))
+	if len(cargoIDs) == 0 {
+		return out, nil
+	}
+	rows, err := r.pg.Query(ctx, `
+SELECT id, cargo_id, is_negotiable, price_request, total_amount, total_currency, with_prepayment, without_prepayment,
+  prepayment_amount, prepayment_currency, prepayment_type, remaining_amount, remaining_currency, remaining_type, max_counter_rounds
+FROM payments WHERE cargo_id = ANY($1)`, cargoIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var pay Payment
+		err := rows.Scan(&pay.ID, &pay.CargoID, &pay.IsNegotiable, &pay.PriceRequest, &pay.TotalAmount, &pay.TotalCurrency,
+			&pay.WithPrepayment, &pay.WithoutPrepayment, &pay.PrepaymentAmount, &pay.PrepaymentCurrency,
+			&pay.PrepaymentType, &pay.RemainingAmount, &pay.RemainingCurrency, &pay.RemainingType, &pay.MaxCounterRounds)
+		if err != nil {
+			return nil, err
+		}
+		out[pay.CargoID] = &pay
+	}
+	return out, rows.Err()
+}
+
 // GetPayment returns payment for a cargo (if any).
 func (r *Repo) GetPayment(ctx context.Context, cargoID uuid.UUID) (*Payment, error) {
 	var pay Payment
 	err := r.pg.QueryRow(ctx, `
 SELECT id, cargo_id, is_negotiable, price_request, total_amount, total_currency, with_prepayment, without_prepayment,
-  prepayment_amount, prepayment_currency, prepayment_type, remaining_amount, remaining_currency, remaining_type
+  prepayment_amount, prepayment_currency, prepayment_type, remaining_amount, remaining_currency, remaining_type, max_counter_rounds
 FROM payments WHERE cargo_id = $1`, cargoID).Scan(
 		&pay.ID, &pay.CargoID, &pay.IsNegotiable, &pay.PriceRequest, &pay.TotalAmount, &pay.TotalCurrency,
 		&pay.WithPrepayment, &pay.WithoutPrepayment, &pay.PrepaymentAmount, &pay.PrepaymentCurrency,
-		&pay.PrepaymentType, &pay.RemainingAmount, &pay.RemainingCurrency, &pay.RemainingType)
+		&pay.PrepaymentType, &pay.RemainingAmount, &pay.RemainingCurrency, &pay.RemainingType, &pay.MaxCounterRounds)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, nil
@@ -211,6 +303,7 @@ func scanCargo(row pgx.Row) (*Cargo, error) {
 		&c.TempMin, &c.TempMax, &c.ADREnabled, &c.ADRClass, &loadingTypes, &requirements, &c.ShipmentType, &c.BeltsCount,
 		&docBytes, &c.ContactName, &c.ContactPhone, &c.Status, &c.CreatedAt, &c.UpdatedAt, &c.DeletedAt,
 		&c.ModerationRejectionReason, &c.CreatedByType, &c.CreatedByID, &c.CompanyID,
+		&c.DistanceKm, &c.DurationMinutes,
 	)
 	if err != nil {
 		return nil, err
@@ -223,8 +316,8 @@ func scanCargo(row pgx.Row) (*Cargo, error) {
 	return &c, nil
 }
 
-// List returns paginated cargo list with filters.
-func (r *Repo) List(ctx context.Context, f ListFilter) (ListResult, error) {
+// listWhere строит условие WHERE по фильтру списка (общий для List, Count и выгрузки отчётов).
+func listWhere(f ListFilter) (string, []any) {
 	var args []any
 	var conds []string
 	argNum := 1
@@ -269,7 +362,28 @@ func (r *Repo) List(ctx context.Context, f ListFilter) (ListResult, error) {
 		conds = append(conds, "EXISTS (SELECT 1 FROM offers o WHERE o.cargo_id = cargo.id)")
 	}
 
-	where := strings.Join(conds, " AND ")
+	return strings.Join(conds, " AND "), args
+}
+
+// listOrder переводит sort ("created_at:desc", "weight:asc", "status:desc") в ORDER BY; по умолчанию created_at DESC.
+func listOrder(sort string) string {
+	parts := strings.SplitN(sort, ":", 2)
+	if len(parts) == 2 {
+		col := strings.TrimSpace(parts[0])
+		dir := strings.ToUpper(strings.TrimSpace(parts[1]))
+		if col == "created_at" || col == "weight" || col == "status" {
+			if dir == "ASC" || dir == "DESC" {
+				return col + " " + dir
+			}
+		}
+	}
+	return "created_at DESC"
+}
+
+// List returns paginated cargo list with filters.
+func (r *Repo) List(ctx context.Context, f ListFilter) (ListResult, error) {
+	where, args := listWhere(f)
+	argNum := len(args) + 1
 
 	// total
 	var total int
@@ -278,19 +392,7 @@ func (r *Repo) List(ctx context.Context, f ListFilter) (ListResult, error) {
 		return ListResult{}, err
 	}
 
-	order := "created_at DESC"
-	if f.Sort != "" {
-		parts := strings.SplitN(f.Sort, ":", 2)
-		if len(parts) == 2 {
-			col := strings.TrimSpace(parts[0])
-			dir := strings.ToUpper(strings.TrimSpace(parts[1]))
-			if col == "created_at" || col == "weight" || col == "status" {
-				if dir == "ASC" || dir == "DESC" {
-					order = col + " " + dir
-				}
-			}
-		}
-	}
+	order := listOrder(f.Sort)
 
 	limit := f.Limit
 	if limit <= 0 {
@@ -306,7 +408,8 @@ func (r *Repo) List(ctx context.Context, f ListFilter) (ListResult, error) {
 	args = append(args, limit, offset)
 	q := `SELECT id, weight, volume, ready_enabled, ready_at, load_comment, truck_type,
   temp_min, temp_max, adr_enabled, adr_class, loading_types, requirements, shipment_type, belts_count,
-  documents, contact_name, contact_phone, status, created_at, updated_at, deleted_at, moderation_rejection_reason, created_by_type, created_by_id, company_id
+  documents, contact_name, contact_phone, status, created_at, updated_at, deleted_at, moderation_rejection_reason, created_by_type, created_by_id, company_id,
+  distance_km::float8, duration_minutes
 FROM cargo WHERE ` + where + ` ORDER BY ` + order + ` LIMIT $` + strconv.Itoa(argNum) + ` OFFSET $` + strconv.Itoa(argNum+1)
 
 	rows, err := r.pg.Query(ctx, q, args...)
@@ -470,11 +573,12 @@ VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
 	if p.Payment != nil && existing.Status != StatusAssigned && existing.Status != StatusInTransit && existing.Status != StatusDelivered {
 		_, err = tx.Exec(ctx, `
 UPDATE payments SET is_negotiable=$2, price_request=$3, total_amount=$4, total_currency=$5, with_prepayment=$6, without_prepayment=$7,
-  prepayment_amount=$8, prepayment_currency=$9, prepayment_type=$10, remaining_amount=$11, remaining_currency=$12, remaining_type=$13
+  prepayment_amount=$8, prepayment_currency=$9, prepayment_type=$10, remaining_amount=$11, remaining_currency=$12, remaining_type=$13,
+  max_counter_rounds=$14
 WHERE cargo_id = $1`,
 			id, p.Payment.IsNegotiable, p.Payment.PriceRequest, p.Payment.TotalAmount, p.Payment.TotalCurrency,
 			p.Payment.WithPrepayment, p.Payment.WithoutPrepayment, p.Payment.PrepaymentAmount, p.Payment.PrepaymentCurrency,
-			p.Payment.PrepaymentType, p.Payment.RemainingAmount, p.Payment.RemainingCurrency, p.Payment.RemainingType)
+			p.Payment.PrepaymentType, p.Payment.RemainingAmount, p.Payment.RemainingCurrency, p.Payment.RemainingType, p.Payment.MaxCounterRounds)
 		if err != nil {
 			return err
 		}
@@ -484,11 +588,11 @@ WHERE cargo_id = $1`,
 		if n == 0 {
 			_, err = tx.Exec(ctx, `
 INSERT INTO payments (cargo_id, is_negotiable, price_request, total_amount, total_currency, with_prepayment, without_prepayment,
-  prepayment_amount, prepayment_currency, prepayment_type, remaining_amount, remaining_currency, remaining_type)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+  prepayment_amount, prepayment_currency, prepayment_type, remaining_amount, remaining_currency, remaining_type, max_counter_rounds)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`,
 				id, p.Payment.IsNegotiable, p.Payment.PriceRequest, p.Payment.TotalAmount, p.Payment.TotalCurrency,
 				p.Payment.WithPrepayment, p.Payment.WithoutPrepayment, p.Payment.PrepaymentAmount, p.Payment.PrepaymentCurrency,
-				p.Payment.PrepaymentType, p.Payment.RemainingAmount, p.Payment.RemainingCurrency, p.Payment.RemainingType)
+				p.Payment.PrepaymentType, p.Payment.RemainingAmount, p.Payment.RemainingCurrency, p.Payment.RemainingType, p.Payment.MaxCounterRounds)
 			if err != nil {
 				return err
 			}
@@ -552,8 +656,8 @@ func (r *Repo) GetOfferByID(ctx context.Context, offerID uuid.UUID) (*Offer, err
 	var o Offer
 	var rejReason string
 	err := r.pg.QueryRow(ctx, `
-SELECT id, cargo_id, carrier_id, price, currency, comment, status, COALESCE(rejection_reason, ''), created_at
-FROM offers WHERE id = $1`, offerID).Scan(&o.ID, &o.CargoID, &o.CarrierID, &o.Price, &o.Currency, &o.Comment, &o.Status, &rejReason, &o.CreatedAt)
+SELECT id, cargo_id, carrier_id, price, currency, comment, status, COALESCE(rejection_reason, ''), rounds_count, last_round_by, expires_at, created_at, updated_at, listing_id
+FROM offers WHERE id = $1`, offerID).Scan(&o.ID, &o.CargoID, &o.CarrierID, &o.Price, &o.Currency, &o.Comment, &o.Status, &rejReason, &o.RoundsCount, &o.LastRoundBy, &o.ExpiresAt, &o.CreatedAt, &o.UpdatedAt, &o.ListingID)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, nil
@@ -569,7 +673,7 @@ FROM offers WHERE id = $1`, offerID).Scan(&o.ID, &o.CargoID, &o.CarrierID, &o.Pr
 // GetOffers returns all offers for a cargo.
 func (r *Repo) GetOffers(ctx context.Context, cargoID uuid.UUID) ([]Offer, error) {
 	rows, err := r.pg.Query(ctx, `
-SELECT id, cargo_id, carrier_id, price, currency, comment, status, COALESCE(rejection_reason, ''), created_at
+SELECT id, cargo_id, carrier_id, price, currency, comment, status, COALESCE(rejection_reason, ''), rounds_count, last_round_by, expires_at, created_at, updated_at, listing_id
 FROM offers WHERE cargo_id = $1 ORDER BY created_at DESC`, cargoID)
 	if err != nil {
 		return nil, err
@@ -579,7 +683,7 @@ FROM offers WHERE cargo_id = $1 ORDER BY created_at DESC`, cargoID)
 	for rows.Next() {
 		var o Offer
 		var rejReason string
-		err := rows.Scan(&o.ID, &o.CargoID, &o.CarrierID, &o.Price, &o.Currency, &o.Comment, &o.Status, &rejReason, &o.CreatedAt)
+		err := rows.Scan(&o.ID, &o.CargoID, &o.CarrierID, &o.Price, &o.Currency, &o.Comment, &o.Status, &rejReason, &o.RoundsCount, &o.LastRoundBy, &o.ExpiresAt, &o.CreatedAt, &o.UpdatedAt, &o.ListingID)
 		if err != nil {
 			return nil, err
 		}
@@ -591,14 +695,159 @@ FROM offers WHERE cargo_id = $1 ORDER BY created_at DESC`, cargoID)
 	return list, rows.Err()
 }
 
-// CreateOffer inserts an offer for a cargo.
-func (r *Repo) CreateOffer(ctx context.Context, cargoID, carrierID uuid.UUID, price float64, currency, comment string) (uuid.UUID, error) {
+// CreateOffer inserts an offer for a cargo; the offer itself is round 1 of the negotiation (author: driver).
+// expiresAt — optional validity of the offer (nil = until accepted/rejected/withdrawn).
+func (r *Repo) CreateOffer(ctx context.Context, cargoID, carrierID uuid.UUID, price float64, currency, comment string, expiresAt *time.Time) (uuid.UUID, error) {
+	tx, err := r.pg.Begin(ctx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	defer tx.Rollback(ctx)
 	var id uuid.UUID
-	err := r.pg.QueryRow(ctx, `
-INSERT INTO offers (cargo_id, carrier_id, price, currency, comment, status, created_at)
-VALUES ($1, $2, $3, $4, $5, 'PENDING', now()) RETURNING id`,
-		cargoID, carrierID, price, currency, nullStr(comment)).Scan(&id)
-	return id, err
+	err = tx.QueryRow(ctx, `
+INSERT INTO offers (cargo_id, carrier_id, price, currency, comment, status, rounds_count, last_round_by, expires_at, created_at)
+VALUES ($1, $2, $3, $4, $5, 'PENDING', 1, $6, $7, now()) RETURNING id`,
+		cargoID, carrierID, price, currency, nullStr(comment), SideDriver, expiresAt).Scan(&id)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	_, err = tx.Exec(ctx, `
+INSERT INTO offer_rounds (offer_id, round_no, author_type, author_id, price, currency, comment)
+VALUES ($1, 1, 'DRIVER', $2, $3, $4, $5)`,
+		id, carrierID, price, currency, nullStr(comment))
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return id, tx.Commit(ctx)
+}
+
+// ShipperOfferInput — предложение груза водителю от грузоотправителя (например, по объявлению о свободной машине).
+type ShipperOfferInput struct {
+	CargoID    uuid.UUID
+	CarrierID  uuid.UUID
+	ListingID  *uuid.UUID
+	AuthorType string // DISPATCHER, COMPANY
+	AuthorID   uuid.UUID
+	Price      float64
+	Currency   string
+	Comment    string
+	ExpiresAt  *time.Time
+}
+
+// CreateShipperOffer создаёт оффер, первое предложение в котором — от грузоотправителя (last_round_by = SHIPPER):
+// водитель принимает его или торгуется как обычно.
+func (r *Repo) CreateShipperOffer(ctx context.Context, in ShipperOfferInput) (uuid.UUID, error) {
+	tx, err := r.pg.Begin(ctx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	defer tx.Rollback(ctx)
+	var id uuid.UUID
+	err = tx.QueryRow(ctx, `
+INSERT INTO offers (cargo_id, carrier_id, price, currency, comment, status, rounds_count, last_round_by, expires_at, listing_id, created_at)
+VALUES ($1, $2, $3, $4, $5, 'PENDING', 1, $6, $7, $8, now()) RETURNING id`,
+		in.CargoID, in.CarrierID, in.Price, in.Currency, nullStr(in.Comment), SideShipper, in.ExpiresAt, in.ListingID).Scan(&id)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	_, err = tx.Exec(ctx, `
+INSERT INTO offer_rounds (offer_id, round_no, author_type, author_id, price, currency, comment)
+VALUES ($1, 1, $2, $3, $4, $5, $6)`,
+		id, in.AuthorType, in.AuthorID, in.Price, in.Currency, nullStr(in.Comment))
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return id, tx.Commit(ctx)
+}
+
+var (
+	ErrOfferNotPending     = errors.New("cargo: offer not found or not pending")
+	ErrOfferNotYourTurn    = errors.New("cargo: offer awaits the other side")
+	ErrOfferNotNegotiable  = errors.New("cargo: price is not negotiable")
+	ErrOfferRoundsExceeded = errors.New("cargo: counter-offer limit reached")
+)
+
+// CounterOfferInput — встречное предложение цены.
+type CounterOfferInput struct {
+	AuthorType string // DRIVER, DISPATCHER, COMPANY, ADMIN
+	AuthorID   *uuid.UUID
+	Price      float64
+	Currency   string
+	Comment    string
+}
+
+// CounterOffer добавляет раунд торга и делает его цену текущей ценой оффера.
+// Предлагать можно только в свой ход (последнее предложение — от другой стороны),
+// если оплата груза договорная (или цена по запросу) и лимит payments.max_counter_rounds не исчерпан.
+func (r *Repo) CounterOffer(ctx context.Context, offerID uuid.UUID, in CounterOfferInput) (*OfferRound, error) {
+	tx, err := r.pg.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	var status, lastBy string
+	var rounds int
+	var maxRounds *int
+	var negotiable, priceRequest *bool
+	err = tx.QueryRow(ctx, `
+SELECT CASE WHEN o.expires_at <= now() THEN 'EXPIRED' ELSE o.status END,
+       o.last_round_by, o.rounds_count, p.max_counter_rounds, p.is_negotiable, p.price_request
+FROM offers o
+LEFT JOIN payments p ON p.cargo_id = o.cargo_id
+WHERE o.id = $1
+FOR UPDATE OF o`, offerID).Scan(&status, &lastBy, &rounds, &maxRounds, &negotiable, &priceRequest)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrOfferNotPending
+		}
+		return nil, err
+	}
+	round := OfferRound{OfferID: offerID, RoundNo: rounds + 1, AuthorType: in.AuthorType, AuthorID: in.AuthorID,
+		Price: in.Price, Currency: in.Currency, Comment: nullStr(in.Comment)}
+	switch {
+	case status != OfferStatusPending:
+		return nil, ErrOfferNotPending
+	case lastBy == round.Side():
+		return nil, ErrOfferNotYourTurn
+	case negotiable != nil && !*negotiable && (priceRequest == nil || !*priceRequest):
+		return nil, ErrOfferNotNegotiable
+	case maxRounds != nil && rounds-1 >= *maxRounds:
+		return nil, ErrOfferRoundsExceeded
+	}
+	err = tx.QueryRow(ctx, `
+INSERT INTO offer_rounds (offer_id, round_no, author_type, author_id, price, currency, comment)
+VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at`,
+		offerID, round.RoundNo, round.AuthorType, round.AuthorID, round.Price, round.Currency, round.Comment).Scan(&round.ID, &round.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.Exec(ctx, `
+UPDATE offers SET price = $2, currency = $3, rounds_count = $4, last_round_by = $5, updated_at = now()
+WHERE id = $1`, offerID, round.Price, round.Currency, round.RoundNo, round.Side())
+	if err != nil {
+		return nil, err
+	}
+	return &round, tx.Commit(ctx)
+}
+
+// GetOfferRounds returns the negotiation thread of an offer (oldest first).
+func (r *Repo) GetOfferRounds(ctx context.Context, offerID uuid.UUID) ([]OfferRound, error) {
+	rows, err := r.pg.Query(ctx, `
+SELECT id, offer_id, round_no, author_type, author_id, price, currency, comment, created_at
+FROM offer_rounds WHERE offer_id = $1 ORDER BY round_no`, offerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []OfferRound
+	for rows.Next() {
+		var x OfferRound
+		if err := rows.Scan(&x.ID, &x.OfferID, &x.RoundNo, &x.AuthorType, &x.AuthorID, &x.Price, &x.Currency, &x.Comment, &x.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, x)
+	}
+	return list, rows.Err()
 }
 
 func nullStr(s string) *string {
@@ -609,31 +858,143 @@ func nullStr(s string) *string {
 }
 
 // AcceptOffer sets offer status to accepted and cargo status to assigned. Returns cargoID and carrierID (driver).
-func (r *Repo) AcceptOffer(ctx context.Context, offerID uuid.UUID) (cargoID, carrierID uuid.UUID, err error) {
+// side — who accepts (SideShipper or SideDriver): only the proposal of the other side can be accepted.
+func (r *Repo) AcceptOffer(ctx context.Context, offerID uuid.UUID, side string) (cargoID, carrierID uuid.UUID, err error) {
 	tx, err := r.pg.Begin(ctx)
 	if err != nil {
 		return uuid.Nil, uuid.Nil, err
 	}
 	defer tx.Rollback(ctx)
-	err = tx.QueryRow(ctx, "SELECT cargo_id, carrier_id FROM offers WHERE id = $1 AND status = 'PENDING'", offerID).Scan(&cargoID, &carrierID)
+	var lastBy string
+	err = tx.QueryRow(ctx, "SELECT cargo_id, carrier_id, last_round_by FROM offers WHERE id = $1 AND status = 'PENDING' AND (expires_at IS NULL OR expires_at > now()) FOR UPDATE", offerID).Scan(&cargoID, &carrierID, &lastBy)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return uuid.Nil, uuid.Nil, errors.New("cargo: offer not found or not pending")
+			return uuid.Nil, uuid.Nil, ErrOfferNotPending
 		}
 		return uuid.Nil, uuid.Nil, err
 	}
-	_, err = tx.Exec(ctx, "UPDATE offers SET status = 'ACCEPTED' WHERE id = $1", offerID)
+	if lastBy == side {
+		return uuid.Nil, uuid.Nil, ErrOfferNotYourTurn
+	}
+	_, err = tx.Exec(ctx, "UPDATE offers SET status = 'ACCEPTED', updated_at = now() WHERE id = $1", offerID)
 	if err != nil {
 		return uuid.Nil, uuid.Nil, err
 	}
-	_, err = tx.Exec(ctx, "UPDATE cargo SET status = $1, updated_at = now() WHERE id = $2 AND deleted_at IS NULL", StatusAssigned, cargoID)
+	// last_search_status — чтобы при отмене рейса вернуть груз в тот же поиск (SEARCHING_ALL / SEARCHING_COMPANY)
+	_, err = tx.Exec(ctx, "UPDATE cargo SET status = $1, last_search_status = status, updated_at = now() WHERE id = $2 AND deleted_at IS NULL", StatusAssigned, cargoID)
 	if err != nil {
 		return uuid.Nil, uuid.Nil, err
 	}
 	_, _ = tx.Exec(ctx, "UPDATE offers SET status = 'REJECTED' WHERE cargo_id = $1 AND id != $2 AND status = 'PENDING'", cargoID, offerID)
+	// груз предложен по объявлению о свободной машине — машина занята
+	_, err = tx.Exec(ctx, `
+UPDATE truck_listings SET status = 'BOOKED', updated_at = now()
+WHERE id = (SELECT listing_id FROM offers WHERE id = $1) AND status = 'ACTIVE'`, offerID)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
 	return cargoID, carrierID, tx.Commit(ctx)
 }
 
+// WithdrawOffer — водитель отзывает свой PENDING-оффер. Возвращает оффер (уже WITHDRAWN).
+func (r *Repo) WithdrawOffer(ctx context.Context, offerID, carrierID uuid.UUID) (*Offer, error) {
+	tag, err := r.pg.Exec(ctx,
+		"UPDATE offers SET status = 'WITHDRAWN', updated_at = now() WHERE id = $1 AND carrier_id = $2 AND status = 'PENDING'",
+		offerID, carrierID)
+	if err != nil {
+		return nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, ErrOfferNotPending
+	}
+	return r.GetOfferByID(ctx, offerID)
+}
+
+// ExpireOffers переводит просроченные PENDING-офферы в EXPIRED и возвращает их.
+func (r *Repo) ExpireOffers(ctx context.Context) ([]Offer, error) {
+	rows, err := r.pg.Query(ctx, `
+UPDATE offers SET status = 'EXPIRED', updated_at = now()
+WHERE status = 'PENDING' AND expires_at IS NOT NULL AND expires_at <= now()
+RETURNING id, cargo_id, carrier_id, price, currency, expires_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []Offer
+	for rows.Next() {
+		o := Offer{Status: OfferStatusExpired}
+		if err := rows.Scan(&o.ID, &o.CargoID, &o.CarrierID, &o.Price, &o.Currency, &o.ExpiresAt); err != nil {
+			return nil, err
+		}
+		list = append(list, o)
+	}
+	return list, rows.Err()
+}
+
+// ListOffersByCarrier returns offers of a driver across all cargo (newest first). statuses empty — all.
+func (r *Repo) ListOffersByCarrier(ctx context.Context, carrierID uuid.UUID, statuses []string, limit, offset int) ([]Offer, int, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if statuses == nil {
+		statuses = []string{}
+	}
+	var total int
+	if err := r.pg.QueryRow(ctx,
+		`SELECT COUNT(*) FROM offers WHERE carrier_id = $1 AND (cardinality($2::text[]) = 0 OR status = ANY($2))`,
+		carrierID, statuses).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := r.pg.Query(ctx, `
+SELECT id, cargo_id, carrier_id, price, currency, comment, status, COALESCE(rejection_reason, ''), rounds_count, last_round_by, expires_at, created_at, updated_at, listing_id
+FROM offers WHERE carrier_id = $1 AND (cardinality($2::text[]) = 0 OR status = ANY($2))
+ORDER BY created_at DESC
+LIMIT $3 OFFSET $4`, carrierID, statuses, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	var list []Offer
+	for rows.Next() {
+		var o Offer
+		var rejReason string
+		err := rows.Scan(&o.ID, &o.CargoID, &o.CarrierID, &o.Price, &o.Currency, &o.Comment, &o.Status, &rejReason, &o.RoundsCount, &o.LastRoundBy, &o.ExpiresAt, &o.CreatedAt, &o.UpdatedAt, &o.ListingID)
+		if err != nil {
+			return nil, 0, err
+		}
+		if rejReason != "" {
+			o.RejectionReason = &rejReason
+		}
+		list = append(list, o)
+	}
+	return list, total, rows.Err()
+}
+
+// OffersByListing returns offers proposed by shippers for a truck listing (newest first).
+func (r *Repo) OffersByListing(ctx context.Context, listingID uuid.UUID) ([]Offer, error) {
+	rows, err := r.pg.Query(ctx, `
+SELECT id, cargo_id, carrier_id, price, currency, comment, status, COALESCE(rejection_reason, ''), rounds_count, last_round_by, expires_at, created_at, updated_at, listing_id
+FROM offers WHERE listing_id = $1 ORDER BY created_at DESC`, listingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []Offer
+	for rows.Next() {
+		var o Offer
+		var rejReason string
+		err := rows.Scan(&o.ID, &o.CargoID, &o.CarrierID, &o.Price, &o.Currency, &o.Comment, &o.Status, &rejReason, &o.RoundsCount, &o.LastRoundBy, &o.ExpiresAt, &o.CreatedAt, &o.UpdatedAt, &o.ListingID)
+		if err != nil {
+			return nil, err
+		}
+		if rejReason != "" {
+			o.RejectionReason = &rejReason
+		}
+		list = append(list, o)
+	}
+	return list, rows.Err()
+}
+
 // RejectOffer sets offer status to rejected with optional reason (dispatcher).
 func (r *Repo) RejectOffer(ctx context.Context, offerID uuid.UUID, reason string) error {
 	res, err := r.pg.Exec(ctx,
@@ -643,7 +1004,7 @@ func (r *Repo) RejectOffer(ctx context.Context, offerID uuid.UUID, reason string
 		return err
 	}
 	if res.RowsAffected() == 0 {
-		return errors.New("cargo: offer not found or not pending")
+		return ErrOfferNotPending
 	}
 	return nil
 }
@@ -694,7 +1055,8 @@ func (r *Repo) ListPendingModeration(ctx context.Context, limit, offset int) ([]
 	_ = r.pg.QueryRow(ctx, "SELECT count(*) FROM cargo WHERE deleted_at IS NULL AND status = $1", StatusPendingModeration).Scan(&total)
 	q := `SELECT id, weight, volume, ready_enabled, ready_at, load_comment, truck_type,
   temp_min, temp_max, adr_enabled, adr_class, loading_types, requirements, shipment_type, belts_count,
-  documents, contact_name, contact_phone, status, created_at, updated_at, deleted_at, moderation_rejection_reason, created_by_type, created_by_id, company_id
+  documents, contact_name, contact_phone, status, created_at, updated_at, deleted_at, moderation_rejection_reason, created_by_type, created_by_id, company_id,
+  distance_km::float8, duration_minutes
 FROM cargo WHERE deleted_at IS NULL AND status = $1 ORDER BY created_at ASC LIMIT $2 OFFSET $3`
 	rows, err := r.pg.Query(ctx, q, StatusPendingModeration, limit, offset)
 	if err != nil {
@@ -0,0 +1,340 @@
+package cargo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Владелец шаблона груза.
+const (
+	TemplateOwnerCompany    = "COMPANY"
+	TemplateOwnerDispatcher = "DISPATCHER"
+)
+
+// MaxReadyOffsetDays — верхняя граница ready_offset_days расписания.
+const MaxReadyOffsetDays = 60
+
+var (
+	ErrTemplateNameTaken = errors.New("cargo: template name already exists")
+	ErrInvalidSchedule   = errors.New("cargo: invalid schedule")
+)
+
+// Template — сохранённое тело POST /api/cargo (table cargo_templates). Payload хранится как JSON запроса без company_id.
+type Template struct {
+	ID        uuid.UUID
+	OwnerType string
+	OwnerID   uuid.UUID
+	Name      string
+	Payload   json.RawMessage
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Schedule — расписание автоматического создания груза из шаблона (table cargo_schedules).
+// Время — Asia/Tashkent; NextRunAt/LastRunAt — в UTC.
+type Schedule struct {
+	ID              uuid.UUID
+	TemplateID      uuid.UUID
+	Weekdays        []int // 1 = понедельник … 7 = воскресенье
+	RunTime         string
+	ReadyOffsetDays *int
+	Active          bool
+	NextRunAt       *time.Time
+	LastRunAt       *time.Time
+	LastCargoID     *uuid.UUID
+	LastError       *string
+	RunsCount       int
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// ScheduleInput — параметры расписания.
+type ScheduleInput struct {
+	Weekdays        []int
+	RunTime         string // HH:MM
+	ReadyOffsetDays *int
+	Active          bool
+}
+
+// Normalize проверяет параметры и упорядочивает дни недели без повторов.
+func (in *ScheduleInput) Normalize() error {
+	if _, _, ok := ParseRunTime(in.RunTime); !ok {
+		return ErrInvalidSchedule
+	}
+	if in.ReadyOffsetDays != nil && (*in.ReadyOffsetDays < 0 || *in.ReadyOffsetDays > MaxReadyOffsetDays) {
+		return ErrInvalidSchedule
+	}
+	seen := make(map[int]bool, len(in.Weekdays))
+	days := make([]int, 0, len(in.Weekdays))
+	for _, d := range in.Weekdays {
+		if d < 1 || d > 7 {
+			return ErrInvalidSchedule
+		}
+		if !seen[d] {
+			seen[d] = true
+			days = append(days, d)
+		}
+	}
+	if len(days) == 0 {
+		return ErrInvalidSchedule
+	}
+	sort.Ints(days)
+	in.Weekdays = days
+	return nil
+}
+
+// ParseRunTime разбирает время запуска в формате HH:MM.
+func ParseRunTime(s string) (hour, minute int, ok bool) {
+	if len(s) != 5 || s[2] != ':' {
+		return 0, 0, false
+	}
+	for _, i := range []int{0, 1, 3, 4} {
+		if s[i] < '0' || s[i] > '9' {
+			return 0, 0, false
+		}
+	}
+	hour = int(s[0]-'0')*10 + int(s[1]-'0')
+	minute = int(s[3]-'0')*10 + int(s[4]-'0')
+	return hour, minute, hour < 24 && minute < 60
+}
+
+// NextScheduleRun возвращает ближайший момент строго после after, попадающий на один из weekdays в runTime (по loc).
+// Для некорректных параметров — нулевое время.
+func NextScheduleRun(weekdays []int, runTime string, after time.Time, loc *time.Location) time.Time {
+	hour, minute, ok := ParseRunTime(runTime)
+	if !ok || len(weekdays) == 0 {
+		return time.Time{}
+	}
+	local := after.In(loc)
+	for i := 0; i <= 7; i++ {
+		day := local.AddDate(0, 0, i)
+		candidate := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, loc)
+		if !candidate.After(after) {
+			continue
+		}
+		wd := int(candidate.Weekday())
+		if wd == 0 {
+			wd = 7
+		}
+		for _, d := range weekdays {
+			if d == wd {
+				return candidate
+			}
+		}
+	}
+	return time.Time{}
+}
+
+const templateColumns = `id, owner_type, owner_id, name, payload, created_at, updated_at`
+
+func scanTemplate(row pgx.Row) (*Template, error) {
+	var t Template
+	if err := row.Scan(&t.ID, &t.OwnerType, &t.OwnerID, &t.Name, &t.Payload, &t.CreatedAt, &t.UpdatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+const scheduleColumns = `id, template_id, weekdays, run_time, ready_offset_days, active, next_run_at, last_run_at,
+  last_cargo_id, last_error, runs_count, created_at, updated_at`
+
+func scanSchedule(row pgx.Row) (*Schedule, error) {
+	var s Schedule
+	err := row.Scan(&s.ID, &s.TemplateID, &s.Weekdays, &s.RunTime, &s.ReadyOffsetDays, &s.Active, &s.NextRunAt, &s.LastRunAt,
+		&s.LastCargoID, &s.LastError, &s.RunsCount, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &s, nil
+}
+
+func templateNameErr(err error) error {
+	var e *pgconn.PgError
+	if errors.As(err, &e) && e.SQLState() == "23505" {
+		return ErrTemplateNameTaken
+	}
+	return err
+}
+
+// CreateTemplate сохраняет шаблон владельца.
+func (r *Repo) CreateTemplate(ctx context.Context, ownerType string, ownerID uuid.UUID, name string, payload json.RawMessage) (*Template, error) {
+	t, err := scanTemplate(r.pg.QueryRow(ctx, `
+INSERT INTO cargo_templates (owner_type, owner_id, name, payload) VALUES ($1, $2, $3, $4)
+RETURNING `+templateColumns, ownerType, ownerID, name, payload))
+	if err != nil {
+		return nil, templateNameErr(err)
+	}
+	return t, nil
+}
+
+// UpdateTemplate заменяет имя и тело шаблона. Удалённый шаблон — (nil, nil).
+func (r *Repo) UpdateTemplate(ctx context.Context, id uuid.UUID, name string, payload json.RawMessage) (*Template, error) {
+	t, err := scanTemplate(r.pg.QueryRow(ctx, `
+UPDATE cargo_templates SET name = $2, payload = $3, updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING `+templateColumns, id, name, payload))
+	if err != nil {
+		return nil, templateNameErr(err)
+	}
+	return t, nil
+}
+
+// GetTemplate возвращает шаблон (без удалённых). Если нет — (nil, nil).
+func (r *Repo) GetTemplate(ctx context.Context, id uuid.UUID) (*Template, error) {
+	return scanTemplate(r.pg.QueryRow(ctx, `SELECT `+templateColumns+` FROM cargo_templates WHERE id = $1 AND deleted_at IS NULL`, id))
+}
+
+// ListTemplates возвращает шаблоны владельца по имени и общее число.
+func (r *Repo) ListTemplates(ctx context.Context, ownerType string, ownerID uuid.UUID, limit, offset int) ([]Template, int, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	var total int
+	if err := r.pg.QueryRow(ctx, `SELECT COUNT(*) FROM cargo_templates WHERE owner_type = $1 AND owner_id = $2 AND deleted_at IS NULL`,
+		ownerType, ownerID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := r.pg.Query(ctx, `SELECT `+templateColumns+` FROM cargo_templates
+WHERE owner_type = $1 AND owner_id = $2 AND deleted_at IS NULL
+ORDER BY lower(name) LIMIT $3 OFFSET $4`, ownerType, ownerID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	var list []Template
+	for rows.Next() {
+		t, err := scanTemplate(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		list = append(list, *t)
+	}
+	return list, total, rows.Err()
+}
+
+// DeleteTemplate мягко удаляет шаблон и выключает его расписания.
+func (r *Repo) DeleteTemplate(ctx context.Context, id uuid.UUID) error {
+	tx, err := r.pg.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	if _, err := tx.Exec(ctx, `UPDATE cargo_templates SET deleted_at = now(), updated_at = now() WHERE id = $1 AND deleted_at IS NULL`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE cargo_schedules SET active = false, next_run_at = NULL, updated_at = now() WHERE template_id = $1`, id); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// CreateSchedule добавляет расписание к шаблону. nextRun — первый запуск (nil для неактивного).
+func (r *Repo) CreateSchedule(ctx context.Context, templateID uuid.UUID, in ScheduleInput, nextRun *time.Time) (*Schedule, error) {
+	return scanSchedule(r.pg.QueryRow(ctx, `
+INSERT INTO cargo_schedules (template_id, weekdays, run_time, ready_offset_days, active, next_run_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING `+scheduleColumns, templateID, in.Weekdays, in.RunTime, in.ReadyOffsetDays, in.Active, utcPtr(nextRun)))
+}
+
+// UpdateSchedule заменяет параметры расписания и следующий запуск. Если нет — (nil, nil).
+func (r *Repo) UpdateSchedule(ctx context.Context, id uuid.UUID, in ScheduleInput, nextRun *time.Time) (*Schedule, error) {
+	return scanSchedule(r.pg.QueryRow(ctx, `
+UPDATE cargo_schedules SET weekdays = $2, run_time = $3, ready_offset_days = $4, active = $5, next_run_at = $6, updated_at = now()
+WHERE id = $1
+RETURNING `+scheduleColumns, id, in.Weekdays, in.RunTime, in.ReadyOffsetDays, in.Active, utcPtr(nextRun)))
+}
+
+// GetSchedule возвращает расписание. Если нет — (nil, nil).
+func (r *Repo) GetSchedule(ctx context.Context, id uuid.UUID) (*Schedule, error) {
+	return scanSchedule(r.pg.QueryRow(ctx, `SELECT `+scheduleColumns+` FROM cargo_schedules WHERE id = $1`, id))
+}
+
+// ListSchedules возвращает расписания шаблона.
+func (r *Repo) ListSchedules(ctx context.Context, templateID uuid.UUID) ([]Schedule, error) {
+	return r.querySchedules(ctx, `SELECT `+scheduleColumns+` FROM cargo_schedules WHERE template_id = $1 ORDER BY created_at`, templateID)
+}
+
+// DeleteSchedule удаляет расписание (созданные грузы остаются).
+func (r *Repo) DeleteSchedule(ctx context.Context, id uuid.UUID) error {
+	_, err := r.pg.Exec(ctx, `DELETE FROM cargo_schedules WHERE id = $1`, id)
+	return err
+}
+
+// DueSchedules возвращает активные расписания живых шаблонов, у которых next_run_at <= now.
+func (r *Repo) DueSchedules(ctx context.Context, now time.Time) ([]Schedule, error) {
+	return r.querySchedules(ctx, `SELECT `+scheduleColumns+` FROM cargo_schedules
+WHERE active AND next_run_at <= $1
+  AND EXISTS (SELECT 1 FROM cargo_templates t WHERE t.id = template_id AND t.deleted_at IS NULL)
+ORDER BY next_run_at LIMIT 100`, now.UTC())
+}
+
+// ClaimScheduleRun переносит next_run_at с prev на next; false — запуск уже забран другим экземпляром.
+func (r *Repo) ClaimScheduleRun(ctx context.Context, id uuid.UUID, prev, next time.Time) (bool, error) {
+	var nextArg *time.Time
+	if !next.IsZero() {
+		nextArg = &next
+	}
+	tag, err := r.pg.Exec(ctx, `
+UPDATE cargo_schedules SET next_run_at = $3, last_run_at = $4, updated_at = now()
+WHERE id = $1 AND active AND next_run_at = $2`, id, prev.UTC(), utcPtr(nextArg), time.Now().UTC())
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// RecordScheduleRun фиксирует результат запуска: созданный груз или текст ошибки.
+func (r *Repo) RecordScheduleRun(ctx context.Context, id uuid.UUID, cargoID *uuid.UUID, runErr error) error {
+	var errText *string
+	if runErr != nil {
+		s := runErr.Error()
+		errText = &s
+	}
+	_, err := r.pg.Exec(ctx, `
+UPDATE cargo_schedules
+SET last_cargo_id = COALESCE($2, last_cargo_id), last_error = $3,
+    runs_count = runs_count + CASE WHEN $2::uuid IS NULL THEN 0 ELSE 1 END, updated_at = now()
+WHERE id = $1`, id, cargoID, errText)
+	return err
+}
+
+func (r *Repo) querySchedules(ctx context.Context, q string, args ...any) ([]Schedule, error) {
+	rows, err := r.pg.Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []Schedule
+	for rows.Next() {
+		s, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *s)
+	}
+	return list, rows.Err()
+}
+
+func utcPtr(t *time.Time) *time.Time {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
@@ -0,0 +1,60 @@
+package cargo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextScheduleRun(t *testing.T) {
+	loc := time.FixedZone("UTC+5", 5*60*60)
+	// 2026-10-19 — понедельник
+	monday := time.Date(2026, 10, 19, 8, 0, 0, 0, loc)
+
+	cases := []struct {
+		name     string
+		weekdays []int
+		runTime  string
+		after    time.Time
+		want     time.Time
+	}{
+		{"same day later", []int{1}, "09:30", monday, time.Date(2026, 10, 19, 9, 30, 0, 0, loc)},
+		{"same day passed → next week", []int{1}, "07:00", monday, time.Date(2026, 10, 26, 7, 0, 0, 0, loc)},
+		{"exact moment is not included", []int{1}, "08:00", monday, time.Date(2026, 10, 26, 8, 0, 0, 0, loc)},
+		{"next listed weekday", []int{3, 5}, "06:00", monday, time.Date(2026, 10, 21, 6, 0, 0, 0, loc)},
+		{"sunday is 7", []int{7}, "23:59", monday, time.Date(2026, 10, 25, 23, 59, 0, 0, loc)},
+		{"after given in another zone", []int{2}, "00:30", time.Date(2026, 10, 19, 19, 0, 0, 0, time.UTC), time.Date(2026, 10, 20, 0, 30, 0, 0, loc)},
+		{"invalid run time", []int{1}, "24:00", monday, time.Time{}},
+		{"no weekdays", nil, "10:00", monday, time.Time{}},
+	}
+	for _, tc := range cases {
+		got := NextScheduleRun(tc.weekdays, tc.runTime, tc.after, loc)
+		if !got.Equal(tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestScheduleInputNormalize(t *testing.T) {
+	in := ScheduleInput{Weekdays: []int{5, 1, 5, 3}, RunTime: "07:15"}
+	if err := in.Normalize(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(in.Weekdays) != 3 || in.Weekdays[0] != 1 || in.Weekdays[1] != 3 || in.Weekdays[2] != 5 {
+		t.Fatalf("weekdays not normalized: %v", in.Weekdays)
+	}
+
+	offset := MaxReadyOffsetDays + 1
+	bad := []ScheduleInput{
+		{Weekdays: nil, RunTime: "07:15"},
+		{Weekdays: []int{0}, RunTime: "07:15"},
+		{Weekdays: []int{8}, RunTime: "07:15"},
+		{Weekdays: []int{1}, RunTime: "7:15"},
+		{Weekdays: []int{1}, RunTime: "07:60"},
+		{Weekdays: []int{1}, RunTime: "07:15", ReadyOffsetDays: &offset},
+	}
+	for i, b := range bad {
+		if err := b.Normalize(); err == nil {
+			t.Errorf("case %d: expected error for %+v", i, b)
+		}
+	}
+}
@@ -2,8 +2,10 @@ package companies
 
 import (
 	"context"
+	"errors"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -126,6 +128,24 @@ func (r *Repo) SetOwner(ctx context.Context, companyID, ownerID uuid.UUID) error
 	return err
 }
 
+// DisplayCurrency returns company's display currency preference ("" — not set).
+func (r *Repo) DisplayCurrency(ctx context.Context, id uuid.UUID) (string, error) {
+	const q = `SELECT COALESCE(display_currency, '') FROM companies WHERE id = $1`
+	var cur string
+	err := r.pg.QueryRow(ctx, q, id).Scan(&cur)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	return cur, err
+}
+
+// SetDisplayCurrency sets company's display currency preference ("" — reset).
+func (r *Repo) SetDisplayCurrency(ctx context.Context, id uuid.UUID, cur string) error {
+	const q = `UPDATE companies SET display_currency = NULLIF($2, ''), updated_at = now() WHERE id = $1`
+	_, err := r.pg.Exec(ctx, q, id, cur)
+	return err
+}
+
 // CreateWithOwnerDispatcher creates a company owned by a freelance dispatcher (Broker). Used for "create own company" flow.
 func (r *Repo) CreateWithOwnerDispatcher(ctx context.Context, name string, ownerDispatcherID uuid.UUID) (uuid.UUID, error) {
 	const q = `
@@ -168,6 +188,27 @@ ORDER BY (c.owner_dispatcher_id = $1) DESC, c.name`
 	return list, rows.Err()
 }
 
+// UserIDs returns app users of the company: владелец и пользователи с ролью (для онлайн-оповещений).
+func (r *Repo) UserIDs(ctx context.Context, companyID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := r.pg.Query(ctx, `
+SELECT owner_id FROM companies WHERE id = $1 AND owner_id IS NOT NULL AND deleted_at IS NULL
+UNION
+SELECT user_id FROM user_company_roles WHERE company_id = $1`, companyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
 // ListForUser returns companies where user is owner or has a role (for GET /auth/companies).
 func (r *Repo) ListForUser(ctx context.Context, userID uuid.UUID) ([]CompanyWithRole, error) {
 	const q = `
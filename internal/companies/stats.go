@@ -0,0 +1,259 @@
+package companies
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"sarbonNew/internal/currency"
+)
+
+// Order outcome and company role in company_order_stats.
+const (
+	OutcomeCompleted = "COMPLETED"
+	OutcomeCancelled = "CANCELLED"
+
+	RoleOwner   = "OWNER"   // компания-владелец груза (cargo.company_id)
+	RoleCarrier = "CARRIER" // компания водителя рейса (drivers.company_id)
+)
+
+// RevenueCurrency — валюта companies.total_revenue: суммы пересчитываются в базовую валюту по курсу на дату заказа.
+const RevenueCurrency = currency.Base
+
+// Stats periods for GET /v1/companies/:companyId/stats.
+const (
+	PeriodDay   = "day"
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+)
+
+var ErrInvalidPeriod = errors.New("invalid stats period")
+
+// upsertOrderStatsSQL пишет исход заказа для каждой компании-участника: владелец груза и компания водителя.
+// По каждому грузу берётся последний рейс; груз владельца учитывается и без рейса (отменён до назначения) — сумма
+// тогда из оплаты груза. $1 = cargo_id (NULL — все грузы, для backfill). Исход: рейс COMPLETED → COMPLETED;
+// груз CANCELLED → CANCELLED; без рейса — по статусу груза. Отменённый рейс исхода не даёт: груз возвращается в поиск.
+const upsertOrderStatsSQL = `
+INSERT INTO company_order_stats (company_id, cargo_id, trip_id, role, outcome, amount, currency, amount_base, occurred_at)
+SELECT x.company_id, x.cargo_id, x.trip_id, x.role, x.outcome, x.amount, x.currency,
+       ROUND((x.amount * currency_rate_to_base(x.currency, x.occurred_at::date))::numeric, 2), x.occurred_at
+FROM (
+  SELECT o.company_id, o.cargo_id, o.trip_id, o.role, o.amount, o.currency, o.occurred_at,
+         CASE WHEN o.trip_status = 'COMPLETED' THEN 'COMPLETED'
+              WHEN o.cargo_status = 'CANCELLED' THEN 'CANCELLED'
+              WHEN o.trip_id IS NULL AND o.cargo_status = 'COMPLETED' THEN 'COMPLETED'
+         END AS outcome
+  FROM (
+    SELECT c.company_id, c.id AS cargo_id, t.id AS trip_id, 'OWNER' AS role,
+           COALESCE(t.agreed_price, o.price, p.total_amount, 0) AS amount,
+           UPPER(COALESCE(t.agreed_currency, o.currency, p.total_currency, '` + RevenueCurrency + `')) AS currency,
+           COALESCE(t.updated_at, c.updated_at) AS occurred_at,
+           t.status AS trip_status, c.status AS cargo_status
+    FROM cargo c
+    LEFT JOIN LATERAL (SELECT t2.* FROM trips t2 WHERE t2.cargo_id = c.id ORDER BY t2.created_at DESC LIMIT 1) t ON true
+    LEFT JOIN offers o ON o.id = t.offer_id
+    LEFT JOIN payments p ON p.cargo_id = c.id
+    WHERE c.company_id IS NOT NULL AND ($1::uuid IS NULL OR c.id = $1)
+  UNION ALL
+    SELECT d.company_id, c.id, t.id, 'CARRIER',
+           COALESCE(t.agreed_price, o.price), UPPER(COALESCE(t.agreed_currency, o.currency)), t.updated_at,
+           t.status, c.status
+    FROM trips t
+    JOIN cargo c ON c.id = t.cargo_id
+    JOIN offers o ON o.id = t.offer_id
+    JOIN drivers d ON d.id = t.driver_id
+    WHERE d.company_id IS NOT NULL AND d.company_id IS DISTINCT FROM c.company_id
+      AND t.id = (SELECT t2.id FROM trips t2 WHERE t2.cargo_id = c.id ORDER BY t2.created_at DESC LIMIT 1)
+      AND ($1::uuid IS NULL OR c.id = $1)
+  ) o
+) x
+JOIN companies co ON co.id = x.company_id
+WHERE x.outcome IS NOT NULL
+ON CONFLICT (company_id, cargo_id) DO UPDATE
+SET trip_id = EXCLUDED.trip_id, outcome = EXCLUDED.outcome, amount = EXCLUDED.amount,
+    currency = EXCLUDED.currency, amount_base = EXCLUDED.amount_base, occurred_at = EXCLUDED.occurred_at
+RETURNING company_id`
+
+// recomputeCountersSQL пересчитывает companies.completed_orders, cancelled_orders, total_revenue из company_order_stats.
+// total_revenue — сумма amount_base (в RevenueCurrency). $1 = company ids (NULL — все компании).
+const recomputeCountersSQL = `
+UPDATE companies co
+SET completed_orders = (SELECT COUNT(*) FROM company_order_stats s WHERE s.company_id = co.id AND s.outcome = 'COMPLETED'),
+    cancelled_orders = (SELECT COUNT(*) FROM company_order_stats s WHERE s.company_id = co.id AND s.outcome = 'CANCELLED'),
+    total_revenue = (SELECT COALESCE(SUM(s.amount_base), 0) FROM company_order_stats s
+                     WHERE s.company_id = co.id AND s.outcome = 'COMPLETED'),
+    updated_at = now()
+WHERE $1::uuid[] IS NULL OR co.id = ANY($1)`
+
+// RecordCargoOutcome фиксирует исход заказа по грузу (после завершения рейса или отмены груза)
+// и обновляет счётчики затронутых компаний. Груз с активным рейсом или в поиске не учитывается.
+func (r *Repo) RecordCargoOutcome(ctx context.Context, cargoID uuid.UUID) error {
+	tx, err := r.pg.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	rows, err := tx.Query(ctx, upsertOrderStatsSQL, cargoID)
+	if err != nil {
+		return err
+	}
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(ids) > 0 {
+		if _, err := tx.Exec(ctx, recomputeCountersSQL, ids); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// BackfillOrderStats пересобирает company_order_stats по всем грузам и пересчитывает счётчики всех компаний.
+// Возвращает число записей в журнале.
+func (r *Repo) BackfillOrderStats(ctx context.Context) (int64, error) {
+	tx, err := r.pg.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, `DELETE FROM company_order_stats`); err != nil {
+		return 0, err
+	}
+	tag, err := tx.Exec(ctx, upsertOrderStatsSQL, nil)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(ctx, recomputeCountersSQL, nil); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// Counters — текущие счётчики компании (companies.completed_orders, cancelled_orders, total_revenue).
+type Counters struct {
+	CompletedOrders int
+	CancelledOrders int
+	TotalRevenue    float64
+}
+
+// CurrencyAmount — сумма выручки в одной валюте.
+type CurrencyAmount struct {
+	Currency string
+	Amount   float64
+}
+
+// PeriodStats — показатели компании за один период (день/неделя/месяц).
+type PeriodStats struct {
+	PeriodStart     time.Time
+	CompletedOrders int
+	CancelledOrders int
+	Revenue         []CurrencyAmount
+	RevenueBase     float64 // выручка периода в RevenueCurrency
+}
+
+// Stats — ответ для GET /v1/companies/:companyId/stats.
+type Stats struct {
+	Counters Counters
+	Revenue  []CurrencyAmount // выручка за всё время по валютам
+	Periods  []PeriodStats
+}
+
+// ValidPeriod reports whether p is a supported stats period.
+func ValidPeriod(p string) bool {
+	return p == PeriodDay || p == PeriodWeek || p == PeriodMonth
+}
+
+// GetStats возвращает счётчики компании, выручку по валютам и разбивку по периодам в [from, to).
+// Если компании нет — (nil, nil).
+func (r *Repo) GetStats(ctx context.Context, companyID uuid.UUID, period string, from, to time.Time) (*Stats, error) {
+	if !ValidPeriod(period) {
+		return nil, ErrInvalidPeriod
+	}
+	var st Stats
+	err := r.pg.QueryRow(ctx,
+		`SELECT completed_orders, cancelled_orders, total_revenue::float8 FROM companies WHERE id = $1 AND deleted_at IS NULL`,
+		companyID).Scan(&st.Counters.CompletedOrders, &st.Counters.CancelledOrders, &st.Counters.TotalRevenue)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	rows, err := r.pg.Query(ctx, `
+SELECT currency, SUM(amount)::float8 FROM company_order_stats
+WHERE company_id = $1 AND outcome = 'COMPLETED'
+GROUP BY currency ORDER BY currency`, companyID)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var a CurrencyAmount
+		if err := rows.Scan(&a.Currency, &a.Amount); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		st.Revenue = append(st.Revenue, a)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = r.pg.Query(ctx, `
+SELECT date_trunc($2, occurred_at) AS p, currency,
+       COUNT(*) FILTER (WHERE outcome = 'COMPLETED'),
+       COUNT(*) FILTER (WHERE outcome = 'CANCELLED'),
+       COALESCE(SUM(amount) FILTER (WHERE outcome = 'COMPLETED'), 0)::float8,
+       COALESCE(SUM(amount_base) FILTER (WHERE outcome = 'COMPLETED'), 0)::float8
+FROM company_order_stats
+WHERE company_id = $1 AND occurred_at >= $3 AND occurred_at < $4
+GROUP BY p, currency
+ORDER BY p, currency`, companyID, period, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			start               time.Time
+			cur                 string
+			completed, canceled int
+			amount, amountBase  float64
+		)
+		if err := rows.Scan(&start, &cur, &completed, &canceled, &amount, &amountBase); err != nil {
+			return nil, err
+		}
+		n := len(st.Periods)
+		if n == 0 || !st.Periods[n-1].PeriodStart.Equal(start) {
+			st.Periods = append(st.Periods, PeriodStats{PeriodStart: start})
+			n++
+		}
+		ps := &st.Periods[n-1]
+		ps.CompletedOrders += completed
+		ps.CancelledOrders += canceled
+		ps.RevenueBase += amountBase
+		if completed > 0 {
+			ps.Revenue = append(ps.Revenue, CurrencyAmount{Currency: cur, Amount: amount})
+		}
+	}
+	return &st, rows.Err()
+}
@@ -0,0 +1,62 @@
+package companies
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"sarbonNew/internal/appusers"
+)
+
+// TestRecordCargoOutcome_CargoWithoutTrip: груз компании, отменённый до назначения рейса, попадает в журнал
+// как CANCELLED с суммой из оплаты груза.
+func TestRecordCargoOutcome_CargoWithoutTrip(t *testing.T) {
+	pool := testPool(t)
+	defer pool.Close()
+	ctx := context.Background()
+	repo := NewRepo(pool)
+
+	owner, err := appusers.NewRepo(pool).Create(ctx, "+7997"+uuid.New().String()[:8], "hash", nil, nil, nil, "OWNER")
+	if err != nil {
+		t.Fatalf("create company user: %v", err)
+	}
+	companyID, err := repo.CreateByOwner(ctx, CreateByOwnerParams{
+		Name: "Stats Company " + uuid.New().String(), Type: "Shipper", OwnerID: uuid.MustParse(owner.ID),
+	})
+	if err != nil {
+		t.Fatalf("CreateByOwner: %v", err)
+	}
+	var cargoID uuid.UUID
+	if err := pool.QueryRow(ctx, `
+INSERT INTO cargo (weight, volume, truck_type, status, company_id) VALUES (10, 20, 'TENT', 'CANCELLED', $1) RETURNING id`,
+		companyID).Scan(&cargoID); err != nil {
+		t.Fatalf("insert cargo: %v", err)
+	}
+	t.Cleanup(func() { _, _ = pool.Exec(context.Background(), `DELETE FROM cargo WHERE id = $1`, cargoID) })
+	if _, err := pool.Exec(ctx, `INSERT INTO payments (cargo_id, total_amount, total_currency) VALUES ($1, 700, 'usd')`, cargoID); err != nil {
+		t.Fatalf("insert payment: %v", err)
+	}
+
+	if err := repo.RecordCargoOutcome(ctx, cargoID); err != nil {
+		t.Fatalf("RecordCargoOutcome: %v", err)
+	}
+	var outcome, currency string
+	var amount float64
+	var tripID *uuid.UUID
+	if err := pool.QueryRow(ctx, `
+SELECT outcome, trip_id, amount::float8, currency FROM company_order_stats WHERE company_id = $1 AND cargo_id = $2`,
+		companyID, cargoID).Scan(&outcome, &tripID, &amount, &currency); err != nil {
+		t.Fatalf("order stats row: %v", err)
+	}
+	if outcome != OutcomeCancelled || tripID != nil || amount != 700 || currency != "USD" {
+		t.Errorf("row: outcome %s trip %v amount %v %s", outcome, tripID, amount, currency)
+	}
+	var cancelled int
+	if err := pool.QueryRow(ctx, `SELECT cancelled_orders FROM companies WHERE id = $1`, companyID).Scan(&cancelled); err != nil {
+		t.Fatalf("companies: %v", err)
+	}
+	if cancelled != 1 {
+		t.Errorf("cancelled_orders: got %d, want 1", cancelled)
+	}
+}
@@ -37,3 +37,8 @@ func CanRemove(actorRole, targetRoleName string) bool {
 		return false
 	}
 }
+
+// CanEditCompany returns true if role can change company-wide settings (e.g. display currency).
+func CanEditCompany(actorRole string) bool {
+	return actorRole == "Owner" || actorRole == "CEO"
+}
@@ -6,6 +6,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"sarbonNew/internal/routing"
 )
 
 type Config struct {
@@ -40,6 +42,47 @@ type Config struct {
 
 	// FreelanceDispatcherCargoLimit — макс. число грузов на одного фриланс-диспетчера (0 = без лимита)
 	FreelanceDispatcherCargoLimit int
+
+	// Оценка маршрута (internal/routing): коэффициент дорог, скорости по типу кузова, таможня, режим труда и отдыха
+	RouteRoadFactor        float64
+	RouteDefaultSpeedKmh   float64
+	RouteTruckSpeeds       map[string]float64
+	RouteCustomsDelay      time.Duration
+	RouteDailyDrivingLimit time.Duration
+	RouteDailyRest         time.Duration
+
+	// Офферы: срок действия по умолчанию (0 = бессрочно, если водитель не указал valid_hours) и период фоновой проверки истечения
+	OfferDefaultValidity  time.Duration
+	OfferExpiryCheckEvery time.Duration
+
+	// AuctionCloseCheckEvery — период фоновой проверки аукционов с истёкшим сроком (0 = выключено)
+	AuctionCloseCheckEvery time.Duration
+
+	// CargoScheduleCheckEvery — период запуска расписаний создания грузов из шаблонов (0 = выключено)
+	CargoScheduleCheckEvery time.Duration
+
+	// Выгрузки отчётов: период обработки очереди фоновых выгрузок (0 = выключено) и срок хранения готового файла
+	ReportExportCheckEvery time.Duration
+	ReportExportTTL        time.Duration
+
+	// PODDisputeWindow — сколько после подтверждения доставки (ePOD) грузоотправитель может открыть спор
+	PODDisputeWindow time.Duration
+	// DeliveryPINMaxAttempts — сколько раз водитель может ошибиться с PIN получателя, после чего PIN блокируется до перевыпуска
+	DeliveryPINMaxAttempts int
+	// TrackingLinkTTL — срок действия публичной ссылки отслеживания рейса по умолчанию; TrackingLinkMaxTTL — максимум по запросу
+	TrackingLinkTTL    time.Duration
+	TrackingLinkMaxTTL time.Duration
+	// TrackingLinkBaseURL — адрес страницы отслеживания на фронтенде (к нему добавляется /<token>); пусто — ссылка на /public/track
+	TrackingLinkBaseURL string
+	// InvoicePrepaymentDays — срок оплаты предоплаты по счёту (дней после выставления); InvoicePaymentTermDays — срок оплаты
+	// остатка после доставки для AFTER_INVOICE и прочих условий; InvoiceDeferredDays — для DEFERRED (отсрочка)
+	InvoicePrepaymentDays  int
+	InvoicePaymentTermDays int
+	InvoiceDeferredDays    int
+	// InvoiceCheckEvery — период выставления счетов по рейсам и проверки просрочки (0 = выключено)
+	InvoiceCheckEvery time.Duration
+	// LedgerCheckEvery — период проводки завершённых рейсов: заработок водителя и комиссия диспетчера (0 = выключено)
+	LedgerCheckEvery time.Duration
 }
 
 func LoadFromEnv() (Config, error) {
@@ -85,6 +128,34 @@ func LoadFromEnv() (Config, error) {
 
 	cfg.FreelanceDispatcherCargoLimit = mustAtoi(getEnv("FREELANCE_DISPATCHER_CARGO_LIMIT", "0"))
 
+	cfg.RouteRoadFactor = mustFloat(getEnv("ROUTE_ROAD_FACTOR", "1.25"))
+	cfg.RouteDefaultSpeedKmh = mustFloat(getEnv("ROUTE_DEFAULT_SPEED_KMH", "55"))
+	speeds, err := routing.ParseTruckSpeeds(os.Getenv("ROUTE_TRUCK_SPEEDS"))
+	if err != nil {
+		return Config{}, fmt.Errorf("ROUTE_TRUCK_SPEEDS: %w", err)
+	}
+	cfg.RouteTruckSpeeds = speeds
+	cfg.RouteCustomsDelay = time.Duration(mustAtoi(getEnv("ROUTE_CUSTOMS_DELAY_MINUTES", "360"))) * time.Minute
+	cfg.RouteDailyDrivingLimit = time.Duration(mustAtoi(getEnv("ROUTE_DAILY_DRIVING_MINUTES", "540"))) * time.Minute
+	cfg.RouteDailyRest = time.Duration(mustAtoi(getEnv("ROUTE_DAILY_REST_MINUTES", "660"))) * time.Minute
+
+	cfg.OfferDefaultValidity = time.Duration(mustAtoi(getEnv("OFFER_DEFAULT_VALID_HOURS", "0"))) * time.Hour
+	cfg.OfferExpiryCheckEvery = time.Duration(mustAtoi(getEnv("OFFER_EXPIRY_CHECK_SECONDS", "60"))) * time.Second
+	cfg.AuctionCloseCheckEvery = time.Duration(mustAtoi(getEnv("AUCTION_CLOSE_CHECK_SECONDS", "15"))) * time.Second
+	cfg.CargoScheduleCheckEvery = time.Duration(mustAtoi(getEnv("CARGO_SCHEDULE_CHECK_SECONDS", "60"))) * time.Second
+	cfg.ReportExportCheckEvery = time.Duration(mustAtoi(getEnv("REPORT_EXPORT_CHECK_SECONDS", "10"))) * time.Second
+	cfg.ReportExportTTL = time.Duration(mustAtoi(getEnv("REPORT_EXPORT_TTL_HOURS", "24"))) * time.Hour
+	cfg.PODDisputeWindow = time.Duration(mustAtoi(getEnv("POD_DISPUTE_WINDOW_HOURS", "48"))) * time.Hour
+	cfg.DeliveryPINMaxAttempts = mustAtoi(getEnv("DELIVERY_PIN_MAX_ATTEMPTS", "5"))
+	cfg.TrackingLinkTTL = time.Duration(mustAtoi(getEnv("TRACKING_LINK_TTL_HOURS", "72"))) * time.Hour
+	cfg.TrackingLinkMaxTTL = time.Duration(mustAtoi(getEnv("TRACKING_LINK_MAX_TTL_HOURS", "720"))) * time.Hour
+	cfg.TrackingLinkBaseURL = strings.TrimRight(getEnv("TRACKING_LINK_BASE_URL", ""), "/")
+	cfg.InvoicePrepaymentDays = mustAtoi(getEnv("INVOICE_PREPAYMENT_DAYS", "2"))
+	cfg.InvoicePaymentTermDays = mustAtoi(getEnv("INVOICE_PAYMENT_TERM_DAYS", "5"))
+	cfg.InvoiceDeferredDays = mustAtoi(getEnv("INVOICE_DEFERRED_DAYS", "30"))
+	cfg.InvoiceCheckEvery = time.Duration(mustAtoi(getEnv("INVOICE_CHECK_SECONDS", "300"))) * time.Second
+	cfg.LedgerCheckEvery = time.Duration(mustAtoi(getEnv("LEDGER_CHECK_SECONDS", "300"))) * time.Second
+
 	return cfg, nil
 }
 
@@ -103,6 +174,14 @@ func mustAtoi(s string) int {
 	return n
 }
 
+func mustFloat(s string) float64 {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
 func mustBool(s string) bool {
 	b, err := strconv.ParseBool(strings.TrimSpace(s))
 	if err != nil {
@@ -0,0 +1,147 @@
+// Package currency — курсы валют и пересчёт сумм между валютами через базовую валюту.
+package currency
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Base — базовая валюта отчётности; курсы хранятся как число единиц Base за 1 единицу валюты.
+const Base = "USD"
+
+// Источник курса.
+const (
+	SourceManual = "MANUAL"
+	SourceImport = "IMPORT"
+)
+
+// Rate model (table currency_rates).
+type Rate struct {
+	ID        uuid.UUID
+	Currency  string
+	RateDate  time.Time
+	Rate      float64
+	Source    string
+	UpdatedBy *uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Converter пересчитывает суммы по набору курсов к базовой валюте.
+type Converter struct {
+	toBase map[string]float64
+}
+
+// NewConverter создаёт конвертер; rates — курсы к Base (Base = 1 добавляется автоматически).
+func NewConverter(rates map[string]float64) *Converter {
+	m := make(map[string]float64, len(rates)+1)
+	for k, v := range rates {
+		if v > 0 {
+			m[strings.ToUpper(k)] = v
+		}
+	}
+	m[Base] = 1
+	return &Converter{toBase: m}
+}
+
+// Convert пересчитывает amount из from в to. ok=false, если для одной из валют нет курса.
+func (c *Converter) Convert(amount float64, from, to string) (float64, bool) {
+	from, to = strings.ToUpper(strings.TrimSpace(from)), strings.ToUpper(strings.TrimSpace(to))
+	if from == to {
+		return amount, true
+	}
+	rf, ok1 := c.toBase[from]
+	rt, ok2 := c.toBase[to]
+	if !ok1 || !ok2 {
+		return 0, false
+	}
+	return math.Round(amount*rf/rt*100) / 100, true
+}
+
+// ToBase пересчитывает amount в базовую валюту.
+func (c *Converter) ToBase(amount float64, from string) (float64, bool) {
+	return c.Convert(amount, from, Base)
+}
+
+// ImportRow — строка файла импорта курсов.
+type ImportRow struct {
+	Line     int
+	Currency string
+	Date     time.Time
+	Rate     float64
+}
+
+var ErrEmptyImport = errors.New("no rates in file")
+
+// ParseCSV разбирает файл курсов: заголовок date,currency,rate (порядок колонок любой; разделитель , или ;).
+// Дата в формате YYYY-MM-DD; rate — единиц Base за 1 единицу валюты.
+func ParseCSV(r io.Reader) ([]ImportRow, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	text := strings.TrimPrefix(string(data), "\ufeff")
+	cr := csv.NewReader(strings.NewReader(text))
+	if first, _, _ := strings.Cut(text, "\n"); strings.Count(first, ";") > strings.Count(first, ",") {
+		cr.Comma = ';'
+	}
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err != nil {
+		return nil, ErrEmptyImport
+	}
+	col := map[string]int{}
+	for i, h := range header {
+		col[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, k := range []string{"date", "currency", "rate"} {
+		if _, ok := col[k]; !ok {
+			return nil, fmt.Errorf("missing column %q", k)
+		}
+	}
+	var out []ImportRow
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		get := func(k string) string {
+			if i := col[k]; i < len(rec) {
+				return strings.TrimSpace(rec[i])
+			}
+			return ""
+		}
+		if get("date") == "" && get("currency") == "" && get("rate") == "" {
+			continue
+		}
+		d, err := time.Parse("2006-01-02", get("date"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid date %q", line, get("date"))
+		}
+		v, err := strconv.ParseFloat(strings.ReplaceAll(get("rate"), ",", "."), 64)
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("line %d: invalid rate %q", line, get("rate"))
+		}
+		cur := strings.ToUpper(get("currency"))
+		if cur == "" {
+			return nil, fmt.Errorf("line %d: currency is required", line)
+		}
+		out = append(out, ImportRow{Line: line, Currency: cur, Date: d, Rate: v})
+	}
+	if len(out) == 0 {
+		return nil, ErrEmptyImport
+	}
+	return out, nil
+}
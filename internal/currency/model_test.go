@@ -0,0 +1,38 @@
+package currency
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConverter(t *testing.T) {
+	c := NewConverter(map[string]float64{"UZS": 0.00008, "eur": 1.1})
+	if v, ok := c.Convert(100, "USD", "USD"); !ok || v != 100 {
+		t.Fatalf("same currency: got %v %v", v, ok)
+	}
+	if v, ok := c.ToBase(12_500_000, "UZS"); !ok || v != 1000 {
+		t.Fatalf("UZS→USD: got %v %v", v, ok)
+	}
+	if v, ok := c.Convert(1000, "EUR", "UZS"); !ok || v != 13_750_000 {
+		t.Fatalf("EUR→UZS: got %v %v", v, ok)
+	}
+	if _, ok := c.Convert(10, "RUB", "USD"); ok {
+		t.Fatal("RUB has no rate: expected ok=false")
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	rows, err := ParseCSV(strings.NewReader("\ufeffcurrency;date;rate\nuzs;2026-01-05;0,0000795\n\nEUR;2026-01-05;1.09\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[0].Currency != "UZS" || rows[0].Rate != 0.0000795 || rows[1].Line != 4 {
+		t.Fatalf("unexpected rows: %+v", rows)
+	}
+	if _, err := ParseCSV(strings.NewReader("date,currency\n2026-01-05,EUR\n")); err == nil {
+		t.Fatal("missing rate column: expected error")
+	}
+	if _, err := ParseCSV(strings.NewReader("date,currency,rate\n05.01.2026,EUR,1.1\n")); err == nil {
+		t.Fatal("invalid date: expected error")
+	}
+}
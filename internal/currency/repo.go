@@ -0,0 +1,110 @@
+package currency
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repo struct {
+	pg *pgxpool.Pool
+}
+
+func NewRepo(pg *pgxpool.Pool) *Repo {
+	return &Repo{pg: pg}
+}
+
+const upsertRateSQL = `
+INSERT INTO currency_rates (currency, rate_date, rate, source, updated_by)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (currency, rate_date) DO UPDATE
+SET rate = EXCLUDED.rate, source = EXCLUDED.source, updated_by = EXCLUDED.updated_by, updated_at = now()`
+
+// Upsert сохраняет курс валюты на дату (одна запись на валюту и день).
+func (r *Repo) Upsert(ctx context.Context, currency string, date time.Time, rate float64, source string, updatedBy *uuid.UUID) error {
+	_, err := r.pg.Exec(ctx, upsertRateSQL, currency, date, rate, source, updatedBy)
+	return err
+}
+
+// UpsertMany сохраняет курсы из файла импорта в одной транзакции.
+func (r *Repo) UpsertMany(ctx context.Context, rows []ImportRow, updatedBy *uuid.UUID) error {
+	tx, err := r.pg.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	for _, row := range rows {
+		if _, err := tx.Exec(ctx, upsertRateSQL, row.Currency, row.Date, row.Rate, SourceImport, updatedBy); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// Delete удаляет курс валюты на дату. false — записи не было.
+func (r *Repo) Delete(ctx context.Context, currency string, date time.Time) (bool, error) {
+	tag, err := r.pg.Exec(ctx, `DELETE FROM currency_rates WHERE currency = $1 AND rate_date = $2`, currency, date)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// List возвращает историю курсов (currency пустая — все валюты) в диапазоне дат, новые сверху.
+func (r *Repo) List(ctx context.Context, currency string, from, to time.Time, limit, offset int) ([]Rate, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	rows, err := r.pg.Query(ctx, `
+SELECT id, currency, rate_date, rate::float8, source, updated_by, created_at, updated_at
+FROM currency_rates
+WHERE ($1 = '' OR currency = $1) AND rate_date >= $2 AND rate_date <= $3
+ORDER BY rate_date DESC, currency
+LIMIT $4 OFFSET $5`, currency, from, to, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []Rate
+	for rows.Next() {
+		var x Rate
+		if err := rows.Scan(&x.ID, &x.Currency, &x.RateDate, &x.Rate, &x.Source, &x.UpdatedBy, &x.CreatedAt, &x.UpdatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, x)
+	}
+	return list, rows.Err()
+}
+
+// RatesOn возвращает курс каждой валюты к Base на дату (последний на дату или раньше, иначе ближайший после).
+func (r *Repo) RatesOn(ctx context.Context, date time.Time) (map[string]float64, error) {
+	rows, err := r.pg.Query(ctx, `
+SELECT DISTINCT ON (currency) currency, rate::float8
+FROM currency_rates
+ORDER BY currency, (rate_date > $1::date), ABS(rate_date - $1::date)`, date)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := map[string]float64{}
+	for rows.Next() {
+		var cur string
+		var v float64
+		if err := rows.Scan(&cur, &v); err != nil {
+			return nil, err
+		}
+		out[cur] = v
+	}
+	return out, rows.Err()
+}
+
+// ConverterOn — конвертер по курсам на дату.
+func (r *Repo) ConverterOn(ctx context.Context, date time.Time) (*Converter, error) {
+	rates, err := r.RatesOn(ctx, date)
+	if err != nil {
+		return nil, err
+	}
+	return NewConverter(rates), nil
+}
@@ -217,6 +217,24 @@ func (r *Repo) UpdateLastOnlineAt(ctx context.Context, id uuid.UUID, t time.Time
 	return err
 }
 
+// DisplayCurrency returns dispatcher's display currency preference ("" — not set).
+func (r *Repo) DisplayCurrency(ctx context.Context, id uuid.UUID) (string, error) {
+	const q = `SELECT COALESCE(display_currency, '') FROM freelance_dispatchers WHERE id = $1`
+	var cur string
+	err := r.pg.QueryRow(ctx, q, id).Scan(&cur)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	return cur, err
+}
+
+// SetDisplayCurrency sets dispatcher's display currency preference ("" — reset).
+func (r *Repo) SetDisplayCurrency(ctx context.Context, id uuid.UUID, cur string) error {
+	const q = `UPDATE freelance_dispatchers SET display_currency = NULLIF($2, ''), updated_at = now() WHERE id = $1`
+	_, err := r.pg.Exec(ctx, q, id, cur)
+	return err
+}
+
 // UpdatePhoto сохраняет фото диспетчера в БД (бинарные данные + content-type).
 func (r *Repo) UpdatePhoto(ctx context.Context, id uuid.UUID, data []byte, contentType string) error {
 	const q = `UPDATE freelance_dispatchers SET photo_data = $2, photo_content_type = $3, updated_at = now() WHERE id = $1`
@@ -0,0 +1,158 @@
+package documents
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"sarbonNew/internal/cargo"
+	"sarbonNew/internal/companies"
+	"sarbonNew/internal/dispatchers"
+	"sarbonNew/internal/drivers"
+	"sarbonNew/internal/trips"
+)
+
+var (
+	ErrNoDriver      = errors.New("documents: trip has no driver")
+	ErrCargoNotFound = errors.New("documents: cargo not found")
+)
+
+// Generator собирает данные документа из репозиториев, формирует PDF и сохраняет его в trip_documents.
+type Generator struct {
+	repo        *Repo
+	cargo       *cargo.Repo
+	drivers     *drivers.Repo
+	companies   *companies.Repo
+	dispatchers *dispatchers.Repo
+}
+
+func NewGenerator(repo *Repo, cargoRepo *cargo.Repo, driversRepo *drivers.Repo, companiesRepo *companies.Repo, dispatchersRepo *dispatchers.Repo) *Generator {
+	return &Generator{repo: repo, cargo: cargoRepo, drivers: driversRepo, companies: companiesRepo, dispatchers: dispatchersRepo}
+}
+
+// Get возвращает документ рейса и его PDF. Файл формируется, если его ещё нет, если он был на другом языке
+// или если regenerate (данные груза/водителя изменились); номер документа при этом не меняется.
+func (g *Generator) Get(ctx context.Context, t *trips.Trip, docType, lang string, regenerate bool) (*Document, []byte, error) {
+	if !IsType(docType) {
+		return nil, nil, ErrUnknownType
+	}
+	if t.DriverID == nil {
+		return nil, nil, ErrNoDriver
+	}
+	lang = DocLang(lang)
+	now := time.Now()
+	doc, err := g.repo.Reserve(ctx, t.ID, docType, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !regenerate && doc.GeneratedAt != nil && doc.Lang != nil && *doc.Lang == lang {
+		data, err := g.repo.File(ctx, doc.ID)
+		if err != nil || data != nil {
+			return doc, data, err
+		}
+	}
+	d, err := g.collect(ctx, t)
+	if err != nil {
+		return nil, nil, err
+	}
+	d.DocType, d.Number, d.Date, d.Lang = docType, doc.Number, doc.CreatedAt, lang
+	data, err := Render(d)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := g.repo.Store(ctx, doc.ID, lang, data); err != nil {
+		return nil, nil, err
+	}
+	doc.Lang, doc.GeneratedAt = &lang, &now
+	return doc, data, nil
+}
+
+// collect загружает груз, маршрут, оплату, водителя и стороны перевозки.
+func (g *Generator) collect(ctx context.Context, t *trips.Trip) (*Data, error) {
+	c, err := g.cargo.GetByID(ctx, t.CargoID, true)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return nil, ErrCargoNotFound
+	}
+	points, err := g.cargo.GetRoutePoints(ctx, t.CargoID)
+	if err != nil {
+		return nil, err
+	}
+	payment, err := g.cargo.GetPayment(ctx, t.CargoID)
+	if err != nil {
+		return nil, err
+	}
+	drv, err := g.drivers.FindByID(ctx, *t.DriverID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && drv == nil) {
+		return nil, ErrNoDriver
+	}
+	if err != nil {
+		return nil, err
+	}
+	sender, err := g.sender(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	carrier, err := g.carrier(ctx, drv)
+	if err != nil {
+		return nil, err
+	}
+	return &Data{Trip: *t, Cargo: *c, Points: points, Payment: payment, Sender: sender, Carrier: carrier, Driver: *drv}, nil
+}
+
+// sender — грузоотправитель: компания груза, иначе диспетчер-создатель, иначе контакт из груза.
+func (g *Generator) sender(ctx context.Context, c *cargo.Cargo) (Party, error) {
+	contact := Party{Name: strVal(c.ContactName), Phone: strVal(c.ContactPhone)}
+	if c.CompanyID != nil {
+		p, err := g.companyParty(ctx, *c.CompanyID)
+		if err != nil || p != nil {
+			return partyOr(p, contact), err
+		}
+	}
+	if c.CreatedByType != nil && *c.CreatedByType == "DISPATCHER" && c.CreatedByID != nil {
+		d, err := g.dispatchers.FindByID(ctx, *c.CreatedByID)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return contact, err
+		}
+		if d != nil {
+			return Party{Name: strVal(d.Name), Phone: d.Phone}, nil
+		}
+	}
+	return contact, nil
+}
+
+// carrier — перевозчик: компания водителя, иначе сам водитель.
+func (g *Generator) carrier(ctx context.Context, drv *drivers.Driver) (Party, error) {
+	self := Party{Name: strVal(drv.Name), Phone: drv.Phone}
+	if drv.CompanyID != nil {
+		if id, err := uuid.Parse(*drv.CompanyID); err == nil {
+			p, err := g.companyParty(ctx, id)
+			return partyOr(p, self), err
+		}
+	}
+	return self, nil
+}
+
+// companyParty — реквизиты компании; nil — компания не найдена (удалена).
+func (g *Generator) companyParty(ctx context.Context, id uuid.UUID) (*Party, error) {
+	co, err := g.companies.GetByIDTZ(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Party{Name: co.Name, Address: strVal(co.Address), Phone: strVal(co.Phone), Inn: strVal(co.Inn)}, nil
+}
+
+func partyOr(p *Party, fallback Party) Party {
+	if p == nil {
+		return fallback
+	}
+	return *p
+}
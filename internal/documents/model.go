@@ -0,0 +1,47 @@
+// Package documents — PDF-документы рейса: CMR (международная товарно-транспортная накладная) и путевой лист.
+// Документ собирается из груза, маршрута, оплаты, KYC водителя и данных ТС на языке пользователя;
+// номер присваивается один раз и сохраняется при перегенерации (таблица trip_documents).
+package documents
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Типы документов.
+const (
+	TypeCMR       = "CMR"
+	TypeTripSheet = "TRIP_SHEET"
+)
+
+// Document — документ рейса (без файла; файл читается через Repo.File).
+type Document struct {
+	ID          uuid.UUID
+	TripID      uuid.UUID
+	DocType     string
+	Number      string
+	Lang        *string
+	CreatedAt   time.Time
+	GeneratedAt *time.Time // nil — номер выдан, файл ещё не сформирован
+}
+
+// IsType — поддерживаемый тип документа.
+func IsType(docType string) bool {
+	return docType == TypeCMR || docType == TypeTripSheet
+}
+
+// numberPrefix — префикс номера по типу документа: CMR-2026-, TS-2026-.
+func numberPrefix(docType string, year int) string {
+	p := "CMR"
+	if docType == TypeTripSheet {
+		p = "TS"
+	}
+	return fmt.Sprintf("%s-%d-", p, year)
+}
+
+// FileName — имя PDF-файла, например CMR-2026-000123.pdf.
+func FileName(number string) string {
+	return number + ".pdf"
+}
@@ -0,0 +1,544 @@
+package documents
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+
+	"sarbonNew/internal/cargo"
+	"sarbonNew/internal/drivers"
+	"sarbonNew/internal/reference"
+	"sarbonNew/internal/trips"
+)
+
+// DejaVu Sans Condensed: латиница, кириллица, турецкий и узбекский алфавиты (CJK нет — см. DocLang).
+//
+//go:embed fonts/DejaVuSansCondensed.ttf fonts/DejaVuSansCondensed-Bold.ttf
+var fonts embed.FS
+
+var ErrUnknownType = errors.New("documents: unknown document type")
+
+// Party — сторона перевозки (отправитель, перевозчик, получатель).
+type Party struct {
+	Name    string
+	Address string
+	Phone   string
+	Inn     string
+}
+
+// Data — всё, что печатается в документе.
+type Data struct {
+	DocType string
+	Number  string
+	Date    time.Time
+	Lang    string
+	Trip    trips.Trip
+	Cargo   cargo.Cargo
+	Points  []cargo.RoutePoint
+	Payment *cargo.Payment // nil — оплата не задана
+	Sender  Party
+	Carrier Party
+	Driver  drivers.Driver
+}
+
+// DocLang — язык документа: ru, uz, en, tr; остальные (zh) — английский.
+func DocLang(lang string) string {
+	switch lang = strings.ToLower(strings.TrimSpace(lang)); lang {
+	case "ru", "uz", "en", "tr":
+		return lang
+	}
+	return "en"
+}
+
+// Render формирует PDF документа.
+func Render(d *Data) ([]byte, error) {
+	if !IsType(d.DocType) {
+		return nil, ErrUnknownType
+	}
+	regular, err := fonts.ReadFile("fonts/DejaVuSansCondensed.ttf")
+	if err != nil {
+		return nil, err
+	}
+	bold, err := fonts.ReadFile("fonts/DejaVuSansCondensed-Bold.ttf")
+	if err != nil {
+		return nil, err
+	}
+	f := gofpdf.New("P", "mm", "A4", "")
+	f.AddUTF8FontFromBytes(fontFamily, "", regular)
+	f.AddUTF8FontFromBytes(fontFamily, "B", bold)
+	f.SetMargins(marginMM, marginMM, marginMM)
+	f.SetAutoPageBreak(true, marginMM)
+	f.SetTitle(d.Number, true)
+	f.AddPage()
+
+	p := &page{f: f, d: d, lang: DocLang(d.Lang)}
+	if d.DocType == TypeCMR {
+		p.cmr()
+	} else {
+		p.tripSheet()
+	}
+	var buf bytes.Buffer
+	if err := f.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+const (
+	fontFamily = "dejavu"
+	marginMM   = 12.0
+	lineMM     = 4.6
+)
+
+// page — вёрстка документа: строки из ячеек-рамок «заголовок + текст», высота строки — по самой высокой ячейке.
+type page struct {
+	f    *gofpdf.Fpdf
+	d    *Data
+	lang string
+}
+
+type cell struct {
+	title string
+	body  string
+	width float64 // доля ширины страницы; 0 — поровну с другими такими ячейками
+	lines int     // минимальное число строк текста (место под подпись)
+}
+
+func (p *page) label(field string) string {
+	return reference.RefLabel("document.label", field, p.lang)
+}
+
+func (p *page) contentWidth() float64 {
+	w, _ := p.f.GetPageSize()
+	return w - 2*marginMM
+}
+
+func (p *page) header(title string) {
+	p.f.SetFont(fontFamily, "B", 14)
+	p.f.CellFormat(0, 8, title, "", 1, "L", false, 0, "")
+	p.f.SetFont(fontFamily, "", 10)
+	line := p.label("NUMBER") + ": " + p.d.Number + "    " + p.label("DATE") + ": " + p.d.Date.Format("02.01.2006") +
+		"    " + p.label("TRIP") + ": " + p.d.Trip.ID.String()
+	p.f.CellFormat(0, 6, line, "", 1, "L", false, 0, "")
+	p.f.Ln(2)
+}
+
+// row рисует строку ячеек; пустой текст ячейки печатается как «—», кроме ячеек под подпись (lines > 0).
+func (p *page) row(cells ...cell) {
+	total := p.contentWidth()
+	fixed, free := 0.0, 0
+	for _, c := range cells {
+		if c.width > 0 {
+			fixed += c.width * total
+		} else {
+			free++
+		}
+	}
+	widths := make([]float64, len(cells))
+	titles := make([][]string, len(cells))
+	lines := make([][]string, len(cells))
+	height := 0.0
+	for i, c := range cells {
+		widths[i] = c.width * total
+		if c.width <= 0 {
+			widths[i] = (total - fixed) / float64(free)
+		}
+		p.f.SetFont(fontFamily, "B", 7.5)
+		titles[i] = p.f.SplitText(c.title, widths[i]-3)
+		p.f.SetFont(fontFamily, "", 9)
+		body := c.body
+		if strings.TrimSpace(body) == "" && c.lines == 0 {
+			body = "—"
+		}
+		if body != "" {
+			for _, part := range strings.Split(body, "\n") {
+				lines[i] = append(lines[i], p.f.SplitText(part, widths[i]-3)...)
+			}
+		}
+		for len(lines[i]) < c.lines {
+			lines[i] = append(lines[i], "")
+		}
+		if h := float64(len(titles[i]))*4 + float64(len(lines[i]))*lineMM + 3; h > height {
+			height = h
+		}
+	}
+	_, pageH := p.f.GetPageSize()
+	if p.f.GetY()+height > pageH-marginMM {
+		p.f.AddPage()
+	}
+	x, y := p.f.GetX(), p.f.GetY()
+	for i := range cells {
+		p.f.Rect(x, y, widths[i], height, "D")
+		p.f.SetXY(x+1.5, y+1)
+		p.f.SetFont(fontFamily, "B", 7.5)
+		for _, l := range titles[i] {
+			p.f.SetX(x + 1.5)
+			p.f.CellFormat(widths[i]-3, 4, l, "", 2, "L", false, 0, "")
+		}
+		p.f.SetFont(fontFamily, "", 9)
+		for _, l := range lines[i] {
+			p.f.SetX(x + 1.5)
+			p.f.CellFormat(widths[i]-3, lineMM, l, "", 2, "L", false, 0, "")
+		}
+		x += widths[i]
+	}
+	p.f.SetXY(marginMM, y+height)
+}
+
+// table рисует таблицу с заголовком; ширины — доли ширины страницы.
+func (p *page) table(widths []float64, head []string, rows [][]string) {
+	total := p.contentWidth()
+	p.f.SetFont(fontFamily, "B", 8)
+	for i, h := range head {
+		p.f.CellFormat(widths[i]*total, 6, h, "1", 0, "L", false, 0, "")
+	}
+	p.f.Ln(-1)
+	p.f.SetFont(fontFamily, "", 8.5)
+	for _, r := range rows {
+		n := 1
+		split := make([][]string, len(r))
+		for i, v := range r {
+			split[i] = p.f.SplitText(v, widths[i]*total-2)
+			if len(split[i]) > n {
+				n = len(split[i])
+			}
+		}
+		h := float64(n) * lineMM
+		_, pageH := p.f.GetPageSize()
+		if p.f.GetY()+h > pageH-marginMM {
+			p.f.AddPage()
+		}
+		x, y := p.f.GetX(), p.f.GetY()
+		for i := range r {
+			w := widths[i] * total
+			p.f.Rect(x, y, w, h, "D")
+			for j, l := range split[i] {
+				p.f.SetXY(x+1, y+float64(j)*lineMM)
+				p.f.CellFormat(w-2, lineMM, l, "", 0, "L", false, 0, "")
+			}
+			x += w
+		}
+		p.f.SetXY(marginMM, y+h)
+	}
+	p.f.Ln(2)
+}
+
+func (p *page) cmr() {
+	d := p.d
+	p.header("CMR — " + p.label("CMR_TITLE"))
+	load, unload := mainPoints(d.Points)
+	p.row(
+		cell{title: "1. " + p.label("SENDER"), body: partyText(d.Sender, p)},
+		cell{title: "16. " + p.label("CARRIER"), body: partyText(d.Carrier, p)},
+	)
+	p.row(
+		cell{title: "2. " + p.label("CONSIGNEE"), body: p.pointText(unload)},
+		cell{title: p.label("VEHICLE") + " / " + p.label("DRIVER"), body: p.vehicleShort() + "\n" + p.driverShort()},
+	)
+	taking := p.pointText(load)
+	if d.Cargo.ReadyAt != nil {
+		taking += "\n" + d.Cargo.ReadyAt.Format("02.01.2006")
+	}
+	p.row(
+		cell{title: "3. " + p.label("DELIVERY_PLACE"), body: p.pointText(unload)},
+		cell{title: "4. " + p.label("TAKING_OVER"), body: taking},
+	)
+	p.row(cell{title: "5. " + p.label("DOCUMENTS"), body: documentsText(d.Cargo.Documents)})
+	p.row(
+		cell{title: "6–9. " + p.label("GOODS"), body: p.goodsText(), width: 0.5},
+		cell{title: "11. " + p.label("WEIGHT"), body: num(d.Cargo.Weight)},
+		cell{title: "12. " + p.label("VOLUME"), body: num(d.Cargo.Volume)},
+	)
+	adr := ""
+	if d.Cargo.ADREnabled {
+		adr = "ADR"
+		if d.Cargo.ADRClass != nil {
+			adr += " " + *d.Cargo.ADRClass
+		}
+	}
+	p.row(
+		cell{title: p.label("ADR_CLASS"), body: adr},
+		cell{title: p.label("TEMPERATURE"), body: temperature(d.Cargo.TempMin, d.Cargo.TempMax)},
+	)
+	p.row(cell{title: "13. " + p.label("INSTRUCTIONS"), body: strVal(d.Cargo.LoadComment)})
+	p.row(
+		cell{title: "14. " + p.label("PAYMENT"), body: p.paymentTerms()},
+		cell{title: "15. " + p.label("PRICE"), body: p.price()},
+	)
+	established := ""
+	if load != nil {
+		established = cityName(load.CityCode, p.lang) + ", "
+	}
+	p.row(cell{title: "21. " + p.label("ESTABLISHED"), body: established + d.Date.Format("02.01.2006")})
+	p.signatures(
+		"22. "+p.label("SIGN_SENDER"),
+		"23. "+p.label("SIGN_CARRIER"),
+		"24. "+p.label("SIGN_CONSIGNEE"),
+	)
+}
+
+func (p *page) tripSheet() {
+	d := p.d
+	p.header(p.label("TRIP_SHEET_TITLE"))
+	drv := d.Driver
+	passport := strings.TrimSpace(strVal(drv.DriverPassportSeries) + " " + strVal(drv.DriverPassportNumber))
+	p.row(
+		cell{title: p.label("DRIVER"), body: strVal(drv.Name) + "\n" + p.label("PHONE") + ": " + drv.Phone},
+		cell{title: p.label("PASSPORT") + " / " + p.label("PINFL"), body: passport + "\n" + strVal(drv.DriverPINFL)},
+	)
+	p.row(
+		cell{title: p.label("TRACTOR"), body: vehicleText(drv.PowerPlateNumber, drv.PowerTechSeries, drv.PowerTechNumber, drv.PowerOwnerName, p)},
+		cell{title: p.label("TRAILER"), body: vehicleText(drv.TrailerPlateNumber, drv.TrailerTechSeries, drv.TrailerTechNumber, drv.TrailerOwnerName, p)},
+	)
+	p.row(
+		cell{title: p.label("SENDER"), body: partyText(d.Sender, p)},
+		cell{title: p.label("CARRIER"), body: partyText(d.Carrier, p)},
+	)
+	p.row(
+		cell{title: p.label("GOODS"), body: p.goodsText(), width: 0.5},
+		cell{title: p.label("WEIGHT"), body: num(d.Cargo.Weight)},
+		cell{title: p.label("VOLUME"), body: num(d.Cargo.Volume)},
+	)
+	var dist, dur string
+	if d.Cargo.DistanceKm != nil {
+		dist = num(*d.Cargo.DistanceKm)
+	}
+	if d.Cargo.DurationMinutes != nil {
+		dur = num(math.Round(float64(*d.Cargo.DurationMinutes)/6) / 10)
+	}
+	p.row(
+		cell{title: p.label("DISTANCE"), body: dist},
+		cell{title: p.label("DURATION"), body: dur},
+		cell{title: p.label("CONTACT"), body: strings.TrimSpace(strVal(d.Cargo.ContactName) + " " + strVal(d.Cargo.ContactPhone))},
+	)
+
+	p.f.Ln(2)
+	p.f.SetFont(fontFamily, "B", 10)
+	p.f.CellFormat(0, 6, p.label("ROUTE"), "", 1, "L", false, 0, "")
+	rows := make([][]string, 0, len(d.Points))
+	for i, rp := range d.Points {
+		rows = append(rows, []string{
+			strconv.Itoa(i + 1), reference.RefLabel("cargo.route_point_type", rp.Type, p.lang), cityName(rp.CityCode, p.lang),
+			rp.Address, rp.Orientir, strVal(rp.Comment),
+		})
+	}
+	p.table([]float64{0.05, 0.12, 0.15, 0.28, 0.2, 0.2},
+		[]string{"№", p.label("POINT_TYPE"), p.label("CITY"), p.label("ADDRESS"), p.label("LANDMARK"), p.label("COMMENT")}, rows)
+
+	p.row(
+		cell{title: p.label("PAYMENT"), body: p.paymentTerms()},
+		cell{title: p.label("PRICE"), body: p.price()},
+	)
+	p.signatures(p.label("SIGN_DISPATCHER"), p.label("SIGN_DRIVER"))
+}
+
+// signatures — строка пустых ячеек для подписей.
+func (p *page) signatures(titles ...string) {
+	cells := make([]cell, len(titles))
+	for i, t := range titles {
+		cells[i] = cell{title: t, lines: 4}
+	}
+	p.f.Ln(2)
+	p.row(cells...)
+}
+
+func (p *page) pointText(rp *cargo.RoutePoint) string {
+	if rp == nil {
+		return ""
+	}
+	parts := []string{cityName(rp.CityCode, p.lang)}
+	if rp.Address != "" {
+		parts = append(parts, rp.Address)
+	}
+	if rp.Orientir != "" {
+		parts = append(parts, rp.Orientir)
+	}
+	return strings.Join(parts, "\n")
+}
+
+func (p *page) goodsText() string {
+	c := p.d.Cargo
+	lines := []string{p.label("TRUCK_TYPE") + ": " + reference.RefLabel("cargo.truck_type", c.TruckType, p.lang)}
+	if c.ShipmentType != nil {
+		lines = append(lines, p.label("SHIPMENT_TYPE")+": "+reference.RefLabel("cargo.shipment_type", *c.ShipmentType, p.lang))
+	}
+	if len(c.LoadingTypes) > 0 {
+		types := make([]string, len(c.LoadingTypes))
+		for i, t := range c.LoadingTypes {
+			types[i] = reference.RefLabel("cargo.loading_type", t, p.lang)
+		}
+		lines = append(lines, p.label("LOADING_TYPES")+": "+strings.Join(types, ", "))
+	}
+	if c.ReadyAt != nil {
+		lines = append(lines, p.label("READY_AT")+": "+c.ReadyAt.Format("02.01.2006 15:04"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (p *page) price() string {
+	if p.d.Trip.AgreedPrice != nil {
+		return money(*p.d.Trip.AgreedPrice, p.d.Trip.AgreedCurrency)
+	}
+	if p.d.Payment != nil && p.d.Payment.TotalAmount != nil {
+		return money(*p.d.Payment.TotalAmount, p.d.Payment.TotalCurrency)
+	}
+	return ""
+}
+
+func (p *page) paymentTerms() string {
+	pm := p.d.Payment
+	if pm == nil {
+		return ""
+	}
+	var lines []string
+	if pm.WithPrepayment && pm.PrepaymentAmount != nil {
+		l := p.label("PREPAYMENT") + ": " + money(*pm.PrepaymentAmount, pm.PrepaymentCurrency)
+		if pm.PrepaymentType != nil {
+			l += " (" + reference.RefLabel("cargo.prepayment_type", *pm.PrepaymentType, p.lang) + ")"
+		}
+		lines = append(lines, l)
+	}
+	if pm.RemainingAmount != nil {
+		l := p.label("REMAINING") + ": " + money(*pm.RemainingAmount, pm.RemainingCurrency)
+		if pm.RemainingType != nil {
+			l += " (" + reference.RefLabel("cargo.remaining_type", *pm.RemainingType, p.lang) + ")"
+		}
+		lines = append(lines, l)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (p *page) vehicleShort() string {
+	drv := p.d.Driver
+	parts := []string{p.label("TRACTOR") + ": " + orDash(drv.PowerPlateNumber)}
+	if drv.TrailerPlateNumber != nil && *drv.TrailerPlateNumber != "" {
+		parts = append(parts, p.label("TRAILER")+": "+*drv.TrailerPlateNumber)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (p *page) driverShort() string {
+	return strings.TrimSpace(strVal(p.d.Driver.Name) + ", " + p.d.Driver.Phone)
+}
+
+func partyText(pt Party, p *page) string {
+	lines := []string{pt.Name}
+	if pt.Address != "" {
+		lines = append(lines, pt.Address)
+	}
+	if pt.Phone != "" {
+		lines = append(lines, p.label("PHONE")+": "+pt.Phone)
+	}
+	if pt.Inn != "" {
+		lines = append(lines, p.label("INN")+": "+pt.Inn)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func vehicleText(plate, techSeries, techNumber, owner *string, p *page) string {
+	if plate == nil || *plate == "" {
+		return ""
+	}
+	lines := []string{*plate}
+	if tech := strings.TrimSpace(strVal(techSeries) + " " + strVal(techNumber)); tech != "" {
+		lines = append(lines, p.label("TECH_PASSPORT")+": "+tech)
+	}
+	if owner != nil && *owner != "" {
+		lines = append(lines, p.label("OWNER")+": "+*owner)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// documentsText — документы, которые требует груз (cargo.Documents).
+func documentsText(docs *cargo.Documents) string {
+	if docs == nil {
+		return ""
+	}
+	var out []string
+	for _, f := range []struct {
+		name string
+		on   *bool
+	}{{"CMR", docs.CMR}, {"TIR", docs.TIR}, {"T1", docs.T1}, {"Medbook", docs.Medbook}, {"GLONASS", docs.GLONASS}, {"Seal", docs.Seal}, {"Permit", docs.Permit}} {
+		if f.on != nil && *f.on {
+			out = append(out, f.name)
+		}
+	}
+	return strings.Join(out, ", ")
+}
+
+// mainPoints — основные точки погрузки и выгрузки (по флагам, иначе первая и последняя точки).
+func mainPoints(points []cargo.RoutePoint) (load, unload *cargo.RoutePoint) {
+	for i := range points {
+		if points[i].IsMainLoad && load == nil {
+			load = &points[i]
+		}
+		if points[i].IsMainUnload {
+			unload = &points[i]
+		}
+	}
+	if len(points) > 0 {
+		if load == nil {
+			load = &points[0]
+		}
+		if unload == nil {
+			unload = &points[len(points)-1]
+		}
+	}
+	return load, unload
+}
+
+// cityName — название города по коду: ru — русское, иначе английское (если есть).
+func cityName(code, lang string) string {
+	city, err := reference.FindCity(code)
+	if err != nil {
+		return code
+	}
+	if lang != "ru" && city.NameEn != nil && *city.NameEn != "" {
+		return *city.NameEn
+	}
+	return city.NameRu
+}
+
+func temperature(min, max *float64) string {
+	switch {
+	case min != nil && max != nil:
+		return num(*min) + " … " + num(*max)
+	case min != nil:
+		return "≥ " + num(*min)
+	case max != nil:
+		return "≤ " + num(*max)
+	}
+	return ""
+}
+
+func money(amount float64, currency *string) string {
+	s := strconv.FormatFloat(amount, 'f', 2, 64)
+	if currency != nil {
+		s += " " + *currency
+	}
+	return s
+}
+
+func num(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func strVal(p *string) string {
+	if p == nil {
+		return ""
+	}
+	return *p
+}
+
+func orDash(p *string) string {
+	if p == nil || *p == "" {
+		return "—"
+	}
+	return *p
+}
@@ -0,0 +1,86 @@
+package documents
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"sarbonNew/internal/cargo"
+	"sarbonNew/internal/drivers"
+	"sarbonNew/internal/trips"
+)
+
+func testData(docType, lang string) *Data {
+	s := func(v string) *string { return &v }
+	price, prepay := 1500.0, 500.0
+	yes := true
+	driverID := uuid.New()
+	return &Data{
+		DocType: docType,
+		Number:  numberPrefix(docType, 2026) + "000042",
+		Date:    time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC),
+		Lang:    lang,
+		Trip:    trips.Trip{ID: uuid.New(), DriverID: &driverID, Status: trips.StatusAssigned, AgreedPrice: &price, AgreedCurrency: s("USD")},
+		Cargo: cargo.Cargo{
+			ID: uuid.New(), Weight: 20, Volume: 82, TruckType: "TENT", ADREnabled: true, ADRClass: s("3"),
+			Documents: &cargo.Documents{CMR: &yes, TIR: &yes}, LoadComment: s("Погрузка с 9:00, İstanbul'a teslim"),
+		},
+		Points: []cargo.RoutePoint{
+			{Type: "load", CityCode: "TAS", Address: "ул. Навои, 12", IsMainLoad: true},
+			{Type: "customs", CityCode: "XXX", Address: "Gümrük kapısı"},
+			{Type: "unload", CityCode: "IST", Address: "Atatürk Cd. 5", IsMainUnload: true},
+		},
+		Payment: &cargo.Payment{WithPrepayment: true, PrepaymentAmount: &prepay, PrepaymentCurrency: s("USD")},
+		Sender:  Party{Name: "ООО «Sarbon Logistics»", Address: "Ташкент", Inn: "123456789"},
+		Carrier: Party{Name: "Aliyev O'tkir", Phone: "+998901234567"},
+		Driver:  drivers.Driver{Name: s("Aliyev O'tkir"), Phone: "+998901234567", PowerPlateNumber: s("01A123BC"), TrailerPlateNumber: s("01 1234 AA")},
+	}
+}
+
+func TestRender(t *testing.T) {
+	for _, docType := range []string{TypeCMR, TypeTripSheet} {
+		for _, lang := range []string{"ru", "uz", "en", "tr", "zh"} {
+			pdf, err := Render(testData(docType, lang))
+			if err != nil {
+				t.Fatalf("%s/%s: %v", docType, lang, err)
+			}
+			if !bytes.HasPrefix(pdf, []byte("%PDF-")) {
+				t.Fatalf("%s/%s: not a PDF", docType, lang)
+			}
+		}
+	}
+	if _, err := Render(testData("INVOICE", "ru")); err != ErrUnknownType {
+		t.Errorf("unknown type: %v", err)
+	}
+}
+
+func TestNumberAndLang(t *testing.T) {
+	if got := numberPrefix(TypeCMR, 2026); got != "CMR-2026-" {
+		t.Errorf("CMR prefix: %s", got)
+	}
+	if got := FileName(numberPrefix(TypeTripSheet, 2026) + "000007"); got != "TS-2026-000007.pdf" {
+		t.Errorf("file name: %s", got)
+	}
+	for in, want := range map[string]string{"RU": "ru", "uz": "uz", "zh": "en", "": "en"} {
+		if got := DocLang(in); got != want {
+			t.Errorf("DocLang(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestMainPoints(t *testing.T) {
+	points := []cargo.RoutePoint{{CityCode: "A"}, {CityCode: "B", IsMainLoad: true}, {CityCode: "C", IsMainUnload: true}, {CityCode: "D"}}
+	load, unload := mainPoints(points)
+	if load.CityCode != "B" || unload.CityCode != "C" {
+		t.Errorf("main points: %s, %s", load.CityCode, unload.CityCode)
+	}
+	load, unload = mainPoints(points[3:])
+	if load.CityCode != "D" || unload.CityCode != "D" {
+		t.Errorf("fallback: %s, %s", load.CityCode, unload.CityCode)
+	}
+	if load, unload = mainPoints(nil); load != nil || unload != nil {
+		t.Error("empty route")
+	}
+}
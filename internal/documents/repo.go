@@ -0,0 +1,90 @@
+package documents
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repo struct {
+	pg *pgxpool.Pool
+}
+
+func NewRepo(pg *pgxpool.Pool) *Repo {
+	return &Repo{pg: pg}
+}
+
+const documentColumns = `id, trip_id, doc_type, number, lang, created_at, generated_at`
+
+func scanDocument(row pgx.Row) (*Document, error) {
+	var d Document
+	if err := row.Scan(&d.ID, &d.TripID, &d.DocType, &d.Number, &d.Lang, &d.CreatedAt, &d.GeneratedAt); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// Get returns trip document by type (nil — ещё не создавался).
+func (r *Repo) Get(ctx context.Context, tripID uuid.UUID, docType string) (*Document, error) {
+	d, err := scanDocument(r.pg.QueryRow(ctx, `SELECT `+documentColumns+` FROM trip_documents WHERE trip_id = $1 AND doc_type = $2`,
+		tripID, docType))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return d, err
+}
+
+// ListByTrip returns all documents of the trip.
+func (r *Repo) ListByTrip(ctx context.Context, tripID uuid.UUID) ([]Document, error) {
+	rows, err := r.pg.Query(ctx, `SELECT `+documentColumns+` FROM trip_documents WHERE trip_id = $1 ORDER BY doc_type`, tripID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []Document
+	for rows.Next() {
+		d, err := scanDocument(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *d)
+	}
+	return list, rows.Err()
+}
+
+// Reserve выдаёт документу рейса номер (если его ещё нет) и возвращает документ. Повторный вызов возвращает
+// тот же номер; при гонке двух запросов строка создаётся один раз (ON CONFLICT), проигравший перечитывает её.
+func (r *Repo) Reserve(ctx context.Context, tripID uuid.UUID, docType string, now time.Time) (*Document, error) {
+	if d, err := r.Get(ctx, tripID, docType); err != nil || d != nil {
+		return d, err
+	}
+	d, err := scanDocument(r.pg.QueryRow(ctx, `
+INSERT INTO trip_documents (trip_id, doc_type, number)
+VALUES ($1, $2, $3 || lpad(nextval('trip_document_number_seq')::text, 6, '0'))
+ON CONFLICT (trip_id, doc_type) DO NOTHING
+RETURNING `+documentColumns, tripID, docType, numberPrefix(docType, now.Year())))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return r.Get(ctx, tripID, docType)
+	}
+	return d, err
+}
+
+// Store сохраняет сформированный PDF документа.
+func (r *Repo) Store(ctx context.Context, id uuid.UUID, lang string, data []byte) error {
+	_, err := r.pg.Exec(ctx, `UPDATE trip_documents SET lang = $2, data = $3, generated_at = now() WHERE id = $1`, id, lang, data)
+	return err
+}
+
+// File returns PDF of the document; nil — файл ещё не сформирован.
+func (r *Repo) File(ctx context.Context, id uuid.UUID) ([]byte, error) {
+	var data []byte
+	err := r.pg.QueryRow(ctx, `SELECT data FROM trip_documents WHERE id = $1`, id).Scan(&data)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return data, err
+}
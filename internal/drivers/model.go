@@ -49,6 +49,9 @@ type Driver struct {
 	TrailerOwnerID       *string `json:"trailer_owner_id"`
 	TrailerOwnerName     *string `json:"trailer_owner_name"`
 	TrailerScanStatus    *bool   `json:"trailer_scan_status"`
+	// TrailerCapacityWeight/TrailerCapacityVolume — грузоподъёмность (т) и объём кузова (м³); для консолидации грузов.
+	TrailerCapacityWeight *float64 `json:"trailer_capacity_weight"`
+	TrailerCapacityVolume *float64 `json:"trailer_capacity_volume"`
 
 	DriverOwner *bool   `json:"driver_owner"`
 	KYCStatus     *string `json:"kyc_status"`
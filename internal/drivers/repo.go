@@ -30,6 +30,7 @@ const driverSelectCols = `
   d.driver_passport_series, d.driver_passport_number, d.driver_pinfl, d.driver_scan_status,
   p.power_plate_type, p.power_plate_number, p.power_tech_series, p.power_tech_number, p.power_owner_id, p.power_owner_name, p.power_scan_status,
   t.trailer_plate_type, t.trailer_plate_number, t.trailer_tech_series, t.trailer_tech_number, t.trailer_owner_id, t.trailer_owner_name, t.trailer_scan_status,
+  t.capacity_weight, t.capacity_volume,
   d.driver_owner, d.kyc_status,
   (d.photo_data IS NOT NULL) AS has_photo`
 
@@ -89,6 +90,7 @@ func scanDriver(row pgx.Row) (*Driver, error) {
 		&d.DriverPassportSeries, &d.DriverPassportNumber, &d.DriverPINFL, &d.DriverScanStatus,
 		&d.PowerPlateType, &d.PowerPlateNumber, &d.PowerTechSeries, &d.PowerTechNumber, &d.PowerOwnerID, &d.PowerOwnerName, &d.PowerScanStatus,
 		&d.TrailerPlateType, &d.TrailerPlateNumber, &d.TrailerTechSeries, &d.TrailerTechNumber, &d.TrailerOwnerID, &d.TrailerOwnerName, &d.TrailerScanStatus,
+		&d.TrailerCapacityWeight, &d.TrailerCapacityVolume,
 		&d.DriverOwner, &d.KYCStatus,
 		&d.HasPhoto,
 	)
@@ -172,6 +174,24 @@ func (r *Repo) UnlinkFromFreelancer(ctx context.Context, driverID, freelancerID
 	return tag.RowsAffected() > 0, nil
 }
 
+// DisplayCurrency returns driver's display currency preference ("" — not set).
+func (r *Repo) DisplayCurrency(ctx context.Context, id uuid.UUID) (string, error) {
+	const q = `SELECT COALESCE(display_currency, '') FROM drivers WHERE id = $1`
+	var cur string
+	err := r.pg.QueryRow(ctx, q, id).Scan(&cur)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	return cur, err
+}
+
+// SetDisplayCurrency sets driver's display currency preference ("" — reset).
+func (r *Repo) SetDisplayCurrency(ctx context.Context, id uuid.UUID, cur string) error {
+	const q = `UPDATE drivers SET display_currency = NULLIF($2, ''), updated_at = now() WHERE id = $1`
+	_, err := r.pg.Exec(ctx, q, id, cur)
+	return err
+}
+
 // SearchByPhone returns drivers whose phone matches the search (exact match first, then containing). For dispatcher to find driver and invite by id.
 func (r *Repo) SearchByPhone(ctx context.Context, phoneSearch string, limit int) ([]*Driver, error) {
 	if limit <= 0 {
@@ -295,12 +315,14 @@ type UpdateTrailerProfile struct {
 	TrailerOwnerID     *string
 	TrailerOwnerName   *string
 	TrailerScanStatus  *bool
+	CapacityWeight     *float64
+	CapacityVolume     *float64
 }
 
 func (r *Repo) UpdateTrailerProfile(ctx context.Context, id uuid.UUID, u UpdateTrailerProfile) error {
 	const q = `
-INSERT INTO driver_trailers (driver_id, trailer_plate_type, trailer_plate_number, trailer_tech_series, trailer_tech_number, trailer_owner_id, trailer_owner_name, trailer_scan_status, updated_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, now())
+INSERT INTO driver_trailers (driver_id, trailer_plate_type, trailer_plate_number, trailer_tech_series, trailer_tech_number, trailer_owner_id, trailer_owner_name, trailer_scan_status, capacity_weight, capacity_volume, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, now())
 ON CONFLICT (driver_id) DO UPDATE SET
   trailer_plate_type = COALESCE(EXCLUDED.trailer_plate_type, driver_trailers.trailer_plate_type),
   trailer_plate_number = COALESCE(EXCLUDED.trailer_plate_number, driver_trailers.trailer_plate_number),
@@ -309,8 +331,11 @@ ON CONFLICT (driver_id) DO UPDATE SET
   trailer_owner_id = COALESCE(EXCLUDED.trailer_owner_id, driver_trailers.trailer_owner_id),
   trailer_owner_name = COALESCE(EXCLUDED.trailer_owner_name, driver_trailers.trailer_owner_name),
   trailer_scan_status = COALESCE(EXCLUDED.trailer_scan_status, driver_trailers.trailer_scan_status),
+  capacity_weight = COALESCE(EXCLUDED.capacity_weight, driver_trailers.capacity_weight),
+  capacity_volume = COALESCE(EXCLUDED.capacity_volume, driver_trailers.capacity_volume),
   updated_at = now()`
-	_, err := r.pg.Exec(ctx, q, id, u.TrailerPlateType, u.TrailerPlateNumber, u.TrailerTechSeries, u.TrailerTechNumber, u.TrailerOwnerID, u.TrailerOwnerName, u.TrailerScanStatus)
+	_, err := r.pg.Exec(ctx, q, id, u.TrailerPlateType, u.TrailerPlateNumber, u.TrailerTechSeries, u.TrailerTechNumber, u.TrailerOwnerID, u.TrailerOwnerName, u.TrailerScanStatus,
+		u.CapacityWeight, u.CapacityVolume)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,71 @@
+// Package exports — выгрузка отчётов (грузы, рейсы, офферы) в CSV/XLSX: колонки, локализованные заголовки
+// и фоновая генерация больших выгрузок (таблица report_exports).
+package exports
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Виды отчётов.
+const (
+	KindCargo  = "CARGO"
+	KindTrips  = "TRIPS"
+	KindOffers = "OFFERS"
+)
+
+// Статусы фоновой выгрузки.
+const (
+	StatusPending    = "PENDING"
+	StatusProcessing = "PROCESSING"
+	StatusReady      = "READY"
+	StatusFailed     = "FAILED"
+)
+
+// Лимиты: до MaxSyncRows строк файл отдаётся сразу потоком, больше — генерируется в фоне; больше MaxRows — отказ.
+const (
+	MaxSyncRows = 10000
+	MaxRows     = 300000
+)
+
+// Params — фильтры и язык отчёта; сохраняются в report_exports.params для фоновой генерации.
+// Status, CreatedFrom/CreatedTo применяются ко всем видам; остальные поля — к своему виду.
+type Params struct {
+	Lang        string   `json:"lang"`
+	Status      []string `json:"status,omitempty"`
+	CreatedFrom string   `json:"created_from,omitempty"` // YYYY-MM-DD
+	CreatedTo   string   `json:"created_to,omitempty"`
+	// CARGO — те же фильтры, что GET /api/cargo
+	WeightMin  *float64 `json:"weight_min,omitempty"`
+	WeightMax  *float64 `json:"weight_max,omitempty"`
+	TruckType  string   `json:"truck_type,omitempty"`
+	WithOffers *bool    `json:"with_offers,omitempty"`
+	Sort       string   `json:"sort,omitempty"`
+	// TRIPS
+	DriverID *uuid.UUID `json:"driver_id,omitempty"`
+	// OFFERS
+	CargoID *uuid.UUID `json:"cargo_id,omitempty"`
+}
+
+// Export — фоновая выгрузка (таблица report_exports); файл (data) читается отдельно через Repo.File.
+type Export struct {
+	ID         uuid.UUID
+	AdminID    uuid.UUID
+	Kind       string
+	Format     string
+	Params     Params
+	Status     string
+	RowsCount  *int
+	FileName   *string
+	Error      *string
+	CreatedAt  time.Time
+	StartedAt  *time.Time
+	FinishedAt *time.Time
+	ExpiresAt  *time.Time
+}
+
+// IsKind — поддерживаемый вид отчёта.
+func IsKind(kind string) bool {
+	return kind == KindCargo || kind == KindTrips || kind == KindOffers
+}
@@ -0,0 +1,133 @@
+package exports
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// StaleAfter — выгрузка в PROCESSING дольше этого срока (упал процесс) снова берётся в работу.
+const StaleAfter = 30 * time.Minute
+
+type Repo struct {
+	pg *pgxpool.Pool
+}
+
+func NewRepo(pg *pgxpool.Pool) *Repo {
+	return &Repo{pg: pg}
+}
+
+const exportColumns = `id, admin_id, kind, format, params, status, rows_count, file_name, error, created_at, started_at, finished_at, expires_at`
+
+func scanExport(row pgx.Row) (*Export, error) {
+	var e Export
+	var params []byte
+	err := row.Scan(&e.ID, &e.AdminID, &e.Kind, &e.Format, &params, &e.Status, &e.RowsCount, &e.FileName, &e.Error,
+		&e.CreatedAt, &e.StartedAt, &e.FinishedAt, &e.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	_ = json.Unmarshal(params, &e.Params)
+	return &e, nil
+}
+
+// Create ставит выгрузку в очередь (PENDING).
+func (r *Repo) Create(ctx context.Context, adminID uuid.UUID, kind, format string, p Params) (*Export, error) {
+	params, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	return scanExport(r.pg.QueryRow(ctx, `
+INSERT INTO report_exports (admin_id, kind, format, params) VALUES ($1, $2, $3, $4)
+RETURNING `+exportColumns, adminID, kind, format, params))
+}
+
+// Get returns export by id (without file data).
+func (r *Repo) Get(ctx context.Context, id uuid.UUID) (*Export, error) {
+	e, err := scanExport(r.pg.QueryRow(ctx, `SELECT `+exportColumns+` FROM report_exports WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return e, err
+}
+
+// ListByAdmin returns the admin's exports, newest first.
+func (r *Repo) ListByAdmin(ctx context.Context, adminID uuid.UUID, limit int) ([]Export, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	rows, err := r.pg.Query(ctx, `SELECT `+exportColumns+` FROM report_exports WHERE admin_id = $1 ORDER BY created_at DESC LIMIT $2`,
+		adminID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []Export
+	for rows.Next() {
+		e, err := scanExport(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *e)
+	}
+	return list, rows.Err()
+}
+
+// File returns generated file of a READY export; nil data — файла нет (не готов или удалён по сроку).
+func (r *Repo) File(ctx context.Context, id uuid.UUID) ([]byte, error) {
+	var data []byte
+	err := r.pg.QueryRow(ctx, `SELECT data FROM report_exports WHERE id = $1 AND status = $2`, id, StatusReady).Scan(&data)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// ClaimNext берёт в работу самую старую выгрузку в очереди (или зависшую в PROCESSING дольше StaleAfter).
+// nil — очередь пуста. Несколько воркеров не возьмут одну выгрузку (FOR UPDATE SKIP LOCKED).
+func (r *Repo) ClaimNext(ctx context.Context) (*Export, error) {
+	e, err := scanExport(r.pg.QueryRow(ctx, `
+UPDATE report_exports SET status = $1, started_at = now()
+WHERE id = (
+  SELECT id FROM report_exports
+  WHERE status = $2 OR (status = $1 AND started_at < now() - make_interval(secs => $3))
+  ORDER BY created_at LIMIT 1 FOR UPDATE SKIP LOCKED
+)
+RETURNING `+exportColumns, StatusProcessing, StatusPending, StaleAfter.Seconds()))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return e, err
+}
+
+// Finish сохраняет готовый файл; файл хранится ttl.
+func (r *Repo) Finish(ctx context.Context, id uuid.UUID, rowsCount int, fileName string, data []byte, ttl time.Duration) error {
+	_, err := r.pg.Exec(ctx, `
+UPDATE report_exports SET status = $2, rows_count = $3, file_name = $4, data = $5, error = NULL,
+  finished_at = now(), expires_at = now() + make_interval(secs => $6)
+WHERE id = $1`, id, StatusReady, rowsCount, fileName, data, ttl.Seconds())
+	return err
+}
+
+// Fail отмечает выгрузку как неудачную.
+func (r *Repo) Fail(ctx context.Context, id uuid.UUID, runErr error) error {
+	_, err := r.pg.Exec(ctx, `UPDATE report_exports SET status = $2, error = $3, finished_at = now() WHERE id = $1`,
+		id, StatusFailed, runErr.Error())
+	return err
+}
+
+// DeleteExpired удаляет выгрузки с истёкшим сроком хранения файла и неудачные старше суток.
+func (r *Repo) DeleteExpired(ctx context.Context) (int64, error) {
+	tag, err := r.pg.Exec(ctx, `
+DELETE FROM report_exports
+WHERE expires_at < now() OR (status = $1 AND finished_at < now() - interval '1 day')`, StatusFailed)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,239 @@
+package exports
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"sarbonNew/internal/cargo"
+	"sarbonNew/internal/currency"
+	"sarbonNew/internal/reference"
+	"sarbonNew/internal/tabular"
+	"sarbonNew/internal/trips"
+)
+
+var ErrUnknownKind = errors.New("exports: unknown report kind")
+
+// Колонки отчётов; заголовки — reference.RefLabel("export.column", <колонка>, lang).
+var columns = map[string][]string{
+	KindCargo: {"ID", "CREATED_AT", "STATUS", "COMPANY", "CREATED_BY", "TRUCK_TYPE", "WEIGHT", "VOLUME", "READY_AT",
+		"LOAD_CITY", "LOAD_ADDRESS", "UNLOAD_CITY", "UNLOAD_ADDRESS", "DISTANCE_KM", "PRICE", "CURRENCY", "OFFERS_COUNT",
+		"ADR", "CONTACT_NAME", "CONTACT_PHONE"},
+	KindTrips: {"ID", "CARGO_ID", "CREATED_AT", "STATUS", "DRIVER", "DRIVER_PHONE", "LOAD_CITY", "UNLOAD_CITY", "DISTANCE_KM",
+		"PRICE", "CURRENCY", "PENDING_DRIVER_HOURS", "ASSIGNED_HOURS", "LOADING_HOURS", "EN_ROUTE_HOURS", "UNLOADING_HOURS",
+		"TOTAL_HOURS", "COMPLETED_AT", "EXPENSES", "PROFIT"},
+	KindOffers: {"ID", "CARGO_ID", "CREATED_AT", "STATUS", "DRIVER", "DRIVER_PHONE", "LOAD_CITY", "UNLOAD_CITY", "TRUCK_TYPE",
+		"PRICE", "CURRENCY", "ROUNDS", "LAST_ROUND_BY", "EXPIRES_AT", "REJECTION_REASON", "COMMENT"},
+}
+
+// Header — локализованные заголовки колонок отчёта.
+func Header(kind, lang string) []string {
+	cols := columns[kind]
+	out := make([]string, len(cols))
+	for i, col := range cols {
+		out[i] = reference.RefLabel("export.column", col, lang)
+	}
+	return out
+}
+
+// FileName — имя файла выгрузки, например cargo_20261018_1530.xlsx.
+func FileName(kind, format string, at time.Time) string {
+	return strings.ToLower(kind) + "_" + at.Format("20060102_1504") + "." + format
+}
+
+// Generator строит отчёты по репозиториям грузов и рейсов; rates — для пересчёта расходов рейса в валюту цены.
+type Generator struct {
+	cargo *cargo.Repo
+	trips *trips.Repo
+	rates *currency.Repo
+}
+
+func NewGenerator(cargoRepo *cargo.Repo, tripsRepo *trips.Repo, ratesRepo *currency.Repo) *Generator {
+	return &Generator{cargo: cargoRepo, trips: tripsRepo, rates: ratesRepo}
+}
+
+func cargoFilter(p Params) cargo.ListFilter {
+	return cargo.ListFilter{
+		Status:      p.Status,
+		WeightMin:   p.WeightMin,
+		WeightMax:   p.WeightMax,
+		TruckType:   p.TruckType,
+		CreatedFrom: p.CreatedFrom,
+		CreatedTo:   p.CreatedTo,
+		WithOffers:  p.WithOffers,
+		Sort:        p.Sort,
+	}
+}
+
+func tripsFilter(p Params) trips.ExportFilter {
+	return trips.ExportFilter{Status: p.Status, DriverID: p.DriverID, CreatedFrom: p.CreatedFrom, CreatedTo: p.CreatedTo}
+}
+
+func offersFilter(p Params) cargo.OfferExportFilter {
+	return cargo.OfferExportFilter{Status: p.Status, CargoID: p.CargoID, CreatedFrom: p.CreatedFrom, CreatedTo: p.CreatedTo}
+}
+
+// Count returns number of rows the report will have (без заголовка).
+func (g *Generator) Count(ctx context.Context, kind string, p Params) (int, error) {
+	switch kind {
+	case KindCargo:
+		return g.cargo.Count(ctx, cargoFilter(p))
+	case KindTrips:
+		return g.trips.CountForExport(ctx, tripsFilter(p))
+	case KindOffers:
+		return g.cargo.CountOffers(ctx, offersFilter(p))
+	}
+	return 0, ErrUnknownKind
+}
+
+// Write пишет отчёт в w построчно (заголовок w уже записан) и возвращает число строк; w не закрывается.
+func (g *Generator) Write(ctx context.Context, kind string, p Params, w tabular.Writer) (int, error) {
+	n := 0
+	cities := newCityNames(p.Lang)
+	now := time.Now()
+	switch kind {
+	case KindCargo:
+		err := g.cargo.ExportRows(ctx, cargoFilter(p), func(e *cargo.ExportRow) error {
+			n++
+			return w.WriteRow(cargoRow(e, p.Lang, cities))
+		})
+		return n, err
+	case KindTrips:
+		var conv *currency.Converter
+		if g.rates != nil {
+			var err error
+			if conv, err = g.rates.ConverterOn(ctx, now); err != nil {
+				return 0, err
+			}
+		}
+		err := g.trips.ExportRows(ctx, tripsFilter(p), func(e *trips.ExportRow) error {
+			n++
+			return w.WriteRow(tripRow(e, p.Lang, cities, now, conv))
+		})
+		return n, err
+	case KindOffers:
+		err := g.cargo.ExportOffers(ctx, offersFilter(p), func(e *cargo.OfferExportRow) error {
+			n++
+			return w.WriteRow(offerRow(e, p.Lang, cities))
+		})
+		return n, err
+	}
+	return 0, ErrUnknownKind
+}
+
+func cargoRow(e *cargo.ExportRow, lang string, cities *cityNames) []any {
+	var createdBy any
+	if e.CreatedByType != nil {
+		createdBy = reference.RefLabel("cargo.created_by_type", *e.CreatedByType, lang)
+	}
+	return []any{
+		e.ID.String(), e.CreatedAt, reference.RefLabel("cargo.cargo_status", e.Status, lang), str(e.CompanyName), createdBy,
+		reference.RefLabel("cargo.truck_type", e.TruckType, lang), e.Weight, e.Volume, tm(e.ReadyAt),
+		cities.name(e.LoadCity), str(e.LoadAddress), cities.name(e.UnloadCity), str(e.UnloadAddress), num(e.DistanceKm),
+		num(e.TotalAmount), str(e.TotalCurrency), e.OffersCount,
+		e.ADREnabled, str(e.ContactName), str(e.ContactPhone),
+	}
+}
+
+// tripRow — строка отчёта по рейсам; расходы и прибыль — в валюте цены, пусто, если нет цены или курса (conv == nil — только та же валюта).
+func tripRow(e *trips.ExportRow, lang string, cities *cityNames, now time.Time, conv *currency.Converter) []any {
+	durations := trips.StatusDurations(e.History, now)
+	var total any
+	if len(e.History) > 0 {
+		end := now
+		if last := e.History[len(e.History)-1]; trips.IsFinal(last.ToStatus) {
+			end = last.ChangedAt
+		}
+		total = hours(end.Sub(e.History[0].ChangedAt))
+	}
+	statusHours := func(status string) any {
+		if d, ok := durations[status]; ok {
+			return hours(d)
+		}
+		return nil
+	}
+	var expenses, profit any
+	if e.AgreedPrice != nil && e.AgreedCurrency != nil {
+		convert := func(amount float64, from, to string) (float64, bool) {
+			if conv == nil {
+				return amount, strings.EqualFold(from, to)
+			}
+			return conv.Convert(amount, from, to)
+		}
+		if ex, pr, ok := trips.Profit(*e.AgreedPrice, *e.AgreedCurrency, e.ApprovedExpenses, convert); ok {
+			expenses, profit = ex, pr
+		}
+	}
+	return []any{
+		e.ID.String(), e.CargoID.String(), e.CreatedAt, reference.RefLabel("cargo.trip_status", e.Status, lang),
+		str(e.DriverName), str(e.DriverPhone), cities.name(e.LoadCity), cities.name(e.UnloadCity), num(e.DistanceKm),
+		num(e.AgreedPrice), str(e.AgreedCurrency),
+		statusHours(trips.StatusPendingDriver), statusHours(trips.StatusAssigned), statusHours(trips.StatusLoading),
+		statusHours(trips.StatusEnRoute), statusHours(trips.StatusUnloading), total, tm(e.CompletedAt), expenses, profit,
+	}
+}
+
+func offerRow(e *cargo.OfferExportRow, lang string, cities *cityNames) []any {
+	return []any{
+		e.ID.String(), e.CargoID.String(), e.CreatedAt, reference.RefLabel("cargo.offer_status", e.Status, lang),
+		str(e.DriverName), str(e.DriverPhone), cities.name(e.LoadCity), cities.name(e.UnloadCity),
+		reference.RefLabel("cargo.truck_type", e.TruckType, lang),
+		e.Price, e.Currency, e.RoundsCount, reference.RefLabel("export.offer_side", e.LastRoundBy, lang), tm(e.ExpiresAt),
+		str(e.RejectionReason), str(e.Comment),
+	}
+}
+
+// cityNames — название города по коду на языке отчёта (ru — русское, иначе английское); кэш на одну выгрузку.
+type cityNames struct {
+	lang string
+	m    map[string]string
+}
+
+func newCityNames(lang string) *cityNames {
+	return &cityNames{lang: strings.ToLower(lang), m: make(map[string]string)}
+}
+
+func (c *cityNames) name(code *string) any {
+	if code == nil || *code == "" {
+		return nil
+	}
+	if n, ok := c.m[*code]; ok {
+		return n
+	}
+	n := *code
+	if city, err := reference.FindCity(*code); err == nil {
+		n = city.NameRu
+		if c.lang != "ru" && city.NameEn != nil && *city.NameEn != "" {
+			n = *city.NameEn
+		}
+	}
+	c.m[*code] = n
+	return n
+}
+
+func str(p *string) any {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
+
+func num(p *float64) any {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
+
+func tm(p *time.Time) any {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
+
+func hours(d time.Duration) float64 {
+	return math.Round(d.Hours()*100) / 100
+}
@@ -0,0 +1,103 @@
+package exports
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"sarbonNew/internal/cargo"
+	"sarbonNew/internal/currency"
+	"sarbonNew/internal/trips"
+)
+
+func TestHeaderLocalized(t *testing.T) {
+	for kind, cols := range columns {
+		ru := Header(kind, "ru")
+		if len(ru) != len(cols) {
+			t.Fatalf("%s: header has %d columns, want %d", kind, len(ru), len(cols))
+		}
+		for i, col := range cols {
+			// у каждой колонки есть подпись (иначе RefLabel вернёт сам код)
+			if ru[i] == col && col != "ID" && col != "ADR" {
+				t.Errorf("%s: no label for column %s", kind, col)
+			}
+		}
+	}
+	if h := Header(KindTrips, "en"); h[3] != "Status" {
+		t.Errorf("en header: %q", h)
+	}
+}
+
+func TestRowsMatchColumns(t *testing.T) {
+	cities := newCityNames("ru")
+	tas := "TAS"
+	c := cargoRow(&cargo.ExportRow{Cargo: cargo.Cargo{ID: uuid.New(), Status: cargo.StatusSearchingAll, TruckType: "TENT"}, LoadCity: &tas}, "ru", cities)
+	if len(c) != len(columns[KindCargo]) {
+		t.Errorf("cargo row has %d values, want %d", len(c), len(columns[KindCargo]))
+	}
+	if c[2] != "В поиске (всем)" || c[9] != "Ташкент" {
+		t.Errorf("cargo row labels: %v", c)
+	}
+	o := offerRow(&cargo.OfferExportRow{Offer: cargo.Offer{ID: uuid.New(), Status: cargo.OfferStatusPending, LastRoundBy: cargo.SideShipper}}, "en", cities)
+	if len(o) != len(columns[KindOffers]) {
+		t.Errorf("offer row has %d values, want %d", len(o), len(columns[KindOffers]))
+	}
+	if o[12] != "Shipper" {
+		t.Errorf("offer last round: %v", o[12])
+	}
+}
+
+func TestTripRowDurations(t *testing.T) {
+	t0 := time.Date(2026, 5, 1, 8, 0, 0, 0, time.UTC)
+	done := t0.Add(50 * time.Hour)
+	row := tripRow(&trips.ExportRow{
+		Trip: trips.Trip{ID: uuid.New(), Status: trips.StatusCompleted},
+		History: []trips.StatusChange{
+			{ToStatus: trips.StatusPendingDriver, ChangedAt: t0},
+			{ToStatus: trips.StatusAssigned, ChangedAt: t0.Add(90 * time.Minute)},
+			{ToStatus: trips.StatusLoading, ChangedAt: t0.Add(10 * time.Hour)},
+			{ToStatus: trips.StatusEnRoute, ChangedAt: t0.Add(12 * time.Hour)},
+			{ToStatus: trips.StatusUnloading, ChangedAt: t0.Add(48 * time.Hour)},
+			{ToStatus: trips.StatusCompleted, ChangedAt: done},
+		},
+		CompletedAt: &done,
+	}, "en", newCityNames("en"), t0.Add(1000*time.Hour), nil)
+	if len(row) != len(columns[KindTrips]) {
+		t.Fatalf("trip row has %d values, want %d", len(row), len(columns[KindTrips]))
+	}
+	want := []any{1.5, 8.5, 2.0, 36.0, 2.0, 50.0}
+	for i, w := range want {
+		if row[11+i] != w {
+			t.Errorf("%s: got %v, want %v", columns[KindTrips][11+i], row[11+i], w)
+		}
+	}
+	if row[17] != done {
+		t.Errorf("completed_at: %v", row[17])
+	}
+}
+
+func TestTripRowProfit(t *testing.T) {
+	price, cur := 1000.0, "USD"
+	e := &trips.ExportRow{
+		Trip:             trips.Trip{ID: uuid.New(), Status: trips.StatusCompleted, AgreedPrice: &price, AgreedCurrency: &cur},
+		ApprovedExpenses: map[string]float64{"USD": 100, "UZS": 1250000},
+	}
+	n := len(columns[KindTrips])
+	row := tripRow(e, "en", newCityNames("en"), time.Now(), currency.NewConverter(map[string]float64{"UZS": 0.00008}))
+	if row[n-2] != 200.0 || row[n-1] != 800.0 {
+		t.Errorf("expenses/profit: %v %v", row[n-2], row[n-1])
+	}
+	// без курса сум расходы и прибыль не считаются
+	row = tripRow(e, "en", newCityNames("en"), time.Now(), nil)
+	if row[n-2] != nil || row[n-1] != nil {
+		t.Errorf("without rates: %v %v", row[n-2], row[n-1])
+	}
+}
+
+func TestFileName(t *testing.T) {
+	at := time.Date(2026, 10, 18, 15, 30, 0, 0, time.UTC)
+	if got := FileName(KindOffers, "xlsx", at); got != "offers_20261018_1530.xlsx" {
+		t.Errorf("FileName: %s", got)
+	}
+}
@@ -0,0 +1,82 @@
+package invoices
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"sarbonNew/internal/cargo"
+	"sarbonNew/internal/drivers"
+	"sarbonNew/internal/trips"
+)
+
+var (
+	ErrNotReady      = errors.New("invoices: trip has no driver or agreed price")
+	ErrNoPayer       = errors.New("invoices: cargo has no dispatcher or company owner")
+	ErrCargoNotFound = errors.New("invoices: cargo not found")
+)
+
+// Generator выставляет счёт на рейс по согласованной цене и условиям оплаты груза.
+type Generator struct {
+	repo    *Repo
+	cargo   *cargo.Repo
+	drivers *drivers.Repo
+	terms   Terms
+}
+
+func NewGenerator(repo *Repo, cargoRepo *cargo.Repo, driversRepo *drivers.Repo, terms Terms) *Generator {
+	return &Generator{repo: repo, cargo: cargoRepo, drivers: driversRepo, terms: terms}
+}
+
+// Issue возвращает счёт рейса, выставляя его при первом обращении.
+func (g *Generator) Issue(ctx context.Context, t *trips.Trip) (*Invoice, error) {
+	if inv, err := g.repo.GetByTrip(ctx, t.ID); err != nil || inv != nil {
+		return inv, err
+	}
+	if t.DriverID == nil || t.AgreedPrice == nil || t.AgreedCurrency == nil {
+		return nil, ErrNotReady
+	}
+	obj, err := g.cargo.GetByID(ctx, t.CargoID, true)
+	if err != nil {
+		return nil, err
+	}
+	if obj == nil {
+		return nil, ErrCargoNotFound
+	}
+	inv := &Invoice{TripID: t.ID, DriverID: *t.DriverID, Currency: *t.AgreedCurrency, Amount: round2(*t.AgreedPrice)}
+	// плательщик — как у уведомлений: диспетчер-создатель груза, иначе компания груза
+	switch {
+	case obj.CreatedByType != nil && *obj.CreatedByType == PartyDispatcher && obj.CreatedByID != nil:
+		inv.PayerType, inv.PayerID = PartyDispatcher, *obj.CreatedByID
+	case obj.CompanyID != nil:
+		inv.PayerType, inv.PayerID = PartyCompany, *obj.CompanyID
+	default:
+		return nil, ErrNoPayer
+	}
+	payment, err := g.cargo.GetPayment(ctx, t.CargoID)
+	if err != nil {
+		return nil, err
+	}
+	inv.PrepaymentAmount = PrepaymentShare(inv.Amount, inv.Currency, payment)
+	if inv.PrepaymentAmount > 0 {
+		due := time.Now().AddDate(0, 0, g.terms.PrepaymentDays)
+		inv.PrepaymentDueAt = &due
+	}
+	if payment != nil {
+		inv.RemainingType = payment.RemainingType
+	}
+	inv.RemainingTermDays = g.terms.RemainingTermDays(inv.RemainingType)
+	drv, err := g.drivers.FindByID(ctx, *t.DriverID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	if drv != nil && drv.CompanyID != nil {
+		if id, err := uuid.Parse(*drv.CompanyID); err == nil {
+			inv.CarrierCompanyID = &id
+		}
+	}
+	return g.repo.Create(ctx, inv)
+}
@@ -0,0 +1,220 @@
+// Package invoices — счета за перевозку и учёт оплат: счёт на рейс по согласованной цене оффера,
+// предоплата и остаток по условиям оплаты груза, платежи, просрочка и балансы компаний и водителей.
+package invoices
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"sarbonNew/internal/cargo"
+)
+
+// Статусы счёта.
+const (
+	StatusIssued        = "ISSUED"
+	StatusPartiallyPaid = "PARTIALLY_PAID"
+	StatusPaid          = "PAID"
+	StatusCancelled     = "CANCELLED"
+)
+
+// Плательщик (payer_type) — создатель груза; кто записал платёж (recorded_by_type).
+const (
+	PartyDispatcher = "DISPATCHER"
+	PartyCompany    = "COMPANY"
+	PartyDriver     = "DRIVER"
+)
+
+// Вид платежа.
+const (
+	KindPrepayment = "PREPAYMENT"
+	KindRemaining  = "REMAINING"
+	KindPartial    = "PARTIAL"
+)
+
+// IsKind — допустимый вид платежа.
+func IsKind(kind string) bool {
+	return kind == KindPrepayment || kind == KindRemaining || kind == KindPartial
+}
+
+// Invoice — счёт за рейс (invoices). DeliveredAt — из trip_pods, от него считается срок оплаты остатка.
+type Invoice struct {
+	ID                uuid.UUID
+	TripID            uuid.UUID
+	Number            string
+	PayerType         string
+	PayerID           uuid.UUID
+	DriverID          uuid.UUID
+	CarrierCompanyID  *uuid.UUID
+	Currency          string
+	Amount            float64
+	PrepaymentAmount  float64
+	PrepaymentDueAt   *time.Time
+	RemainingType     *string
+	RemainingTermDays int
+	PaidAmount        float64
+	Status            string
+	OverdueSince      *time.Time
+	IssuedAt          time.Time
+	UpdatedAt         time.Time
+	DeliveredAt       *time.Time
+}
+
+// RemainingAmount — остаток после предоплаты.
+func (inv *Invoice) RemainingAmount() float64 {
+	return round2(inv.Amount - inv.PrepaymentAmount)
+}
+
+// Outstanding — сколько ещё не оплачено.
+func (inv *Invoice) Outstanding() float64 {
+	if inv.Status == StatusCancelled {
+		return 0
+	}
+	return math.Max(0, round2(inv.Amount-inv.PaidAmount))
+}
+
+// RemainingDueAt — срок оплаты остатка: доставка + remaining_term_days; nil — груз ещё не доставлен.
+func (inv *Invoice) RemainingDueAt() *time.Time {
+	if inv.DeliveredAt == nil {
+		return nil
+	}
+	due := inv.DeliveredAt.AddDate(0, 0, inv.RemainingTermDays)
+	return &due
+}
+
+// OverdueAmount — сумма, срок оплаты которой прошёл, за вычетом оплаченного. Платежи гасят сначала предоплату, потом остаток.
+func (inv *Invoice) OverdueAmount(now time.Time) float64 {
+	if inv.Status == StatusCancelled || inv.Status == StatusPaid {
+		return 0
+	}
+	var due float64
+	if inv.PrepaymentAmount > 0 && inv.PrepaymentDueAt != nil && now.After(*inv.PrepaymentDueAt) {
+		due += inv.PrepaymentAmount
+	}
+	if at := inv.RemainingDueAt(); at != nil && now.After(*at) {
+		due += inv.RemainingAmount()
+	}
+	return math.Max(0, round2(due-inv.PaidAmount))
+}
+
+// Overdue — есть просроченная сумма.
+func (inv *Invoice) Overdue(now time.Time) bool {
+	return inv.OverdueAmount(now) > 0
+}
+
+// statusFor — статус счёта по оплаченной сумме.
+func statusFor(amount, paid float64) string {
+	switch {
+	case paid <= 0:
+		return StatusIssued
+	case round2(paid) >= round2(amount):
+		return StatusPaid
+	}
+	return StatusPartiallyPaid
+}
+
+// Payment — платёж по счёту (invoice_payments).
+type Payment struct {
+	ID             uuid.UUID
+	InvoiceID      uuid.UUID
+	Kind           string
+	Amount         float64
+	Method         *string
+	Reference      *string
+	PaidAt         time.Time
+	RecordedByType string
+	RecordedByID   uuid.UUID
+	CreatedAt      time.Time
+}
+
+// Terms — сроки оплаты: предоплата — дней после выставления счёта, остаток — дней после доставки.
+type Terms struct {
+	PrepaymentDays   int // INVOICE_PREPAYMENT_DAYS
+	AfterInvoiceDays int // INVOICE_PAYMENT_TERM_DAYS — AFTER_INVOICE и прочие условия
+	DeferredDays     int // INVOICE_DEFERRED_DAYS — отсрочка платежа
+}
+
+// RemainingTermDays — через сколько дней после доставки должен быть оплачен остаток (по payments.remaining_type).
+// ON_DELIVERY и CASH — в день доставки.
+func (t Terms) RemainingTermDays(remainingType *string) int {
+	if remainingType == nil {
+		return t.AfterInvoiceDays
+	}
+	switch strings.ToUpper(*remainingType) {
+	case "ON_DELIVERY", "CASH":
+		return 0
+	case "DEFERRED":
+		return t.DeferredDays
+	}
+	return t.AfterInvoiceDays
+}
+
+// PrepaymentShare — предоплата в валюте согласованной цены. Если предоплата указана в той же валюте — берётся как есть
+// (не больше цены); в другой валюте — пропорционально её доле в исходной цене груза; иначе предоплаты нет.
+func PrepaymentShare(agreed float64, currency string, p *cargo.Payment) float64 {
+	if p == nil || !p.WithPrepayment || p.PrepaymentAmount == nil || *p.PrepaymentAmount <= 0 || agreed <= 0 {
+		return 0
+	}
+	amount := *p.PrepaymentAmount
+	if p.PrepaymentCurrency == nil || strings.EqualFold(*p.PrepaymentCurrency, currency) {
+		return round2(math.Min(amount, agreed))
+	}
+	if p.TotalAmount != nil && *p.TotalAmount > 0 && p.TotalCurrency != nil && strings.EqualFold(*p.TotalCurrency, *p.PrepaymentCurrency) {
+		return round2(math.Min(amount / *p.TotalAmount * agreed, agreed))
+	}
+	return 0
+}
+
+// Balance — сводка по счетам в одной валюте.
+type Balance struct {
+	Currency        string
+	Invoiced        float64
+	Paid            float64
+	Outstanding     float64
+	Overdue         float64
+	Invoices        int
+	OverdueInvoices int
+}
+
+// Balances группирует счета по валюте; отменённые счета не учитываются.
+func Balances(list []Invoice, now time.Time) []Balance {
+	byCurrency := map[string]*Balance{}
+	for i := range list {
+		inv := &list[i]
+		if inv.Status == StatusCancelled {
+			continue
+		}
+		b := byCurrency[inv.Currency]
+		if b == nil {
+			b = &Balance{Currency: inv.Currency}
+			byCurrency[inv.Currency] = b
+		}
+		b.Invoices++
+		b.Invoiced = round2(b.Invoiced + inv.Amount)
+		b.Paid = round2(b.Paid + math.Min(inv.PaidAmount, inv.Amount))
+		b.Outstanding = round2(b.Outstanding + inv.Outstanding())
+		if od := inv.OverdueAmount(now); od > 0 {
+			b.Overdue = round2(b.Overdue + od)
+			b.OverdueInvoices++
+		}
+	}
+	out := make([]Balance, 0, len(byCurrency))
+	for _, b := range byCurrency {
+		out = append(out, *b)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Currency < out[j].Currency })
+	return out
+}
+
+// numberPrefix — префикс номера счёта: INV-2026-.
+func numberPrefix(year int) string {
+	return fmt.Sprintf("INV-%d-", year)
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,101 @@
+package invoices
+
+import (
+	"testing"
+	"time"
+
+	"sarbonNew/internal/cargo"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+func TestPrepaymentShare(t *testing.T) {
+	cases := []struct {
+		name string
+		p    *cargo.Payment
+		want float64
+	}{
+		{"no payment", nil, 0},
+		{"without prepayment", &cargo.Payment{PrepaymentAmount: ptr(300.0)}, 0},
+		{"same currency", &cargo.Payment{WithPrepayment: true, PrepaymentAmount: ptr(300.0), PrepaymentCurrency: ptr("USD")}, 300},
+		{"capped by price", &cargo.Payment{WithPrepayment: true, PrepaymentAmount: ptr(1500.0), PrepaymentCurrency: ptr("USD")}, 1000},
+		{"other currency by share", &cargo.Payment{WithPrepayment: true, PrepaymentAmount: ptr(3000000.0), PrepaymentCurrency: ptr("UZS"),
+			TotalAmount: ptr(12000000.0), TotalCurrency: ptr("UZS")}, 250},
+		{"other currency unknown share", &cargo.Payment{WithPrepayment: true, PrepaymentAmount: ptr(100.0), PrepaymentCurrency: ptr("EUR")}, 0},
+	}
+	for _, c := range cases {
+		if got := PrepaymentShare(1000, "USD", c.p); got != c.want {
+			t.Errorf("%s: got %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestRemainingTermDays(t *testing.T) {
+	terms := Terms{PrepaymentDays: 2, AfterInvoiceDays: 5, DeferredDays: 30}
+	cases := map[string]int{"ON_DELIVERY": 0, "CASH": 0, "AFTER_INVOICE": 5, "DEFERRED": 30, "OTHER": 5}
+	for rt, want := range cases {
+		if got := terms.RemainingTermDays(ptr(rt)); got != want {
+			t.Errorf("%s: got %d, want %d", rt, got, want)
+		}
+	}
+	if got := terms.RemainingTermDays(nil); got != 5 {
+		t.Errorf("nil: got %d, want 5", got)
+	}
+}
+
+func TestOverdueAmount(t *testing.T) {
+	issued := time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC)
+	prepayDue := issued.AddDate(0, 0, 2)
+	inv := &Invoice{Amount: 1000, PrepaymentAmount: 300, PrepaymentDueAt: &prepayDue, RemainingTermDays: 5, Status: StatusIssued}
+
+	if got := inv.OverdueAmount(issued.AddDate(0, 0, 1)); got != 0 {
+		t.Errorf("before prepayment due: %v", got)
+	}
+	if got := inv.OverdueAmount(issued.AddDate(0, 0, 3)); got != 300 {
+		t.Errorf("prepayment overdue: %v", got)
+	}
+	inv.PaidAmount, inv.Status = 300, StatusPartiallyPaid
+	delivered := issued.AddDate(0, 0, 4)
+	inv.DeliveredAt = &delivered
+	if got := inv.OverdueAmount(delivered.AddDate(0, 0, 5)); got != 0 {
+		t.Errorf("remaining due moment is not overdue yet: %v", got)
+	}
+	if got := inv.OverdueAmount(delivered.AddDate(0, 0, 6)); got != 700 {
+		t.Errorf("remaining overdue: %v", got)
+	}
+	inv.PaidAmount, inv.Status = 1000, StatusPaid
+	if inv.Overdue(delivered.AddDate(0, 0, 10)) || inv.Outstanding() != 0 {
+		t.Error("paid invoice is never overdue")
+	}
+}
+
+func TestStatusFor(t *testing.T) {
+	if s := statusFor(100, 0); s != StatusIssued {
+		t.Errorf("0: %s", s)
+	}
+	if s := statusFor(100, 40); s != StatusPartiallyPaid {
+		t.Errorf("40: %s", s)
+	}
+	if s := statusFor(100, 100.001); s != StatusPaid {
+		t.Errorf("100: %s", s)
+	}
+}
+
+func TestBalances(t *testing.T) {
+	now := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	past := now.AddDate(0, 0, -1)
+	list := []Invoice{
+		{Currency: "USD", Amount: 1000, PaidAmount: 300, Status: StatusPartiallyPaid, PrepaymentAmount: 500, PrepaymentDueAt: &past},
+		{Currency: "USD", Amount: 500, PaidAmount: 500, Status: StatusPaid},
+		{Currency: "UZS", Amount: 2000000, Status: StatusIssued},
+		{Currency: "USD", Amount: 700, Status: StatusCancelled},
+	}
+	got := Balances(list, now)
+	if len(got) != 2 || got[0].Currency != "USD" || got[1].Currency != "UZS" {
+		t.Fatalf("currencies: %+v", got)
+	}
+	usd := got[0]
+	if usd.Invoices != 2 || usd.Invoiced != 1500 || usd.Paid != 800 || usd.Outstanding != 700 || usd.Overdue != 200 || usd.OverdueInvoices != 1 {
+		t.Errorf("USD balance: %+v", usd)
+	}
+}
@@ -0,0 +1,252 @@
+package invoices
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrNotFound    = errors.New("invoice not found")
+	ErrCancelled   = errors.New("invoice cancelled")
+	ErrAlreadyPaid = errors.New("invoice already paid")
+	ErrOverpayment = errors.New("payment exceeds outstanding amount")
+)
+
+type Repo struct {
+	pg *pgxpool.Pool
+}
+
+func NewRepo(pg *pgxpool.Pool) *Repo {
+	return &Repo{pg: pg}
+}
+
+const invoiceSelect = `SELECT i.id, i.trip_id, i.number, i.payer_type, i.payer_id, i.driver_id, i.carrier_company_id, i.currency,
+  i.amount, i.prepayment_amount, i.prepayment_due_at, i.remaining_type, i.remaining_term_days, i.paid_amount, i.status,
+  i.overdue_since, i.issued_at, i.updated_at, p.delivered_at
+FROM invoices i LEFT JOIN trip_pods p ON p.trip_id = i.trip_id`
+
+func scanInvoice(row pgx.Row) (*Invoice, error) {
+	var inv Invoice
+	err := row.Scan(&inv.ID, &inv.TripID, &inv.Number, &inv.PayerType, &inv.PayerID, &inv.DriverID, &inv.CarrierCompanyID, &inv.Currency,
+		&inv.Amount, &inv.PrepaymentAmount, &inv.PrepaymentDueAt, &inv.RemainingType, &inv.RemainingTermDays, &inv.PaidAmount, &inv.Status,
+		&inv.OverdueSince, &inv.IssuedAt, &inv.UpdatedAt, &inv.DeliveredAt)
+	if err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// Create выставляет счёт на рейс; если счёт уже есть — возвращает существующий (один счёт на рейс).
+func (r *Repo) Create(ctx context.Context, inv *Invoice) (*Invoice, error) {
+	now := time.Now()
+	_, err := r.pg.Exec(ctx, `
+INSERT INTO invoices (trip_id, number, payer_type, payer_id, driver_id, carrier_company_id, currency, amount,
+  prepayment_amount, prepayment_due_at, remaining_type, remaining_term_days, issued_at)
+VALUES ($1, $2 || lpad(nextval('invoice_number_seq')::text, 6, '0'), $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+ON CONFLICT (trip_id) DO NOTHING`,
+		inv.TripID, numberPrefix(now.Year()), inv.PayerType, inv.PayerID, inv.DriverID, inv.CarrierCompanyID, inv.Currency, inv.Amount,
+		inv.PrepaymentAmount, inv.PrepaymentDueAt, inv.RemainingType, inv.RemainingTermDays, now)
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByTrip(ctx, inv.TripID)
+}
+
+// GetByTrip returns invoice of the trip (nil — счёт ещё не выставлен).
+func (r *Repo) GetByTrip(ctx context.Context, tripID uuid.UUID) (*Invoice, error) {
+	inv, err := scanInvoice(r.pg.QueryRow(ctx, invoiceSelect+` WHERE i.trip_id = $1`, tripID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return inv, err
+}
+
+// Filter — выборка счетов: по плательщику, водителю или компании перевозчика; Status и OverdueOnly — необязательно.
+type Filter struct {
+	PayerType        string
+	PayerID          *uuid.UUID
+	DriverID         *uuid.UUID
+	CarrierCompanyID *uuid.UUID
+	Status           string
+	OverdueOnly      bool
+	Limit            int // 0 — без ограничения (для балансов)
+}
+
+// List returns invoices by filter, newest first.
+func (r *Repo) List(ctx context.Context, f Filter) ([]Invoice, error) {
+	var where []string
+	var args []any
+	add := func(cond string, v any) {
+		args = append(args, v)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+	if f.PayerID != nil {
+		add("i.payer_type = $%d", f.PayerType)
+		add("i.payer_id = $%d", *f.PayerID)
+	}
+	if f.DriverID != nil {
+		add("i.driver_id = $%d", *f.DriverID)
+	}
+	if f.CarrierCompanyID != nil {
+		add("i.carrier_company_id = $%d", *f.CarrierCompanyID)
+	}
+	if f.Status != "" {
+		add("i.status = $%d", f.Status)
+	}
+	if f.OverdueOnly {
+		where = append(where, "i.overdue_since IS NOT NULL")
+	}
+	q := invoiceSelect
+	if len(where) > 0 {
+		q += " WHERE " + strings.Join(where, " AND ")
+	}
+	q += " ORDER BY i.issued_at DESC"
+	if f.Limit > 0 {
+		q += fmt.Sprintf(" LIMIT %d", f.Limit)
+	}
+	rows, err := r.pg.Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []Invoice
+	for rows.Next() {
+		inv, err := scanInvoice(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *inv)
+	}
+	return list, rows.Err()
+}
+
+// AddPayment записывает платёж и пересчитывает оплаченную сумму и статус счёта; переплата не допускается.
+func (r *Repo) AddPayment(ctx context.Context, invoiceID uuid.UUID, p Payment) (*Payment, error) {
+	tx, err := r.pg.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	var amount, paid float64
+	var status string
+	err = tx.QueryRow(ctx, `SELECT amount, paid_amount, status FROM invoices WHERE id = $1 FOR UPDATE`, invoiceID).Scan(&amount, &paid, &status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case status == StatusCancelled:
+		return nil, ErrCancelled
+	case status == StatusPaid:
+		return nil, ErrAlreadyPaid
+	case round2(paid+p.Amount) > round2(amount):
+		return nil, ErrOverpayment
+	}
+	err = tx.QueryRow(ctx, `
+INSERT INTO invoice_payments (invoice_id, kind, amount, method, reference, paid_at, recorded_by_type, recorded_by_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, created_at`,
+		invoiceID, p.Kind, p.Amount, p.Method, p.Reference, p.PaidAt, p.RecordedByType, p.RecordedByID).Scan(&p.ID, &p.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	paid = round2(paid + p.Amount)
+	status = statusFor(amount, paid)
+	_, err = tx.Exec(ctx, `
+UPDATE invoices SET paid_amount = $2, status = $3, overdue_since = CASE WHEN $3 = 'PAID' THEN NULL ELSE overdue_since END, updated_at = now()
+WHERE id = $1`, invoiceID, paid, status)
+	if err != nil {
+		return nil, err
+	}
+	p.InvoiceID = invoiceID
+	return &p, tx.Commit(ctx)
+}
+
+// Payments returns payments of the invoice in payment order.
+func (r *Repo) Payments(ctx context.Context, invoiceID uuid.UUID) ([]Payment, error) {
+	rows, err := r.pg.Query(ctx, `
+SELECT id, invoice_id, kind, amount, method, reference, paid_at, recorded_by_type, recorded_by_id, created_at
+FROM invoice_payments WHERE invoice_id = $1 ORDER BY paid_at, created_at`, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []Payment
+	for rows.Next() {
+		var p Payment
+		if err := rows.Scan(&p.ID, &p.InvoiceID, &p.Kind, &p.Amount, &p.Method, &p.Reference, &p.PaidAt, &p.RecordedByType, &p.RecordedByID, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, p)
+	}
+	return list, rows.Err()
+}
+
+// TripsWithoutInvoice — рейсы, по которым пора выставить счёт: водитель приступил к погрузке (LOADING и далее),
+// цена согласована, у груза есть плательщик (как в Generator.Issue), счёта ещё нет. Рейсы без плательщика
+// не отбираются — иначе они навсегда занимали бы начало очереди.
+func (r *Repo) TripsWithoutInvoice(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	rows, err := r.pg.Query(ctx, `
+SELECT t.id FROM trips t JOIN cargo c ON c.id = t.cargo_id
+WHERE t.status IN ('LOADING', 'EN_ROUTE', 'UNLOADING', 'COMPLETED') AND t.driver_id IS NOT NULL AND t.agreed_price IS NOT NULL
+  AND ((c.created_by_type = 'DISPATCHER' AND c.created_by_id IS NOT NULL) OR c.company_id IS NOT NULL)
+  AND NOT EXISTS (SELECT 1 FROM invoices i WHERE i.trip_id = t.id)
+ORDER BY t.updated_at LIMIT $1`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// CancelForCancelledTrips отменяет неоплаченные счета отменённых рейсов; счета с платежами остаются для расчётов.
+func (r *Repo) CancelForCancelledTrips(ctx context.Context) (int64, error) {
+	tag, err := r.pg.Exec(ctx, `
+UPDATE invoices i SET status = 'CANCELLED', overdue_since = NULL, updated_at = now()
+FROM trips t WHERE t.id = i.trip_id AND t.status = 'CANCELLED' AND i.status = 'ISSUED' AND i.paid_amount = 0`)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// Open returns unpaid invoices (ISSUED, PARTIALLY_PAID) — для проверки просрочки.
+func (r *Repo) Open(ctx context.Context) ([]Invoice, error) {
+	rows, err := r.pg.Query(ctx, invoiceSelect+` WHERE i.status IN ('ISSUED', 'PARTIALLY_PAID')`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []Invoice
+	for rows.Next() {
+		inv, err := scanInvoice(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *inv)
+	}
+	return list, rows.Err()
+}
+
+// SetOverdue отмечает начало просрочки (since) или снимает её (nil).
+func (r *Repo) SetOverdue(ctx context.Context, id uuid.UUID, since *time.Time) error {
+	_, err := r.pg.Exec(ctx, `UPDATE invoices SET overdue_since = $2, updated_at = now() WHERE id = $1`, id, since)
+	return err
+}
@@ -0,0 +1,79 @@
+// Интеграционный тест очереди выставления счетов. Запуск с БД: TEST_DATABASE_URL или DATABASE_URL заданы.
+package invoices
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func testPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	connStr := os.Getenv("TEST_DATABASE_URL")
+	if connStr == "" {
+		connStr = os.Getenv("DATABASE_URL")
+	}
+	if connStr == "" {
+		t.Skip("TEST_DATABASE_URL or DATABASE_URL required for integration test")
+	}
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, connStr)
+	if err != nil {
+		t.Fatalf("pgxpool.New: %v", err)
+	}
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		t.Fatalf("pool.Ping: %v", err)
+	}
+	return pool
+}
+
+// TestTripsWithoutInvoice_SkipsTripsWithoutPayer: рейс, груз которого без плательщика, не попадает в очередь
+// и не загораживает следующий рейс, даже будучи самым старым.
+func TestTripsWithoutInvoice_SkipsTripsWithoutPayer(t *testing.T) {
+	pool := testPool(t)
+	defer pool.Close()
+	ctx := context.Background()
+
+	var driverID uuid.UUID
+	if err := pool.QueryRow(ctx, `INSERT INTO drivers (phone) VALUES ($1) RETURNING id`, "+7998"+uuid.New().String()[:8]).Scan(&driverID); err != nil {
+		t.Fatalf("insert driver: %v", err)
+	}
+	trip := func(createdByType *string, createdByID *uuid.UUID, updatedAt time.Time) uuid.UUID {
+		var cargoID, tripID uuid.UUID
+		if err := pool.QueryRow(ctx, `
+INSERT INTO cargo (weight, volume, truck_type, status, created_by_type, created_by_id) VALUES (10, 20, 'TENT', 'ASSIGNED', $1, $2) RETURNING id`,
+			createdByType, createdByID).Scan(&cargoID); err != nil {
+			t.Fatalf("insert cargo: %v", err)
+		}
+		if err := pool.QueryRow(ctx, `
+INSERT INTO trips (cargo_id, offer_id, driver_id, status, agreed_price, agreed_currency, updated_at)
+VALUES ($1, $2, $3, 'COMPLETED', 1000, 'USD', $4) RETURNING id`,
+			cargoID, uuid.New(), driverID, updatedAt).Scan(&tripID); err != nil {
+			t.Fatalf("insert trip: %v", err)
+		}
+		t.Cleanup(func() { _, _ = pool.Exec(context.Background(), `DELETE FROM cargo WHERE id = $1`, cargoID) })
+		return tripID
+	}
+	dispatcher, dispatcherID := "DISPATCHER", uuid.New()
+	noPayer := trip(nil, nil, time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC))
+	payable := trip(&dispatcher, &dispatcherID, time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC))
+	t.Cleanup(func() { _, _ = pool.Exec(context.Background(), `DELETE FROM drivers WHERE id = $1`, driverID) })
+
+	ids, err := NewRepo(pool).TripsWithoutInvoice(ctx, 1)
+	if err != nil {
+		t.Fatalf("TripsWithoutInvoice: %v", err)
+	}
+	for _, id := range ids {
+		if id == noPayer {
+			t.Fatal("trip without payer is queued")
+		}
+	}
+	if len(ids) != 1 || ids[0] != payable {
+		t.Errorf("oldest payable trip must be first: got %v, want %v", ids, payable)
+	}
+}
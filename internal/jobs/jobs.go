@@ -0,0 +1,32 @@
+// Package jobs — периодические фоновые задачи API (истечение офферов и т.п.).
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Every запускает fn сразу и затем каждые interval, пока ctx не отменён. Не блокирует.
+// interval <= 0 — задача отключена.
+func Every(ctx context.Context, logger *zap.Logger, name string, interval time.Duration, fn func(ctx context.Context) error) {
+	if interval <= 0 {
+		logger.Info("job disabled", zap.String("job", name))
+		return
+	}
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			if err := fn(ctx); err != nil && ctx.Err() == nil {
+				logger.Error("job failed", zap.String("job", name), zap.Error(err))
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+			}
+		}
+	}()
+}
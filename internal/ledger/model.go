@@ -0,0 +1,253 @@
+// Package ledger — заработок водителей и комиссия фриланс-диспетчеров: соглашения о комиссии диспетчер—водитель,
+// журнал проводок (двойная запись) по завершённым рейсам и выписки за период.
+package ledger
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"sarbonNew/internal/currency"
+)
+
+// Тип комиссии диспетчера.
+const (
+	CommissionPercent = "PERCENT"
+	CommissionFixed   = "FIXED"
+)
+
+// Счета журнала.
+const (
+	AccountFreightPayable       = "FREIGHT_PAYABLE"       // долг плательщика (создателя груза) за перевозку
+	AccountDriverEarnings       = "DRIVER_EARNINGS"       // заработок водителя
+	AccountDispatcherCommission = "DISPATCHER_COMMISSION" // комиссия диспетчера
+)
+
+// Владелец счёта (owner_type).
+const (
+	OwnerDriver     = "DRIVER"
+	OwnerDispatcher = "DISPATCHER"
+	OwnerCompany    = "COMPANY"
+)
+
+// Сторона проводки.
+const (
+	SideDebit  = "DEBIT"
+	SideCredit = "CREDIT"
+)
+
+// KindTripCompleted — проводка по завершённому рейсу (одна на рейс).
+const KindTripCompleted = "TRIP_COMPLETED"
+
+// Повтор неудачной проводки: через postRetryBase, дальше интервал удваивается до postRetryMax.
+const (
+	postRetryBase = 15 * time.Minute
+	postRetryMax  = 24 * time.Hour
+)
+
+// PostRetryDelay — через сколько повторить проводку рейса после attempts неудачных попыток подряд.
+func PostRetryDelay(attempts int) time.Duration {
+	d := postRetryBase
+	for i := 1; i < attempts && d < postRetryMax; i++ {
+		d *= 2
+	}
+	return min(d, postRetryMax)
+}
+
+// Agreement — соглашение о комиссии диспетчера с водителем (dispatcher_commission_agreements).
+type Agreement struct {
+	ID            uuid.UUID
+	DispatcherID  uuid.UUID
+	DriverID      uuid.UUID
+	Type          string
+	Percent       *float64
+	FixedAmount   *float64
+	FixedCurrency *string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// Validate проверяет тип и значение комиссии; возвращает false для неполного соглашения.
+func (a *Agreement) Validate() bool {
+	switch a.Type {
+	case CommissionPercent:
+		return a.Percent != nil && *a.Percent > 0 && *a.Percent <= 100
+	case CommissionFixed:
+		return a.FixedAmount != nil && *a.FixedAmount > 0 && a.FixedCurrency != nil && *a.FixedCurrency != ""
+	}
+	return false
+}
+
+// Commission — комиссия с рейса в валюте цены; не больше цены. Фиксированная комиссия в другой валюте пересчитывается
+// по курсам conv; ok=false — курса нет.
+func (a *Agreement) Commission(price float64, cur string, conv *currency.Converter) (float64, bool) {
+	if price <= 0 {
+		return 0, true
+	}
+	var v float64
+	switch a.Type {
+	case CommissionPercent:
+		if a.Percent == nil {
+			return 0, true
+		}
+		v = price * *a.Percent / 100
+	case CommissionFixed:
+		if a.FixedAmount == nil || a.FixedCurrency == nil {
+			return 0, true
+		}
+		v = *a.FixedAmount
+		if !strings.EqualFold(*a.FixedCurrency, cur) {
+			if conv == nil {
+				return 0, false
+			}
+			converted, ok := conv.Convert(v, *a.FixedCurrency, cur)
+			if !ok {
+				return 0, false
+			}
+			v = converted
+		}
+	default:
+		return 0, true
+	}
+	return round2(math.Min(v, price)), true
+}
+
+// Transaction — проводка по рейсу (ledger_transactions) с условиями комиссии на момент проводки.
+type Transaction struct {
+	ID                uuid.UUID
+	TripID            uuid.UUID
+	Kind              string
+	Currency          string
+	Amount            float64
+	Commission        float64
+	CommissionType    *string
+	CommissionPercent *float64
+	DispatcherID      *uuid.UUID
+	PostedAt          time.Time
+}
+
+// Entry — строка журнала (ledger_entries).
+type Entry struct {
+	ID            uuid.UUID
+	TransactionID uuid.UUID
+	TripID        uuid.UUID
+	Account       string
+	OwnerType     string
+	OwnerID       uuid.UUID
+	Side          string
+	Amount        float64
+	Currency      string
+	PostedAt      time.Time
+}
+
+// Signed — сумма со знаком для счетов заработка: кредит увеличивает баланс, дебет уменьшает.
+func (e *Entry) Signed() float64 {
+	if e.Side == SideDebit {
+		return -e.Amount
+	}
+	return e.Amount
+}
+
+// Party — участник проводки: плательщик, водитель, диспетчер.
+type Party struct {
+	Type string
+	ID   uuid.UUID
+}
+
+// TripPosting — исходные данные проводки по рейсу.
+type TripPosting struct {
+	TripID     uuid.UUID
+	Currency   string
+	Amount     float64
+	Payer      Party
+	DriverID   uuid.UUID
+	Dispatcher *uuid.UUID // диспетчер с комиссией; nil — без комиссии
+	Agreement  *Agreement
+	Commission float64
+}
+
+// Entries — строки проводки: долг плательщика переходит в заработок водителя, комиссия — из заработка водителя
+// диспетчеру. Сумма дебетов равна сумме кредитов.
+func (p *TripPosting) Entries() []Entry {
+	out := []Entry{
+		{Account: AccountFreightPayable, OwnerType: p.Payer.Type, OwnerID: p.Payer.ID, Side: SideDebit, Amount: p.Amount},
+		{Account: AccountDriverEarnings, OwnerType: OwnerDriver, OwnerID: p.DriverID, Side: SideCredit, Amount: p.Amount},
+	}
+	if p.Dispatcher != nil && p.Commission > 0 {
+		out = append(out,
+			Entry{Account: AccountDriverEarnings, OwnerType: OwnerDriver, OwnerID: p.DriverID, Side: SideDebit, Amount: p.Commission},
+			Entry{Account: AccountDispatcherCommission, OwnerType: OwnerDispatcher, OwnerID: *p.Dispatcher, Side: SideCredit, Amount: p.Commission},
+		)
+	}
+	for i := range out {
+		out[i].Currency = p.Currency
+	}
+	return out
+}
+
+// Balanced — дебет равен кредиту (с точностью до копейки).
+func Balanced(entries []Entry) bool {
+	var debit, credit float64
+	for _, e := range entries {
+		if e.Side == SideDebit {
+			debit += e.Amount
+		} else {
+			credit += e.Amount
+		}
+	}
+	return round2(debit) == round2(credit)
+}
+
+// StatementLine — итоги выписки в одной валюте: начальный остаток, начисления (кредит), списания (дебет), конечный остаток.
+type StatementLine struct {
+	Currency string
+	Opening  float64
+	Credits  float64
+	Debits   float64
+	Closing  float64
+	Trips    int
+}
+
+// Statement группирует строки журнала по валюте: opening — проводки до начала периода, entries — за период.
+func Statement(opening map[string]float64, entries []Entry) []StatementLine {
+	byCurrency := map[string]*StatementLine{}
+	get := func(cur string) *StatementLine {
+		l := byCurrency[cur]
+		if l == nil {
+			l = &StatementLine{Currency: cur}
+			byCurrency[cur] = l
+		}
+		return l
+	}
+	for cur, v := range opening {
+		get(cur).Opening = round2(v)
+	}
+	trips := map[string]map[uuid.UUID]bool{}
+	for _, e := range entries {
+		l := get(e.Currency)
+		if e.Side == SideDebit {
+			l.Debits = round2(l.Debits + e.Amount)
+		} else {
+			l.Credits = round2(l.Credits + e.Amount)
+		}
+		if trips[e.Currency] == nil {
+			trips[e.Currency] = map[uuid.UUID]bool{}
+		}
+		trips[e.Currency][e.TripID] = true
+	}
+	out := make([]StatementLine, 0, len(byCurrency))
+	for cur, l := range byCurrency {
+		l.Closing = round2(l.Opening + l.Credits - l.Debits)
+		l.Trips = len(trips[cur])
+		out = append(out, *l)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Currency < out[j].Currency })
+	return out
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,106 @@
+package ledger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"sarbonNew/internal/currency"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+func TestCommission(t *testing.T) {
+	conv := currency.NewConverter(map[string]float64{"UZS": 0.00008})
+	cases := []struct {
+		name string
+		a    Agreement
+		conv *currency.Converter
+		want float64
+		ok   bool
+	}{
+		{"percent", Agreement{Type: CommissionPercent, Percent: ptr(10.0)}, nil, 100, true},
+		{"fixed same currency", Agreement{Type: CommissionFixed, FixedAmount: ptr(50.0), FixedCurrency: ptr("usd")}, nil, 50, true},
+		{"fixed capped by price", Agreement{Type: CommissionFixed, FixedAmount: ptr(5000.0), FixedCurrency: ptr("USD")}, nil, 1000, true},
+		{"fixed other currency", Agreement{Type: CommissionFixed, FixedAmount: ptr(625000.0), FixedCurrency: ptr("UZS")}, conv, 50, true},
+		{"fixed no rate", Agreement{Type: CommissionFixed, FixedAmount: ptr(10.0), FixedCurrency: ptr("EUR")}, conv, 0, false},
+		{"fixed without converter", Agreement{Type: CommissionFixed, FixedAmount: ptr(10.0), FixedCurrency: ptr("UZS")}, nil, 0, false},
+	}
+	for _, c := range cases {
+		got, ok := c.a.Commission(1000, "USD", c.conv)
+		if got != c.want || ok != c.ok {
+			t.Errorf("%s: got %v/%v, want %v/%v", c.name, got, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		a    Agreement
+		want bool
+	}{
+		{Agreement{Type: CommissionPercent, Percent: ptr(15.0)}, true},
+		{Agreement{Type: CommissionPercent, Percent: ptr(0.0)}, false},
+		{Agreement{Type: CommissionPercent, Percent: ptr(101.0)}, false},
+		{Agreement{Type: CommissionFixed, FixedAmount: ptr(20.0), FixedCurrency: ptr("USD")}, true},
+		{Agreement{Type: CommissionFixed, FixedAmount: ptr(20.0)}, false},
+		{Agreement{Type: "OTHER", Percent: ptr(5.0)}, false},
+	}
+	for i, c := range cases {
+		if got := c.a.Validate(); got != c.want {
+			t.Errorf("case %d: got %v, want %v", i, got, c.want)
+		}
+	}
+}
+
+func TestTripPostingEntries(t *testing.T) {
+	dispatcher := uuid.New()
+	p := &TripPosting{TripID: uuid.New(), Currency: "USD", Amount: 1000, Payer: Party{Type: OwnerCompany, ID: uuid.New()},
+		DriverID: uuid.New(), Dispatcher: &dispatcher, Commission: 120}
+	entries := p.Entries()
+	if len(entries) != 4 || !Balanced(entries) {
+		t.Fatalf("entries: %+v", entries)
+	}
+	var net float64
+	for _, e := range entries {
+		if e.Account == AccountDriverEarnings {
+			net += e.Signed()
+		}
+	}
+	if net != 880 {
+		t.Errorf("driver net: got %v, want 880", net)
+	}
+	p.Dispatcher = nil
+	if entries := p.Entries(); len(entries) != 2 || !Balanced(entries) {
+		t.Errorf("without commission: %+v", entries)
+	}
+}
+
+func TestStatement(t *testing.T) {
+	trip1, trip2 := uuid.New(), uuid.New()
+	entries := []Entry{
+		{TripID: trip1, Side: SideCredit, Amount: 1000, Currency: "USD"},
+		{TripID: trip1, Side: SideDebit, Amount: 100, Currency: "USD"},
+		{TripID: trip2, Side: SideCredit, Amount: 5000000, Currency: "UZS"},
+	}
+	lines := Statement(map[string]float64{"USD": 250}, entries)
+	if len(lines) != 2 {
+		t.Fatalf("lines: %+v", lines)
+	}
+	if l := lines[0]; l.Currency != "USD" || l.Opening != 250 || l.Credits != 1000 || l.Debits != 100 || l.Closing != 1150 || l.Trips != 1 {
+		t.Errorf("USD: %+v", l)
+	}
+	if l := lines[1]; l.Currency != "UZS" || l.Closing != 5000000 || l.Trips != 1 {
+		t.Errorf("UZS: %+v", l)
+	}
+}
+
+func TestPostRetryDelay(t *testing.T) {
+	cases := map[int]time.Duration{1: 15 * time.Minute, 2: 30 * time.Minute, 3: time.Hour, 7: 16 * time.Hour, 8: 24 * time.Hour, 100: 24 * time.Hour}
+	for attempts, want := range cases {
+		if got := PostRetryDelay(attempts); got != want {
+			t.Errorf("%d: got %v, want %v", attempts, got, want)
+		}
+	}
+}
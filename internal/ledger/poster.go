@@ -0,0 +1,90 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"sarbonNew/internal/cargo"
+	"sarbonNew/internal/currency"
+	"sarbonNew/internal/drivers"
+	"sarbonNew/internal/trips"
+)
+
+var (
+	ErrNotCompleted  = errors.New("ledger: trip is not completed or has no driver and agreed price")
+	ErrNoPayer       = errors.New("ledger: cargo has no dispatcher or company owner")
+	ErrCargoNotFound = errors.New("ledger: cargo not found")
+	ErrNoRate        = errors.New("ledger: no currency rate for fixed commission")
+)
+
+// Poster проводит завершённые рейсы: заработок водителя и комиссия диспетчера, к которому водитель привязан
+// (drivers.freelancer_id), по соглашению на момент проводки.
+type Poster struct {
+	repo    *Repo
+	cargo   *cargo.Repo
+	drivers *drivers.Repo
+	rates   *currency.Repo
+}
+
+func NewPoster(repo *Repo, cargoRepo *cargo.Repo, driversRepo *drivers.Repo, ratesRepo *currency.Repo) *Poster {
+	return &Poster{repo: repo, cargo: cargoRepo, drivers: driversRepo, rates: ratesRepo}
+}
+
+// Post проводит рейс; повторный вызов для уже проведённого рейса возвращает (nil, nil).
+func (p *Poster) Post(ctx context.Context, t *trips.Trip) (*Transaction, error) {
+	if t.Status != trips.StatusCompleted || t.DriverID == nil || t.AgreedPrice == nil || t.AgreedCurrency == nil {
+		return nil, ErrNotCompleted
+	}
+	obj, err := p.cargo.GetByID(ctx, t.CargoID, true)
+	if err != nil {
+		return nil, err
+	}
+	if obj == nil {
+		return nil, ErrCargoNotFound
+	}
+	posting := &TripPosting{TripID: t.ID, Currency: *t.AgreedCurrency, Amount: round2(*t.AgreedPrice), DriverID: *t.DriverID}
+	// плательщик — как у счетов: диспетчер-создатель груза, иначе компания груза
+	switch {
+	case obj.CreatedByType != nil && *obj.CreatedByType == OwnerDispatcher && obj.CreatedByID != nil:
+		posting.Payer = Party{Type: OwnerDispatcher, ID: *obj.CreatedByID}
+	case obj.CompanyID != nil:
+		posting.Payer = Party{Type: OwnerCompany, ID: *obj.CompanyID}
+	default:
+		return nil, ErrNoPayer
+	}
+	drv, err := p.drivers.FindByID(ctx, *t.DriverID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	if drv != nil && drv.FreelancerID != nil {
+		if dispatcherID, err := uuid.Parse(*drv.FreelancerID); err == nil {
+			if err := p.applyCommission(ctx, posting, dispatcherID); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return p.repo.PostTrip(ctx, posting)
+}
+
+func (p *Poster) applyCommission(ctx context.Context, posting *TripPosting, dispatcherID uuid.UUID) error {
+	a, err := p.repo.GetAgreement(ctx, dispatcherID, posting.DriverID)
+	if err != nil || a == nil {
+		return err
+	}
+	commission, ok := a.Commission(posting.Amount, posting.Currency, nil)
+	if !ok {
+		conv, err := p.rates.ConverterOn(ctx, time.Now())
+		if err != nil {
+			return err
+		}
+		if commission, ok = a.Commission(posting.Amount, posting.Currency, conv); !ok {
+			return ErrNoRate
+		}
+	}
+	posting.Dispatcher, posting.Agreement, posting.Commission = &dispatcherID, a, commission
+	return nil
+}
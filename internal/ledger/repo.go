@@ -0,0 +1,220 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrUnbalanced = errors.New("ledger: debits do not equal credits")
+
+type Repo struct {
+	pg *pgxpool.Pool
+}
+
+func NewRepo(pg *pgxpool.Pool) *Repo {
+	return &Repo{pg: pg}
+}
+
+const agreementCols = `id, dispatcher_id, driver_id, type, percent, fixed_amount, fixed_currency, created_at, updated_at`
+
+func scanAgreement(row pgx.Row) (*Agreement, error) {
+	var a Agreement
+	if err := row.Scan(&a.ID, &a.DispatcherID, &a.DriverID, &a.Type, &a.Percent, &a.FixedAmount, &a.FixedCurrency, &a.CreatedAt, &a.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// UpsertAgreement задаёт комиссию диспетчера с водителя; новые условия действуют для рейсов, завершённых после изменения.
+func (r *Repo) UpsertAgreement(ctx context.Context, a *Agreement) (*Agreement, error) {
+	return scanAgreement(r.pg.QueryRow(ctx, `
+INSERT INTO dispatcher_commission_agreements (dispatcher_id, driver_id, type, percent, fixed_amount, fixed_currency)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (dispatcher_id, driver_id) DO UPDATE SET type = EXCLUDED.type, percent = EXCLUDED.percent,
+  fixed_amount = EXCLUDED.fixed_amount, fixed_currency = EXCLUDED.fixed_currency, updated_at = now()
+RETURNING `+agreementCols, a.DispatcherID, a.DriverID, a.Type, a.Percent, a.FixedAmount, a.FixedCurrency))
+}
+
+// GetAgreement returns agreement of the pair (nil — комиссия не задана).
+func (r *Repo) GetAgreement(ctx context.Context, dispatcherID, driverID uuid.UUID) (*Agreement, error) {
+	a, err := scanAgreement(r.pg.QueryRow(ctx, `SELECT `+agreementCols+` FROM dispatcher_commission_agreements
+WHERE dispatcher_id = $1 AND driver_id = $2`, dispatcherID, driverID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return a, err
+}
+
+// ListAgreements returns agreements of the dispatcher.
+func (r *Repo) ListAgreements(ctx context.Context, dispatcherID uuid.UUID) ([]Agreement, error) {
+	rows, err := r.pg.Query(ctx, `SELECT `+agreementCols+` FROM dispatcher_commission_agreements
+WHERE dispatcher_id = $1 ORDER BY created_at`, dispatcherID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []Agreement
+	for rows.Next() {
+		a, err := scanAgreement(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *a)
+	}
+	return list, rows.Err()
+}
+
+// DeleteAgreement убирает комиссию; уже проведённые рейсы не меняются.
+func (r *Repo) DeleteAgreement(ctx context.Context, dispatcherID, driverID uuid.UUID) (bool, error) {
+	tag, err := r.pg.Exec(ctx, `DELETE FROM dispatcher_commission_agreements WHERE dispatcher_id = $1 AND driver_id = $2`, dispatcherID, driverID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// TripsToPost — завершённые рейсы с водителем, согласованной ценой и плательщиком (как в Poster.Post), по которым ещё
+// нет проводки. Рейсы без плательщика не отбираются, рейсы с неудачной проводкой — только после next_attempt_at:
+// иначе они навсегда занимали бы начало очереди.
+func (r *Repo) TripsToPost(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	rows, err := r.pg.Query(ctx, `
+SELECT t.id FROM trips t JOIN cargo c ON c.id = t.cargo_id
+WHERE t.status = 'COMPLETED' AND t.driver_id IS NOT NULL AND t.agreed_price IS NOT NULL
+  AND ((c.created_by_type = 'DISPATCHER' AND c.created_by_id IS NOT NULL) OR c.company_id IS NOT NULL)
+  AND NOT EXISTS (SELECT 1 FROM ledger_transactions lt WHERE lt.trip_id = t.id AND lt.kind = 'TRIP_COMPLETED')
+  AND NOT EXISTS (SELECT 1 FROM ledger_post_failures f WHERE f.trip_id = t.id AND f.next_attempt_at > now())
+ORDER BY t.updated_at LIMIT $1`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// RecordPostFailure отмечает неудачную проводку рейса; следующая попытка — через PostRetryDelay от числа неудач подряд.
+// Возвращает число неудач.
+func (r *Repo) RecordPostFailure(ctx context.Context, tripID uuid.UUID, cause error, now time.Time) (int, error) {
+	tx, err := r.pg.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+	attempts := 0
+	err = tx.QueryRow(ctx, `SELECT attempts FROM ledger_post_failures WHERE trip_id = $1 FOR UPDATE`, tripID).Scan(&attempts)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return 0, err
+	}
+	attempts++
+	_, err = tx.Exec(ctx, `
+INSERT INTO ledger_post_failures (trip_id, attempts, last_error, next_attempt_at) VALUES ($1, $2, $3, $4)
+ON CONFLICT (trip_id) DO UPDATE SET attempts = EXCLUDED.attempts, last_error = EXCLUDED.last_error,
+  next_attempt_at = EXCLUDED.next_attempt_at, updated_at = now()`,
+		tripID, attempts, cause.Error(), now.Add(PostRetryDelay(attempts)))
+	if err != nil {
+		return 0, err
+	}
+	return attempts, tx.Commit(ctx)
+}
+
+// PostTrip записывает проводку по рейсу и её строки в одной транзакции. Повторная проводка рейса — (nil, nil).
+func (r *Repo) PostTrip(ctx context.Context, p *TripPosting) (*Transaction, error) {
+	entries := p.Entries()
+	if !Balanced(entries) {
+		return nil, ErrUnbalanced
+	}
+	tx, err := r.pg.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	t := Transaction{TripID: p.TripID, Kind: KindTripCompleted, Currency: p.Currency, Amount: p.Amount}
+	if p.Dispatcher != nil && p.Commission > 0 {
+		t.Commission, t.DispatcherID = p.Commission, p.Dispatcher
+		if p.Agreement != nil {
+			t.CommissionType, t.CommissionPercent = &p.Agreement.Type, p.Agreement.Percent
+		}
+	}
+	err = tx.QueryRow(ctx, `
+INSERT INTO ledger_transactions (trip_id, kind, currency, amount, commission, commission_type, commission_percent, dispatcher_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (trip_id, kind) DO NOTHING
+RETURNING id, posted_at`,
+		t.TripID, t.Kind, t.Currency, t.Amount, t.Commission, t.CommissionType, t.CommissionPercent, t.DispatcherID).Scan(&t.ID, &t.PostedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM ledger_post_failures WHERE trip_id = $1`, t.TripID); err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		_, err = tx.Exec(ctx, `
+INSERT INTO ledger_entries (transaction_id, account, owner_type, owner_id, side, amount, currency, posted_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+			t.ID, e.Account, e.OwnerType, e.OwnerID, e.Side, e.Amount, e.Currency, t.PostedAt)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &t, tx.Commit(ctx)
+}
+
+// Entries returns entries of the owner's account for [from, to), in posting order.
+func (r *Repo) Entries(ctx context.Context, account, ownerType string, ownerID uuid.UUID, from, to time.Time) ([]Entry, error) {
+	rows, err := r.pg.Query(ctx, `
+SELECT e.id, e.transaction_id, lt.trip_id, e.account, e.owner_type, e.owner_id, e.side, e.amount, e.currency, e.posted_at
+FROM ledger_entries e JOIN ledger_transactions lt ON lt.id = e.transaction_id
+WHERE e.account = $1 AND e.owner_type = $2 AND e.owner_id = $3 AND e.posted_at >= $4 AND e.posted_at < $5
+ORDER BY e.posted_at, e.side DESC`, account, ownerType, ownerID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []Entry
+	for rows.Next() {
+		var e Entry
+		if err := rows.Scan(&e.ID, &e.TransactionID, &e.TripID, &e.Account, &e.OwnerType, &e.OwnerID, &e.Side, &e.Amount, &e.Currency, &e.PostedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+	}
+	return list, rows.Err()
+}
+
+// Balances — остаток счёта владельца по валютам на момент before (кредит минус дебет).
+func (r *Repo) Balances(ctx context.Context, account, ownerType string, ownerID uuid.UUID, before time.Time) (map[string]float64, error) {
+	rows, err := r.pg.Query(ctx, `
+SELECT currency, COALESCE(SUM(CASE WHEN side = 'CREDIT' THEN amount ELSE -amount END), 0)
+FROM ledger_entries
+WHERE account = $1 AND owner_type = $2 AND owner_id = $3 AND posted_at < $4
+GROUP BY currency`, account, ownerType, ownerID, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := map[string]float64{}
+	for rows.Next() {
+		var cur string
+		var v float64
+		if err := rows.Scan(&cur, &v); err != nil {
+			return nil, err
+		}
+		out[cur] = v
+	}
+	return out, rows.Err()
+}
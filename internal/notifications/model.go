@@ -0,0 +1,49 @@
+// Package notifications — уведомления водителей, диспетчеров и компаний внутри приложения.
+// Уведомление сохраняется в БД и, если получатель онлайн, отправляется по websocket чата.
+package notifications
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Тип получателя.
+const (
+	RecipientDriver     = "DRIVER"
+	RecipientDispatcher = "DISPATCHER"
+	RecipientCompany    = "COMPANY"
+)
+
+// Виды уведомлений.
+const (
+	KindOfferWithdrawn   = "OFFER_WITHDRAWN"
+	KindOfferExpired     = "OFFER_EXPIRED"
+	KindAuctionWon       = "AUCTION_WON"
+	KindAuctionClosed    = "AUCTION_CLOSED"
+	KindTripDelivered    = "TRIP_DELIVERED"
+	KindPODDisputed      = "POD_DISPUTED"
+	KindPaymentRecorded  = "PAYMENT_RECORDED"
+	KindInvoiceOverdue   = "INVOICE_OVERDUE"
+	KindExpenseSubmitted = "EXPENSE_SUBMITTED"
+	KindCustomsHeld      = "CUSTOMS_HELD"
+	KindExpenseReviewed  = "EXPENSE_REVIEWED"
+	KindTripCancelled    = "TRIP_CANCELLED"
+	KindTripAssigned     = "TRIP_ASSIGNED"
+	KindDriverSwapped    = "DRIVER_SWAPPED"
+	KindIncidentReported = "INCIDENT_REPORTED"
+	KindIncidentSOS      = "INCIDENT_SOS"
+	KindIncidentUpdated  = "INCIDENT_UPDATED"
+	KindCargoProposed    = "CARGO_PROPOSED" // грузоотправитель предложил груз по объявлению о свободной машине
+)
+
+// Notification model (table notifications).
+type Notification struct {
+	ID            uuid.UUID
+	RecipientType string
+	RecipientID   uuid.UUID
+	Kind          string
+	Payload       map[string]any
+	ReadAt        *time.Time
+	CreatedAt     time.Time
+}
@@ -0,0 +1,70 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Pusher доставляет событие онлайн-пользователю (chat.Hub).
+type Pusher interface {
+	SendToUser(userID uuid.UUID, payload []byte)
+}
+
+// Notifier сохраняет уведомления и отправляет их онлайн-получателям.
+type Notifier struct {
+	repo   *Repo
+	push   Pusher
+	logger *zap.Logger
+}
+
+// NewNotifier; push может быть nil (фоновые задачи без websocket) — тогда уведомления только сохраняются.
+func NewNotifier(repo *Repo, push Pusher, logger *zap.Logger) *Notifier {
+	return &Notifier{repo: repo, push: push, logger: logger}
+}
+
+// Notify сохраняет уведомление и отправляет событие {"type":"notification"} водителю или диспетчеру.
+// Ошибки только логируются: уведомление не должно ломать основное действие.
+func (n *Notifier) Notify(ctx context.Context, recipientType string, recipientID uuid.UUID, kind string, payload map[string]any) {
+	if n == nil || recipientID == uuid.Nil {
+		return
+	}
+	item := &Notification{RecipientType: recipientType, RecipientID: recipientID, Kind: kind, Payload: payload}
+	if err := n.repo.Create(ctx, item); err != nil {
+		n.logger.Error("notification create", zap.Error(err), zap.String("kind", kind))
+		return
+	}
+	if n.push == nil || recipientType == RecipientCompany {
+		return
+	}
+	raw, _ := json.Marshal(map[string]any{
+		"type": "notification",
+		"data": map[string]any{"id": item.ID.String(), "kind": kind, "payload": payload, "created_at": item.CreatedAt},
+	})
+	n.push.SendToUser(recipientID, raw)
+}
+
+// NotifyCompany сохраняет уведомление компании и, в отличие от Notify, сразу отправляет событие
+// онлайн-пользователям компании userIDs (срочные оповещения, например SOS водителя).
+func (n *Notifier) NotifyCompany(ctx context.Context, companyID uuid.UUID, userIDs []uuid.UUID, kind string, payload map[string]any) {
+	if n == nil || companyID == uuid.Nil {
+		return
+	}
+	item := &Notification{RecipientType: RecipientCompany, RecipientID: companyID, Kind: kind, Payload: payload}
+	if err := n.repo.Create(ctx, item); err != nil {
+		n.logger.Error("notification create", zap.Error(err), zap.String("kind", kind))
+		return
+	}
+	if n.push == nil {
+		return
+	}
+	raw, _ := json.Marshal(map[string]any{
+		"type": "notification",
+		"data": map[string]any{"id": item.ID.String(), "kind": kind, "payload": payload, "created_at": item.CreatedAt, "company_id": companyID.String()},
+	})
+	for _, id := range userIDs {
+		n.push.SendToUser(id, raw)
+	}
+}
@@ -0,0 +1,82 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repo struct {
+	pg *pgxpool.Pool
+}
+
+func NewRepo(pg *pgxpool.Pool) *Repo {
+	return &Repo{pg: pg}
+}
+
+// Create сохраняет уведомление; ID и CreatedAt заполняются из БД.
+func (r *Repo) Create(ctx context.Context, n *Notification) error {
+	payload, err := json.Marshal(n.Payload)
+	if err != nil {
+		return err
+	}
+	return r.pg.QueryRow(ctx, `
+INSERT INTO notifications (recipient_type, recipient_id, kind, payload)
+VALUES ($1, $2, $3, $4) RETURNING id, created_at`,
+		n.RecipientType, n.RecipientID, n.Kind, payload).Scan(&n.ID, &n.CreatedAt)
+}
+
+// List возвращает уведомления получателя (новые сверху) и число непрочитанных.
+func (r *Repo) List(ctx context.Context, recipientType string, recipientID uuid.UUID, unreadOnly bool, limit, offset int) ([]Notification, int, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	rows, err := r.pg.Query(ctx, `
+SELECT id, recipient_type, recipient_id, kind, payload, read_at, created_at
+FROM notifications
+WHERE recipient_type = $1 AND recipient_id = $2 AND (NOT $3 OR read_at IS NULL)
+ORDER BY created_at DESC
+LIMIT $4 OFFSET $5`, recipientType, recipientID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	var list []Notification
+	for rows.Next() {
+		var n Notification
+		var payload []byte
+		if err := rows.Scan(&n.ID, &n.RecipientType, &n.RecipientID, &n.Kind, &payload, &n.ReadAt, &n.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		_ = json.Unmarshal(payload, &n.Payload)
+		list = append(list, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	var unread int
+	err = r.pg.QueryRow(ctx, `SELECT COUNT(*) FROM notifications WHERE recipient_type = $1 AND recipient_id = $2 AND read_at IS NULL`,
+		recipientType, recipientID).Scan(&unread)
+	return list, unread, err
+}
+
+// MarkRead отмечает уведомление прочитанным. false — уведомление не найдено у этого получателя.
+func (r *Repo) MarkRead(ctx context.Context, id uuid.UUID, recipientType string, recipientID uuid.UUID) (bool, error) {
+	tag, err := r.pg.Exec(ctx, `
+UPDATE notifications SET read_at = COALESCE(read_at, now())
+WHERE id = $1 AND recipient_type = $2 AND recipient_id = $3`, id, recipientType, recipientID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// MarkAllRead отмечает все уведомления получателя прочитанными.
+func (r *Repo) MarkAllRead(ctx context.Context, recipientType string, recipientID uuid.UUID) error {
+	_, err := r.pg.Exec(ctx, `
+UPDATE notifications SET read_at = now()
+WHERE recipient_type = $1 AND recipient_id = $2 AND read_at IS NULL`, recipientType, recipientID)
+	return err
+}
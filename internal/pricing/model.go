@@ -0,0 +1,67 @@
+// Package pricing — рекомендация цены по направлению (lane) на основе принятых офферов.
+package pricing
+
+import "time"
+
+// Уровни агрегации: от самого точного к самому общему. Берётся первый уровень с достаточной выборкой.
+const (
+	LevelExact       = "LANE_TRUCK_WEIGHT_SEASON"
+	LevelLaneTruck   = "LANE_TRUCK"
+	LevelLane        = "LANE"
+	LevelOriginTruck = "ORIGIN_TRUCK"
+	LevelTruck       = "TRUCK"
+)
+
+// Query — параметры запроса рекомендации.
+type Query struct {
+	OriginRegion string // region_code (или city_code) основной погрузки
+	DestRegion   string // region_code (или city_code) основной выгрузки
+	TruckType    string
+	WeightTons   float64
+	Currency     string
+	DistanceKm   float64 // оценка расстояния нового груза (internal/routing)
+	At           time.Time
+}
+
+// Suggestion — рекомендуемый диапазон цены: P25..P75 ставки за км × расстояние, медиана — рекомендуемая цена.
+type Suggestion struct {
+	Currency    string
+	DistanceKm  float64
+	Min         float64
+	Median      float64
+	Max         float64
+	PerKmMin    float64
+	PerKmMedian float64
+	PerKmMax    float64
+	Samples     int
+	Level       string
+}
+
+// WeightBand возвращает диапазон веса [min, max) в тоннах, в который попадает груз.
+// max = 0 — без верхней границы.
+func WeightBand(tons float64) (min, max float64) {
+	switch {
+	case tons < 5:
+		return 0, 5
+	case tons < 10:
+		return 5, 10
+	case tons < 20:
+		return 10, 20
+	default:
+		return 20, 0
+	}
+}
+
+// SeasonMonths возвращает месяцы сезона, в который попадает дата (зима: 12, 1, 2 и т.д.).
+func SeasonMonths(t time.Time) []int {
+	switch t.Month() {
+	case time.December, time.January, time.February:
+		return []int{12, 1, 2}
+	case time.March, time.April, time.May:
+		return []int{3, 4, 5}
+	case time.June, time.July, time.August:
+		return []int{6, 7, 8}
+	default:
+		return []int{9, 10, 11}
+	}
+}
@@ -0,0 +1,35 @@
+package pricing
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWeightBand(t *testing.T) {
+	cases := []struct {
+		tons     float64
+		min, max float64
+	}{
+		{1.5, 0, 5},
+		{5, 5, 10},
+		{19.9, 10, 20},
+		{22, 20, 0},
+	}
+	for _, c := range cases {
+		min, max := WeightBand(c.tons)
+		if min != c.min || max != c.max {
+			t.Errorf("WeightBand(%v) = [%v, %v), want [%v, %v)", c.tons, min, max, c.min, c.max)
+		}
+	}
+}
+
+func TestSeasonMonths(t *testing.T) {
+	winter := SeasonMonths(time.Date(2026, time.January, 10, 0, 0, 0, 0, time.UTC))
+	if len(winter) != 3 || winter[0] != 12 {
+		t.Fatalf("January: got %v", winter)
+	}
+	autumn := SeasonMonths(time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC))
+	if autumn[0] != 9 || autumn[2] != 11 {
+		t.Fatalf("October: got %v", autumn)
+	}
+}
@@ -0,0 +1,130 @@
+package pricing
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// MinSamples — минимум принятых офферов на уровне агрегации, чтобы ему доверять.
+const MinSamples = 3
+
+// historyMonths — за сколько месяцев берутся принятые офферы.
+const historyMonths = 24
+
+type Repo struct {
+	pg *pgxpool.Pool
+}
+
+func NewRepo(pg *pgxpool.Pool) *Repo {
+	return &Repo{pg: pg}
+}
+
+// laneSQL — ставка за км по принятым офферам в валюте $1: цена оффера переводится в базовую валюту по курсу
+// на дату оффера, затем в $1 по текущему курсу. Офферы в валютах без курса не учитываются.
+// Направление груза: регион (или город) основной погрузки и выгрузки.
+const laneSQL = `
+SELECT COUNT(x.per_km),
+       COALESCE(percentile_cont(0.25) WITHIN GROUP (ORDER BY x.per_km), 0),
+       COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY x.per_km), 0),
+       COALESCE(percentile_cont(0.75) WITHIN GROUP (ORDER BY x.per_km), 0)
+FROM (
+  SELECT (o.price * currency_rate_to_base(o.currency, o.created_at::date) / currency_rate_to_base($1, CURRENT_DATE))::float8
+           / c.distance_km::float8 AS per_km,
+         o.created_at, c.truck_type, c.weight,
+         (SELECT COALESCE(NULLIF(rp.region_code, ''), rp.city_code) FROM route_points rp
+          WHERE rp.cargo_id = c.id ORDER BY rp.is_main_load DESC, (rp.type = 'LOAD') DESC, rp.point_order LIMIT 1) AS origin,
+         (SELECT COALESCE(NULLIF(rp.region_code, ''), rp.city_code) FROM route_points rp
+          WHERE rp.cargo_id = c.id ORDER BY rp.is_main_unload DESC, (rp.type = 'UNLOAD') DESC, rp.point_order DESC LIMIT 1) AS dest
+  FROM offers o
+  JOIN cargo c ON c.id = o.cargo_id
+  WHERE o.status = 'ACCEPTED' AND c.distance_km > 0
+    AND o.created_at >= now() - make_interval(months => $2)
+) x
+WHERE true`
+
+type level struct {
+	name   string
+	origin bool
+	dest   bool
+	truck  bool
+	weight bool
+	season bool
+}
+
+var levels = []level{
+	{name: LevelExact, origin: true, dest: true, truck: true, weight: true, season: true},
+	{name: LevelLaneTruck, origin: true, dest: true, truck: true},
+	{name: LevelLane, origin: true, dest: true},
+	{name: LevelOriginTruck, origin: true, truck: true},
+	{name: LevelTruck, truck: true},
+}
+
+// Suggest возвращает рекомендуемый диапазон цены для груза. Если данных нет ни на одном уровне — (nil, nil).
+func (r *Repo) Suggest(ctx context.Context, q Query) (*Suggestion, error) {
+	if q.DistanceKm <= 0 {
+		return nil, nil
+	}
+	currency := strings.ToUpper(strings.TrimSpace(q.Currency))
+	for _, lv := range levels {
+		if (lv.origin && q.OriginRegion == "") || (lv.dest && q.DestRegion == "") {
+			continue
+		}
+		sql := laneSQL
+		args := []any{currency, historyMonths}
+		add := func(cond string, v any) {
+			args = append(args, v)
+			sql += " AND " + strings.ReplaceAll(cond, "?", "$"+strconv.Itoa(len(args)))
+		}
+		if lv.truck {
+			add("x.truck_type = ?", strings.ToUpper(q.TruckType))
+		}
+		if lv.origin {
+			add("x.origin = ?", q.OriginRegion)
+		}
+		if lv.dest {
+			add("x.dest = ?", q.DestRegion)
+		}
+		if lv.weight {
+			min, max := WeightBand(q.WeightTons)
+			add("x.weight >= ?", min)
+			if max > 0 {
+				add("x.weight < ?", max)
+			}
+		}
+		if lv.season {
+			add("EXTRACT(MONTH FROM x.created_at)::int = ANY(?)", SeasonMonths(q.At))
+		}
+
+		var (
+			n             int
+			p25, p50, p75 float64
+		)
+		if err := r.pg.QueryRow(ctx, sql, args...).Scan(&n, &p25, &p50, &p75); err != nil {
+			return nil, err
+		}
+		if n < MinSamples {
+			continue
+		}
+		return &Suggestion{
+			Currency:    currency,
+			DistanceKm:  q.DistanceKm,
+			Min:         round2(p25 * q.DistanceKm),
+			Median:      round2(p50 * q.DistanceKm),
+			Max:         round2(p75 * q.DistanceKm),
+			PerKmMin:    round2(p25),
+			PerKmMedian: round2(p50),
+			PerKmMax:    round2(p75),
+			Samples:     n,
+			Level:       lv.name,
+		}, nil
+	}
+	return nil, nil
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
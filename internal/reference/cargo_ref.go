@@ -75,6 +75,71 @@ var LoadingTypeRefs = []RefItem{
 	{Value: "OTHER", Label: "Другое"},
 }
 
+// ReviewTagRefs — теги отзыва после рейса (UPPERCASE). Водитель о заказчике и заказчик о водителе.
+var ReviewTagRefs = []RefItem{
+	{Value: "ON_TIME", Label: "Вовремя"},
+	{Value: "CARGO_INTACT", Label: "Груз в сохранности"},
+	{Value: "PAID_ON_TIME", Label: "Оплата вовремя"},
+	{Value: "POLITE", Label: "Вежливость"},
+	{Value: "ACCURATE_DESCRIPTION", Label: "Груз соответствует описанию"},
+	{Value: "LATE", Label: "Опоздание"},
+	{Value: "CARGO_DAMAGED", Label: "Груз повреждён"},
+	{Value: "PAYMENT_DELAYED", Label: "Задержка оплаты"},
+}
+
+// ExpenseCategoryRefs — категории расходов водителя в рейсе (UPPERCASE).
+var ExpenseCategoryRefs = []RefItem{
+	{Value: "FUEL", Label: "Топливо"},
+	{Value: "TOLL", Label: "Платная дорога"},
+	{Value: "PARKING", Label: "Стоянка"},
+	{Value: "CUSTOMS", Label: "Таможенные сборы"},
+	{Value: "OTHER", Label: "Другое"},
+}
+
+// CustomsStatusRefs — статусы прохождения таможни на точке CUSTOMS (UPPERCASE).
+var CustomsStatusRefs = []RefItem{
+	{Value: "QUEUED", Label: "В очереди"},
+	{Value: "DECLARATION_SUBMITTED", Label: "Декларация подана"},
+	{Value: "INSPECTION", Label: "Досмотр"},
+	{Value: "CLEARED", Label: "Выпущен"},
+	{Value: "HELD", Label: "Задержан"},
+}
+
+// TripCancelReasonRefs — причины отмены рейса и замены водителя (UPPERCASE); FORCE_MAJEURE — без штрафа.
+var TripCancelReasonRefs = []RefItem{
+	{Value: "DRIVER_UNAVAILABLE", Label: "Водитель не может выполнить рейс"},
+	{Value: "DRIVER_ILLNESS", Label: "Болезнь водителя"},
+	{Value: "VEHICLE_BREAKDOWN", Label: "Поломка машины"},
+	{Value: "DRIVER_NO_SHOW", Label: "Водитель не приехал"},
+	{Value: "CARGO_NOT_READY", Label: "Груз не готов"},
+	{Value: "CARGO_MISMATCH", Label: "Груз не соответствует описанию"},
+	{Value: "PRICE_DISAGREEMENT", Label: "Разногласия по цене"},
+	{Value: "DOCUMENTS_ISSUE", Label: "Проблемы с документами"},
+	{Value: "SHIPPER_CANCELLED", Label: "Заказ отменён грузоотправителем"},
+	{Value: "FORCE_MAJEURE", Label: "Форс-мажор"},
+	{Value: "OTHER", Label: "Другое"},
+}
+
+// IncidentTypeRefs — типы инцидентов водителя в рейсе (UPPERCASE).
+var IncidentTypeRefs = []RefItem{
+	{Value: "BREAKDOWN", Label: "Поломка"},
+	{Value: "ACCIDENT", Label: "ДТП"},
+	{Value: "POLICE_STOP", Label: "Остановка полицией"},
+	{Value: "CARGO_DAMAGE", Label: "Повреждение груза"},
+	{Value: "THEFT", Label: "Кража"},
+	{Value: "MEDICAL", Label: "Проблемы со здоровьем"},
+	{Value: "ROAD_BLOCKED", Label: "Дорога перекрыта"},
+	{Value: "OTHER", Label: "Другое"},
+}
+
+// IncidentSeverityRefs — серьёзность инцидента (UPPERCASE); SOS всегда CRITICAL.
+var IncidentSeverityRefs = []RefItem{
+	{Value: "LOW", Label: "Низкая"},
+	{Value: "MEDIUM", Label: "Средняя"},
+	{Value: "HIGH", Label: "Высокая"},
+	{Value: "CRITICAL", Label: "Критическая"},
+}
+
 // AllowedValues возвращает слайс допустимых value в ВЕРХНЕМ регистре (для валидации и хранения).
 func AllowedValues(items []RefItem) []string {
 	out := make([]string, 0, len(items))
@@ -105,6 +170,24 @@ func AllowedRoutePointTypes() []string { return AllowedValues(RoutePointTypeRefs
 // AllowedTruckTypes возвращает допустимые truck_type (UPPERCASE).
 func AllowedTruckTypes() []string { return AllowedValues(TruckTypeRefs) }
 
+// AllowedReviewTags возвращает допустимые теги отзыва (UPPERCASE).
+func AllowedReviewTags() []string { return AllowedValues(ReviewTagRefs) }
+
+// AllowedExpenseCategories возвращает допустимые категории расходов (UPPERCASE).
+func AllowedExpenseCategories() []string { return AllowedValues(ExpenseCategoryRefs) }
+
+// AllowedCustomsStatuses возвращает допустимые статусы таможни (UPPERCASE).
+func AllowedCustomsStatuses() []string { return AllowedValues(CustomsStatusRefs) }
+
+// AllowedTripCancelReasons возвращает допустимые причины отмены рейса (UPPERCASE).
+func AllowedTripCancelReasons() []string { return AllowedValues(TripCancelReasonRefs) }
+
+// AllowedIncidentTypes возвращает допустимые типы инцидентов (UPPERCASE).
+func AllowedIncidentTypes() []string { return AllowedValues(IncidentTypeRefs) }
+
+// AllowedIncidentSeverities возвращает допустимую серьёзность инцидента (UPPERCASE).
+func AllowedIncidentSeverities() []string { return AllowedValues(IncidentSeverityRefs) }
+
 // IsAllowed проверяет, что value есть в списке (приводит к верхнему регистру для сравнения).
 func IsAllowed(value string, allowed []string) bool {
 	v := strings.ToUpper(strings.TrimSpace(value))
@@ -3,6 +3,7 @@
 package reference
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
@@ -110,3 +111,43 @@ func CitiesByCountry(countryCode string) ([]CityRef, error) {
 	}
 	return out, nil
 }
+
+var (
+	ErrCityNotFound  = errors.New("city not found")
+	ErrCityAmbiguous = errors.New("city code is ambiguous, use CODE-COUNTRY (e.g. TAS-UZ)")
+)
+
+// FindCity ищет город по ID (TAS-UZ) или коду (TAS). Код сначала ищется в дополнительном списке (UZ, AE, TM, KG, TJ);
+// код, встречающийся в нескольких странах основного списка, — ErrCityAmbiguous.
+func FindCity(code string) (*CityRef, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return nil, ErrCityNotFound
+	}
+	for i := range supplementalCities {
+		if c := &supplementalCities[i]; c.ID == code || c.Code == code {
+			return c, nil
+		}
+	}
+	list, err := LoadCities()
+	if err != nil {
+		return nil, err
+	}
+	var found *CityRef
+	for i := range list {
+		c := &list[i]
+		if c.ID == code {
+			return c, nil
+		}
+		if c.Code == code {
+			if found != nil && found.CountryCode != c.CountryCode {
+				return nil, ErrCityAmbiguous
+			}
+			found = c
+		}
+	}
+	if found == nil {
+		return nil, ErrCityNotFound
+	}
+	return found, nil
+}
@@ -66,3 +66,21 @@ func TestCitiesByCountry(t *testing.T) {
 		t.Errorf("expected 10000+ cities total, got %d", len(all))
 	}
 }
+
+func TestFindCity(t *testing.T) {
+	for _, code := range []string{"TAS", "tas", "TAS-UZ", " DXB "} {
+		c, err := FindCity(code)
+		if err != nil || c == nil || c.Lat == nil || c.Lng == nil {
+			t.Errorf("FindCity(%q) = %+v, %v", code, c, err)
+		}
+	}
+	if c, _ := FindCity("TAS"); c != nil && c.CountryCode != "UZ" {
+		t.Errorf("TAS: got country %q, want UZ", c.CountryCode)
+	}
+	if _, err := FindCity("QQQ-ZZ"); err != ErrCityNotFound {
+		t.Errorf("unknown code: got %v, want ErrCityNotFound", err)
+	}
+	if _, err := FindCity(""); err != ErrCityNotFound {
+		t.Errorf("empty code: got %v, want ErrCityNotFound", err)
+	}
+}
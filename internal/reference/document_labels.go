@@ -0,0 +1,61 @@
+package reference
+
+// Подписи полей PDF-документов рейса (CMR, путевой лист): RefLabel("document.label", "<FIELD>", lang).
+// Китайского нет: встроенный шрифт документов не содержит CJK, документы на zh формируются на английском.
+func init() {
+	labels := map[string]map[string]string{
+		"document.label.CMR_TITLE":        {"ru": "Международная товарно-транспортная накладная", "uz": "Xalqaro tovar-transport yuk xati", "en": "International consignment note", "tr": "Uluslararası hamule senedi"},
+		"document.label.TRIP_SHEET_TITLE": {"ru": "Путевой лист", "uz": "Yo'l varaqasi", "en": "Trip sheet", "tr": "Sefer föyü"},
+		"document.label.NUMBER":           {"ru": "Номер", "uz": "Raqam", "en": "Number", "tr": "Numara"},
+		"document.label.DATE":             {"ru": "Дата", "uz": "Sana", "en": "Date", "tr": "Tarih"},
+		"document.label.TRIP":             {"ru": "Рейс", "uz": "Reys", "en": "Trip", "tr": "Sefer"},
+		"document.label.SENDER":           {"ru": "Отправитель", "uz": "Jo'natuvchi", "en": "Sender", "tr": "Gönderici"},
+		"document.label.CONSIGNEE":        {"ru": "Получатель", "uz": "Qabul qiluvchi", "en": "Consignee", "tr": "Alıcı"},
+		"document.label.CARRIER":          {"ru": "Перевозчик", "uz": "Tashuvchi", "en": "Carrier", "tr": "Taşıyıcı"},
+		"document.label.DELIVERY_PLACE":   {"ru": "Место разгрузки", "uz": "Tushirish joyi", "en": "Place of delivery", "tr": "Teslim yeri"},
+		"document.label.TAKING_OVER":      {"ru": "Место и дата погрузки", "uz": "Yuklash joyi va sanasi", "en": "Place and date of taking over the goods", "tr": "Yükleme yeri ve tarihi"},
+		"document.label.DOCUMENTS":        {"ru": "Прилагаемые документы", "uz": "Ilova qilingan hujjatlar", "en": "Documents attached", "tr": "Ekli belgeler"},
+		"document.label.GOODS":            {"ru": "Груз", "uz": "Yuk", "en": "Goods", "tr": "Yük"},
+		"document.label.SHIPMENT_TYPE":    {"ru": "Вид отправки", "uz": "Jo'natma turi", "en": "Shipment type", "tr": "Sevkiyat türü"},
+		"document.label.LOADING_TYPES":    {"ru": "Способ погрузки", "uz": "Yuklash usuli", "en": "Loading method", "tr": "Yükleme şekli"},
+		"document.label.TRUCK_TYPE":       {"ru": "Тип кузова", "uz": "Kuzov turi", "en": "Truck type", "tr": "Kasa tipi"},
+		"document.label.WEIGHT":           {"ru": "Вес брутто, т", "uz": "Brutto og'irlik, t", "en": "Gross weight, t", "tr": "Brüt ağırlık, t"},
+		"document.label.VOLUME":           {"ru": "Объём, м³", "uz": "Hajm, m³", "en": "Volume, m³", "tr": "Hacim, m³"},
+		"document.label.ADR_CLASS":        {"ru": "Опасный груз (ADR), класс", "uz": "Xavfli yuk (ADR), sinf", "en": "Dangerous goods (ADR), class", "tr": "Tehlikeli madde (ADR), sınıf"},
+		"document.label.TEMPERATURE":      {"ru": "Температурный режим, °C", "uz": "Harorat rejimi, °C", "en": "Temperature, °C", "tr": "Sıcaklık, °C"},
+		"document.label.INSTRUCTIONS":     {"ru": "Указания отправителя", "uz": "Jo'natuvchi ko'rsatmalari", "en": "Sender's instructions", "tr": "Göndericinin talimatları"},
+		"document.label.PAYMENT":          {"ru": "Условия оплаты", "uz": "To'lov shartlari", "en": "Payment terms", "tr": "Ödeme koşulları"},
+		"document.label.PRICE":            {"ru": "Стоимость перевозки", "uz": "Tashish narxi", "en": "Carriage charges", "tr": "Taşıma ücreti"},
+		"document.label.PREPAYMENT":       {"ru": "Предоплата", "uz": "Oldindan to'lov", "en": "Prepayment", "tr": "Ön ödeme"},
+		"document.label.REMAINING":        {"ru": "Остаток", "uz": "Qoldiq", "en": "Remaining", "tr": "Kalan"},
+		"document.label.VEHICLE":          {"ru": "Транспортное средство", "uz": "Transport vositasi", "en": "Vehicle", "tr": "Araç"},
+		"document.label.TRACTOR":          {"ru": "Тягач", "uz": "Shatakchi", "en": "Tractor", "tr": "Çekici"},
+		"document.label.TRAILER":          {"ru": "Прицеп", "uz": "Tirkama", "en": "Trailer", "tr": "Römork"},
+		"document.label.TECH_PASSPORT":    {"ru": "Техпаспорт", "uz": "Texpasport", "en": "Registration certificate", "tr": "Ruhsat"},
+		"document.label.OWNER":            {"ru": "Владелец", "uz": "Egasi", "en": "Owner", "tr": "Sahibi"},
+		"document.label.DRIVER":           {"ru": "Водитель", "uz": "Haydovchi", "en": "Driver", "tr": "Sürücü"},
+		"document.label.PHONE":            {"ru": "Телефон", "uz": "Telefon", "en": "Phone", "tr": "Telefon"},
+		"document.label.PASSPORT":         {"ru": "Паспорт", "uz": "Pasport", "en": "Passport", "tr": "Pasaport"},
+		"document.label.PINFL":            {"ru": "ПИНФЛ", "uz": "JShShIR", "en": "PINFL", "tr": "PINFL"},
+		"document.label.INN":              {"ru": "ИНН", "uz": "STIR", "en": "TIN", "tr": "VKN"},
+		"document.label.ESTABLISHED":      {"ru": "Составлено", "uz": "Tuzilgan", "en": "Established in", "tr": "Düzenlendiği yer"},
+		"document.label.SIGN_SENDER":      {"ru": "Подпись и печать отправителя", "uz": "Jo'natuvchi imzosi va muhri", "en": "Signature and stamp of the sender", "tr": "Göndericinin imza ve kaşesi"},
+		"document.label.SIGN_CARRIER":     {"ru": "Подпись и печать перевозчика", "uz": "Tashuvchi imzosi va muhri", "en": "Signature and stamp of the carrier", "tr": "Taşıyıcının imza ve kaşesi"},
+		"document.label.SIGN_CONSIGNEE":   {"ru": "Груз получен: подпись и печать получателя", "uz": "Yuk qabul qilindi: qabul qiluvchi imzosi va muhri", "en": "Goods received: signature and stamp of the consignee", "tr": "Yük teslim alındı: alıcının imza ve kaşesi"},
+		"document.label.SIGN_DISPATCHER":  {"ru": "Диспетчер", "uz": "Dispetcher", "en": "Dispatcher", "tr": "Dispeçer"},
+		"document.label.SIGN_DRIVER":      {"ru": "Водитель (подпись)", "uz": "Haydovchi (imzo)", "en": "Driver (signature)", "tr": "Sürücü (imza)"},
+		"document.label.ROUTE":            {"ru": "Маршрут", "uz": "Marshrut", "en": "Route", "tr": "Güzergah"},
+		"document.label.POINT_TYPE":       {"ru": "Тип", "uz": "Turi", "en": "Type", "tr": "Tür"},
+		"document.label.CITY":             {"ru": "Город", "uz": "Shahar", "en": "City", "tr": "Şehir"},
+		"document.label.ADDRESS":          {"ru": "Адрес", "uz": "Manzil", "en": "Address", "tr": "Adres"},
+		"document.label.LANDMARK":         {"ru": "Ориентир", "uz": "Mo'ljal", "en": "Landmark", "tr": "Tarif"},
+		"document.label.COMMENT":          {"ru": "Комментарий", "uz": "Izoh", "en": "Comment", "tr": "Yorum"},
+		"document.label.DISTANCE":         {"ru": "Расстояние, км", "uz": "Masofa, km", "en": "Distance, km", "tr": "Mesafe, km"},
+		"document.label.DURATION":         {"ru": "Время в пути, ч", "uz": "Yo'ldagi vaqt, soat", "en": "Driving time, h", "tr": "Yol süresi, sa"},
+		"document.label.READY_AT":         {"ru": "Готов к погрузке", "uz": "Yuklashga tayyor", "en": "Ready for loading", "tr": "Yüklemeye hazır"},
+		"document.label.CONTACT":          {"ru": "Контактное лицо", "uz": "Aloqa shaxsi", "en": "Contact person", "tr": "İletişim kişisi"},
+	}
+	for k, v := range labels {
+		refLabels[k] = v
+	}
+}
@@ -0,0 +1,49 @@
+package reference
+
+// Заголовки колонок отчётов (выгрузки CSV/XLSX): RefLabel("export.column", "<COLUMN>", lang).
+func init() {
+	columns := map[string]map[string]string{
+		"export.column.ID":                   {"ru": "ID", "uz": "ID", "en": "ID", "tr": "ID", "zh": "ID"},
+		"export.column.CARGO_ID":             {"ru": "ID груза", "uz": "Yuk ID", "en": "Cargo ID", "tr": "Yük ID", "zh": "货物ID"},
+		"export.column.CREATED_AT":           {"ru": "Создан", "uz": "Yaratilgan", "en": "Created at", "tr": "Oluşturulma", "zh": "创建时间"},
+		"export.column.STATUS":               {"ru": "Статус", "uz": "Holat", "en": "Status", "tr": "Durum", "zh": "状态"},
+		"export.column.COMPANY":              {"ru": "Компания", "uz": "Kompaniya", "en": "Company", "tr": "Şirket", "zh": "公司"},
+		"export.column.CREATED_BY":           {"ru": "Кто создал", "uz": "Kim yaratgan", "en": "Created by", "tr": "Oluşturan", "zh": "创建者"},
+		"export.column.TRUCK_TYPE":           {"ru": "Тип кузова", "uz": "Kuzov turi", "en": "Truck type", "tr": "Kasa tipi", "zh": "车型"},
+		"export.column.WEIGHT":               {"ru": "Вес, т", "uz": "Og'irlik, t", "en": "Weight, t", "tr": "Ağırlık, t", "zh": "重量(吨)"},
+		"export.column.VOLUME":               {"ru": "Объём, м³", "uz": "Hajm, m³", "en": "Volume, m³", "tr": "Hacim, m³", "zh": "体积(m³)"},
+		"export.column.READY_AT":             {"ru": "Готов к погрузке", "uz": "Yuklashga tayyor", "en": "Ready at", "tr": "Yüklemeye hazır", "zh": "可装货时间"},
+		"export.column.LOAD_CITY":            {"ru": "Город погрузки", "uz": "Yuklash shahri", "en": "Load city", "tr": "Yükleme şehri", "zh": "装货城市"},
+		"export.column.LOAD_ADDRESS":         {"ru": "Адрес погрузки", "uz": "Yuklash manzili", "en": "Load address", "tr": "Yükleme adresi", "zh": "装货地址"},
+		"export.column.UNLOAD_CITY":          {"ru": "Город выгрузки", "uz": "Tushirish shahri", "en": "Unload city", "tr": "Boşaltma şehri", "zh": "卸货城市"},
+		"export.column.UNLOAD_ADDRESS":       {"ru": "Адрес выгрузки", "uz": "Tushirish manzili", "en": "Unload address", "tr": "Boşaltma adresi", "zh": "卸货地址"},
+		"export.column.DISTANCE_KM":          {"ru": "Расстояние, км", "uz": "Masofa, km", "en": "Distance, km", "tr": "Mesafe, km", "zh": "距离(公里)"},
+		"export.column.PRICE":                {"ru": "Цена", "uz": "Narx", "en": "Price", "tr": "Fiyat", "zh": "价格"},
+		"export.column.CURRENCY":             {"ru": "Валюта", "uz": "Valyuta", "en": "Currency", "tr": "Para birimi", "zh": "货币"},
+		"export.column.OFFERS_COUNT":         {"ru": "Офферов", "uz": "Takliflar soni", "en": "Offers", "tr": "Teklif sayısı", "zh": "报价数"},
+		"export.column.ADR":                  {"ru": "ADR", "uz": "ADR", "en": "ADR", "tr": "ADR", "zh": "ADR"},
+		"export.column.CONTACT_NAME":         {"ru": "Контактное лицо", "uz": "Aloqa shaxsi", "en": "Contact name", "tr": "İletişim kişisi", "zh": "联系人"},
+		"export.column.CONTACT_PHONE":        {"ru": "Телефон контакта", "uz": "Aloqa telefoni", "en": "Contact phone", "tr": "İletişim telefonu", "zh": "联系电话"},
+		"export.column.DRIVER":               {"ru": "Водитель", "uz": "Haydovchi", "en": "Driver", "tr": "Sürücü", "zh": "司机"},
+		"export.column.DRIVER_PHONE":         {"ru": "Телефон водителя", "uz": "Haydovchi telefoni", "en": "Driver phone", "tr": "Sürücü telefonu", "zh": "司机电话"},
+		"export.column.PENDING_DRIVER_HOURS": {"ru": "Ожидание водителя, ч", "uz": "Haydovchi kutish, soat", "en": "Pending driver, h", "tr": "Sürücü bekleme, sa", "zh": "等待司机(小时)"},
+		"export.column.ASSIGNED_HOURS":       {"ru": "Назначен, ч", "uz": "Tayinlangan, soat", "en": "Assigned, h", "tr": "Atandı, sa", "zh": "已分配(小时)"},
+		"export.column.LOADING_HOURS":        {"ru": "Погрузка, ч", "uz": "Yuklash, soat", "en": "Loading, h", "tr": "Yükleme, sa", "zh": "装货(小时)"},
+		"export.column.EN_ROUTE_HOURS":       {"ru": "В пути, ч", "uz": "Yo'lda, soat", "en": "En route, h", "tr": "Yolda, sa", "zh": "运输中(小时)"},
+		"export.column.UNLOADING_HOURS":      {"ru": "Выгрузка, ч", "uz": "Tushirish, soat", "en": "Unloading, h", "tr": "Boşaltma, sa", "zh": "卸货(小时)"},
+		"export.column.TOTAL_HOURS":          {"ru": "Всего, ч", "uz": "Jami, soat", "en": "Total, h", "tr": "Toplam, sa", "zh": "合计(小时)"},
+		"export.column.COMPLETED_AT":         {"ru": "Завершён", "uz": "Tugallangan", "en": "Completed at", "tr": "Tamamlanma", "zh": "完成时间"},
+		"export.column.EXPENSES":             {"ru": "Расходы (подтв.)", "uz": "Xarajatlar (tasdiq.)", "en": "Approved expenses", "tr": "Onaylı giderler", "zh": "已批准费用"},
+		"export.column.PROFIT":               {"ru": "Прибыль", "uz": "Foyda", "en": "Profit", "tr": "Kâr", "zh": "利润"},
+		"export.column.ROUNDS":               {"ru": "Раундов торга", "uz": "Savdo raundlari", "en": "Negotiation rounds", "tr": "Pazarlık turu", "zh": "议价轮数"},
+		"export.column.LAST_ROUND_BY":        {"ru": "Последнее предложение", "uz": "Oxirgi taklif", "en": "Last proposal by", "tr": "Son teklif veren", "zh": "最后报价方"},
+		"export.column.EXPIRES_AT":           {"ru": "Действует до", "uz": "Amal qilish muddati", "en": "Expires at", "tr": "Geçerlilik sonu", "zh": "有效期至"},
+		"export.column.REJECTION_REASON":     {"ru": "Причина отклонения", "uz": "Rad etish sababi", "en": "Rejection reason", "tr": "Ret nedeni", "zh": "拒绝原因"},
+		"export.column.COMMENT":              {"ru": "Комментарий", "uz": "Izoh", "en": "Comment", "tr": "Yorum", "zh": "备注"},
+		"export.offer_side.DRIVER":           {"ru": "Водитель", "uz": "Haydovchi", "en": "Driver", "tr": "Sürücü", "zh": "司机"},
+		"export.offer_side.SHIPPER":          {"ru": "Грузоотправитель", "uz": "Yuk jo'natuvchi", "en": "Shipper", "tr": "Gönderici", "zh": "发货人"},
+	}
+	for k, v := range columns {
+		refLabels[k] = v
+	}
+}
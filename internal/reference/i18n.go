@@ -68,6 +68,8 @@ var refLabels = map[string]map[string]string{
 	"cargo.offer_status.PENDING":     {"ru": "На рассмотрении", "uz": "Ko'rib chiqilmoqda", "en": "Pending", "tr": "Beklemede", "zh": "待处理"},
 	"cargo.offer_status.ACCEPTED":    {"ru": "Принят", "uz": "Qabul qilindi", "en": "Accepted", "tr": "Kabul edildi", "zh": "已接受"},
 	"cargo.offer_status.REJECTED":    {"ru": "Отклонён", "uz": "Rad etilgan", "en": "Rejected", "tr": "Reddedildi", "zh": "已拒绝"},
+	"cargo.offer_status.EXPIRED":     {"ru": "Истёк срок", "uz": "Muddati o'tgan", "en": "Expired", "tr": "Süresi doldu", "zh": "已过期"},
+	"cargo.offer_status.WITHDRAWN":   {"ru": "Отозван", "uz": "Qaytarib olingan", "en": "Withdrawn", "tr": "Geri çekildi", "zh": "已撤回"},
 	"cargo.created_by_type.ADMIN":      {"ru": "Админ", "uz": "Admin", "en": "Admin", "tr": "Admin", "zh": "管理员"},
 	"cargo.created_by_type.DISPATCHER": {"ru": "Диспетчер", "uz": "Dispetcher", "en": "Dispatcher", "tr": "Dispatçı", "zh": "调度员"},
 	"cargo.created_by_type.COMPANY":    {"ru": "Компания", "uz": "Kompaniya", "en": "Company", "tr": "Şirket", "zh": "公司"},
@@ -110,6 +112,53 @@ var refLabels = map[string]map[string]string{
 	"cargo.loading_type.CRANE":    {"ru": "Кран", "uz": "Kran", "en": "Crane", "tr": "Vinç", "zh": "起重机"},
 	"cargo.loading_type.FORKLIFT": {"ru": "Погрузчик", "uz": "Yuklovchi", "en": "Forklift", "tr": "Forklift", "zh": "叉车"},
 	"cargo.loading_type.OTHER":    {"ru": "Другое", "uz": "Boshqa", "en": "Other", "tr": "Diğer", "zh": "其他"},
+	"cargo.review_tag.ON_TIME":              {"ru": "Вовремя", "uz": "O'z vaqtida", "en": "On time", "tr": "Zamanında", "zh": "准时"},
+	"cargo.review_tag.CARGO_INTACT":         {"ru": "Груз в сохранности", "uz": "Yuk butun", "en": "Cargo intact", "tr": "Yük sağlam", "zh": "货物完好"},
+	"cargo.review_tag.PAID_ON_TIME":         {"ru": "Оплата вовремя", "uz": "To'lov o'z vaqtida", "en": "Paid on time", "tr": "Zamanında ödendi", "zh": "按时付款"},
+	"cargo.review_tag.POLITE":               {"ru": "Вежливость", "uz": "Xushmuomalalik", "en": "Polite", "tr": "Kibar", "zh": "礼貌"},
+	"cargo.review_tag.ACCURATE_DESCRIPTION": {"ru": "Груз соответствует описанию", "uz": "Yuk tavsifga mos", "en": "Cargo as described", "tr": "Yük açıklamaya uygun", "zh": "货物与描述相符"},
+	"cargo.review_tag.LATE":                 {"ru": "Опоздание", "uz": "Kechikish", "en": "Late", "tr": "Geç kaldı", "zh": "迟到"},
+	"cargo.review_tag.CARGO_DAMAGED":        {"ru": "Груз повреждён", "uz": "Yuk shikastlangan", "en": "Cargo damaged", "tr": "Yük hasarlı", "zh": "货物损坏"},
+	"cargo.review_tag.PAYMENT_DELAYED":      {"ru": "Задержка оплаты", "uz": "To'lov kechikdi", "en": "Payment delayed", "tr": "Ödeme gecikti", "zh": "付款延迟"},
+	"cargo.expense_category.FUEL":                {"ru": "Топливо", "uz": "Yoqilg'i", "en": "Fuel", "tr": "Yakıt", "zh": "燃油"},
+	"cargo.expense_category.TOLL":                {"ru": "Платная дорога", "uz": "Pullik yo'l", "en": "Toll", "tr": "Otoyol ücreti", "zh": "过路费"},
+	"cargo.expense_category.PARKING":             {"ru": "Стоянка", "uz": "To'xtash joyi", "en": "Parking", "tr": "Otopark", "zh": "停车费"},
+	"cargo.expense_category.CUSTOMS":             {"ru": "Таможенные сборы", "uz": "Bojxona yig'imlari", "en": "Customs fees", "tr": "Gümrük ücretleri", "zh": "海关费用"},
+	"cargo.expense_category.OTHER":               {"ru": "Другое", "uz": "Boshqa", "en": "Other", "tr": "Diğer", "zh": "其他"},
+	"cargo.expense_status.PENDING":               {"ru": "На проверке", "uz": "Tekshiruvda", "en": "Pending", "tr": "Onay bekliyor", "zh": "待审核"},
+	"cargo.expense_status.APPROVED":              {"ru": "Подтверждён", "uz": "Tasdiqlangan", "en": "Approved", "tr": "Onaylandı", "zh": "已批准"},
+	"cargo.expense_status.REJECTED":              {"ru": "Отклонён", "uz": "Rad etilgan", "en": "Rejected", "tr": "Reddedildi", "zh": "已拒绝"},
+	"cargo.customs_status.QUEUED":                {"ru": "В очереди", "uz": "Navbatda", "en": "Queued", "tr": "Sırada", "zh": "排队中"},
+	"cargo.customs_status.DECLARATION_SUBMITTED": {"ru": "Декларация подана", "uz": "Deklaratsiya topshirildi", "en": "Declaration submitted", "tr": "Beyanname verildi", "zh": "已提交报关单"},
+	"cargo.customs_status.INSPECTION":            {"ru": "Досмотр", "uz": "Ko'rik", "en": "Inspection", "tr": "Muayene", "zh": "查验中"},
+	"cargo.customs_status.CLEARED":               {"ru": "Выпущен", "uz": "Chiqarildi", "en": "Cleared", "tr": "Gümrükten çekildi", "zh": "已放行"},
+	"cargo.customs_status.HELD":                  {"ru": "Задержан", "uz": "Ushlab qolingan", "en": "Held", "tr": "Alıkonuldu", "zh": "被扣留"},
+	"cargo.trip_cancel_reason.DRIVER_UNAVAILABLE": {"ru": "Водитель не может выполнить рейс", "uz": "Haydovchi reysni bajara olmaydi", "en": "Driver unavailable", "tr": "Sürücü müsait değil", "zh": "司机无法执行"},
+	"cargo.trip_cancel_reason.DRIVER_ILLNESS":     {"ru": "Болезнь водителя", "uz": "Haydovchi kasalligi", "en": "Driver illness", "tr": "Sürücü hastalığı", "zh": "司机生病"},
+	"cargo.trip_cancel_reason.VEHICLE_BREAKDOWN":  {"ru": "Поломка машины", "uz": "Mashina buzilishi", "en": "Vehicle breakdown", "tr": "Araç arızası", "zh": "车辆故障"},
+	"cargo.trip_cancel_reason.DRIVER_NO_SHOW":     {"ru": "Водитель не приехал", "uz": "Haydovchi kelmadi", "en": "Driver did not show up", "tr": "Sürücü gelmedi", "zh": "司机未到场"},
+	"cargo.trip_cancel_reason.CARGO_NOT_READY":    {"ru": "Груз не готов", "uz": "Yuk tayyor emas", "en": "Cargo not ready", "tr": "Yük hazır değil", "zh": "货物未准备好"},
+	"cargo.trip_cancel_reason.CARGO_MISMATCH":     {"ru": "Груз не соответствует описанию", "uz": "Yuk tavsifga mos emas", "en": "Cargo does not match description", "tr": "Yük açıklamaya uymuyor", "zh": "货物与描述不符"},
+	"cargo.trip_cancel_reason.PRICE_DISAGREEMENT": {"ru": "Разногласия по цене", "uz": "Narx bo'yicha kelishmovchilik", "en": "Price disagreement", "tr": "Fiyat anlaşmazlığı", "zh": "价格分歧"},
+	"cargo.trip_cancel_reason.DOCUMENTS_ISSUE":    {"ru": "Проблемы с документами", "uz": "Hujjatlar bilan muammo", "en": "Documents issue", "tr": "Belge sorunu", "zh": "单据问题"},
+	"cargo.trip_cancel_reason.SHIPPER_CANCELLED":  {"ru": "Заказ отменён грузоотправителем", "uz": "Buyurtma yuk jo'natuvchi tomonidan bekor qilindi", "en": "Cancelled by shipper", "tr": "Gönderici tarafından iptal edildi", "zh": "发货人取消"},
+	"cargo.trip_cancel_reason.FORCE_MAJEURE":      {"ru": "Форс-мажор", "uz": "Fors-major", "en": "Force majeure", "tr": "Mücbir sebep", "zh": "不可抗力"},
+	"cargo.trip_cancel_reason.OTHER":              {"ru": "Другое", "uz": "Boshqa", "en": "Other", "tr": "Diğer", "zh": "其他"},
+	"cargo.incident_type.BREAKDOWN":      {"ru": "Поломка", "uz": "Buzilish", "en": "Breakdown", "tr": "Arıza", "zh": "故障"},
+	"cargo.incident_type.ACCIDENT":       {"ru": "ДТП", "uz": "YTH", "en": "Accident", "tr": "Kaza", "zh": "交通事故"},
+	"cargo.incident_type.POLICE_STOP":    {"ru": "Остановка полицией", "uz": "Politsiya to'xtatdi", "en": "Police stop", "tr": "Polis durdurması", "zh": "警察拦停"},
+	"cargo.incident_type.CARGO_DAMAGE":   {"ru": "Повреждение груза", "uz": "Yuk shikastlandi", "en": "Cargo damage", "tr": "Yük hasarı", "zh": "货物损坏"},
+	"cargo.incident_type.THEFT":          {"ru": "Кража", "uz": "O'g'irlik", "en": "Theft", "tr": "Hırsızlık", "zh": "盗窃"},
+	"cargo.incident_type.MEDICAL":        {"ru": "Проблемы со здоровьем", "uz": "Sog'liq muammosi", "en": "Medical emergency", "tr": "Sağlık sorunu", "zh": "健康问题"},
+	"cargo.incident_type.ROAD_BLOCKED":   {"ru": "Дорога перекрыта", "uz": "Yo'l yopilgan", "en": "Road blocked", "tr": "Yol kapalı", "zh": "道路封闭"},
+	"cargo.incident_type.OTHER":          {"ru": "Другое", "uz": "Boshqa", "en": "Other", "tr": "Diğer", "zh": "其他"},
+	"cargo.incident_severity.LOW":        {"ru": "Низкая", "uz": "Past", "en": "Low", "tr": "Düşük", "zh": "低"},
+	"cargo.incident_severity.MEDIUM":     {"ru": "Средняя", "uz": "O'rta", "en": "Medium", "tr": "Orta", "zh": "中"},
+	"cargo.incident_severity.HIGH":       {"ru": "Высокая", "uz": "Yuqori", "en": "High", "tr": "Yüksek", "zh": "高"},
+	"cargo.incident_severity.CRITICAL":   {"ru": "Критическая", "uz": "Jiddiy", "en": "Critical", "tr": "Kritik", "zh": "紧急"},
+	"cargo.incident_status.OPEN":         {"ru": "Открыт", "uz": "Ochiq", "en": "Open", "tr": "Açık", "zh": "未处理"},
+	"cargo.incident_status.ACKNOWLEDGED": {"ru": "Принят", "uz": "Qabul qilindi", "en": "Acknowledged", "tr": "Alındı", "zh": "已确认"},
+	"cargo.incident_status.RESOLVED":     {"ru": "Решён", "uz": "Hal qilindi", "en": "Resolved", "tr": "Çözüldü", "zh": "已解决"},
 	// --- drivers ---
 	"drivers.registration_step.NAME-OFERTA":    {"ru": "Имя и оферта", "uz": "Ism va oferta", "en": "Name and offer", "tr": "Ad ve teklif", "zh": "姓名和要约"},
 	"drivers.registration_step.GEO-PUSH":       {"ru": "Геолокация и push", "uz": "Geolokatsiya va push", "en": "Geolocation and push", "tr": "Konum ve push", "zh": "地理位置和推送"},
@@ -0,0 +1,43 @@
+package reviews
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Party types (author_type / target_type, UPPERCASE in API and DB).
+const (
+	PartyDriver     = "DRIVER"
+	PartyDispatcher = "DISPATCHER"
+	PartyCompany    = "COMPANY"
+)
+
+// Review status: PUBLISHED (counted in rating) or HIDDEN (hidden by admin moderation).
+const (
+	StatusPublished = "PUBLISHED"
+	StatusHidden    = "HIDDEN"
+)
+
+// Review model (table trip_reviews).
+type Review struct {
+	ID               uuid.UUID
+	TripID           uuid.UUID
+	AuthorType       string
+	AuthorID         uuid.UUID
+	TargetType       string
+	TargetID         uuid.UUID
+	Score            int
+	Tags             []string
+	Comment          *string
+	Status           string
+	ModerationReason *string
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// Summary is the aggregated rating of a target (only PUBLISHED reviews).
+type Summary struct {
+	Rating *float64
+	Count  int
+}
@@ -0,0 +1,231 @@
+package reviews
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrAlreadyExists = errors.New("review already exists")
+var ErrNotFound = errors.New("review not found")
+
+type Repo struct {
+	pg *pgxpool.Pool
+}
+
+func NewRepo(pg *pgxpool.Pool) *Repo {
+	return &Repo{pg: pg}
+}
+
+// CreateParams for a new review after trip completion.
+type CreateParams struct {
+	TripID     uuid.UUID
+	AuthorType string
+	AuthorID   uuid.UUID
+	TargetType string
+	TargetID   uuid.UUID
+	Score      int
+	Tags       []string
+	Comment    *string
+}
+
+const selectCols = `id, trip_id, author_type, author_id, target_type, target_id, score, tags, comment, status, moderation_reason, created_at, updated_at`
+
+// Create inserts a review and recomputes the target rating in one transaction.
+// One review per (trip, author side, target side); repeated call returns ErrAlreadyExists.
+func (r *Repo) Create(ctx context.Context, p CreateParams) (uuid.UUID, error) {
+	tx, err := r.pg.Begin(ctx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var id uuid.UUID
+	err = tx.QueryRow(ctx, `
+INSERT INTO trip_reviews (trip_id, author_type, author_id, target_type, target_id, score, tags, comment, status)
+VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF(TRIM($8), ''), $9) RETURNING id`,
+		p.TripID, p.AuthorType, p.AuthorID, p.TargetType, p.TargetID, p.Score, p.Tags, p.Comment, StatusPublished).Scan(&id)
+	if err != nil {
+		var e *pgconn.PgError
+		if errors.As(err, &e) && e.SQLState() == "23505" {
+			return uuid.Nil, ErrAlreadyExists
+		}
+		return uuid.Nil, err
+	}
+	if err := recompute(ctx, tx, p.TargetType, p.TargetID); err != nil {
+		return uuid.Nil, err
+	}
+	return id, tx.Commit(ctx)
+}
+
+// GetByID returns review by id (nil if not found).
+func (r *Repo) GetByID(ctx context.Context, id uuid.UUID) (*Review, error) {
+	rv, err := scanReview(r.pg.QueryRow(ctx, `SELECT `+selectCols+` FROM trip_reviews WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return rv, nil
+}
+
+// ListByTrip returns all reviews of a trip (both directions).
+func (r *Repo) ListByTrip(ctx context.Context, tripID uuid.UUID) ([]Review, error) {
+	rows, err := r.pg.Query(ctx, `SELECT `+selectCols+` FROM trip_reviews WHERE trip_id = $1 ORDER BY created_at`, tripID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return collect(rows)
+}
+
+// ListFilter for public and admin lists.
+type ListFilter struct {
+	TargetType string
+	TargetID   *uuid.UUID
+	Status     string // empty = any
+	Limit      int
+	Offset     int
+}
+
+// List returns reviews by filter ordered by newest first, with total count.
+func (r *Repo) List(ctx context.Context, f ListFilter) ([]Review, int, error) {
+	var conds []string
+	var args []any
+	add := func(cond string, v any) {
+		args = append(args, v)
+		conds = append(conds, strings.ReplaceAll(cond, "?", "$"+strconv.Itoa(len(args))))
+	}
+	if f.TargetType != "" {
+		add("target_type = ?", f.TargetType)
+	}
+	if f.TargetID != nil {
+		add("target_id = ?", *f.TargetID)
+	}
+	if f.Status != "" {
+		add("status = ?", f.Status)
+	}
+	where := "TRUE"
+	if len(conds) > 0 {
+		where = strings.Join(conds, " AND ")
+	}
+	var total int
+	if err := r.pg.QueryRow(ctx, "SELECT count(*) FROM trip_reviews WHERE "+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	limit := f.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	offset := f.Offset
+	if offset < 0 {
+		offset = 0
+	}
+	args = append(args, limit, offset)
+	q := `SELECT ` + selectCols + ` FROM trip_reviews WHERE ` + where +
+		` ORDER BY created_at DESC LIMIT $` + strconv.Itoa(len(args)-1) + ` OFFSET $` + strconv.Itoa(len(args))
+	rows, err := r.pg.Query(ctx, q, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	list, err := collect(rows)
+	return list, total, err
+}
+
+// GetSummary returns average score and count of published reviews for target.
+func (r *Repo) GetSummary(ctx context.Context, targetType string, targetID uuid.UUID) (Summary, error) {
+	var s Summary
+	err := r.pg.QueryRow(ctx,
+		`SELECT AVG(score)::float8, count(*) FROM trip_reviews WHERE target_type = $1 AND target_id = $2 AND status = $3`,
+		targetType, targetID, StatusPublished).Scan(&s.Rating, &s.Count)
+	return s, err
+}
+
+// SetStatus hides or restores a review (admin moderation) and recomputes the target rating.
+func (r *Repo) SetStatus(ctx context.Context, id uuid.UUID, status string, reason string) error {
+	tx, err := r.pg.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var targetType string
+	var targetID uuid.UUID
+	err = tx.QueryRow(ctx, `
+UPDATE trip_reviews SET status = $2, moderation_reason = NULLIF(TRIM($3), ''), updated_at = now()
+WHERE id = $1 RETURNING target_type, target_id`, id, status, reason).Scan(&targetType, &targetID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+	if err := recompute(ctx, tx, targetType, targetID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Recompute writes aggregated rating and rating_count of target into its table (drivers, freelance_dispatchers, companies).
+func (r *Repo) Recompute(ctx context.Context, targetType string, targetID uuid.UUID) error {
+	return recompute(ctx, r.pg, targetType, targetID)
+}
+
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+func recompute(ctx context.Context, db execer, targetType string, targetID uuid.UUID) error {
+	var table string
+	switch targetType {
+	case PartyDriver:
+		table = "drivers"
+	case PartyDispatcher:
+		table = "freelance_dispatchers"
+	case PartyCompany:
+		table = "companies"
+	default:
+		return errors.New("reviews: unknown target type")
+	}
+	_, err := db.Exec(ctx, `
+UPDATE `+table+` t SET
+  rating = s.avg_score,
+  rating_count = s.cnt,
+  updated_at = now()
+FROM (
+  SELECT ROUND(AVG(score)::numeric, 2)::float8 AS avg_score, count(*)::int AS cnt
+  FROM trip_reviews WHERE target_type = $1 AND target_id = $2 AND status = $3
+) s
+WHERE t.id = $2`, targetType, targetID, StatusPublished)
+	return err
+}
+
+func scanReview(row pgx.Row) (*Review, error) {
+	var rv Review
+	err := row.Scan(&rv.ID, &rv.TripID, &rv.AuthorType, &rv.AuthorID, &rv.TargetType, &rv.TargetID,
+		&rv.Score, &rv.Tags, &rv.Comment, &rv.Status, &rv.ModerationReason, &rv.CreatedAt, &rv.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &rv, nil
+}
+
+func collect(rows pgx.Rows) ([]Review, error) {
+	var list []Review
+	for rows.Next() {
+		rv, err := scanReview(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *rv)
+	}
+	return list, rows.Err()
+}
@@ -0,0 +1,164 @@
+// Package routing — офлайн-оценка расстояния и времени в пути по точкам маршрута
+// (дуга большого круга × коэффициент дорог, скорость по типу кузова, задержки на таможне).
+package routing
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	earthRadiusKm  = 6371.0
+	kmPerDegreeLat = 111.32
+)
+
+// PointTypeCustoms — тип точки маршрута, на которой добавляется задержка таможни.
+const PointTypeCustoms = "CUSTOMS"
+
+// Point — точка маршрута (lat/lng и тип из route_points).
+type Point struct {
+	Lat  float64
+	Lng  float64
+	Type string
+}
+
+// DefaultTruckSpeeds — средняя скорость (км/ч) с учётом дорог и коротких остановок по типу кузова.
+var DefaultTruckSpeeds = map[string]float64{
+	"TENT":         60,
+	"FLATBED":      60,
+	"REFRIGERATOR": 58,
+	"TANKER":       55,
+	"OTHER":        55,
+}
+
+// Config — параметры оценщика (из env, см. config.Config).
+type Config struct {
+	RoadFactor        float64            // во сколько раз дорога длиннее дуги большого круга
+	DefaultSpeedKmh   float64            // скорость для неизвестного типа кузова
+	TruckSpeeds       map[string]float64 // скорость по truck_type (UPPERCASE)
+	CustomsDelay      time.Duration      // задержка на каждой точке CUSTOMS
+	DailyDrivingLimit time.Duration      // макс. время за рулём в сутки (0 — без отдыха)
+	DailyRest         time.Duration      // отдых после каждого полного DailyDrivingLimit
+}
+
+// Estimate — результат оценки маршрута.
+type Estimate struct {
+	DistanceKm float64
+	Driving    time.Duration
+	Customs    time.Duration
+	Rest       time.Duration
+}
+
+// Total — полное время в пути.
+func (e Estimate) Total() time.Duration { return e.Driving + e.Customs + e.Rest }
+
+// Estimator считает расстояние и время по точкам маршрута без внешних сервисов.
+type Estimator struct {
+	cfg Config
+}
+
+func NewEstimator(cfg Config) *Estimator {
+	if cfg.RoadFactor <= 0 {
+		cfg.RoadFactor = 1
+	}
+	if cfg.DefaultSpeedKmh <= 0 {
+		cfg.DefaultSpeedKmh = 55
+	}
+	if cfg.TruckSpeeds == nil {
+		cfg.TruckSpeeds = DefaultTruckSpeeds
+	}
+	return &Estimator{cfg: cfg}
+}
+
+// SpeedKmh возвращает скорость для типа кузова.
+func (e *Estimator) SpeedKmh(truckType string) float64 {
+	if v, ok := e.cfg.TruckSpeeds[strings.ToUpper(strings.TrimSpace(truckType))]; ok && v > 0 {
+		return v
+	}
+	return e.cfg.DefaultSpeedKmh
+}
+
+// Estimate оценивает маршрут по точкам в порядке следования.
+func (e *Estimator) Estimate(truckType string, points []Point) Estimate {
+	var est Estimate
+	for i := 1; i < len(points); i++ {
+		est.DistanceKm += HaversineKm(points[i-1], points[i]) * e.cfg.RoadFactor
+	}
+	for _, p := range points {
+		if strings.EqualFold(p.Type, PointTypeCustoms) {
+			est.Customs += e.cfg.CustomsDelay
+		}
+	}
+	est.DistanceKm = math.Round(est.DistanceKm*10) / 10
+	est.Driving = time.Duration(est.DistanceKm / e.SpeedKmh(truckType) * float64(time.Hour)).Round(time.Minute)
+	if e.cfg.DailyDrivingLimit > 0 {
+		// отдых только между сменами: после последней смены отдых не нужен
+		est.Rest = time.Duration((est.Driving-1)/e.cfg.DailyDrivingLimit) * e.cfg.DailyRest
+	}
+	return est
+}
+
+// ETA оценивает оставшийся путь от текущей позиции через оставшиеся точки и время прибытия.
+func (e *Estimator) ETA(truckType string, from Point, remaining []Point, now time.Time) (Estimate, time.Time) {
+	points := make([]Point, 0, len(remaining)+1)
+	points = append(points, from)
+	points = append(points, remaining...)
+	est := e.Estimate(truckType, points)
+	return est, now.Add(est.Total())
+}
+
+// HaversineKm — расстояние по дуге большого круга в километрах.
+func HaversineKm(a, b Point) float64 {
+	lat1, lat2 := a.Lat*math.Pi/180, b.Lat*math.Pi/180
+	dLat := lat2 - lat1
+	dLng := (b.Lng - a.Lng) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// Box — прямоугольник широт/долгот вокруг точки (грубый отбор в SQL перед точной проверкой радиуса).
+type Box struct {
+	MinLat, MaxLat, MinLng, MaxLng float64
+}
+
+// BoundingBox — прямоугольник, в который гарантированно попадает круг радиуса radiusKm вокруг p.
+// У полюсов и при большом радиусе долгота не ограничивается.
+func BoundingBox(p Point, radiusKm float64) Box {
+	dLat := radiusKm / kmPerDegreeLat
+	b := Box{MinLat: math.Max(p.Lat-dLat, -90), MaxLat: math.Min(p.Lat+dLat, 90), MinLng: -180, MaxLng: 180}
+	cos := math.Cos(math.Max(math.Abs(p.Lat)+dLat, 0) * math.Pi / 180)
+	if b.MaxLat < 90 && b.MinLat > -90 && cos > 0 {
+		dLng := radiusKm / (kmPerDegreeLat * cos)
+		if dLng < 180 {
+			b.MinLng, b.MaxLng = p.Lng-dLng, p.Lng+dLng
+		}
+	}
+	return b
+}
+
+// ParseTruckSpeeds разбирает строку вида "TENT:60,REFRIGERATOR:58" поверх DefaultTruckSpeeds.
+func ParseTruckSpeeds(s string) (map[string]float64, error) {
+	out := make(map[string]float64, len(DefaultTruckSpeeds))
+	for k, v := range DefaultTruckSpeeds {
+		out[k] = v
+	}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, ":", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid truck speed %q", part)
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(kv[1]), 64)
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("invalid truck speed %q", part)
+		}
+		out[strings.ToUpper(strings.TrimSpace(kv[0]))] = v
+	}
+	return out, nil
+}
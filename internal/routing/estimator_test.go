@@ -0,0 +1,104 @@
+package routing
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+var (
+	tashkent  = Point{Lat: 41.2995, Lng: 69.2401, Type: "LOAD"}
+	samarkand = Point{Lat: 39.6542, Lng: 66.9597, Type: "UNLOAD"}
+)
+
+func TestHaversineKm(t *testing.T) {
+	d := HaversineKm(tashkent, samarkand)
+	// Ташкент — Самарканд по прямой ≈ 270 км
+	if math.Abs(d-270) > 10 {
+		t.Fatalf("Tashkent-Samarkand: got %.1f km", d)
+	}
+	if HaversineKm(tashkent, tashkent) != 0 {
+		t.Fatal("same point: expected 0")
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	e := NewEstimator(Config{
+		RoadFactor:        1.2,
+		TruckSpeeds:       map[string]float64{"TENT": 60},
+		DefaultSpeedKmh:   50,
+		CustomsDelay:      6 * time.Hour,
+		DailyDrivingLimit: 9 * time.Hour,
+		DailyRest:         11 * time.Hour,
+	})
+	customs := Point{Lat: 40.5, Lng: 68.0, Type: "CUSTOMS"}
+	est := e.Estimate("tent", []Point{tashkent, customs, samarkand})
+	want := (HaversineKm(tashkent, customs) + HaversineKm(customs, samarkand)) * 1.2
+	if math.Abs(est.DistanceKm-want) > 0.1 {
+		t.Fatalf("distance: got %.1f, want %.1f", est.DistanceKm, want)
+	}
+	if est.Customs != 6*time.Hour {
+		t.Fatalf("customs: got %v", est.Customs)
+	}
+	if est.Rest != 0 {
+		t.Fatalf("rest: expected none for a short route, got %v", est.Rest)
+	}
+	if got := e.Estimate("UNKNOWN", []Point{tashkent, samarkand}); got.Driving <= e.Estimate("TENT", []Point{tashkent, samarkand}).Driving {
+		t.Fatal("default speed is lower, driving time should be longer")
+	}
+}
+
+func TestEstimateDailyRest(t *testing.T) {
+	e := NewEstimator(Config{RoadFactor: 1, DefaultSpeedKmh: 100, TruckSpeeds: map[string]float64{}, DailyDrivingLimit: 9 * time.Hour, DailyRest: 11 * time.Hour})
+	// ≈ 2000 км по прямой → 20 ч за рулём → две смены отдыха
+	far := Point{Lat: 41.2995, Lng: 69.2401 + 2000/(111.32*math.Cos(41.2995*math.Pi/180))}
+	est := e.Estimate("", []Point{tashkent, far})
+	if est.Driving < 19*time.Hour || est.Driving > 21*time.Hour {
+		t.Fatalf("driving: got %v", est.Driving)
+	}
+	if est.Rest != 22*time.Hour {
+		t.Fatalf("rest: got %v, want 22h", est.Rest)
+	}
+}
+
+func TestETA(t *testing.T) {
+	e := NewEstimator(Config{RoadFactor: 1, DefaultSpeedKmh: 60})
+	now := time.Date(2026, 1, 1, 8, 0, 0, 0, time.UTC)
+	est, at := e.ETA("", tashkent, []Point{samarkand}, now)
+	if !at.Equal(now.Add(est.Total())) {
+		t.Fatalf("eta: got %v", at)
+	}
+	if est.DistanceKm <= 0 {
+		t.Fatal("expected positive remaining distance")
+	}
+}
+
+func TestParseTruckSpeeds(t *testing.T) {
+	m, err := ParseTruckSpeeds("tent:70, TANKER:50")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m["TENT"] != 70 || m["TANKER"] != 50 || m["REFRIGERATOR"] != DefaultTruckSpeeds["REFRIGERATOR"] {
+		t.Fatalf("unexpected speeds: %v", m)
+	}
+	if _, err := ParseTruckSpeeds("TENT=70"); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestBoundingBox(t *testing.T) {
+	tashkent := Point{Lat: 41.31, Lng: 69.28}
+	b := BoundingBox(tashkent, 100)
+	if b.MinLat > 40.5 || b.MaxLat < 42.1 || b.MinLng > 68.1 || b.MaxLng < 70.4 {
+		t.Errorf("box too small: %+v", b)
+	}
+	// любая точка на границе круга попадает в прямоугольник
+	for _, p := range []Point{{Lat: 42.2, Lng: 69.28}, {Lat: 41.31, Lng: 70.47}, {Lat: 40.42, Lng: 69.28}} {
+		if d := HaversineKm(tashkent, p); d <= 100 && (p.Lat < b.MinLat || p.Lat > b.MaxLat || p.Lng < b.MinLng || p.Lng > b.MaxLng) {
+			t.Errorf("point %v (%.1f km) outside %+v", p, d, b)
+		}
+	}
+	if b := BoundingBox(Point{Lat: 89.5, Lng: 0}, 200); b.MinLng != -180 || b.MaxLng != 180 || b.MaxLat != 90 {
+		t.Errorf("pole: %+v", b)
+	}
+}
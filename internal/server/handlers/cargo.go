@@ -1,19 +1,28 @@
 package handlers
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 
 	"sarbonNew/internal/cargo"
+	"sarbonNew/internal/companies"
 	"sarbonNew/internal/config"
+	"sarbonNew/internal/currency"
 	"sarbonNew/internal/drivers"
+	"sarbonNew/internal/notifications"
+	"sarbonNew/internal/pricing"
 	"sarbonNew/internal/reference"
+	"sarbonNew/internal/routing"
 	"sarbonNew/internal/security"
 	"sarbonNew/internal/server/mw"
 	"sarbonNew/internal/server/resp"
@@ -25,12 +34,18 @@ type CargoHandler struct {
 	repo      *cargo.Repo
 	tripsRepo *trips.Repo
 	drivers   *drivers.Repo
+	companies *companies.Repo
+	routes    *routing.Estimator
+	rates     *currency.Repo
+	pricing   *pricing.Repo
+	prefs     *DisplayCurrencyPrefs
+	notifier  *notifications.Notifier
 	jwtm      *security.JWTManager
 	cfg       config.Config
 }
 
-func NewCargoHandler(logger *zap.Logger, repo *cargo.Repo, tripsRepo *trips.Repo, driversRepo *drivers.Repo, jwtm *security.JWTManager, cfg config.Config) *CargoHandler {
-	return &CargoHandler{logger: logger, repo: repo, tripsRepo: tripsRepo, drivers: driversRepo, jwtm: jwtm, cfg: cfg}
+func NewCargoHandler(logger *zap.Logger, repo *cargo.Repo, tripsRepo *trips.Repo, driversRepo *drivers.Repo, companiesRepo *companies.Repo, routes *routing.Estimator, rates *currency.Repo, pricingRepo *pricing.Repo, prefs *DisplayCurrencyPrefs, notifier *notifications.Notifier, jwtm *security.JWTManager, cfg config.Config) *CargoHandler {
+	return &CargoHandler{logger: logger, repo: repo, tripsRepo: tripsRepo, drivers: driversRepo, companies: companiesRepo, routes: routes, rates: rates, pricing: pricingRepo, prefs: prefs, notifier: notifier, jwtm: jwtm, cfg: cfg}
 }
 
 // CreateCargoReq body for POST /api/cargo.
@@ -84,6 +99,7 @@ type PaymentReq struct {
 	RemainingAmount    *float64 `json:"remaining_amount"`
 	RemainingCurrency  *string  `json:"remaining_currency"`
 	RemainingType      *string  `json:"remaining_type"`
+	MaxCounterRounds   *int     `json:"max_counter_rounds"`
 }
 
 func (h *CargoHandler) Create(c *gin.Context) {
@@ -98,7 +114,50 @@ func (h *CargoHandler) Create(c *gin.Context) {
 	}
 	params := toCreateParams(req)
 	params.CompanyID = req.CompanyID
-	// Автоматически записываем, кто создал груз: admin, dispatcher или company
+	if !h.setCargoCreator(c, &params, 1) {
+		return
+	}
+	id, err := h.repo.Create(c.Request.Context(), params)
+	if err != nil {
+		h.logger.Error("cargo create", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "failed_to_create_cargo")
+		return
+	}
+	// Возвращаем полный объект груза (как GET /api/cargo/:id), чтобы клиент видел все сохранённые данные
+	obj, err := h.repo.GetByID(c.Request.Context(), id, false)
+	if err != nil || obj == nil {
+		resp.SuccessLang(c, http.StatusCreated, "created", gin.H{"id": id.String()})
+		return
+	}
+	points, _ := h.repo.GetRoutePoints(c.Request.Context(), id)
+	h.refreshRouteEstimate(c.Request.Context(), obj, points)
+	pay, _ := h.repo.GetPayment(c.Request.Context(), id)
+	detail := toCargoDetail(obj, points, pay)
+	detail["price_suggestion"] = h.createdPriceSuggestion(c.Request.Context(), obj, points, pay)
+	resp.SuccessLang(c, http.StatusCreated, "created", detail)
+}
+
+// createdPriceSuggestion — рыночная цена для только что созданного груза (как GET /api/cargo/:id/price-suggestion),
+// чтобы создатель сразу видел, не выбивается ли его цена из диапазона. nil — рекомендация недоступна.
+func (h *CargoHandler) createdPriceSuggestion(ctx context.Context, obj *cargo.Cargo, points []cargo.RoutePoint, pay *cargo.Payment) gin.H {
+	if h.pricing == nil || obj.DistanceKm == nil {
+		return nil
+	}
+	currency := defaultSuggestionCurrency
+	if pay != nil && pay.TotalCurrency != nil && reference.IsAllowed(strings.ToUpper(*pay.TotalCurrency), reference.AllowedCurrencies()) {
+		currency = strings.ToUpper(*pay.TotalCurrency)
+	}
+	out, err := suggestPrice(ctx, h.pricing, points, obj.TruckType, obj.Weight, currency, *obj.DistanceKm)
+	if err != nil {
+		h.logger.Warn("cargo price suggestion", zap.Error(err), zap.String("cargo_id", obj.ID.String()))
+		return nil
+	}
+	return out
+}
+
+// setCargoCreator записывает, кто создаёт груз (admin, dispatcher или company), по X-User-Token и params.CompanyID.
+// adding — сколько грузов создаётся (для лимита фриланс-диспетчера); false — ответ уже отправлен.
+func (h *CargoHandler) setCargoCreator(c *gin.Context, params *cargo.CreateParams, adding int) bool {
 	raw := strings.TrimSpace(c.GetHeader(mw.HeaderUserToken))
 	if raw != "" && h.jwtm != nil {
 		if userID, role, err := h.jwtm.ParseAccess(raw); err == nil {
@@ -110,48 +169,46 @@ func (h *CargoHandler) Create(c *gin.Context) {
 				params.CreatedByType = strPtr("DISPATCHER")
 				params.CreatedByID = &userID
 				// Лимит грузов для фриланс-диспетчера (из env)
-				if h.cfg.FreelanceDispatcherCargoLimit > 0 {
-					count, err := h.repo.CountByDispatcher(c.Request.Context(), userID)
-					if err != nil {
-						h.logger.Error("cargo count by dispatcher", zap.Error(err))
-						resp.ErrorLang(c, http.StatusInternalServerError, "failed_to_check_cargo_limit")
-						return
-					}
-					if count >= h.cfg.FreelanceDispatcherCargoLimit {
-						resp.ErrorWithData(c, http.StatusForbidden, "cargo limit reached for freelance dispatcher", gin.H{
-							"limit":  h.cfg.FreelanceDispatcherCargoLimit,
-							"current": count,
-						})
-						return
-					}
+				if !h.checkDispatcherCargoLimit(c, userID, adding) {
+					return false
 				}
 			}
 		}
 	}
 	// Если создатель не определён по JWT, но передан company_id — считаем создателем компанию
-	if params.CreatedByType == nil && req.CompanyID != nil {
+	if params.CreatedByType == nil && params.CompanyID != nil {
 		params.CreatedByType = strPtr("COMPANY")
-		params.CreatedByID = req.CompanyID
-		params.CompanyID = req.CompanyID
+		params.CreatedByID = params.CompanyID
 	}
-	id, err := h.repo.Create(c.Request.Context(), params)
-	if err != nil {
-		h.logger.Error("cargo create", zap.Error(err))
-		resp.ErrorLang(c, http.StatusInternalServerError, "failed_to_create_cargo")
-		return
+	return true
+}
+
+// checkDispatcherCargoLimit проверяет лимит грузов фриланс-диспетчера (FREELANCE_DISPATCHER_CARGO_LIMIT) с учётом adding новых; при превышении отвечает 403.
+func (h *CargoHandler) checkDispatcherCargoLimit(c *gin.Context, dispatcherID uuid.UUID, adding int) bool {
+	if h.cfg.FreelanceDispatcherCargoLimit <= 0 {
+		return true
 	}
-	// Возвращаем полный объект груза (как GET /api/cargo/:id), чтобы клиент видел все сохранённые данные
-	obj, err := h.repo.GetByID(c.Request.Context(), id, false)
-	if err != nil || obj == nil {
-		resp.SuccessLang(c, http.StatusCreated, "created", gin.H{"id": id.String()})
-		return
+	count, err := h.repo.CountByDispatcher(c.Request.Context(), dispatcherID)
+	if err != nil {
+		h.logger.Error("cargo count by dispatcher", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "failed_to_check_cargo_limit")
+		return false
+	}
+	if count+adding > h.cfg.FreelanceDispatcherCargoLimit {
+		resp.ErrorWithData(c, http.StatusForbidden, "cargo limit reached for freelance dispatcher", gin.H{
+			"limit":   h.cfg.FreelanceDispatcherCargoLimit,
+			"current": count,
+		})
+		return false
 	}
-	points, _ := h.repo.GetRoutePoints(c.Request.Context(), id)
-	pay, _ := h.repo.GetPayment(c.Request.Context(), id)
-	resp.SuccessLang(c, http.StatusCreated, "created", toCargoDetail(obj, points, pay))
+	return true
 }
 
 func (h *CargoHandler) List(c *gin.Context) {
+	displayCur, conv, ok := h.displayCurrency(c)
+	if !ok {
+		return
+	}
 	f := cargo.ListFilter{
 		Page:        getIntQuery(c, "page", 1),
 		Limit:       getIntQuery(c, "limit", 20),
@@ -198,8 +255,34 @@ func (h *CargoHandler) List(c *gin.Context) {
 		resp.ErrorLang(c, http.StatusInternalServerError, "failed_to_list")
 		return
 	}
+	ids := make([]uuid.UUID, 0, len(result.Items))
+	for _, it := range result.Items {
+		ids = append(ids, it.ID)
+	}
+	payments, err := h.repo.GetPaymentsByCargoIDs(c.Request.Context(), ids)
+	if err != nil {
+		h.logger.Warn("cargo list payments", zap.Error(err))
+	}
+	items := toCargoListItems(result.Items, payments)
+	if conv != nil {
+		for i, it := range result.Items {
+			pay := payments[it.ID]
+			if pay == nil || pay.TotalAmount == nil || pay.TotalCurrency == nil {
+				continue
+			}
+			amount, ok := conv.Convert(*pay.TotalAmount, *pay.TotalCurrency, displayCur)
+			if !ok {
+				continue
+			}
+			items[i]["display_amount"] = amount
+			items[i]["display_currency"] = displayCur
+			if it.DistanceKm != nil && *it.DistanceKm > 0 {
+				items[i]["display_price_per_km"] = math.Round(amount / *it.DistanceKm * 100) / 100
+			}
+		}
+	}
 	resp.OKLang(c, "ok", gin.H{
-		"items": toCargoListItems(result.Items),
+		"items": items,
 		"total": result.Total,
 	})
 }
@@ -249,6 +332,12 @@ func (h *CargoHandler) Update(c *gin.Context) {
 		resp.ErrorLang(c, http.StatusInternalServerError, "failed_to_update_cargo")
 		return
 	}
+	if len(req.RoutePoints) > 0 || req.TruckType != nil {
+		if obj, _ := h.repo.GetByID(c.Request.Context(), id, false); obj != nil {
+			points, _ := h.repo.GetRoutePoints(c.Request.Context(), id)
+			h.refreshRouteEstimate(c.Request.Context(), obj, points)
+		}
+	}
 	resp.OKLang(c, "ok", gin.H{"id": id.String()})
 }
 
@@ -283,6 +372,11 @@ func (h *CargoHandler) PatchStatus(c *gin.Context) {
 		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
 		return
 	}
+	if req.Status == cargo.StatusCancelled && h.companies != nil {
+		if err := h.companies.RecordCargoOutcome(c.Request.Context(), id); err != nil {
+			h.logger.Error("company order stats", zap.Error(err), zap.String("cargo_id", id.String()))
+		}
+	}
 	resp.OKLang(c, "updated", gin.H{"id": id.String(), "status": req.Status})
 }
 
@@ -302,10 +396,11 @@ func (h *CargoHandler) CreateOffer(c *gin.Context) {
 		return
 	}
 	var req struct {
-		CarrierID uuid.UUID `json:"carrier_id" binding:"required"`
-		Price     float64   `json:"price" binding:"required"`
-		Currency  string    `json:"currency" binding:"required"`
-		Comment   string    `json:"comment"`
+		CarrierID  uuid.UUID `json:"carrier_id" binding:"required"`
+		Price      float64   `json:"price" binding:"required"`
+		Currency   string    `json:"currency" binding:"required"`
+		Comment    string    `json:"comment"`
+		ValidHours *int      `json:"valid_hours"` // срок действия оффера; по умолчанию OFFER_DEFAULT_VALID_HOURS
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
@@ -323,13 +418,22 @@ func (h *CargoHandler) CreateOffer(c *gin.Context) {
 			}
 		}
 	}
-	offerID, err := h.repo.CreateOffer(c.Request.Context(), id, req.CarrierID, req.Price, req.Currency, req.Comment)
+	if h.openAuction(c, id) {
+		resp.ErrorLang(c, http.StatusConflict, "auction_in_progress")
+		return
+	}
+	expiresAt, ok := offerExpiresAt(req.ValidHours, h.cfg.OfferDefaultValidity)
+	if !ok {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_offer_validity")
+		return
+	}
+	offerID, err := h.repo.CreateOffer(c.Request.Context(), id, req.CarrierID, req.Price, req.Currency, req.Comment, expiresAt)
 	if err != nil {
 		h.logger.Error("cargo create offer", zap.Error(err))
 		resp.ErrorLang(c, http.StatusInternalServerError, "failed to create offer")
 		return
 	}
-	resp.SuccessLang(c, http.StatusCreated, "created", gin.H{"id": offerID.String()})
+	resp.SuccessLang(c, http.StatusCreated, "created", gin.H{"id": offerID.String(), "expires_at": expiresAt})
 }
 
 func (h *CargoHandler) ListOffers(c *gin.Context) {
@@ -338,35 +442,132 @@ func (h *CargoHandler) ListOffers(c *gin.Context) {
 		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
 		return
 	}
+	displayCur, conv, ok := h.displayCurrency(c)
+	if !ok {
+		return
+	}
 	offers, err := h.repo.GetOffers(c.Request.Context(), id)
 	if err != nil {
 		h.logger.Error("cargo list offers", zap.Error(err))
 		resp.ErrorLang(c, http.StatusInternalServerError, "failed to list offers")
 		return
 	}
-	resp.OKLang(c, "ok", gin.H{"items": toOfferList(offers)})
+	items := toOfferList(offers)
+	if a, _ := h.repo.GetAuction(c.Request.Context(), id); a.IsOpen() && a.Sealed {
+		// закрытые ставки: цены не раскрываются до окончания аукциона
+		for i := range items {
+			items[i]["price"], items[i]["sealed"] = nil, true
+		}
+		conv = nil
+	}
+	if conv != nil {
+		for i, o := range offers {
+			if price, ok := conv.Convert(o.Price, o.Currency, displayCur); ok {
+				items[i]["display_price"] = price
+				items[i]["display_currency"] = displayCur
+			}
+		}
+	}
+	resp.OKLang(c, "ok", gin.H{"items": items})
 }
 
+// displayCurrency берёт валюту из query display_currency, иначе сохранённую в профиле (по X-User-Token),
+// и загружает курсы на сегодня. conv = nil — валюта не задана; ok = false — ответ с ошибкой уже отправлен.
+func (h *CargoHandler) displayCurrency(c *gin.Context) (cur string, conv *currency.Converter, ok bool) {
+	if h.rates == nil {
+		return "", nil, true
+	}
+	cur, ok = normalizeDisplayCurrency(c.Query("display_currency"))
+	if !ok {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_currency")
+		return "", nil, false
+	}
+	if cur == "" {
+		saved, err := h.prefs.Lookup(c.Request.Context(), c.GetHeader(mw.HeaderUserToken))
+		if err != nil {
+			h.logger.Warn("display currency preference", zap.Error(err))
+		}
+		cur = saved
+	}
+	if cur == "" {
+		return "", nil, true
+	}
+	conv, err := h.rates.ConverterOn(c.Request.Context(), time.Now())
+	if err != nil {
+		h.logger.Warn("currency rates", zap.Error(err))
+		return "", nil, true
+	}
+	return cur, conv, true
+}
+
+// AcceptOffer POST /api/offers/:id/accept — заказчик принимает текущую цену оффера (исходную или встречную водителя).
 func (h *CargoHandler) AcceptOffer(c *gin.Context) {
 	offerID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		resp.ErrorLang(c, http.StatusBadRequest, "invalid offer id")
 		return
 	}
-	cargoID, carrierID, err := h.repo.AcceptOffer(c.Request.Context(), offerID)
-	if err != nil {
+	h.acceptOffer(c, offerID, cargo.SideShipper)
+}
+
+// acceptOffer принимает оффер стороной side и создаёт рейс с согласованной ценой.
+func (h *CargoHandler) acceptOffer(c *gin.Context, offerID uuid.UUID, side string) {
+	if offer, _ := h.repo.GetOfferByID(c.Request.Context(), offerID); offer != nil && h.openAuction(c, offer.CargoID) {
+		resp.ErrorLang(c, http.StatusConflict, "auction_in_progress")
+		return
+	}
+	res, err := AcceptOfferWithTrip(c.Request.Context(), h.repo, h.tripsRepo, offerID, side)
+	if errors.Is(err, cargo.ErrOfferNotYourTurn) {
+		resp.ErrorLang(c, http.StatusConflict, "offer_not_your_turn")
+		return
+	}
+	if res.CargoID == uuid.Nil {
 		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
 		return
 	}
-	if h.tripsRepo != nil {
-		tripID, _ := h.tripsRepo.Create(c.Request.Context(), cargoID, offerID)
-		if tripID != uuid.Nil {
-			_ = h.tripsRepo.AssignDriver(c.Request.Context(), tripID, carrierID)
-			resp.OKLang(c, "ok", gin.H{"cargo_id": cargoID.String(), "offer_id": offerID.String(), "trip_id": tripID.String(), "driver_id": carrierID.String(), "status": "accepted"})
-			return
+	if err != nil {
+		// оффер уже принят — отвечаем успехом, рейс без водителя виден диспетчеру
+		h.logger.Error("accept offer trip", zap.Error(err), zap.String("offer_id", offerID.String()))
+	}
+	out := gin.H{"cargo_id": res.CargoID.String(), "offer_id": offerID.String(), "status": "accepted"}
+	if res.TripID != uuid.Nil {
+		out["trip_id"], out["driver_id"] = res.TripID.String(), res.CarrierID.String()
+	}
+	resp.OKLang(c, "ok", out)
+}
+
+// AcceptedOffer — результат принятия оффера: TripID = uuid.Nil, если рейс не создан.
+type AcceptedOffer struct {
+	CargoID   uuid.UUID
+	CarrierID uuid.UUID
+	TripID    uuid.UUID
+}
+
+// AcceptOfferWithTrip принимает оффер стороной side, отмечает победителя аукциона и создаёт рейс с назначенным
+// перевозчиком. Используется API и автозакрытием аукциона. Если оффер принят, но рейс не создан или водитель
+// не назначен, возвращаются и результат (CargoID заполнен), и ошибка.
+func AcceptOfferWithTrip(ctx context.Context, repo *cargo.Repo, tripsRepo *trips.Repo, offerID uuid.UUID, side string) (AcceptedOffer, error) {
+	var res AcceptedOffer
+	cargoID, carrierID, err := repo.AcceptOffer(ctx, offerID, side)
+	if err != nil {
+		return res, err
+	}
+	res.CargoID, res.CarrierID = cargoID, carrierID
+	var errs []error
+	if err := repo.MarkAuctionAwarded(ctx, cargoID, offerID); err != nil {
+		errs = append(errs, fmt.Errorf("auction award: %w", err))
+	}
+	if tripsRepo != nil {
+		if tripID, err := tripsRepo.Create(ctx, cargoID, offerID); err != nil {
+			errs = append(errs, fmt.Errorf("create trip: %w", err))
+		} else {
+			res.TripID = tripID
+			if err := tripsRepo.AssignDriver(ctx, tripID, carrierID); err != nil {
+				errs = append(errs, fmt.Errorf("assign driver: %w", err))
+			}
 		}
 	}
-	resp.OKLang(c, "ok", gin.H{"cargo_id": cargoID.String(), "offer_id": offerID.String(), "status": "accepted"})
+	return res, errors.Join(errs...)
 }
 
 // RejectOfferReq body for POST /v1/dispatchers/offers/:id/reject (reason optional).
@@ -477,6 +678,9 @@ func validateCargoCreate(req CreateCargoReq) error {
 		if req.Payment.RemainingCurrency != nil && *req.Payment.RemainingCurrency != "" && !reference.IsAllowed(*req.Payment.RemainingCurrency, reference.AllowedCurrencies()) {
 			return errors.New("payment.remaining_currency must be from reference GET /v1/reference/cargo → currency")
 		}
+		if req.Payment.MaxCounterRounds != nil && *req.Payment.MaxCounterRounds < 0 {
+			return errors.New("payment.max_counter_rounds must be >= 0")
+		}
 		if req.Payment.PrepaymentType != nil && *req.Payment.PrepaymentType != "" && !reference.IsAllowed(*req.Payment.PrepaymentType, reference.AllowedPrepaymentTypes()) {
 			return errors.New("payment.prepayment_type must be from reference GET /v1/reference/cargo → prepayment_type")
 		}
@@ -528,6 +732,9 @@ func validateCargoUpdate(req UpdateCargoReq) error {
 		if req.Payment.RemainingCurrency != nil && *req.Payment.RemainingCurrency != "" && !reference.IsAllowed(*req.Payment.RemainingCurrency, reference.AllowedCurrencies()) {
 			return errors.New("payment.remaining_currency must be from reference GET /v1/reference/cargo → currency")
 		}
+		if req.Payment.MaxCounterRounds != nil && *req.Payment.MaxCounterRounds < 0 {
+			return errors.New("payment.max_counter_rounds must be >= 0")
+		}
 		if req.Payment.PrepaymentType != nil && *req.Payment.PrepaymentType != "" && !reference.IsAllowed(*req.Payment.PrepaymentType, reference.AllowedPrepaymentTypes()) {
 			return errors.New("payment.prepayment_type must be from reference GET /v1/reference/cargo → prepayment_type")
 		}
@@ -601,6 +808,7 @@ func toCreateParams(req CreateCargoReq) cargo.CreateParams {
 			RemainingAmount:    req.Payment.RemainingAmount,
 			RemainingCurrency:  strPtrUpper(req.Payment.RemainingCurrency),
 			RemainingType:      strPtrUpper(req.Payment.RemainingType),
+			MaxCounterRounds:   req.Payment.MaxCounterRounds,
 		}
 	}
 	return p
@@ -648,15 +856,51 @@ func toUpdateParams(req UpdateCargoReq) cargo.UpdateParams {
 			PrepaymentAmount: req.Payment.PrepaymentAmount, PrepaymentCurrency: strPtrUpper(req.Payment.PrepaymentCurrency),
 			PrepaymentType: strPtrUpper(req.Payment.PrepaymentType), RemainingAmount: req.Payment.RemainingAmount,
 			RemainingCurrency: strPtrUpper(req.Payment.RemainingCurrency), RemainingType: strPtrUpper(req.Payment.RemainingType),
+			MaxCounterRounds: req.Payment.MaxCounterRounds,
 		}
 	}
 	return p
 }
 
-func toCargoListItems(items []cargo.Cargo) []gin.H {
+// refreshRouteEstimate пересчитывает distance_km и duration_minutes груза по точкам маршрута и сохраняет их.
+func (h *CargoHandler) refreshRouteEstimate(ctx context.Context, obj *cargo.Cargo, points []cargo.RoutePoint) {
+	if err := setRouteEstimate(ctx, h.repo, h.routes, obj, points); err != nil {
+		h.logger.Warn("cargo route estimate", zap.Error(err), zap.String("cargo_id", obj.ID.String()))
+	}
+}
+
+// setRouteEstimate сохраняет оценку расстояния и времени маршрута груза и обновляет obj.
+func setRouteEstimate(ctx context.Context, repo *cargo.Repo, routes *routing.Estimator, obj *cargo.Cargo, points []cargo.RoutePoint) error {
+	if routes == nil || len(points) < 2 {
+		return nil
+	}
+	est := routes.Estimate(obj.TruckType, toRoutingPoints(points))
+	minutes := int(est.Total().Minutes())
+	if err := repo.SetRouteEstimate(ctx, obj.ID, est.DistanceKm, minutes); err != nil {
+		return err
+	}
+	obj.DistanceKm = &est.DistanceKm
+	obj.DurationMinutes = &minutes
+	return nil
+}
+
+func toRoutingPoints(points []cargo.RoutePoint) []routing.Point {
+	out := make([]routing.Point, 0, len(points))
+	for _, rp := range points {
+		out = append(out, routing.Point{Lat: rp.Lat, Lng: rp.Lng, Type: rp.Type})
+	}
+	return out
+}
+
+func toCargoListItems(items []cargo.Cargo, payments map[uuid.UUID]*cargo.Payment) []gin.H {
 	out := make([]gin.H, 0, len(items))
 	for _, c := range items {
-		out = append(out, toCargoItem(&c))
+		item := toCargoItem(&c)
+		if pay := payments[c.ID]; pay != nil && pay.TotalAmount != nil && c.DistanceKm != nil && *c.DistanceKm > 0 {
+			item["price_per_km"] = math.Round(*pay.TotalAmount / *c.DistanceKm * 100) / 100
+			item["price_currency"] = pay.TotalCurrency
+		}
+		out = append(out, item)
 	}
 	return out
 }
@@ -670,6 +914,7 @@ func toCargoItem(c *cargo.Cargo) gin.H {
 		"shipment_type": c.ShipmentType, "belts_count": c.BeltsCount, "documents": c.Documents,
 		"contact_name": c.ContactName, "contact_phone": c.ContactPhone, "status": c.Status,
 		"created_at": c.CreatedAt, "updated_at": c.UpdatedAt,
+		"distance_km": c.DistanceKm, "duration_minutes": c.DurationMinutes,
 	}
 	if c.CreatedByType != nil {
 		out["created_by_type"] = *c.CreatedByType
@@ -713,20 +958,27 @@ func toPaymentResp(p *cargo.Payment) gin.H {
 		"with_prepayment": p.WithPrepayment, "without_prepayment": p.WithoutPrepayment,
 		"prepayment_amount": p.PrepaymentAmount, "prepayment_currency": p.PrepaymentCurrency, "prepayment_type": p.PrepaymentType,
 		"remaining_amount": p.RemainingAmount, "remaining_currency": p.RemainingCurrency, "remaining_type": p.RemainingType,
+		"max_counter_rounds": p.MaxCounterRounds,
 	}
 }
 
 func toOfferList(offers []cargo.Offer) []gin.H {
 	out := make([]gin.H, 0, len(offers))
 	for _, o := range offers {
-		out = append(out, gin.H{
-			"id": o.ID.String(), "cargo_id": o.CargoID.String(), "carrier_id": o.CarrierID.String(),
-			"price": o.Price, "currency": o.Currency, "comment": o.Comment, "status": o.Status, "created_at": o.CreatedAt,
-		})
+		out = append(out, toOfferResp(&o))
 	}
 	return out
 }
 
+func toOfferResp(o *cargo.Offer) gin.H {
+	return gin.H{
+		"id": o.ID.String(), "cargo_id": o.CargoID.String(), "carrier_id": o.CarrierID.String(),
+		"price": o.Price, "currency": o.Currency, "comment": o.Comment, "status": o.Status, "created_at": o.CreatedAt,
+		"rounds_count": o.RoundsCount, "last_round_by": o.LastRoundBy, "expires_at": o.ExpiresAt, "updated_at": o.UpdatedAt,
+		"listing_id": o.ListingID,
+	}
+}
+
 func getIntQuery(c *gin.Context, key string, defaultVal int) int {
 	v := c.Query(key)
 	if v == "" {
@@ -0,0 +1,289 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"sarbonNew/internal/cargo"
+	"sarbonNew/internal/reference"
+	"sarbonNew/internal/server/mw"
+	"sarbonNew/internal/server/resp"
+)
+
+// Ограничения параметров аукциона.
+const (
+	minAuctionDuration  = 10 * time.Minute
+	maxAuctionDuration  = 30 * 24 * time.Hour
+	maxAntiSnipeMinutes = 60
+)
+
+// StartAuctionReq body for POST /api/cargo/:id/auction. Срок — ends_at (RFC3339) или duration_minutes.
+type StartAuctionReq struct {
+	EndsAt           *time.Time `json:"ends_at"`
+	DurationMinutes  *int       `json:"duration_minutes"`
+	Currency         string     `json:"currency"` // по умолчанию валюта оплаты груза
+	ReservePrice     *float64   `json:"reserve_price"`
+	Sealed           bool       `json:"sealed"`
+	AntiSnipeMinutes int        `json:"anti_snipe_minutes"`
+	WinnerSelection  string     `json:"winner_selection"` // AUTO (по умолчанию) или MANUAL
+}
+
+// StartAuction POST /api/cargo/:id/auction — перевести груз в режим аукциона (ставки до срока, выигрывает минимальная цена).
+func (h *CargoHandler) StartAuction(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return
+	}
+	var req StartAuctionReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+		return
+	}
+	ctx := c.Request.Context()
+	obj, _ := h.repo.GetByID(ctx, id, false)
+	if obj == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "cargo_not_found")
+		return
+	}
+	if !cargo.IsSearching(obj.Status) {
+		resp.ErrorLang(c, http.StatusBadRequest, "cargo_not_searching")
+		return
+	}
+	// cargo_auctions.ends_at — TIMESTAMP в UTC (сравнивается с now() в БД)
+	now := time.Now().UTC()
+	var endsAt time.Time
+	switch {
+	case req.EndsAt != nil:
+		endsAt = req.EndsAt.UTC()
+	case req.DurationMinutes != nil:
+		endsAt = now.Add(time.Duration(*req.DurationMinutes) * time.Minute)
+	}
+	if d := endsAt.Sub(now); d < minAuctionDuration || d > maxAuctionDuration {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_auction_deadline")
+		return
+	}
+	in := cargo.AuctionInput{
+		Currency: strings.ToUpper(strings.TrimSpace(req.Currency)), ReservePrice: req.ReservePrice, Sealed: req.Sealed,
+		AntiSnipeMinutes: req.AntiSnipeMinutes, WinnerSelection: strings.ToUpper(strings.TrimSpace(req.WinnerSelection)), EndsAt: endsAt,
+	}
+	if in.WinnerSelection == "" {
+		in.WinnerSelection = cargo.WinnerAuto
+	}
+	if in.Currency == "" {
+		if pay, _ := h.repo.GetPayment(ctx, id); pay != nil && pay.TotalCurrency != nil && *pay.TotalCurrency != "" {
+			in.Currency = strings.ToUpper(*pay.TotalCurrency)
+		} else {
+			in.Currency = defaultSuggestionCurrency
+		}
+	}
+	if !reference.IsAllowed(in.Currency, reference.AllowedCurrencies()) {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_currency")
+		return
+	}
+	if (in.WinnerSelection != cargo.WinnerAuto && in.WinnerSelection != cargo.WinnerManual) ||
+		in.AntiSnipeMinutes < 0 || in.AntiSnipeMinutes > maxAntiSnipeMinutes || (in.ReservePrice != nil && *in.ReservePrice <= 0) {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+		return
+	}
+	a, err := h.repo.StartAuction(ctx, id, in)
+	if errors.Is(err, cargo.ErrAuctionExists) {
+		resp.ErrorLang(c, http.StatusConflict, "auction_already_exists")
+		return
+	}
+	if err != nil {
+		h.logger.Error("start auction", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	h.respondAuction(c, http.StatusCreated, a, true)
+}
+
+// GetAuction GET /api/cargo/:id/auction — состояние торгов. При sealed лучшая ставка скрыта до закрытия.
+func (h *CargoHandler) GetAuction(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return
+	}
+	a, err := h.repo.GetAuction(c.Request.Context(), id)
+	if err != nil {
+		h.logger.Error("get auction", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if a == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "auction_not_found")
+		return
+	}
+	h.respondAuction(c, http.StatusOK, a, false)
+}
+
+// CancelAuction DELETE /api/cargo/:id/auction — отменить открытый аукцион (ставки остаются обычными офферами).
+func (h *CargoHandler) CancelAuction(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return
+	}
+	if err := h.repo.CancelAuction(c.Request.Context(), id); err != nil {
+		if errors.Is(err, cargo.ErrAuctionNotOpen) {
+			resp.ErrorLang(c, http.StatusConflict, "auction_not_open")
+			return
+		}
+		h.logger.Error("cancel auction", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	resp.OKLang(c, "ok", gin.H{"cargo_id": id.String(), "status": cargo.AuctionCancelled})
+}
+
+// AwardAuctionReq body for POST /api/cargo/:id/auction/award.
+type AwardAuctionReq struct {
+	OfferID uuid.UUID `json:"offer_id" binding:"required"`
+}
+
+// AwardAuction POST /api/cargo/:id/auction/award — ручной выбор победителя после закрытия торгов (принятие оффера → рейс).
+func (h *CargoHandler) AwardAuction(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return
+	}
+	var req AwardAuctionReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+		return
+	}
+	ctx := c.Request.Context()
+	a, err := h.repo.GetAuction(ctx, id)
+	if err != nil || a == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "auction_not_found")
+		return
+	}
+	if a.Status != cargo.AuctionClosed && a.Status != cargo.AuctionNoWinner {
+		resp.ErrorLang(c, http.StatusConflict, "auction_not_closed")
+		return
+	}
+	offer, err := h.repo.GetOfferByID(ctx, req.OfferID)
+	if err != nil || offer == nil || offer.CargoID != id {
+		resp.ErrorLang(c, http.StatusNotFound, "offer_not_found")
+		return
+	}
+	h.acceptOffer(c, req.OfferID, cargo.SideShipper)
+}
+
+// BidReq body for POST /v1/driver/cargo/:id/bid.
+type BidReq struct {
+	Price    float64 `json:"price" binding:"required,gt=0"`
+	Currency string  `json:"currency"` // по умолчанию валюта аукциона
+	Comment  string  `json:"comment"`
+}
+
+// DriverBid POST /v1/driver/cargo/:id/bid — ставка водителя в аукционе; повторная ставка должна быть ниже своей предыдущей.
+func (h *CargoHandler) DriverBid(c *gin.Context) {
+	driverID := c.MustGet(mw.CtxDriverID).(uuid.UUID)
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return
+	}
+	var req BidReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+		return
+	}
+	ctx := c.Request.Context()
+	obj, _ := h.repo.GetByID(ctx, id, false)
+	if obj == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "cargo_not_found")
+		return
+	}
+	if !cargo.IsSearching(obj.Status) {
+		resp.ErrorLang(c, http.StatusBadRequest, "cargo_not_searching")
+		return
+	}
+	if obj.Status == cargo.StatusSearchingCompany && h.drivers != nil {
+		if drv, _ := h.drivers.FindByID(ctx, driverID); drv == nil || drv.CompanyID == nil || obj.CompanyID == nil || *drv.CompanyID != obj.CompanyID.String() {
+			resp.ErrorLang(c, http.StatusForbidden, "cargo_visible_only_to_company_drivers")
+			return
+		}
+	}
+	currency := strings.ToUpper(strings.TrimSpace(req.Currency))
+	if currency == "" {
+		if a, _ := h.repo.GetAuction(ctx, id); a != nil {
+			currency = a.Currency
+		}
+	}
+	offer, a, err := h.repo.PlaceBid(ctx, id, driverID, req.Price, currency, strings.TrimSpace(req.Comment))
+	switch {
+	case errors.Is(err, cargo.ErrAuctionNotOpen):
+		resp.ErrorLang(c, http.StatusConflict, "auction_not_open")
+		return
+	case errors.Is(err, cargo.ErrBidCurrency):
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_currency")
+		return
+	case errors.Is(err, cargo.ErrBidNotLower):
+		resp.ErrorLang(c, http.StatusConflict, "bid_not_lower")
+		return
+	case err != nil:
+		h.logger.Error("place bid", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	resp.SuccessLang(c, http.StatusCreated, "created", gin.H{"offer": toOfferResp(offer), "auction": toAuctionResp(a, nil, false)})
+}
+
+// openAuction — груз в открытом аукционе: обычные офферы, торг и принятие недоступны до закрытия.
+func (h *CargoHandler) openAuction(c *gin.Context, cargoID uuid.UUID) bool {
+	a, err := h.repo.GetAuction(c.Request.Context(), cargoID)
+	if err != nil {
+		h.logger.Warn("get auction", zap.Error(err))
+		return false
+	}
+	return a.IsOpen()
+}
+
+func (h *CargoHandler) respondAuction(c *gin.Context, status int, a *cargo.Auction, owner bool) {
+	sum, err := h.repo.AuctionSummary(c.Request.Context(), a)
+	if err != nil {
+		h.logger.Error("auction summary", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if status == http.StatusCreated {
+		resp.SuccessLang(c, status, "created", toAuctionResp(a, &sum, owner))
+		return
+	}
+	resp.OKLang(c, "ok", toAuctionResp(a, &sum, owner))
+}
+
+// toAuctionResp; резервная цена показывается только владельцу (owner), участникам — reserve_met.
+func toAuctionResp(a *cargo.Auction, sum *cargo.AuctionSummary, owner bool) gin.H {
+	out := gin.H{
+		"cargo_id": a.CargoID.String(), "status": a.Status, "currency": a.Currency, "sealed": a.Sealed,
+		"anti_snipe_minutes": a.AntiSnipeMinutes, "winner_selection": a.WinnerSelection,
+		"starts_at": a.StartsAt, "ends_at": a.EndsAt, "original_ends_at": a.OriginalEndsAt, "extensions_count": a.ExtensionsCount,
+		"has_reserve": a.ReservePrice != nil, "closed_at": a.ClosedAt,
+	}
+	if owner {
+		out["reserve_price"] = a.ReservePrice
+	}
+	if a.WinnerOfferID != nil {
+		out["winner_offer_id"] = a.WinnerOfferID.String()
+	}
+	if sum != nil {
+		out["bids_count"] = sum.BidsCount
+		out["reserve_met"] = sum.ReserveMet
+		if !a.Sealed || !a.IsOpen() {
+			out["best_bid"] = sum.BestBid
+		}
+	}
+	return out
+}
@@ -0,0 +1,340 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"sarbonNew/internal/cargo"
+	"sarbonNew/internal/reference"
+	"sarbonNew/internal/server/resp"
+	"sarbonNew/internal/tabular"
+)
+
+// Ограничения импорта грузов.
+const (
+	maxCargoImportSize = 5 << 20
+	maxCargoImportRows = 1000
+)
+
+// cargoImportColumns — колонки файла импорта (первая строка — заголовок, регистр не важен), по порядку шаблона.
+// Одна строка — один груз; маршрут: погрузка, необязательная таможня, выгрузка. Города — коды справочника (TAS или TAS-UZ);
+// если lat/lng не заданы — берутся координаты города, если не задан адрес — название города.
+var cargoImportColumns = []string{
+	"weight", "volume", "truck_type", "ready_at", "load_comment",
+	"temp_min", "temp_max", "adr_class", "loading_types", "requirements", "shipment_type", "belts_count",
+	"contact_name", "contact_phone",
+	"load_city", "load_address", "load_lat", "load_lng", "load_orientir",
+	"customs_city", "customs_address", "customs_lat", "customs_lng",
+	"unload_city", "unload_address", "unload_lat", "unload_lng", "unload_orientir",
+	"price", "currency", "is_negotiable", "price_request", "prepayment_amount", "prepayment_type", "remaining_type",
+}
+
+var cargoImportRequired = []string{"weight", "volume", "truck_type", "load_city", "unload_city"}
+
+// cargoImportRow — строка файла: значения по именам колонок.
+type cargoImportRow map[string]string
+
+// ImportCargo POST /api/cargo/import — массовое создание грузов из CSV/XLSX (multipart: file, company_id, dry_run).
+// Каждая строка проверяется так же, как POST /api/cargo; валидные строки создаются одной транзакцией, по невалидным — отчёт.
+// dry_run=true — только проверка.
+func (h *CargoHandler) ImportCargo(c *gin.Context) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "file_required")
+		return
+	}
+	if file.Size > maxCargoImportSize {
+		resp.ErrorLang(c, http.StatusBadRequest, "file_too_large")
+		return
+	}
+	var companyID *uuid.UUID
+	if v := strings.TrimSpace(c.PostForm("company_id")); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+			return
+		}
+		companyID = &id
+	}
+	dryRun := c.PostForm("dry_run") == "true" || c.PostForm("dry_run") == "1" || c.Query("dry_run") == "true" || c.Query("dry_run") == "1"
+
+	f, err := file.Open()
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "cannot_read_file")
+		return
+	}
+	defer f.Close()
+	rows, err := tabular.Read(file.Filename, f)
+	if err != nil {
+		resp.ErrorWithData(c, http.StatusBadRequest, resp.Msg("invalid_import_file", resp.Lang(c)), gin.H{"detail": err.Error()})
+		return
+	}
+	header, ignored, missing := cargoImportHeader(rows[0])
+	if len(missing) > 0 {
+		resp.ErrorWithData(c, http.StatusBadRequest, resp.Msg("import_missing_columns", resp.Lang(c)), gin.H{"missing_columns": missing})
+		return
+	}
+	if len(rows)-1 > maxCargoImportRows {
+		resp.ErrorWithData(c, http.StatusBadRequest, resp.Msg("import_too_many_rows", resp.Lang(c)), gin.H{"max_rows": maxCargoImportRows})
+		return
+	}
+
+	items := make([]gin.H, 0, len(rows)-1)
+	var valid []cargo.CreateParams
+	var validItems []gin.H
+	for i, cells := range rows[1:] {
+		row := cargoImportRow{}
+		for j, col := range header {
+			if col != "" && j < len(cells) {
+				row[col] = cells[j]
+			}
+		}
+		item := gin.H{"row": i + 2} // номер строки в файле (1 — заголовок)
+		req, errs := cargoReqFromImportRow(row)
+		if len(errs) == 0 {
+			if err := binding.Validator.ValidateStruct(&req); err != nil {
+				errs = append(errs, err.Error())
+			} else if err := validateCargoCreate(req); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+		if len(errs) > 0 {
+			item["valid"] = false
+			item["errors"] = errs
+		} else {
+			item["valid"] = true
+			p := toCreateParams(req)
+			p.CompanyID = companyID
+			valid = append(valid, p)
+			validItems = append(validItems, item)
+		}
+		items = append(items, item)
+	}
+
+	out := gin.H{
+		"dry_run": dryRun, "total_rows": len(items), "valid_rows": len(valid), "invalid_rows": len(items) - len(valid),
+		"created": 0, "ignored_columns": ignored, "items": items,
+	}
+	if dryRun || len(valid) == 0 {
+		resp.OKLang(c, "ok", out)
+		return
+	}
+	var creator cargo.CreateParams
+	creator.CompanyID = companyID
+	if !h.setCargoCreator(c, &creator, len(valid)) {
+		return
+	}
+	for i := range valid {
+		valid[i].CreatedByType, valid[i].CreatedByID = creator.CreatedByType, creator.CreatedByID
+	}
+	ctx := c.Request.Context()
+	ids, err := h.repo.CreateBatch(ctx, valid)
+	if err != nil {
+		h.logger.Error("cargo import", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "failed_to_create_cargo")
+		return
+	}
+	for i, id := range ids {
+		validItems[i]["cargo_id"] = id.String()
+		if obj, _ := h.repo.GetByID(ctx, id, false); obj != nil {
+			points, _ := h.repo.GetRoutePoints(ctx, id)
+			h.refreshRouteEstimate(ctx, obj, points)
+		}
+	}
+	out["created"] = len(ids)
+	resp.OKLang(c, "ok", out)
+}
+
+// ImportCargoTemplate GET /api/cargo/import/template — CSV-шаблон файла импорта (заголовок и пример строки).
+func (h *CargoHandler) ImportCargoTemplate(c *gin.Context) {
+	example := map[string]string{
+		"weight": "20", "volume": "82", "truck_type": "REFRIGERATOR", "ready_at": "2026-11-02 09:00", "temp_min": "2", "temp_max": "6",
+		"contact_name": "Dilshod", "contact_phone": "+998901234567",
+		"load_city": "TAS", "load_address": "Sergeli, 5", "unload_city": "ALM-KZ", "unload_address": "Rayymbek ave, 100",
+		"price": "2500", "currency": "USD", "is_negotiable": "yes",
+	}
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write(cargoImportColumns)
+	row := make([]string, len(cargoImportColumns))
+	for i, col := range cargoImportColumns {
+		row[i] = example[col]
+	}
+	_ = w.Write(row)
+	w.Flush()
+	c.Header("Content-Disposition", `attachment; filename="cargo_import_template.csv"`)
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+// cargoImportHeader сопоставляет заголовок с колонками импорта: header[i] — имя колонки ("" — не распознана).
+func cargoImportHeader(cells []string) (header, ignored, missing []string) {
+	known := make(map[string]bool, len(cargoImportColumns))
+	for _, col := range cargoImportColumns {
+		known[col] = true
+	}
+	seen := map[string]bool{}
+	header, ignored = make([]string, len(cells)), []string{}
+	for i, cell := range cells {
+		col := strings.ToLower(strings.Join(strings.Fields(cell), "_"))
+		if !known[col] || seen[col] {
+			if cell != "" {
+				ignored = append(ignored, cell)
+			}
+			continue
+		}
+		seen[col] = true
+		header[i] = col
+	}
+	for _, col := range cargoImportRequired {
+		if !seen[col] {
+			missing = append(missing, col)
+		}
+	}
+	return header, ignored, missing
+}
+
+// cargoReqFromImportRow собирает CreateCargoReq из строки импорта; errs — ошибки разбора значений по колонкам.
+func cargoReqFromImportRow(row cargoImportRow) (req CreateCargoReq, errs []string) {
+	num := func(col string) *float64 {
+		v := strings.ReplaceAll(strings.ReplaceAll(row[col], " ", ""), ",", ".")
+		if v == "" {
+			return nil
+		}
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+			errs = append(errs, col+": must be a number")
+			return nil
+		}
+		return &n
+	}
+	str := func(col string) *string {
+		if v := row[col]; v != "" {
+			return &v
+		}
+		return nil
+	}
+
+	if v := num("weight"); v != nil {
+		req.Weight = *v
+	}
+	if v := num("volume"); v != nil {
+		req.Volume = *v
+	}
+	req.TruckType = upperStr(row["truck_type"])
+	if v := row["ready_at"]; v != "" {
+		if t, ok := parseImportTime(v); ok {
+			s := t.Format("2006-01-02T15:04:05")
+			req.ReadyEnabled, req.ReadyAt = true, &s
+		} else {
+			errs = append(errs, "ready_at: expected YYYY-MM-DD [HH:MM] or DD.MM.YYYY [HH:MM]")
+		}
+	}
+	req.LoadComment = str("load_comment")
+	req.TempMin, req.TempMax = num("temp_min"), num("temp_max")
+	if v := str("adr_class"); v != nil {
+		req.ADREnabled, req.ADRClass = true, v
+	}
+	req.LoadingTypes = splitImportList(row["loading_types"])
+	req.Requirements = splitImportList(row["requirements"])
+	req.ShipmentType = str("shipment_type")
+	if v := num("belts_count"); v != nil {
+		n := int(*v)
+		req.BeltsCount = &n
+	}
+	req.ContactName, req.ContactPhone = str("contact_name"), str("contact_phone")
+
+	order := 1
+	addPoint := func(prefix, typ string, required bool) {
+		code := row[prefix+"_city"]
+		if code == "" {
+			if required {
+				errs = append(errs, prefix+"_city: required")
+			}
+			return
+		}
+		city, err := reference.FindCity(code)
+		if err != nil {
+			errs = append(errs, prefix+"_city: "+err.Error())
+			return
+		}
+		rp := RoutePointReq{
+			Type: typ, CityCode: city.Code, Address: row[prefix+"_address"], Orientir: row[prefix+"_orientir"], PointOrder: order,
+			IsMainLoad: typ == "LOAD", IsMainUnload: typ == "UNLOAD",
+		}
+		if rp.Address == "" {
+			rp.Address = city.NameRu
+		}
+		lat, lng := num(prefix+"_lat"), num(prefix+"_lng")
+		switch {
+		case lat != nil && lng != nil:
+			rp.Lat, rp.Lng = *lat, *lng
+		case city.Lat != nil && city.Lng != nil:
+			rp.Lat, rp.Lng = *city.Lat, *city.Lng
+		default:
+			errs = append(errs, prefix+"_lat/"+prefix+"_lng: required (no coordinates for city)")
+		}
+		req.RoutePoints = append(req.RoutePoints, rp)
+		order++
+	}
+	addPoint("load", "LOAD", true)
+	addPoint("customs", "CUSTOMS", false)
+	addPoint("unload", "UNLOAD", true)
+
+	price, prepay := num("price"), num("prepayment_amount")
+	negotiable, priceRequest := importBool(row["is_negotiable"]), importBool(row["price_request"])
+	if price != nil || prepay != nil || negotiable || priceRequest || row["currency"] != "" {
+		req.Payment = &PaymentReq{
+			IsNegotiable: negotiable, PriceRequest: priceRequest, TotalAmount: price, TotalCurrency: strPtrUpper(str("currency")),
+			PrepaymentAmount: prepay, PrepaymentType: str("prepayment_type"), RemainingType: str("remaining_type"),
+		}
+		if prepay != nil && *prepay > 0 {
+			req.Payment.WithPrepayment = true
+			req.Payment.PrepaymentCurrency = req.Payment.TotalCurrency
+		} else {
+			req.Payment.WithoutPrepayment = true
+		}
+	}
+	return req, errs
+}
+
+// parseImportTime разбирает дату/время из таблицы (в том числе серийный номер даты Excel).
+func parseImportTime(v string) (time.Time, bool) {
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02", "02.01.2006 15:04", "02.01.2006"} {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t, true
+		}
+	}
+	if serial, err := strconv.ParseFloat(v, 64); err == nil && serial > 1 && serial < 100000 {
+		base := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+		return base.Add(time.Duration(serial * float64(24*time.Hour))).Round(time.Minute), true
+	}
+	return time.Time{}, false
+}
+
+func splitImportList(v string) []string {
+	var out []string
+	for _, part := range strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ';' || r == '|' }) {
+		if p := strings.TrimSpace(part); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func importBool(v string) bool {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "1", "true", "yes", "y", "x", "+", "да":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCargoImportHeader(t *testing.T) {
+	header, ignored, missing := cargoImportHeader([]string{"Weight", "Volume", "truck type", "Load_City", "notes", "weight"})
+	if header[0] != "weight" || header[2] != "truck_type" || header[3] != "load_city" || header[4] != "" || header[5] != "" {
+		t.Errorf("header: %q", header)
+	}
+	if len(ignored) != 2 || ignored[0] != "notes" || ignored[1] != "weight" {
+		t.Errorf("ignored: %q", ignored)
+	}
+	if len(missing) != 1 || missing[0] != "unload_city" {
+		t.Errorf("missing: %q", missing)
+	}
+}
+
+func TestCargoReqFromImportRow(t *testing.T) {
+	req, errs := cargoReqFromImportRow(cargoImportRow{
+		"weight": "20,5", "volume": "82", "truck_type": "refrigerator", "ready_at": "02.11.2026 09:00",
+		"temp_min": "2", "temp_max": "6", "loading_types": "rear; side",
+		"load_city": "TAS", "unload_city": "ALM-KZ", "unload_address": "Rayymbek ave, 100", "unload_lat": "43.2", "unload_lng": "76.9",
+		"price": "2500", "currency": "usd", "prepayment_amount": "500",
+	})
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if req.Weight != 20.5 || req.TruckType != "REFRIGERATOR" || !req.ReadyEnabled || req.ReadyAt == nil || *req.ReadyAt != "2026-11-02T09:00:00" {
+		t.Errorf("cargo fields: %+v", req)
+	}
+	if len(req.LoadingTypes) != 2 || req.LoadingTypes[1] != "side" {
+		t.Errorf("loading_types: %q", req.LoadingTypes)
+	}
+	if len(req.RoutePoints) != 2 {
+		t.Fatalf("route points: %+v", req.RoutePoints)
+	}
+	load, unload := req.RoutePoints[0], req.RoutePoints[1]
+	if load.Type != "LOAD" || load.CityCode != "TAS" || load.Address != "Ташкент" || load.Lat == 0 || !load.IsMainLoad || load.PointOrder != 1 {
+		t.Errorf("load point: %+v", load)
+	}
+	if unload.Type != "UNLOAD" || unload.Lat != 43.2 || unload.Lng != 76.9 || !unload.IsMainUnload || unload.PointOrder != 2 {
+		t.Errorf("unload point: %+v", unload)
+	}
+	if req.Payment == nil || *req.Payment.TotalAmount != 2500 || *req.Payment.TotalCurrency != "USD" || !req.Payment.WithPrepayment {
+		t.Errorf("payment: %+v", req.Payment)
+	}
+	if err := validateCargoCreate(req); err != nil {
+		t.Errorf("validateCargoCreate: %v", err)
+	}
+}
+
+func TestCargoReqFromImportRowErrors(t *testing.T) {
+	_, errs := cargoReqFromImportRow(cargoImportRow{
+		"weight": "twenty", "volume": "82", "truck_type": "TENT", "ready_at": "soon",
+		"load_city": "QQQ-ZZ", "customs_city": "TAS",
+	})
+	joined := strings.Join(errs, "\n")
+	for _, want := range []string{"weight:", "ready_at:", "load_city:", "unload_city: required"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("expected error %q in %q", want, errs)
+		}
+	}
+}
@@ -124,13 +124,13 @@ func (h *CargoRecommendationsHandler) AcceptRecommendation(c *gin.Context) {
 		price = *pay.TotalAmount
 		currency = *pay.TotalCurrency
 	}
-	offerID, err := h.cargoRepo.CreateOffer(c.Request.Context(), cargoID, driverID, price, currency, "")
+	offerID, err := h.cargoRepo.CreateOffer(c.Request.Context(), cargoID, driverID, price, currency, "", nil)
 	if err != nil {
 		h.logger.Error("cargo recommend accept create offer", zap.Error(err))
 		resp.ErrorLang(c, http.StatusInternalServerError, "failed_to_accept")
 		return
 	}
-	_, carrierID, err := h.cargoRepo.AcceptOffer(c.Request.Context(), offerID)
+	_, carrierID, err := h.cargoRepo.AcceptOffer(c.Request.Context(), offerID, cargo.SideShipper)
 	if err != nil {
 		resp.ErrorLang(c, http.StatusInternalServerError, "failed_to_accept")
 		return
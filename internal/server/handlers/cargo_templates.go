@@ -0,0 +1,429 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"sarbonNew/internal/cargo"
+	"sarbonNew/internal/routing"
+	"sarbonNew/internal/server/mw"
+	"sarbonNew/internal/server/resp"
+	"sarbonNew/internal/timeutil"
+)
+
+var (
+	// ErrTemplateCargoInvalid — шаблон с переопределениями не проходит валидацию POST /api/cargo.
+	ErrTemplateCargoInvalid = errors.New("template cargo is invalid")
+	// ErrDispatcherCargoLimit — достигнут лимит грузов фриланс-диспетчера.
+	ErrDispatcherCargoLimit = errors.New("cargo limit reached for freelance dispatcher")
+)
+
+// CargoTemplateReq body for POST/PUT .../cargo-templates. cargo — то же тело, что у POST /api/cargo (company_id игнорируется).
+type CargoTemplateReq struct {
+	Name  string         `json:"name" binding:"required,max=120"`
+	Cargo CreateCargoReq `json:"cargo"`
+}
+
+// CargoScheduleReq body for POST .../cargo-templates/:id/schedules and PUT .../cargo-schedules/:id. Время — Asia/Tashkent.
+type CargoScheduleReq struct {
+	Weekdays        []int  `json:"weekdays" binding:"required"` // 1 = понедельник … 7 = воскресенье
+	RunTime         string `json:"run_time" binding:"required"` // HH:MM
+	ReadyOffsetDays *int   `json:"ready_offset_days"`           // ready_at = момент создания + N дней
+	Active          *bool  `json:"active"`                      // по умолчанию true
+}
+
+// ListCargoTemplates GET /v1/dispatchers/cargo-templates, GET /v1/cargo-templates. Query: limit, offset.
+func (h *CargoHandler) ListCargoTemplates(c *gin.Context) {
+	ownerType, ownerID, ok := templateOwner(c)
+	if !ok {
+		return
+	}
+	list, total, err := h.repo.ListTemplates(c.Request.Context(), ownerType, ownerID, getIntQuery(c, "limit", 20), getIntQuery(c, "offset", 0))
+	if err != nil {
+		h.logger.Error("cargo templates list", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	items := make([]gin.H, 0, len(list))
+	for i := range list {
+		items = append(items, toCargoTemplateResp(&list[i], nil))
+	}
+	resp.OKLang(c, "ok", gin.H{"items": items, "total": total})
+}
+
+// CreateCargoTemplate POST /v1/dispatchers/cargo-templates, POST /v1/cargo-templates.
+func (h *CargoHandler) CreateCargoTemplate(c *gin.Context) {
+	ownerType, ownerID, ok := templateOwner(c)
+	if !ok {
+		return
+	}
+	name, payload, ok := bindCargoTemplate(c)
+	if !ok {
+		return
+	}
+	t, err := h.repo.CreateTemplate(c.Request.Context(), ownerType, ownerID, name, payload)
+	if errors.Is(err, cargo.ErrTemplateNameTaken) {
+		resp.ErrorLang(c, http.StatusConflict, "cargo_template_name_taken")
+		return
+	}
+	if err != nil {
+		h.logger.Error("cargo template create", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	resp.SuccessLang(c, http.StatusCreated, "created", toCargoTemplateResp(t, nil))
+}
+
+// GetCargoTemplate GET .../cargo-templates/:id — шаблон и его расписания.
+func (h *CargoHandler) GetCargoTemplate(c *gin.Context) {
+	t, ok := h.ownTemplate(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	schedules, err := h.repo.ListSchedules(c.Request.Context(), t.ID)
+	if err != nil {
+		h.logger.Error("cargo schedules list", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if schedules == nil {
+		schedules = []cargo.Schedule{}
+	}
+	resp.OKLang(c, "ok", toCargoTemplateResp(t, schedules))
+}
+
+// UpdateCargoTemplate PUT .../cargo-templates/:id — заменить имя и тело шаблона (расписания сохраняются).
+func (h *CargoHandler) UpdateCargoTemplate(c *gin.Context) {
+	t, ok := h.ownTemplate(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	name, payload, ok := bindCargoTemplate(c)
+	if !ok {
+		return
+	}
+	t, err := h.repo.UpdateTemplate(c.Request.Context(), t.ID, name, payload)
+	if errors.Is(err, cargo.ErrTemplateNameTaken) {
+		resp.ErrorLang(c, http.StatusConflict, "cargo_template_name_taken")
+		return
+	}
+	if err != nil || t == nil {
+		if err != nil {
+			h.logger.Error("cargo template update", zap.Error(err))
+		}
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	resp.OKLang(c, "ok", toCargoTemplateResp(t, nil))
+}
+
+// DeleteCargoTemplate DELETE .../cargo-templates/:id — удалить шаблон; его расписания выключаются.
+func (h *CargoHandler) DeleteCargoTemplate(c *gin.Context) {
+	t, ok := h.ownTemplate(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	if err := h.repo.DeleteTemplate(c.Request.Context(), t.ID); err != nil {
+		h.logger.Error("cargo template delete", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	resp.OKLang(c, "ok", gin.H{"id": t.ID.String(), "status": "deleted"})
+}
+
+// CreateCargoFromTemplate POST .../cargo-templates/:id/cargo — создать груз из шаблона.
+// Тело (необязательно) — любые поля POST /api/cargo; они заменяют поля шаблона целиком (route_points, payment — тоже).
+func (h *CargoHandler) CreateCargoFromTemplate(c *gin.Context) {
+	t, ok := h.ownTemplate(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	var overrides map[string]json.RawMessage
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&overrides); err != nil {
+			resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+			return
+		}
+	}
+	ctx := c.Request.Context()
+	if t.OwnerType == cargo.TemplateOwnerDispatcher && !h.checkDispatcherCargoLimit(c, t.OwnerID, 1) {
+		return
+	}
+	id, err := CreateCargoFromTemplate(ctx, h.repo, h.routes, 0, t, overrides, nil)
+	if errors.Is(err, ErrTemplateCargoInvalid) {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+		return
+	}
+	if err != nil {
+		h.logger.Error("cargo from template", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "failed_to_create_cargo")
+		return
+	}
+	obj, err := h.repo.GetByID(ctx, id, false)
+	if err != nil || obj == nil {
+		resp.SuccessLang(c, http.StatusCreated, "created", gin.H{"id": id.String()})
+		return
+	}
+	points, _ := h.repo.GetRoutePoints(ctx, id)
+	pay, _ := h.repo.GetPayment(ctx, id)
+	resp.SuccessLang(c, http.StatusCreated, "created", toCargoDetail(obj, points, pay))
+}
+
+// CreateCargoSchedule POST .../cargo-templates/:id/schedules — расписание автоматического создания груза.
+func (h *CargoHandler) CreateCargoSchedule(c *gin.Context) {
+	t, ok := h.ownTemplate(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	in, next, ok := bindCargoSchedule(c)
+	if !ok {
+		return
+	}
+	s, err := h.repo.CreateSchedule(c.Request.Context(), t.ID, in, next)
+	if err != nil {
+		h.logger.Error("cargo schedule create", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	resp.SuccessLang(c, http.StatusCreated, "created", toCargoScheduleResp(s))
+}
+
+// UpdateCargoSchedule PUT .../cargo-schedules/:id — заменить параметры расписания; следующий запуск пересчитывается.
+func (h *CargoHandler) UpdateCargoSchedule(c *gin.Context) {
+	s, ok := h.ownSchedule(c)
+	if !ok {
+		return
+	}
+	in, next, ok := bindCargoSchedule(c)
+	if !ok {
+		return
+	}
+	s, err := h.repo.UpdateSchedule(c.Request.Context(), s.ID, in, next)
+	if err != nil || s == nil {
+		if err != nil {
+			h.logger.Error("cargo schedule update", zap.Error(err))
+		}
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	resp.OKLang(c, "ok", toCargoScheduleResp(s))
+}
+
+// DeleteCargoSchedule DELETE .../cargo-schedules/:id — созданные ранее грузы остаются.
+func (h *CargoHandler) DeleteCargoSchedule(c *gin.Context) {
+	s, ok := h.ownSchedule(c)
+	if !ok {
+		return
+	}
+	if err := h.repo.DeleteSchedule(c.Request.Context(), s.ID); err != nil {
+		h.logger.Error("cargo schedule delete", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	resp.OKLang(c, "ok", gin.H{"id": s.ID.String(), "status": "deleted"})
+}
+
+// CreateCargoFromTemplate создаёт груз из шаблона от имени его владельца; груз идёт на модерацию, как при POST /api/cargo.
+// dispatcherCargoLimit > 0 — проверка лимита фриланс-диспетчера (ErrDispatcherCargoLimit). Используется API и планировщиком расписаний.
+func CreateCargoFromTemplate(ctx context.Context, repo *cargo.Repo, routes *routing.Estimator, dispatcherCargoLimit int,
+	t *cargo.Template, overrides map[string]json.RawMessage, scheduleID *uuid.UUID) (uuid.UUID, error) {
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(t.Payload, &fields); err != nil {
+		return uuid.Nil, err
+	}
+	for k, v := range overrides {
+		fields[k] = v
+	}
+	raw, err := json.Marshal(fields)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	var req CreateCargoReq
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return uuid.Nil, fmt.Errorf("%w: %v", ErrTemplateCargoInvalid, err)
+	}
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		return uuid.Nil, fmt.Errorf("%w: %v", ErrTemplateCargoInvalid, err)
+	}
+	if err := validateCargoCreate(req); err != nil {
+		return uuid.Nil, fmt.Errorf("%w: %v", ErrTemplateCargoInvalid, err)
+	}
+	p := toCreateParams(req)
+	p.TemplateID = &t.ID
+	p.ScheduleID = scheduleID
+	switch t.OwnerType {
+	case cargo.TemplateOwnerDispatcher:
+		if dispatcherCargoLimit > 0 {
+			count, err := repo.CountByDispatcher(ctx, t.OwnerID)
+			if err != nil {
+				return uuid.Nil, err
+			}
+			if count >= dispatcherCargoLimit {
+				return uuid.Nil, ErrDispatcherCargoLimit
+			}
+		}
+		p.CreatedByType = strPtr("DISPATCHER")
+		p.CreatedByID = &t.OwnerID
+		p.CompanyID = req.CompanyID
+	case cargo.TemplateOwnerCompany:
+		p.CreatedByType = strPtr("COMPANY")
+		p.CreatedByID = &t.OwnerID
+		p.CompanyID = &t.OwnerID
+	}
+	id, err := repo.Create(ctx, p)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if obj, _ := repo.GetByID(ctx, id, false); obj != nil {
+		points, _ := repo.GetRoutePoints(ctx, id)
+		_ = setRouteEstimate(ctx, repo, routes, obj, points)
+	}
+	return id, nil
+}
+
+// templateOwner — владелец шаблонов по контексту: фриланс-диспетчер (/v1/dispatchers) или компания пользователя (/v1).
+func templateOwner(c *gin.Context) (string, uuid.UUID, bool) {
+	if v, ok := c.Get(mw.CtxDispatcherID); ok {
+		if id, ok := v.(uuid.UUID); ok {
+			return cargo.TemplateOwnerDispatcher, id, true
+		}
+	}
+	companyID, ok := appUserCompanyID(c)
+	if !ok {
+		resp.ErrorLang(c, http.StatusForbidden, "company_not_selected")
+		return "", uuid.Nil, false
+	}
+	return cargo.TemplateOwnerCompany, companyID, true
+}
+
+// ownTemplate загружает шаблон по id и проверяет, что он принадлежит текущему владельцу.
+func (h *CargoHandler) ownTemplate(c *gin.Context, rawID string) (*cargo.Template, bool) {
+	ownerType, ownerID, ok := templateOwner(c)
+	if !ok {
+		return nil, false
+	}
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return nil, false
+	}
+	t, err := h.repo.GetTemplate(c.Request.Context(), id)
+	if err != nil {
+		h.logger.Error("cargo template get", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return nil, false
+	}
+	if t == nil || t.OwnerType != ownerType || t.OwnerID != ownerID {
+		resp.ErrorLang(c, http.StatusNotFound, "cargo_template_not_found")
+		return nil, false
+	}
+	return t, true
+}
+
+// ownSchedule загружает расписание по :id; доступ — через владельца шаблона.
+func (h *CargoHandler) ownSchedule(c *gin.Context) (*cargo.Schedule, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return nil, false
+	}
+	s, err := h.repo.GetSchedule(c.Request.Context(), id)
+	if err != nil {
+		h.logger.Error("cargo schedule get", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return nil, false
+	}
+	if s == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "cargo_schedule_not_found")
+		return nil, false
+	}
+	if _, ok := h.ownTemplate(c, s.TemplateID.String()); !ok {
+		return nil, false
+	}
+	return s, true
+}
+
+// bindCargoTemplate читает и валидирует тело шаблона (как POST /api/cargo) и возвращает его JSON для хранения.
+func bindCargoTemplate(c *gin.Context) (string, json.RawMessage, bool) {
+	var req CargoTemplateReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+		return "", nil, false
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+		return "", nil, false
+	}
+	if err := validateCargoCreate(req.Cargo); err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+		return "", nil, false
+	}
+	req.Cargo.CompanyID = nil
+	payload, err := json.Marshal(req.Cargo)
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+		return "", nil, false
+	}
+	return name, payload, true
+}
+
+// bindCargoSchedule читает параметры расписания и считает первый запуск (nil для неактивного).
+func bindCargoSchedule(c *gin.Context) (cargo.ScheduleInput, *time.Time, bool) {
+	var req CargoScheduleReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+		return cargo.ScheduleInput{}, nil, false
+	}
+	in := cargo.ScheduleInput{Weekdays: req.Weekdays, RunTime: strings.TrimSpace(req.RunTime), ReadyOffsetDays: req.ReadyOffsetDays, Active: true}
+	if req.Active != nil {
+		in.Active = *req.Active
+	}
+	if err := in.Normalize(); err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_cargo_schedule")
+		return cargo.ScheduleInput{}, nil, false
+	}
+	if !in.Active {
+		return in, nil, true
+	}
+	next := cargo.NextScheduleRun(in.Weekdays, in.RunTime, time.Now(), timeutil.Tashkent())
+	return in, &next, true
+}
+
+func toCargoTemplateResp(t *cargo.Template, schedules []cargo.Schedule) gin.H {
+	out := gin.H{
+		"id": t.ID.String(), "owner_type": t.OwnerType, "owner_id": t.OwnerID.String(), "name": t.Name,
+		"cargo": t.Payload, "created_at": t.CreatedAt, "updated_at": t.UpdatedAt,
+	}
+	if schedules != nil {
+		items := make([]gin.H, 0, len(schedules))
+		for i := range schedules {
+			items = append(items, toCargoScheduleResp(&schedules[i]))
+		}
+		out["schedules"] = items
+	}
+	return out
+}
+
+func toCargoScheduleResp(s *cargo.Schedule) gin.H {
+	out := gin.H{
+		"id": s.ID.String(), "template_id": s.TemplateID.String(), "weekdays": s.Weekdays, "run_time": s.RunTime,
+		"timezone": timeutil.Tashkent().String(), "ready_offset_days": s.ReadyOffsetDays, "active": s.Active,
+		"next_run_at": s.NextRunAt, "last_run_at": s.LastRunAt, "last_error": s.LastError, "runs_count": s.RunsCount,
+		"created_at": s.CreatedAt, "updated_at": s.UpdatedAt,
+	}
+	if s.LastCargoID != nil {
+		out["last_cargo_id"] = s.LastCargoID.String()
+	}
+	return out
+}
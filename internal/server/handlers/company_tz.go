@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
@@ -449,3 +450,169 @@ func (h *CompanyTZHandler) RemoveUser(c *gin.Context) {
 	_ = h.audit.Log(c.Request.Context(), &userID, &companyID, "delete", "user_company_role", targetUserID, map[string]interface{}{"role": targetRoleName}, nil)
 	c.Status(http.StatusNoContent)
 }
+
+// CompanyStats GET /companies/:companyId/stats — счётчики компании и разбивка по периодам.
+// Query: period=day|week|month (по умолчанию month), from, to (YYYY-MM-DD; to включительно).
+func (h *CompanyTZHandler) CompanyStats(c *gin.Context) {
+	userID, ok := h.appUserID(c)
+	if !ok {
+		resp.ErrorLang(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	companyID, err := uuid.Parse(c.Param("companyId"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_company_id")
+		return
+	}
+	if _, ok := h.getCompanyRole(c.Request.Context(), userID, companyID); !ok {
+		resp.ErrorLang(c, http.StatusForbidden, "not_member_of_company")
+		return
+	}
+	period := strings.ToLower(strings.TrimSpace(c.DefaultQuery("period", companies.PeriodMonth)))
+	if !companies.ValidPeriod(period) {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_period")
+		return
+	}
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	if v := strings.TrimSpace(c.Query("to")); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			resp.ErrorLang(c, http.StatusBadRequest, "invalid_date")
+			return
+		}
+		to = t.AddDate(0, 0, 1)
+	}
+	var from time.Time
+	switch period {
+	case companies.PeriodDay:
+		from = to.AddDate(0, 0, -30)
+	case companies.PeriodWeek:
+		from = to.AddDate(0, 0, -7*12)
+	default:
+		from = to.AddDate(-1, 0, 0)
+	}
+	if v := strings.TrimSpace(c.Query("from")); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			resp.ErrorLang(c, http.StatusBadRequest, "invalid_date")
+			return
+		}
+		from = t
+	}
+	if !from.Before(to) {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_date_range")
+		return
+	}
+	st, err := h.companies.GetStats(c.Request.Context(), companyID, period, from, to)
+	if err != nil {
+		h.logger.Error("company stats", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if st == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "company_not_found")
+		return
+	}
+	periods := make([]gin.H, 0, len(st.Periods))
+	for _, p := range st.Periods {
+		periods = append(periods, gin.H{
+			"period_start":     p.PeriodStart.Format("2006-01-02"),
+			"completed_orders": p.CompletedOrders,
+			"cancelled_orders": p.CancelledOrders,
+			"revenue":          toCurrencyAmountsResp(p.Revenue),
+			"revenue_base":     math.Round(p.RevenueBase*100) / 100,
+		})
+	}
+	resp.OKLang(c, "ok", gin.H{
+		"company_id":       companyID,
+		"completed_orders": st.Counters.CompletedOrders,
+		"cancelled_orders": st.Counters.CancelledOrders,
+		"total_revenue":    st.Counters.TotalRevenue,
+		"revenue_currency": companies.RevenueCurrency,
+		"revenue":          toCurrencyAmountsResp(st.Revenue),
+		"period":           period,
+		"from":             from.Format("2006-01-02"),
+		"to":               to.AddDate(0, 0, -1).Format("2006-01-02"),
+		"periods":          periods,
+	})
+}
+
+// GetDisplayCurrency GET /v1/companies/:companyId/display-currency — валюта отображения цен для пользователей компании.
+func (h *CompanyTZHandler) GetDisplayCurrency(c *gin.Context) {
+	userID, ok := h.appUserID(c)
+	if !ok {
+		resp.ErrorLang(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	companyID, err := uuid.Parse(c.Param("companyId"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_company_id")
+		return
+	}
+	if _, ok := h.getCompanyRole(c.Request.Context(), userID, companyID); !ok {
+		resp.ErrorLang(c, http.StatusForbidden, "not_member_of_company")
+		return
+	}
+	cur, err := h.companies.DisplayCurrency(c.Request.Context(), companyID)
+	if err != nil {
+		h.logger.Error("company display currency", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	resp.OKLang(c, "ok", displayCurrencyBody(cur))
+}
+
+// PutDisplayCurrency PUT /v1/companies/:companyId/display-currency — меняют Owner и CEO.
+func (h *CompanyTZHandler) PutDisplayCurrency(c *gin.Context) {
+	userID, ok := h.appUserID(c)
+	if !ok {
+		resp.ErrorLang(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	companyID, err := uuid.Parse(c.Param("companyId"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_company_id")
+		return
+	}
+	role, ok := h.getCompanyRole(c.Request.Context(), userID, companyID)
+	if !ok {
+		resp.ErrorLang(c, http.StatusForbidden, "not_member_of_company")
+		return
+	}
+	if !companytz.CanEditCompany(role) {
+		resp.ErrorLang(c, http.StatusForbidden, "your_role_cannot_edit_company")
+		return
+	}
+	var req displayCurrencyReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload")
+		return
+	}
+	cur, ok := normalizeDisplayCurrency(*req.DisplayCurrency)
+	if !ok {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_currency")
+		return
+	}
+	old, err := h.companies.DisplayCurrency(c.Request.Context(), companyID)
+	if err != nil {
+		h.logger.Error("company display currency", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if err := h.companies.SetDisplayCurrency(c.Request.Context(), companyID, cur); err != nil {
+		h.logger.Error("set company display currency", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	_ = h.audit.Log(c.Request.Context(), &userID, &companyID, "update", "company", companyID, map[string]interface{}{"display_currency": old}, map[string]interface{}{"display_currency": cur})
+	resp.OKLang(c, "updated", displayCurrencyBody(cur))
+}
+
+func toCurrencyAmountsResp(list []companies.CurrencyAmount) []gin.H {
+	out := make([]gin.H, 0, len(list))
+	for _, a := range list {
+		out = append(out, gin.H{"currency": a.Currency, "amount": a.Amount})
+	}
+	return out
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"sarbonNew/internal/currency"
+	"sarbonNew/internal/reference"
+	"sarbonNew/internal/server/mw"
+	"sarbonNew/internal/server/resp"
+)
+
+// maxRatesImportSize — макс. размер файла импорта курсов.
+const maxRatesImportSize = 2 << 20
+
+type CurrencyRatesHandler struct {
+	logger *zap.Logger
+	repo   *currency.Repo
+}
+
+func NewCurrencyRatesHandler(logger *zap.Logger, repo *currency.Repo) *CurrencyRatesHandler {
+	return &CurrencyRatesHandler{logger: logger, repo: repo}
+}
+
+// Current GET /v1/reference/currency-rates?date=YYYY-MM-DD — курсы всех валют к базовой на дату (по умолчанию сегодня).
+func (h *CurrencyRatesHandler) Current(c *gin.Context) {
+	date := time.Now()
+	if v := strings.TrimSpace(c.Query("date")); v != "" {
+		d, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			resp.ErrorLang(c, http.StatusBadRequest, "invalid_date")
+			return
+		}
+		date = d
+	}
+	rates, err := h.repo.RatesOn(c.Request.Context(), date)
+	if err != nil {
+		h.logger.Error("currency rates", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	rates[currency.Base] = 1
+	resp.OKLang(c, "ok", gin.H{"base": currency.Base, "date": date.Format("2006-01-02"), "rates": rates})
+}
+
+// AdminList GET /v1/admin/currency-rates — история курсов. Query: currency, from, to (YYYY-MM-DD), limit, offset.
+func (h *CurrencyRatesHandler) AdminList(c *gin.Context) {
+	to := time.Now()
+	from := to.AddDate(0, -1, 0)
+	for key, dst := range map[string]*time.Time{"from": &from, "to": &to} {
+		if v := strings.TrimSpace(c.Query(key)); v != "" {
+			d, err := time.Parse("2006-01-02", v)
+			if err != nil {
+				resp.ErrorLang(c, http.StatusBadRequest, "invalid_date")
+				return
+			}
+			*dst = d
+		}
+	}
+	offset := getIntQuery(c, "offset", 0)
+	list, err := h.repo.List(c.Request.Context(), strings.ToUpper(strings.TrimSpace(c.Query("currency"))), from, to, getIntQuery(c, "limit", 100), offset)
+	if err != nil {
+		h.logger.Error("currency rates list", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	items := make([]gin.H, 0, len(list))
+	for _, x := range list {
+		items = append(items, toCurrencyRateResp(x))
+	}
+	resp.OKLang(c, "ok", gin.H{"base": currency.Base, "items": items})
+}
+
+// UpsertRateReq body for PUT /v1/admin/currency-rates.
+type UpsertRateReq struct {
+	Currency string  `json:"currency" binding:"required"`
+	Date     string  `json:"date" binding:"required"`
+	Rate     float64 `json:"rate" binding:"required,gt=0"`
+}
+
+// AdminUpsert PUT /v1/admin/currency-rates — задать курс валюты на дату (единиц базовой валюты за 1 единицу).
+func (h *CurrencyRatesHandler) AdminUpsert(c *gin.Context) {
+	var req UpsertRateReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+		return
+	}
+	cur := strings.ToUpper(strings.TrimSpace(req.Currency))
+	if !isRateCurrency(cur) {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_currency")
+		return
+	}
+	d, err := time.Parse("2006-01-02", strings.TrimSpace(req.Date))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_date")
+		return
+	}
+	adminID := adminIDFromCtx(c)
+	if err := h.repo.Upsert(c.Request.Context(), cur, d, req.Rate, currency.SourceManual, adminID); err != nil {
+		h.logger.Error("currency rate upsert", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "failed_to_update")
+		return
+	}
+	resp.OKLang(c, "updated", gin.H{"currency": cur, "date": d.Format("2006-01-02"), "rate": req.Rate, "base": currency.Base})
+}
+
+// AdminDelete DELETE /v1/admin/currency-rates/:currency/:date
+func (h *CurrencyRatesHandler) AdminDelete(c *gin.Context) {
+	cur := strings.ToUpper(strings.TrimSpace(c.Param("currency")))
+	d, err := time.Parse("2006-01-02", c.Param("date"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_date")
+		return
+	}
+	ok, err := h.repo.Delete(c.Request.Context(), cur, d)
+	if err != nil {
+		h.logger.Error("currency rate delete", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if !ok {
+		resp.ErrorLang(c, http.StatusNotFound, "currency_rate_not_found")
+		return
+	}
+	resp.OKLang(c, "ok", gin.H{"currency": cur, "date": d.Format("2006-01-02")})
+}
+
+// AdminImport POST /v1/admin/currency-rates/import — multipart, поле "file": CSV с колонками date,currency,rate.
+// Файл применяется целиком или не применяется вовсе.
+func (h *CurrencyRatesHandler) AdminImport(c *gin.Context) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "file_required")
+		return
+	}
+	if file.Size > maxRatesImportSize {
+		resp.ErrorLang(c, http.StatusBadRequest, "file_too_large")
+		return
+	}
+	f, err := file.Open()
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "cannot_read_file")
+		return
+	}
+	defer f.Close()
+	rows, err := currency.ParseCSV(f)
+	if err != nil {
+		resp.ErrorWithData(c, http.StatusBadRequest, resp.Msg("invalid_rates_file", resp.Lang(c)), gin.H{"detail": err.Error()})
+		return
+	}
+	for _, row := range rows {
+		if !isRateCurrency(row.Currency) {
+			resp.ErrorWithData(c, http.StatusBadRequest, resp.Msg("invalid_rates_file", resp.Lang(c)), gin.H{"line": row.Line, "currency": row.Currency})
+			return
+		}
+	}
+	if err := h.repo.UpsertMany(c.Request.Context(), rows, adminIDFromCtx(c)); err != nil {
+		h.logger.Error("currency rates import", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "failed_to_update")
+		return
+	}
+	resp.OKLang(c, "ok", gin.H{"imported": len(rows)})
+}
+
+// isRateCurrency — валюта из справочника, для которой хранится курс (кроме базовой и OTHER).
+func isRateCurrency(cur string) bool {
+	return cur != currency.Base && cur != "OTHER" && reference.IsAllowed(cur, reference.AllowedCurrencies())
+}
+
+func adminIDFromCtx(c *gin.Context) *uuid.UUID {
+	if v, ok := c.Get(mw.CtxAdminID); ok {
+		if id, ok := v.(uuid.UUID); ok {
+			return &id
+		}
+	}
+	return nil
+}
+
+func toCurrencyRateResp(x currency.Rate) gin.H {
+	out := gin.H{
+		"id": x.ID.String(), "currency": x.Currency, "date": x.RateDate.Format("2006-01-02"), "rate": x.Rate,
+		"source": x.Source, "created_at": x.CreatedAt, "updated_at": x.UpdatedAt,
+	}
+	if x.UpdatedBy != nil {
+		out["updated_by"] = x.UpdatedBy.String()
+	}
+	return out
+}
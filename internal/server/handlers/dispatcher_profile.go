@@ -45,6 +45,39 @@ func (h *DispatcherProfileHandler) Get(c *gin.Context) {
 	resp.OKLang(c, "ok", gin.H{"dispatcher": d})
 }
 
+// GetDisplayCurrency GET /v1/dispatchers/profile/display-currency
+func (h *DispatcherProfileHandler) GetDisplayCurrency(c *gin.Context) {
+	id := c.MustGet(mw.CtxDispatcherID).(uuid.UUID)
+	cur, err := h.repo.DisplayCurrency(c.Request.Context(), id)
+	if err != nil {
+		h.logger.Error("dispatcher display currency", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	resp.OKLang(c, "ok", displayCurrencyBody(cur))
+}
+
+// PutDisplayCurrency PUT /v1/dispatchers/profile/display-currency
+func (h *DispatcherProfileHandler) PutDisplayCurrency(c *gin.Context) {
+	id := c.MustGet(mw.CtxDispatcherID).(uuid.UUID)
+	var req displayCurrencyReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload")
+		return
+	}
+	cur, ok := normalizeDisplayCurrency(*req.DisplayCurrency)
+	if !ok {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_currency")
+		return
+	}
+	if err := h.repo.SetDisplayCurrency(c.Request.Context(), id, cur); err != nil {
+		h.logger.Error("set dispatcher display currency", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	resp.OKLang(c, "updated", displayCurrencyBody(cur))
+}
+
 type dispPatchReq struct {
 	Name           *string `json:"name,omitempty"`
 	PassportSeries *string `json:"passport_series,omitempty"`
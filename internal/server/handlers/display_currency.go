@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"sarbonNew/internal/companies"
+	"sarbonNew/internal/dispatchers"
+	"sarbonNew/internal/drivers"
+	"sarbonNew/internal/reference"
+	"sarbonNew/internal/security"
+)
+
+// displayCurrencyReq body for PUT .../display-currency. Пустая строка сбрасывает предпочтение.
+type displayCurrencyReq struct {
+	DisplayCurrency *string `json:"display_currency" binding:"required"`
+}
+
+// normalizeDisplayCurrency приводит код валюты к верхнему регистру; "" — без предпочтения.
+// ok = false — валюта не из справочника (OTHER тоже нельзя: в неё нечего пересчитывать).
+func normalizeDisplayCurrency(raw string) (cur string, ok bool) {
+	cur = strings.ToUpper(strings.TrimSpace(raw))
+	if cur == "" {
+		return "", true
+	}
+	if cur == "OTHER" || !reference.IsAllowed(cur, reference.AllowedCurrencies()) {
+		return "", false
+	}
+	return cur, true
+}
+
+// displayCurrencyBody — ответ GET/PUT .../display-currency; null — предпочтение не задано.
+func displayCurrencyBody(cur string) gin.H {
+	if cur == "" {
+		return gin.H{"display_currency": nil}
+	}
+	return gin.H{"display_currency": cur}
+}
+
+// DisplayCurrencyPrefs находит сохранённую валюту отображения по X-User-Token:
+// у водителя и диспетчера — своя, у пользователя компании — выбранной в токене компании.
+type DisplayCurrencyPrefs struct {
+	jwtm        *security.JWTManager
+	drivers     *drivers.Repo
+	dispatchers *dispatchers.Repo
+	companies   *companies.Repo
+}
+
+func NewDisplayCurrencyPrefs(jwtm *security.JWTManager, driversRepo *drivers.Repo, dispatchersRepo *dispatchers.Repo, companiesRepo *companies.Repo) *DisplayCurrencyPrefs {
+	return &DisplayCurrencyPrefs{jwtm: jwtm, drivers: driversRepo, dispatchers: dispatchersRepo, companies: companiesRepo}
+}
+
+// Lookup возвращает валюту отображения владельца токена; "" — токена нет, он невалиден или валюта не задана.
+func (p *DisplayCurrencyPrefs) Lookup(ctx context.Context, rawToken string) (string, error) {
+	rawToken = strings.TrimSpace(rawToken)
+	if p == nil || p.jwtm == nil || rawToken == "" {
+		return "", nil
+	}
+	userID, role, companyID, _, err := p.jwtm.ParseAccessWithSID(rawToken)
+	if err != nil || userID == uuid.Nil {
+		return "", nil
+	}
+	switch role {
+	case "driver":
+		return p.drivers.DisplayCurrency(ctx, userID)
+	case "dispatcher":
+		return p.dispatchers.DisplayCurrency(ctx, userID)
+	case "user":
+		if companyID != uuid.Nil {
+			return p.companies.DisplayCurrency(ctx, companyID)
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,18 @@
+package handlers
+
+import "testing"
+
+func TestNormalizeDisplayCurrency(t *testing.T) {
+	if cur, ok := normalizeDisplayCurrency(" usd "); !ok || cur != "USD" {
+		t.Errorf("usd: got %q %v", cur, ok)
+	}
+	if cur, ok := normalizeDisplayCurrency(""); !ok || cur != "" {
+		t.Errorf("empty resets: got %q %v", cur, ok)
+	}
+	if _, ok := normalizeDisplayCurrency("OTHER"); ok {
+		t.Error("OTHER accepted")
+	}
+	if _, ok := normalizeDisplayCurrency("XXX"); ok {
+		t.Error("unknown currency accepted")
+	}
+}
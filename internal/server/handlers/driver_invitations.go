@@ -286,6 +286,9 @@ type SetDriverTrailerReq struct {
 	TrailerOwnerID     *string `json:"trailer_owner_id,omitempty"`
 	TrailerOwnerName   *string `json:"trailer_owner_name,omitempty"`
 	TrailerScanStatus  *bool   `json:"trailer_scan_status,omitempty"`
+	// TrailerCapacityWeight (т) и TrailerCapacityVolume (м³) — вместимость для консолидации грузов.
+	TrailerCapacityWeight *float64 `json:"trailer_capacity_weight,omitempty" binding:"omitempty,gt=0"`
+	TrailerCapacityVolume *float64 `json:"trailer_capacity_volume,omitempty" binding:"omitempty,gt=0"`
 }
 
 // SetDriverTrailer adds or updates прицеп for a driver. Водитель должен быть принят по приглашению (freelancer_id = я).
@@ -335,6 +338,8 @@ func (h *DriverInvitationsHandler) SetDriverTrailer(c *gin.Context) {
 		TrailerOwnerID:     req.TrailerOwnerID,
 		TrailerOwnerName:   req.TrailerOwnerName,
 		TrailerScanStatus:  req.TrailerScanStatus,
+		CapacityWeight:     req.TrailerCapacityWeight,
+		CapacityVolume:     req.TrailerCapacityVolume,
 	}); err != nil {
 		h.logger.Error("dispatcher set driver trailer", zap.Error(err))
 		resp.ErrorLang(c, http.StatusInternalServerError, "failed_to_update_trailer")
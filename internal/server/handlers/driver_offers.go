@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"sarbonNew/internal/cargo"
+	"sarbonNew/internal/notifications"
+	"sarbonNew/internal/server/mw"
+	"sarbonNew/internal/server/resp"
+)
+
+// maxOfferValidHours — максимальный срок действия оффера (30 дней).
+const maxOfferValidHours = 720
+
+// offerExpiresAt — срок действия нового оффера: validHours часов или def (0 — без срока).
+// Время в UTC: expires_at (TIMESTAMP) сравнивается с now() в БД. ok = false — validHours вне 1..maxOfferValidHours.
+func offerExpiresAt(validHours *int, def time.Duration) (expiresAt *time.Time, ok bool) {
+	validity := def
+	if validHours != nil {
+		if *validHours < 1 || *validHours > maxOfferValidHours {
+			return nil, false
+		}
+		validity = time.Duration(*validHours) * time.Hour
+	}
+	if validity <= 0 {
+		return nil, true
+	}
+	t := time.Now().UTC().Add(validity)
+	return &t, true
+}
+
+var offerStatuses = []string{cargo.OfferStatusPending, cargo.OfferStatusAccepted, cargo.OfferStatusRejected, cargo.OfferStatusExpired, cargo.OfferStatusWithdrawn}
+
+// ListMyOffers GET /v1/driver/offers — офферы водителя по всем грузам. Query: status (через запятую), limit, offset.
+func (h *CargoHandler) ListMyOffers(c *gin.Context) {
+	driverID := c.MustGet(mw.CtxDriverID).(uuid.UUID)
+	var statuses []string
+	if v := strings.TrimSpace(c.Query("status")); v != "" {
+		for _, st := range strings.Split(v, ",") {
+			st = strings.ToUpper(strings.TrimSpace(st))
+			if !containsStr(offerStatuses, st) {
+				resp.ErrorLang(c, http.StatusBadRequest, "invalid_status")
+				return
+			}
+			statuses = append(statuses, st)
+		}
+	}
+	list, total, err := h.repo.ListOffersByCarrier(c.Request.Context(), driverID, statuses, getIntQuery(c, "limit", 20), getIntQuery(c, "offset", 0))
+	if err != nil {
+		h.logger.Error("driver offers list", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	resp.OKLang(c, "ok", gin.H{"items": toOfferList(list), "total": total})
+}
+
+// WithdrawOffer POST /v1/driver/offers/:id/withdraw — водитель отзывает свой PENDING-оффер; владелец груза получает уведомление.
+func (h *CargoHandler) WithdrawOffer(c *gin.Context) {
+	driverID := c.MustGet(mw.CtxDriverID).(uuid.UUID)
+	offerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return
+	}
+	ctx := c.Request.Context()
+	offer, err := h.repo.WithdrawOffer(ctx, offerID, driverID)
+	if errors.Is(err, cargo.ErrOfferNotPending) {
+		resp.ErrorLang(c, http.StatusBadRequest, "offer_not_found_or_not_pending")
+		return
+	}
+	if err != nil || offer == nil {
+		h.logger.Error("withdraw offer", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if obj, _ := h.repo.GetByID(ctx, offer.CargoID, false); obj != nil {
+		if recipientType, recipientID, ok := cargoOwner(obj); ok {
+			h.notifier.Notify(ctx, recipientType, recipientID, notifications.KindOfferWithdrawn, map[string]any{
+				"offer_id": offer.ID.String(), "cargo_id": offer.CargoID.String(), "driver_id": driverID.String(),
+				"price": offer.Price, "currency": offer.Currency,
+			})
+		}
+	}
+	resp.OKLang(c, "ok", toOfferResp(offer))
+}
+
+// cargoOwner — кому отправлять уведомления по грузу: диспетчеру-создателю или компании груза.
+func cargoOwner(obj *cargo.Cargo) (recipientType string, recipientID uuid.UUID, ok bool) {
+	if obj.CreatedByType != nil && *obj.CreatedByType == "DISPATCHER" && obj.CreatedByID != nil {
+		return notifications.RecipientDispatcher, *obj.CreatedByID, true
+	}
+	if obj.CompanyID != nil {
+		return notifications.RecipientCompany, *obj.CompanyID, true
+	}
+	return "", uuid.Nil, false
+}
+
+func containsStr(list []string, v string) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
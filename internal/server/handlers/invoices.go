@@ -0,0 +1,348 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"sarbonNew/internal/cargo"
+	"sarbonNew/internal/invoices"
+	"sarbonNew/internal/notifications"
+	"sarbonNew/internal/reference"
+	"sarbonNew/internal/server/mw"
+	"sarbonNew/internal/server/resp"
+	"sarbonNew/internal/trips"
+)
+
+// InvoicesHandler — счета за перевозку, платежи, просрочка и балансы.
+// Плательщик — создатель груза (диспетчер или компания), получатель — водитель (и его компания).
+type InvoicesHandler struct {
+	logger    *zap.Logger
+	repo      *invoices.Repo
+	gen       *invoices.Generator
+	trips     *trips.Repo
+	cargoRepo *cargo.Repo
+	notifier  *notifications.Notifier
+}
+
+// NewInvoicesHandler creates the handler.
+func NewInvoicesHandler(logger *zap.Logger, repo *invoices.Repo, gen *invoices.Generator, tripsRepo *trips.Repo, cargoRepo *cargo.Repo, notifier *notifications.Notifier) *InvoicesHandler {
+	return &InvoicesHandler{logger: logger, repo: repo, gen: gen, trips: tripsRepo, cargoRepo: cargoRepo, notifier: notifier}
+}
+
+// GetForTrip — счёт рейса с платежами для создателя груза; выставляется при первом обращении.
+// GET /v1/dispatchers/trips/:id/invoice, GET /v1/trips/:id/invoice
+func (h *InvoicesHandler) GetForTrip(c *gin.Context) {
+	t, _, _, ok := cargoCreatorTrip(c, h.trips, h.cargoRepo)
+	if !ok {
+		return
+	}
+	h.sendInvoice(c, t)
+}
+
+// GetMy — счёт рейса для назначенного водителя.
+// GET /v1/driver/trips/:id/invoice
+func (h *InvoicesHandler) GetMy(c *gin.Context) {
+	t, ok := driverOwnTrip(c, h.trips)
+	if !ok {
+		return
+	}
+	h.sendInvoice(c, t)
+}
+
+// AddPaymentReq — платёж по счёту. paid_at — RFC3339, по умолчанию сейчас; method — из справочника prepayment_type.
+type AddPaymentReq struct {
+	Kind      string  `json:"kind" binding:"required"`
+	Amount    float64 `json:"amount" binding:"required,gt=0"`
+	Method    *string `json:"method"`
+	Reference *string `json:"reference" binding:"omitempty,max=255"`
+	PaidAt    *string `json:"paid_at"`
+}
+
+// AddPayment — создатель груза отмечает платёж (предоплата, остаток, частичная оплата); водителю приходит PAYMENT_RECORDED.
+// POST /v1/dispatchers/trips/:id/invoice/payments, POST /v1/trips/:id/invoice/payments
+func (h *InvoicesHandler) AddPayment(c *gin.Context) {
+	t, byType, byID, ok := cargoCreatorTrip(c, h.trips, h.cargoRepo)
+	if !ok {
+		return
+	}
+	h.addPayment(c, t, byType, byID)
+}
+
+// AddPaymentByDriver — водитель отмечает полученную оплату (например, наличными при выгрузке); плательщику приходит PAYMENT_RECORDED.
+// POST /v1/driver/trips/:id/invoice/payments
+func (h *InvoicesHandler) AddPaymentByDriver(c *gin.Context) {
+	t, ok := driverOwnTrip(c, h.trips)
+	if !ok {
+		return
+	}
+	h.addPayment(c, t, invoices.PartyDriver, *t.DriverID)
+}
+
+// ListDispatcher — счета, выставленные по грузам диспетчера (?status=, ?overdue=true, ?limit=).
+// GET /v1/dispatchers/invoices
+func (h *InvoicesHandler) ListDispatcher(c *gin.Context) {
+	id := c.MustGet(mw.CtxDispatcherID).(uuid.UUID)
+	h.list(c, invoices.Filter{PayerType: invoices.PartyDispatcher, PayerID: &id})
+}
+
+// BalanceDispatcher — баланс диспетчера как плательщика по валютам.
+// GET /v1/dispatchers/invoices/balance
+func (h *InvoicesHandler) BalanceDispatcher(c *gin.Context) {
+	id := c.MustGet(mw.CtxDispatcherID).(uuid.UUID)
+	payer, ok := h.balance(c, invoices.Filter{PayerType: invoices.PartyDispatcher, PayerID: &id})
+	if !ok {
+		return
+	}
+	resp.OKLang(c, "ok", gin.H{"as_payer": payer})
+}
+
+// ListCompany — счета компании: ?role=payer (по грузам компании, по умолчанию) или carrier (рейсы водителей компании).
+// GET /v1/invoices
+func (h *InvoicesHandler) ListCompany(c *gin.Context) {
+	companyID, ok := appUserCompanyID(c)
+	if !ok {
+		resp.ErrorLang(c, http.StatusForbidden, "company_not_selected")
+		return
+	}
+	switch strings.ToLower(c.DefaultQuery("role", "payer")) {
+	case "payer":
+		h.list(c, invoices.Filter{PayerType: invoices.PartyCompany, PayerID: &companyID})
+	case "carrier":
+		h.list(c, invoices.Filter{CarrierCompanyID: &companyID})
+	default:
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+	}
+}
+
+// BalanceCompany — баланс компании по валютам: как плательщика (грузы компании) и как перевозчика (водители компании).
+// GET /v1/invoices/balance
+func (h *InvoicesHandler) BalanceCompany(c *gin.Context) {
+	companyID, ok := appUserCompanyID(c)
+	if !ok {
+		resp.ErrorLang(c, http.StatusForbidden, "company_not_selected")
+		return
+	}
+	payer, ok := h.balance(c, invoices.Filter{PayerType: invoices.PartyCompany, PayerID: &companyID})
+	if !ok {
+		return
+	}
+	carrier, ok := h.balance(c, invoices.Filter{CarrierCompanyID: &companyID})
+	if !ok {
+		return
+	}
+	resp.OKLang(c, "ok", gin.H{"as_payer": payer, "as_carrier": carrier})
+}
+
+// ListDriver — счета по рейсам водителя.
+// GET /v1/driver/invoices
+func (h *InvoicesHandler) ListDriver(c *gin.Context) {
+	id := c.MustGet(mw.CtxDriverID).(uuid.UUID)
+	h.list(c, invoices.Filter{DriverID: &id})
+}
+
+// BalanceDriver — сколько водителю начислено, получено и причитается, по валютам.
+// GET /v1/driver/invoices/balance
+func (h *InvoicesHandler) BalanceDriver(c *gin.Context) {
+	id := c.MustGet(mw.CtxDriverID).(uuid.UUID)
+	receivable, ok := h.balance(c, invoices.Filter{DriverID: &id})
+	if !ok {
+		return
+	}
+	resp.OKLang(c, "ok", gin.H{"as_carrier": receivable})
+}
+
+func (h *InvoicesHandler) sendInvoice(c *gin.Context, t *trips.Trip) {
+	ctx := c.Request.Context()
+	inv, ok := h.issue(c, t)
+	if !ok {
+		return
+	}
+	payments, err := h.repo.Payments(ctx, inv.ID)
+	if err != nil {
+		h.logger.Error("invoice payments", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if payments == nil {
+		payments = []invoices.Payment{}
+	}
+	resp.OKLang(c, "ok", toInvoiceResp(inv, payments, time.Now()))
+}
+
+func (h *InvoicesHandler) addPayment(c *gin.Context, t *trips.Trip, byType string, byID uuid.UUID) {
+	var req AddPaymentReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+		return
+	}
+	p := invoices.Payment{Kind: strings.ToUpper(strings.TrimSpace(req.Kind)), Amount: req.Amount, Reference: req.Reference,
+		PaidAt: time.Now(), RecordedByType: byType, RecordedByID: byID}
+	if !invoices.IsKind(p.Kind) {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payment_kind")
+		return
+	}
+	if req.Method != nil {
+		method := strings.ToUpper(strings.TrimSpace(*req.Method))
+		if !containsStr(reference.AllowedPrepaymentTypes(), method) {
+			resp.ErrorLang(c, http.StatusBadRequest, "invalid_payment_method")
+			return
+		}
+		p.Method = &method
+	}
+	if req.PaidAt != nil {
+		at, err := time.Parse(time.RFC3339, *req.PaidAt)
+		if err != nil || at.After(time.Now().Add(time.Minute)) {
+			resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+			return
+		}
+		p.PaidAt = at
+	}
+	inv, ok := h.issue(c, t)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+	payment, err := h.repo.AddPayment(ctx, inv.ID, p)
+	switch {
+	case errors.Is(err, invoices.ErrCancelled):
+		resp.ErrorLang(c, http.StatusConflict, "invoice_cancelled")
+		return
+	case errors.Is(err, invoices.ErrAlreadyPaid):
+		resp.ErrorLang(c, http.StatusConflict, "invoice_already_paid")
+		return
+	case errors.Is(err, invoices.ErrOverpayment):
+		resp.ErrorWithData(c, http.StatusBadRequest, resp.Msg("invoice_overpayment", resp.Lang(c)), gin.H{"outstanding": inv.Outstanding()})
+		return
+	case err != nil:
+		h.logger.Error("invoice add payment", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if updated, err := h.repo.GetByTrip(ctx, t.ID); err == nil && updated != nil {
+		inv = updated
+	}
+	h.notifyPayment(ctx, inv, payment)
+	payments, _ := h.repo.Payments(ctx, inv.ID)
+	if payments == nil {
+		payments = []invoices.Payment{}
+	}
+	resp.SuccessLang(c, http.StatusCreated, "created", toInvoiceResp(inv, payments, time.Now()))
+}
+
+// notifyPayment уведомляет другую сторону счёта о записанном платеже.
+func (h *InvoicesHandler) notifyPayment(ctx context.Context, inv *invoices.Invoice, p *invoices.Payment) {
+	payload := map[string]any{
+		"invoice_id": inv.ID.String(), "number": inv.Number, "trip_id": inv.TripID.String(), "kind": p.Kind,
+		"amount": p.Amount, "currency": inv.Currency, "outstanding": inv.Outstanding(), "status": inv.Status,
+	}
+	if p.RecordedByType == invoices.PartyDriver {
+		h.notifier.Notify(ctx, inv.PayerType, inv.PayerID, notifications.KindPaymentRecorded, payload)
+		return
+	}
+	h.notifier.Notify(ctx, notifications.RecipientDriver, inv.DriverID, notifications.KindPaymentRecorded, payload)
+}
+
+// issue возвращает счёт рейса (выставляет при первом обращении) или пишет ошибку.
+func (h *InvoicesHandler) issue(c *gin.Context, t *trips.Trip) (*invoices.Invoice, bool) {
+	inv, err := h.gen.Issue(c.Request.Context(), t)
+	switch {
+	case errors.Is(err, invoices.ErrNotReady), errors.Is(err, invoices.ErrNoPayer):
+		resp.ErrorLang(c, http.StatusConflict, "invoice_unavailable")
+		return nil, false
+	case errors.Is(err, invoices.ErrCargoNotFound):
+		resp.ErrorLang(c, http.StatusNotFound, "cargo_not_found")
+		return nil, false
+	case err != nil:
+		h.logger.Error("invoice issue", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return nil, false
+	}
+	return inv, true
+}
+
+func (h *InvoicesHandler) list(c *gin.Context, f invoices.Filter) {
+	f.Status = strings.ToUpper(strings.TrimSpace(c.Query("status")))
+	f.OverdueOnly = c.Query("overdue") == "true"
+	f.Limit = getIntQuery(c, "limit", 50)
+	if f.Limit > 200 {
+		f.Limit = 200
+	}
+	list, err := h.repo.List(c.Request.Context(), f)
+	if err != nil {
+		h.logger.Error("invoices list", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	now := time.Now()
+	items := make([]gin.H, 0, len(list))
+	for i := range list {
+		items = append(items, toInvoiceResp(&list[i], nil, now))
+	}
+	resp.OKLang(c, "ok", gin.H{"items": items})
+}
+
+func (h *InvoicesHandler) balance(c *gin.Context, f invoices.Filter) ([]gin.H, bool) {
+	list, err := h.repo.List(c.Request.Context(), f)
+	if err != nil {
+		h.logger.Error("invoices balance", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return nil, false
+	}
+	balances := invoices.Balances(list, time.Now())
+	out := make([]gin.H, 0, len(balances))
+	for _, b := range balances {
+		out = append(out, gin.H{
+			"currency": b.Currency, "invoiced": b.Invoiced, "paid": b.Paid, "outstanding": b.Outstanding,
+			"overdue": b.Overdue, "invoices": b.Invoices, "overdue_invoices": b.OverdueInvoices,
+		})
+	}
+	return out, true
+}
+
+// toInvoiceResp — счёт с вычисляемыми суммами; payments == nil — без списка платежей.
+func toInvoiceResp(inv *invoices.Invoice, payments []invoices.Payment, now time.Time) gin.H {
+	res := gin.H{
+		"id":                  inv.ID.String(),
+		"number":              inv.Number,
+		"trip_id":             inv.TripID.String(),
+		"payer_type":          inv.PayerType,
+		"payer_id":            inv.PayerID.String(),
+		"driver_id":           inv.DriverID.String(),
+		"carrier_company_id":  inv.CarrierCompanyID,
+		"currency":            inv.Currency,
+		"amount":              inv.Amount,
+		"prepayment_amount":   inv.PrepaymentAmount,
+		"prepayment_due_at":   inv.PrepaymentDueAt,
+		"remaining_amount":    inv.RemainingAmount(),
+		"remaining_type":      inv.RemainingType,
+		"remaining_term_days": inv.RemainingTermDays,
+		"remaining_due_at":    inv.RemainingDueAt(),
+		"delivered_at":        inv.DeliveredAt,
+		"paid_amount":         inv.PaidAmount,
+		"outstanding":         inv.Outstanding(),
+		"overdue_amount":      inv.OverdueAmount(now),
+		"overdue":             inv.Overdue(now),
+		"overdue_since":       inv.OverdueSince,
+		"status":              inv.Status,
+		"issued_at":           inv.IssuedAt,
+		"updated_at":          inv.UpdatedAt,
+	}
+	if payments != nil {
+		items := make([]gin.H, 0, len(payments))
+		for _, p := range payments {
+			items = append(items, gin.H{
+				"id": p.ID.String(), "kind": p.Kind, "amount": p.Amount, "method": p.Method, "reference": p.Reference,
+				"paid_at": p.PaidAt, "recorded_by_type": p.RecordedByType, "created_at": p.CreatedAt,
+			})
+		}
+		res["payments"] = items
+	}
+	return res
+}
@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"sarbonNew/internal/drivers"
+	"sarbonNew/internal/ledger"
+	"sarbonNew/internal/reference"
+	"sarbonNew/internal/server/mw"
+	"sarbonNew/internal/server/resp"
+)
+
+// LedgerHandler — комиссия фриланс-диспетчера с привязанных водителей и выписки по заработку и комиссии.
+type LedgerHandler struct {
+	logger  *zap.Logger
+	repo    *ledger.Repo
+	drivers *drivers.Repo
+}
+
+// NewLedgerHandler creates the handler.
+func NewLedgerHandler(logger *zap.Logger, repo *ledger.Repo, driversRepo *drivers.Repo) *LedgerHandler {
+	return &LedgerHandler{logger: logger, repo: repo, drivers: driversRepo}
+}
+
+// SetCommissionReq — комиссия с рейса: PERCENT (percent от согласованной цены) или FIXED (amount в currency).
+type SetCommissionReq struct {
+	Type     string   `json:"type" binding:"required"`
+	Percent  *float64 `json:"percent"`
+	Amount   *float64 `json:"amount"`
+	Currency *string  `json:"currency"`
+}
+
+// ListCommissions — соглашения о комиссии диспетчера со всеми водителями.
+// GET /v1/dispatchers/commissions
+func (h *LedgerHandler) ListCommissions(c *gin.Context) {
+	dispatcherID := c.MustGet(mw.CtxDispatcherID).(uuid.UUID)
+	list, err := h.repo.ListAgreements(c.Request.Context(), dispatcherID)
+	if err != nil {
+		h.logger.Error("commission agreements list", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	items := make([]gin.H, 0, len(list))
+	for i := range list {
+		items = append(items, toAgreementResp(&list[i]))
+	}
+	resp.OKLang(c, "ok", gin.H{"items": items})
+}
+
+// GetCommission — комиссия с водителя (agreement = null — не задана).
+// GET /v1/dispatchers/drivers/:driverId/commission
+func (h *LedgerHandler) GetCommission(c *gin.Context) {
+	dispatcherID := c.MustGet(mw.CtxDispatcherID).(uuid.UUID)
+	driverID, err := uuid.Parse(c.Param("driverId"))
+	if err != nil || driverID == uuid.Nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_driver_id")
+		return
+	}
+	a, err := h.repo.GetAgreement(c.Request.Context(), dispatcherID, driverID)
+	if err != nil {
+		h.logger.Error("commission agreement get", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	resp.OKLang(c, "ok", gin.H{"agreement": toAgreementResp(a)})
+}
+
+// SetCommission — задать или изменить комиссию с привязанного водителя (freelancer_id = я).
+// Новые условия применяются к рейсам, завершённым после изменения.
+// PUT /v1/dispatchers/drivers/:driverId/commission
+func (h *LedgerHandler) SetCommission(c *gin.Context) {
+	dispatcherID := c.MustGet(mw.CtxDispatcherID).(uuid.UUID)
+	driverID, err := uuid.Parse(c.Param("driverId"))
+	if err != nil || driverID == uuid.Nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_driver_id")
+		return
+	}
+	var req SetCommissionReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+		return
+	}
+	a := &ledger.Agreement{DispatcherID: dispatcherID, DriverID: driverID, Type: strings.ToUpper(strings.TrimSpace(req.Type))}
+	switch a.Type {
+	case ledger.CommissionPercent:
+		a.Percent = req.Percent
+	case ledger.CommissionFixed:
+		a.FixedAmount = req.Amount
+		if req.Currency != nil {
+			cur := strings.ToUpper(strings.TrimSpace(*req.Currency))
+			if !reference.IsAllowed(cur, reference.AllowedCurrencies()) {
+				resp.ErrorLang(c, http.StatusBadRequest, "invalid_currency")
+				return
+			}
+			a.FixedCurrency = &cur
+		}
+	}
+	if !a.Validate() {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_commission")
+		return
+	}
+	ctx := c.Request.Context()
+	drv, err := h.drivers.FindByID(ctx, driverID)
+	if err != nil || drv == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "driver_not_found")
+		return
+	}
+	if drv.FreelancerID == nil || *drv.FreelancerID != dispatcherID.String() {
+		resp.ErrorLang(c, http.StatusForbidden, "driver_must_accept_invitation")
+		return
+	}
+	saved, err := h.repo.UpsertAgreement(ctx, a)
+	if err != nil {
+		h.logger.Error("commission agreement upsert", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	resp.OKLang(c, "updated", toAgreementResp(saved))
+}
+
+// DeleteCommission — убрать комиссию с водителя; проведённые рейсы не меняются.
+// DELETE /v1/dispatchers/drivers/:driverId/commission
+func (h *LedgerHandler) DeleteCommission(c *gin.Context) {
+	dispatcherID := c.MustGet(mw.CtxDispatcherID).(uuid.UUID)
+	driverID, err := uuid.Parse(c.Param("driverId"))
+	if err != nil || driverID == uuid.Nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_driver_id")
+		return
+	}
+	deleted, err := h.repo.DeleteAgreement(c.Request.Context(), dispatcherID, driverID)
+	if err != nil {
+		h.logger.Error("commission agreement delete", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if !deleted {
+		resp.ErrorLang(c, http.StatusNotFound, "commission_not_found")
+		return
+	}
+	resp.OKLang(c, "ok", gin.H{"status": "deleted"})
+}
+
+// MyCommission — комиссия текущего диспетчера водителя (agreement = null — диспетчера или комиссии нет).
+// GET /v1/driver/commission
+func (h *LedgerHandler) MyCommission(c *gin.Context) {
+	driverID := c.MustGet(mw.CtxDriverID).(uuid.UUID)
+	ctx := c.Request.Context()
+	drv, err := h.drivers.FindByID(ctx, driverID)
+	if err != nil || drv == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "driver_not_found")
+		return
+	}
+	var a *ledger.Agreement
+	if drv.FreelancerID != nil {
+		if dispatcherID, err := uuid.Parse(*drv.FreelancerID); err == nil {
+			if a, err = h.repo.GetAgreement(ctx, dispatcherID, driverID); err != nil {
+				h.logger.Error("commission agreement get", zap.Error(err))
+				resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+				return
+			}
+		}
+	}
+	resp.OKLang(c, "ok", gin.H{"agreement": toAgreementResp(a)})
+}
+
+// DriverStatement — выписка водителя за период: начислено по рейсам (gross), комиссия диспетчера, к получению (net).
+// GET /v1/driver/earnings/statement?from=YYYY-MM-DD&to=YYYY-MM-DD
+func (h *LedgerHandler) DriverStatement(c *gin.Context) {
+	driverID := c.MustGet(mw.CtxDriverID).(uuid.UUID)
+	h.statement(c, ledger.AccountDriverEarnings, ledger.OwnerDriver, driverID)
+}
+
+// DispatcherStatement — выписка диспетчера за период: комиссия по рейсам привязанных водителей.
+// GET /v1/dispatchers/earnings/statement?from=YYYY-MM-DD&to=YYYY-MM-DD
+func (h *LedgerHandler) DispatcherStatement(c *gin.Context) {
+	dispatcherID := c.MustGet(mw.CtxDispatcherID).(uuid.UUID)
+	h.statement(c, ledger.AccountDispatcherCommission, ledger.OwnerDispatcher, dispatcherID)
+}
+
+// statement — итоги по валютам и строки по рейсам за [from, to]; по умолчанию — с начала текущего месяца.
+func (h *LedgerHandler) statement(c *gin.Context, account, ownerType string, ownerID uuid.UUID) {
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	for key, dst := range map[string]*time.Time{"from": &from, "to": &to} {
+		if v := strings.TrimSpace(c.Query(key)); v != "" {
+			d, err := time.Parse("2006-01-02", v)
+			if err != nil {
+				resp.ErrorLang(c, http.StatusBadRequest, "invalid_date")
+				return
+			}
+			*dst = d
+		}
+	}
+	if to.Before(from) {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_date_range")
+		return
+	}
+	ctx := c.Request.Context()
+	end := to.AddDate(0, 0, 1)
+	opening, err := h.repo.Balances(ctx, account, ownerType, ownerID, from)
+	if err != nil {
+		h.logger.Error("ledger balances", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	entries, err := h.repo.Entries(ctx, account, ownerType, ownerID, from, end)
+	if err != nil {
+		h.logger.Error("ledger entries", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	totals := make([]gin.H, 0)
+	for _, l := range ledger.Statement(opening, entries) {
+		totals = append(totals, gin.H{
+			"currency": l.Currency, "opening": l.Opening, "credits": l.Credits, "debits": l.Debits, "closing": l.Closing, "trips": l.Trips,
+		})
+	}
+	resp.OKLang(c, "ok", gin.H{
+		"from":   from.Format("2006-01-02"),
+		"to":     to.Format("2006-01-02"),
+		"totals": totals,
+		"trips":  toStatementTrips(account, entries),
+	})
+}
+
+// toStatementTrips — строки выписки по рейсам: для водителя gross/commission/net, для диспетчера commission.
+func toStatementTrips(account string, entries []ledger.Entry) []gin.H {
+	type line struct {
+		currency              string
+		credit, debit, signed float64
+		postedAt              time.Time
+	}
+	byTrip := map[uuid.UUID]*line{}
+	var order []uuid.UUID
+	for _, e := range entries {
+		l := byTrip[e.TripID]
+		if l == nil {
+			l = &line{currency: e.Currency, postedAt: e.PostedAt}
+			byTrip[e.TripID] = l
+			order = append(order, e.TripID)
+		}
+		if e.Side == ledger.SideDebit {
+			l.debit += e.Amount
+		} else {
+			l.credit += e.Amount
+		}
+		l.signed += e.Signed()
+	}
+	sort.SliceStable(order, func(i, j int) bool { return byTrip[order[i]].postedAt.After(byTrip[order[j]].postedAt) })
+	out := make([]gin.H, 0, len(order))
+	for _, id := range order {
+		l := byTrip[id]
+		item := gin.H{"trip_id": id.String(), "currency": l.currency, "posted_at": l.postedAt}
+		if account == ledger.AccountDriverEarnings {
+			item["gross"], item["commission"], item["net"] = math.Round(l.credit*100)/100, math.Round(l.debit*100)/100, math.Round(l.signed*100)/100
+		} else {
+			item["commission"] = math.Round(l.signed*100) / 100
+		}
+		out = append(out, item)
+	}
+	return out
+}
+
+func toAgreementResp(a *ledger.Agreement) gin.H {
+	if a == nil {
+		return nil
+	}
+	return gin.H{
+		"id":            a.ID.String(),
+		"dispatcher_id": a.DispatcherID.String(),
+		"driver_id":     a.DriverID.String(),
+		"type":          a.Type,
+		"percent":       a.Percent,
+		"amount":        a.FixedAmount,
+		"currency":      a.FixedCurrency,
+		"created_at":    a.CreatedAt,
+		"updated_at":    a.UpdatedAt,
+	}
+}
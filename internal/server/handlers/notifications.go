@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"sarbonNew/internal/notifications"
+	"sarbonNew/internal/server/mw"
+	"sarbonNew/internal/server/resp"
+)
+
+type NotificationsHandler struct {
+	logger *zap.Logger
+	repo   *notifications.Repo
+}
+
+func NewNotificationsHandler(logger *zap.Logger, repo *notifications.Repo) *NotificationsHandler {
+	return &NotificationsHandler{logger: logger, repo: repo}
+}
+
+// ListDriver GET /v1/driver/notifications. Query: unread (true/1), limit, offset.
+func (h *NotificationsHandler) ListDriver(c *gin.Context) {
+	h.list(c, notifications.RecipientDriver, c.MustGet(mw.CtxDriverID).(uuid.UUID))
+}
+
+// ListDispatcher GET /v1/dispatchers/notifications.
+func (h *NotificationsHandler) ListDispatcher(c *gin.Context) {
+	h.list(c, notifications.RecipientDispatcher, c.MustGet(mw.CtxDispatcherID).(uuid.UUID))
+}
+
+// ListCompany GET /v1/notifications — уведомления текущей компании пользователя.
+func (h *NotificationsHandler) ListCompany(c *gin.Context) {
+	companyID, ok := appUserCompanyID(c)
+	if !ok {
+		resp.ErrorLang(c, http.StatusForbidden, "company_not_selected")
+		return
+	}
+	h.list(c, notifications.RecipientCompany, companyID)
+}
+
+// MarkReadDriver POST /v1/driver/notifications/:id/read (:id = "all" — все).
+func (h *NotificationsHandler) MarkReadDriver(c *gin.Context) {
+	h.markRead(c, notifications.RecipientDriver, c.MustGet(mw.CtxDriverID).(uuid.UUID))
+}
+
+// MarkReadDispatcher POST /v1/dispatchers/notifications/:id/read.
+func (h *NotificationsHandler) MarkReadDispatcher(c *gin.Context) {
+	h.markRead(c, notifications.RecipientDispatcher, c.MustGet(mw.CtxDispatcherID).(uuid.UUID))
+}
+
+// MarkReadCompany POST /v1/notifications/:id/read.
+func (h *NotificationsHandler) MarkReadCompany(c *gin.Context) {
+	companyID, ok := appUserCompanyID(c)
+	if !ok {
+		resp.ErrorLang(c, http.StatusForbidden, "company_not_selected")
+		return
+	}
+	h.markRead(c, notifications.RecipientCompany, companyID)
+}
+
+func (h *NotificationsHandler) list(c *gin.Context, recipientType string, recipientID uuid.UUID) {
+	unreadOnly := c.Query("unread") == "true" || c.Query("unread") == "1"
+	list, unread, err := h.repo.List(c.Request.Context(), recipientType, recipientID, unreadOnly, getIntQuery(c, "limit", 20), getIntQuery(c, "offset", 0))
+	if err != nil {
+		h.logger.Error("notifications list", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	items := make([]gin.H, 0, len(list))
+	for _, n := range list {
+		items = append(items, gin.H{
+			"id": n.ID.String(), "kind": n.Kind, "payload": n.Payload, "read_at": n.ReadAt, "created_at": n.CreatedAt,
+		})
+	}
+	resp.OKLang(c, "ok", gin.H{"items": items, "unread": unread})
+}
+
+func (h *NotificationsHandler) markRead(c *gin.Context, recipientType string, recipientID uuid.UUID) {
+	ctx := c.Request.Context()
+	if c.Param("id") == "all" {
+		if err := h.repo.MarkAllRead(ctx, recipientType, recipientID); err != nil {
+			h.logger.Error("notifications mark all read", zap.Error(err))
+			resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		resp.OKLang(c, "ok", gin.H{})
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return
+	}
+	ok, err := h.repo.MarkRead(ctx, id, recipientType, recipientID)
+	if err != nil {
+		h.logger.Error("notifications mark read", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if !ok {
+		resp.ErrorLang(c, http.StatusNotFound, "notification_not_found")
+		return
+	}
+	resp.OKLang(c, "ok", gin.H{"id": id.String()})
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"sarbonNew/internal/cargo"
+	"sarbonNew/internal/reference"
+	"sarbonNew/internal/server/mw"
+	"sarbonNew/internal/server/resp"
+)
+
+// CounterOfferReq body for POST .../offers/:id/counter.
+type CounterOfferReq struct {
+	Price    float64 `json:"price" binding:"required,gt=0"`
+	Currency string  `json:"currency"` // по умолчанию — валюта текущего предложения
+	Comment  string  `json:"comment"`
+}
+
+// DriverCounter POST /v1/driver/offers/:id/counter — водитель отвечает встречной ценой на предложение заказчика.
+func (h *CargoHandler) DriverCounter(c *gin.Context) {
+	driverID := c.MustGet(mw.CtxDriverID).(uuid.UUID)
+	h.counter(c, "DRIVER", driverID, func(o *cargo.Offer, _ *cargo.Cargo) bool {
+		return o.CarrierID == driverID
+	})
+}
+
+// DispatcherCounter POST /v1/dispatchers/offers/:id/counter — диспетчер (создатель груза) предлагает свою цену.
+func (h *CargoHandler) DispatcherCounter(c *gin.Context) {
+	dispatcherID := c.MustGet(mw.CtxDispatcherID).(uuid.UUID)
+	h.counter(c, "DISPATCHER", dispatcherID, func(_ *cargo.Offer, obj *cargo.Cargo) bool {
+		return obj.CreatedByType != nil && *obj.CreatedByType == "DISPATCHER" && obj.CreatedByID != nil && *obj.CreatedByID == dispatcherID
+	})
+}
+
+// CompanyCounter POST /v1/offers/:id/counter — пользователь компании-владельца груза предлагает свою цену.
+func (h *CargoHandler) CompanyCounter(c *gin.Context) {
+	userID := c.MustGet(mw.CtxAppUserID).(uuid.UUID)
+	companyID, ok := appUserCompanyID(c)
+	if !ok {
+		resp.ErrorLang(c, http.StatusForbidden, "company_not_selected")
+		return
+	}
+	h.counter(c, "COMPANY", userID, func(_ *cargo.Offer, obj *cargo.Cargo) bool {
+		return obj.CompanyID != nil && *obj.CompanyID == companyID
+	})
+}
+
+// DriverAcceptOffer POST /v1/driver/offers/:id/accept — водитель принимает встречную цену заказчика; создаётся рейс.
+func (h *CargoHandler) DriverAcceptOffer(c *gin.Context) {
+	driverID := c.MustGet(mw.CtxDriverID).(uuid.UUID)
+	offerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return
+	}
+	offer, err := h.repo.GetOfferByID(c.Request.Context(), offerID)
+	if err != nil || offer == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "offer_not_found")
+		return
+	}
+	if offer.CarrierID != driverID {
+		resp.ErrorLang(c, http.StatusForbidden, "not_your_offer")
+		return
+	}
+	h.acceptOffer(c, offerID, cargo.SideDriver)
+}
+
+// OfferRounds GET /api/offers/:id/rounds — история торга по офферу (от исходного предложения к последнему).
+func (h *CargoHandler) OfferRounds(c *gin.Context) {
+	offerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return
+	}
+	ctx := c.Request.Context()
+	offer, err := h.repo.GetOfferByID(ctx, offerID)
+	if err != nil || offer == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "offer_not_found")
+		return
+	}
+	rounds, err := h.repo.GetOfferRounds(ctx, offerID)
+	if err != nil {
+		h.logger.Error("offer rounds", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	items := make([]gin.H, 0, len(rounds))
+	for _, r := range rounds {
+		items = append(items, toOfferRoundResp(r))
+	}
+	out := toOfferResp(offer)
+	if a, _ := h.repo.GetAuction(ctx, offer.CargoID); a.IsOpen() && a.Sealed {
+		// закрытые ставки: цены не раскрываются до окончания аукциона
+		out["price"], out["sealed"] = nil, true
+		for i := range items {
+			items[i]["price"], items[i]["sealed"] = nil, true
+		}
+	}
+	resp.OKLang(c, "ok", gin.H{"offer": out, "rounds": items})
+}
+
+// counter проверяет доступ (allowed) и добавляет встречное предложение от authorType.
+func (h *CargoHandler) counter(c *gin.Context, authorType string, authorID uuid.UUID, allowed func(*cargo.Offer, *cargo.Cargo) bool) {
+	offerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return
+	}
+	var req CounterOfferReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+		return
+	}
+	ctx := c.Request.Context()
+	offer, err := h.repo.GetOfferByID(ctx, offerID)
+	if err != nil || offer == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "offer_not_found")
+		return
+	}
+	obj, _ := h.repo.GetByID(ctx, offer.CargoID, false)
+	if obj == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "cargo_not_found")
+		return
+	}
+	if !allowed(offer, obj) {
+		if authorType == "DRIVER" {
+			resp.ErrorLang(c, http.StatusForbidden, "not_your_offer")
+		} else {
+			resp.ErrorLang(c, http.StatusForbidden, "not_your_cargo")
+		}
+		return
+	}
+	if !cargo.IsSearching(obj.Status) {
+		resp.ErrorLang(c, http.StatusBadRequest, "cargo_not_searching")
+		return
+	}
+	if h.openAuction(c, obj.ID) {
+		resp.ErrorLang(c, http.StatusConflict, "auction_in_progress")
+		return
+	}
+	currency := strings.ToUpper(strings.TrimSpace(req.Currency))
+	if currency == "" {
+		currency = offer.Currency
+	}
+	if !reference.IsAllowed(currency, reference.AllowedCurrencies()) {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_currency")
+		return
+	}
+	round, err := h.repo.CounterOffer(ctx, offerID, cargo.CounterOfferInput{
+		AuthorType: authorType, AuthorID: &authorID, Price: req.Price, Currency: currency, Comment: strings.TrimSpace(req.Comment),
+	})
+	switch {
+	case errors.Is(err, cargo.ErrOfferNotPending):
+		resp.ErrorLang(c, http.StatusBadRequest, "offer_not_found_or_not_pending")
+		return
+	case errors.Is(err, cargo.ErrOfferNotYourTurn):
+		resp.ErrorLang(c, http.StatusConflict, "offer_not_your_turn")
+		return
+	case errors.Is(err, cargo.ErrOfferNotNegotiable):
+		resp.ErrorLang(c, http.StatusBadRequest, "offer_not_negotiable")
+		return
+	case errors.Is(err, cargo.ErrOfferRoundsExceeded):
+		resp.ErrorLang(c, http.StatusConflict, "offer_rounds_exceeded")
+		return
+	case err != nil:
+		h.logger.Error("counter offer", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	resp.SuccessLang(c, http.StatusCreated, "created", toOfferRoundResp(*round))
+}
+
+func toOfferRoundResp(r cargo.OfferRound) gin.H {
+	out := gin.H{
+		"id": r.ID.String(), "offer_id": r.OfferID.String(), "round_no": r.RoundNo, "side": r.Side(),
+		"author_type": r.AuthorType, "price": r.Price, "currency": r.Currency, "comment": r.Comment, "created_at": r.CreatedAt,
+	}
+	if r.AuthorID != nil {
+		out["author_id"] = r.AuthorID.String()
+	}
+	return out
+}
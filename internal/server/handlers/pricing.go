@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"sarbonNew/internal/cargo"
+	"sarbonNew/internal/pricing"
+	"sarbonNew/internal/reference"
+	"sarbonNew/internal/routing"
+	"sarbonNew/internal/server/resp"
+)
+
+// defaultSuggestionCurrency — валюта рекомендации, если она не передана и у груза нет оплаты.
+const defaultSuggestionCurrency = "USD"
+
+type PricingHandler struct {
+	logger    *zap.Logger
+	repo      *pricing.Repo
+	cargoRepo *cargo.Repo
+	routes    *routing.Estimator
+}
+
+func NewPricingHandler(logger *zap.Logger, repo *pricing.Repo, cargoRepo *cargo.Repo, routes *routing.Estimator) *PricingHandler {
+	return &PricingHandler{logger: logger, repo: repo, cargoRepo: cargoRepo, routes: routes}
+}
+
+// PriceSuggestionPointReq — точка маршрута для предпросмотра цены (как в POST /api/cargo, без адреса).
+// Координаты — указатели: нулевая широта/долгота (экватор, Гринвич) допустима.
+type PriceSuggestionPointReq struct {
+	Type         string   `json:"type" binding:"required,oneof=LOAD UNLOAD CUSTOMS TRANSIT"`
+	CityCode     string   `json:"city_code"`
+	RegionCode   string   `json:"region_code"`
+	Lat          *float64 `json:"lat" binding:"required,gte=-90,lte=90"`
+	Lng          *float64 `json:"lng" binding:"required,gte=-180,lte=180"`
+	PointOrder   int      `json:"point_order"`
+	IsMainLoad   bool     `json:"is_main_load"`
+	IsMainUnload bool     `json:"is_main_unload"`
+}
+
+// PriceSuggestionReq body for POST /api/cargo/price-suggestion. Принимает и черновик тела POST /api/cargo как есть:
+// лишние поля игнорируются, валюта по умолчанию — payment.total_currency.
+type PriceSuggestionReq struct {
+	TruckType   string                    `json:"truck_type" binding:"required"`
+	Weight      float64                   `json:"weight" binding:"required,gt=0"`
+	Currency    string                    `json:"currency"`
+	Payment     *PaymentReq               `json:"payment"`
+	RoutePoints []PriceSuggestionPointReq `json:"route_points" binding:"required,min=2,dive"`
+}
+
+// Preview POST /api/cargo/price-suggestion — рекомендуемая цена до создания груза.
+func (h *PricingHandler) Preview(c *gin.Context) {
+	var req PriceSuggestionReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+		return
+	}
+	if !reference.IsAllowed(req.TruckType, reference.AllowedTruckTypes()) {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+		return
+	}
+	currency := strings.ToUpper(strings.TrimSpace(req.Currency))
+	if currency == "" && req.Payment != nil && req.Payment.TotalCurrency != nil {
+		currency = strings.ToUpper(strings.TrimSpace(*req.Payment.TotalCurrency))
+	}
+	if currency == "" {
+		currency = defaultSuggestionCurrency
+	}
+	if !reference.IsAllowed(currency, reference.AllowedCurrencies()) {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_currency")
+		return
+	}
+	points := make([]cargo.RoutePoint, 0, len(req.RoutePoints))
+	for _, rp := range req.RoutePoints {
+		points = append(points, cargo.RoutePoint{
+			Type: strings.ToUpper(rp.Type), CityCode: strings.ToUpper(strings.TrimSpace(rp.CityCode)),
+			RegionCode: strings.TrimSpace(rp.RegionCode), Lat: *rp.Lat, Lng: *rp.Lng,
+			PointOrder: rp.PointOrder, IsMainLoad: rp.IsMainLoad, IsMainUnload: rp.IsMainUnload,
+		})
+	}
+	sort.SliceStable(points, func(i, j int) bool { return points[i].PointOrder < points[j].PointOrder })
+	est := h.routes.Estimate(req.TruckType, toRoutingPoints(points))
+	h.respond(c, points, strings.ToUpper(req.TruckType), req.Weight, currency, est.DistanceKm)
+}
+
+// ForCargo GET /api/cargo/:id/price-suggestion — рекомендуемая цена для экрана оффера. Query: currency (по умолчанию валюта оплаты груза).
+func (h *PricingHandler) ForCargo(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return
+	}
+	ctx := c.Request.Context()
+	obj, err := h.cargoRepo.GetByID(ctx, id, false)
+	if err != nil {
+		resp.ErrorLang(c, http.StatusInternalServerError, "failed_to_get_cargo")
+		return
+	}
+	if obj == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "cargo_not_found")
+		return
+	}
+	points, err := h.cargoRepo.GetRoutePoints(ctx, id)
+	if err != nil {
+		resp.ErrorLang(c, http.StatusInternalServerError, "failed_to_get_cargo")
+		return
+	}
+	currency := strings.ToUpper(strings.TrimSpace(c.Query("currency")))
+	if currency == "" {
+		if pay, _ := h.cargoRepo.GetPayment(ctx, id); pay != nil && pay.TotalCurrency != nil && *pay.TotalCurrency != "" {
+			currency = strings.ToUpper(*pay.TotalCurrency)
+		} else {
+			currency = defaultSuggestionCurrency
+		}
+	}
+	if !reference.IsAllowed(currency, reference.AllowedCurrencies()) {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_currency")
+		return
+	}
+	var distance float64
+	if obj.DistanceKm != nil {
+		distance = *obj.DistanceKm
+	} else {
+		distance = h.routes.Estimate(obj.TruckType, toRoutingPoints(points)).DistanceKm
+	}
+	h.respond(c, points, obj.TruckType, obj.Weight, currency, distance)
+}
+
+func (h *PricingHandler) respond(c *gin.Context, points []cargo.RoutePoint, truckType string, weight float64, currency string, distanceKm float64) {
+	out, err := suggestPrice(c.Request.Context(), h.repo, points, truckType, weight, currency, distanceKm)
+	if err != nil {
+		h.logger.Error("price suggestion", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	resp.OKLang(c, "ok", out)
+}
+
+// suggestPrice — ответ рекомендации цены по точкам груза (отсортированным по point_order); общий для предпросмотра,
+// экрана оффера и ответа POST /api/cargo.
+func suggestPrice(ctx context.Context, repo *pricing.Repo, points []cargo.RoutePoint, truckType string, weight float64, currency string, distanceKm float64) (gin.H, error) {
+	origin, dest := laneOf(points)
+	s, err := repo.Suggest(ctx, pricing.Query{
+		OriginRegion: origin, DestRegion: dest, TruckType: truckType, WeightTons: weight,
+		Currency: currency, DistanceKm: distanceKm, At: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := gin.H{
+		"available":   s != nil,
+		"currency":    currency,
+		"distance_km": distanceKm,
+		"origin":      origin,
+		"destination": dest,
+	}
+	if s != nil {
+		out["min"] = s.Min
+		out["median"] = s.Median
+		out["max"] = s.Max
+		out["per_km"] = gin.H{"min": s.PerKmMin, "median": s.PerKmMedian, "max": s.PerKmMax}
+		out["samples"] = s.Samples
+		out["level"] = s.Level
+	}
+	return out, nil
+}
+
+// laneOf — направление груза: регион (или город) основной погрузки и основной выгрузки.
+// Точки должны быть отсортированы по point_order.
+func laneOf(points []cargo.RoutePoint) (origin, dest string) {
+	key := func(rp cargo.RoutePoint) string {
+		if rp.RegionCode != "" {
+			return rp.RegionCode
+		}
+		return rp.CityCode
+	}
+	for _, rp := range points {
+		if rp.IsMainLoad {
+			origin = key(rp)
+			break
+		}
+		if origin == "" && rp.Type == "LOAD" {
+			origin = key(rp)
+		}
+	}
+	for i := len(points) - 1; i >= 0; i-- {
+		rp := points[i]
+		if rp.IsMainUnload {
+			dest = key(rp)
+			break
+		}
+		if dest == "" && rp.Type == "UNLOAD" {
+			dest = key(rp)
+		}
+	}
+	return origin, dest
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+func TestPriceSuggestionReqCoordinates(t *testing.T) {
+	bind := func(body string) error {
+		var req PriceSuggestionReq
+		if err := json.Unmarshal([]byte(body), &req); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		return binding.Validator.ValidateStruct(&req)
+	}
+	// экватор и Гринвич — допустимые координаты
+	if err := bind(`{"truck_type":"TENT","weight":10,"route_points":[
+		{"type":"LOAD","lat":0,"lng":0},{"type":"UNLOAD","lat":5.6,"lng":-0.2}]}`); err != nil {
+		t.Errorf("zero coordinates rejected: %v", err)
+	}
+	if err := bind(`{"truck_type":"TENT","weight":10,"route_points":[{"type":"LOAD","lng":0},{"type":"UNLOAD","lat":1,"lng":1}]}`); err == nil {
+		t.Error("missing lat accepted")
+	}
+	if err := bind(`{"truck_type":"TENT","weight":10,"route_points":[{"type":"LOAD","lat":91,"lng":0},{"type":"UNLOAD","lat":1,"lng":1}]}`); err == nil {
+		t.Error("lat out of range accepted")
+	}
+}
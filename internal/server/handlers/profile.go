@@ -144,6 +144,39 @@ func (h *ProfileHandler) Heartbeat(c *gin.Context) {
 	resp.OKLang(c, "heartbeat", gin.H{"event": "heartbeat", "driver": d})
 }
 
+// GET /v1/driver/profile/display-currency
+func (h *ProfileHandler) GetDisplayCurrency(c *gin.Context) {
+	driverID := c.MustGet(mw.CtxDriverID).(uuid.UUID)
+	cur, err := h.drivers.DisplayCurrency(c.Request.Context(), driverID)
+	if err != nil {
+		h.logger.Error("driver display currency", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	resp.OKLang(c, "ok", displayCurrencyBody(cur))
+}
+
+// PUT /v1/driver/profile/display-currency
+func (h *ProfileHandler) PutDisplayCurrency(c *gin.Context) {
+	driverID := c.MustGet(mw.CtxDriverID).(uuid.UUID)
+	var req displayCurrencyReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload")
+		return
+	}
+	cur, ok := normalizeDisplayCurrency(*req.DisplayCurrency)
+	if !ok {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_currency")
+		return
+	}
+	if err := h.drivers.SetDisplayCurrency(c.Request.Context(), driverID, cur); err != nil {
+		h.logger.Error("set driver display currency", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	resp.OKLang(c, "updated", displayCurrencyBody(cur))
+}
+
 type phoneChangeRequestReq struct {
 	NewPhone string `json:"new_phone" binding:"required"`
 }
@@ -291,6 +324,9 @@ type patchTrailerReq struct {
 	TrailerOwnerID     *string `json:"trailer_owner_id,omitempty"`
 	TrailerOwnerName   *string `json:"trailer_owner_name,omitempty"`
 	TrailerScanStatus  *bool   `json:"trailer_scan_status,omitempty"`
+	// TrailerCapacityWeight (т) и TrailerCapacityVolume (м³) — вместимость для консолидации грузов.
+	TrailerCapacityWeight *float64 `json:"trailer_capacity_weight,omitempty" binding:"omitempty,gt=0"`
+	TrailerCapacityVolume *float64 `json:"trailer_capacity_volume,omitempty" binding:"omitempty,gt=0"`
 }
 
 // PATCH /v1/driver/profile/trailer
@@ -326,6 +362,8 @@ func (h *ProfileHandler) PatchTrailer(c *gin.Context) {
 		TrailerOwnerID:     req.TrailerOwnerID,
 		TrailerOwnerName:   req.TrailerOwnerName,
 		TrailerScanStatus:  req.TrailerScanStatus,
+		CapacityWeight:     req.TrailerCapacityWeight,
+		CapacityVolume:     req.TrailerCapacityVolume,
 	}); err != nil {
 		h.logger.Error("update trailer profile failed", zap.Error(err))
 		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
@@ -36,6 +36,12 @@ type ReferenceCargoResponse struct {
 	PrepaymentType  []ItemWithLabel               `json:"prepayment_type"`
 	RemainingType   []ItemWithLabel               `json:"remaining_type"`
 	LoadingType     []ItemWithLabel               `json:"loading_type"`
+	ReviewTag       []ItemWithLabel               `json:"review_tag"`
+	ExpenseCategory []ItemWithLabel               `json:"expense_category"`
+	CustomsStatus   []ItemWithLabel               `json:"customs_status"`
+	TripCancelReason []ItemWithLabel              `json:"trip_cancel_reason"`
+	IncidentType     []ItemWithLabel              `json:"incident_type"`
+	IncidentSeverity []ItemWithLabel              `json:"incident_severity"`
 }
 
 // ReferenceCompanyResponse — справочник для раздела Company. Все value в верхнем регистре.
@@ -186,6 +192,8 @@ func GetReferenceCargo(c *gin.Context) {
 			{Value: "PENDING", Label: reference.RefLabel("cargo.offer_status", "PENDING", lang)},
 			{Value: "ACCEPTED", Label: reference.RefLabel("cargo.offer_status", "ACCEPTED", lang)},
 			{Value: "REJECTED", Label: reference.RefLabel("cargo.offer_status", "REJECTED", lang)},
+			{Value: "EXPIRED", Label: reference.RefLabel("cargo.offer_status", "EXPIRED", lang)},
+			{Value: "WITHDRAWN", Label: reference.RefLabel("cargo.offer_status", "WITHDRAWN", lang)},
 		},
 		CreatedByType: []ItemWithLabel{
 			{Value: "ADMIN", Label: reference.RefLabel("cargo.created_by_type", "ADMIN", lang)},
@@ -213,6 +221,12 @@ func GetReferenceCargo(c *gin.Context) {
 		PrepaymentType: refItemsToItemWithLabelLocalized(reference.PrepaymentTypeRefs, "cargo.prepayment_type", lang),
 		RemainingType:  refItemsToItemWithLabelLocalized(reference.RemainingTypeRefs, "cargo.remaining_type", lang),
 		LoadingType:    refItemsToItemWithLabelLocalized(reference.LoadingTypeRefs, "cargo.loading_type", lang),
+		ReviewTag:      refItemsToItemWithLabelLocalized(reference.ReviewTagRefs, "cargo.review_tag", lang),
+		ExpenseCategory: refItemsToItemWithLabelLocalized(reference.ExpenseCategoryRefs, "cargo.expense_category", lang),
+		CustomsStatus:   refItemsToItemWithLabelLocalized(reference.CustomsStatusRefs, "cargo.customs_status", lang),
+		TripCancelReason: refItemsToItemWithLabelLocalized(reference.TripCancelReasonRefs, "cargo.trip_cancel_reason", lang),
+		IncidentType:     refItemsToItemWithLabelLocalized(reference.IncidentTypeRefs, "cargo.incident_type", lang),
+		IncidentSeverity: refItemsToItemWithLabelLocalized(reference.IncidentSeverityRefs, "cargo.incident_severity", lang),
 	}
 	resp.OKLang(c, "ok", out)
 }
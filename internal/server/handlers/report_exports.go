@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"sarbonNew/internal/exports"
+	"sarbonNew/internal/server/resp"
+	"sarbonNew/internal/tabular"
+)
+
+// ReportExportsHandler — выгрузка отчётов (грузы, рейсы, офферы) в CSV/XLSX для админов.
+type ReportExportsHandler struct {
+	logger *zap.Logger
+	repo   *exports.Repo
+	gen    *exports.Generator
+}
+
+// NewReportExportsHandler creates the handler.
+func NewReportExportsHandler(logger *zap.Logger, repo *exports.Repo, gen *exports.Generator) *ReportExportsHandler {
+	return &ReportExportsHandler{logger: logger, repo: repo, gen: gen}
+}
+
+var errBadExportParam = errors.New("invalid export parameter")
+
+// exportParamsFromQuery читает фильтры отчёта из query (имена как в GET /api/cargo); язык — из X-Language.
+func exportParamsFromQuery(c *gin.Context, kind string) (exports.Params, error) {
+	p := exports.Params{
+		Lang:        resp.Lang(c),
+		CreatedFrom: strings.TrimSpace(c.Query("created_from")),
+		CreatedTo:   strings.TrimSpace(c.Query("created_to")),
+	}
+	for _, d := range []string{p.CreatedFrom, p.CreatedTo} {
+		if d != "" {
+			if _, err := time.Parse("2006-01-02", d); err != nil {
+				return p, errBadExportParam
+			}
+		}
+	}
+	if v := strings.TrimSpace(c.Query("status")); v != "" {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.ToUpper(strings.TrimSpace(s)); s != "" {
+				p.Status = append(p.Status, s)
+			}
+		}
+	}
+	switch kind {
+	case exports.KindCargo:
+		p.TruckType = strings.ToUpper(strings.TrimSpace(c.Query("truck_type")))
+		p.Sort = c.DefaultQuery("sort", "created_at:desc")
+		for key, dst := range map[string]**float64{"weight_min": &p.WeightMin, "weight_max": &p.WeightMax} {
+			if v := c.Query(key); v != "" {
+				n, err := strconv.ParseFloat(v, 64)
+				if err != nil {
+					return p, errBadExportParam
+				}
+				*dst = &n
+			}
+		}
+		if v := c.Query("with_offers"); v != "" {
+			b := strings.ToLower(v) == "true" || v == "1"
+			p.WithOffers = &b
+		}
+	case exports.KindTrips:
+		if v := c.Query("driver_id"); v != "" {
+			id, err := uuid.Parse(v)
+			if err != nil {
+				return p, errBadExportParam
+			}
+			p.DriverID = &id
+		}
+	case exports.KindOffers:
+		if v := c.Query("cargo_id"); v != "" {
+			id, err := uuid.Parse(v)
+			if err != nil {
+				return p, errBadExportParam
+			}
+			p.CargoID = &id
+		}
+	}
+	return p, nil
+}
+
+// Export отдаёт отчёт файлом (до exports.MaxSyncRows строк — сразу потоком) или ставит его в фоновую генерацию.
+// GET /v1/admin/reports/:kind?format=csv|xlsx&async=true&status=...&created_from=...&created_to=...
+// kind: cargo, trips, offers.
+func (h *ReportExportsHandler) Export(c *gin.Context) {
+	kind := strings.ToUpper(c.Param("kind"))
+	if !exports.IsKind(kind) {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_export_kind")
+		return
+	}
+	format := strings.ToLower(c.DefaultQuery("format", tabular.FormatCSV))
+	if format != tabular.FormatCSV && format != tabular.FormatXLSX {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_export_format")
+		return
+	}
+	p, err := exportParamsFromQuery(c, kind)
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_export_params")
+		return
+	}
+	ctx := c.Request.Context()
+	total, err := h.gen.Count(ctx, kind, p)
+	if err != nil {
+		h.logger.Error("report export count", zap.Error(err), zap.String("kind", kind))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if total > exports.MaxRows {
+		resp.ErrorWithData(c, http.StatusBadRequest, resp.Msg("export_too_large", resp.Lang(c)), gin.H{"rows": total, "max_rows": exports.MaxRows})
+		return
+	}
+	async := strings.ToLower(c.Query("async")) == "true" || c.Query("async") == "1"
+	if async || total > exports.MaxSyncRows {
+		adminID := adminIDFromCtx(c)
+		if adminID == nil {
+			resp.ErrorLang(c, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		e, err := h.repo.Create(ctx, *adminID, kind, format, p)
+		if err != nil {
+			h.logger.Error("report export create", zap.Error(err))
+			resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+			return
+		}
+		out := toReportExportResp(e)
+		out["rows_estimate"] = total
+		resp.SuccessLang(c, http.StatusAccepted, "export_queued", out)
+		return
+	}
+
+	fileName := exports.FileName(kind, format, time.Now())
+	c.Header("Content-Type", tabular.ContentType(format))
+	c.Header("Content-Disposition", `attachment; filename="`+fileName+`"`)
+	c.Status(http.StatusOK)
+	w, err := tabular.NewWriter(format, c.Writer, exports.Header(kind, p.Lang))
+	if err == nil {
+		_, err = h.gen.Write(ctx, kind, p, w)
+		if cerr := w.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if err != nil {
+		// заголовки уже отправлены — файл будет обрезан, остаётся только записать в лог
+		h.logger.Error("report export stream", zap.Error(err), zap.String("kind", kind))
+	}
+}
+
+// List returns the admin's background exports.
+// GET /v1/admin/report-exports
+func (h *ReportExportsHandler) List(c *gin.Context) {
+	adminID := adminIDFromCtx(c)
+	if adminID == nil {
+		resp.ErrorLang(c, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	list, err := h.repo.ListByAdmin(c.Request.Context(), *adminID, getIntQuery(c, "limit", 20))
+	if err != nil {
+		h.logger.Error("report exports list", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	items := make([]gin.H, 0, len(list))
+	for i := range list {
+		items = append(items, toReportExportResp(&list[i]))
+	}
+	resp.OKLang(c, "ok", gin.H{"items": items})
+}
+
+// Get returns status of a background export.
+// GET /v1/admin/report-exports/:id
+func (h *ReportExportsHandler) Get(c *gin.Context) {
+	e, ok := h.ownExport(c)
+	if !ok {
+		return
+	}
+	resp.OKLang(c, "ok", toReportExportResp(e))
+}
+
+// Download отдаёт готовый файл фоновой выгрузки.
+// GET /v1/admin/report-exports/:id/download
+func (h *ReportExportsHandler) Download(c *gin.Context) {
+	e, ok := h.ownExport(c)
+	if !ok {
+		return
+	}
+	if e.Status != exports.StatusReady {
+		resp.ErrorLang(c, http.StatusConflict, "export_not_ready")
+		return
+	}
+	data, err := h.repo.File(c.Request.Context(), e.ID)
+	if err != nil {
+		h.logger.Error("report export file", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if data == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "export_not_found")
+		return
+	}
+	fileName := exports.FileName(e.Kind, e.Format, e.CreatedAt)
+	if e.FileName != nil {
+		fileName = *e.FileName
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+fileName+`"`)
+	c.Data(http.StatusOK, tabular.ContentType(e.Format), data)
+}
+
+// ownExport загружает выгрузку по :id; чужие выгрузки не видны (404).
+func (h *ReportExportsHandler) ownExport(c *gin.Context) (*exports.Export, bool) {
+	adminID := adminIDFromCtx(c)
+	if adminID == nil {
+		resp.ErrorLang(c, http.StatusUnauthorized, "unauthorized")
+		return nil, false
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return nil, false
+	}
+	e, err := h.repo.Get(c.Request.Context(), id)
+	if err != nil {
+		h.logger.Error("report export get", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return nil, false
+	}
+	if e == nil || e.AdminID != *adminID {
+		resp.ErrorLang(c, http.StatusNotFound, "export_not_found")
+		return nil, false
+	}
+	return e, true
+}
+
+func toReportExportResp(e *exports.Export) gin.H {
+	out := gin.H{
+		"id": e.ID.String(), "kind": e.Kind, "format": e.Format, "status": e.Status, "params": e.Params,
+		"rows_count": e.RowsCount, "file_name": e.FileName, "error": e.Error,
+		"created_at": e.CreatedAt, "started_at": e.StartedAt, "finished_at": e.FinishedAt, "expires_at": e.ExpiresAt,
+	}
+	if e.Status == exports.StatusReady {
+		out["download_url"] = "/v1/admin/report-exports/" + e.ID.String() + "/download"
+	}
+	return out
+}
@@ -0,0 +1,309 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"sarbonNew/internal/cargo"
+	"sarbonNew/internal/reference"
+	"sarbonNew/internal/reviews"
+	"sarbonNew/internal/server/mw"
+	"sarbonNew/internal/server/resp"
+	"sarbonNew/internal/trips"
+)
+
+// ReviewsHandler: post-trip ratings (driver <-> shipper/dispatcher), public review list, admin moderation.
+type ReviewsHandler struct {
+	logger    *zap.Logger
+	repo      *reviews.Repo
+	tripsRepo *trips.Repo
+	cargoRepo *cargo.Repo
+}
+
+func NewReviewsHandler(logger *zap.Logger, repo *reviews.Repo, tripsRepo *trips.Repo, cargoRepo *cargo.Repo) *ReviewsHandler {
+	return &ReviewsHandler{logger: logger, repo: repo, tripsRepo: tripsRepo, cargoRepo: cargoRepo}
+}
+
+// CreateReviewReq body for POST .../trips/:id/review.
+type CreateReviewReq struct {
+	Score   int      `json:"score" binding:"required,min=1,max=5"`
+	Tags    []string `json:"tags"`
+	Comment *string  `json:"comment"`
+}
+
+// CreateByDriver: driver rates the cargo owner (company if cargo has company_id, otherwise dispatcher who created the cargo).
+func (h *ReviewsHandler) CreateByDriver(c *gin.Context) {
+	driverID := c.MustGet(mw.CtxDriverID).(uuid.UUID)
+	t, obj, ok := h.completedTrip(c)
+	if !ok {
+		return
+	}
+	if t.DriverID == nil || *t.DriverID != driverID {
+		resp.ErrorLang(c, http.StatusForbidden, "not_your_trip")
+		return
+	}
+	var targetType string
+	var targetID uuid.UUID
+	switch {
+	case obj.CompanyID != nil:
+		targetType, targetID = reviews.PartyCompany, *obj.CompanyID
+	case obj.CreatedByType != nil && *obj.CreatedByType == "DISPATCHER" && obj.CreatedByID != nil:
+		targetType, targetID = reviews.PartyDispatcher, *obj.CreatedByID
+	default:
+		resp.ErrorLang(c, http.StatusBadRequest, "review_target_not_found")
+		return
+	}
+	h.create(c, t.ID, reviews.PartyDriver, driverID, targetType, targetID)
+}
+
+// CreateByDispatcher: freelance dispatcher who created the cargo rates the driver.
+func (h *ReviewsHandler) CreateByDispatcher(c *gin.Context) {
+	dispatcherID := c.MustGet(mw.CtxDispatcherID).(uuid.UUID)
+	t, obj, ok := h.completedTrip(c)
+	if !ok {
+		return
+	}
+	if obj.CreatedByType == nil || *obj.CreatedByType != "DISPATCHER" || obj.CreatedByID == nil || *obj.CreatedByID != dispatcherID {
+		resp.ErrorLang(c, http.StatusForbidden, "not_your_cargo")
+		return
+	}
+	if t.DriverID == nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "review_target_not_found")
+		return
+	}
+	h.create(c, t.ID, reviews.PartyDispatcher, dispatcherID, reviews.PartyDriver, *t.DriverID)
+}
+
+// CreateByCompany: company user (with selected company after switch-company) rates the driver of company cargo.
+func (h *ReviewsHandler) CreateByCompany(c *gin.Context) {
+	companyID, ok := appUserCompanyID(c)
+	if !ok {
+		resp.ErrorLang(c, http.StatusForbidden, "company_not_selected")
+		return
+	}
+	t, obj, ok := h.completedTrip(c)
+	if !ok {
+		return
+	}
+	if obj.CompanyID == nil || *obj.CompanyID != companyID {
+		resp.ErrorLang(c, http.StatusForbidden, "not_your_cargo")
+		return
+	}
+	if t.DriverID == nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "review_target_not_found")
+		return
+	}
+	h.create(c, t.ID, reviews.PartyCompany, companyID, reviews.PartyDriver, *t.DriverID)
+}
+
+// completedTrip loads trip by :id and its cargo; trip must be COMPLETED. Writes error response when !ok.
+func (h *ReviewsHandler) completedTrip(c *gin.Context) (*trips.Trip, *cargo.Cargo, bool) {
+	tripID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return nil, nil, false
+	}
+	t, err := h.tripsRepo.GetByID(c.Request.Context(), tripID)
+	if err != nil || t == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "trip_not_found")
+		return nil, nil, false
+	}
+	if t.Status != trips.StatusCompleted {
+		resp.ErrorLang(c, http.StatusBadRequest, "trip_not_completed")
+		return nil, nil, false
+	}
+	obj, _ := h.cargoRepo.GetByID(c.Request.Context(), t.CargoID, true)
+	if obj == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "cargo_not_found")
+		return nil, nil, false
+	}
+	return t, obj, true
+}
+
+func (h *ReviewsHandler) create(c *gin.Context, tripID uuid.UUID, authorType string, authorID uuid.UUID, targetType string, targetID uuid.UUID) {
+	var req CreateReviewReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+		return
+	}
+	tags := make([]string, 0, len(req.Tags))
+	for _, tag := range req.Tags {
+		if !reference.IsAllowed(tag, reference.AllowedReviewTags()) {
+			resp.ErrorLang(c, http.StatusBadRequest, "invalid_review_tag")
+			return
+		}
+		tags = append(tags, upperStr(tag))
+	}
+	id, err := h.repo.Create(c.Request.Context(), reviews.CreateParams{
+		TripID: tripID, AuthorType: authorType, AuthorID: authorID,
+		TargetType: targetType, TargetID: targetID,
+		Score: req.Score, Tags: tags, Comment: req.Comment,
+	})
+	if err != nil {
+		if errors.Is(err, reviews.ErrAlreadyExists) {
+			resp.ErrorLang(c, http.StatusConflict, "review_already_exists")
+			return
+		}
+		h.logger.Error("review create", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "failed_to_create_review")
+		return
+	}
+	resp.SuccessLang(c, http.StatusCreated, "created", gin.H{
+		"id": id.String(), "trip_id": tripID.String(), "target_type": targetType, "target_id": targetID.String(), "score": req.Score,
+	})
+}
+
+// ListByTrip for GET /api/trips/:id/reviews: both directions of a trip (published only).
+func (h *ReviewsHandler) ListByTrip(c *gin.Context) {
+	tripID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return
+	}
+	list, err := h.repo.ListByTrip(c.Request.Context(), tripID)
+	if err != nil {
+		h.logger.Error("reviews list by trip", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "failed_to_list")
+		return
+	}
+	out := make([]gin.H, 0, len(list))
+	for i := range list {
+		if list[i].Status == reviews.StatusPublished {
+			out = append(out, toReviewResp(&list[i], false))
+		}
+	}
+	resp.OKLang(c, "ok", gin.H{"items": out})
+}
+
+// ListPublic for GET /v1/reviews?target_type=DRIVER&target_id=...: public reviews on a profile with aggregated rating.
+func (h *ReviewsHandler) ListPublic(c *gin.Context) {
+	targetType := upperStr(c.Query("target_type"))
+	if targetType != reviews.PartyDriver && targetType != reviews.PartyDispatcher && targetType != reviews.PartyCompany {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_review_target")
+		return
+	}
+	targetID, err := uuid.Parse(strings.TrimSpace(c.Query("target_id")))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_review_target")
+		return
+	}
+	page := getIntQuery(c, "page", 1)
+	limit := getIntQuery(c, "limit", 20)
+	list, total, err := h.repo.List(c.Request.Context(), reviews.ListFilter{
+		TargetType: targetType, TargetID: &targetID, Status: reviews.StatusPublished,
+		Limit: limit, Offset: (page - 1) * limit,
+	})
+	if err != nil {
+		h.logger.Error("reviews list public", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "failed_to_list")
+		return
+	}
+	summary, _ := h.repo.GetSummary(c.Request.Context(), targetType, targetID)
+	out := make([]gin.H, 0, len(list))
+	for i := range list {
+		out = append(out, toReviewResp(&list[i], false))
+	}
+	resp.OKLang(c, "ok", gin.H{"items": out, "total": total, "rating": summary.Rating, "rating_count": summary.Count})
+}
+
+// AdminList for GET /v1/admin/reviews?status=PUBLISHED|HIDDEN&target_type=&target_id=.
+func (h *ReviewsHandler) AdminList(c *gin.Context) {
+	f := reviews.ListFilter{
+		TargetType: upperStr(c.Query("target_type")),
+		Status:     upperStr(c.Query("status")),
+		Limit:      getIntQuery(c, "limit", 50),
+	}
+	f.Offset = (getIntQuery(c, "page", 1) - 1) * f.Limit
+	if v := strings.TrimSpace(c.Query("target_id")); v != "" {
+		if id, err := uuid.Parse(v); err == nil {
+			f.TargetID = &id
+		}
+	}
+	list, total, err := h.repo.List(c.Request.Context(), f)
+	if err != nil {
+		h.logger.Error("reviews admin list", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "failed_to_list")
+		return
+	}
+	out := make([]gin.H, 0, len(list))
+	for i := range list {
+		out = append(out, toReviewResp(&list[i], true))
+	}
+	resp.OKLang(c, "ok", gin.H{"items": out, "total": total})
+}
+
+// ModerateReviewReq body for POST /v1/admin/reviews/:id/hide (reason required).
+type ModerateReviewReq struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// AdminHide hides an abusive review; it is excluded from the target rating.
+func (h *ReviewsHandler) AdminHide(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return
+	}
+	var req ModerateReviewReq
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Reason) == "" {
+		resp.ErrorLang(c, http.StatusBadRequest, "reason_required")
+		return
+	}
+	h.setStatus(c, id, reviews.StatusHidden, req.Reason)
+}
+
+// AdminRestore publishes a previously hidden review again.
+func (h *ReviewsHandler) AdminRestore(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return
+	}
+	h.setStatus(c, id, reviews.StatusPublished, "")
+}
+
+func (h *ReviewsHandler) setStatus(c *gin.Context, id uuid.UUID, status, reason string) {
+	if err := h.repo.SetStatus(c.Request.Context(), id, status, reason); err != nil {
+		if errors.Is(err, reviews.ErrNotFound) {
+			resp.ErrorLang(c, http.StatusNotFound, "review_not_found")
+			return
+		}
+		h.logger.Error("review set status", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "failed_to_update")
+		return
+	}
+	resp.OKLang(c, "updated", gin.H{"id": id.String(), "status": status})
+}
+
+// appUserCompanyID returns company selected by company user (JWT company_id after switch-company).
+func appUserCompanyID(c *gin.Context) (uuid.UUID, bool) {
+	v, ok := c.Get(mw.CtxAppUserCompanyID)
+	if !ok {
+		return uuid.Nil, false
+	}
+	id, ok := v.(uuid.UUID)
+	return id, ok && id != uuid.Nil
+}
+
+func toReviewResp(rv *reviews.Review, withModeration bool) gin.H {
+	out := gin.H{
+		"id": rv.ID.String(), "trip_id": rv.TripID.String(),
+		"author_type": rv.AuthorType, "author_id": rv.AuthorID.String(),
+		"target_type": rv.TargetType, "target_id": rv.TargetID.String(),
+		"score": rv.Score, "tags": rv.Tags, "comment": rv.Comment, "created_at": rv.CreatedAt,
+	}
+	if rv.Tags == nil {
+		out["tags"] = []string{}
+	}
+	if withModeration {
+		out["status"] = rv.Status
+		out["moderation_reason"] = rv.ModerationReason
+		out["updated_at"] = rv.UpdatedAt
+	}
+	return out
+}
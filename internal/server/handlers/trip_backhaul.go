@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"sarbonNew/internal/cargo"
+	"sarbonNew/internal/currency"
+	"sarbonNew/internal/drivers"
+	"sarbonNew/internal/reference"
+	"sarbonNew/internal/routing"
+	"sarbonNew/internal/server/resp"
+	"sarbonNew/internal/trips"
+)
+
+const (
+	defaultBackhaulRadiusKm = 200
+	maxBackhaulRadiusKm     = 1000
+	maxBackhaulLimit        = 50
+)
+
+// TripBackhaulHandler — подсказки обратного груза: пока рейс в работе, водителю и его диспетчеру показываются грузы
+// в поиске с погрузкой рядом с выгрузкой рейса и готовностью не раньше прибытия, по выгодности с учётом порожнего перегона.
+type TripBackhaulHandler struct {
+	logger    *zap.Logger
+	trips     *trips.Repo
+	cargoRepo *cargo.Repo
+	drivers   *drivers.Repo
+	routes    *routing.Estimator
+	rates     *currency.Repo
+}
+
+// NewTripBackhaulHandler creates the handler.
+func NewTripBackhaulHandler(logger *zap.Logger, tripsRepo *trips.Repo, cargoRepo *cargo.Repo, driversRepo *drivers.Repo, routes *routing.Estimator, rates *currency.Repo) *TripBackhaulHandler {
+	return &TripBackhaulHandler{logger: logger, trips: tripsRepo, cargoRepo: cargoRepo, drivers: driversRepo, routes: routes, rates: rates}
+}
+
+// SuggestMy — обратные грузы для рейса водителя.
+// GET /v1/driver/trips/:id/backhaul?radius_km=&truck_type=&limit=&offset=
+func (h *TripBackhaulHandler) SuggestMy(c *gin.Context) {
+	t, ok := driverOwnTrip(c, h.trips)
+	if !ok {
+		return
+	}
+	drv, _ := h.drivers.FindByID(c.Request.Context(), *t.DriverID)
+	if drv == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "driver_not_found")
+		return
+	}
+	h.suggest(c, t, drv)
+}
+
+// Suggest — обратные грузы для рейса водителя диспетчера (freelancer_id).
+// GET /v1/dispatchers/trips/:id/backhaul?radius_km=&truck_type=&limit=&offset=
+func (h *TripBackhaulHandler) Suggest(c *gin.Context) {
+	t, drv, ok := dispatcherDriverTrip(c, h.trips, h.drivers)
+	if !ok {
+		return
+	}
+	h.suggest(c, t, drv)
+}
+
+func (h *TripBackhaulHandler) suggest(c *gin.Context, t *trips.Trip, drv *drivers.Driver) {
+	if !tripTrackable(t) {
+		resp.ErrorLang(c, http.StatusBadRequest, "trip_not_active")
+		return
+	}
+	radius := float64(defaultBackhaulRadiusKm)
+	if v := strings.TrimSpace(c.Query("radius_km")); v != "" {
+		r, err := strconv.ParseFloat(v, 64)
+		if err != nil || r <= 0 || r > maxBackhaulRadiusKm {
+			resp.ErrorLang(c, http.StatusBadRequest, "invalid_search_radius")
+			return
+		}
+		radius = r
+	}
+	ctx := c.Request.Context()
+	obj, _ := h.cargoRepo.GetByID(ctx, t.CargoID, true)
+	g, err := loadTripGroup(ctx, h.trips, h.cargoRepo, t)
+	if err != nil || obj == nil {
+		resp.ErrorLang(c, http.StatusInternalServerError, "failed_to_get_cargo")
+		return
+	}
+	truckType := obj.TruckType
+	if v := strings.ToUpper(strings.TrimSpace(c.Query("truck_type"))); v != "" {
+		if !reference.IsAllowed(v, reference.AllowedTruckTypes()) {
+			resp.ErrorLang(c, http.StatusBadRequest, "invalid_truck_type")
+			return
+		}
+		truckType = v
+	}
+	end, remaining := h.tripEnd(ctx, t, g)
+	if end == nil {
+		resp.ErrorLang(c, http.StatusConflict, "trip_unload_point_unknown")
+		return
+	}
+	eta, located := h.tripEndETA(obj.TruckType, drv, remaining)
+
+	f := cargo.BackhaulFilter{
+		Near:      routing.Point{Lat: end.Lat, Lng: end.Lng},
+		RadiusKm:  radius,
+		ReadyFrom: eta,
+		TruckType: truckType,
+		MaxWeight: drv.TrailerCapacityWeight,
+		MaxVolume: drv.TrailerCapacityVolume,
+	}
+	if drv.CompanyID != nil {
+		if id, err := uuid.Parse(*drv.CompanyID); err == nil {
+			f.CompanyID = &id
+		}
+	}
+	list, err := h.cargoRepo.BackhaulCandidates(ctx, f)
+	if err != nil {
+		h.logger.Error("trip backhaul candidates", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	conv := currency.NewConverter(nil)
+	if h.rates != nil {
+		if cv, err := h.rates.ConverterOn(ctx, time.Now()); err == nil {
+			conv = cv
+		} else {
+			h.logger.Warn("currency rates", zap.Error(err))
+		}
+	}
+	roadKm := func(a, b routing.Point) float64 {
+		return h.routes.Estimate(truckType, []routing.Point{a, b}).DistanceKm
+	}
+	ranked := cargo.RankBackhaul(list, f, roadKm, conv.ToBase)
+
+	limit, offset := getIntQuery(c, "limit", 20), getIntQuery(c, "offset", 0)
+	if limit > maxBackhaulLimit {
+		limit = maxBackhaulLimit
+	}
+	total := len(ranked)
+	var page []cargo.BackhaulSuggestion
+	if offset < total {
+		page = ranked[offset:min(offset+limit, total)]
+	}
+	lang := resp.Lang(c)
+	items := make([]gin.H, 0, len(page))
+	for i := range page {
+		items = append(items, toBackhaulResp(&page[i], lang))
+	}
+	resp.OKLang(c, "ok", gin.H{
+		"trip_id":               t.ID.String(),
+		"trip_status":           t.Status,
+		"unload_point":          toBackhaulPoint(end, lang),
+		"eta":                   eta,
+		"driver_location_known": located,
+		"radius_km":             radius,
+		"truck_type":            truckType,
+		"base_currency":         currency.Base,
+		"items":                 items,
+		"total":                 total,
+		"limit":                 limit,
+		"offset":                offset,
+	})
+}
+
+// tripEnd — где машина освободится: основная выгрузка груза рейса, в консолидированном рейсе — последняя точка
+// объединённого маршрута; remaining — оставшиеся до неё точки.
+func (h *TripBackhaulHandler) tripEnd(ctx context.Context, t *trips.Trip, g *tripGroup) (*cargo.RoutePoint, []cargo.RoutePoint) {
+	stops, err := h.trips.Stops(ctx, g.Host.ID)
+	if err != nil {
+		h.logger.Warn("trip backhaul stops", zap.Error(err))
+	}
+	remaining := remainingRoutePoints(t.Status, g.Points, stops)
+	if len(g.Members) > 1 {
+		if len(g.Points) == 0 {
+			return nil, nil
+		}
+		return &g.Points[len(g.Points)-1], remaining
+	}
+	var end *cargo.RoutePoint
+	for i := range g.Points {
+		if g.Points[i].IsMainUnload {
+			end = &g.Points[i]
+		}
+	}
+	if end == nil {
+		return nil, nil
+	}
+	for i := range remaining {
+		if remaining[i].ID == end.ID {
+			return end, remaining[:i+1]
+		}
+	}
+	return end, nil
+}
+
+// tripEndETA — прибытие в точку выгрузки: от текущей позиции водителя, а если она неизвестна — по оставшимся точкам
+// от ближайшей (без пути до неё).
+func (h *TripBackhaulHandler) tripEndETA(truckType string, drv *drivers.Driver, remaining []cargo.RoutePoint) (time.Time, bool) {
+	now := time.Now()
+	if drv.Latitude != nil && drv.Longitude != nil {
+		_, at := h.routes.ETA(truckType, routing.Point{Lat: *drv.Latitude, Lng: *drv.Longitude}, toRoutingPoints(remaining), now)
+		return at, true
+	}
+	return now.Add(h.routes.Estimate(truckType, toRoutingPoints(remaining)).Total()), false
+}
+
+func toBackhaulPoint(rp *cargo.RoutePoint, lang string) gin.H {
+	return gin.H{
+		"city_code": rp.CityCode,
+		"city_name": trackingCityName(rp.CityCode, lang),
+		"address":   rp.Address,
+		"lat":       rp.Lat,
+		"lng":       rp.Lng,
+	}
+}
+
+func toBackhaulResp(s *cargo.BackhaulSuggestion, lang string) gin.H {
+	res := gin.H{
+		"cargo_id":         s.Cargo.ID.String(),
+		"status":           s.Cargo.Status,
+		"truck_type":       s.Cargo.TruckType,
+		"truck_type_label": reference.RefLabel("cargo.truck_type", s.Cargo.TruckType, lang),
+		"weight":           s.Cargo.Weight,
+		"volume":           s.Cargo.Volume,
+		"ready_enabled":    s.Cargo.ReadyEnabled,
+		"ready_at":         s.Cargo.ReadyAt,
+		"load":             toBackhaulPoint(&s.Load, lang),
+		"unload":           nil,
+		"detour_km":        s.DetourKm,
+		"loaded_km":        s.LoadedKm,
+		"price":            nil,
+		"currency":         nil,
+		"price_request":    false,
+		"price_base":       s.PriceBase,
+		"rate_per_km":      s.RatePerKm,
+		"created_at":       s.Cargo.CreatedAt,
+	}
+	if s.Unload != nil {
+		res["unload"] = toBackhaulPoint(s.Unload, lang)
+	}
+	if p := s.Payment; p != nil {
+		res["price"], res["currency"], res["price_request"] = p.TotalAmount, p.TotalCurrency, p.PriceRequest
+	}
+	return res
+}
@@ -0,0 +1,432 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"sarbonNew/internal/cargo"
+	"sarbonNew/internal/companies"
+	"sarbonNew/internal/drivers"
+	"sarbonNew/internal/notifications"
+	"sarbonNew/internal/reference"
+	"sarbonNew/internal/server/mw"
+	"sarbonNew/internal/server/resp"
+	"sarbonNew/internal/trips"
+)
+
+// TripCancellationsHandler — отмена рейса с причиной (справочник trip_cancel_reason), штрафом по этапу и возвратом груза
+// в поиск или передачей выбранному водителю; замена водителя на активном рейсе диспетчером с записью передачи.
+type TripCancellationsHandler struct {
+	logger    *zap.Logger
+	repo      *trips.Repo
+	cargoRepo *cargo.Repo
+	drivers   *drivers.Repo
+	companies *companies.Repo
+	notifier  *notifications.Notifier
+}
+
+// NewTripCancellationsHandler creates the handler.
+func NewTripCancellationsHandler(logger *zap.Logger, repo *trips.Repo, cargoRepo *cargo.Repo, driversRepo *drivers.Repo, companiesRepo *companies.Repo, notifier *notifications.Notifier) *TripCancellationsHandler {
+	return &TripCancellationsHandler{logger: logger, repo: repo, cargoRepo: cargoRepo, drivers: driversRepo, companies: companiesRepo, notifier: notifier}
+}
+
+// CancelTripReq — причина отмены (обязательна; для OTHER нужен комментарий).
+// reassign_driver_id — только создатель груза: вместо возврата груза в поиск создать рейс на этого водителя.
+type CancelTripReq struct {
+	ReasonCode       string  `json:"reason_code" binding:"required"`
+	Comment          *string `json:"comment"`
+	ReassignDriverID *string `json:"reassign_driver_id"`
+}
+
+// SwapDriverReq — новый водитель (работает с тем же диспетчером), причина и место передачи (необязательно).
+type SwapDriverReq struct {
+	DriverID   string   `json:"driver_id" binding:"required"`
+	ReasonCode string   `json:"reason_code" binding:"required"`
+	Comment    *string  `json:"comment"`
+	Lat        *float64 `json:"lat"`
+	Lng        *float64 `json:"lng"`
+}
+
+// PenaltyRuleReq — процент штрафа для стороны (DRIVER | SHIPPER) и статуса рейса на момент отмены.
+type PenaltyRuleReq struct {
+	Party      string   `json:"party" binding:"required,oneof=DRIVER SHIPPER"`
+	TripStatus string   `json:"trip_status" binding:"required"`
+	Percent    *float64 `json:"percent" binding:"required,gte=0,lte=100"`
+}
+
+// CancelByDriver — водитель отменяет рейс (PENDING_DRIVER, ASSIGNED, LOADING); груз возвращается в поиск.
+// POST /v1/driver/trips/:id/cancel
+func (h *TripCancellationsHandler) CancelByDriver(c *gin.Context) {
+	t, ok := driverOwnTrip(c, h.repo)
+	if !ok {
+		return
+	}
+	var req CancelTripReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+		return
+	}
+	if req.ReassignDriverID != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+		return
+	}
+	h.cancel(c, t, req, trips.PartyDriver, notifications.RecipientDriver, *t.DriverID)
+}
+
+// Cancel — создатель груза отменяет рейс; груз возвращается в поиск или, с reassign_driver_id, передаётся
+// новым рейсом (PENDING_DRIVER, та же согласованная цена) выбранному водителю.
+// POST /v1/dispatchers/trips/:id/cancel, POST /v1/trips/:id/cancel
+func (h *TripCancellationsHandler) Cancel(c *gin.Context) {
+	t, byType, byID, ok := cargoCreatorTrip(c, h.repo, h.cargoRepo)
+	if !ok {
+		return
+	}
+	var req CancelTripReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+		return
+	}
+	h.cancel(c, t, req, trips.PartyShipper, byType, byID)
+}
+
+func (h *TripCancellationsHandler) cancel(c *gin.Context, t *trips.Trip, req CancelTripReq, party, byType string, byID uuid.UUID) {
+	ctx := c.Request.Context()
+	in := trips.CancelInput{
+		TripID:          t.ID,
+		ReasonCode:      strings.ToUpper(strings.TrimSpace(req.ReasonCode)),
+		Comment:         trimmedOrNil(req.Comment),
+		Party:           party,
+		CancelledByType: byType,
+		CancelledByID:   byID,
+	}
+	if !reference.IsAllowed(in.ReasonCode, reference.AllowedTripCancelReasons()) {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_cancel_reason")
+		return
+	}
+	if in.ReasonCode == "OTHER" && in.Comment == nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "cancel_comment_required")
+		return
+	}
+	if req.ReassignDriverID != nil {
+		id, err := uuid.Parse(*req.ReassignDriverID)
+		if err != nil {
+			resp.ErrorLang(c, http.StatusBadRequest, "invalid_driver_id")
+			return
+		}
+		if drv, _ := h.drivers.FindByID(ctx, id); drv == nil {
+			resp.ErrorLang(c, http.StatusNotFound, "driver_not_found")
+			return
+		}
+		if t.DriverID != nil && *t.DriverID == id {
+			resp.ErrorLang(c, http.StatusBadRequest, "same_driver")
+			return
+		}
+		in.ReassignDriverID = &id
+	}
+	x, err := h.repo.Cancel(ctx, in)
+	if err != nil {
+		switch {
+		case errors.Is(err, trips.ErrNotFound):
+			resp.ErrorLang(c, http.StatusNotFound, "trip_not_found")
+		case errors.Is(err, trips.ErrInvalidTransition):
+			resp.ErrorLang(c, http.StatusConflict, "trip_not_cancellable")
+		case errors.Is(err, trips.ErrHasConsolidated):
+			resp.ErrorLang(c, http.StatusConflict, "trip_has_consolidated_cargos")
+		default:
+			h.logger.Error("trip cancel", zap.Error(err))
+			resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		}
+		return
+	}
+	// груз не отменяется — возвращается в поиск или переходит новому рейсу, поэтому исход заказа не фиксируется
+	payload := map[string]any{
+		"trip_id": t.ID.String(), "cargo_id": t.CargoID.String(), "reason_code": x.ReasonCode, "comment": x.Comment,
+		"penalty_amount": x.PenaltyAmount, "penalty_currency": x.PenaltyCurrency,
+	}
+	if party == trips.PartyDriver {
+		if obj, _ := h.cargoRepo.GetByID(ctx, t.CargoID, true); obj != nil {
+			if recipientType, recipientID, ok := cargoOwner(obj); ok {
+				h.notifier.Notify(ctx, recipientType, recipientID, notifications.KindTripCancelled, payload)
+			}
+		}
+	} else if t.DriverID != nil {
+		h.notifier.Notify(ctx, notifications.RecipientDriver, *t.DriverID, notifications.KindTripCancelled, payload)
+	}
+	if x.NewTripID != nil {
+		h.notifier.Notify(ctx, notifications.RecipientDriver, *in.ReassignDriverID, notifications.KindTripAssigned, map[string]any{
+			"trip_id": x.NewTripID.String(), "cargo_id": t.CargoID.String(),
+		})
+	}
+	resp.OKLang(c, "updated", toCancellationResp(x, resp.Lang(c)))
+}
+
+// GetMy — причина и штраф отмены рейса (водитель рейса).
+// GET /v1/driver/trips/:id/cancellation
+func (h *TripCancellationsHandler) GetMy(c *gin.Context) {
+	t, ok := driverOwnTrip(c, h.repo)
+	if !ok {
+		return
+	}
+	h.get(c, t)
+}
+
+// Get — причина и штраф отмены рейса (создатель груза).
+// GET /v1/dispatchers/trips/:id/cancellation, GET /v1/trips/:id/cancellation
+func (h *TripCancellationsHandler) Get(c *gin.Context) {
+	t, _, _, ok := cargoCreatorTrip(c, h.repo, h.cargoRepo)
+	if !ok {
+		return
+	}
+	h.get(c, t)
+}
+
+func (h *TripCancellationsHandler) get(c *gin.Context, t *trips.Trip) {
+	x, err := h.repo.Cancellation(c.Request.Context(), t.ID)
+	if err != nil {
+		h.logger.Error("trip cancellation", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if x == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "trip_cancellation_not_found")
+		return
+	}
+	resp.OKLang(c, "ok", toCancellationResp(x, resp.Lang(c)))
+}
+
+// SwapDriver — диспетчер передаёт активный рейс (ASSIGNED … UNLOADING) другому своему водителю; рейс продолжается
+// с тем же статусом и отметками, передача записывается. Присоединённые рейсы консолидированного рейса передаются вместе.
+// POST /v1/dispatchers/trips/:id/driver-swap
+func (h *TripCancellationsHandler) SwapDriver(c *gin.Context) {
+	dispatcherID := c.MustGet(mw.CtxDispatcherID).(uuid.UUID)
+	t, _, ok := dispatcherDriverTrip(c, h.repo, h.drivers)
+	if !ok {
+		return
+	}
+	var req SwapDriverReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+		return
+	}
+	reason := strings.ToUpper(strings.TrimSpace(req.ReasonCode))
+	if !reference.IsAllowed(reason, reference.AllowedTripCancelReasons()) {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_cancel_reason")
+		return
+	}
+	if (req.Lat == nil) != (req.Lng == nil) || (req.Lat != nil && (*req.Lat < -90 || *req.Lat > 90 || *req.Lng < -180 || *req.Lng > 180)) {
+		resp.ErrorLang(c, http.StatusBadRequest, "pod_invalid_geo")
+		return
+	}
+	ctx := c.Request.Context()
+	toID, err := uuid.Parse(req.DriverID)
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_driver_id")
+		return
+	}
+	if toID == *t.DriverID {
+		resp.ErrorLang(c, http.StatusBadRequest, "same_driver")
+		return
+	}
+	to, _ := h.drivers.FindByID(ctx, toID)
+	if to == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "driver_not_found")
+		return
+	}
+	if to.FreelancerID == nil || *to.FreelancerID != dispatcherID.String() {
+		resp.ErrorLang(c, http.StatusForbidden, "driver_must_accept_invitation")
+		return
+	}
+	hostID := t.ID
+	if t.ParentTripID != nil {
+		hostID = *t.ParentTripID
+	}
+	ho, err := h.repo.SwapDriver(ctx, trips.Handover{
+		TripID:          hostID,
+		FromDriverID:    *t.DriverID,
+		ToDriverID:      toID,
+		ReasonCode:      reason,
+		Comment:         trimmedOrNil(req.Comment),
+		Lat:             req.Lat,
+		Lng:             req.Lng,
+		InitiatedByType: notifications.RecipientDispatcher,
+		InitiatedByID:   dispatcherID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, trips.ErrNotFound):
+			resp.ErrorLang(c, http.StatusNotFound, "trip_not_found")
+		case errors.Is(err, trips.ErrInvalidTransition):
+			resp.ErrorLang(c, http.StatusConflict, "trip_not_active")
+		case errors.Is(err, trips.ErrDriverMismatch):
+			resp.ErrorLang(c, http.StatusConflict, "trip_driver_changed")
+		default:
+			h.logger.Error("trip driver swap", zap.Error(err))
+			resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		}
+		return
+	}
+	payload := map[string]any{
+		"trip_id": ho.TripID.String(), "cargo_id": t.CargoID.String(), "from_driver_id": ho.FromDriverID.String(),
+		"to_driver_id": ho.ToDriverID.String(), "reason_code": ho.ReasonCode,
+	}
+	h.notifier.Notify(ctx, notifications.RecipientDriver, ho.ToDriverID, notifications.KindTripAssigned, payload)
+	h.notifier.Notify(ctx, notifications.RecipientDriver, ho.FromDriverID, notifications.KindDriverSwapped, payload)
+	if obj, _ := h.cargoRepo.GetByID(ctx, t.CargoID, true); obj != nil {
+		if recipientType, recipientID, ok := cargoOwner(obj); ok {
+			h.notifier.Notify(ctx, recipientType, recipientID, notifications.KindDriverSwapped, payload)
+		}
+	}
+	resp.OKLang(c, "updated", toHandoverResp(ho, resp.Lang(c)))
+}
+
+// Handovers — история передач рейса между водителями (диспетчер водителя).
+// GET /v1/dispatchers/trips/:id/handovers
+func (h *TripCancellationsHandler) Handovers(c *gin.Context) {
+	t, _, ok := dispatcherDriverTrip(c, h.repo, h.drivers)
+	if !ok {
+		return
+	}
+	hostID := t.ID
+	if t.ParentTripID != nil {
+		hostID = *t.ParentTripID
+	}
+	list, err := h.repo.Handovers(c.Request.Context(), hostID)
+	if err != nil {
+		h.logger.Error("trip handovers", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	lang := resp.Lang(c)
+	items := make([]gin.H, 0, len(list))
+	for i := range list {
+		items = append(items, toHandoverResp(&list[i], lang))
+	}
+	resp.OKLang(c, "ok", gin.H{"trip_id": hostID.String(), "items": items})
+}
+
+// AdminPenaltyRules GET /v1/admin/trip-cancel-penalties — правила штрафов за отмену рейса.
+func (h *TripCancellationsHandler) AdminPenaltyRules(c *gin.Context) {
+	list, err := h.repo.PenaltyRules(c.Request.Context())
+	if err != nil {
+		h.logger.Error("trip cancel penalty rules", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	lang := resp.Lang(c)
+	items := make([]gin.H, 0, len(list))
+	for i := range list {
+		items = append(items, toPenaltyRuleResp(&list[i], lang))
+	}
+	resp.OKLang(c, "ok", gin.H{"items": items})
+}
+
+// AdminUpsertPenaltyRule PUT /v1/admin/trip-cancel-penalties — задать процент штрафа для стороны и статуса рейса.
+func (h *TripCancellationsHandler) AdminUpsertPenaltyRule(c *gin.Context) {
+	var req PenaltyRuleReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+		return
+	}
+	status := strings.ToUpper(strings.TrimSpace(req.TripStatus))
+	if !trips.CanCancel(status) {
+		resp.ErrorLang(c, http.StatusBadRequest, "trip_not_cancellable")
+		return
+	}
+	p, err := h.repo.UpsertPenaltyRule(c.Request.Context(), req.Party, status, *req.Percent, adminIDFromCtx(c))
+	if err != nil {
+		h.logger.Error("trip cancel penalty upsert", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "failed_to_update")
+		return
+	}
+	resp.OKLang(c, "updated", toPenaltyRuleResp(p, resp.Lang(c)))
+}
+
+// AdminDeletePenaltyRule DELETE /v1/admin/trip-cancel-penalties/:id
+func (h *TripCancellationsHandler) AdminDeletePenaltyRule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return
+	}
+	deleted, err := h.repo.DeletePenaltyRule(c.Request.Context(), id)
+	if err != nil {
+		h.logger.Error("trip cancel penalty delete", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if !deleted {
+		resp.ErrorLang(c, http.StatusNotFound, "penalty_rule_not_found")
+		return
+	}
+	resp.OKLang(c, "ok", gin.H{"status": "deleted"})
+}
+
+func trimmedOrNil(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	v := strings.TrimSpace(*s)
+	if v == "" {
+		return nil
+	}
+	return &v
+}
+
+func toCancellationResp(x *trips.Cancellation, lang string) gin.H {
+	res := gin.H{
+		"trip_id":           x.TripID.String(),
+		"reason_code":       x.ReasonCode,
+		"reason_label":      reference.RefLabel("cargo.trip_cancel_reason", x.ReasonCode, lang),
+		"comment":           x.Comment,
+		"party":             x.Party,
+		"cancelled_by_type": x.CancelledByType,
+		"cancelled_by_id":   x.CancelledByID.String(),
+		"from_status":       x.FromStatus,
+		"from_status_label": reference.RefLabel("cargo.trip_status", x.FromStatus, lang),
+		"penalty_percent":   x.PenaltyPercent,
+		"penalty_amount":    x.PenaltyAmount,
+		"penalty_currency":  x.PenaltyCurrency,
+		"cargo_outcome":     x.CargoOutcome,
+		"new_trip_id":       nil,
+		"created_at":        x.CreatedAt,
+	}
+	if x.NewTripID != nil {
+		res["new_trip_id"] = x.NewTripID.String()
+	}
+	return res
+}
+
+func toHandoverResp(h *trips.Handover, lang string) gin.H {
+	return gin.H{
+		"id":                h.ID.String(),
+		"trip_id":           h.TripID.String(),
+		"from_driver_id":    h.FromDriverID.String(),
+		"to_driver_id":      h.ToDriverID.String(),
+		"trip_status":       h.TripStatus,
+		"trip_status_label": reference.RefLabel("cargo.trip_status", h.TripStatus, lang),
+		"reason_code":       h.ReasonCode,
+		"reason_label":      reference.RefLabel("cargo.trip_cancel_reason", h.ReasonCode, lang),
+		"comment":           h.Comment,
+		"lat":               h.Lat,
+		"lng":               h.Lng,
+		"initiated_by_type": h.InitiatedByType,
+		"initiated_by_id":   h.InitiatedByID.String(),
+		"created_at":        h.CreatedAt,
+	}
+}
+
+func toPenaltyRuleResp(p *trips.PenaltyRule, lang string) gin.H {
+	return gin.H{
+		"id":                p.ID.String(),
+		"party":             p.Party,
+		"trip_status":       p.TripStatus,
+		"trip_status_label": reference.RefLabel("cargo.trip_status", p.TripStatus, lang),
+		"percent":           p.Percent,
+		"updated_at":        p.UpdatedAt,
+	}
+}
@@ -0,0 +1,534 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"sarbonNew/internal/cargo"
+	"sarbonNew/internal/drivers"
+	"sarbonNew/internal/reference"
+	"sarbonNew/internal/server/mw"
+	"sarbonNew/internal/server/resp"
+	"sarbonNew/internal/trips"
+)
+
+// TripConsolidationHandler — консолидированные рейсы (LTL): к рейсу-носителю присоединяются рейсы других грузов
+// того же водителя, машина проходит объединённый маршрут; оффер, цена, счёт и POD остаются у каждого рейса свои.
+type TripConsolidationHandler struct {
+	logger    *zap.Logger
+	repo      *trips.Repo
+	cargoRepo *cargo.Repo
+	drivers   *drivers.Repo
+}
+
+// NewTripConsolidationHandler creates the handler.
+func NewTripConsolidationHandler(logger *zap.Logger, repo *trips.Repo, cargoRepo *cargo.Repo, driversRepo *drivers.Repo) *TripConsolidationHandler {
+	return &TripConsolidationHandler{logger: logger, repo: repo, cargoRepo: cargoRepo, drivers: driversRepo}
+}
+
+// AddTripCargoReq — присоединить рейс trip_id; sequence — порядок всех точек объединённого маршрута (необязательно,
+// по умолчанию точки вставляются автоматически туда, где меньше всего удлиняют маршрут).
+type AddTripCargoReq struct {
+	TripID   string   `json:"trip_id" binding:"required"`
+	Sequence []string `json:"sequence"`
+}
+
+// ListMy — грузы рейса, объединённый маршрут и загрузка машины (водитель).
+// GET /v1/driver/trips/:id/cargos
+func (h *TripConsolidationHandler) ListMy(c *gin.Context) {
+	t, ok := driverOwnTrip(c, h.repo)
+	if !ok {
+		return
+	}
+	drv, _ := h.drivers.FindByID(c.Request.Context(), *t.DriverID)
+	h.list(c, t, drv)
+}
+
+// List — грузы рейса, объединённый маршрут и загрузка машины (диспетчер водителя).
+// GET /v1/dispatchers/trips/:id/cargos
+func (h *TripConsolidationHandler) List(c *gin.Context) {
+	t, drv, ok := dispatcherDriverTrip(c, h.repo, h.drivers)
+	if !ok {
+		return
+	}
+	h.list(c, t, drv)
+}
+
+// Candidates — назначенные (ASSIGNED) рейсы того же водителя, которые можно присоединить к рейсу:
+// совместимость груза и загрузка машины после присоединения.
+// GET /v1/dispatchers/trips/:id/cargos/candidates
+func (h *TripConsolidationHandler) Candidates(c *gin.Context) {
+	t, drv, ok := dispatcherDriverTrip(c, h.repo, h.drivers)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+	g, progress, ok := h.group(c, t)
+	if !ok {
+		return
+	}
+	cargos := h.cargos(ctx, g)
+	hostCargo := cargos[g.Host.CargoID]
+	if hostCargo == nil {
+		resp.ErrorLang(c, http.StatusInternalServerError, "failed_to_get_cargo")
+		return
+	}
+	list, err := h.repo.ListByDriver(ctx, *g.Host.DriverID, 100)
+	if err != nil {
+		h.logger.Error("consolidation candidates", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	lang := resp.Lang(c)
+	items := make([]gin.H, 0)
+	for i := range list {
+		cand := &list[i]
+		if cand.Status != trips.StatusAssigned || cand.ParentTripID != nil || g.Member(cand.CargoID) != nil {
+			continue
+		}
+		obj, _ := h.cargoRepo.GetByID(ctx, cand.CargoID, true)
+		points, err := h.cargoRepo.GetRoutePoints(ctx, cand.CargoID)
+		if obj == nil || err != nil {
+			continue
+		}
+		item := gin.H{
+			"trip_id":      cand.ID.String(),
+			"cargo_id":     cand.CargoID.String(),
+			"weight":       obj.Weight,
+			"volume":       obj.Volume,
+			"truck_type":   obj.TruckType,
+			"compatible":   true,
+			"reason":       nil,
+			"peak_weight":  nil,
+			"peak_volume":  nil,
+			"fits":         nil,
+			"route_points": len(points),
+		}
+		if key := consolidationConflict(hostCargo, obj); key != "" {
+			item["compatible"], item["reason"] = false, resp.Msg(key, lang)
+			items = append(items, item)
+			continue
+		}
+		seq := trips.MergeSequence(toSeqPoints(g.Points), progress.Visited(), toSeqPoints(points))
+		loads := groupLoads(cargos)
+		loads[obj.ID] = trips.Load{Weight: obj.Weight, Volume: obj.Volume}
+		peak := trips.PeakLoad(seq, loads)
+		item["peak_weight"], item["peak_volume"] = peak.Weight, peak.Volume
+		if key := capacityConflict(drv, peak); key != "vehicle_capacity_unknown" {
+			item["fits"] = key == ""
+		}
+		items = append(items, item)
+	}
+	resp.OKLang(c, "ok", gin.H{"trip_id": g.Host.ID.String(), "items": items})
+}
+
+// Add — присоединить рейс другого груза к рейсу (носитель в ASSIGNED, LOADING или EN_ROUTE; присоединяемый — ASSIGNED,
+// тот же водитель). Грузы должны быть совместимы (тип кузова, температура, не FTL), а пиковая загрузка на объединённом
+// маршруте — не больше грузоподъёмности и объёма машины водителя.
+// POST /v1/dispatchers/trips/:id/cargos
+func (h *TripConsolidationHandler) Add(c *gin.Context) {
+	t, drv, ok := dispatcherDriverTrip(c, h.repo, h.drivers)
+	if !ok {
+		return
+	}
+	var req AddTripCargoReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+		return
+	}
+	ctx := c.Request.Context()
+	g, progress, ok := h.group(c, t)
+	if !ok {
+		return
+	}
+	switch g.Host.Status {
+	case trips.StatusAssigned, trips.StatusLoading, trips.StatusEnRoute:
+	default:
+		resp.ErrorLang(c, http.StatusBadRequest, "trip_not_active")
+		return
+	}
+	child, ok := h.trip(c, req.TripID)
+	if !ok {
+		return
+	}
+	if child.ID == g.Host.ID || child.Status != trips.StatusAssigned || child.ParentTripID != nil ||
+		child.DriverID == nil || *child.DriverID != *g.Host.DriverID || g.Member(child.CargoID) != nil {
+		resp.ErrorLang(c, http.StatusConflict, "trip_not_consolidatable")
+		return
+	}
+	cargos := h.cargos(ctx, g)
+	hostCargo := cargos[g.Host.CargoID]
+	obj, _ := h.cargoRepo.GetByID(ctx, child.CargoID, true)
+	points, err := h.cargoRepo.GetRoutePoints(ctx, child.CargoID)
+	if hostCargo == nil || obj == nil || err != nil {
+		resp.ErrorLang(c, http.StatusInternalServerError, "failed_to_get_cargo")
+		return
+	}
+	if key := consolidationConflict(hostCargo, obj); key != "" {
+		resp.ErrorLang(c, http.StatusConflict, key)
+		return
+	}
+	current := toSeqPoints(g.Points)
+	var seq []trips.SeqPoint
+	if len(req.Sequence) > 0 {
+		order := make([]uuid.UUID, 0, len(req.Sequence))
+		for _, s := range req.Sequence {
+			id, err := uuid.Parse(s)
+			if err != nil {
+				resp.ErrorLang(c, http.StatusBadRequest, "invalid_stop_sequence")
+				return
+			}
+			order = append(order, id)
+		}
+		seq, err = trips.ValidateSequence(current, progress.Visited(), append(current, toSeqPoints(points)...), order)
+		if err != nil {
+			resp.ErrorLang(c, http.StatusBadRequest, "invalid_stop_sequence")
+			return
+		}
+	} else {
+		seq = trips.MergeSequence(current, progress.Visited(), toSeqPoints(points))
+	}
+	loads := groupLoads(cargos)
+	loads[obj.ID] = trips.Load{Weight: obj.Weight, Volume: obj.Volume}
+	if key := capacityConflict(drv, trips.PeakLoad(seq, loads)); key != "" {
+		resp.ErrorLang(c, http.StatusConflict, key)
+		return
+	}
+	ids := make([]uuid.UUID, 0, len(seq))
+	for _, p := range seq {
+		ids = append(ids, p.ID)
+	}
+	if err := h.repo.Consolidate(ctx, g.Host.ID, child.ID, ids); err != nil {
+		if errors.Is(err, trips.ErrNotConsolidatable) {
+			resp.ErrorLang(c, http.StatusConflict, "trip_not_consolidatable")
+			return
+		}
+		h.logger.Error("consolidate trip", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	h.list(c, g.Host, drv)
+}
+
+// Remove — отсоединить рейс от рейса-носителя, пока водитель не отметился ни на одной точке его груза.
+// DELETE /v1/dispatchers/trips/:id/cargos/:tripId
+func (h *TripConsolidationHandler) Remove(c *gin.Context) {
+	t, drv, ok := dispatcherDriverTrip(c, h.repo, h.drivers)
+	if !ok {
+		return
+	}
+	childID, err := uuid.Parse(c.Param("tripId"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return
+	}
+	g, progress, ok := h.group(c, t)
+	if !ok {
+		return
+	}
+	var child *trips.Trip
+	for i := range g.Members[1:] {
+		if g.Members[i+1].ID == childID {
+			child = &g.Members[i+1]
+		}
+	}
+	if child == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "trip_not_found")
+		return
+	}
+	var ids []uuid.UUID
+	for i, rp := range g.Points {
+		if rp.CargoID != child.CargoID {
+			ids = append(ids, rp.ID)
+			continue
+		}
+		if progress.Stops[i] != nil {
+			resp.ErrorLang(c, http.StatusConflict, "trip_cargo_already_started")
+			return
+		}
+	}
+	detached, err := h.repo.Detach(c.Request.Context(), g.Host.ID, child.ID, ids)
+	if err != nil {
+		h.logger.Error("detach trip", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if !detached {
+		resp.ErrorLang(c, http.StatusNotFound, "trip_not_found")
+		return
+	}
+	h.list(c, g.Host, drv)
+}
+
+func (h *TripConsolidationHandler) list(c *gin.Context, t *trips.Trip, drv *drivers.Driver) {
+	g, progress, ok := h.group(c, t)
+	if !ok {
+		return
+	}
+	cargos := h.cargos(c.Request.Context(), g)
+	lang := resp.Lang(c)
+	members := make([]gin.H, 0, len(g.Members))
+	for _, m := range g.Members {
+		item := gin.H{
+			"trip_id":      m.ID.String(),
+			"cargo_id":     m.CargoID.String(),
+			"status":       m.Status,
+			"status_label": reference.RefLabel("cargo.trip_status", m.Status, lang),
+			"is_host":      m.ID == g.Host.ID,
+			"weight":       nil,
+			"volume":       nil,
+		}
+		if obj := cargos[m.CargoID]; obj != nil {
+			item["weight"], item["volume"], item["truck_type"] = obj.Weight, obj.Volume, obj.TruckType
+		}
+		members = append(members, item)
+	}
+	sequence := make([]gin.H, 0, len(g.Points))
+	for i, rp := range g.Points {
+		item := gin.H{
+			"route_point_id": rp.ID.String(),
+			"cargo_id":       rp.CargoID.String(),
+			"trip_id":        nil,
+			"type":           rp.Type,
+			"type_label":     reference.RefLabel("cargo.route_point_type", rp.Type, lang),
+			"city_code":      rp.CityCode,
+			"address":        rp.Address,
+			"lat":            rp.Lat,
+			"lng":            rp.Lng,
+			"state":          "PENDING",
+		}
+		if m := g.Member(rp.CargoID); m != nil {
+			item["trip_id"] = m.ID.String()
+		}
+		if s := progress.Stops[i]; s != nil {
+			item["state"] = "ARRIVED"
+			if s.DepartedAt != nil {
+				item["state"] = "DEPARTED"
+			}
+		}
+		sequence = append(sequence, item)
+	}
+	peak := trips.PeakLoad(toSeqPoints(g.Points), groupLoads(cargos))
+	res := gin.H{
+		"host_trip_id":    g.Host.ID.String(),
+		"consolidated":    len(g.Members) > 1,
+		"cargos":          members,
+		"sequence":        sequence,
+		"peak_weight":     peak.Weight,
+		"peak_volume":     peak.Volume,
+		"capacity_weight": nil,
+		"capacity_volume": nil,
+	}
+	if drv != nil {
+		res["capacity_weight"], res["capacity_volume"] = drv.TrailerCapacityWeight, drv.TrailerCapacityVolume
+	}
+	resp.OKLang(c, "ok", res)
+}
+
+// dispatcherDriverTrip — рейс :id, водитель которого работает с текущим диспетчером (freelancer_id).
+func dispatcherDriverTrip(c *gin.Context, tripsRepo *trips.Repo, driversRepo *drivers.Repo) (*trips.Trip, *drivers.Driver, bool) {
+	dispatcherID := c.MustGet(mw.CtxDispatcherID).(uuid.UUID)
+	tripID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return nil, nil, false
+	}
+	t, _ := tripsRepo.GetByID(c.Request.Context(), tripID)
+	if t == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "trip_not_found")
+		return nil, nil, false
+	}
+	if t.DriverID == nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "trip_not_active")
+		return nil, nil, false
+	}
+	drv, _ := driversRepo.FindByID(c.Request.Context(), *t.DriverID)
+	if drv == nil || drv.FreelancerID == nil || *drv.FreelancerID != dispatcherID.String() {
+		resp.ErrorLang(c, http.StatusForbidden, "trip_driver_not_managed")
+		return nil, nil, false
+	}
+	return t, drv, true
+}
+
+func (h *TripConsolidationHandler) trip(c *gin.Context, id string) (*trips.Trip, bool) {
+	tripID, err := uuid.Parse(id)
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return nil, false
+	}
+	t, _ := h.repo.GetByID(c.Request.Context(), tripID)
+	if t == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "trip_not_found")
+		return nil, false
+	}
+	return t, true
+}
+
+// group загружает группу рейса и отметки на точках рейса-носителя.
+func (h *TripConsolidationHandler) group(c *gin.Context, t *trips.Trip) (*tripGroup, trips.StopProgress, bool) {
+	ctx := c.Request.Context()
+	g, err := loadTripGroup(ctx, h.repo, h.cargoRepo, t)
+	if err != nil {
+		h.logger.Error("trip group", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "failed_to_get_cargo")
+		return nil, trips.StopProgress{}, false
+	}
+	stops, err := h.repo.Stops(ctx, g.Host.ID)
+	if err != nil {
+		h.logger.Error("trip group stops", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return nil, trips.StopProgress{}, false
+	}
+	return g, trips.NewStopProgress(toStopPoints(g.Points), stops), true
+}
+
+func (h *TripConsolidationHandler) cargos(ctx context.Context, g *tripGroup) map[uuid.UUID]*cargo.Cargo {
+	out := make(map[uuid.UUID]*cargo.Cargo, len(g.Members))
+	for _, m := range g.Members {
+		if obj, _ := h.cargoRepo.GetByID(ctx, m.CargoID, true); obj != nil {
+			out[m.CargoID] = obj
+		}
+	}
+	return out
+}
+
+// tripGroup — рейс-носитель, присоединённые к нему рейсы и объединённый маршрут их грузов.
+// Для обычного рейса группа из одного рейса, маршрут — точки его груза.
+type tripGroup struct {
+	Host    *trips.Trip
+	Members []trips.Trip // носитель первым
+	Points  []cargo.RoutePoint
+}
+
+// Member — рейс группы, везущий груз cargoID, или nil.
+func (g *tripGroup) Member(cargoID uuid.UUID) *trips.Trip {
+	for i := range g.Members {
+		if g.Members[i].CargoID == cargoID {
+			return &g.Members[i]
+		}
+	}
+	return nil
+}
+
+// CargoPoints — точки объединённого маршрута одного груза.
+func (g *tripGroup) CargoPoints(cargoID uuid.UUID) []cargo.RoutePoint {
+	var out []cargo.RoutePoint
+	for _, rp := range g.Points {
+		if rp.CargoID == cargoID {
+			out = append(out, rp)
+		}
+	}
+	return out
+}
+
+// loadTripGroup — группа рейса t (t может быть как носителем, так и присоединённым рейсом).
+// Отметки на точках (trip_stops) всей группы хранятся у носителя.
+func loadTripGroup(ctx context.Context, tripsRepo *trips.Repo, cargoRepo *cargo.Repo, t *trips.Trip) (*tripGroup, error) {
+	host := t
+	if t.ParentTripID != nil {
+		p, err := tripsRepo.GetByID(ctx, *t.ParentTripID)
+		if err != nil {
+			return nil, err
+		}
+		if p != nil {
+			host = p
+		}
+	}
+	children, err := tripsRepo.Children(ctx, host.ID)
+	if err != nil {
+		return nil, err
+	}
+	g := &tripGroup{Host: host, Members: append([]trips.Trip{*host}, children...)}
+	for _, m := range g.Members {
+		points, err := cargoRepo.GetRoutePoints(ctx, m.CargoID)
+		if err != nil {
+			return nil, err
+		}
+		g.Points = append(g.Points, points...)
+	}
+	if len(children) == 0 {
+		return g, nil
+	}
+	seq, err := tripsRepo.Sequence(ctx, host.ID)
+	if err != nil {
+		return nil, err
+	}
+	pos := make(map[uuid.UUID]int, len(seq))
+	for i, id := range seq {
+		pos[id] = i
+	}
+	// точки, которых нет в сохранённом порядке, — в конец
+	rank := func(id uuid.UUID) int {
+		if i, ok := pos[id]; ok {
+			return i
+		}
+		return len(seq)
+	}
+	sort.SliceStable(g.Points, func(i, j int) bool { return rank(g.Points[i].ID) < rank(g.Points[j].ID) })
+	return g, nil
+}
+
+// consolidationConflict — ключ ошибки, если груз other нельзя везти вместе с грузом host, иначе "".
+func consolidationConflict(host, other *cargo.Cargo) string {
+	for _, obj := range []*cargo.Cargo{host, other} {
+		if obj.ShipmentType != nil && strings.EqualFold(*obj.ShipmentType, "FTL") {
+			return "cargo_full_truck_load"
+		}
+	}
+	if !strings.EqualFold(host.TruckType, other.TruckType) {
+		return "cargo_truck_type_mismatch"
+	}
+	if host.TempMin != nil || host.TempMax != nil || other.TempMin != nil || other.TempMax != nil {
+		lo, hi := tempRange(host)
+		olo, ohi := tempRange(other)
+		if lo > ohi || olo > hi {
+			return "cargo_temperature_mismatch"
+		}
+	}
+	return ""
+}
+
+func tempRange(obj *cargo.Cargo) (float64, float64) {
+	lo, hi := -1e9, 1e9
+	if obj.TempMin != nil {
+		lo = *obj.TempMin
+	}
+	if obj.TempMax != nil {
+		hi = *obj.TempMax
+	}
+	return lo, hi
+}
+
+// capacityConflict — ключ ошибки, если пиковая загрузка не помещается в машину водителя (объём проверяется, если указан).
+func capacityConflict(drv *drivers.Driver, peak trips.Load) string {
+	if drv == nil || drv.TrailerCapacityWeight == nil {
+		return "vehicle_capacity_unknown"
+	}
+	if peak.Weight > *drv.TrailerCapacityWeight || (drv.TrailerCapacityVolume != nil && peak.Volume > *drv.TrailerCapacityVolume) {
+		return "vehicle_capacity_exceeded"
+	}
+	return ""
+}
+
+func groupLoads(cargos map[uuid.UUID]*cargo.Cargo) map[uuid.UUID]trips.Load {
+	out := make(map[uuid.UUID]trips.Load, len(cargos))
+	for id, obj := range cargos {
+		out[id] = trips.Load{Weight: obj.Weight, Volume: obj.Volume}
+	}
+	return out
+}
+
+func toSeqPoints(points []cargo.RoutePoint) []trips.SeqPoint {
+	out := make([]trips.SeqPoint, 0, len(points))
+	for _, rp := range points {
+		out = append(out, trips.SeqPoint{ID: rp.ID, CargoID: rp.CargoID, Type: rp.Type, Lat: rp.Lat, Lng: rp.Lng})
+	}
+	return out
+}
@@ -0,0 +1,426 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"sarbonNew/internal/cargo"
+	"sarbonNew/internal/notifications"
+	"sarbonNew/internal/reference"
+	"sarbonNew/internal/server/resp"
+	"sarbonNew/internal/trips"
+)
+
+const maxCustomsDocumentSize = 5 * 1024 * 1024 // 5 MB
+
+// TripCustomsHandler — прохождение таможни на точках CUSTOMS: статус (очередь, декларация, досмотр, выпуск, задержка),
+// декларационные документы (TIR, T1 — по cargo.documents), время на таможне; при задержке уведомляется грузоотправитель.
+type TripCustomsHandler struct {
+	logger    *zap.Logger
+	repo      *trips.Repo
+	cargoRepo *cargo.Repo
+	notifier  *notifications.Notifier
+}
+
+// NewTripCustomsHandler creates the handler.
+func NewTripCustomsHandler(logger *zap.Logger, repo *trips.Repo, cargoRepo *cargo.Repo, notifier *notifications.Notifier) *TripCustomsHandler {
+	return &TripCustomsHandler{logger: logger, repo: repo, cargoRepo: cargoRepo, notifier: notifier}
+}
+
+// CustomsStatusReq — новый статус таможни; для HELD comment — причина задержки (обязательна).
+type CustomsStatusReq struct {
+	Status  string  `json:"status" binding:"required"`
+	Comment *string `json:"comment" binding:"omitempty,max=1000"`
+}
+
+// ListMy — таможни рейса (водитель).
+// GET /v1/driver/trips/:id/customs
+func (h *TripCustomsHandler) ListMy(c *gin.Context) {
+	t, ok := driverOwnTrip(c, h.repo)
+	if !ok {
+		return
+	}
+	h.list(c, t)
+}
+
+// SetStatusByDriver — водитель меняет статус таможни на точке.
+// POST /v1/driver/trips/:id/customs/:pointId/status
+func (h *TripCustomsHandler) SetStatusByDriver(c *gin.Context) {
+	t, ok := driverOwnTrip(c, h.repo)
+	if !ok {
+		return
+	}
+	h.setStatus(c, t, notifications.RecipientDriver, *t.DriverID)
+}
+
+// AddDocumentByDriver — водитель прикрепляет декларационный документ.
+// POST /v1/driver/trips/:id/customs/:pointId/documents
+func (h *TripCustomsHandler) AddDocumentByDriver(c *gin.Context) {
+	t, ok := driverOwnTrip(c, h.repo)
+	if !ok {
+		return
+	}
+	h.addDocument(c, t, notifications.RecipientDriver, *t.DriverID)
+}
+
+// DeleteDocumentByDriver — водитель удаляет загруженный им документ.
+// DELETE /v1/driver/trips/:id/customs/:pointId/documents/:docId
+func (h *TripCustomsHandler) DeleteDocumentByDriver(c *gin.Context) {
+	t, ok := driverOwnTrip(c, h.repo)
+	if !ok {
+		return
+	}
+	h.deleteDocument(c, t, notifications.RecipientDriver, *t.DriverID)
+}
+
+// List — таможни рейса (создатель груза).
+// GET /v1/dispatchers/trips/:id/customs, GET /v1/trips/:id/customs
+func (h *TripCustomsHandler) List(c *gin.Context) {
+	t, _, _, ok := cargoCreatorTrip(c, h.repo, h.cargoRepo)
+	if !ok {
+		return
+	}
+	h.list(c, t)
+}
+
+// SetStatus — создатель груза меняет статус таможни на точке.
+// POST /v1/dispatchers/trips/:id/customs/:pointId/status, POST /v1/trips/:id/customs/:pointId/status
+func (h *TripCustomsHandler) SetStatus(c *gin.Context) {
+	t, byType, byID, ok := cargoCreatorTrip(c, h.repo, h.cargoRepo)
+	if !ok {
+		return
+	}
+	h.setStatus(c, t, byType, byID)
+}
+
+// AddDocument — создатель груза прикрепляет декларационный документ.
+// POST /v1/dispatchers/trips/:id/customs/:pointId/documents, POST /v1/trips/:id/customs/:pointId/documents
+func (h *TripCustomsHandler) AddDocument(c *gin.Context) {
+	t, byType, byID, ok := cargoCreatorTrip(c, h.repo, h.cargoRepo)
+	if !ok {
+		return
+	}
+	h.addDocument(c, t, byType, byID)
+}
+
+// DeleteDocument — создатель груза удаляет загруженный им документ.
+// DELETE /v1/dispatchers/trips/:id/customs/:pointId/documents/:docId, DELETE /v1/trips/:id/customs/:pointId/documents/:docId
+func (h *TripCustomsHandler) DeleteDocument(c *gin.Context) {
+	t, byType, byID, ok := cargoCreatorTrip(c, h.repo, h.cargoRepo)
+	if !ok {
+		return
+	}
+	h.deleteDocument(c, t, byType, byID)
+}
+
+// DocumentFile отдаёт скан таможенного документа.
+// GET /api/trips/:id/customs/documents/:docId/file
+func (h *TripCustomsHandler) DocumentFile(c *gin.Context) {
+	tripID, err1 := uuid.Parse(c.Param("id"))
+	docID, err2 := uuid.Parse(c.Param("docId"))
+	if err1 != nil || err2 != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return
+	}
+	data, contentType, err := h.repo.CustomsDocumentFile(c.Request.Context(), tripID, docID)
+	if err != nil {
+		h.logger.Error("customs document file", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if data == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "photo_not_found")
+		return
+	}
+	c.Data(http.StatusOK, contentType, data)
+}
+
+func (h *TripCustomsHandler) setStatus(c *gin.Context, t *trips.Trip, byType string, byID uuid.UUID) {
+	if !tripInProgress(c, t) {
+		return
+	}
+	rp, ok := h.customsPoint(c, t)
+	if !ok {
+		return
+	}
+	var req CustomsStatusReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+		return
+	}
+	status := strings.ToUpper(strings.TrimSpace(req.Status))
+	if !reference.IsAllowed(status, reference.AllowedCustomsStatuses()) {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_customs_status")
+		return
+	}
+	var comment *string
+	if req.Comment != nil {
+		if v := strings.TrimSpace(*req.Comment); v != "" {
+			comment = &v
+		}
+	}
+	if status == trips.CustomsHeld && (comment == nil || len([]rune(*comment)) < 3) {
+		resp.ErrorLang(c, http.StatusBadRequest, "customs_hold_reason_required")
+		return
+	}
+	ctx := c.Request.Context()
+	cs, err := h.repo.SetCustomsStatus(ctx, t.ID, rp.ID, status, comment, byType, byID)
+	if errors.Is(err, trips.ErrCustomsTransition) {
+		resp.ErrorLang(c, http.StatusConflict, "invalid_customs_transition")
+		return
+	}
+	if err != nil {
+		h.logger.Error("customs status", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if status == trips.CustomsHeld {
+		h.notifyHeld(c, t, rp, cs, byType, byID)
+	}
+	h.list(c, t)
+}
+
+// notifyHeld — грузоотправителю (создателю груза) о задержке на таможне, если задержку отметил не он сам.
+func (h *TripCustomsHandler) notifyHeld(c *gin.Context, t *trips.Trip, rp *cargo.RoutePoint, cs *trips.Customs, byType string, byID uuid.UUID) {
+	ctx := c.Request.Context()
+	obj, _ := h.cargoRepo.GetByID(ctx, t.CargoID, true)
+	if obj == nil {
+		return
+	}
+	recipientType, recipientID, ok := cargoOwner(obj)
+	if !ok || (recipientType == byType && recipientID == byID) {
+		return
+	}
+	h.notifier.Notify(ctx, recipientType, recipientID, notifications.KindCustomsHeld, map[string]any{
+		"trip_id": t.ID.String(), "cargo_id": t.CargoID.String(), "route_point_id": rp.ID.String(),
+		"city_code": rp.CityCode, "address": rp.Address, "reason": cs.HoldReason,
+	})
+}
+
+func (h *TripCustomsHandler) addDocument(c *gin.Context, t *trips.Trip, byType string, byID uuid.UUID) {
+	if !tripInProgress(c, t) {
+		return
+	}
+	rp, ok := h.customsPoint(c, t)
+	if !ok {
+		return
+	}
+	d := trips.CustomsDocument{
+		DocType:        strings.ToUpper(strings.TrimSpace(c.PostForm("doc_type"))),
+		Number:         strings.TrimSpace(c.PostForm("number")),
+		UploadedByType: byType,
+		UploadedByID:   byID,
+	}
+	if !trips.IsCustomsDocType(d.DocType) {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_customs_document_type")
+		return
+	}
+	if d.Number == "" || len([]rune(d.Number)) > 100 {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+		return
+	}
+	var file []byte
+	if _, err := c.FormFile("file"); err == nil {
+		data, contentType, ok := readPODImage(c, "file", maxCustomsDocumentSize)
+		if !ok {
+			return
+		}
+		file, d.ContentType = data, &contentType
+	}
+	saved, err := h.repo.AddCustomsDocument(c.Request.Context(), t.ID, rp.ID, d, file)
+	if errors.Is(err, trips.ErrCustomsNotStarted) {
+		resp.ErrorLang(c, http.StatusConflict, "customs_not_started")
+		return
+	}
+	if err != nil {
+		h.logger.Error("customs document add", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	resp.SuccessLang(c, http.StatusCreated, "created", toCustomsDocResp(t.ID, saved))
+}
+
+func (h *TripCustomsHandler) deleteDocument(c *gin.Context, t *trips.Trip, byType string, byID uuid.UUID) {
+	docID, err := uuid.Parse(c.Param("docId"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return
+	}
+	deleted, err := h.repo.DeleteCustomsDocument(c.Request.Context(), t.ID, docID, byType, byID)
+	if err != nil {
+		h.logger.Error("customs document delete", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if !deleted {
+		resp.ErrorLang(c, http.StatusNotFound, "customs_document_not_found")
+		return
+	}
+	resp.OKLang(c, "ok", gin.H{"status": "deleted"})
+}
+
+// list — точки CUSTOMS маршрута со статусом, историей, документами и временем на таможне.
+func (h *TripCustomsHandler) list(c *gin.Context, t *trips.Trip) {
+	ctx := c.Request.Context()
+	obj, _ := h.cargoRepo.GetByID(ctx, t.CargoID, true)
+	points, err := h.cargoRepo.GetRoutePoints(ctx, t.CargoID)
+	if err != nil || obj == nil {
+		resp.ErrorLang(c, http.StatusInternalServerError, "failed_to_get_cargo")
+		return
+	}
+	customs, err := h.repo.CustomsList(ctx, t.ID)
+	if err != nil {
+		h.logger.Error("customs list", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	events, err := h.repo.CustomsEvents(ctx, t.ID)
+	if err != nil {
+		h.logger.Error("customs events", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	docs, err := h.repo.CustomsDocuments(ctx, t.ID)
+	if err != nil {
+		h.logger.Error("customs documents", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	byPoint := map[uuid.UUID]*trips.Customs{}
+	for i := range customs {
+		byPoint[customs[i].RoutePointID] = &customs[i]
+	}
+	lang := resp.Lang(c)
+	required := requiredCustomsDocuments(obj.Documents)
+	now := time.Now()
+	items := make([]gin.H, 0)
+	for _, rp := range points {
+		if !strings.EqualFold(rp.Type, "CUSTOMS") {
+			continue
+		}
+		item := gin.H{
+			"route_point_id":          rp.ID.String(),
+			"order":                   rp.PointOrder,
+			"city_code":               rp.CityCode,
+			"address":                 rp.Address,
+			"status":                  nil,
+			"status_label":            nil,
+			"hold_reason":             nil,
+			"started_at":              nil,
+			"cleared_at":              nil,
+			"time_in_customs_minutes": nil,
+			"held_minutes":            nil,
+			"minutes_by_status":       gin.H{},
+			"history":                 []gin.H{},
+			"documents":               []gin.H{},
+			"required_documents":      required,
+			"missing_documents":       required,
+		}
+		if cs := byPoint[rp.ID]; cs != nil {
+			var csEvents []trips.CustomsEvent
+			history := make([]gin.H, 0)
+			for _, e := range events {
+				if e.CustomsID == cs.ID {
+					csEvents = append(csEvents, e)
+					history = append(history, gin.H{
+						"from_status": e.FromStatus, "to_status": e.ToStatus, "comment": e.Comment, "by_type": e.ByType, "at": e.CreatedAt,
+					})
+				}
+			}
+			var csDocs []trips.CustomsDocument
+			docsResp := make([]gin.H, 0)
+			for i := range docs {
+				if docs[i].CustomsID == cs.ID {
+					csDocs = append(csDocs, docs[i])
+					docsResp = append(docsResp, toCustomsDocResp(t.ID, &docs[i]))
+				}
+			}
+			m := trips.CustomsMetrics(csEvents, now)
+			byStatus := gin.H{}
+			for s, d := range m.ByStatus {
+				byStatus[s] = int(d.Minutes())
+			}
+			item["status"], item["status_label"] = cs.Status, reference.RefLabel("cargo.customs_status", cs.Status, lang)
+			item["hold_reason"], item["started_at"], item["cleared_at"] = cs.HoldReason, cs.StartedAt, cs.ClearedAt
+			item["time_in_customs_minutes"], item["held_minutes"] = int(m.Total.Minutes()), int(m.Held.Minutes())
+			item["minutes_by_status"], item["history"], item["documents"] = byStatus, history, docsResp
+			item["missing_documents"] = trips.MissingCustomsDocuments(required, csDocs)
+		}
+		items = append(items, item)
+	}
+	resp.OKLang(c, "ok", gin.H{"trip_id": t.ID.String(), "items": items})
+}
+
+// customsPoint — точка :pointId маршрута груза рейса; должна быть типа CUSTOMS.
+func (h *TripCustomsHandler) customsPoint(c *gin.Context, t *trips.Trip) (*cargo.RoutePoint, bool) {
+	pointID, err := uuid.Parse(c.Param("pointId"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return nil, false
+	}
+	points, err := h.cargoRepo.GetRoutePoints(c.Request.Context(), t.CargoID)
+	if err != nil {
+		resp.ErrorLang(c, http.StatusInternalServerError, "failed_to_get_cargo")
+		return nil, false
+	}
+	for i := range points {
+		if points[i].ID != pointID {
+			continue
+		}
+		if !strings.EqualFold(points[i].Type, "CUSTOMS") {
+			resp.ErrorLang(c, http.StatusBadRequest, "route_point_not_customs")
+			return nil, false
+		}
+		return &points[i], true
+	}
+	resp.ErrorLang(c, http.StatusNotFound, "route_point_not_found")
+	return nil, false
+}
+
+// tripInProgress — изменения по таможне только пока рейс в работе.
+func tripInProgress(c *gin.Context, t *trips.Trip) bool {
+	switch t.Status {
+	case trips.StatusAssigned, trips.StatusLoading, trips.StatusEnRoute, trips.StatusUnloading:
+		return true
+	}
+	resp.ErrorLang(c, http.StatusBadRequest, "trip_not_active")
+	return false
+}
+
+// requiredCustomsDocuments — документы, отмеченные в cargo.documents, которые предъявляются на таможне.
+func requiredCustomsDocuments(d *cargo.Documents) []string {
+	out := []string{}
+	if d == nil {
+		return out
+	}
+	for _, x := range []struct {
+		flag    *bool
+		docType string
+	}{{d.TIR, trips.CustomsDocTIR}, {d.T1, trips.CustomsDocT1}, {d.CMR, trips.CustomsDocCMR}, {d.Permit, trips.CustomsDocPermit}} {
+		if x.flag != nil && *x.flag {
+			out = append(out, x.docType)
+		}
+	}
+	return out
+}
+
+func toCustomsDocResp(tripID uuid.UUID, d *trips.CustomsDocument) gin.H {
+	var fileURL any
+	if d.HasFile() {
+		fileURL = "/api/trips/" + tripID.String() + "/customs/documents/" + d.ID.String() + "/file"
+	}
+	return gin.H{
+		"id":               d.ID.String(),
+		"doc_type":         d.DocType,
+		"number":           d.Number,
+		"file_url":         fileURL,
+		"uploaded_by_type": d.UploadedByType,
+		"created_at":       d.CreatedAt,
+	}
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"sarbonNew/internal/cargo"
+	"sarbonNew/internal/server/mw"
+	"sarbonNew/internal/server/resp"
+	"sarbonNew/internal/telegram"
+	"sarbonNew/internal/trips"
+	"sarbonNew/internal/util"
+)
+
+// TripDeliveryPINHandler — PIN-код получателя: создатель груза (диспетчер или компания) видит PIN,
+// передаёт его получателю сам или отправляет на телефон получателя через Telegram Gateway.
+type TripDeliveryPINHandler struct {
+	logger      *zap.Logger
+	repo        *trips.Repo
+	cargoRepo   *cargo.Repo
+	tg          *telegram.GatewayClient
+	maxAttempts int
+}
+
+// NewTripDeliveryPINHandler creates the handler; maxAttempts — DELIVERY_PIN_MAX_ATTEMPTS.
+func NewTripDeliveryPINHandler(logger *zap.Logger, repo *trips.Repo, cargoRepo *cargo.Repo, tg *telegram.GatewayClient, maxAttempts int) *TripDeliveryPINHandler {
+	return &TripDeliveryPINHandler{logger: logger, repo: repo, cargoRepo: cargoRepo, tg: tg, maxAttempts: maxAttempts}
+}
+
+// Get — PIN получателя по рейсу.
+// GET /v1/dispatchers/trips/:id/delivery-pin, GET /v1/trips/:id/delivery-pin
+func (h *TripDeliveryPINHandler) Get(c *gin.Context) {
+	t, ok := h.creatorTrip(c)
+	if !ok {
+		return
+	}
+	p, err := h.repo.GetDeliveryPIN(c.Request.Context(), t.ID)
+	if err != nil {
+		h.logger.Error("delivery pin get", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if p == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "delivery_pin_not_issued")
+		return
+	}
+	resp.OKLang(c, "ok", h.pinResp(p))
+}
+
+// SendDeliveryPINReq — телефон получателя (E.164).
+type SendDeliveryPINReq struct {
+	Phone string `json:"phone" binding:"required"`
+}
+
+// Send отправляет PIN на телефон получателя через Telegram Gateway.
+// POST /v1/dispatchers/trips/:id/delivery-pin/send, POST /v1/trips/:id/delivery-pin/send
+func (h *TripDeliveryPINHandler) Send(c *gin.Context) {
+	t, ok := h.creatorTrip(c)
+	if !ok {
+		return
+	}
+	var req SendDeliveryPINReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+		return
+	}
+	phone, err := util.NormalizeE164StrictPlus(req.Phone)
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+		return
+	}
+	ctx := c.Request.Context()
+	p, ok := h.pendingPIN(c, t.ID)
+	if !ok {
+		return
+	}
+	sendCtx, cancel := context.WithTimeout(ctx, otpSendTimeout)
+	defer cancel()
+	// ttl 0 — без ограничения: PIN действует до доставки, а не минуты, как OTP
+	if _, err := h.tg.SendVerificationMessage(sendCtx, phone, p.PIN, 0); WriteOTPSendError(c, err, h.logger, "delivery pin send failed") {
+		return
+	}
+	p, err = h.repo.MarkDeliveryPINSent(ctx, t.ID, phone)
+	if err != nil {
+		h.logger.Error("delivery pin mark sent", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	resp.OKLang(c, "ok", h.pinResp(p))
+}
+
+// Regenerate выпускает новый PIN (например, после блокировки по попыткам); старый перестаёт действовать.
+// POST /v1/dispatchers/trips/:id/delivery-pin/regenerate, POST /v1/trips/:id/delivery-pin/regenerate
+func (h *TripDeliveryPINHandler) Regenerate(c *gin.Context) {
+	t, ok := h.creatorTrip(c)
+	if !ok {
+		return
+	}
+	p, err := h.repo.RegenerateDeliveryPIN(c.Request.Context(), t.ID)
+	switch {
+	case errors.Is(err, trips.ErrDeliveryPINNotFound):
+		resp.ErrorLang(c, http.StatusNotFound, "delivery_pin_not_issued")
+		return
+	case errors.Is(err, trips.ErrDeliveryPINVerified):
+		resp.ErrorLang(c, http.StatusConflict, "delivery_pin_already_verified")
+		return
+	case err != nil:
+		h.logger.Error("delivery pin regenerate", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	resp.OKLang(c, "updated", h.pinResp(p))
+}
+
+// pendingPIN загружает PIN рейса, который ещё можно передать получателю.
+func (h *TripDeliveryPINHandler) pendingPIN(c *gin.Context, tripID uuid.UUID) (*trips.DeliveryPIN, bool) {
+	p, err := h.repo.GetDeliveryPIN(c.Request.Context(), tripID)
+	if err != nil {
+		h.logger.Error("delivery pin get", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return nil, false
+	}
+	switch {
+	case p == nil:
+		resp.ErrorLang(c, http.StatusNotFound, "delivery_pin_not_issued")
+		return nil, false
+	case p.VerifiedAt != nil:
+		resp.ErrorLang(c, http.StatusConflict, "delivery_pin_already_verified")
+		return nil, false
+	case p.Locked(h.maxAttempts):
+		resp.ErrorLang(c, http.StatusTooManyRequests, "delivery_pin_locked")
+		return nil, false
+	}
+	return p, true
+}
+
+// creatorTrip загружает рейс по :id; груз должен принадлежать текущему диспетчеру или компании пользователя.
+func (h *TripDeliveryPINHandler) creatorTrip(c *gin.Context) (*trips.Trip, bool) {
+	t, _, _, ok := cargoCreatorTrip(c, h.repo, h.cargoRepo)
+	return t, ok
+}
+
+// cargoCreatorTrip загружает рейс по :id и проверяет, что груз создан текущим диспетчером (DISPATCHER)
+// или принадлежит компании пользователя (COMPANY); возвращает тип и id создателя.
+func cargoCreatorTrip(c *gin.Context, tripsRepo *trips.Repo, cargoRepo *cargo.Repo) (*trips.Trip, string, uuid.UUID, bool) {
+	tripID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return nil, "", uuid.Nil, false
+	}
+	ctx := c.Request.Context()
+	t, err := tripsRepo.GetByID(ctx, tripID)
+	if err != nil || t == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "trip_not_found")
+		return nil, "", uuid.Nil, false
+	}
+	obj, _ := cargoRepo.GetByID(ctx, t.CargoID, true)
+	if obj == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "cargo_not_found")
+		return nil, "", uuid.Nil, false
+	}
+	if v, ok := c.Get(mw.CtxDispatcherID); ok {
+		dispatcherID, _ := v.(uuid.UUID)
+		if obj.CreatedByType == nil || *obj.CreatedByType != "DISPATCHER" || obj.CreatedByID == nil || *obj.CreatedByID != dispatcherID {
+			resp.ErrorLang(c, http.StatusForbidden, "not_your_cargo")
+			return nil, "", uuid.Nil, false
+		}
+		return t, "DISPATCHER", dispatcherID, true
+	}
+	companyID, ok := appUserCompanyID(c)
+	if !ok {
+		resp.ErrorLang(c, http.StatusForbidden, "company_not_selected")
+		return nil, "", uuid.Nil, false
+	}
+	if obj.CompanyID == nil || *obj.CompanyID != companyID {
+		resp.ErrorLang(c, http.StatusForbidden, "not_your_cargo")
+		return nil, "", uuid.Nil, false
+	}
+	return t, "COMPANY", companyID, true
+}
+
+func (h *TripDeliveryPINHandler) pinResp(p *trips.DeliveryPIN) gin.H {
+	return gin.H{
+		"trip_id":       p.TripID.String(),
+		"pin":           p.PIN,
+		"attempts":      p.Attempts,
+		"attempts_left": p.AttemptsLeft(h.maxAttempts),
+		"locked":        p.Locked(h.maxAttempts),
+		"verified":      p.VerifiedAt != nil,
+		"verified_at":   p.VerifiedAt,
+		"sent_to_phone": p.SentToPhone,
+		"sent_at":       p.SentAt,
+		"created_at":    p.CreatedAt,
+		"updated_at":    p.UpdatedAt,
+	}
+}
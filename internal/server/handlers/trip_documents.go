@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"sarbonNew/internal/cargo"
+	"sarbonNew/internal/documents"
+	"sarbonNew/internal/server/resp"
+	"sarbonNew/internal/trips"
+)
+
+// TripDocumentsHandler — PDF-документы рейса (CMR, путевой лист).
+type TripDocumentsHandler struct {
+	logger    *zap.Logger
+	repo      *documents.Repo
+	gen       *documents.Generator
+	trips     *trips.Repo
+	cargoRepo *cargo.Repo
+}
+
+// NewTripDocumentsHandler creates the handler.
+func NewTripDocumentsHandler(logger *zap.Logger, repo *documents.Repo, gen *documents.Generator, tripsRepo *trips.Repo, cargoRepo *cargo.Repo) *TripDocumentsHandler {
+	return &TripDocumentsHandler{logger: logger, repo: repo, gen: gen, trips: tripsRepo, cargoRepo: cargoRepo}
+}
+
+// List returns trip documents (номер, язык, когда сформирован); ещё не созданные типы — без номера.
+// Документы содержат паспортные данные водителя — только создателю груза (диспетчер или компания).
+// GET /v1/dispatchers/trips/:id/documents, GET /v1/trips/:id/documents
+func (h *TripDocumentsHandler) List(c *gin.Context) {
+	t, _, _, ok := cargoCreatorTrip(c, h.trips, h.cargoRepo)
+	if !ok {
+		return
+	}
+	list, err := h.repo.ListByTrip(c.Request.Context(), t.ID)
+	if err != nil {
+		h.logger.Error("trip documents list", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	byType := make(map[string]*documents.Document, len(list))
+	for i := range list {
+		byType[list[i].DocType] = &list[i]
+	}
+	items := make([]gin.H, 0, 2)
+	for _, docType := range []string{documents.TypeCMR, documents.TypeTripSheet} {
+		item := gin.H{
+			"doc_type":     docType,
+			"available":    tripDocumentsAvailable(t),
+			"download_url": strings.TrimSuffix(c.Request.URL.Path, "/") + "/" + strings.ToLower(docType),
+		}
+		if d := byType[docType]; d != nil {
+			item["number"] = d.Number
+			item["lang"] = d.Lang
+			item["created_at"] = d.CreatedAt
+			item["generated_at"] = d.GeneratedAt
+		}
+		items = append(items, item)
+	}
+	resp.OKLang(c, "ok", gin.H{"items": items})
+}
+
+// Download отдаёт PDF документа на языке X-Language (формируется при первом запросе).
+// GET /v1/dispatchers/trips/:id/documents/:type, GET /v1/trips/:id/documents/:type — type: cmr, trip_sheet
+func (h *TripDocumentsHandler) Download(c *gin.Context) {
+	t, _, _, ok := cargoCreatorTrip(c, h.trips, h.cargoRepo)
+	if !ok {
+		return
+	}
+	h.send(c, t)
+}
+
+// Regenerate формирует документ заново по актуальным данным груза и водителя; номер сохраняется.
+// POST /v1/dispatchers/trips/:id/documents/:type, POST /v1/trips/:id/documents/:type
+func (h *TripDocumentsHandler) Regenerate(c *gin.Context) {
+	t, _, _, ok := cargoCreatorTrip(c, h.trips, h.cargoRepo)
+	if !ok {
+		return
+	}
+	d, _, ok := h.generate(c, t, true)
+	if !ok {
+		return
+	}
+	resp.OKLang(c, "ok", gin.H{
+		"doc_type": d.DocType, "number": d.Number, "lang": d.Lang, "created_at": d.CreatedAt, "generated_at": d.GeneratedAt,
+		"download_url": c.Request.URL.Path,
+	})
+}
+
+// DownloadMy — PDF документа рейса для назначенного водителя.
+// GET /v1/driver/trips/:id/documents/:type
+func (h *TripDocumentsHandler) DownloadMy(c *gin.Context) {
+	t, ok := driverOwnTrip(c, h.trips)
+	if !ok {
+		return
+	}
+	h.send(c, t)
+}
+
+func (h *TripDocumentsHandler) send(c *gin.Context, t *trips.Trip) {
+	d, data, ok := h.generate(c, t, false)
+	if !ok {
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+documents.FileName(d.Number)+`"`)
+	c.Data(http.StatusOK, "application/pdf", data)
+}
+
+// generate проверяет тип (:type) и состояние рейса и возвращает документ с PDF.
+func (h *TripDocumentsHandler) generate(c *gin.Context, t *trips.Trip, regenerate bool) (*documents.Document, []byte, bool) {
+	docType := strings.ToUpper(c.Param("type"))
+	if !documents.IsType(docType) {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_document_type")
+		return nil, nil, false
+	}
+	if !tripDocumentsAvailable(t) {
+		resp.ErrorLang(c, http.StatusConflict, "trip_documents_unavailable")
+		return nil, nil, false
+	}
+	d, data, err := h.gen.Get(c.Request.Context(), t, docType, resp.Lang(c), regenerate)
+	if err != nil {
+		h.generateError(c, err)
+		return nil, nil, false
+	}
+	return d, data, true
+}
+
+func (h *TripDocumentsHandler) generateError(c *gin.Context, err error) {
+	if errors.Is(err, documents.ErrNoDriver) {
+		resp.ErrorLang(c, http.StatusConflict, "trip_documents_unavailable")
+		return
+	}
+	if errors.Is(err, documents.ErrCargoNotFound) {
+		resp.ErrorLang(c, http.StatusNotFound, "cargo_not_found")
+		return
+	}
+	h.logger.Error("trip document generate", zap.Error(err))
+	resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+}
+
+// tripDocumentsAvailable — документы формируются, когда водитель подтвердил рейс и рейс не отменён.
+func tripDocumentsAvailable(t *trips.Trip) bool {
+	return t.DriverID != nil && t.Status != trips.StatusCancelled && t.Status != trips.StatusPendingDriver
+}
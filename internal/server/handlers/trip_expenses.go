@@ -0,0 +1,307 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"sarbonNew/internal/cargo"
+	"sarbonNew/internal/currency"
+	"sarbonNew/internal/notifications"
+	"sarbonNew/internal/reference"
+	"sarbonNew/internal/server/resp"
+	"sarbonNew/internal/trips"
+)
+
+const maxExpenseReceiptSize = 5 * 1024 * 1024 // 5 MB
+
+// TripExpensesHandler — расходы водителя в рейсе (топливо, платные дороги, стоянки, таможенные сборы):
+// водитель вносит расход с чеком, создатель груза подтверждает или отклоняет; подтверждённые расходы входят в прибыль рейса.
+type TripExpensesHandler struct {
+	logger    *zap.Logger
+	repo      *trips.Repo
+	cargoRepo *cargo.Repo
+	rates     *currency.Repo
+	notifier  *notifications.Notifier
+}
+
+// NewTripExpensesHandler creates the handler.
+func NewTripExpensesHandler(logger *zap.Logger, repo *trips.Repo, cargoRepo *cargo.Repo, rates *currency.Repo, notifier *notifications.Notifier) *TripExpensesHandler {
+	return &TripExpensesHandler{logger: logger, repo: repo, cargoRepo: cargoRepo, rates: rates, notifier: notifier}
+}
+
+// Add — водитель вносит расход по активному рейсу (ASSIGNED … UNLOADING).
+// POST /v1/driver/trips/:id/expenses (multipart: category, amount, currency, comment, receipt, lat, lng, spent_at RFC3339)
+func (h *TripExpensesHandler) Add(c *gin.Context) {
+	t, ok := driverOwnTrip(c, h.repo)
+	if !ok {
+		return
+	}
+	switch t.Status {
+	case trips.StatusAssigned, trips.StatusLoading, trips.StatusEnRoute, trips.StatusUnloading:
+	default:
+		resp.ErrorLang(c, http.StatusBadRequest, "trip_not_active")
+		return
+	}
+	e := trips.Expense{
+		TripID:   t.ID,
+		DriverID: *t.DriverID,
+		Category: strings.ToUpper(strings.TrimSpace(c.PostForm("category"))),
+		Currency: strings.ToUpper(strings.TrimSpace(c.PostForm("currency"))),
+		SpentAt:  time.Now(),
+	}
+	if !reference.IsAllowed(e.Category, reference.AllowedExpenseCategories()) {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_expense_category")
+		return
+	}
+	amount, err := strconv.ParseFloat(strings.TrimSpace(c.PostForm("amount")), 64)
+	if err != nil || amount <= 0 {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_expense_amount")
+		return
+	}
+	e.Amount = amount
+	if e.Currency == "OTHER" || !reference.IsAllowed(e.Currency, reference.AllowedCurrencies()) {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_currency")
+		return
+	}
+	if v := strings.TrimSpace(c.PostForm("comment")); v != "" {
+		if len([]rune(v)) > 1000 {
+			resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+			return
+		}
+		e.Comment = &v
+	}
+	if v := strings.TrimSpace(c.PostForm("spent_at")); v != "" {
+		ts, err := time.Parse(time.RFC3339, v)
+		if err != nil || ts.After(time.Now().Add(time.Minute)) {
+			resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+			return
+		}
+		e.SpentAt = ts
+	}
+	if e.Lat, e.Lng, ok = podGeo(c, false); !ok {
+		return
+	}
+	var receipt []byte
+	if _, err := c.FormFile("receipt"); err == nil {
+		data, contentType, ok := readPODImage(c, "receipt", maxExpenseReceiptSize)
+		if !ok {
+			return
+		}
+		receipt, e.ReceiptContentType = data, &contentType
+	}
+	ctx := c.Request.Context()
+	saved, err := h.repo.AddExpense(ctx, e, receipt)
+	if err != nil {
+		h.logger.Error("trip expense add", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if obj, _ := h.cargoRepo.GetByID(ctx, t.CargoID, true); obj != nil {
+		if recipientType, recipientID, ok := cargoOwner(obj); ok {
+			h.notifier.Notify(ctx, recipientType, recipientID, notifications.KindExpenseSubmitted, map[string]any{
+				"trip_id": t.ID.String(), "expense_id": saved.ID.String(), "category": saved.Category,
+				"amount": saved.Amount, "currency": saved.Currency,
+			})
+		}
+	}
+	resp.SuccessLang(c, http.StatusCreated, "created", toExpenseResp(saved, resp.Lang(c)))
+}
+
+// ListMy — расходы рейса для водителя.
+// GET /v1/driver/trips/:id/expenses
+func (h *TripExpensesHandler) ListMy(c *gin.Context) {
+	t, ok := driverOwnTrip(c, h.repo)
+	if !ok {
+		return
+	}
+	h.list(c, t)
+}
+
+// Delete — водитель удаляет расход, пока он не проверен.
+// DELETE /v1/driver/trips/:id/expenses/:expenseId
+func (h *TripExpensesHandler) Delete(c *gin.Context) {
+	t, ok := driverOwnTrip(c, h.repo)
+	if !ok {
+		return
+	}
+	expenseID, err := uuid.Parse(c.Param("expenseId"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return
+	}
+	deleted, err := h.repo.DeleteExpense(c.Request.Context(), t.ID, expenseID, *t.DriverID)
+	if err != nil {
+		h.logger.Error("trip expense delete", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if !deleted {
+		resp.ErrorLang(c, http.StatusNotFound, "expense_not_found")
+		return
+	}
+	resp.OKLang(c, "ok", gin.H{"status": "deleted"})
+}
+
+// List — расходы рейса для создателя груза: список, суммы по валютам и прибыль рейса.
+// GET /v1/dispatchers/trips/:id/expenses, GET /v1/trips/:id/expenses
+func (h *TripExpensesHandler) List(c *gin.Context) {
+	t, _, _, ok := cargoCreatorTrip(c, h.repo, h.cargoRepo)
+	if !ok {
+		return
+	}
+	h.list(c, t)
+}
+
+// RejectExpenseReq — причина отклонения расхода.
+type RejectExpenseReq struct {
+	Reason string `json:"reason" binding:"required,min=3,max=1000"`
+}
+
+// Approve — создатель груза подтверждает расход; водителю приходит EXPENSE_REVIEWED.
+// POST /v1/dispatchers/trips/:id/expenses/:expenseId/approve, POST /v1/trips/:id/expenses/:expenseId/approve
+func (h *TripExpensesHandler) Approve(c *gin.Context) {
+	h.review(c, trips.ExpenseApproved)
+}
+
+// Reject — создатель груза отклоняет расход с причиной; водителю приходит EXPENSE_REVIEWED.
+// POST /v1/dispatchers/trips/:id/expenses/:expenseId/reject, POST /v1/trips/:id/expenses/:expenseId/reject
+func (h *TripExpensesHandler) Reject(c *gin.Context) {
+	h.review(c, trips.ExpenseRejected)
+}
+
+func (h *TripExpensesHandler) review(c *gin.Context, status string) {
+	t, byType, byID, ok := cargoCreatorTrip(c, h.repo, h.cargoRepo)
+	if !ok {
+		return
+	}
+	expenseID, err := uuid.Parse(c.Param("expenseId"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return
+	}
+	var reason *string
+	if status == trips.ExpenseRejected {
+		var req RejectExpenseReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+			return
+		}
+		v := strings.TrimSpace(req.Reason)
+		reason = &v
+	}
+	ctx := c.Request.Context()
+	e, err := h.repo.ReviewExpense(ctx, t.ID, expenseID, status, byType, byID, reason)
+	switch {
+	case errors.Is(err, trips.ErrExpenseNotFound):
+		resp.ErrorLang(c, http.StatusNotFound, "expense_not_found")
+		return
+	case errors.Is(err, trips.ErrExpenseReviewed):
+		resp.ErrorLang(c, http.StatusConflict, "expense_already_reviewed")
+		return
+	case err != nil:
+		h.logger.Error("trip expense review", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	h.notifier.Notify(ctx, notifications.RecipientDriver, e.DriverID, notifications.KindExpenseReviewed, map[string]any{
+		"trip_id": t.ID.String(), "expense_id": e.ID.String(), "status": e.Status, "amount": e.Amount,
+		"currency": e.Currency, "reject_reason": e.RejectReason,
+	})
+	resp.OKLang(c, "updated", toExpenseResp(e, resp.Lang(c)))
+}
+
+// Receipt отдаёт фото чека расхода.
+// GET /api/trips/:id/expenses/:expenseId/receipt
+func (h *TripExpensesHandler) Receipt(c *gin.Context) {
+	tripID, err1 := uuid.Parse(c.Param("id"))
+	expenseID, err2 := uuid.Parse(c.Param("expenseId"))
+	if err1 != nil || err2 != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return
+	}
+	data, contentType, err := h.repo.ExpenseReceipt(c.Request.Context(), tripID, expenseID)
+	if err != nil {
+		h.logger.Error("trip expense receipt", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if data == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "photo_not_found")
+		return
+	}
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// list — расходы, суммы по валютам и прибыль (цена минус подтверждённые расходы по курсам на сегодня).
+func (h *TripExpensesHandler) list(c *gin.Context, t *trips.Trip) {
+	ctx := c.Request.Context()
+	list, err := h.repo.Expenses(ctx, t.ID)
+	if err != nil {
+		h.logger.Error("trip expenses list", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	lang := resp.Lang(c)
+	items := make([]gin.H, 0, len(list))
+	for i := range list {
+		items = append(items, toExpenseResp(&list[i], lang))
+	}
+	totals := trips.ExpenseTotals(list)
+	totalsResp := make([]gin.H, 0, len(totals))
+	approved := map[string]float64{}
+	for _, x := range totals {
+		totalsResp = append(totalsResp, gin.H{"currency": x.Currency, "pending": x.Pending, "approved": x.Approved, "rejected": x.Rejected})
+		if x.Approved > 0 {
+			approved[x.Currency] = x.Approved
+		}
+	}
+	res := gin.H{"trip_id": t.ID.String(), "items": items, "totals": totalsResp, "profit": nil}
+	if t.AgreedPrice != nil && t.AgreedCurrency != nil {
+		convert := func(amount float64, from, to string) (float64, bool) { return amount, strings.EqualFold(from, to) }
+		if conv, err := h.rates.ConverterOn(ctx, time.Now()); err == nil {
+			convert = conv.Convert
+		} else {
+			h.logger.Warn("trip expenses rates", zap.Error(err))
+		}
+		profit := gin.H{"price": *t.AgreedPrice, "currency": *t.AgreedCurrency, "approved_expenses": nil, "profit": nil}
+		if expenses, p, ok := trips.Profit(*t.AgreedPrice, *t.AgreedCurrency, approved, convert); ok {
+			profit["approved_expenses"], profit["profit"] = expenses, p
+		}
+		res["profit"] = profit
+	}
+	resp.OKLang(c, "ok", res)
+}
+
+func toExpenseResp(e *trips.Expense, lang string) gin.H {
+	var receiptURL any
+	if e.HasReceipt() {
+		receiptURL = "/api/trips/" + e.TripID.String() + "/expenses/" + e.ID.String() + "/receipt"
+	}
+	return gin.H{
+		"id":               e.ID.String(),
+		"trip_id":          e.TripID.String(),
+		"driver_id":        e.DriverID.String(),
+		"category":         e.Category,
+		"category_label":   reference.RefLabel("cargo.expense_category", e.Category, lang),
+		"amount":           e.Amount,
+		"currency":         e.Currency,
+		"comment":          e.Comment,
+		"receipt_url":      receiptURL,
+		"lat":              e.Lat,
+		"lng":              e.Lng,
+		"spent_at":         e.SpentAt,
+		"status":           e.Status,
+		"status_label":     reference.RefLabel("cargo.expense_status", e.Status, lang),
+		"reviewed_by_type": e.ReviewedByType,
+		"reviewed_at":      e.ReviewedAt,
+		"reject_reason":    e.RejectReason,
+		"created_at":       e.CreatedAt,
+	}
+}
@@ -0,0 +1,544 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"sarbonNew/internal/cargo"
+	"sarbonNew/internal/companies"
+	"sarbonNew/internal/drivers"
+	"sarbonNew/internal/notifications"
+	"sarbonNew/internal/reference"
+	"sarbonNew/internal/server/mw"
+	"sarbonNew/internal/server/resp"
+	"sarbonNew/internal/trips"
+)
+
+const maxIncidentPhotoSize = 5 * 1024 * 1024 // 5 MB
+
+// TripIncidentsHandler — инциденты в рейсе (поломка, ДТП, остановка полицией и т.д.) и SOS:
+// водитель сообщает об инциденте, диспетчер водителя и владелец груза принимают и закрывают его.
+// SOS сразу отправляется по WebSocket диспетчеру водителя, диспетчеру-создателю груза и пользователям компаний.
+type TripIncidentsHandler struct {
+	logger    *zap.Logger
+	repo      *trips.Repo
+	cargoRepo *cargo.Repo
+	drivers   *drivers.Repo
+	companies *companies.Repo
+	notifier  *notifications.Notifier
+}
+
+// NewTripIncidentsHandler creates the handler.
+func NewTripIncidentsHandler(logger *zap.Logger, repo *trips.Repo, cargoRepo *cargo.Repo, driversRepo *drivers.Repo, companiesRepo *companies.Repo, notifier *notifications.Notifier) *TripIncidentsHandler {
+	return &TripIncidentsHandler{logger: logger, repo: repo, cargoRepo: cargoRepo, drivers: driversRepo, companies: companiesRepo, notifier: notifier}
+}
+
+// incidentParty — кто следит за инцидентами рейса: диспетчер или компания.
+type incidentParty struct {
+	Type string // notifications.RecipientDispatcher, notifications.RecipientCompany
+	ID   uuid.UUID
+}
+
+// Report — водитель сообщает об инциденте по активному рейсу (ASSIGNED … UNLOADING).
+// POST /v1/driver/trips/:id/incidents (multipart: type, severity, description, lat, lng, photo)
+func (h *TripIncidentsHandler) Report(c *gin.Context) {
+	h.report(c, false)
+}
+
+// SOS — экстренный инцидент: серьёзность CRITICAL, тип по умолчанию OTHER; оповещение уходит сразу.
+// POST /v1/driver/trips/:id/sos (multipart: type, description, lat, lng, photo — всё необязательно)
+func (h *TripIncidentsHandler) SOS(c *gin.Context) {
+	h.report(c, true)
+}
+
+func (h *TripIncidentsHandler) report(c *gin.Context, sos bool) {
+	t, ok := driverOwnTrip(c, h.repo)
+	if !ok {
+		return
+	}
+	switch t.Status {
+	case trips.StatusAssigned, trips.StatusLoading, trips.StatusEnRoute, trips.StatusUnloading:
+	default:
+		resp.ErrorLang(c, http.StatusBadRequest, "trip_not_active")
+		return
+	}
+	in := trips.Incident{
+		TripID:   t.ID,
+		DriverID: *t.DriverID,
+		Type:     strings.ToUpper(strings.TrimSpace(c.PostForm("type"))),
+		Severity: strings.ToUpper(strings.TrimSpace(c.PostForm("severity"))),
+		IsSOS:    sos,
+	}
+	if sos {
+		in.Severity = trips.SeverityCritical
+		if in.Type == "" {
+			in.Type = trips.IncidentTypeOther
+		}
+	}
+	if !reference.IsAllowed(in.Type, reference.AllowedIncidentTypes()) {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_incident_type")
+		return
+	}
+	if !reference.IsAllowed(in.Severity, reference.AllowedIncidentSeverities()) {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_incident_severity")
+		return
+	}
+	if v := strings.TrimSpace(c.PostForm("description")); v != "" {
+		if len([]rune(v)) > 2000 {
+			resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+			return
+		}
+		in.Description = &v
+	}
+	if in.Lat, in.Lng, ok = podGeo(c, false); !ok {
+		return
+	}
+	var photo []byte
+	var photoType string
+	if _, err := c.FormFile("photo"); err == nil {
+		if photo, photoType, ok = readPODImage(c, "photo", maxIncidentPhotoSize); !ok {
+			return
+		}
+	}
+	ctx := c.Request.Context()
+	saved, err := h.repo.AddIncident(ctx, in, photo, photoType)
+	if err != nil {
+		h.logger.Error("trip incident add", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	kind := notifications.KindIncidentReported
+	if saved.IsSOS {
+		kind = notifications.KindIncidentSOS
+		h.logger.Warn("trip incident SOS", zap.String("trip_id", t.ID.String()), zap.String("incident_id", saved.ID.String()))
+	}
+	h.notifyParties(ctx, t, kind, map[string]any{
+		"trip_id": t.ID.String(), "incident_id": saved.ID.String(), "driver_id": saved.DriverID.String(),
+		"type": saved.Type, "severity": saved.Severity, "is_sos": saved.IsSOS, "description": saved.Description,
+		"lat": saved.Lat, "lng": saved.Lng,
+	})
+	photos, _ := h.repo.IncidentPhotos(ctx, []uuid.UUID{saved.ID})
+	resp.SuccessLang(c, http.StatusCreated, "created", toIncidentResp(saved, photos, nil, resp.Lang(c)))
+}
+
+// AddPhoto — водитель прикладывает ещё одно фото к нерешённому инциденту (до trips.MaxIncidentPhotos).
+// POST /v1/driver/trips/:id/incidents/:incidentId/photos (multipart: photo)
+func (h *TripIncidentsHandler) AddPhoto(c *gin.Context) {
+	t, ok := driverOwnTrip(c, h.repo)
+	if !ok {
+		return
+	}
+	incidentID, err := uuid.Parse(c.Param("incidentId"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return
+	}
+	data, contentType, ok := readPODImage(c, "photo", maxIncidentPhotoSize)
+	if !ok {
+		return
+	}
+	p, err := h.repo.AddIncidentPhoto(c.Request.Context(), t.ID, incidentID, *t.DriverID, data, contentType)
+	switch {
+	case errors.Is(err, trips.ErrIncidentNotFound):
+		resp.ErrorLang(c, http.StatusNotFound, "incident_not_found")
+		return
+	case errors.Is(err, trips.ErrIncidentResolved):
+		resp.ErrorLang(c, http.StatusConflict, "incident_already_resolved")
+		return
+	case errors.Is(err, trips.ErrIncidentPhotoLimit):
+		resp.ErrorLang(c, http.StatusBadRequest, "incident_photo_limit")
+		return
+	case err != nil:
+		h.logger.Error("trip incident photo add", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	resp.SuccessLang(c, http.StatusCreated, "created", toIncidentPhotoResp(t.ID, p))
+}
+
+// ListMy — инциденты рейса для водителя.
+// GET /v1/driver/trips/:id/incidents
+func (h *TripIncidentsHandler) ListMy(c *gin.Context) {
+	t, ok := driverOwnTrip(c, h.repo)
+	if !ok {
+		return
+	}
+	h.list(c, t)
+}
+
+// HistoryMy — история инцидентов водителя по всем рейсам.
+// GET /v1/driver/incidents?limit=&offset=
+func (h *TripIncidentsHandler) HistoryMy(c *gin.Context) {
+	h.history(c, c.MustGet(mw.CtxDriverID).(uuid.UUID))
+}
+
+// ResolveIncidentReq — решение по инциденту (обязательно при закрытии).
+type ResolveIncidentReq struct {
+	Resolution string `json:"resolution" binding:"required,min=3,max=2000"`
+}
+
+// ResolveByDriver — водитель закрывает свой инцидент (например, поломка устранена).
+// POST /v1/driver/trips/:id/incidents/:incidentId/resolve
+func (h *TripIncidentsHandler) ResolveByDriver(c *gin.Context) {
+	t, ok := driverOwnTrip(c, h.repo)
+	if !ok {
+		return
+	}
+	h.setStatus(c, t, trips.IncidentResolved, "DRIVER", *t.DriverID)
+}
+
+// List — инциденты рейса для диспетчера водителя или владельца груза (диспетчер-создатель, компания).
+// GET /v1/dispatchers/trips/:id/incidents, GET /v1/trips/:id/incidents
+func (h *TripIncidentsHandler) List(c *gin.Context) {
+	t, _, ok := h.partyTrip(c)
+	if !ok {
+		return
+	}
+	h.list(c, t)
+}
+
+// Acknowledge — диспетчер или компания принимает инцидент в работу; водителю приходит INCIDENT_UPDATED.
+// POST /v1/dispatchers/trips/:id/incidents/:incidentId/acknowledge, POST /v1/trips/:id/incidents/:incidentId/acknowledge
+func (h *TripIncidentsHandler) Acknowledge(c *gin.Context) {
+	t, party, ok := h.partyTrip(c)
+	if !ok {
+		return
+	}
+	h.setStatus(c, t, trips.IncidentAcknowledged, party.Type, party.ID)
+}
+
+// Resolve — диспетчер или компания закрывает инцидент с решением; водителю приходит INCIDENT_UPDATED.
+// POST /v1/dispatchers/trips/:id/incidents/:incidentId/resolve, POST /v1/trips/:id/incidents/:incidentId/resolve
+func (h *TripIncidentsHandler) Resolve(c *gin.Context) {
+	t, party, ok := h.partyTrip(c)
+	if !ok {
+		return
+	}
+	h.setStatus(c, t, trips.IncidentResolved, party.Type, party.ID)
+}
+
+// DriverHistory — история инцидентов водителя для его диспетчера (freelancer_id) или компании водителя.
+// GET /v1/dispatchers/drivers/:driverId/incidents, GET /v1/drivers/:driverId/incidents
+func (h *TripIncidentsHandler) DriverHistory(c *gin.Context) {
+	driverID, err := uuid.Parse(c.Param("driverId"))
+	if err != nil || driverID == uuid.Nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_driver_id")
+		return
+	}
+	drv, _ := h.drivers.FindByID(c.Request.Context(), driverID)
+	if drv == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "driver_not_found")
+		return
+	}
+	if v, ok := c.Get(mw.CtxDispatcherID); ok {
+		dispatcherID, _ := v.(uuid.UUID)
+		if drv.FreelancerID == nil || *drv.FreelancerID != dispatcherID.String() {
+			resp.ErrorLang(c, http.StatusForbidden, "trip_driver_not_managed")
+			return
+		}
+	} else {
+		companyID, ok := appUserCompanyID(c)
+		if !ok {
+			resp.ErrorLang(c, http.StatusForbidden, "company_not_selected")
+			return
+		}
+		if drv.CompanyID == nil || *drv.CompanyID != companyID.String() {
+			resp.ErrorLang(c, http.StatusForbidden, "trip_driver_not_managed")
+			return
+		}
+	}
+	h.history(c, driverID)
+}
+
+// Photo отдаёт фото инцидента.
+// GET /api/trips/:id/incidents/:incidentId/photos/:photoId
+func (h *TripIncidentsHandler) Photo(c *gin.Context) {
+	tripID, err1 := uuid.Parse(c.Param("id"))
+	incidentID, err2 := uuid.Parse(c.Param("incidentId"))
+	photoID, err3 := uuid.Parse(c.Param("photoId"))
+	if err1 != nil || err2 != nil || err3 != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return
+	}
+	data, contentType, err := h.repo.IncidentPhotoData(c.Request.Context(), tripID, incidentID, photoID)
+	if err != nil {
+		h.logger.Error("trip incident photo", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if data == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "photo_not_found")
+		return
+	}
+	c.Data(http.StatusOK, contentType, data)
+}
+
+func (h *TripIncidentsHandler) setStatus(c *gin.Context, t *trips.Trip, status, byType string, byID uuid.UUID) {
+	incidentID, err := uuid.Parse(c.Param("incidentId"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return
+	}
+	var comment *string
+	if status == trips.IncidentResolved {
+		var req ResolveIncidentReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+			return
+		}
+		v := strings.TrimSpace(req.Resolution)
+		comment = &v
+	}
+	ctx := c.Request.Context()
+	in, err := h.repo.SetIncidentStatus(ctx, t.ID, incidentID, status, comment, byType, byID)
+	switch {
+	case errors.Is(err, trips.ErrIncidentNotFound):
+		resp.ErrorLang(c, http.StatusNotFound, "incident_not_found")
+		return
+	case errors.Is(err, trips.ErrIncidentTransition):
+		resp.ErrorLang(c, http.StatusConflict, "invalid_incident_transition")
+		return
+	case err != nil:
+		h.logger.Error("trip incident status", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	payload := map[string]any{
+		"trip_id": t.ID.String(), "incident_id": in.ID.String(), "status": in.Status, "by_type": byType,
+		"resolution": in.Resolution,
+	}
+	if byType == "DRIVER" {
+		h.notifyParties(ctx, t, notifications.KindIncidentUpdated, payload)
+	} else {
+		h.notifier.Notify(ctx, notifications.RecipientDriver, in.DriverID, notifications.KindIncidentUpdated, payload)
+	}
+	photos, _ := h.repo.IncidentPhotos(ctx, []uuid.UUID{in.ID})
+	resp.OKLang(c, "updated", toIncidentResp(in, photos, nil, resp.Lang(c)))
+}
+
+// list — инциденты рейса с фото, историей статусов и сводкой.
+func (h *TripIncidentsHandler) list(c *gin.Context, t *trips.Trip) {
+	ctx := c.Request.Context()
+	list, err := h.repo.Incidents(ctx, t.ID)
+	if err != nil {
+		h.logger.Error("trip incidents list", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	events, err := h.repo.IncidentEvents(ctx, t.ID)
+	if err != nil {
+		h.logger.Error("trip incident events", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	byIncident := map[uuid.UUID][]trips.IncidentEvent{}
+	for _, e := range events {
+		byIncident[e.IncidentID] = append(byIncident[e.IncidentID], e)
+	}
+	items, ok := h.items(c, list, func(in *trips.Incident) []trips.IncidentEvent {
+		if e := byIncident[in.ID]; e != nil {
+			return e
+		}
+		return []trips.IncidentEvent{}
+	})
+	if !ok {
+		return
+	}
+	resp.OKLang(c, "ok", gin.H{"trip_id": t.ID.String(), "items": items, "summary": toIncidentSummaryResp(trips.IncidentSummary(list))})
+}
+
+// history — инциденты водителя по всем рейсам (без истории статусов), новые первыми.
+func (h *TripIncidentsHandler) history(c *gin.Context, driverID uuid.UUID) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	list, err := h.repo.DriverIncidents(c.Request.Context(), driverID, limit, offset)
+	if err != nil {
+		h.logger.Error("driver incidents history", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	items, ok := h.items(c, list, nil)
+	if !ok {
+		return
+	}
+	resp.OKLang(c, "ok", gin.H{"driver_id": driverID.String(), "items": items, "summary": toIncidentSummaryResp(trips.IncidentSummary(list)),
+		"limit": limit, "offset": offset})
+}
+
+func (h *TripIncidentsHandler) items(c *gin.Context, list []trips.Incident, events func(*trips.Incident) []trips.IncidentEvent) ([]gin.H, bool) {
+	ids := make([]uuid.UUID, 0, len(list))
+	for _, in := range list {
+		ids = append(ids, in.ID)
+	}
+	photos, err := h.repo.IncidentPhotos(c.Request.Context(), ids)
+	if err != nil {
+		h.logger.Error("trip incident photos", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return nil, false
+	}
+	lang := resp.Lang(c)
+	items := make([]gin.H, 0, len(list))
+	for i := range list {
+		var ev []trips.IncidentEvent
+		if events != nil {
+			ev = events(&list[i])
+		}
+		items = append(items, toIncidentResp(&list[i], photos, ev, lang))
+	}
+	return items, true
+}
+
+// parties — диспетчер водителя (freelancer_id), компания водителя и владелец груза (диспетчер-создатель или компания), без повторов.
+func (h *TripIncidentsHandler) parties(ctx context.Context, t *trips.Trip) []incidentParty {
+	var out []incidentParty
+	add := func(typ string, id uuid.UUID) {
+		for _, p := range out {
+			if p.Type == typ && p.ID == id {
+				return
+			}
+		}
+		out = append(out, incidentParty{Type: typ, ID: id})
+	}
+	if t.DriverID != nil {
+		if drv, _ := h.drivers.FindByID(ctx, *t.DriverID); drv != nil {
+			if drv.FreelancerID != nil {
+				if id, err := uuid.Parse(*drv.FreelancerID); err == nil {
+					add(notifications.RecipientDispatcher, id)
+				}
+			}
+			if drv.CompanyID != nil {
+				if id, err := uuid.Parse(*drv.CompanyID); err == nil {
+					add(notifications.RecipientCompany, id)
+				}
+			}
+		}
+	}
+	if obj, _ := h.cargoRepo.GetByID(ctx, t.CargoID, true); obj != nil {
+		if recipientType, recipientID, ok := cargoOwner(obj); ok {
+			add(recipientType, recipientID)
+		}
+		if obj.CompanyID != nil {
+			add(notifications.RecipientCompany, *obj.CompanyID)
+		}
+	}
+	return out
+}
+
+// notifyParties отправляет уведомление всем, кто следит за инцидентами рейса; компаниям — сразу онлайн-пользователям.
+func (h *TripIncidentsHandler) notifyParties(ctx context.Context, t *trips.Trip, kind string, payload map[string]any) {
+	for _, p := range h.parties(ctx, t) {
+		if p.Type != notifications.RecipientCompany {
+			h.notifier.Notify(ctx, p.Type, p.ID, kind, payload)
+			continue
+		}
+		userIDs, err := h.companies.UserIDs(ctx, p.ID)
+		if err != nil {
+			h.logger.Warn("trip incident company users", zap.Error(err))
+		}
+		h.notifier.NotifyCompany(ctx, p.ID, userIDs, kind, payload)
+	}
+}
+
+// partyTrip загружает рейс по :id и проверяет, что текущий диспетчер или компания следит за его инцидентами.
+func (h *TripIncidentsHandler) partyTrip(c *gin.Context) (*trips.Trip, incidentParty, bool) {
+	tripID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return nil, incidentParty{}, false
+	}
+	ctx := c.Request.Context()
+	t, _ := h.repo.GetByID(ctx, tripID)
+	if t == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "trip_not_found")
+		return nil, incidentParty{}, false
+	}
+	var me incidentParty
+	if v, ok := c.Get(mw.CtxDispatcherID); ok {
+		id, _ := v.(uuid.UUID)
+		me = incidentParty{Type: notifications.RecipientDispatcher, ID: id}
+	} else {
+		companyID, ok := appUserCompanyID(c)
+		if !ok {
+			resp.ErrorLang(c, http.StatusForbidden, "company_not_selected")
+			return nil, incidentParty{}, false
+		}
+		me = incidentParty{Type: notifications.RecipientCompany, ID: companyID}
+	}
+	for _, p := range h.parties(ctx, t) {
+		if p == me {
+			return t, me, true
+		}
+	}
+	resp.ErrorLang(c, http.StatusForbidden, "not_your_cargo")
+	return nil, incidentParty{}, false
+}
+
+func toIncidentPhotoResp(tripID uuid.UUID, p *trips.IncidentPhoto) gin.H {
+	return gin.H{
+		"id":           p.ID.String(),
+		"url":          "/api/trips/" + tripID.String() + "/incidents/" + p.IncidentID.String() + "/photos/" + p.ID.String(),
+		"content_type": p.ContentType,
+		"created_at":   p.CreatedAt,
+	}
+}
+
+// toIncidentResp; photos — фото любых инцидентов (берутся только свои), events == nil — история не выводится.
+func toIncidentResp(in *trips.Incident, photos []trips.IncidentPhoto, events []trips.IncidentEvent, lang string) gin.H {
+	photoList := make([]gin.H, 0)
+	for i := range photos {
+		if photos[i].IncidentID == in.ID {
+			photoList = append(photoList, toIncidentPhotoResp(in.TripID, &photos[i]))
+		}
+	}
+	res := gin.H{
+		"id":                   in.ID.String(),
+		"trip_id":              in.TripID.String(),
+		"driver_id":            in.DriverID.String(),
+		"type":                 in.Type,
+		"type_label":           reference.RefLabel("cargo.incident_type", in.Type, lang),
+		"severity":             in.Severity,
+		"severity_label":       reference.RefLabel("cargo.incident_severity", in.Severity, lang),
+		"is_sos":               in.IsSOS,
+		"description":          in.Description,
+		"lat":                  in.Lat,
+		"lng":                  in.Lng,
+		"status":               in.Status,
+		"status_label":         reference.RefLabel("cargo.incident_status", in.Status, lang),
+		"acknowledged_by_type": in.AcknowledgedByType,
+		"acknowledged_at":      in.AcknowledgedAt,
+		"resolved_by_type":     in.ResolvedByType,
+		"resolved_at":          in.ResolvedAt,
+		"resolution":           in.Resolution,
+		"photos":               photoList,
+		"created_at":           in.CreatedAt,
+		"updated_at":           in.UpdatedAt,
+	}
+	if events != nil {
+		history := make([]gin.H, 0, len(events))
+		for _, e := range events {
+			history = append(history, gin.H{
+				"from_status": e.FromStatus, "to_status": e.ToStatus, "comment": e.Comment,
+				"by_type": e.ByType, "by_id": e.ByID.String(), "created_at": e.CreatedAt,
+			})
+		}
+		res["history"] = history
+	}
+	return res
+}
+
+func toIncidentSummaryResp(s trips.IncidentStats) gin.H {
+	return gin.H{"total": s.Total, "open": s.Open, "sos": s.SOS, "by_type": s.ByType}
+}
@@ -0,0 +1,467 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"sarbonNew/internal/cargo"
+	"sarbonNew/internal/companies"
+	"sarbonNew/internal/notifications"
+	"sarbonNew/internal/server/mw"
+	"sarbonNew/internal/server/resp"
+	"sarbonNew/internal/trips"
+)
+
+const (
+	maxPODPhotoSize     = 5 * 1024 * 1024 // 5 MB
+	maxPODSignatureSize = 1 * 1024 * 1024 // 1 MB
+)
+
+var allowedPODImageTypes = map[string]bool{"image/jpeg": true, "image/png": true}
+
+// TripPODHandler — подтверждение доставки (ePOD): фото груза, подпись получателя, спор грузоотправителя.
+type TripPODHandler struct {
+	logger        *zap.Logger
+	repo          *trips.Repo
+	cargoRepo     *cargo.Repo
+	companies     *companies.Repo
+	notifier      *notifications.Notifier
+	disputeWindow time.Duration
+	pinAttempts   int
+}
+
+// NewTripPODHandler creates the handler; disputeWindow — POD_DISPUTE_WINDOW_HOURS, pinAttempts — DELIVERY_PIN_MAX_ATTEMPTS.
+func NewTripPODHandler(logger *zap.Logger, repo *trips.Repo, cargoRepo *cargo.Repo, companiesRepo *companies.Repo, notifier *notifications.Notifier, disputeWindow time.Duration, pinAttempts int) *TripPODHandler {
+	return &TripPODHandler{logger: logger, repo: repo, cargoRepo: cargoRepo, companies: companiesRepo, notifier: notifier, disputeWindow: disputeWindow, pinAttempts: pinAttempts}
+}
+
+// UploadPhoto — водитель загружает фото доставленного груза (multipart: photo, lat, lng, taken_at RFC3339 — необязательно).
+// POST /v1/driver/trips/:id/pod/photos — только в статусе UNLOADING.
+func (h *TripPODHandler) UploadPhoto(c *gin.Context) {
+	t, ok := driverOwnTrip(c, h.repo)
+	if !ok {
+		return
+	}
+	if t.Status != trips.StatusUnloading {
+		resp.ErrorLang(c, http.StatusBadRequest, "trip_not_unloading")
+		return
+	}
+	data, contentType, ok := readPODImage(c, "photo", maxPODPhotoSize)
+	if !ok {
+		return
+	}
+	lat, lng, ok := podGeo(c, false)
+	if !ok {
+		return
+	}
+	takenAt := time.Now()
+	if v := strings.TrimSpace(c.PostForm("taken_at")); v != "" {
+		ts, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+			return
+		}
+		takenAt = ts
+	}
+	p, err := h.repo.AddPODPhoto(c.Request.Context(), t.ID, data, contentType, lat, lng, takenAt)
+	if errors.Is(err, trips.ErrPODTooManyPhotos) {
+		resp.ErrorWithData(c, http.StatusBadRequest, resp.Msg("pod_too_many_photos", resp.Lang(c)), gin.H{"max_photos": trips.MaxPODPhotos})
+		return
+	}
+	if errors.Is(err, trips.ErrPODAlreadySubmitted) {
+		resp.ErrorLang(c, http.StatusConflict, "pod_already_submitted")
+		return
+	}
+	if err != nil {
+		h.logger.Error("pod photo upload", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	resp.OKLang(c, "photo_uploaded", toPODPhotoResp(p))
+}
+
+// DeletePhoto — водитель удаляет фото, пока доставка не подтверждена.
+// DELETE /v1/driver/trips/:id/pod/photos/:photoId
+func (h *TripPODHandler) DeletePhoto(c *gin.Context) {
+	t, ok := driverOwnTrip(c, h.repo)
+	if !ok {
+		return
+	}
+	photoID, err := uuid.Parse(c.Param("photoId"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return
+	}
+	deleted, err := h.repo.DeletePODPhoto(c.Request.Context(), t.ID, photoID)
+	if err != nil {
+		h.logger.Error("pod photo delete", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if !deleted {
+		resp.ErrorLang(c, http.StatusNotFound, "photo_not_found")
+		return
+	}
+	resp.OKLang(c, "ok", gin.H{"status": "deleted"})
+}
+
+// Submit — водитель подтверждает доставку: имя получателя, подпись (изображение), координаты. Рейс → COMPLETED.
+// POST /v1/driver/trips/:id/pod (multipart: signature, consignee_name, lat, lng, delivery_pin). Нужно хотя бы одно фото;
+// delivery_pin — код, который получатель получил от грузоотправителя (обязателен, если рейсу выдан PIN).
+func (h *TripPODHandler) Submit(c *gin.Context) {
+	t, ok := driverOwnTrip(c, h.repo)
+	if !ok {
+		return
+	}
+	consignee := strings.TrimSpace(c.PostForm("consignee_name"))
+	if consignee == "" || len([]rune(consignee)) > 255 {
+		resp.ErrorLang(c, http.StatusBadRequest, "pod_consignee_required")
+		return
+	}
+	lat, lng, ok := podGeo(c, true)
+	if !ok {
+		return
+	}
+	signature, contentType, ok := readPODImage(c, "signature", maxPODSignatureSize)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+	// PIN проверяем только на месте выгрузки, чтобы не расходовать попытки раньше времени
+	if t.Status == trips.StatusCompleted {
+		resp.ErrorLang(c, http.StatusConflict, "pod_already_submitted")
+		return
+	}
+	if t.Status != trips.StatusUnloading {
+		resp.ErrorLang(c, http.StatusBadRequest, "trip_not_unloading")
+		return
+	}
+	if !h.verifyPIN(c, t.ID) {
+		return
+	}
+	pod, err := h.repo.SubmitPOD(ctx, trips.SubmitPODParams{
+		TripID: t.ID, DriverID: *t.DriverID, ConsigneeName: consignee,
+		Signature: signature, SignatureContentType: contentType,
+		Lat: *lat, Lng: *lng, DisputeWindow: h.disputeWindow,
+	})
+	switch {
+	case errors.Is(err, trips.ErrPODNoPhotos):
+		resp.ErrorLang(c, http.StatusBadRequest, "pod_photos_required")
+		return
+	case errors.Is(err, trips.ErrPODAlreadySubmitted):
+		resp.ErrorLang(c, http.StatusConflict, "pod_already_submitted")
+		return
+	case errors.Is(err, trips.ErrDeliveryPINRequired):
+		resp.ErrorLang(c, http.StatusBadRequest, "delivery_pin_required")
+		return
+	case errors.Is(err, trips.ErrDeliveryPINNotFound):
+		resp.ErrorLang(c, http.StatusConflict, "delivery_pin_not_issued")
+		return
+	case errors.Is(err, trips.ErrInvalidTransition):
+		resp.ErrorLang(c, http.StatusBadRequest, "trip_not_unloading")
+		return
+	case errors.Is(err, trips.ErrNotFound):
+		resp.ErrorLang(c, http.StatusNotFound, "trip_not_found")
+		return
+	case err != nil:
+		h.logger.Error("pod submit", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	onTripStatusChanged(ctx, h.logger, h.cargoRepo, h.companies, t.CargoID, trips.StatusCompleted)
+	if obj, _ := h.cargoRepo.GetByID(ctx, t.CargoID, true); obj != nil {
+		if recipientType, recipientID, ok := cargoOwner(obj); ok {
+			h.notifier.Notify(ctx, recipientType, recipientID, notifications.KindTripDelivered, map[string]any{
+				"trip_id": t.ID.String(), "cargo_id": t.CargoID.String(), "consignee_name": pod.ConsigneeName,
+				"dispute_until": pod.DisputeUntil,
+			})
+		}
+	}
+	resp.OKLang(c, "updated", h.podResp(c, pod, nil))
+}
+
+// verifyPIN проверяет delivery_pin из формы; неверный ввод расходует попытку (DELIVERY_PIN_MAX_ATTEMPTS).
+func (h *TripPODHandler) verifyPIN(c *gin.Context, tripID uuid.UUID) bool {
+	p, err := h.repo.VerifyDeliveryPIN(c.Request.Context(), tripID, strings.TrimSpace(c.PostForm("delivery_pin")), h.pinAttempts)
+	switch {
+	case errors.Is(err, trips.ErrDeliveryPINRequired):
+		resp.ErrorLang(c, http.StatusBadRequest, "delivery_pin_required")
+		return false
+	case errors.Is(err, trips.ErrDeliveryPINInvalid):
+		resp.ErrorWithData(c, http.StatusBadRequest, resp.Msg("delivery_pin_invalid", resp.Lang(c)), gin.H{"attempts_left": p.AttemptsLeft(h.pinAttempts)})
+		return false
+	case errors.Is(err, trips.ErrDeliveryPINLocked):
+		resp.ErrorLang(c, http.StatusTooManyRequests, "delivery_pin_locked")
+		return false
+	case errors.Is(err, trips.ErrDeliveryPINNotFound):
+		resp.ErrorLang(c, http.StatusConflict, "delivery_pin_not_issued")
+		return false
+	case err != nil:
+		h.logger.Error("delivery pin verify", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return false
+	}
+	return true
+}
+
+// Get — подтверждение доставки рейса: получатель, геопозиция, время, ссылки на фото и подпись, состояние спора.
+// GET /api/trips/:id/pod
+func (h *TripPODHandler) Get(c *gin.Context) {
+	tripID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return
+	}
+	ctx := c.Request.Context()
+	pod, err := h.repo.GetPOD(ctx, tripID)
+	if err != nil {
+		h.logger.Error("pod get", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	photos, err := h.repo.PODPhotos(ctx, tripID)
+	if err != nil {
+		h.logger.Error("pod photos", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if pod == nil {
+		// фото могут быть загружены до подтверждения — отдаём их без подписи
+		items := make([]gin.H, 0, len(photos))
+		for i := range photos {
+			items = append(items, toPODPhotoResp(&photos[i]))
+		}
+		resp.OKLang(c, "ok", gin.H{"trip_id": tripID.String(), "submitted": false, "photos": items})
+		return
+	}
+	resp.OKLang(c, "ok", h.podResp(c, pod, photos))
+}
+
+// Photo отдаёт изображение фото доставки.
+// GET /api/trips/:id/pod/photos/:photoId
+func (h *TripPODHandler) Photo(c *gin.Context) {
+	tripID, err1 := uuid.Parse(c.Param("id"))
+	photoID, err2 := uuid.Parse(c.Param("photoId"))
+	if err1 != nil || err2 != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return
+	}
+	data, contentType, err := h.repo.PODPhotoData(c.Request.Context(), tripID, photoID)
+	if err != nil {
+		h.logger.Error("pod photo", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if data == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "photo_not_found")
+		return
+	}
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// Signature отдаёт изображение подписи получателя.
+// GET /api/trips/:id/pod/signature
+func (h *TripPODHandler) Signature(c *gin.Context) {
+	tripID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return
+	}
+	data, contentType, err := h.repo.PODSignature(c.Request.Context(), tripID)
+	if err != nil {
+		h.logger.Error("pod signature", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if data == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "pod_not_found")
+		return
+	}
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// DisputePODReq — причина спора по доставке.
+type DisputePODReq struct {
+	Reason string `json:"reason" binding:"required,min=3,max=2000"`
+}
+
+// DisputeByDispatcher — диспетчер-создатель груза оспаривает доставку.
+// POST /v1/dispatchers/trips/:id/pod/dispute
+func (h *TripPODHandler) DisputeByDispatcher(c *gin.Context) {
+	dispatcherID := c.MustGet(mw.CtxDispatcherID).(uuid.UUID)
+	t, obj, ok := h.tripWithCargo(c)
+	if !ok {
+		return
+	}
+	if obj.CreatedByType == nil || *obj.CreatedByType != "DISPATCHER" || obj.CreatedByID == nil || *obj.CreatedByID != dispatcherID {
+		resp.ErrorLang(c, http.StatusForbidden, "not_your_cargo")
+		return
+	}
+	h.dispute(c, t, notifications.RecipientDispatcher, dispatcherID)
+}
+
+// DisputeByCompany — компания груза оспаривает доставку.
+// POST /v1/trips/:id/pod/dispute
+func (h *TripPODHandler) DisputeByCompany(c *gin.Context) {
+	companyID, ok := appUserCompanyID(c)
+	if !ok {
+		resp.ErrorLang(c, http.StatusForbidden, "company_not_selected")
+		return
+	}
+	t, obj, ok := h.tripWithCargo(c)
+	if !ok {
+		return
+	}
+	if obj.CompanyID == nil || *obj.CompanyID != companyID {
+		resp.ErrorLang(c, http.StatusForbidden, "not_your_cargo")
+		return
+	}
+	h.dispute(c, t, notifications.RecipientCompany, companyID)
+}
+
+func (h *TripPODHandler) dispute(c *gin.Context, t *trips.Trip, byType string, byID uuid.UUID) {
+	var req DisputePODReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+		return
+	}
+	ctx := c.Request.Context()
+	pod, err := h.repo.DisputePOD(ctx, t.ID, byType, byID, strings.TrimSpace(req.Reason))
+	switch {
+	case errors.Is(err, trips.ErrPODNotFound):
+		resp.ErrorLang(c, http.StatusNotFound, "pod_not_found")
+		return
+	case errors.Is(err, trips.ErrPODAlreadyDisputed):
+		resp.ErrorLang(c, http.StatusConflict, "pod_already_disputed")
+		return
+	case errors.Is(err, trips.ErrPODDisputeClosed):
+		resp.ErrorLang(c, http.StatusConflict, "pod_dispute_window_closed")
+		return
+	case err != nil:
+		h.logger.Error("pod dispute", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	h.notifier.Notify(ctx, notifications.RecipientDriver, pod.DriverID, notifications.KindPODDisputed, map[string]any{
+		"trip_id": t.ID.String(), "cargo_id": t.CargoID.String(), "reason": pod.DisputeReason,
+	})
+	resp.OKLang(c, "updated", h.podResp(c, pod, nil))
+}
+
+func (h *TripPODHandler) tripWithCargo(c *gin.Context) (*trips.Trip, *cargo.Cargo, bool) {
+	tripID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return nil, nil, false
+	}
+	t, err := h.repo.GetByID(c.Request.Context(), tripID)
+	if err != nil || t == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "trip_not_found")
+		return nil, nil, false
+	}
+	obj, _ := h.cargoRepo.GetByID(c.Request.Context(), t.CargoID, true)
+	if obj == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "cargo_not_found")
+		return nil, nil, false
+	}
+	return t, obj, true
+}
+
+// podResp — ответ по POD; photos == nil — загрузить из БД.
+func (h *TripPODHandler) podResp(c *gin.Context, pod *trips.POD, photos []trips.PODPhoto) gin.H {
+	if photos == nil {
+		var err error
+		if photos, err = h.repo.PODPhotos(c.Request.Context(), pod.TripID); err != nil {
+			h.logger.Error("pod photos", zap.Error(err))
+		}
+	}
+	items := make([]gin.H, 0, len(photos))
+	for i := range photos {
+		items = append(items, toPODPhotoResp(&photos[i]))
+	}
+	return gin.H{
+		"trip_id":          pod.TripID.String(),
+		"submitted":        true,
+		"status":           pod.Status,
+		"driver_id":        pod.DriverID.String(),
+		"consignee_name":   pod.ConsigneeName,
+		"lat":              pod.Lat,
+		"lng":              pod.Lng,
+		"delivered_at":     pod.DeliveredAt,
+		"dispute_until":    pod.DisputeUntil,
+		"can_dispute":      pod.CanDispute(time.Now()),
+		"dispute_reason":   pod.DisputeReason,
+		"disputed_by_type": pod.DisputedByType,
+		"disputed_at":      pod.DisputedAt,
+		"signature_url":    "/api/trips/" + pod.TripID.String() + "/pod/signature",
+		"photos":           items,
+	}
+}
+
+func toPODPhotoResp(p *trips.PODPhoto) gin.H {
+	return gin.H{
+		"id": p.ID.String(), "content_type": p.ContentType, "lat": p.Lat, "lng": p.Lng,
+		"taken_at": p.TakenAt, "created_at": p.CreatedAt,
+		"url": "/api/trips/" + p.TripID.String() + "/pod/photos/" + p.ID.String(),
+	}
+}
+
+// readPODImage читает изображение (jpeg/png) из multipart-поля field.
+func readPODImage(c *gin.Context, field string, maxSize int64) ([]byte, string, bool) {
+	file, err := c.FormFile(field)
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "photo_file_required")
+		return nil, "", false
+	}
+	if file.Size > maxSize {
+		resp.ErrorLang(c, http.StatusBadRequest, "file_too_large")
+		return nil, "", false
+	}
+	contentType := file.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "image/jpeg"
+	}
+	if !allowedPODImageTypes[contentType] {
+		resp.ErrorLang(c, http.StatusBadRequest, "allowed_image_types")
+		return nil, "", false
+	}
+	data, err := readMultipartFile(file)
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "cannot_read_file")
+		return nil, "", false
+	}
+	return data, contentType, true
+}
+
+func readMultipartFile(file *multipart.FileHeader) ([]byte, error) {
+	f, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// podGeo читает lat/lng из формы; required — координаты обязательны.
+func podGeo(c *gin.Context, required bool) (lat, lng *float64, ok bool) {
+	latStr, lngStr := strings.TrimSpace(c.PostForm("lat")), strings.TrimSpace(c.PostForm("lng"))
+	if latStr == "" && lngStr == "" && !required {
+		return nil, nil, true
+	}
+	la, err1 := strconv.ParseFloat(latStr, 64)
+	ln, err2 := strconv.ParseFloat(lngStr, 64)
+	if err1 != nil || err2 != nil || la < -90 || la > 90 || ln < -180 || ln > 180 {
+		resp.ErrorLang(c, http.StatusBadRequest, "pod_invalid_geo")
+		return nil, nil, false
+	}
+	return &la, &ln, true
+}
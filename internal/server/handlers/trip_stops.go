@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"sarbonNew/internal/cargo"
+	"sarbonNew/internal/companies"
+	"sarbonNew/internal/notifications"
+	"sarbonNew/internal/reference"
+	"sarbonNew/internal/server/resp"
+	"sarbonNew/internal/trips"
+)
+
+// TripStopsHandler — прибытие и отъезд водителя по каждой точке маршрута (многоточечные рейсы):
+// какие точки пройдены, время на каждой точке; статус рейса выводится из прохождения точек.
+type TripStopsHandler struct {
+	logger    *zap.Logger
+	repo      *trips.Repo
+	cargoRepo *cargo.Repo
+	companies *companies.Repo
+}
+
+// NewTripStopsHandler creates the handler.
+func NewTripStopsHandler(logger *zap.Logger, repo *trips.Repo, cargoRepo *cargo.Repo, companiesRepo *companies.Repo) *TripStopsHandler {
+	return &TripStopsHandler{logger: logger, repo: repo, cargoRepo: cargoRepo, companies: companiesRepo}
+}
+
+// StopCheckpointReq — координаты водителя при отметке (необязательно).
+type StopCheckpointReq struct {
+	Lat *float64 `json:"lat"`
+	Lng *float64 `json:"lng"`
+}
+
+// ListMy — точки маршрута рейса с отметками (водитель); для консолидированного рейса — объединённый маршрут всех грузов.
+// GET /v1/driver/trips/:id/stops
+func (h *TripStopsHandler) ListMy(c *gin.Context) {
+	t, ok := driverOwnTrip(c, h.repo)
+	if !ok {
+		return
+	}
+	h.list(c, t, true)
+}
+
+// List — точки маршрута рейса с отметками (создатель груза).
+// GET /v1/dispatchers/trips/:id/stops, GET /v1/trips/:id/stops
+func (h *TripStopsHandler) List(c *gin.Context) {
+	t, _, _, ok := cargoCreatorTrip(c, h.repo, h.cargoRepo)
+	if !ok {
+		return
+	}
+	h.list(c, t, false)
+}
+
+// Arrive — водитель прибыл на точку маршрута. Точки проходятся по порядку: прибыть можно на следующую,
+// уехав с предыдущей; в консолидированном рейсе порядок — объединённый, статус каждого рейса группы выводится
+// из точек его груза. Прибытие на первую погрузку переводит рейс в LOADING, на конечную выгрузку — в UNLOADING;
+// прибытие на точку CUSTOMS ставит таможню в очередь (QUEUED).
+// POST /v1/driver/trips/:id/stops/:pointId/arrive
+func (h *TripStopsHandler) Arrive(c *gin.Context) {
+	h.checkpoint(c, true)
+}
+
+// Depart — водитель уехал с точки маршрута; отъезд с последней погрузки переводит рейс в EN_ROUTE.
+// POST /v1/driver/trips/:id/stops/:pointId/depart
+func (h *TripStopsHandler) Depart(c *gin.Context) {
+	h.checkpoint(c, false)
+}
+
+func (h *TripStopsHandler) checkpoint(c *gin.Context, arrive bool) {
+	t, ok := driverOwnTrip(c, h.repo)
+	if !ok {
+		return
+	}
+	pointID, err := uuid.Parse(c.Param("pointId"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return
+	}
+	var req StopCheckpointReq
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+			return
+		}
+	}
+	if (req.Lat == nil) != (req.Lng == nil) || (req.Lat != nil && (*req.Lat < -90 || *req.Lat > 90 || *req.Lng < -180 || *req.Lng > 180)) {
+		resp.ErrorLang(c, http.StatusBadRequest, "pod_invalid_geo")
+		return
+	}
+	ctx := c.Request.Context()
+	g, progress, ok := h.progress(c, t)
+	if !ok {
+		return
+	}
+	// в консолидированном рейсе точку проходит рейс, везущий её груз
+	member := t
+	if i := progress.Index(pointID); i >= 0 {
+		if m := g.Member(g.Points[i].CargoID); m != nil {
+			member = m
+		}
+	}
+	switch member.Status {
+	case trips.StatusAssigned, trips.StatusLoading, trips.StatusEnRoute, trips.StatusUnloading:
+	default:
+		resp.ErrorLang(c, http.StatusBadRequest, "trip_not_active")
+		return
+	}
+	if arrive {
+		err = progress.CanArrive(pointID)
+	} else {
+		err = progress.CanDepart(pointID)
+	}
+	if err == nil {
+		if arrive {
+			_, err = h.repo.ArriveStop(ctx, g.Host.ID, pointID, req.Lat, req.Lng)
+		} else {
+			_, err = h.repo.DepartStop(ctx, g.Host.ID, pointID, req.Lat, req.Lng)
+		}
+	}
+	if err == nil && arrive && strings.EqualFold(g.Points[progress.Index(pointID)].Type, "CUSTOMS") {
+		// прибытие на таможню — очередь, если таможня на точке ещё не начата
+		if _, err := h.repo.SetCustomsStatus(ctx, member.ID, pointID, trips.CustomsQueued, nil, notifications.RecipientDriver, *t.DriverID); err != nil && !errors.Is(err, trips.ErrCustomsTransition) {
+			h.logger.Warn("customs queue on arrival", zap.Error(err))
+		}
+	}
+	if err != nil {
+		if key := stopErrorKey(err); key != "" {
+			resp.ErrorLang(c, http.StatusConflict, key)
+			return
+		}
+		if errors.Is(err, trips.ErrStopUnknownPoint) {
+			resp.ErrorLang(c, http.StatusNotFound, "route_point_not_found")
+			return
+		}
+		h.logger.Error("trip stop checkpoint", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	stops, err := h.repo.Stops(ctx, g.Host.ID)
+	if err != nil {
+		h.logger.Error("trip stops list", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	// статус каждого рейса группы — по точкам его груза
+	for i := range g.Members {
+		m := &g.Members[i]
+		own := trips.NewStopProgress(toStopPoints(g.CargoPoints(m.CargoID)), stops)
+		for _, status := range trips.StatusSteps(m.Status, own.Status()) {
+			if err := h.repo.SetStatus(ctx, m.ID, status); err != nil {
+				h.logger.Warn("trip status from stops", zap.Error(err), zap.String("trip_id", m.ID.String()), zap.String("status", status))
+				break
+			}
+			m.Status = status
+			onTripStatusChanged(ctx, h.logger, h.cargoRepo, h.companies, m.CargoID, status)
+		}
+		if m.ID == t.ID {
+			t.Status = m.Status
+		}
+	}
+	progress = trips.NewStopProgress(progress.Points, stops)
+	resp.OKLang(c, "updated", toStopsResp(t, g.Points, progress, resp.Lang(c)))
+}
+
+func (h *TripStopsHandler) list(c *gin.Context, t *trips.Trip, merged bool) {
+	g, progress, ok := h.progress(c, t)
+	if !ok {
+		return
+	}
+	points := g.Points
+	if !merged {
+		// создателю груза — только точки его груза
+		points = g.CargoPoints(t.CargoID)
+		progress = trips.NewStopProgress(toStopPoints(points), stopsOf(progress))
+	}
+	resp.OKLang(c, "ok", toStopsResp(t, points, progress, resp.Lang(c)))
+}
+
+// progress загружает объединённый маршрут группы рейса и отметки рейса-носителя.
+func (h *TripStopsHandler) progress(c *gin.Context, t *trips.Trip) (*tripGroup, trips.StopProgress, bool) {
+	ctx := c.Request.Context()
+	g, err := loadTripGroup(ctx, h.repo, h.cargoRepo, t)
+	if err != nil {
+		h.logger.Error("trip stops route points", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "failed_to_get_cargo")
+		return nil, trips.StopProgress{}, false
+	}
+	stops, err := h.repo.Stops(ctx, g.Host.ID)
+	if err != nil {
+		h.logger.Error("trip stops list", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return nil, trips.StopProgress{}, false
+	}
+	return g, trips.NewStopProgress(toStopPoints(g.Points), stops), true
+}
+
+// stopsOf — отметки из прогресса (для пересчёта по подмножеству точек).
+func stopsOf(p trips.StopProgress) []trips.Stop {
+	var out []trips.Stop
+	for _, s := range p.Stops {
+		if s != nil {
+			out = append(out, *s)
+		}
+	}
+	return out
+}
+
+func stopErrorKey(err error) string {
+	switch {
+	case errors.Is(err, trips.ErrStopAlreadyArrived):
+		return "stop_already_arrived"
+	case errors.Is(err, trips.ErrStopNotArrived):
+		return "stop_not_arrived"
+	case errors.Is(err, trips.ErrStopOutOfOrder):
+		return "stop_out_of_order"
+	case errors.Is(err, trips.ErrStopNotDeparted):
+		return "stop_not_departed"
+	}
+	return ""
+}
+
+func toStopPoints(points []cargo.RoutePoint) []trips.StopPoint {
+	out := make([]trips.StopPoint, 0, len(points))
+	for _, rp := range points {
+		out = append(out, trips.StopPoint{ID: rp.ID, Type: rp.Type, IsMainLoad: rp.IsMainLoad, IsMainUnload: rp.IsMainUnload})
+	}
+	return out
+}
+
+// toStopsResp — точки маршрута с состоянием PENDING/ARRIVED/DEPARTED и временем на точке (dwell_minutes).
+func toStopsResp(t *trips.Trip, points []cargo.RoutePoint, p trips.StopProgress, lang string) gin.H {
+	now := time.Now()
+	items := make([]gin.H, 0, len(points))
+	for i, rp := range points {
+		item := gin.H{
+			"route_point_id": rp.ID.String(),
+			"order":          rp.PointOrder,
+			"type":           rp.Type,
+			"type_label":     reference.RefLabel("cargo.route_point_type", rp.Type, lang),
+			"city_code":      rp.CityCode,
+			"address":        rp.Address,
+			"lat":            rp.Lat,
+			"lng":            rp.Lng,
+			"is_main_load":   rp.IsMainLoad,
+			"is_main_unload": rp.IsMainUnload,
+			"state":          "PENDING",
+			"arrived_at":     nil,
+			"departed_at":    nil,
+			"dwell_minutes":  nil,
+		}
+		if s := p.Stops[i]; s != nil {
+			item["state"] = "ARRIVED"
+			if s.DepartedAt != nil {
+				item["state"] = "DEPARTED"
+			}
+			item["arrived_at"], item["departed_at"] = s.ArrivedAt, s.DepartedAt
+			item["arrived_lat"], item["arrived_lng"] = s.ArrivedLat, s.ArrivedLng
+			item["departed_lat"], item["departed_lng"] = s.DepartedLat, s.DepartedLng
+			item["dwell_minutes"] = int(s.Dwell(now).Minutes())
+		}
+		items = append(items, item)
+	}
+	res := gin.H{
+		"trip_id":                t.ID.String(),
+		"status":                 t.Status,
+		"status_label":           reference.RefLabel("cargo.trip_status", t.Status, lang),
+		"stops":                  items,
+		"done":                   p.Done(),
+		"total":                  len(points),
+		"current_route_point_id": nil,
+		"next_route_point_id":    nil,
+	}
+	if i := p.Current(); i >= 0 {
+		res["current_route_point_id"] = points[i].ID.String()
+	}
+	if i := p.Next(); i >= 0 {
+		res["next_route_point_id"] = points[i].ID.String()
+	}
+	return res
+}
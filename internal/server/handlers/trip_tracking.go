@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"sarbonNew/internal/cargo"
+	"sarbonNew/internal/drivers"
+	"sarbonNew/internal/reference"
+	"sarbonNew/internal/routing"
+	"sarbonNew/internal/server/resp"
+	"sarbonNew/internal/tracking"
+	"sarbonNew/internal/trips"
+)
+
+// TripTrackingHandler — публичные ссылки отслеживания рейса для получателя и конечного клиента (без аккаунта).
+// Публичный ответ не содержит данных водителя и контактных телефонов.
+type TripTrackingHandler struct {
+	logger    *zap.Logger
+	links     *tracking.Repo
+	trips     *trips.Repo
+	cargoRepo *cargo.Repo
+	drivers   *drivers.Repo
+	routes    *routing.Estimator
+	ttl       time.Duration
+	maxTTL    time.Duration
+	baseURL   string
+}
+
+// NewTripTrackingHandler creates the handler; ttl/maxTTL — TRACKING_LINK_TTL_HOURS / TRACKING_LINK_MAX_TTL_HOURS,
+// baseURL — TRACKING_LINK_BASE_URL (страница фронтенда; пусто — ссылка на публичный API).
+func NewTripTrackingHandler(logger *zap.Logger, links *tracking.Repo, tripsRepo *trips.Repo, cargoRepo *cargo.Repo, driversRepo *drivers.Repo, routes *routing.Estimator, ttl, maxTTL time.Duration, baseURL string) *TripTrackingHandler {
+	return &TripTrackingHandler{logger: logger, links: links, trips: tripsRepo, cargoRepo: cargoRepo, drivers: driversRepo, routes: routes, ttl: ttl, maxTTL: maxTTL, baseURL: baseURL}
+}
+
+// CreateTrackingLinkReq — срок действия ссылки в часах (0 — по умолчанию).
+type CreateTrackingLinkReq struct {
+	TTLHours int `json:"ttl_hours" binding:"omitempty,min=1"`
+}
+
+// Create — создатель груза создаёт публичную ссылку отслеживания рейса.
+// POST /v1/dispatchers/trips/:id/tracking-links, POST /v1/trips/:id/tracking-links
+func (h *TripTrackingHandler) Create(c *gin.Context) {
+	t, byType, byID, ok := cargoCreatorTrip(c, h.trips, h.cargoRepo)
+	if !ok {
+		return
+	}
+	var req CreateTrackingLinkReq
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+			return
+		}
+	}
+	if t.Status == trips.StatusCancelled {
+		resp.ErrorLang(c, http.StatusConflict, "trip_not_active")
+		return
+	}
+	ttl := tracking.ClampTTL(time.Duration(req.TTLHours)*time.Hour, h.ttl, h.maxTTL)
+	l, err := h.links.Create(c.Request.Context(), t.ID, byType, byID, ttl)
+	if err != nil {
+		h.logger.Error("tracking link create", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	resp.SuccessLang(c, http.StatusCreated, "created", h.linkResp(l))
+}
+
+// List — ссылки отслеживания рейса (включая отозванные и истёкшие).
+// GET /v1/dispatchers/trips/:id/tracking-links, GET /v1/trips/:id/tracking-links
+func (h *TripTrackingHandler) List(c *gin.Context) {
+	t, _, _, ok := cargoCreatorTrip(c, h.trips, h.cargoRepo)
+	if !ok {
+		return
+	}
+	list, err := h.links.ListByTrip(c.Request.Context(), t.ID)
+	if err != nil {
+		h.logger.Error("tracking links list", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	items := make([]gin.H, 0, len(list))
+	for i := range list {
+		items = append(items, h.linkResp(&list[i]))
+	}
+	resp.OKLang(c, "ok", gin.H{"items": items})
+}
+
+// Revoke — отзыв ссылки; публичная страница по ней сразу перестаёт открываться.
+// DELETE /v1/dispatchers/trips/:id/tracking-links/:linkId, DELETE /v1/trips/:id/tracking-links/:linkId
+func (h *TripTrackingHandler) Revoke(c *gin.Context) {
+	t, _, _, ok := cargoCreatorTrip(c, h.trips, h.cargoRepo)
+	if !ok {
+		return
+	}
+	linkID, err := uuid.Parse(c.Param("linkId"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return
+	}
+	revoked, err := h.links.Revoke(c.Request.Context(), t.ID, linkID)
+	if err != nil {
+		h.logger.Error("tracking link revoke", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if !revoked {
+		resp.ErrorLang(c, http.StatusNotFound, "tracking_link_not_found")
+		return
+	}
+	resp.OKLang(c, "ok", gin.H{"status": "revoked"})
+}
+
+// Track — публичная страница отслеживания: статус, история статусов, последняя позиция, ETA и точки маршрута.
+// GET /public/track/:token — без заголовков и авторизации; X-Language необязателен.
+func (h *TripTrackingHandler) Track(c *gin.Context) {
+	ctx := c.Request.Context()
+	l, err := h.links.GetByToken(ctx, c.Param("token"))
+	if err != nil {
+		h.logger.Error("tracking link get", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	if l == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "tracking_link_not_found")
+		return
+	}
+	if !l.Active(time.Now()) {
+		resp.ErrorLang(c, http.StatusGone, "tracking_link_expired")
+		return
+	}
+	t, err := h.trips.GetByID(ctx, l.TripID)
+	if err != nil || t == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "trip_not_found")
+		return
+	}
+	obj, _ := h.cargoRepo.GetByID(ctx, t.CargoID, true)
+	points, err := h.cargoRepo.GetRoutePoints(ctx, t.CargoID)
+	if err != nil || obj == nil {
+		resp.ErrorLang(c, http.StatusInternalServerError, "failed_to_get_cargo")
+		return
+	}
+	history, err := h.trips.History(ctx, t.ID)
+	if err != nil {
+		h.logger.Error("tracking trip history", zap.Error(err))
+	}
+	stopsTripID := t.ID
+	if t.ParentTripID != nil {
+		// отметки консолидированного рейса хранятся у рейса-носителя
+		stopsTripID = *t.ParentTripID
+	}
+	stops, err := h.trips.Stops(ctx, stopsTripID)
+	if err != nil {
+		h.logger.Error("tracking trip stops", zap.Error(err))
+	}
+	if err := h.links.Touch(ctx, l.ID); err != nil {
+		h.logger.Warn("tracking link touch", zap.Error(err))
+	}
+	lang := resp.Lang(c)
+	res := gin.H{
+		"status":       t.Status,
+		"status_label": reference.RefLabel("cargo.trip_status", t.Status, lang),
+		"updated_at":   t.UpdatedAt,
+		"history":      toTrackingHistory(history, lang),
+		"route_points": toTrackingPoints(points, stops, lang),
+		"position":     nil,
+		"eta":          nil,
+		"delivered_at": nil,
+		"expires_at":   l.ExpiresAt,
+	}
+	if pod, _ := h.trips.GetPOD(ctx, t.ID); pod != nil {
+		res["delivered_at"] = pod.DeliveredAt
+	}
+	if !tripTrackable(t) {
+		resp.OKLang(c, "ok", res)
+		return
+	}
+	drv, _ := h.drivers.FindByID(ctx, *t.DriverID)
+	if drv == nil || drv.Latitude == nil || drv.Longitude == nil {
+		resp.OKLang(c, "ok", res)
+		return
+	}
+	from := routing.Point{Lat: *drv.Latitude, Lng: *drv.Longitude}
+	res["position"] = gin.H{"lat": from.Lat, "lng": from.Lng, "updated_at": drv.LastOnlineAt}
+	est, at := h.routes.ETA(obj.TruckType, from, toRoutingPoints(remainingRoutePoints(t.Status, points, stops)), time.Now())
+	res["eta"] = gin.H{
+		"remaining_distance_km": est.DistanceKm,
+		"remaining_minutes":     int(est.Total().Minutes()),
+		"eta":                   at,
+	}
+	resp.OKLang(c, "ok", res)
+}
+
+func (h *TripTrackingHandler) linkResp(l *tracking.Link) gin.H {
+	url := "/public/track/" + l.Token
+	if h.baseURL != "" {
+		url = h.baseURL + "/" + l.Token
+	}
+	return gin.H{
+		"id":              l.ID.String(),
+		"trip_id":         l.TripID.String(),
+		"token":           l.Token,
+		"url":             url,
+		"created_by_type": l.CreatedByType,
+		"expires_at":      l.ExpiresAt,
+		"revoked_at":      l.RevokedAt,
+		"active":          l.Active(time.Now()),
+		"views":           l.Views,
+		"last_viewed_at":  l.LastViewedAt,
+		"created_at":      l.CreatedAt,
+	}
+}
+
+// tripTrackable — позиция и ETA показываются только для рейса в работе с назначенным водителем.
+func tripTrackable(t *trips.Trip) bool {
+	if t.DriverID == nil {
+		return false
+	}
+	switch t.Status {
+	case trips.StatusAssigned, trips.StatusLoading, trips.StatusEnRoute, trips.StatusUnloading:
+		return true
+	}
+	return false
+}
+
+func toTrackingHistory(history []trips.StatusChange, lang string) []gin.H {
+	out := make([]gin.H, 0, len(history))
+	for _, h := range history {
+		out = append(out, gin.H{
+			"status": h.ToStatus, "status_label": reference.RefLabel("cargo.trip_status", h.ToStatus, lang), "at": h.ChangedAt,
+		})
+	}
+	return out
+}
+
+// toTrackingPoints — точки маршрута без комментариев и ориентиров для водителя (там бывают телефоны)
+// с временем прибытия и отъезда водителя.
+func toTrackingPoints(points []cargo.RoutePoint, stops []trips.Stop, lang string) []gin.H {
+	progress := trips.NewStopProgress(toStopPoints(points), stops)
+	out := make([]gin.H, 0, len(points))
+	for i, rp := range points {
+		var arrivedAt, departedAt any
+		if s := progress.Stops[i]; s != nil {
+			arrivedAt, departedAt = s.ArrivedAt, s.DepartedAt
+		}
+		out = append(out, gin.H{
+			"order":          rp.PointOrder,
+			"type":           rp.Type,
+			"type_label":     reference.RefLabel("cargo.route_point_type", rp.Type, lang),
+			"city_code":      rp.CityCode,
+			"city_name":      trackingCityName(rp.CityCode, lang),
+			"address":        rp.Address,
+			"lat":            rp.Lat,
+			"lng":            rp.Lng,
+			"is_main_load":   rp.IsMainLoad,
+			"is_main_unload": rp.IsMainUnload,
+			"arrived_at":     arrivedAt,
+			"departed_at":    departedAt,
+		})
+	}
+	return out
+}
+
+func trackingCityName(code, lang string) string {
+	if code == "" {
+		return ""
+	}
+	city, err := reference.FindCity(code)
+	if err != nil {
+		return code
+	}
+	if lang != "ru" && city.NameEn != nil && *city.NameEn != "" {
+		return *city.NameEn
+	}
+	return city.NameRu
+}
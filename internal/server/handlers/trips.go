@@ -1,14 +1,19 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 
 	"sarbonNew/internal/cargo"
+	"sarbonNew/internal/companies"
+	"sarbonNew/internal/drivers"
+	"sarbonNew/internal/routing"
 	"sarbonNew/internal/server/mw"
 	"sarbonNew/internal/server/resp"
 	"sarbonNew/internal/trips"
@@ -18,10 +23,13 @@ type TripsHandler struct {
 	logger    *zap.Logger
 	repo      *trips.Repo
 	cargoRepo *cargo.Repo
+	companies *companies.Repo
+	drivers   *drivers.Repo
+	routes    *routing.Estimator
 }
 
-func NewTripsHandler(logger *zap.Logger, repo *trips.Repo, cargoRepo *cargo.Repo) *TripsHandler {
-	return &TripsHandler{logger: logger, repo: repo, cargoRepo: cargoRepo}
+func NewTripsHandler(logger *zap.Logger, repo *trips.Repo, cargoRepo *cargo.Repo, companiesRepo *companies.Repo, driversRepo *drivers.Repo, routes *routing.Estimator) *TripsHandler {
+	return &TripsHandler{logger: logger, repo: repo, cargoRepo: cargoRepo, companies: companiesRepo, drivers: driversRepo, routes: routes}
 }
 
 // Get returns trip by id.
@@ -149,7 +157,7 @@ func (h *TripsHandler) DriverReject(c *gin.Context) {
 
 // PatchStatusReq body for PATCH /api/trips/:id/status (driver: loading, en_route, unloading, completed).
 type PatchStatusReq struct {
-	Status string `json:"status" binding:"required,oneof=LOADING EN_ROUTE UNLOADING COMPLETED CANCELLED"`
+	Status string `json:"status" binding:"required,oneof=LOADING EN_ROUTE UNLOADING COMPLETED"`
 }
 
 // PatchStatus updates trip status (driver only).
@@ -171,6 +179,10 @@ func (h *TripsHandler) PatchStatus(c *gin.Context) {
 		return
 	}
 	if err := h.repo.SetStatus(c.Request.Context(), tripID, req.Status); err != nil {
+		if err == trips.ErrPODRequired {
+			resp.ErrorLang(c, http.StatusBadRequest, "pod_required")
+			return
+		}
 		if err == trips.ErrInvalidTransition {
 			resp.ErrorLang(c, http.StatusBadRequest, "invalid_status_transition")
 			return
@@ -178,14 +190,113 @@ func (h *TripsHandler) PatchStatus(c *gin.Context) {
 		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
 		return
 	}
-	if h.cargoRepo != nil {
-		if req.Status == trips.StatusLoading {
-			_ = h.cargoRepo.SetCargoStatusInProgress(c.Request.Context(), t.CargoID)
-		} else if req.Status == trips.StatusCompleted {
-			_ = h.cargoRepo.SetCargoStatusCompleted(c.Request.Context(), t.CargoID)
+	onTripStatusChanged(c.Request.Context(), h.logger, h.cargoRepo, h.companies, t.CargoID, req.Status)
+	resp.OKLang(c, "updated", gin.H{"status": req.Status})
+}
+
+// onTripStatusChanged — последствия смены статуса рейса: статус груза и статистика заказов компании.
+// Отмена рейса груз не отменяет (он возвращается в поиск), отменённый заказ фиксирует отмена груза.
+func onTripStatusChanged(ctx context.Context, logger *zap.Logger, cargoRepo *cargo.Repo, companiesRepo *companies.Repo, cargoID uuid.UUID, status string) {
+	if cargoRepo != nil {
+		if status == trips.StatusLoading {
+			_ = cargoRepo.SetCargoStatusInProgress(ctx, cargoID)
+		} else if status == trips.StatusCompleted {
+			_ = cargoRepo.SetCargoStatusCompleted(ctx, cargoID)
+		}
+	}
+	if companiesRepo != nil && status == trips.StatusCompleted {
+		if err := companiesRepo.RecordCargoOutcome(ctx, cargoID); err != nil {
+			logger.Error("company order stats", zap.Error(err), zap.String("cargo_id", cargoID.String()))
 		}
 	}
-	resp.OKLang(c, "updated", gin.H{"status": req.Status})
+}
+
+// ETA GET /api/trips/:id/eta — оставшееся расстояние и время прибытия от текущей позиции водителя.
+// ASSIGNED: путь через все точки (водитель едет на погрузку); LOADING/EN_ROUTE: точки после основной погрузки;
+// UNLOADING: водитель на месте.
+func (h *TripsHandler) ETA(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return
+	}
+	ctx := c.Request.Context()
+	t, err := h.repo.GetByID(ctx, id)
+	if err != nil || t == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "trip_not_found")
+		return
+	}
+	switch t.Status {
+	case trips.StatusAssigned, trips.StatusLoading, trips.StatusEnRoute, trips.StatusUnloading:
+	default:
+		resp.ErrorLang(c, http.StatusBadRequest, "trip_not_active")
+		return
+	}
+	if t.DriverID == nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "trip_not_active")
+		return
+	}
+	drv, _ := h.drivers.FindByID(ctx, *t.DriverID)
+	if drv == nil || drv.Latitude == nil || drv.Longitude == nil {
+		resp.ErrorLang(c, http.StatusConflict, "driver_location_unknown")
+		return
+	}
+	obj, _ := h.cargoRepo.GetByID(ctx, t.CargoID, true)
+	g, err := loadTripGroup(ctx, h.repo, h.cargoRepo, t)
+	if err != nil || obj == nil {
+		resp.ErrorLang(c, http.StatusInternalServerError, "failed_to_get_cargo")
+		return
+	}
+	stops, err := h.repo.Stops(ctx, g.Host.ID)
+	if err != nil {
+		h.logger.Warn("trip eta stops", zap.Error(err))
+	}
+	// в консолидированном рейсе — до последней точки груза этого рейса по объединённому маршруту
+	remaining := remainingRoutePoints(t.Status, g.Points, stops)
+	for i := len(remaining) - 1; i >= 0 && remaining[i].CargoID != t.CargoID; i-- {
+		remaining = remaining[:i]
+	}
+	from := routing.Point{Lat: *drv.Latitude, Lng: *drv.Longitude}
+	now := time.Now()
+	est, at := h.routes.ETA(obj.TruckType, from, toRoutingPoints(remaining), now)
+	res := gin.H{
+		"trip_id":               t.ID.String(),
+		"status":                t.Status,
+		"location_updated_at":   drv.LastOnlineAt,
+		"remaining_distance_km": est.DistanceKm,
+		"remaining_minutes":     int(est.Total().Minutes()),
+		"customs_minutes":       int(est.Customs.Minutes()),
+		"rest_minutes":          int(est.Rest.Minutes()),
+		"eta":                   at,
+		"remaining_points":      len(remaining),
+	}
+	resp.OKLang(c, "ok", res)
+}
+
+// remainingRoutePoints — точки маршрута, которые водителю ещё предстоит пройти: по отметкам на точках (trip_stops),
+// а если их нет — по статусу рейса.
+func remainingRoutePoints(status string, points []cargo.RoutePoint, stops []trips.Stop) []cargo.RoutePoint {
+	if len(stops) > 0 {
+		var out []cargo.RoutePoint
+		for _, i := range trips.NewStopProgress(toStopPoints(points), stops).Remaining() {
+			out = append(out, points[i])
+		}
+		return out
+	}
+	switch status {
+	case trips.StatusAssigned:
+		return points
+	case trips.StatusLoading, trips.StatusEnRoute:
+		for i, rp := range points {
+			if rp.IsMainLoad {
+				return points[i+1:]
+			}
+		}
+		if len(points) > 0 {
+			return points[1:]
+		}
+	}
+	return nil
 }
 
 func toTripResp(t *trips.Trip) gin.H {
@@ -200,5 +311,28 @@ func toTripResp(t *trips.Trip) gin.H {
 	if t.DriverID != nil {
 		res["driver_id"] = t.DriverID.String()
 	}
+	if t.AgreedPrice != nil {
+		res["agreed_price"] = *t.AgreedPrice
+		res["agreed_currency"] = t.AgreedCurrency
+	}
+	if t.ParentTripID != nil {
+		res["parent_trip_id"] = t.ParentTripID.String()
+	}
 	return res
 }
+
+// driverOwnTrip загружает рейс по :id; рейс должен быть назначен текущему водителю (маршруты /v1/driver/trips/:id/...).
+func driverOwnTrip(c *gin.Context, tripsRepo *trips.Repo) (*trips.Trip, bool) {
+	driverID := c.MustGet(mw.CtxDriverID).(uuid.UUID)
+	tripID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return nil, false
+	}
+	t, _ := tripsRepo.GetByID(c.Request.Context(), tripID)
+	if t == nil || t.DriverID == nil || *t.DriverID != driverID {
+		resp.ErrorLang(c, http.StatusForbidden, "trip not found or not assigned to you")
+		return nil, false
+	}
+	return t, true
+}
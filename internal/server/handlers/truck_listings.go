@@ -0,0 +1,603 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"sarbonNew/internal/cargo"
+	"sarbonNew/internal/drivers"
+	"sarbonNew/internal/notifications"
+	"sarbonNew/internal/reference"
+	"sarbonNew/internal/routing"
+	"sarbonNew/internal/server/mw"
+	"sarbonNew/internal/server/resp"
+	"sarbonNew/internal/trucklistings"
+)
+
+const (
+	defaultListingSearchRadiusKm = 150
+	maxListingSearchRadiusKm     = 1000
+	maxListingDestinations       = 10
+)
+
+// TruckListingsHandler — объявления о свободной машине: водитель или его диспетчер публикует машину
+// (тип кузова, вместимость, где и когда свободна, желаемые направления), грузоотправитель ищет по маршруту и радиусу
+// и предлагает груз — это оффер от грузоотправителя, дальше обычный торг и рейс.
+type TruckListingsHandler struct {
+	logger        *zap.Logger
+	repo          *trucklistings.Repo
+	cargoRepo     *cargo.Repo
+	drivers       *drivers.Repo
+	notifier      *notifications.Notifier
+	offerValidity time.Duration
+}
+
+// NewTruckListingsHandler creates the handler; offerValidity — срок предложения груза по умолчанию (0 — без срока).
+func NewTruckListingsHandler(logger *zap.Logger, repo *trucklistings.Repo, cargoRepo *cargo.Repo, driversRepo *drivers.Repo, notifier *notifications.Notifier, offerValidity time.Duration) *TruckListingsHandler {
+	return &TruckListingsHandler{logger: logger, repo: repo, cargoRepo: cargoRepo, drivers: driversRepo, notifier: notifier, offerValidity: offerValidity}
+}
+
+// TruckListingReq — тело создания и изменения объявления. Место — from_lat/from_lng или from_city_code (координаты города).
+type TruckListingReq struct {
+	DriverID       string   `json:"driver_id"` // только диспетчер: водитель, работающий с ним
+	TruckType      string   `json:"truck_type" binding:"required"`
+	CapacityWeight *float64 `json:"capacity_weight"` // т; по умолчанию trailer_capacity_weight водителя
+	CapacityVolume *float64 `json:"capacity_volume"` // м³; по умолчанию trailer_capacity_volume водителя
+	FromCityCode   string   `json:"from_city_code"`
+	FromLat        *float64 `json:"from_lat"`
+	FromLng        *float64 `json:"from_lng"`
+	AvailableFrom  string   `json:"available_from" binding:"required"` // RFC3339 или YYYY-MM-DD
+	AvailableTo    string   `json:"available_to"`
+	Destinations   []string `json:"destinations"` // коды городов
+	Comment        string   `json:"comment" binding:"max=1000"`
+}
+
+// CreateMy — водитель публикует свою свободную машину.
+// POST /v1/driver/truck-listings
+func (h *TruckListingsHandler) CreateMy(c *gin.Context) {
+	driverID := c.MustGet(mw.CtxDriverID).(uuid.UUID)
+	var req TruckListingReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+		return
+	}
+	drv, _ := h.drivers.FindByID(c.Request.Context(), driverID)
+	if drv == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "driver_not_found")
+		return
+	}
+	h.create(c, req, drv, trucklistings.PostedByDriver, driverID)
+}
+
+// Create — диспетчер публикует машину водителя, который с ним работает (freelancer_id).
+// POST /v1/dispatchers/truck-listings
+func (h *TruckListingsHandler) Create(c *gin.Context) {
+	dispatcherID := c.MustGet(mw.CtxDispatcherID).(uuid.UUID)
+	var req TruckListingReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+		return
+	}
+	driverID, err := uuid.Parse(strings.TrimSpace(req.DriverID))
+	if err != nil || driverID == uuid.Nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_driver_id")
+		return
+	}
+	drv, _ := h.drivers.FindByID(c.Request.Context(), driverID)
+	if drv == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "driver_not_found")
+		return
+	}
+	if drv.FreelancerID == nil || *drv.FreelancerID != dispatcherID.String() {
+		resp.ErrorLang(c, http.StatusForbidden, "trip_driver_not_managed")
+		return
+	}
+	h.create(c, req, drv, trucklistings.PostedByDispatcher, dispatcherID)
+}
+
+func (h *TruckListingsHandler) create(c *gin.Context, req TruckListingReq, drv *drivers.Driver, byType string, byID uuid.UUID) {
+	l, ok := listingFromReq(c, req)
+	if !ok {
+		return
+	}
+	l.DriverID, _ = uuid.Parse(drv.ID)
+	l.PostedByType, l.PostedByID = byType, byID
+	if l.CapacityWeight == nil {
+		l.CapacityWeight = drv.TrailerCapacityWeight
+	}
+	if l.CapacityVolume == nil {
+		l.CapacityVolume = drv.TrailerCapacityVolume
+	}
+	ctx := c.Request.Context()
+	id, err := h.repo.Create(ctx, *l)
+	if err != nil {
+		h.logger.Error("truck listing create", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	saved, err := h.repo.Get(ctx, id)
+	if err != nil || saved == nil {
+		h.logger.Error("truck listing get", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	resp.SuccessLang(c, http.StatusCreated, "created", toTruckListingResp(saved, resp.Lang(c)))
+}
+
+// ListMy — объявления водителя.
+// GET /v1/driver/truck-listings
+func (h *TruckListingsHandler) ListMy(c *gin.Context) {
+	driverID := c.MustGet(mw.CtxDriverID).(uuid.UUID)
+	list, err := h.repo.ListByDriver(c.Request.Context(), driverID, getIntQuery(c, "limit", 50))
+	h.respondList(c, list, err)
+}
+
+// List — объявления водителей диспетчера и размещённые им.
+// GET /v1/dispatchers/truck-listings
+func (h *TruckListingsHandler) List(c *gin.Context) {
+	dispatcherID := c.MustGet(mw.CtxDispatcherID).(uuid.UUID)
+	list, err := h.repo.ListByDispatcher(c.Request.Context(), dispatcherID, getIntQuery(c, "limit", 50))
+	h.respondList(c, list, err)
+}
+
+func (h *TruckListingsHandler) respondList(c *gin.Context, list []trucklistings.Listing, err error) {
+	if err != nil {
+		h.logger.Error("truck listings list", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	lang := resp.Lang(c)
+	items := make([]gin.H, 0, len(list))
+	for i := range list {
+		items = append(items, toTruckListingResp(&list[i], lang))
+	}
+	resp.OKLang(c, "ok", gin.H{"items": items})
+}
+
+// Update — изменить активное объявление (место, даты, машину, направления).
+// PUT /v1/driver/truck-listings/:id, PUT /v1/dispatchers/truck-listings/:id
+func (h *TruckListingsHandler) Update(c *gin.Context) {
+	cur, ok := h.ownListing(c)
+	if !ok {
+		return
+	}
+	var req TruckListingReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+		return
+	}
+	l, ok := listingFromReq(c, req)
+	if !ok {
+		return
+	}
+	l.ID = cur.ID
+	if l.CapacityWeight == nil {
+		l.CapacityWeight = cur.CapacityWeight
+	}
+	if l.CapacityVolume == nil {
+		l.CapacityVolume = cur.CapacityVolume
+	}
+	ctx := c.Request.Context()
+	if err := h.repo.Update(ctx, *l); err != nil {
+		if errors.Is(err, trucklistings.ErrNotActive) {
+			resp.ErrorLang(c, http.StatusConflict, "truck_listing_not_active")
+			return
+		}
+		h.logger.Error("truck listing update", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	saved, _ := h.repo.Get(ctx, cur.ID)
+	if saved == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "truck_listing_not_found")
+		return
+	}
+	resp.OKLang(c, "updated", toTruckListingResp(saved, resp.Lang(c)))
+}
+
+// Close — снять объявление с поиска.
+// POST /v1/driver/truck-listings/:id/close, POST /v1/dispatchers/truck-listings/:id/close
+func (h *TruckListingsHandler) Close(c *gin.Context) {
+	l, ok := h.ownListing(c)
+	if !ok {
+		return
+	}
+	if err := h.repo.Close(c.Request.Context(), l.ID); err != nil {
+		if errors.Is(err, trucklistings.ErrNotActive) {
+			resp.ErrorLang(c, http.StatusConflict, "truck_listing_not_active")
+			return
+		}
+		h.logger.Error("truck listing close", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	resp.OKLang(c, "ok", gin.H{"status": trucklistings.StatusClosed})
+}
+
+// Proposals — предложения грузов по объявлению (офферы от грузоотправителей).
+// GET /v1/driver/truck-listings/:id/proposals, GET /v1/dispatchers/truck-listings/:id/proposals
+func (h *TruckListingsHandler) Proposals(c *gin.Context) {
+	l, ok := h.ownListing(c)
+	if !ok {
+		return
+	}
+	offers, err := h.cargoRepo.OffersByListing(c.Request.Context(), l.ID)
+	if err != nil {
+		h.logger.Error("truck listing proposals", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	resp.OKLang(c, "ok", gin.H{"listing_id": l.ID.String(), "items": toOfferList(offers)})
+}
+
+// Search — поиск свободных машин грузоотправителем: рядом с точкой погрузки (from_city или from_lat/from_lng, radius_km),
+// с направлением рядом с точкой выгрузки (to_city или to_lat/to_lng, to_radius_km), тип кузова, дата, вес и объём груза.
+// GET /v1/dispatchers/truck-listings/search, GET /v1/truck-listings/search
+func (h *TruckListingsHandler) Search(c *gin.Context) {
+	f := trucklistings.SearchFilter{
+		TruckType: strings.ToUpper(strings.TrimSpace(c.Query("truck_type"))),
+		Limit:     getIntQuery(c, "limit", 20),
+		Offset:    getIntQuery(c, "offset", 0),
+	}
+	if f.Limit > 100 {
+		f.Limit = 100
+	}
+	if f.TruckType != "" && !reference.IsAllowed(f.TruckType, reference.AllowedTruckTypes()) {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_truck_type")
+		return
+	}
+	var ok bool
+	if f.From, f.FromRadiusKm, ok = searchPoint(c, "from_city", "from_lat", "from_lng", "radius_km"); !ok {
+		return
+	}
+	if f.To, f.ToRadiusKm, ok = searchPoint(c, "to_city", "to_lat", "to_lng", "to_radius_km"); !ok {
+		return
+	}
+	if v := strings.TrimSpace(c.Query("date")); v != "" {
+		d, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			resp.ErrorLang(c, http.StatusBadRequest, "invalid_date")
+			return
+		}
+		f.Date = &d
+	}
+	for key, dst := range map[string]**float64{"weight": &f.MinWeight, "volume": &f.MinVolume} {
+		if v := strings.TrimSpace(c.Query(key)); v != "" {
+			x, err := strconv.ParseFloat(v, 64)
+			if err != nil || x < 0 {
+				resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+				return
+			}
+			*dst = &x
+		}
+	}
+	matches, total, err := h.repo.Search(c.Request.Context(), f, time.Now())
+	if err != nil {
+		h.logger.Error("truck listings search", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	lang := resp.Lang(c)
+	items := make([]gin.H, 0, len(matches))
+	for i := range matches {
+		item := toTruckListingResp(&matches[i].Listing, lang)
+		item["from_distance_km"], item["to_distance_km"] = matches[i].FromDistanceKm, matches[i].ToDistanceKm
+		items = append(items, item)
+	}
+	resp.OKLang(c, "ok", gin.H{"items": items, "total": total, "limit": f.Limit, "offset": f.Offset})
+}
+
+// ProposeCargoReq — предложение груза по объявлению.
+type ProposeCargoReq struct {
+	CargoID    string  `json:"cargo_id" binding:"required,uuid"`
+	Price      float64 `json:"price" binding:"required,gt=0"`
+	Currency   string  `json:"currency" binding:"required"`
+	Comment    string  `json:"comment" binding:"max=1000"`
+	ValidHours *int    `json:"valid_hours"` // срок предложения; по умолчанию OFFER_DEFAULT_VALID_HOURS
+}
+
+// Propose — создатель груза предлагает свой груз водителю по объявлению: создаётся оффер, в котором первое предложение
+// цены — от грузоотправителя; водитель принимает его (POST /v1/driver/offers/:id/accept → рейс) или торгуется.
+// POST /v1/dispatchers/truck-listings/:id/proposals, POST /v1/truck-listings/:id/proposals
+func (h *TruckListingsHandler) Propose(c *gin.Context) {
+	listingID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return
+	}
+	var req ProposeCargoReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+		return
+	}
+	ctx := c.Request.Context()
+	l, _ := h.repo.Get(ctx, listingID)
+	if l == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "truck_listing_not_found")
+		return
+	}
+	if !l.Searchable(time.Now()) {
+		resp.ErrorLang(c, http.StatusConflict, "truck_listing_not_active")
+		return
+	}
+	cargoID, _ := uuid.Parse(req.CargoID)
+	obj, _ := h.cargoRepo.GetByID(ctx, cargoID, false)
+	if obj == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "cargo_not_found")
+		return
+	}
+	var authorType string
+	var authorID uuid.UUID
+	if v, ok := c.Get(mw.CtxDispatcherID); ok {
+		authorType, authorID = "DISPATCHER", v.(uuid.UUID)
+		if obj.CreatedByType == nil || *obj.CreatedByType != "DISPATCHER" || obj.CreatedByID == nil || *obj.CreatedByID != authorID {
+			resp.ErrorLang(c, http.StatusForbidden, "not_your_cargo")
+			return
+		}
+	} else {
+		companyID, ok := appUserCompanyID(c)
+		if !ok {
+			resp.ErrorLang(c, http.StatusForbidden, "company_not_selected")
+			return
+		}
+		if obj.CompanyID == nil || *obj.CompanyID != companyID {
+			resp.ErrorLang(c, http.StatusForbidden, "not_your_cargo")
+			return
+		}
+		authorType, authorID = "COMPANY", companyID
+	}
+	if !cargo.IsSearching(obj.Status) {
+		resp.ErrorLang(c, http.StatusBadRequest, "cargo_not_searching")
+		return
+	}
+	if obj.Status == cargo.StatusSearchingCompany {
+		drv, _ := h.drivers.FindByID(ctx, l.DriverID)
+		if drv == nil || drv.CompanyID == nil || obj.CompanyID == nil || *drv.CompanyID != obj.CompanyID.String() {
+			resp.ErrorLang(c, http.StatusForbidden, "cargo_visible_only_to_company_drivers")
+			return
+		}
+	}
+	if !strings.EqualFold(obj.TruckType, l.TruckType) {
+		resp.ErrorLang(c, http.StatusBadRequest, "cargo_truck_type_mismatch")
+		return
+	}
+	if (l.CapacityWeight != nil && obj.Weight > *l.CapacityWeight) || (l.CapacityVolume != nil && obj.Volume > *l.CapacityVolume) {
+		resp.ErrorLang(c, http.StatusBadRequest, "vehicle_capacity_exceeded")
+		return
+	}
+	currency := strings.ToUpper(strings.TrimSpace(req.Currency))
+	if currency == "OTHER" || !reference.IsAllowed(currency, reference.AllowedCurrencies()) {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_currency")
+		return
+	}
+	if a, err := h.cargoRepo.GetAuction(ctx, cargoID); err == nil && a.IsOpen() {
+		resp.ErrorLang(c, http.StatusConflict, "auction_in_progress")
+		return
+	}
+	offers, err := h.cargoRepo.GetOffers(ctx, cargoID)
+	if err != nil {
+		h.logger.Error("truck listing propose offers", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	for _, o := range offers {
+		if o.CarrierID == l.DriverID && o.Status == cargo.OfferStatusPending {
+			resp.ErrorLang(c, http.StatusConflict, "offer_already_pending")
+			return
+		}
+	}
+	expiresAt, ok := offerExpiresAt(req.ValidHours, h.offerValidity)
+	if !ok {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_offer_validity")
+		return
+	}
+	offerID, err := h.cargoRepo.CreateShipperOffer(ctx, cargo.ShipperOfferInput{
+		CargoID: cargoID, CarrierID: l.DriverID, ListingID: &l.ID, AuthorType: authorType, AuthorID: authorID,
+		Price: req.Price, Currency: currency, Comment: strings.TrimSpace(req.Comment), ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		h.logger.Error("truck listing propose", zap.Error(err))
+		resp.ErrorLang(c, http.StatusInternalServerError, "internal_error")
+		return
+	}
+	payload := map[string]any{
+		"listing_id": l.ID.String(), "offer_id": offerID.String(), "cargo_id": cargoID.String(),
+		"price": req.Price, "currency": currency, "expires_at": expiresAt,
+	}
+	h.notifier.Notify(ctx, notifications.RecipientDriver, l.DriverID, notifications.KindCargoProposed, payload)
+	if l.PostedByType == trucklistings.PostedByDispatcher {
+		h.notifier.Notify(ctx, notifications.RecipientDispatcher, l.PostedByID, notifications.KindCargoProposed, payload)
+	}
+	resp.SuccessLang(c, http.StatusCreated, "created", gin.H{"id": offerID.String(), "listing_id": l.ID.String(), "expires_at": expiresAt})
+}
+
+// ownListing загружает объявление :id: водитель — своё, диспетчер — своих водителей или размещённое им.
+func (h *TruckListingsHandler) ownListing(c *gin.Context) (*trucklistings.Listing, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_id")
+		return nil, false
+	}
+	ctx := c.Request.Context()
+	l, _ := h.repo.Get(ctx, id)
+	if l == nil {
+		resp.ErrorLang(c, http.StatusNotFound, "truck_listing_not_found")
+		return nil, false
+	}
+	if v, ok := c.Get(mw.CtxDriverID); ok {
+		driverID, _ := v.(uuid.UUID)
+		if l.DriverID != driverID {
+			resp.ErrorLang(c, http.StatusNotFound, "truck_listing_not_found")
+			return nil, false
+		}
+		return l, true
+	}
+	dispatcherID := c.MustGet(mw.CtxDispatcherID).(uuid.UUID)
+	if l.PostedByType == trucklistings.PostedByDispatcher && l.PostedByID == dispatcherID {
+		return l, true
+	}
+	if drv, _ := h.drivers.FindByID(ctx, l.DriverID); drv != nil && drv.FreelancerID != nil && *drv.FreelancerID == dispatcherID.String() {
+		return l, true
+	}
+	resp.ErrorLang(c, http.StatusNotFound, "truck_listing_not_found")
+	return nil, false
+}
+
+// listingFromReq проверяет тело объявления: тип кузова, место (координаты или город), даты, направления.
+func listingFromReq(c *gin.Context, req TruckListingReq) (*trucklistings.Listing, bool) {
+	l := &trucklistings.Listing{TruckType: strings.ToUpper(strings.TrimSpace(req.TruckType))}
+	if !reference.IsAllowed(l.TruckType, reference.AllowedTruckTypes()) {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_truck_type")
+		return nil, false
+	}
+	if (req.CapacityWeight != nil && *req.CapacityWeight <= 0) || (req.CapacityVolume != nil && *req.CapacityVolume <= 0) {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+		return nil, false
+	}
+	l.CapacityWeight, l.CapacityVolume = req.CapacityWeight, req.CapacityVolume
+	if (req.FromLat == nil) != (req.FromLng == nil) ||
+		(req.FromLat != nil && (*req.FromLat < -90 || *req.FromLat > 90 || *req.FromLng < -180 || *req.FromLng > 180)) {
+		resp.ErrorLang(c, http.StatusBadRequest, "pod_invalid_geo")
+		return nil, false
+	}
+	if code := strings.ToUpper(strings.TrimSpace(req.FromCityCode)); code != "" {
+		city, ok := listingCity(c, code)
+		if !ok {
+			return nil, false
+		}
+		l.FromCityCode = &city.Code
+		l.FromLat, l.FromLng = *city.Lat, *city.Lng
+	} else if req.FromLat == nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "truck_listing_location_required")
+		return nil, false
+	}
+	if req.FromLat != nil {
+		l.FromLat, l.FromLng = *req.FromLat, *req.FromLng
+	}
+	from, err := parseListingTime(req.AvailableFrom)
+	if err != nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_date")
+		return nil, false
+	}
+	l.AvailableFrom = from
+	if strings.TrimSpace(req.AvailableTo) != "" {
+		to, err := parseListingTime(req.AvailableTo)
+		if err != nil {
+			resp.ErrorLang(c, http.StatusBadRequest, "invalid_date")
+			return nil, false
+		}
+		if to.Before(from) || to.Before(time.Now()) {
+			resp.ErrorLang(c, http.StatusBadRequest, "invalid_truck_listing_dates")
+			return nil, false
+		}
+		l.AvailableTo = &to
+	}
+	if len(req.Destinations) > maxListingDestinations {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_payload_detail")
+		return nil, false
+	}
+	l.Destinations = make([]trucklistings.Destination, 0, len(req.Destinations))
+	for _, code := range req.Destinations {
+		city, ok := listingCity(c, strings.ToUpper(strings.TrimSpace(code)))
+		if !ok {
+			return nil, false
+		}
+		l.Destinations = append(l.Destinations, trucklistings.Destination{CityCode: city.Code, Lat: *city.Lat, Lng: *city.Lng})
+	}
+	if v := strings.TrimSpace(req.Comment); v != "" {
+		l.Comment = &v
+	}
+	return l, true
+}
+
+// listingCity — город из справочника с координатами (для поиска по радиусу).
+func listingCity(c *gin.Context, code string) (*reference.CityRef, bool) {
+	city, err := reference.FindCity(code)
+	if err != nil || city.Lat == nil || city.Lng == nil {
+		resp.ErrorLang(c, http.StatusBadRequest, "invalid_city_code")
+		return nil, false
+	}
+	return city, true
+}
+
+// parseListingTime — RFC3339 или дата YYYY-MM-DD (начало дня UTC).
+func parseListingTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.UTC(), nil
+	}
+	return time.Parse("2006-01-02", s)
+}
+
+// searchPoint читает точку поиска из query: город или координаты, и радиус (км).
+func searchPoint(c *gin.Context, cityKey, latKey, lngKey, radiusKey string) (*routing.Point, float64, bool) {
+	radius := float64(defaultListingSearchRadiusKm)
+	if v := strings.TrimSpace(c.Query(radiusKey)); v != "" {
+		r, err := strconv.ParseFloat(v, 64)
+		if err != nil || r <= 0 || r > maxListingSearchRadiusKm {
+			resp.ErrorLang(c, http.StatusBadRequest, "invalid_search_radius")
+			return nil, 0, false
+		}
+		radius = r
+	}
+	if code := strings.ToUpper(strings.TrimSpace(c.Query(cityKey))); code != "" {
+		city, ok := listingCity(c, code)
+		if !ok {
+			return nil, 0, false
+		}
+		return &routing.Point{Lat: *city.Lat, Lng: *city.Lng}, radius, true
+	}
+	latStr, lngStr := strings.TrimSpace(c.Query(latKey)), strings.TrimSpace(c.Query(lngKey))
+	if latStr == "" && lngStr == "" {
+		return nil, radius, true
+	}
+	lat, err1 := strconv.ParseFloat(latStr, 64)
+	lng, err2 := strconv.ParseFloat(lngStr, 64)
+	if err1 != nil || err2 != nil || lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		resp.ErrorLang(c, http.StatusBadRequest, "pod_invalid_geo")
+		return nil, 0, false
+	}
+	return &routing.Point{Lat: lat, Lng: lng}, radius, true
+}
+
+func toTruckListingResp(l *trucklistings.Listing, lang string) gin.H {
+	destinations := make([]gin.H, 0, len(l.Destinations))
+	for _, d := range l.Destinations {
+		destinations = append(destinations, gin.H{"city_code": d.CityCode, "city_name": trackingCityName(d.CityCode, lang), "lat": d.Lat, "lng": d.Lng})
+	}
+	var fromCityName any
+	if l.FromCityCode != nil {
+		fromCityName = trackingCityName(*l.FromCityCode, lang)
+	}
+	now := time.Now()
+	return gin.H{
+		"id":               l.ID.String(),
+		"driver_id":        l.DriverID.String(),
+		"driver_name":      l.DriverName,
+		"driver_rating":    l.DriverRating,
+		"posted_by_type":   l.PostedByType,
+		"truck_type":       l.TruckType,
+		"truck_type_label": reference.RefLabel("cargo.truck_type", l.TruckType, lang),
+		"capacity_weight":  l.CapacityWeight,
+		"capacity_volume":  l.CapacityVolume,
+		"from_city_code":   l.FromCityCode,
+		"from_city_name":   fromCityName,
+		"from_lat":         l.FromLat,
+		"from_lng":         l.FromLng,
+		"available_from":   l.AvailableFrom,
+		"available_to":     l.AvailableTo,
+		"destinations":     destinations,
+		"comment":          l.Comment,
+		"status":           l.Status,
+		"expired":          l.Expired(now),
+		"created_at":       l.CreatedAt,
+		"updated_at":       l.UpdatedAt,
+	}
+}
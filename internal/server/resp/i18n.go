@@ -1129,6 +1129,874 @@ var apiMessages = map[string]map[string]string{
 		"tr": "isim gerekli",
 		"zh": "需要name",
 	},
+	"trip_not_completed": {
+		"en": "Trip is not completed yet",
+		"ru": "Рейс ещё не завершён",
+		"uz": "Reys hali tugallanmagan",
+		"tr": "Sefer henüz tamamlanmadı",
+		"zh": "行程尚未完成",
+	},
+	"not_your_trip": {
+		"en": "Trip not found or not assigned to you",
+		"ru": "Рейс не найден или не назначен вам",
+		"uz": "Reys topilmadi yoki sizga tayinlanmagan",
+		"tr": "Sefer bulunamadı veya size atanmadı",
+		"zh": "行程不存在或未分配给您",
+	},
+	"review_target_not_found": {
+		"en": "Nobody to review for this trip",
+		"ru": "Некого оценить по этому рейсу",
+		"uz": "Bu reys bo'yicha baholanadigan tomon yo'q",
+		"tr": "Bu sefer için değerlendirilecek taraf yok",
+		"zh": "该行程没有可评价的对象",
+	},
+	"invalid_review_tag": {
+		"en": "Review tag must be from reference GET /v1/reference/cargo → review_tag",
+		"ru": "Тег отзыва должен быть из справочника GET /v1/reference/cargo → review_tag",
+		"uz": "Sharh tegi GET /v1/reference/cargo → review_tag ma'lumotnomasidan bo'lishi kerak",
+		"tr": "Yorum etiketi GET /v1/reference/cargo → review_tag listesinden olmalı",
+		"zh": "评价标签必须来自 GET /v1/reference/cargo → review_tag",
+	},
+	"review_already_exists": {
+		"en": "You have already reviewed this trip",
+		"ru": "Вы уже оставили отзыв по этому рейсу",
+		"uz": "Siz bu reys bo'yicha allaqachon sharh qoldirgansiz",
+		"tr": "Bu sefer için zaten yorum yaptınız",
+		"zh": "您已评价过此行程",
+	},
+	"failed_to_create_review": {
+		"en": "Failed to create review",
+		"ru": "Ошибка создания отзыва",
+		"uz": "Sharh yaratishda xato",
+		"tr": "Yorum oluşturulamadı",
+		"zh": "创建评价失败",
+	},
+	"invalid_review_target": {
+		"en": "target_type (DRIVER, DISPATCHER, COMPANY) and target_id are required",
+		"ru": "Обязательны target_type (DRIVER, DISPATCHER, COMPANY) и target_id",
+		"uz": "target_type (DRIVER, DISPATCHER, COMPANY) va target_id talab qilinadi",
+		"tr": "target_type (DRIVER, DISPATCHER, COMPANY) ve target_id gerekli",
+		"zh": "需要 target_type (DRIVER, DISPATCHER, COMPANY) 和 target_id",
+	},
+	"review_not_found": {
+		"en": "Review not found",
+		"ru": "Отзыв не найден",
+		"uz": "Sharh topilmadi",
+		"tr": "Yorum bulunamadı",
+		"zh": "未找到评价",
+	},
+	"reason_required": {
+		"en": "reason is required",
+		"ru": "Обязательна причина",
+		"uz": "Sabab talab qilinadi",
+		"tr": "Neden gerekli",
+		"zh": "需要原因",
+	},
+	"company_not_selected": {
+		"en": "Select a company first (POST /v1/auth/switch-company)",
+		"ru": "Сначала выберите компанию (POST /v1/auth/switch-company)",
+		"uz": "Avval kompaniyani tanlang (POST /v1/auth/switch-company)",
+		"tr": "Önce şirket seçin (POST /v1/auth/switch-company)",
+		"zh": "请先选择公司 (POST /v1/auth/switch-company)",
+	},
+	"failed_to_update": {
+		"en": "Failed to update",
+		"ru": "Ошибка обновления",
+		"uz": "Yangilashda xato",
+		"tr": "Güncellenemedi",
+		"zh": "更新失败",
+	},
+	"invalid_period": {
+		"en": "Invalid period (day, week, month)",
+		"ru": "Неверный период (day, week, month)",
+		"uz": "Noto'g'ri davr (day, week, month)",
+		"tr": "Geçersiz dönem (day, week, month)",
+		"zh": "无效的周期（day、week、month）",
+	},
+	"invalid_date": {
+		"en": "Invalid date, expected YYYY-MM-DD",
+		"ru": "Неверная дата, ожидается YYYY-MM-DD",
+		"uz": "Noto'g'ri sana, YYYY-MM-DD kutilmoqda",
+		"tr": "Geçersiz tarih, YYYY-MM-DD bekleniyor",
+		"zh": "日期无效，格式应为 YYYY-MM-DD",
+	},
+	"invalid_date_range": {
+		"en": "Invalid date range",
+		"ru": "Неверный диапазон дат",
+		"uz": "Noto'g'ri sana oralig'i",
+		"tr": "Geçersiz tarih aralığı",
+		"zh": "日期范围无效",
+	},
+	"trip_not_active": {
+		"en": "Trip is not active",
+		"ru": "Рейс не активен",
+		"uz": "Reys faol emas",
+		"tr": "Sefer aktif değil",
+		"zh": "行程未在进行中",
+	},
+	"driver_location_unknown": {
+		"en": "Driver location is unknown",
+		"ru": "Местоположение водителя неизвестно",
+		"uz": "Haydovchi joylashuvi noma'lum",
+		"tr": "Sürücü konumu bilinmiyor",
+		"zh": "司机位置未知",
+	},
+	"invalid_currency": {
+		"en": "Currency must be from reference",
+		"ru": "Валюта должна быть из справочника",
+		"uz": "Valyuta ma'lumotnomadan bo'lishi kerak",
+		"tr": "Para birimi referanstan olmalıdır",
+		"zh": "货币必须来自参考列表",
+	},
+	"file_required": {
+		"en": "File is required (form field \"file\")",
+		"ru": "Требуется файл (поле формы \"file\")",
+		"uz": "Fayl talab qilinadi (\"file\" maydoni)",
+		"tr": "Dosya gerekli (\"file\" alanı)",
+		"zh": "需要文件（表单字段 \"file\"）",
+	},
+	"invalid_rates_file": {
+		"en": "Invalid rates file",
+		"ru": "Неверный файл курсов",
+		"uz": "Kurslar fayli noto'g'ri",
+		"tr": "Geçersiz kur dosyası",
+		"zh": "汇率文件无效",
+	},
+	"currency_rate_not_found": {
+		"en": "Currency rate not found",
+		"ru": "Курс валюты не найден",
+		"uz": "Valyuta kursi topilmadi",
+		"tr": "Döviz kuru bulunamadı",
+		"zh": "未找到汇率",
+	},
+	"not_your_offer": {
+		"en": "This offer is not yours",
+		"ru": "Это не ваш оффер",
+		"uz": "Bu sizning taklifingiz emas",
+		"tr": "Bu teklif size ait değil",
+		"zh": "这不是您的报价",
+	},
+	"offer_not_your_turn": {
+		"en": "Waiting for the other side to respond to the current price",
+		"ru": "Ожидается ответ другой стороны на текущую цену",
+		"uz": "Joriy narxga boshqa tomonning javobi kutilmoqda",
+		"tr": "Mevcut fiyata karşı tarafın yanıtı bekleniyor",
+		"zh": "正在等待对方对当前价格的回复",
+	},
+	"offer_not_negotiable": {
+		"en": "The price of this cargo is not negotiable",
+		"ru": "Цена этого груза не обсуждается",
+		"uz": "Bu yuk narxi kelishilmaydi",
+		"tr": "Bu yükün fiyatı pazarlığa açık değil",
+		"zh": "该货物价格不可议价",
+	},
+	"offer_rounds_exceeded": {
+		"en": "Counter-offer limit reached: accept or reject the current price",
+		"ru": "Лимит встречных предложений исчерпан: примите или отклоните текущую цену",
+		"uz": "Qarshi takliflar chegarasi tugadi: joriy narxni qabul qiling yoki rad eting",
+		"tr": "Karşı teklif sınırına ulaşıldı: mevcut fiyatı kabul edin veya reddedin",
+		"zh": "还价次数已达上限：请接受或拒绝当前价格",
+	},
+	"invalid_status": {
+		"en": "Invalid status",
+		"ru": "Неверный статус",
+		"uz": "Noto'g'ri holat",
+		"tr": "Geçersiz durum",
+		"zh": "状态无效",
+	},
+	"invalid_offer_validity": {
+		"en": "valid_hours must be between 1 and 720",
+		"ru": "valid_hours должен быть от 1 до 720",
+		"uz": "valid_hours 1 dan 720 gacha bo'lishi kerak",
+		"tr": "valid_hours 1 ile 720 arasında olmalıdır",
+		"zh": "valid_hours 必须在 1 到 720 之间",
+	},
+	"notification_not_found": {
+		"en": "Notification not found",
+		"ru": "Уведомление не найдено",
+		"uz": "Bildirishnoma topilmadi",
+		"tr": "Bildirim bulunamadı",
+		"zh": "未找到通知",
+	},
+	"auction_in_progress": {
+		"en": "Cargo is in an open auction: place a bid or wait for it to close",
+		"ru": "По грузу идёт аукцион: сделайте ставку или дождитесь завершения",
+		"uz": "Yuk bo'yicha auksion davom etmoqda: stavka qo'ying yoki yakunlanishini kuting",
+		"tr": "Yük için açık artırma sürüyor: teklif verin veya bitmesini bekleyin",
+		"zh": "该货物正在竞价中：请出价或等待结束",
+	},
+	"invalid_auction_deadline": {
+		"en": "Auction must end between 10 minutes and 30 days from now",
+		"ru": "Аукцион должен завершиться не раньше чем через 10 минут и не позже чем через 30 дней",
+		"uz": "Auksion 10 daqiqadan 30 kungacha bo'lgan muddatda tugashi kerak",
+		"tr": "Açık artırma 10 dakika ile 30 gün arasında bitmelidir",
+		"zh": "竞价结束时间须在10分钟至30天之内",
+	},
+	"auction_already_exists": {
+		"en": "An auction already exists for this cargo",
+		"ru": "Для этого груза аукцион уже создан",
+		"uz": "Bu yuk uchun auksion allaqachon mavjud",
+		"tr": "Bu yük için zaten bir açık artırma var",
+		"zh": "该货物已存在竞价",
+	},
+	"auction_not_found": {
+		"en": "Auction not found",
+		"ru": "Аукцион не найден",
+		"uz": "Auksion topilmadi",
+		"tr": "Açık artırma bulunamadı",
+		"zh": "未找到竞价",
+	},
+	"auction_not_open": {
+		"en": "Auction is not open",
+		"ru": "Аукцион не открыт",
+		"uz": "Auksion ochiq emas",
+		"tr": "Açık artırma açık değil",
+		"zh": "竞价未开放",
+	},
+	"auction_not_closed": {
+		"en": "Auction has not closed yet",
+		"ru": "Аукцион ещё не завершён",
+		"uz": "Auksion hali yakunlanmagan",
+		"tr": "Açık artırma henüz kapanmadı",
+		"zh": "竞价尚未结束",
+	},
+	"bid_not_lower": {
+		"en": "New bid must be lower than your current bid",
+		"ru": "Новая ставка должна быть ниже вашей текущей",
+		"uz": "Yangi stavka joriy stavkangizdan past bo'lishi kerak",
+		"tr": "Yeni teklif mevcut teklifinizden düşük olmalıdır",
+		"zh": "新出价必须低于您当前的出价",
+	},
+	"cargo_template_not_found": {
+		"en": "Cargo template not found",
+		"ru": "Шаблон груза не найден",
+		"uz": "Yuk shabloni topilmadi",
+		"tr": "Yük şablonu bulunamadı",
+		"zh": "未找到货物模板",
+	},
+	"cargo_template_name_taken": {
+		"en": "A template with this name already exists",
+		"ru": "Шаблон с таким названием уже существует",
+		"uz": "Bu nomdagi shablon allaqachon mavjud",
+		"tr": "Bu adda bir şablon zaten var",
+		"zh": "同名模板已存在",
+	},
+	"cargo_schedule_not_found": {
+		"en": "Schedule not found",
+		"ru": "Расписание не найдено",
+		"uz": "Jadval topilmadi",
+		"tr": "Program bulunamadı",
+		"zh": "未找到计划",
+	},
+	"invalid_cargo_schedule": {
+		"en": "Invalid schedule: weekdays 1-7, run_time HH:MM, ready_offset_days 0-60",
+		"ru": "Некорректное расписание: weekdays 1–7, run_time ЧЧ:ММ, ready_offset_days 0–60",
+		"uz": "Noto'g'ri jadval: weekdays 1–7, run_time SS:DD, ready_offset_days 0–60",
+		"tr": "Geçersiz program: weekdays 1-7, run_time SS:DD, ready_offset_days 0-60",
+		"zh": "计划无效：weekdays 1-7，run_time HH:MM，ready_offset_days 0-60",
+	},
+	"invalid_import_file": {
+		"en": "Cannot read the file: CSV or XLSX expected",
+		"ru": "Не удалось прочитать файл: ожидается CSV или XLSX",
+		"uz": "Faylni o'qib bo'lmadi: CSV yoki XLSX kutilmoqda",
+		"tr": "Dosya okunamadı: CSV veya XLSX bekleniyor",
+		"zh": "无法读取文件：需要 CSV 或 XLSX",
+	},
+	"import_missing_columns": {
+		"en": "Required columns are missing in the file header",
+		"ru": "В заголовке файла нет обязательных колонок",
+		"uz": "Fayl sarlavhasida majburiy ustunlar yo'q",
+		"tr": "Dosya başlığında zorunlu sütunlar eksik",
+		"zh": "文件表头缺少必填列",
+	},
+	"import_too_many_rows": {
+		"en": "Too many rows in the file",
+		"ru": "Слишком много строк в файле",
+		"uz": "Faylda qatorlar juda ko'p",
+		"tr": "Dosyada çok fazla satır var",
+		"zh": "文件行数过多",
+	},
+	"invalid_export_kind": {
+		"en": "Unknown report: use cargo, trips or offers",
+		"ru": "Неизвестный отчёт: cargo, trips или offers",
+		"uz": "Noma'lum hisobot: cargo, trips yoki offers",
+		"tr": "Bilinmeyen rapor: cargo, trips veya offers",
+		"zh": "未知报表：cargo、trips 或 offers",
+	},
+	"invalid_export_format": {
+		"en": "Format must be csv or xlsx",
+		"ru": "Формат должен быть csv или xlsx",
+		"uz": "Format csv yoki xlsx bo'lishi kerak",
+		"tr": "Biçim csv veya xlsx olmalıdır",
+		"zh": "格式必须为 csv 或 xlsx",
+	},
+	"invalid_export_params": {
+		"en": "Invalid report filters",
+		"ru": "Некорректные фильтры отчёта",
+		"uz": "Hisobot filtrlari noto'g'ri",
+		"tr": "Geçersiz rapor filtreleri",
+		"zh": "报表筛选条件无效",
+	},
+	"export_too_large": {
+		"en": "Too many rows for one export; narrow the filters",
+		"ru": "Слишком много строк для одной выгрузки; сузьте фильтры",
+		"uz": "Bitta eksport uchun qatorlar juda ko'p; filtrlarni toraytiring",
+		"tr": "Tek bir dışa aktarım için çok fazla satır; filtreleri daraltın",
+		"zh": "单次导出行数过多，请缩小筛选范围",
+	},
+	"export_queued": {
+		"en": "Export is being generated; download it when ready",
+		"ru": "Выгрузка формируется; скачайте её, когда будет готова",
+		"uz": "Eksport tayyorlanmoqda; tayyor bo'lganda yuklab oling",
+		"tr": "Dışa aktarım hazırlanıyor; hazır olduğunda indirin",
+		"zh": "正在生成导出文件，完成后即可下载",
+	},
+	"export_not_found": {
+		"en": "Export not found",
+		"ru": "Выгрузка не найдена",
+		"uz": "Eksport topilmadi",
+		"tr": "Dışa aktarım bulunamadı",
+		"zh": "未找到导出",
+	},
+	"export_not_ready": {
+		"en": "Export is not ready yet",
+		"ru": "Выгрузка ещё не готова",
+		"uz": "Eksport hali tayyor emas",
+		"tr": "Dışa aktarım henüz hazır değil",
+		"zh": "导出尚未完成",
+	},
+	"invalid_document_type": {
+		"en": "Invalid document type (cmr, trip_sheet)",
+		"ru": "Неверный тип документа (cmr, trip_sheet)",
+		"uz": "Hujjat turi noto'g'ri (cmr, trip_sheet)",
+		"tr": "Geçersiz belge türü (cmr, trip_sheet)",
+		"zh": "无效的文件类型 (cmr, trip_sheet)",
+	},
+	"trip_documents_unavailable": {
+		"en": "Documents are available after the driver confirms the trip and until it is cancelled",
+		"ru": "Документы доступны после подтверждения рейса водителем и недоступны для отменённого рейса",
+		"uz": "Hujjatlar haydovchi reysni tasdiqlagandan keyin mavjud, bekor qilingan reys uchun mavjud emas",
+		"tr": "Belgeler sürücü seferi onayladıktan sonra kullanılabilir, iptal edilen sefer için kullanılamaz",
+		"zh": "司机确认行程后方可获取文件，已取消的行程不可用",
+	},
+	"pod_required": {
+		"en": "Complete the trip by submitting proof of delivery (photos and consignee signature)",
+		"ru": "Рейс завершается подтверждением доставки (фото и подпись получателя)",
+		"uz": "Reys yetkazib berishni tasdiqlash (foto va qabul qiluvchi imzosi) orqali yakunlanadi",
+		"tr": "Sefer teslim kanıtı (fotoğraflar ve alıcı imzası) ile tamamlanır",
+		"zh": "请提交交付证明（照片和收货人签名）以完成行程",
+	},
+	"trip_not_unloading": {
+		"en": "Proof of delivery is available only while the trip is in UNLOADING status",
+		"ru": "Подтверждение доставки доступно только в статусе UNLOADING",
+		"uz": "Yetkazib berishni tasdiqlash faqat UNLOADING holatida mumkin",
+		"tr": "Teslim kanıtı yalnızca UNLOADING durumunda kullanılabilir",
+		"zh": "仅在 UNLOADING 状态下可提交交付证明",
+	},
+	"pod_too_many_photos": {
+		"en": "Too many delivery photos",
+		"ru": "Слишком много фото доставки",
+		"uz": "Yetkazib berish fotolari juda ko'p",
+		"tr": "Çok fazla teslim fotoğrafı",
+		"zh": "交付照片过多",
+	},
+	"pod_already_submitted": {
+		"en": "Proof of delivery has already been submitted",
+		"ru": "Доставка уже подтверждена",
+		"uz": "Yetkazib berish allaqachon tasdiqlangan",
+		"tr": "Teslim kanıtı zaten gönderildi",
+		"zh": "交付证明已提交",
+	},
+	"pod_consignee_required": {
+		"en": "Consignee name is required (up to 255 characters)",
+		"ru": "Укажите имя получателя (до 255 символов)",
+		"uz": "Qabul qiluvchi ismini kiriting (255 belgigacha)",
+		"tr": "Alıcı adı gerekli (en fazla 255 karakter)",
+		"zh": "请填写收货人姓名（最多255个字符）",
+	},
+	"pod_photos_required": {
+		"en": "Upload at least one photo of the delivered cargo",
+		"ru": "Загрузите хотя бы одно фото доставленного груза",
+		"uz": "Yetkazilgan yukning kamida bitta fotosini yuklang",
+		"tr": "Teslim edilen yükün en az bir fotoğrafını yükleyin",
+		"zh": "请至少上传一张已交付货物的照片",
+	},
+	"pod_invalid_geo": {
+		"en": "Invalid coordinates (lat, lng)",
+		"ru": "Неверные координаты (lat, lng)",
+		"uz": "Koordinatalar noto'g'ri (lat, lng)",
+		"tr": "Geçersiz koordinatlar (lat, lng)",
+		"zh": "坐标无效 (lat, lng)",
+	},
+	"pod_not_found": {
+		"en": "Proof of delivery not found",
+		"ru": "Подтверждение доставки не найдено",
+		"uz": "Yetkazib berish tasdig'i topilmadi",
+		"tr": "Teslim kanıtı bulunamadı",
+		"zh": "未找到交付证明",
+	},
+	"pod_already_disputed": {
+		"en": "Delivery is already disputed",
+		"ru": "Спор по доставке уже открыт",
+		"uz": "Yetkazib berish bo'yicha nizo allaqachon ochilgan",
+		"tr": "Teslimat zaten itiraz edildi",
+		"zh": "该交付已有争议",
+	},
+	"pod_dispute_window_closed": {
+		"en": "The dispute window for this delivery has closed",
+		"ru": "Срок для спора по доставке истёк",
+		"uz": "Yetkazib berish bo'yicha nizo muddati tugagan",
+		"tr": "Bu teslimat için itiraz süresi doldu",
+		"zh": "该交付的争议期限已过",
+	},
+	"delivery_pin_required": {
+		"en": "Enter the delivery PIN received by the consignee",
+		"ru": "Введите PIN-код, полученный получателем",
+		"uz": "Qabul qiluvchi olgan PIN-kodni kiriting",
+		"tr": "Alıcının aldığı teslimat PIN kodunu girin",
+		"zh": "请输入收货人收到的交付PIN码",
+	},
+	"delivery_pin_invalid": {
+		"en": "Invalid delivery PIN",
+		"ru": "Неверный PIN-код получателя",
+		"uz": "PIN-kod noto'g'ri",
+		"tr": "Geçersiz teslimat PIN kodu",
+		"zh": "交付PIN码错误",
+	},
+	"delivery_pin_locked": {
+		"en": "Too many wrong PIN attempts. Ask the shipper to issue a new PIN",
+		"ru": "Слишком много неверных попыток. Попросите грузоотправителя выпустить новый PIN",
+		"uz": "Noto'g'ri urinishlar juda ko'p. Yuk jo'natuvchidan yangi PIN so'rang",
+		"tr": "Çok fazla hatalı PIN denemesi. Göndericiden yeni PIN isteyin",
+		"zh": "PIN码错误次数过多，请让发货人重新生成PIN码",
+	},
+	"delivery_pin_not_issued": {
+		"en": "Delivery PIN is issued when the trip is en route",
+		"ru": "PIN-код получателя выдаётся, когда рейс в пути",
+		"uz": "PIN-kod reys yo'lda bo'lganda beriladi",
+		"tr": "Teslimat PIN kodu sefer yoldayken oluşturulur",
+		"zh": "行程在途时才会生成交付PIN码",
+	},
+	"delivery_pin_already_verified": {
+		"en": "Delivery PIN already confirmed",
+		"ru": "PIN-код получателя уже подтверждён",
+		"uz": "PIN-kod allaqachon tasdiqlangan",
+		"tr": "Teslimat PIN kodu zaten onaylandı",
+		"zh": "交付PIN码已确认",
+	},
+	"tracking_link_not_found": {
+		"en": "Tracking link not found",
+		"ru": "Ссылка отслеживания не найдена",
+		"uz": "Kuzatuv havolasi topilmadi",
+		"tr": "Takip bağlantısı bulunamadı",
+		"zh": "未找到跟踪链接",
+	},
+	"tracking_link_expired": {
+		"en": "Tracking link has expired or was revoked",
+		"ru": "Срок действия ссылки истёк или она отозвана",
+		"uz": "Havola muddati tugagan yoki bekor qilingan",
+		"tr": "Takip bağlantısının süresi doldu veya iptal edildi",
+		"zh": "跟踪链接已过期或已撤销",
+	},
+	"invoice_unavailable": {
+		"en": "Invoice is not available for this trip yet",
+		"ru": "Счёт по этому рейсу ещё не выставлен",
+		"uz": "Bu reys uchun hisob hali chiqarilmagan",
+		"tr": "Bu sefer için fatura henüz düzenlenmedi",
+		"zh": "该行程尚未开具发票",
+	},
+	"invoice_cancelled": {
+		"en": "Invoice is cancelled",
+		"ru": "Счёт отменён",
+		"uz": "Hisob bekor qilingan",
+		"tr": "Fatura iptal edildi",
+		"zh": "发票已取消",
+	},
+	"invoice_already_paid": {
+		"en": "Invoice is already paid in full",
+		"ru": "Счёт уже оплачен полностью",
+		"uz": "Hisob allaqachon to'liq to'langan",
+		"tr": "Fatura zaten tamamen ödendi",
+		"zh": "发票已全额支付",
+	},
+	"invoice_overpayment": {
+		"en": "Payment exceeds the outstanding amount",
+		"ru": "Сумма платежа превышает неоплаченный остаток",
+		"uz": "To'lov summasi qoldiqdan oshib ketdi",
+		"tr": "Ödeme tutarı kalan bakiyeyi aşıyor",
+		"zh": "付款金额超过未付余额",
+	},
+	"invalid_payment_kind": {
+		"en": "Invalid payment kind (PREPAYMENT, REMAINING or PARTIAL)",
+		"ru": "Недопустимый вид платежа (PREPAYMENT, REMAINING или PARTIAL)",
+		"uz": "Noto'g'ri to'lov turi (PREPAYMENT, REMAINING yoki PARTIAL)",
+		"tr": "Geçersiz ödeme türü (PREPAYMENT, REMAINING veya PARTIAL)",
+		"zh": "无效的付款类型（PREPAYMENT、REMAINING 或 PARTIAL）",
+	},
+	"invalid_payment_method": {
+		"en": "Invalid payment method",
+		"ru": "Недопустимый способ оплаты",
+		"uz": "Noto'g'ri to'lov usuli",
+		"tr": "Geçersiz ödeme yöntemi",
+		"zh": "无效的付款方式",
+	},
+	"invalid_commission": {
+		"en": "Commission must be PERCENT with percent in (0, 100] or FIXED with amount > 0 and currency",
+		"ru": "Комиссия: PERCENT с percent в диапазоне (0, 100] или FIXED с amount > 0 и currency",
+		"uz": "Komissiya: PERCENT (percent (0, 100] oralig'ida) yoki FIXED (amount > 0 va currency)",
+		"tr": "Komisyon: PERCENT (percent (0, 100] aralığında) veya FIXED (amount > 0 ve currency)",
+		"zh": "佣金必须为 PERCENT（percent 在 (0, 100] 范围内）或 FIXED（amount > 0 且指定 currency）",
+	},
+	"commission_not_found": {
+		"en": "Commission agreement not found",
+		"ru": "Соглашение о комиссии не найдено",
+		"uz": "Komissiya kelishuvi topilmadi",
+		"tr": "Komisyon anlaşması bulunamadı",
+		"zh": "未找到佣金协议",
+	},
+	"invalid_expense_category": {
+		"en": "Invalid expense category",
+		"ru": "Неверная категория расхода",
+		"uz": "Xarajat toifasi noto'g'ri",
+		"tr": "Geçersiz gider kategorisi",
+		"zh": "费用类别无效",
+	},
+	"invalid_expense_amount": {
+		"en": "Expense amount must be greater than zero",
+		"ru": "Сумма расхода должна быть больше нуля",
+		"uz": "Xarajat summasi noldan katta bo'lishi kerak",
+		"tr": "Gider tutarı sıfırdan büyük olmalıdır",
+		"zh": "费用金额必须大于零",
+	},
+	"expense_not_found": {
+		"en": "Expense not found",
+		"ru": "Расход не найден",
+		"uz": "Xarajat topilmadi",
+		"tr": "Gider bulunamadı",
+		"zh": "未找到费用",
+	},
+	"expense_already_reviewed": {
+		"en": "Expense has already been reviewed",
+		"ru": "Расход уже проверен",
+		"uz": "Xarajat allaqachon ko'rib chiqilgan",
+		"tr": "Gider zaten incelendi",
+		"zh": "费用已审核",
+	},
+	"stop_already_arrived": {
+		"en": "Arrival at this stop is already recorded",
+		"ru": "Прибытие на эту точку уже отмечено",
+		"uz": "Bu nuqtaga yetib kelish allaqachon belgilangan",
+		"tr": "Bu durağa varış zaten kaydedildi",
+		"zh": "已记录到达该站点",
+	},
+	"stop_not_arrived": {
+		"en": "You are not at this stop (arrival not recorded or already departed)",
+		"ru": "Вы не на этой точке (прибытие не отмечено или отъезд уже отмечен)",
+		"uz": "Siz bu nuqtada emassiz (yetib kelish belgilanmagan yoki jo'nash allaqachon belgilangan)",
+		"tr": "Bu durakta değilsiniz (varış kaydedilmedi veya ayrılış zaten kaydedildi)",
+		"zh": "您不在该站点（未记录到达或已记录离开）",
+	},
+	"stop_out_of_order": {
+		"en": "Stops must be visited in route order",
+		"ru": "Точки маршрута проходятся по порядку",
+		"uz": "Marshrut nuqtalari tartib bo'yicha o'tiladi",
+		"tr": "Duraklar rota sırasına göre ziyaret edilmelidir",
+		"zh": "必须按路线顺序经过站点",
+	},
+	"stop_not_departed": {
+		"en": "Record departure from the current stop first",
+		"ru": "Сначала отметьте отъезд с текущей точки",
+		"uz": "Avval joriy nuqtadan jo'nashni belgilang",
+		"tr": "Önce mevcut duraktan ayrılışı kaydedin",
+		"zh": "请先记录离开当前站点",
+	},
+	"route_point_not_found": {
+		"en": "Route point not found on this trip",
+		"ru": "Точка маршрута рейса не найдена",
+		"uz": "Reys marshrut nuqtasi topilmadi",
+		"tr": "Sefere ait rota noktası bulunamadı",
+		"zh": "未找到该行程的路线点",
+	},
+	"invalid_customs_status": {
+		"en": "Invalid customs status",
+		"ru": "Неверный статус таможни",
+		"uz": "Bojxona holati noto'g'ri",
+		"tr": "Geçersiz gümrük durumu",
+		"zh": "海关状态无效",
+	},
+	"invalid_customs_transition": {
+		"en": "This customs status change is not allowed",
+		"ru": "Такая смена статуса таможни недопустима",
+		"uz": "Bojxona holatini bunday o'zgartirish mumkin emas",
+		"tr": "Bu gümrük durumu değişikliğine izin verilmiyor",
+		"zh": "不允许此海关状态变更",
+	},
+	"customs_hold_reason_required": {
+		"en": "Specify the reason for the customs hold",
+		"ru": "Укажите причину задержки на таможне",
+		"uz": "Bojxonada ushlab qolinish sababini ko'rsating",
+		"tr": "Gümrükte alıkonma nedenini belirtin",
+		"zh": "请说明海关扣留原因",
+	},
+	"customs_not_started": {
+		"en": "Customs has not started at this route point",
+		"ru": "Таможня на этой точке ещё не начата",
+		"uz": "Bu nuqtada bojxona hali boshlanmagan",
+		"tr": "Bu noktada gümrük işlemi henüz başlamadı",
+		"zh": "该路线点尚未开始清关",
+	},
+	"invalid_customs_document_type": {
+		"en": "Invalid customs document type",
+		"ru": "Неверный тип таможенного документа",
+		"uz": "Bojxona hujjati turi noto'g'ri",
+		"tr": "Geçersiz gümrük belgesi türü",
+		"zh": "海关单证类型无效",
+	},
+	"customs_document_not_found": {
+		"en": "Customs document not found",
+		"ru": "Таможенный документ не найден",
+		"uz": "Bojxona hujjati topilmadi",
+		"tr": "Gümrük belgesi bulunamadı",
+		"zh": "未找到海关单证",
+	},
+	"route_point_not_customs": {
+		"en": "Route point is not a customs point",
+		"ru": "Точка маршрута не является таможней",
+		"uz": "Marshrut nuqtasi bojxona emas",
+		"tr": "Rota noktası gümrük noktası değil",
+		"zh": "该路线点不是海关点",
+	},
+	"trip_driver_not_managed": {
+		"en": "The trip's driver does not work with you",
+		"ru": "Водитель рейса не работает с вами",
+		"uz": "Reys haydovchisi siz bilan ishlamaydi",
+		"tr": "Seferin sürücüsü sizinle çalışmıyor",
+		"zh": "该行程的司机不隶属于您",
+	},
+	"trip_not_consolidatable": {
+		"en": "This trip cannot be added: it must be ASSIGNED to the same driver and not consolidated yet",
+		"ru": "Этот рейс нельзя присоединить: он должен быть назначен (ASSIGNED) тому же водителю и ещё не объединён",
+		"uz": "Bu reysni qo'shib bo'lmaydi: u o'sha haydovchiga tayinlangan (ASSIGNED) va hali birlashtirilmagan bo'lishi kerak",
+		"tr": "Bu sefer eklenemez: aynı sürücüye atanmış (ASSIGNED) ve henüz birleştirilmemiş olmalı",
+		"zh": "无法添加该行程：必须已分配(ASSIGNED)给同一司机且尚未合并",
+	},
+	"invalid_stop_sequence": {
+		"en": "Invalid stop sequence: list every route point once, keep visited points in place and each cargo's own order",
+		"ru": "Неверный порядок точек: укажите каждую точку один раз, не меняя пройденные точки и порядок точек каждого груза",
+		"uz": "Nuqtalar tartibi noto'g'ri: har bir nuqtani bir marta ko'rsating, o'tilgan nuqtalar va har bir yuk tartibini o'zgartirmang",
+		"tr": "Geçersiz durak sırası: her noktayı bir kez belirtin, geçilen noktaları ve her yükün sırasını değiştirmeyin",
+		"zh": "停靠顺序无效：每个点须出现一次，已到达的点及各货物自身顺序不得改变",
+	},
+	"trip_cargo_already_started": {
+		"en": "The driver has already checked in at this cargo's route points",
+		"ru": "Водитель уже отметился на точках этого груза",
+		"uz": "Haydovchi bu yuk nuqtalarida allaqachon belgilangan",
+		"tr": "Sürücü bu yükün noktalarında zaten giriş yaptı",
+		"zh": "司机已在该货物的路线点签到",
+	},
+	"cargo_full_truck_load": {
+		"en": "Full truck load (FTL) cargo cannot share a vehicle",
+		"ru": "Груз с полной загрузкой (FTL) нельзя везти вместе с другими",
+		"uz": "To'liq yuk (FTL) boshqa yuklar bilan birga tashilmaydi",
+		"tr": "Tam yük (FTL) başka yüklerle taşınamaz",
+		"zh": "整车(FTL)货物不能与其他货物拼车",
+	},
+	"cargo_truck_type_mismatch": {
+		"en": "Cargos require different truck types",
+		"ru": "Грузам нужны разные типы кузова",
+		"uz": "Yuklar uchun turli kuzov turlari kerak",
+		"tr": "Yükler farklı kasa tipleri gerektiriyor",
+		"zh": "货物需要不同的车型",
+	},
+	"cargo_temperature_mismatch": {
+		"en": "Cargo temperature ranges do not overlap",
+		"ru": "Температурные режимы грузов не совпадают",
+		"uz": "Yuklarning harorat rejimlari mos kelmaydi",
+		"tr": "Yüklerin sıcaklık aralıkları örtüşmüyor",
+		"zh": "货物温度范围不重叠",
+	},
+	"vehicle_capacity_unknown": {
+		"en": "The driver's vehicle capacity is not set (trailer_capacity_weight)",
+		"ru": "Грузоподъёмность машины водителя не указана (trailer_capacity_weight)",
+		"uz": "Haydovchi mashinasining yuk ko'tarish quvvati ko'rsatilmagan (trailer_capacity_weight)",
+		"tr": "Sürücünün araç kapasitesi belirtilmemiş (trailer_capacity_weight)",
+		"zh": "未设置司机车辆载重(trailer_capacity_weight)",
+	},
+	"vehicle_capacity_exceeded": {
+		"en": "Cargos exceed the vehicle's weight or volume capacity on the merged route",
+		"ru": "Грузы превышают грузоподъёмность или объём машины на объединённом маршруте",
+		"uz": "Yuklar birlashtirilgan marshrutda mashinaning yuk ko'tarish quvvati yoki hajmidan oshadi",
+		"tr": "Yükler birleştirilmiş güzergahta aracın ağırlık veya hacim kapasitesini aşıyor",
+		"zh": "合并路线上货物超出车辆载重或容积",
+	},
+	"invalid_cancel_reason": {
+		"en": "Invalid reason code (see GET /v1/reference/cargo, trip_cancel_reason)",
+		"ru": "Неверная причина (см. GET /v1/reference/cargo, trip_cancel_reason)",
+		"uz": "Sabab noto'g'ri (GET /v1/reference/cargo, trip_cancel_reason)",
+		"tr": "Geçersiz neden kodu (GET /v1/reference/cargo, trip_cancel_reason)",
+		"zh": "原因代码无效（见 GET /v1/reference/cargo, trip_cancel_reason）",
+	},
+	"cancel_comment_required": {
+		"en": "A comment is required for reason OTHER",
+		"ru": "Для причины OTHER нужен комментарий",
+		"uz": "OTHER sababi uchun izoh kerak",
+		"tr": "OTHER nedeni için yorum gerekli",
+		"zh": "原因为 OTHER 时必须填写备注",
+	},
+	"trip_not_cancellable": {
+		"en": "The trip can no longer be cancelled (only PENDING_DRIVER, ASSIGNED or LOADING); swap the driver instead",
+		"ru": "Рейс уже нельзя отменить (только PENDING_DRIVER, ASSIGNED или LOADING); замените водителя",
+		"uz": "Reysni endi bekor qilib bo'lmaydi (faqat PENDING_DRIVER, ASSIGNED yoki LOADING); haydovchini almashtiring",
+		"tr": "Sefer artık iptal edilemez (yalnızca PENDING_DRIVER, ASSIGNED veya LOADING); sürücüyü değiştirin",
+		"zh": "行程已无法取消（仅限 PENDING_DRIVER、ASSIGNED 或 LOADING），请更换司机",
+	},
+	"trip_has_consolidated_cargos": {
+		"en": "Detach the consolidated trips first",
+		"ru": "Сначала отсоедините присоединённые рейсы",
+		"uz": "Avval birlashtirilgan reyslarni ajrating",
+		"tr": "Önce birleştirilmiş seferleri ayırın",
+		"zh": "请先解除合并的行程",
+	},
+	"trip_cancellation_not_found": {
+		"en": "The trip has no cancellation record",
+		"ru": "У рейса нет записи об отмене",
+		"uz": "Reysda bekor qilish yozuvi yo'q",
+		"tr": "Seferin iptal kaydı yok",
+		"zh": "该行程没有取消记录",
+	},
+	"same_driver": {
+		"en": "This driver is already on the trip",
+		"ru": "Этот водитель уже на рейсе",
+		"uz": "Bu haydovchi allaqachon reysda",
+		"tr": "Bu sürücü zaten seferde",
+		"zh": "该司机已在此行程中",
+	},
+	"trip_driver_changed": {
+		"en": "The trip driver has changed, reload the trip",
+		"ru": "Водитель рейса изменился, обновите рейс",
+		"uz": "Reys haydovchisi o'zgardi, reysni yangilang",
+		"tr": "Seferin sürücüsü değişti, seferi yenileyin",
+		"zh": "行程司机已变更，请刷新",
+	},
+	"penalty_rule_not_found": {
+		"en": "Penalty rule not found",
+		"ru": "Правило штрафа не найдено",
+		"uz": "Jarima qoidasi topilmadi",
+		"tr": "Ceza kuralı bulunamadı",
+		"zh": "未找到罚款规则",
+	},
+	"invalid_incident_type": {
+		"en": "Invalid incident type",
+		"ru": "Недопустимый тип инцидента",
+		"uz": "Hodisa turi noto'g'ri",
+		"tr": "Geçersiz olay türü",
+		"zh": "无效的事件类型",
+	},
+	"invalid_incident_severity": {
+		"en": "Invalid incident severity",
+		"ru": "Недопустимая серьёзность инцидента",
+		"uz": "Hodisa darajasi noto'g'ri",
+		"tr": "Geçersiz olay önem derecesi",
+		"zh": "无效的事件严重程度",
+	},
+	"incident_not_found": {
+		"en": "Incident not found",
+		"ru": "Инцидент не найден",
+		"uz": "Hodisa topilmadi",
+		"tr": "Olay bulunamadı",
+		"zh": "未找到事件",
+	},
+	"incident_already_resolved": {
+		"en": "Incident is already resolved",
+		"ru": "Инцидент уже решён",
+		"uz": "Hodisa allaqachon hal qilingan",
+		"tr": "Olay zaten çözüldü",
+		"zh": "事件已解决",
+	},
+	"incident_photo_limit": {
+		"en": "Photo limit for this incident reached",
+		"ru": "Достигнут лимит фото для инцидента",
+		"uz": "Hodisa uchun rasmlar chegarasiga yetildi",
+		"tr": "Bu olay için fotoğraf sınırına ulaşıldı",
+		"zh": "该事件的照片数量已达上限",
+	},
+	"invalid_incident_transition": {
+		"en": "Incident status cannot be changed this way",
+		"ru": "Нельзя так изменить статус инцидента",
+		"uz": "Hodisa holatini bunday o'zgartirib bo'lmaydi",
+		"tr": "Olay durumu bu şekilde değiştirilemez",
+		"zh": "无法这样更改事件状态",
+	},
+	"invalid_city_code": {
+		"en": "Unknown city code or city has no coordinates",
+		"ru": "Неизвестный код города или у города нет координат",
+		"uz": "Shahar kodi noma'lum yoki shahar koordinatalari yo'q",
+		"tr": "Bilinmeyen şehir kodu veya şehrin koordinatları yok",
+		"zh": "城市代码未知或城市没有坐标",
+	},
+	"invalid_search_radius": {
+		"en": "Search radius must be between 1 and 1000 km",
+		"ru": "Радиус поиска должен быть от 1 до 1000 км",
+		"uz": "Qidiruv radiusi 1 dan 1000 km gacha bo'lishi kerak",
+		"tr": "Arama yarıçapı 1 ile 1000 km arasında olmalıdır",
+		"zh": "搜索半径必须在1到1000公里之间",
+	},
+	"invalid_truck_listing_dates": {
+		"en": "available_to must be in the future and not before available_from",
+		"ru": "available_to должно быть в будущем и не раньше available_from",
+		"uz": "available_to kelajakda va available_from dan oldin bo'lmasligi kerak",
+		"tr": "available_to gelecekte olmalı ve available_from'dan önce olmamalıdır",
+		"zh": "available_to 必须是将来的时间且不早于 available_from",
+	},
+	"invalid_truck_type": {
+		"en": "Invalid truck type",
+		"ru": "Неверный тип кузова",
+		"uz": "Kuzov turi noto'g'ri",
+		"tr": "Geçersiz kasa tipi",
+		"zh": "车厢类型无效",
+	},
+	"offer_already_pending": {
+		"en": "A pending offer for this cargo and driver already exists",
+		"ru": "Для этого груза и водителя уже есть ожидающее предложение",
+		"uz": "Ushbu yuk va haydovchi uchun kutilayotgan taklif allaqachon mavjud",
+		"tr": "Bu yük ve sürücü için bekleyen bir teklif zaten var",
+		"zh": "该货物和司机已有待处理的报价",
+	},
+	"truck_listing_location_required": {
+		"en": "Specify from_city_code or from_lat and from_lng",
+		"ru": "Укажите from_city_code или from_lat и from_lng",
+		"uz": "from_city_code yoki from_lat va from_lng ni ko'rsating",
+		"tr": "from_city_code veya from_lat ve from_lng belirtin",
+		"zh": "请指定 from_city_code 或 from_lat 和 from_lng",
+	},
+	"truck_listing_not_active": {
+		"en": "Truck listing is not active",
+		"ru": "Объявление о машине не активно",
+		"uz": "Mashina e'loni faol emas",
+		"tr": "Araç ilanı aktif değil",
+		"zh": "车辆信息未激活",
+	},
+	"truck_listing_not_found": {
+		"en": "Truck listing not found",
+		"ru": "Объявление о машине не найдено",
+		"uz": "Mashina e'loni topilmadi",
+		"tr": "Araç ilanı bulunamadı",
+		"zh": "未找到车辆信息",
+	},
+	"trip_unload_point_unknown": {
+		"en": "Trip has no main unload point",
+		"ru": "У рейса нет основной точки выгрузки",
+		"uz": "Reysda asosiy tushirish nuqtasi yo'q",
+		"tr": "Seferin ana boşaltma noktası yok",
+		"zh": "行程没有主卸货点",
+	},
+	"your_role_cannot_edit_company": {
+		"en": "Only the company owner or CEO can change company settings",
+		"ru": "Менять настройки компании могут только владелец или CEO",
+		"uz": "Kompaniya sozlamalarini faqat egasi yoki CEO o'zgartira oladi",
+		"tr": "Şirket ayarlarını yalnızca şirket sahibi veya CEO değiştirebilir",
+		"zh": "只有公司所有者或CEO可以更改公司设置",
+	},
 }
 
 // Msg returns localized API description for key. Fallback: en -> ru -> key.
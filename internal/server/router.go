@@ -15,6 +15,7 @@ import (
 	"sarbonNew/internal/cargorecommendations"
 	"sarbonNew/internal/chat"
 	"sarbonNew/internal/companies"
+	"sarbonNew/internal/currency"
 	"sarbonNew/internal/companytz"
 	"sarbonNew/internal/config"
 	"sarbonNew/internal/dispatchercompanies"
@@ -23,8 +24,16 @@ import (
 	"sarbonNew/internal/driverinvitations"
 	"sarbonNew/internal/drivers"
 	"sarbonNew/internal/drivertodispatcherinvitations"
+	"sarbonNew/internal/documents"
+	"sarbonNew/internal/exports"
 	"sarbonNew/internal/goadmin"
 	"sarbonNew/internal/infra"
+	"sarbonNew/internal/invoices"
+	"sarbonNew/internal/ledger"
+	"sarbonNew/internal/notifications"
+	"sarbonNew/internal/pricing"
+	"sarbonNew/internal/reviews"
+	"sarbonNew/internal/routing"
 	"sarbonNew/internal/security"
 	"sarbonNew/internal/server/handlers"
 	"sarbonNew/internal/server/mw"
@@ -32,7 +41,9 @@ import (
 	"sarbonNew/internal/server/swaggerui"
 	"sarbonNew/internal/store"
 	"sarbonNew/internal/telegram"
+	"sarbonNew/internal/tracking"
 	"sarbonNew/internal/trips"
+	"sarbonNew/internal/trucklistings"
 )
 
 func NewRouter(cfg config.Config, deps *infra.Infra, logger *zap.Logger) http.Handler {
@@ -83,6 +94,7 @@ func NewRouter(cfg config.Config, deps *infra.Infra, logger *zap.Logger) http.Ha
 	appusersRepo := appusers.NewRepo(deps.PG)
 	cargoRepo := cargo.NewRepo(deps.PG)
 	tripsRepo := trips.NewRepo(deps.PG)
+	routeEstimator := newRouteEstimator(cfg)
 	dcrRepo := dispatchercompanies.NewRepo(deps.PG)
 	dispInvRepo := dispatcherinvitations.NewRepo(deps.PG)
 	driverInvRepo := driverinvitations.NewRepo(deps.PG)
@@ -134,7 +146,17 @@ func NewRouter(cfg config.Config, deps *infra.Infra, logger *zap.Logger) http.Ha
 	dispProfileH := handlers.NewDispatcherProfileHandler(logger, dispatchersRepo, dispPhoneActions, tgClient, cfg.OTPTTL, cfg.OTPLength)
 	adminAuthH := handlers.NewAdminAuthHandler(logger, adminsRepo, jwtm, refreshStore)
 	adminCompaniesH := handlers.NewAdminCompaniesHandler(logger, companiesRepo, appusersRepo)
-	cargoH := handlers.NewCargoHandler(logger, cargoRepo, tripsRepo, driversRepo, jwtm, cfg)
+	chatRepo := chat.NewRepo(deps.PG)
+	chatPresence := chat.NewPresenceStore(deps.Redis)
+	chatHub := chat.NewHub(chatPresence, logger)
+	notificationsRepo := notifications.NewRepo(deps.PG)
+	notifier := notifications.NewNotifier(notificationsRepo, chatHub, logger)
+	notificationsH := handlers.NewNotificationsHandler(logger, notificationsRepo)
+	currencyRepo := currency.NewRepo(deps.PG)
+	currencyH := handlers.NewCurrencyRatesHandler(logger, currencyRepo)
+	pricingRepo := pricing.NewRepo(deps.PG)
+	displayCurrencyPrefs := handlers.NewDisplayCurrencyPrefs(jwtm, driversRepo, dispatchersRepo, companiesRepo)
+	cargoH := handlers.NewCargoHandler(logger, cargoRepo, tripsRepo, driversRepo, companiesRepo, routeEstimator, currencyRepo, pricingRepo, displayCurrencyPrefs, notifier, jwtm, cfg)
 	adminCargoModH := handlers.NewAdminCargoModerationHandler(logger, cargoRepo)
 	dispCompaniesH := handlers.NewDispatcherCompaniesHandler(logger, companiesRepo, dcrRepo, jwtm)
 	dispInvH := handlers.NewDispatcherInvitationsHandler(logger, dispInvRepo, dcrRepo, dispatchersRepo)
@@ -142,13 +164,30 @@ func NewRouter(cfg config.Config, deps *infra.Infra, logger *zap.Logger) http.Ha
 	driverDispH := handlers.NewDriverDispatchersHandler(logger, driversRepo, dispatchersRepo, dcrRepo)
 	d2dInvRepo := drivertodispatcherinvitations.NewRepo(deps.PG)
 	d2dInvH := handlers.NewDriverToDispatcherInvitationsHandler(logger, d2dInvRepo, driversRepo, dispatchersRepo)
-	tripsH := handlers.NewTripsHandler(logger, tripsRepo, cargoRepo)
+	pricingH := handlers.NewPricingHandler(logger, pricingRepo, cargoRepo, routeEstimator)
+	tripsH := handlers.NewTripsHandler(logger, tripsRepo, cargoRepo, companiesRepo, driversRepo, routeEstimator)
 	cargoRecRepo := cargorecommendations.NewRepo(deps.PG)
 	cargoRecH := handlers.NewCargoRecommendationsHandler(logger, cargoRecRepo, cargoRepo, tripsRepo)
+	reviewsRepo := reviews.NewRepo(deps.PG)
+	reviewsH := handlers.NewReviewsHandler(logger, reviewsRepo, tripsRepo, cargoRepo)
+	reportExportsH := handlers.NewReportExportsHandler(logger, exports.NewRepo(deps.PG), exports.NewGenerator(cargoRepo, tripsRepo, currencyRepo))
+	tripPODH := handlers.NewTripPODHandler(logger, tripsRepo, cargoRepo, companiesRepo, notifier, cfg.PODDisputeWindow, cfg.DeliveryPINMaxAttempts)
+	tripPINH := handlers.NewTripDeliveryPINHandler(logger, tripsRepo, cargoRepo, tgClient, cfg.DeliveryPINMaxAttempts)
+	tripTrackingH := handlers.NewTripTrackingHandler(logger, tracking.NewRepo(deps.PG), tripsRepo, cargoRepo, driversRepo, routeEstimator, cfg.TrackingLinkTTL, cfg.TrackingLinkMaxTTL, cfg.TrackingLinkBaseURL)
+	invoicesRepo := invoices.NewRepo(deps.PG)
+	invoicesH := handlers.NewInvoicesHandler(logger, invoicesRepo, invoices.NewGenerator(invoicesRepo, cargoRepo, driversRepo, invoiceTerms(cfg)), tripsRepo, cargoRepo, notifier)
+	ledgerH := handlers.NewLedgerHandler(logger, ledger.NewRepo(deps.PG), driversRepo)
+	tripExpensesH := handlers.NewTripExpensesHandler(logger, tripsRepo, cargoRepo, currencyRepo, notifier)
+	tripStopsH := handlers.NewTripStopsHandler(logger, tripsRepo, cargoRepo, companiesRepo)
+	tripCustomsH := handlers.NewTripCustomsHandler(logger, tripsRepo, cargoRepo, notifier)
+	tripConsolidationH := handlers.NewTripConsolidationHandler(logger, tripsRepo, cargoRepo, driversRepo)
+	tripCancellationsH := handlers.NewTripCancellationsHandler(logger, tripsRepo, cargoRepo, driversRepo, companiesRepo, notifier)
+	tripIncidentsH := handlers.NewTripIncidentsHandler(logger, tripsRepo, cargoRepo, driversRepo, companiesRepo, notifier)
+	tripBackhaulH := handlers.NewTripBackhaulHandler(logger, tripsRepo, cargoRepo, driversRepo, routeEstimator, currencyRepo)
+	truckListingsH := handlers.NewTruckListingsHandler(logger, trucklistings.NewRepo(deps.PG), cargoRepo, driversRepo, notifier, cfg.OfferDefaultValidity)
+	documentsRepo := documents.NewRepo(deps.PG)
+	tripDocsH := handlers.NewTripDocumentsHandler(logger, documentsRepo, documents.NewGenerator(documentsRepo, cargoRepo, driversRepo, companiesRepo, dispatchersRepo), tripsRepo, cargoRepo)
 
-	chatRepo := chat.NewRepo(deps.PG)
-	chatPresence := chat.NewPresenceStore(deps.Redis)
-	chatHub := chat.NewHub(chatPresence, logger)
 	chatH := handlers.NewChatHandler(logger, chatRepo, chatPresence, chatHub)
 
 	approlesRepo := approles.NewRepo(deps.PG)
@@ -175,26 +214,50 @@ func NewRouter(cfg config.Config, deps *infra.Infra, logger *zap.Logger) http.Ha
 	// Reference: справочники (общие для водителя, диспетчера и др.)
 	v1.GET("/reference/drivers", handlers.GetReferenceDrivers)
 	v1.GET("/reference/cargo", handlers.GetReferenceCargo)
+	v1.GET("/reference/currency-rates", currencyH.Current)
 	v1.GET("/reference/company", handlers.GetReferenceCompany(approlesRepo))
 	v1.GET("/reference/admin", handlers.GetReferenceAdmin)
 	v1.GET("/reference/dispatchers", handlers.GetReferenceDispatchers)
 	v1.GET("/reference/cities", handlers.GetReferenceCities())
 	v1.GET("/reference/countries", handlers.GetReferenceCountries())
 
+	// Reviews: публичные отзывы на профиле (водитель, диспетчер, компания) + средний рейтинг
+	v1.GET("/reviews", reviewsH.ListPublic)
+
 	// API /api/cargo (same base headers as v1)
 	api := r.Group("/api")
 	api.Use(mw.RequireBaseHeaders(cfg))
 	api.POST("/cargo", cargoH.Create)
 	api.GET("/cargo", cargoH.List)
 	api.GET("/cargo/:id", cargoH.GetByID)
+	api.POST("/cargo/price-suggestion", pricingH.Preview)
+	api.POST("/cargo/import", cargoH.ImportCargo)
+	api.GET("/cargo/import/template", cargoH.ImportCargoTemplate)
+	api.GET("/cargo/:id/price-suggestion", pricingH.ForCargo)
 	api.PUT("/cargo/:id", cargoH.Update)
 	api.DELETE("/cargo/:id", cargoH.Delete)
 	api.PATCH("/cargo/:id/status", cargoH.PatchStatus)
 	api.POST("/cargo/:id/offers", cargoH.CreateOffer)
 	api.GET("/cargo/:id/offers", cargoH.ListOffers)
+	api.POST("/cargo/:id/auction", cargoH.StartAuction)
+	api.GET("/cargo/:id/auction", cargoH.GetAuction)
+	api.DELETE("/cargo/:id/auction", cargoH.CancelAuction)
+	api.POST("/cargo/:id/auction/award", cargoH.AwardAuction)
 	api.POST("/offers/:id/accept", cargoH.AcceptOffer)
+	api.GET("/offers/:id/rounds", cargoH.OfferRounds)
 	api.GET("/trips", tripsH.List)
 	api.GET("/trips/:id", tripsH.Get)
+	api.GET("/trips/:id/eta", tripsH.ETA)
+	api.GET("/trips/:id/reviews", reviewsH.ListByTrip)
+	api.GET("/trips/:id/pod", tripPODH.Get)
+	api.GET("/trips/:id/pod/photos/:photoId", tripPODH.Photo)
+	api.GET("/trips/:id/pod/signature", tripPODH.Signature)
+	api.GET("/trips/:id/expenses/:expenseId/receipt", tripExpensesH.Receipt)
+	api.GET("/trips/:id/customs/documents/:docId/file", tripCustomsH.DocumentFile)
+	api.GET("/trips/:id/incidents/:incidentId/photos/:photoId", tripIncidentsH.Photo)
+
+	// Публичное отслеживание рейса по ссылке (получатель, конечный клиент) — без base headers и авторизации
+	r.GET("/public/track/:token", tripTrackingH.Track)
 
 	v1.POST("/dispatchers/auth/phone", dispAuthH.SendOTP)
 	v1.POST("/dispatchers/auth/otp/verify", dispAuthH.VerifyOTP)
@@ -218,6 +281,20 @@ func NewRouter(cfg config.Config, deps *infra.Infra, logger *zap.Logger) http.Ha
 	adminAuthed.GET("/cargo/moderation", adminCargoModH.ListPending)
 	adminAuthed.POST("/cargo/:id/moderation/accept", adminCargoModH.Accept)
 	adminAuthed.POST("/cargo/:id/moderation/reject", adminCargoModH.Reject)
+	adminAuthed.GET("/reviews", reviewsH.AdminList)
+	adminAuthed.POST("/reviews/:id/hide", reviewsH.AdminHide)
+	adminAuthed.POST("/reviews/:id/restore", reviewsH.AdminRestore)
+	adminAuthed.GET("/currency-rates", currencyH.AdminList)
+	adminAuthed.PUT("/currency-rates", currencyH.AdminUpsert)
+	adminAuthed.POST("/currency-rates/import", currencyH.AdminImport)
+	adminAuthed.DELETE("/currency-rates/:currency/:date", currencyH.AdminDelete)
+	adminAuthed.GET("/trip-cancel-penalties", tripCancellationsH.AdminPenaltyRules)
+	adminAuthed.PUT("/trip-cancel-penalties", tripCancellationsH.AdminUpsertPenaltyRule)
+	adminAuthed.DELETE("/trip-cancel-penalties/:id", tripCancellationsH.AdminDeletePenaltyRule)
+	adminAuthed.GET("/reports/:kind", reportExportsH.Export)
+	adminAuthed.GET("/report-exports", reportExportsH.List)
+	adminAuthed.GET("/report-exports/:id", reportExportsH.Get)
+	adminAuthed.GET("/report-exports/:id/download", reportExportsH.Download)
 
 	driverAuthed := v1.Group("/driver")
 	driverAuthed.Use(mw.RequireDriver(jwtm, refreshStore))
@@ -225,6 +302,8 @@ func NewRouter(cfg config.Config, deps *infra.Infra, logger *zap.Logger) http.Ha
 	driverAuthed.GET("/profile", profileH.Get)
 	driverAuthed.PATCH("/profile/driver", profileH.PatchDriver)
 	driverAuthed.PUT("/profile/heartbeat", profileH.Heartbeat)
+	driverAuthed.GET("/profile/display-currency", profileH.GetDisplayCurrency)
+	driverAuthed.PUT("/profile/display-currency", profileH.PutDisplayCurrency)
 	driverAuthed.POST("/profile/photo", profileH.UploadPhoto)
 	driverAuthed.GET("/profile/photo", profileH.GetPhoto)
 	driverAuthed.DELETE("/profile/photo", profileH.DeletePhoto)
@@ -240,6 +319,49 @@ func NewRouter(cfg config.Config, deps *infra.Infra, logger *zap.Logger) http.Ha
 	driverAuthed.POST("/trips/:id/confirm", tripsH.DriverConfirm)
 	driverAuthed.POST("/trips/:id/reject", tripsH.DriverReject)
 	driverAuthed.PATCH("/trips/:id/status", tripsH.PatchStatus)
+	driverAuthed.POST("/trips/:id/review", reviewsH.CreateByDriver)
+	driverAuthed.GET("/trips/:id/documents/:type", tripDocsH.DownloadMy)
+	driverAuthed.POST("/trips/:id/pod/photos", tripPODH.UploadPhoto)
+	driverAuthed.DELETE("/trips/:id/pod/photos/:photoId", tripPODH.DeletePhoto)
+	driverAuthed.POST("/trips/:id/pod", tripPODH.Submit)
+	driverAuthed.GET("/trips/:id/invoice", invoicesH.GetMy)
+	driverAuthed.POST("/trips/:id/invoice/payments", invoicesH.AddPaymentByDriver)
+	driverAuthed.GET("/invoices", invoicesH.ListDriver)
+	driverAuthed.GET("/invoices/balance", invoicesH.BalanceDriver)
+	driverAuthed.GET("/commission", ledgerH.MyCommission)
+	driverAuthed.GET("/earnings/statement", ledgerH.DriverStatement)
+	driverAuthed.POST("/trips/:id/expenses", tripExpensesH.Add)
+	driverAuthed.GET("/trips/:id/expenses", tripExpensesH.ListMy)
+	driverAuthed.DELETE("/trips/:id/expenses/:expenseId", tripExpensesH.Delete)
+	driverAuthed.GET("/trips/:id/stops", tripStopsH.ListMy)
+	driverAuthed.POST("/trips/:id/stops/:pointId/arrive", tripStopsH.Arrive)
+	driverAuthed.POST("/trips/:id/stops/:pointId/depart", tripStopsH.Depart)
+	driverAuthed.GET("/trips/:id/customs", tripCustomsH.ListMy)
+	driverAuthed.POST("/trips/:id/customs/:pointId/status", tripCustomsH.SetStatusByDriver)
+	driverAuthed.POST("/trips/:id/customs/:pointId/documents", tripCustomsH.AddDocumentByDriver)
+	driverAuthed.DELETE("/trips/:id/customs/:pointId/documents/:docId", tripCustomsH.DeleteDocumentByDriver)
+	driverAuthed.GET("/trips/:id/cargos", tripConsolidationH.ListMy)
+	driverAuthed.POST("/trips/:id/cancel", tripCancellationsH.CancelByDriver)
+	driverAuthed.GET("/trips/:id/cancellation", tripCancellationsH.GetMy)
+	driverAuthed.POST("/trips/:id/incidents", tripIncidentsH.Report)
+	driverAuthed.POST("/trips/:id/sos", tripIncidentsH.SOS)
+	driverAuthed.GET("/trips/:id/incidents", tripIncidentsH.ListMy)
+	driverAuthed.POST("/trips/:id/incidents/:incidentId/photos", tripIncidentsH.AddPhoto)
+	driverAuthed.POST("/trips/:id/incidents/:incidentId/resolve", tripIncidentsH.ResolveByDriver)
+	driverAuthed.GET("/incidents", tripIncidentsH.HistoryMy)
+	driverAuthed.GET("/trips/:id/backhaul", tripBackhaulH.SuggestMy)
+	driverAuthed.POST("/truck-listings", truckListingsH.CreateMy)
+	driverAuthed.GET("/truck-listings", truckListingsH.ListMy)
+	driverAuthed.PUT("/truck-listings/:id", truckListingsH.Update)
+	driverAuthed.POST("/truck-listings/:id/close", truckListingsH.Close)
+	driverAuthed.GET("/truck-listings/:id/proposals", truckListingsH.Proposals)
+	driverAuthed.POST("/offers/:id/counter", cargoH.DriverCounter)
+	driverAuthed.POST("/offers/:id/accept", cargoH.DriverAcceptOffer)
+	driverAuthed.GET("/offers", cargoH.ListMyOffers)
+	driverAuthed.POST("/offers/:id/withdraw", cargoH.WithdrawOffer)
+	driverAuthed.POST("/cargo/:id/bid", cargoH.DriverBid)
+	driverAuthed.GET("/notifications", notificationsH.ListDriver)
+	driverAuthed.POST("/notifications/:id/read", notificationsH.MarkReadDriver)
 	driverAuthed.GET("/driver-invitations", driverInvH.ListInvitations)
 	driverAuthed.POST("/driver-invitations/accept", driverInvH.Accept)
 	driverAuthed.POST("/driver-invitations/decline", driverInvH.Decline)
@@ -261,6 +383,8 @@ func NewRouter(cfg config.Config, deps *infra.Infra, logger *zap.Logger) http.Ha
 	dispAuthed.POST("/profile/photo", dispProfileH.UploadPhoto)
 	dispAuthed.GET("/profile/photo", dispProfileH.GetPhoto)
 	dispAuthed.PUT("/profile/password", dispProfileH.ChangePassword)
+	dispAuthed.GET("/profile/display-currency", dispProfileH.GetDisplayCurrency)
+	dispAuthed.PUT("/profile/display-currency", dispProfileH.PutDisplayCurrency)
 	dispAuthed.POST("/profile/phone-change/request", dispProfileH.PhoneChangeRequest)
 	dispAuthed.POST("/profile/phone-change/verify", dispProfileH.PhoneChangeVerify)
 	dispAuthed.DELETE("/profile", dispProfileH.Delete)
@@ -282,9 +406,69 @@ func NewRouter(cfg config.Config, deps *infra.Infra, logger *zap.Logger) http.Ha
 	dispAuthed.POST("/invitations-from-drivers/decline", d2dInvH.DeclineByDispatcher)
 	dispAuthed.PUT("/drivers/:driverId/power", driverInvH.SetDriverPower)
 	dispAuthed.PUT("/drivers/:driverId/trailer", driverInvH.SetDriverTrailer)
+	dispAuthed.GET("/drivers/:driverId/commission", ledgerH.GetCommission)
+	dispAuthed.PUT("/drivers/:driverId/commission", ledgerH.SetCommission)
+	dispAuthed.DELETE("/drivers/:driverId/commission", ledgerH.DeleteCommission)
+	dispAuthed.GET("/commissions", ledgerH.ListCommissions)
+	dispAuthed.GET("/earnings/statement", ledgerH.DispatcherStatement)
 	dispAuthed.PATCH("/trips/:id/assign-driver", tripsH.AssignDriver)
+	dispAuthed.POST("/trips/:id/review", reviewsH.CreateByDispatcher)
+	dispAuthed.POST("/trips/:id/pod/dispute", tripPODH.DisputeByDispatcher)
+	dispAuthed.GET("/trips/:id/documents", tripDocsH.List)
+	dispAuthed.GET("/trips/:id/documents/:type", tripDocsH.Download)
+	dispAuthed.POST("/trips/:id/documents/:type", tripDocsH.Regenerate)
+	dispAuthed.GET("/trips/:id/delivery-pin", tripPINH.Get)
+	dispAuthed.POST("/trips/:id/delivery-pin/send", tripPINH.Send)
+	dispAuthed.POST("/trips/:id/delivery-pin/regenerate", tripPINH.Regenerate)
+	dispAuthed.POST("/trips/:id/tracking-links", tripTrackingH.Create)
+	dispAuthed.GET("/trips/:id/tracking-links", tripTrackingH.List)
+	dispAuthed.DELETE("/trips/:id/tracking-links/:linkId", tripTrackingH.Revoke)
+	dispAuthed.GET("/trips/:id/invoice", invoicesH.GetForTrip)
+	dispAuthed.POST("/trips/:id/invoice/payments", invoicesH.AddPayment)
+	dispAuthed.GET("/invoices", invoicesH.ListDispatcher)
+	dispAuthed.GET("/invoices/balance", invoicesH.BalanceDispatcher)
+	dispAuthed.GET("/trips/:id/expenses", tripExpensesH.List)
+	dispAuthed.POST("/trips/:id/expenses/:expenseId/approve", tripExpensesH.Approve)
+	dispAuthed.POST("/trips/:id/expenses/:expenseId/reject", tripExpensesH.Reject)
+	dispAuthed.GET("/trips/:id/stops", tripStopsH.List)
+	dispAuthed.GET("/trips/:id/customs", tripCustomsH.List)
+	dispAuthed.POST("/trips/:id/customs/:pointId/status", tripCustomsH.SetStatus)
+	dispAuthed.POST("/trips/:id/customs/:pointId/documents", tripCustomsH.AddDocument)
+	dispAuthed.DELETE("/trips/:id/customs/:pointId/documents/:docId", tripCustomsH.DeleteDocument)
+	dispAuthed.GET("/trips/:id/cargos", tripConsolidationH.List)
+	dispAuthed.GET("/trips/:id/cargos/candidates", tripConsolidationH.Candidates)
+	dispAuthed.POST("/trips/:id/cargos", tripConsolidationH.Add)
+	dispAuthed.DELETE("/trips/:id/cargos/:tripId", tripConsolidationH.Remove)
+	dispAuthed.POST("/trips/:id/cancel", tripCancellationsH.Cancel)
+	dispAuthed.GET("/trips/:id/cancellation", tripCancellationsH.Get)
+	dispAuthed.POST("/trips/:id/driver-swap", tripCancellationsH.SwapDriver)
+	dispAuthed.GET("/trips/:id/handovers", tripCancellationsH.Handovers)
+	dispAuthed.GET("/trips/:id/incidents", tripIncidentsH.List)
+	dispAuthed.POST("/trips/:id/incidents/:incidentId/acknowledge", tripIncidentsH.Acknowledge)
+	dispAuthed.POST("/trips/:id/incidents/:incidentId/resolve", tripIncidentsH.Resolve)
+	dispAuthed.GET("/drivers/:driverId/incidents", tripIncidentsH.DriverHistory)
+	dispAuthed.GET("/trips/:id/backhaul", tripBackhaulH.Suggest)
+	dispAuthed.POST("/truck-listings", truckListingsH.Create)
+	dispAuthed.GET("/truck-listings", truckListingsH.List)
+	dispAuthed.GET("/truck-listings/search", truckListingsH.Search)
+	dispAuthed.PUT("/truck-listings/:id", truckListingsH.Update)
+	dispAuthed.POST("/truck-listings/:id/close", truckListingsH.Close)
+	dispAuthed.GET("/truck-listings/:id/proposals", truckListingsH.Proposals)
+	dispAuthed.POST("/truck-listings/:id/proposals", truckListingsH.Propose)
 	dispAuthed.POST("/offers/:id/reject", cargoH.RejectOfferDispatcher)
+	dispAuthed.POST("/offers/:id/counter", cargoH.DispatcherCounter)
+	dispAuthed.GET("/notifications", notificationsH.ListDispatcher)
+	dispAuthed.POST("/notifications/:id/read", notificationsH.MarkReadDispatcher)
 	dispAuthed.POST("/cargo/:id/recommend", cargoRecH.Recommend)
+	dispAuthed.GET("/cargo-templates", cargoH.ListCargoTemplates)
+	dispAuthed.POST("/cargo-templates", cargoH.CreateCargoTemplate)
+	dispAuthed.GET("/cargo-templates/:id", cargoH.GetCargoTemplate)
+	dispAuthed.PUT("/cargo-templates/:id", cargoH.UpdateCargoTemplate)
+	dispAuthed.DELETE("/cargo-templates/:id", cargoH.DeleteCargoTemplate)
+	dispAuthed.POST("/cargo-templates/:id/cargo", cargoH.CreateCargoFromTemplate)
+	dispAuthed.POST("/cargo-templates/:id/schedules", cargoH.CreateCargoSchedule)
+	dispAuthed.PUT("/cargo-schedules/:id", cargoH.UpdateCargoSchedule)
+	dispAuthed.DELETE("/cargo-schedules/:id", cargoH.DeleteCargoSchedule)
 
 	// Company users (company_users): OTP auth, companies, invitations
 	appUserAuthed := v1.Group("")
@@ -295,8 +479,54 @@ func NewRouter(cfg config.Config, deps *infra.Infra, logger *zap.Logger) http.Ha
 	appUserAuthed.POST("/companies/:companyId/invitations", companyTZH.CreateInvitation)
 	appUserAuthed.POST("/invitations/accept", companyTZH.AcceptInvitation)
 	appUserAuthed.GET("/companies/:companyId/users", companyTZH.ListCompanyUsers)
+	appUserAuthed.GET("/companies/:companyId/stats", companyTZH.CompanyStats)
+	appUserAuthed.GET("/companies/:companyId/display-currency", companyTZH.GetDisplayCurrency)
+	appUserAuthed.PUT("/companies/:companyId/display-currency", companyTZH.PutDisplayCurrency)
 	appUserAuthed.PUT("/companies/:companyId/users/:userId/role", companyTZH.UpdateUserRole)
 	appUserAuthed.DELETE("/companies/:companyId/users/:userId", companyTZH.RemoveUser)
+	appUserAuthed.POST("/trips/:id/review", reviewsH.CreateByCompany)
+	appUserAuthed.POST("/trips/:id/pod/dispute", tripPODH.DisputeByCompany)
+	appUserAuthed.GET("/trips/:id/documents", tripDocsH.List)
+	appUserAuthed.GET("/trips/:id/documents/:type", tripDocsH.Download)
+	appUserAuthed.POST("/trips/:id/documents/:type", tripDocsH.Regenerate)
+	appUserAuthed.GET("/trips/:id/delivery-pin", tripPINH.Get)
+	appUserAuthed.POST("/trips/:id/delivery-pin/send", tripPINH.Send)
+	appUserAuthed.POST("/trips/:id/delivery-pin/regenerate", tripPINH.Regenerate)
+	appUserAuthed.POST("/trips/:id/tracking-links", tripTrackingH.Create)
+	appUserAuthed.GET("/trips/:id/tracking-links", tripTrackingH.List)
+	appUserAuthed.DELETE("/trips/:id/tracking-links/:linkId", tripTrackingH.Revoke)
+	appUserAuthed.GET("/trips/:id/invoice", invoicesH.GetForTrip)
+	appUserAuthed.POST("/trips/:id/invoice/payments", invoicesH.AddPayment)
+	appUserAuthed.GET("/invoices", invoicesH.ListCompany)
+	appUserAuthed.GET("/invoices/balance", invoicesH.BalanceCompany)
+	appUserAuthed.GET("/trips/:id/expenses", tripExpensesH.List)
+	appUserAuthed.POST("/trips/:id/expenses/:expenseId/approve", tripExpensesH.Approve)
+	appUserAuthed.POST("/trips/:id/expenses/:expenseId/reject", tripExpensesH.Reject)
+	appUserAuthed.GET("/trips/:id/stops", tripStopsH.List)
+	appUserAuthed.GET("/trips/:id/customs", tripCustomsH.List)
+	appUserAuthed.POST("/trips/:id/customs/:pointId/status", tripCustomsH.SetStatus)
+	appUserAuthed.POST("/trips/:id/customs/:pointId/documents", tripCustomsH.AddDocument)
+	appUserAuthed.DELETE("/trips/:id/customs/:pointId/documents/:docId", tripCustomsH.DeleteDocument)
+	appUserAuthed.POST("/trips/:id/cancel", tripCancellationsH.Cancel)
+	appUserAuthed.GET("/trips/:id/cancellation", tripCancellationsH.Get)
+	appUserAuthed.GET("/trips/:id/incidents", tripIncidentsH.List)
+	appUserAuthed.POST("/trips/:id/incidents/:incidentId/acknowledge", tripIncidentsH.Acknowledge)
+	appUserAuthed.POST("/trips/:id/incidents/:incidentId/resolve", tripIncidentsH.Resolve)
+	appUserAuthed.GET("/drivers/:driverId/incidents", tripIncidentsH.DriverHistory)
+	appUserAuthed.GET("/truck-listings/search", truckListingsH.Search)
+	appUserAuthed.POST("/truck-listings/:id/proposals", truckListingsH.Propose)
+	appUserAuthed.POST("/offers/:id/counter", cargoH.CompanyCounter)
+	appUserAuthed.GET("/notifications", notificationsH.ListCompany)
+	appUserAuthed.POST("/notifications/:id/read", notificationsH.MarkReadCompany)
+	appUserAuthed.GET("/cargo-templates", cargoH.ListCargoTemplates)
+	appUserAuthed.POST("/cargo-templates", cargoH.CreateCargoTemplate)
+	appUserAuthed.GET("/cargo-templates/:id", cargoH.GetCargoTemplate)
+	appUserAuthed.PUT("/cargo-templates/:id", cargoH.UpdateCargoTemplate)
+	appUserAuthed.DELETE("/cargo-templates/:id", cargoH.DeleteCargoTemplate)
+	appUserAuthed.POST("/cargo-templates/:id/cargo", cargoH.CreateCargoFromTemplate)
+	appUserAuthed.POST("/cargo-templates/:id/schedules", cargoH.CreateCargoSchedule)
+	appUserAuthed.PUT("/cargo-schedules/:id", cargoH.UpdateCargoSchedule)
+	appUserAuthed.DELETE("/cargo-schedules/:id", cargoH.DeleteCargoSchedule)
 
 	// Chat (driver, dispatcher, admin): JWT or X-User-ID for Swagger testing; WS supports ?user_id= or ?token=
 	chatGroup := v1.Group("/chat")
@@ -312,3 +542,15 @@ func NewRouter(cfg config.Config, deps *infra.Infra, logger *zap.Logger) http.Ha
 
 	return r
 }
+
+// newRouteEstimator — оценка маршрутов по настройкам ROUTE_* (API и фоновые задачи).
+func newRouteEstimator(cfg config.Config) *routing.Estimator {
+	return routing.NewEstimator(routing.Config{
+		RoadFactor:        cfg.RouteRoadFactor,
+		DefaultSpeedKmh:   cfg.RouteDefaultSpeedKmh,
+		TruckSpeeds:       cfg.RouteTruckSpeeds,
+		CustomsDelay:      cfg.RouteCustomsDelay,
+		DailyDrivingLimit: cfg.RouteDailyDrivingLimit,
+		DailyRest:         cfg.RouteDailyRest,
+	})
+}
@@ -0,0 +1,294 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"sarbonNew/internal/cargo"
+	"sarbonNew/internal/config"
+	"sarbonNew/internal/currency"
+	"sarbonNew/internal/drivers"
+	"sarbonNew/internal/exports"
+	"sarbonNew/internal/infra"
+	"sarbonNew/internal/invoices"
+	"sarbonNew/internal/jobs"
+	"sarbonNew/internal/ledger"
+	"sarbonNew/internal/notifications"
+	"sarbonNew/internal/routing"
+	"sarbonNew/internal/server/handlers"
+	"sarbonNew/internal/tabular"
+	"sarbonNew/internal/timeutil"
+	"sarbonNew/internal/trips"
+)
+
+// StartWorkers запускает фоновые задачи API; останавливаются при отмене ctx.
+func StartWorkers(ctx context.Context, cfg config.Config, deps *infra.Infra, logger *zap.Logger) {
+	cargoRepo := cargo.NewRepo(deps.PG)
+	tripsRepo := trips.NewRepo(deps.PG)
+	notifier := notifications.NewNotifier(notifications.NewRepo(deps.PG), nil, logger)
+
+	jobs.Every(ctx, logger, "offer-expiry", cfg.OfferExpiryCheckEvery, func(ctx context.Context) error {
+		expired, err := cargoRepo.ExpireOffers(ctx)
+		if err != nil {
+			return err
+		}
+		for _, o := range expired {
+			notifier.Notify(ctx, notifications.RecipientDriver, o.CarrierID, notifications.KindOfferExpired, map[string]any{
+				"offer_id": o.ID.String(), "cargo_id": o.CargoID.String(), "price": o.Price, "currency": o.Currency,
+			})
+		}
+		if len(expired) > 0 {
+			logger.Info("offers expired", zap.Int("count", len(expired)))
+		}
+		return nil
+	})
+
+	routeEstimator := newRouteEstimator(cfg)
+	jobs.Every(ctx, logger, "cargo-schedules", cfg.CargoScheduleCheckEvery, func(ctx context.Context) error {
+		now := time.Now()
+		due, err := cargoRepo.DueSchedules(ctx, now)
+		if err != nil {
+			return err
+		}
+		for _, s := range due {
+			runCargoSchedule(ctx, cfg, cargoRepo, routeEstimator, logger, s, now)
+		}
+		return nil
+	})
+
+	ratesRepo := currency.NewRepo(deps.PG)
+	exportsRepo := exports.NewRepo(deps.PG)
+	exportsGen := exports.NewGenerator(cargoRepo, tripsRepo, ratesRepo)
+	jobs.Every(ctx, logger, "report-exports", cfg.ReportExportCheckEvery, func(ctx context.Context) error {
+		if n, err := exportsRepo.DeleteExpired(ctx); err != nil {
+			logger.Warn("report exports cleanup", zap.Error(err))
+		} else if n > 0 {
+			logger.Info("report exports expired", zap.Int64("count", n))
+		}
+		for {
+			e, err := exportsRepo.ClaimNext(ctx)
+			if err != nil || e == nil {
+				return err
+			}
+			runReportExport(ctx, cfg, exportsRepo, exportsGen, logger, e)
+		}
+	})
+
+	invoicesRepo := invoices.NewRepo(deps.PG)
+	invoicesGen := invoices.NewGenerator(invoicesRepo, cargoRepo, drivers.NewRepo(deps.PG), invoiceTerms(cfg))
+	jobs.Every(ctx, logger, "invoices", cfg.InvoiceCheckEvery, func(ctx context.Context) error {
+		return settleInvoices(ctx, invoicesRepo, invoicesGen, tripsRepo, notifier, logger)
+	})
+
+	ledgerRepo := ledger.NewRepo(deps.PG)
+	ledgerPoster := ledger.NewPoster(ledgerRepo, cargoRepo, drivers.NewRepo(deps.PG), ratesRepo)
+	jobs.Every(ctx, logger, "ledger", cfg.LedgerCheckEvery, func(ctx context.Context) error {
+		ids, err := ledgerRepo.TripsToPost(ctx, 100)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			t, err := tripsRepo.GetByID(ctx, id)
+			if err != nil || t == nil {
+				continue
+			}
+			tx, err := ledgerPoster.Post(ctx, t)
+			if err != nil {
+				attempts, ferr := ledgerRepo.RecordPostFailure(ctx, id, err, time.Now())
+				if ferr != nil {
+					logger.Error("ledger post failure", zap.Error(ferr), zap.String("trip_id", id.String()))
+				}
+				logger.Warn("ledger post trip", zap.Error(err), zap.String("trip_id", id.String()), zap.Int("attempts", attempts))
+				continue
+			}
+			if tx != nil {
+				logger.Info("ledger trip posted", zap.String("trip_id", id.String()), zap.Float64("commission", tx.Commission))
+			}
+		}
+		return nil
+	})
+
+	jobs.Every(ctx, logger, "auction-close", cfg.AuctionCloseCheckEvery, func(ctx context.Context) error {
+		due, err := cargoRepo.DueAuctions(ctx)
+		if err != nil {
+			return err
+		}
+		for _, cargoID := range due {
+			closeAuction(ctx, cargoRepo, tripsRepo, notifier, logger, cargoID)
+		}
+		return nil
+	})
+}
+
+// closeAuction завершает торги; при AUTO лучшая ставка принимается тем же путём, что и POST /api/offers/:id/accept
+// (AcceptOfferWithTrip).
+func closeAuction(ctx context.Context, cargoRepo *cargo.Repo, tripsRepo *trips.Repo, notifier *notifications.Notifier, logger *zap.Logger, cargoID uuid.UUID) {
+	a, best, err := cargoRepo.CloseAuction(ctx, cargoID)
+	if err != nil || a == nil {
+		if err != nil {
+			logger.Error("auction close", zap.Error(err), zap.String("cargo_id", cargoID.String()))
+		}
+		return
+	}
+	payload := map[string]any{"cargo_id": cargoID.String(), "status": a.Status}
+	if best != nil && a.WinnerSelection == cargo.WinnerAuto {
+		res, err := handlers.AcceptOfferWithTrip(ctx, cargoRepo, tripsRepo, *best, cargo.SideShipper)
+		if res.CargoID == uuid.Nil {
+			// победителя выберет владелец вручную (аукцион остаётся CLOSED)
+			logger.Warn("auction auto-accept", zap.Error(err), zap.String("offer_id", best.String()))
+		} else {
+			if err != nil {
+				logger.Error("auction auto-accept trip", zap.Error(err), zap.String("offer_id", best.String()))
+			}
+			if res.TripID != uuid.Nil {
+				payload["trip_id"] = res.TripID.String()
+			}
+			payload["status"] = cargo.AuctionAwarded
+			notifier.Notify(ctx, notifications.RecipientDriver, res.CarrierID, notifications.KindAuctionWon, map[string]any{
+				"cargo_id": cargoID.String(), "offer_id": best.String(),
+			})
+		}
+	}
+	if best != nil {
+		payload["best_offer_id"] = best.String()
+	}
+	if obj, _ := cargoRepo.GetByID(ctx, cargoID, false); obj != nil {
+		if obj.CreatedByType != nil && *obj.CreatedByType == "DISPATCHER" && obj.CreatedByID != nil {
+			notifier.Notify(ctx, notifications.RecipientDispatcher, *obj.CreatedByID, notifications.KindAuctionClosed, payload)
+		} else if obj.CompanyID != nil {
+			notifier.Notify(ctx, notifications.RecipientCompany, *obj.CompanyID, notifications.KindAuctionClosed, payload)
+		}
+	}
+}
+
+// runCargoSchedule создаёт груз по расписанию и переносит следующий запуск; ошибка сохраняется в last_error.
+// Пропущенные запуски (простой сервиса) не догоняются: создаётся один груз, следующий — по расписанию после now.
+func runCargoSchedule(ctx context.Context, cfg config.Config, cargoRepo *cargo.Repo, routes *routing.Estimator, logger *zap.Logger, s cargo.Schedule, now time.Time) {
+	if s.NextRunAt == nil {
+		return
+	}
+	next := cargo.NextScheduleRun(s.Weekdays, s.RunTime, now, timeutil.Tashkent())
+	claimed, err := cargoRepo.ClaimScheduleRun(ctx, s.ID, *s.NextRunAt, next)
+	if err != nil || !claimed {
+		if err != nil {
+			logger.Error("cargo schedule claim", zap.Error(err), zap.String("schedule_id", s.ID.String()))
+		}
+		return
+	}
+	var cargoID *uuid.UUID
+	t, err := cargoRepo.GetTemplate(ctx, s.TemplateID)
+	if err == nil && t == nil {
+		err = errors.New("template not found")
+	}
+	if err == nil {
+		overrides := map[string]json.RawMessage{}
+		if s.ReadyOffsetDays != nil {
+			readyAt := now.In(timeutil.Tashkent()).AddDate(0, 0, *s.ReadyOffsetDays).Format("2006-01-02T15:04:05")
+			overrides["ready_enabled"] = json.RawMessage(`true`)
+			overrides["ready_at"], _ = json.Marshal(readyAt)
+		}
+		var id uuid.UUID
+		id, err = handlers.CreateCargoFromTemplate(ctx, cargoRepo, routes, cfg.FreelanceDispatcherCargoLimit, t, overrides, &s.ID)
+		if err == nil {
+			cargoID = &id
+			logger.Info("cargo created by schedule", zap.String("schedule_id", s.ID.String()), zap.String("cargo_id", id.String()))
+		}
+	}
+	if err != nil {
+		logger.Warn("cargo schedule run", zap.Error(err), zap.String("schedule_id", s.ID.String()))
+	}
+	if err := cargoRepo.RecordScheduleRun(ctx, s.ID, cargoID, err); err != nil {
+		logger.Error("cargo schedule record", zap.Error(err))
+	}
+}
+
+// runReportExport генерирует файл фоновой выгрузки целиком в памяти и сохраняет его в report_exports.
+func runReportExport(ctx context.Context, cfg config.Config, repo *exports.Repo, gen *exports.Generator, logger *zap.Logger, e *exports.Export) {
+	var buf bytes.Buffer
+	rows, err := func() (int, error) {
+		w, err := tabular.NewWriter(e.Format, &buf, exports.Header(e.Kind, e.Params.Lang))
+		if err != nil {
+			return 0, err
+		}
+		n, err := gen.Write(ctx, e.Kind, e.Params, w)
+		if cerr := w.Close(); err == nil {
+			err = cerr
+		}
+		return n, err
+	}()
+	if err == nil {
+		err = repo.Finish(ctx, e.ID, rows, exports.FileName(e.Kind, e.Format, e.CreatedAt), buf.Bytes(), cfg.ReportExportTTL)
+		if err == nil {
+			logger.Info("report export ready", zap.String("id", e.ID.String()), zap.String("kind", e.Kind), zap.Int("rows", rows))
+			return
+		}
+	}
+	logger.Error("report export", zap.Error(err), zap.String("id", e.ID.String()))
+	if ferr := repo.Fail(ctx, e.ID, err); ferr != nil {
+		logger.Error("report export fail", zap.Error(ferr), zap.String("id", e.ID.String()))
+	}
+}
+
+// invoiceTerms — сроки оплаты счетов из конфигурации.
+func invoiceTerms(cfg config.Config) invoices.Terms {
+	return invoices.Terms{PrepaymentDays: cfg.InvoicePrepaymentDays, AfterInvoiceDays: cfg.InvoicePaymentTermDays, DeferredDays: cfg.InvoiceDeferredDays}
+}
+
+// settleInvoices выставляет счета по рейсам, у которых началась погрузка, отменяет неоплаченные счета отменённых рейсов
+// и отмечает просрочку: плательщику и водителю один раз приходит INVOICE_OVERDUE.
+func settleInvoices(ctx context.Context, repo *invoices.Repo, gen *invoices.Generator, tripsRepo *trips.Repo, notifier *notifications.Notifier, logger *zap.Logger) error {
+	ids, err := repo.TripsWithoutInvoice(ctx, 100)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		t, err := tripsRepo.GetByID(ctx, id)
+		if err != nil || t == nil {
+			continue
+		}
+		if inv, err := gen.Issue(ctx, t); err != nil {
+			logger.Warn("invoice issue", zap.Error(err), zap.String("trip_id", id.String()))
+		} else {
+			logger.Info("invoice issued", zap.String("trip_id", id.String()), zap.String("number", inv.Number))
+		}
+	}
+	if n, err := repo.CancelForCancelledTrips(ctx); err != nil {
+		logger.Warn("invoices cancel", zap.Error(err))
+	} else if n > 0 {
+		logger.Info("invoices cancelled", zap.Int64("count", n))
+	}
+	open, err := repo.Open(ctx)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for i := range open {
+		inv := &open[i]
+		overdue := inv.Overdue(now)
+		switch {
+		case overdue && inv.OverdueSince == nil:
+			if err := repo.SetOverdue(ctx, inv.ID, &now); err != nil {
+				logger.Error("invoice overdue", zap.Error(err), zap.String("invoice_id", inv.ID.String()))
+				continue
+			}
+			payload := map[string]any{
+				"invoice_id": inv.ID.String(), "number": inv.Number, "trip_id": inv.TripID.String(),
+				"overdue_amount": inv.OverdueAmount(now), "currency": inv.Currency,
+			}
+			notifier.Notify(ctx, inv.PayerType, inv.PayerID, notifications.KindInvoiceOverdue, payload)
+			notifier.Notify(ctx, notifications.RecipientDriver, inv.DriverID, notifications.KindInvoiceOverdue, payload)
+		case !overdue && inv.OverdueSince != nil:
+			// просрочку погасили частичной оплатой
+			if err := repo.SetOverdue(ctx, inv.ID, nil); err != nil {
+				logger.Error("invoice overdue clear", zap.Error(err), zap.String("invoice_id", inv.ID.String()))
+			}
+		}
+	}
+	return nil
+}
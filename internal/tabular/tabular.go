@@ -0,0 +1,110 @@
+// Package tabular читает и пишет табличные файлы (CSV, XLSX): импорт данных и выгрузка отчётов.
+package tabular
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/360EntSecGroup-Skylar/excelize"
+)
+
+// Форматы файлов.
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("tabular: unsupported file format (csv, xlsx)")
+	ErrEmpty             = errors.New("tabular: file has no rows")
+)
+
+// FormatOf определяет формат по расширению имени файла ("" — не поддерживается).
+func FormatOf(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return FormatCSV
+	case ".xlsx":
+		return FormatXLSX
+	}
+	return ""
+}
+
+// Read читает все строки файла: для XLSX — первый лист, для CSV разделитель (',' или ';') определяется по первой строке.
+// Значения ячеек обрезаются по краям; полностью пустые строки пропускаются.
+func Read(filename string, r io.Reader) ([][]string, error) {
+	var (
+		rows [][]string
+		err  error
+	)
+	switch FormatOf(filename) {
+	case FormatCSV:
+		rows, err = readCSV(r)
+	case FormatXLSX:
+		rows, err = readXLSX(r)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	out := rows[:0]
+	for _, row := range rows {
+		empty := true
+		for i := range row {
+			row[i] = strings.TrimSpace(row[i])
+			if row[i] != "" {
+				empty = false
+			}
+		}
+		if !empty {
+			out = append(out, row)
+		}
+	}
+	if len(out) == 0 {
+		return nil, ErrEmpty
+	}
+	return out, nil
+}
+
+func readCSV(r io.Reader) ([][]string, error) {
+	br := bufio.NewReader(r)
+	// BOM от Excel
+	if b, err := br.Peek(3); err == nil && bytes.Equal(b, []byte{0xEF, 0xBB, 0xBF}) {
+		_, _ = br.Discard(3)
+	}
+	first, _ := br.Peek(4096)
+	if i := bytes.IndexByte(first, '\n'); i >= 0 {
+		first = first[:i]
+	}
+	cr := csv.NewReader(br)
+	if bytes.Count(first, []byte{';'}) > bytes.Count(first, []byte{','}) {
+		cr.Comma = ';'
+	}
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	return cr.ReadAll()
+}
+
+func readXLSX(r io.Reader) ([][]string, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, err
+	}
+	sheets := f.GetSheetMap()
+	if len(sheets) == 0 {
+		return nil, ErrEmpty
+	}
+	idx := make([]int, 0, len(sheets))
+	for i := range sheets {
+		idx = append(idx, i)
+	}
+	sort.Ints(idx)
+	return f.GetRows(sheets[idx[0]]), nil
+}
@@ -0,0 +1,107 @@
+package tabular
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/360EntSecGroup-Skylar/excelize"
+)
+
+func TestReadCSV(t *testing.T) {
+	cases := []struct {
+		name string
+		data string
+	}{
+		{"comma", "weight,truck_type\n20,TENT\n\n 18 ,REFRIGERATOR\n"},
+		{"semicolon with BOM", "\xEF\xBB\xBFweight;truck_type\r\n20;TENT\r\n;\r\n18;REFRIGERATOR\r\n"},
+	}
+	for _, tc := range cases {
+		rows, err := Read("plan.CSV", strings.NewReader(tc.data))
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if len(rows) != 3 {
+			t.Fatalf("%s: got %d rows, want 3: %q", tc.name, len(rows), rows)
+		}
+		if rows[0][0] != "weight" || rows[2][0] != "18" || rows[2][1] != "REFRIGERATOR" {
+			t.Errorf("%s: unexpected rows %q", tc.name, rows)
+		}
+	}
+}
+
+func TestReadXLSX(t *testing.T) {
+	f := excelize.NewFile()
+	f.SetSheetRow("Sheet1", "A1", &[]string{"weight", "truck_type"})
+	f.SetSheetRow("Sheet1", "A2", &[]interface{}{20, "TENT"})
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := Read("plan.xlsx", &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[1][0] != "20" || rows[1][1] != "TENT" {
+		t.Errorf("unexpected rows %q", rows)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	if _, err := Read("plan.xls", strings.NewReader("a")); err != ErrUnsupportedFormat {
+		t.Errorf("xls: got %v, want ErrUnsupportedFormat", err)
+	}
+	if _, err := Read("plan.csv", strings.NewReader("\n , \n")); err != ErrEmpty {
+		t.Errorf("empty: got %v, want ErrEmpty", err)
+	}
+}
+
+func TestWriterRoundTrip(t *testing.T) {
+	at := time.Date(2026, 3, 1, 14, 30, 0, 0, time.UTC)
+	for _, format := range []string{FormatCSV, FormatXLSX} {
+		var buf bytes.Buffer
+		w, err := NewWriter(format, &buf, []string{"Груз", "Вес", "Дата", "ADR", "Комментарий"})
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		for i := 0; i < 3; i++ {
+			if err := w.WriteRow([]any{"TAS → ALM", 20.5, at, i == 0, nil}); err != nil {
+				t.Fatalf("%s: %v", format, err)
+			}
+		}
+		if err := w.WriteRow([]any{`<a & "b">`, 7, nil, false, "x;y,z"}); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		rows, err := Read("report."+format, &buf)
+		if err != nil {
+			t.Fatalf("%s: read back: %v", format, err)
+		}
+		if len(rows) != 5 {
+			t.Fatalf("%s: got %d rows, want 5: %q", format, len(rows), rows)
+		}
+		if rows[0][0] != "Груз" || rows[1][0] != "TAS → ALM" || rows[1][1] != "20.5" {
+			t.Errorf("%s: unexpected rows %q", format, rows)
+		}
+		if rows[4][0] != `<a & "b">` || rows[4][1] != "7" || rows[4][4] != "x;y,z" {
+			t.Errorf("%s: escaping: %q", format, rows[4])
+		}
+		if format == FormatCSV && rows[1][2] != "2026-03-01 14:30:00" {
+			t.Errorf("csv time: %q", rows[1][2])
+		}
+	}
+	if _, err := NewWriter("xls", &bytes.Buffer{}, nil); err != ErrUnsupportedFormat {
+		t.Errorf("xls: got %v, want ErrUnsupportedFormat", err)
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 701: "ZZ", 702: "AAA"} {
+		if got := columnName(i); got != want {
+			t.Errorf("columnName(%d) = %q, want %q", i, got, want)
+		}
+	}
+}
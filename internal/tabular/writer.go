@@ -0,0 +1,245 @@
+package tabular
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Writer пишет строки таблицы потоком: CSV — через буфер csv.Writer, XLSX — прямо в zip-архив (лист целиком в памяти не строится).
+// Значения ячеек: nil, string, int, int64, float64, bool, time.Time.
+type Writer interface {
+	WriteRow(values []any) error
+	// Close дописывает файл (для XLSX — закрывает лист и архив); сам w не закрывается.
+	Close() error
+}
+
+// ContentType — MIME-тип файла формата.
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// NewWriter создаёт Writer формата format (FormatCSV, FormatXLSX) и сразу пишет строку заголовка (в XLSX — жирным).
+func NewWriter(format string, w io.Writer, header []string) (Writer, error) {
+	row := make([]any, len(header))
+	for i, h := range header {
+		row[i] = h
+	}
+	switch format {
+	case FormatCSV:
+		cw, err := newCSVWriter(w)
+		if err != nil {
+			return nil, err
+		}
+		if err := cw.WriteRow(row); err != nil {
+			return nil, err
+		}
+		return cw, nil
+	case FormatXLSX:
+		xw, err := newXLSXWriter(w)
+		if err != nil {
+			return nil, err
+		}
+		if err := xw.writeRow(row, xlsxStyleHeader); err != nil {
+			return nil, err
+		}
+		return xw, nil
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+// TimeLayout — формат даты и времени в CSV.
+const TimeLayout = "2006-01-02 15:04:05"
+
+func formatCell(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case int:
+		return strconv.Itoa(x)
+	case int64:
+		return strconv.FormatInt(x, 10)
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(x)
+	case time.Time:
+		return x.Format(TimeLayout)
+	}
+	return ""
+}
+
+type csvWriter struct {
+	cw *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	// BOM — чтобы Excel открывал UTF-8 (кириллицу) без мастера импорта
+	if _, err := w.Write([]byte{0xEF, 0xBB, 0xBF}); err != nil {
+		return nil, err
+	}
+	return &csvWriter{cw: csv.NewWriter(w)}, nil
+}
+
+func (c *csvWriter) WriteRow(values []any) error {
+	rec := make([]string, len(values))
+	for i, v := range values {
+		rec[i] = formatCell(v)
+	}
+	return c.cw.Write(rec)
+}
+
+func (c *csvWriter) Close() error {
+	c.cw.Flush()
+	return c.cw.Error()
+}
+
+// Стили ячеек XLSX (индексы cellXfs в xlsxStyles).
+const (
+	xlsxStyleHeader   = 1
+	xlsxStyleDateTime = 2
+)
+
+const xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/><Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/></Types>`
+
+const xlsxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+
+const xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+const xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/></Relationships>`
+
+const xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy\-mm\-dd\ hh:mm"/></numFmts><fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts><fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills><borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders><cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs><cellXfs count="3"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/><xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/></cellXfs></styleSheet>`
+
+type xlsxWriter struct {
+	zw    *zip.Writer
+	sheet io.Writer
+	row   int
+	buf   bytes.Buffer
+}
+
+func newXLSXWriter(w io.Writer) (*xlsxWriter, error) {
+	zw := zip.NewWriter(w)
+	for _, part := range []struct{ name, body string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	} {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.body); err != nil {
+			return nil, err
+		}
+	}
+	sheet, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	x := &xlsxWriter{zw: zw, sheet: sheet}
+	// заголовок закреплён при прокрутке
+	x.buf.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews><sheetData>`)
+	return x, nil
+}
+
+func (x *xlsxWriter) WriteRow(values []any) error {
+	return x.writeRow(values, 0)
+}
+
+func (x *xlsxWriter) writeRow(values []any, style int) error {
+	x.row++
+	x.buf.WriteString(`<row r="`)
+	x.buf.WriteString(strconv.Itoa(x.row))
+	x.buf.WriteString(`">`)
+	for i, v := range values {
+		s := style
+		var num string
+		ref := ` r="` + columnName(i) + strconv.Itoa(x.row) + `"`
+		switch t := v.(type) {
+		case nil:
+			continue
+		case int:
+			num = strconv.Itoa(t)
+		case int64:
+			num = strconv.FormatInt(t, 10)
+		case float64:
+			num = strconv.FormatFloat(t, 'f', -1, 64)
+		case bool:
+			num = "0"
+			if t {
+				num = "1"
+			}
+			x.buf.WriteString(`<c` + ref + ` t="b"><v>` + num + `</v></c>`)
+			continue
+		case time.Time:
+			num = strconv.FormatFloat(excelTime(t), 'f', 6, 64)
+			if s == 0 {
+				s = xlsxStyleDateTime
+			}
+		}
+		x.buf.WriteString(`<c` + ref)
+		if s != 0 {
+			x.buf.WriteString(` s="` + strconv.Itoa(s) + `"`)
+		}
+		if num != "" {
+			x.buf.WriteString(`><v>` + num + `</v></c>`)
+			continue
+		}
+		x.buf.WriteString(` t="inlineStr"><is><t xml:space="preserve">`)
+		if err := xml.EscapeText(&x.buf, []byte(formatCell(v))); err != nil {
+			return err
+		}
+		x.buf.WriteString(`</t></is></c>`)
+	}
+	x.buf.WriteString(`</row>`)
+	if x.buf.Len() < 32<<10 {
+		return nil
+	}
+	return x.flush()
+}
+
+func (x *xlsxWriter) flush() error {
+	_, err := x.sheet.Write(x.buf.Bytes())
+	x.buf.Reset()
+	return err
+}
+
+func (x *xlsxWriter) Close() error {
+	x.buf.WriteString(`</sheetData></worksheet>`)
+	if err := x.flush(); err != nil {
+		return err
+	}
+	return x.zw.Close()
+}
+
+// columnName — имя колонки по индексу с нуля: 0 → A, 25 → Z, 26 → AA.
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// excelTime — дата/время как число дней от 1899-12-30 (формат Excel); часовой пояс значения сохраняется как есть.
+func excelTime(t time.Time) float64 {
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	return wall.Sub(time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)).Hours() / 24
+}
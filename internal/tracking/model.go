@@ -0,0 +1,43 @@
+package tracking
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Создатель ссылки (created_by_type): диспетчер-создатель груза или компания груза.
+const (
+	CreatorDispatcher = "DISPATCHER"
+	CreatorCompany    = "COMPANY"
+)
+
+// Link — публичная ссылка отслеживания рейса (trip_tracking_links).
+type Link struct {
+	ID            uuid.UUID
+	TripID        uuid.UUID
+	Token         string
+	CreatedByType string
+	CreatedByID   uuid.UUID
+	ExpiresAt     time.Time
+	RevokedAt     *time.Time
+	Views         int
+	LastViewedAt  *time.Time
+	CreatedAt     time.Time
+}
+
+// Active — ссылка не отозвана и не истекла.
+func (l *Link) Active(now time.Time) bool {
+	return l.RevokedAt == nil && now.Before(l.ExpiresAt)
+}
+
+// ClampTTL ограничивает срок действия ссылки: 0 — def, не больше max.
+func ClampTTL(ttl, def, max time.Duration) time.Duration {
+	if ttl <= 0 {
+		ttl = def
+	}
+	if max > 0 && ttl > max {
+		ttl = max
+	}
+	return ttl
+}
@@ -0,0 +1,36 @@
+package tracking
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLinkActive(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	l := &Link{ExpiresAt: now.Add(time.Hour)}
+	if !l.Active(now) {
+		t.Error("unexpired link must be active")
+	}
+	if l.Active(now.Add(time.Hour)) {
+		t.Error("link must expire at ExpiresAt")
+	}
+	l.RevokedAt = &now
+	if l.Active(now) {
+		t.Error("revoked link must not be active")
+	}
+}
+
+func TestClampTTL(t *testing.T) {
+	def, max := 72*time.Hour, 720*time.Hour
+	cases := []struct{ in, want time.Duration }{
+		{0, def},
+		{-time.Hour, def},
+		{24 * time.Hour, 24 * time.Hour},
+		{1000 * time.Hour, max},
+	}
+	for _, c := range cases {
+		if got := ClampTTL(c.in, def, max); got != c.want {
+			t.Errorf("ClampTTL(%v) = %v, want %v", c.in, got, c.want)
+		}
+	}
+}
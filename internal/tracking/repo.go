@@ -0,0 +1,86 @@
+package tracking
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repo struct {
+	pg *pgxpool.Pool
+}
+
+func NewRepo(pg *pgxpool.Pool) *Repo {
+	return &Repo{pg: pg}
+}
+
+const linkColumns = `id, trip_id, token, created_by_type, created_by_id, expires_at, revoked_at, views, last_viewed_at, created_at`
+
+func scanLink(row pgx.Row) (*Link, error) {
+	var l Link
+	err := row.Scan(&l.ID, &l.TripID, &l.Token, &l.CreatedByType, &l.CreatedByID, &l.ExpiresAt, &l.RevokedAt, &l.Views, &l.LastViewedAt, &l.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// Create создаёт ссылку со случайным токеном, действующую ttl.
+func (r *Repo) Create(ctx context.Context, tripID uuid.UUID, byType string, byID uuid.UUID, ttl time.Duration) (*Link, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return scanLink(r.pg.QueryRow(ctx, `
+INSERT INTO trip_tracking_links (trip_id, token, created_by_type, created_by_id, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING `+linkColumns, tripID, hex.EncodeToString(b), byType, byID, time.Now().UTC().Add(ttl)))
+}
+
+// ListByTrip returns links of the trip, newest first (включая отозванные и истёкшие).
+func (r *Repo) ListByTrip(ctx context.Context, tripID uuid.UUID) ([]Link, error) {
+	rows, err := r.pg.Query(ctx, `SELECT `+linkColumns+` FROM trip_tracking_links WHERE trip_id = $1 ORDER BY created_at DESC`, tripID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []Link
+	for rows.Next() {
+		l, err := scanLink(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *l)
+	}
+	return list, rows.Err()
+}
+
+// Revoke отзывает ссылку рейса. false — ссылки нет или она уже отозвана.
+func (r *Repo) Revoke(ctx context.Context, tripID, linkID uuid.UUID) (bool, error) {
+	tag, err := r.pg.Exec(ctx, `UPDATE trip_tracking_links SET revoked_at = now() WHERE id = $1 AND trip_id = $2 AND revoked_at IS NULL`, linkID, tripID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// GetByToken returns link by token (nil — не найдена); активность проверяет вызывающий (Link.Active).
+func (r *Repo) GetByToken(ctx context.Context, token string) (*Link, error) {
+	l, err := scanLink(r.pg.QueryRow(ctx, `SELECT `+linkColumns+` FROM trip_tracking_links WHERE token = $1`, token))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return l, err
+}
+
+// Touch учитывает просмотр ссылки.
+func (r *Repo) Touch(ctx context.Context, id uuid.UUID) error {
+	_, err := r.pg.Exec(ctx, `UPDATE trip_tracking_links SET views = views + 1, last_viewed_at = now() WHERE id = $1`, id)
+	return err
+}
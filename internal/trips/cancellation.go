@@ -0,0 +1,355 @@
+package trips
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Сторона, отменившая рейс: водитель или грузоотправитель (создатель груза).
+const (
+	PartyDriver  = "DRIVER"
+	PartyShipper = "SHIPPER"
+)
+
+// Что стало с грузом после отмены рейса.
+const (
+	CargoOutcomeSearching  = "SEARCHING"  // груз снова в поиске (SEARCHING_ALL / SEARCHING_COMPANY, как до назначения)
+	CargoOutcomeReassigned = "REASSIGNED" // создан новый рейс на выбранного водителя
+)
+
+// CancelReasonForceMajeure — причина отмены, при которой штраф не начисляется.
+const CancelReasonForceMajeure = "FORCE_MAJEURE"
+
+var (
+	ErrHasConsolidated = errors.New("trip carries consolidated trips")
+	ErrDriverMismatch  = errors.New("trip driver changed")
+)
+
+// Cancellation — отмена рейса (table trip_cancellations).
+type Cancellation struct {
+	ID              uuid.UUID
+	TripID          uuid.UUID
+	ReasonCode      string
+	Comment         *string
+	Party           string
+	CancelledByType string
+	CancelledByID   uuid.UUID
+	FromStatus      string
+	PenaltyPercent  *float64
+	PenaltyAmount   *float64
+	PenaltyCurrency *string
+	CargoOutcome    string
+	NewTripID       *uuid.UUID
+	CreatedAt       time.Time
+}
+
+// CancelInput — параметры отмены; ReassignDriverID — создать новый рейс на этого водителя вместо возврата груза в поиск.
+type CancelInput struct {
+	TripID           uuid.UUID
+	ReasonCode       string
+	Comment          *string
+	Party            string
+	CancelledByType  string
+	CancelledByID    uuid.UUID
+	ReassignDriverID *uuid.UUID
+}
+
+// PenaltyRule — штраф за отмену: процент от согласованной цены рейса для стороны и статуса рейса на момент отмены.
+type PenaltyRule struct {
+	ID         uuid.UUID
+	Party      string
+	TripStatus string
+	Percent    float64
+	UpdatedBy  *uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// Handover — передача рейса другому водителю (table trip_handovers).
+type Handover struct {
+	ID              uuid.UUID
+	TripID          uuid.UUID
+	FromDriverID    uuid.UUID
+	ToDriverID      uuid.UUID
+	TripStatus      string
+	ReasonCode      string
+	Comment         *string
+	Lat             *float64
+	Lng             *float64
+	InitiatedByType string
+	InitiatedByID   uuid.UUID
+	CreatedAt       time.Time
+}
+
+// CanCancel — рейс можно отменить из текущего статуса (после отъезда с погрузки — только замена водителя).
+func CanCancel(status string) bool {
+	for _, s := range allowedTransitions[status] {
+		if s == StatusCancelled {
+			return true
+		}
+	}
+	return false
+}
+
+// CanHandover — водителя можно заменить, пока рейс назначен и не завершён.
+func CanHandover(status string) bool {
+	switch status {
+	case StatusAssigned, StatusLoading, StatusEnRoute, StatusUnloading:
+		return true
+	}
+	return false
+}
+
+// CancelPenalty — штраф по проценту правила от согласованной цены (округление до копеек).
+// Нет правила, цены или причина FORCE_MAJEURE — штрафа нет.
+func CancelPenalty(percent *float64, reasonCode string, price *float64) *float64 {
+	if percent == nil || *percent <= 0 || price == nil || reasonCode == CancelReasonForceMajeure {
+		return nil
+	}
+	amount := math.Round(*price**percent) / 100
+	return &amount
+}
+
+const cancellationColumns = `id, trip_id, reason_code, comment, party, cancelled_by_type, cancelled_by_id, from_status,
+  penalty_percent, penalty_amount, penalty_currency, cargo_outcome, new_trip_id, created_at`
+
+func scanCancellation(row pgx.Row) (*Cancellation, error) {
+	var x Cancellation
+	err := row.Scan(&x.ID, &x.TripID, &x.ReasonCode, &x.Comment, &x.Party, &x.CancelledByType, &x.CancelledByID, &x.FromStatus,
+		&x.PenaltyPercent, &x.PenaltyAmount, &x.PenaltyCurrency, &x.CargoOutcome, &x.NewTripID, &x.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &x, nil
+}
+
+// Cancel отменяет рейс одной транзакцией: статус CANCELLED и история, штраф по правилу, отсоединение от рейса-носителя,
+// затем груз возвращается в поиск или передаётся новым рейсом (PENDING_DRIVER, та же цена) выбранному водителю.
+// ErrInvalidTransition — рейс уже нельзя отменить; ErrHasConsolidated — к рейсу присоединены другие рейсы.
+func (r *Repo) Cancel(ctx context.Context, in CancelInput) (*Cancellation, error) {
+	tx, err := r.pg.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	var t Trip
+	err = tx.QueryRow(ctx, `
+SELECT id, cargo_id, offer_id, status, agreed_price, agreed_currency, parent_trip_id FROM trips WHERE id = $1 FOR UPDATE`, in.TripID).
+		Scan(&t.ID, &t.CargoID, &t.OfferID, &t.Status, &t.AgreedPrice, &t.AgreedCurrency, &t.ParentTripID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !CanCancel(t.Status) {
+		return nil, ErrInvalidTransition
+	}
+	var hosting bool
+	if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM trips WHERE parent_trip_id = $1)`, t.ID).Scan(&hosting); err != nil {
+		return nil, err
+	}
+	if hosting {
+		return nil, ErrHasConsolidated
+	}
+	var percent *float64
+	err = tx.QueryRow(ctx, `SELECT percent FROM trip_cancel_penalty_rules WHERE party = $1 AND trip_status = $2`, in.Party, t.Status).Scan(&percent)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	penalty := CancelPenalty(percent, in.ReasonCode, t.AgreedPrice)
+	if penalty == nil {
+		percent = nil
+	}
+	if _, err := tx.Exec(ctx, `
+WITH t AS (
+  UPDATE trips SET status = $1, parent_trip_id = NULL, updated_at = now() WHERE id = $2 RETURNING id
+)
+INSERT INTO trip_status_history (trip_id, from_status, to_status) SELECT id, $3, $1 FROM t`, StatusCancelled, t.ID, t.Status); err != nil {
+		return nil, err
+	}
+	if t.ParentTripID != nil {
+		// точки груза уходят из объединённого маршрута; последний присоединённый рейс — порядок больше не нужен
+		if _, err := tx.Exec(ctx, `
+DELETE FROM trip_route_sequence s USING route_points rp
+WHERE s.trip_id = $1 AND rp.id = s.route_point_id AND rp.cargo_id = $2`, *t.ParentTripID, t.CargoID); err != nil {
+			return nil, err
+		}
+		if _, err := tx.Exec(ctx, `
+DELETE FROM trip_route_sequence WHERE trip_id = $1 AND NOT EXISTS (SELECT 1 FROM trips WHERE parent_trip_id = $1)`, *t.ParentTripID); err != nil {
+			return nil, err
+		}
+	}
+	outcome := CargoOutcomeSearching
+	var newTripID *uuid.UUID
+	if in.ReassignDriverID != nil {
+		outcome = CargoOutcomeReassigned
+		var id uuid.UUID
+		err := tx.QueryRow(ctx, `
+WITH t AS (
+  INSERT INTO trips (cargo_id, offer_id, driver_id, status, agreed_price, agreed_currency)
+  VALUES ($1, $2, $3, $4, $5, $6)
+  RETURNING id
+), h AS (
+  INSERT INTO trip_status_history (trip_id, to_status) SELECT id, $4 FROM t
+)
+SELECT id FROM t`, t.CargoID, t.OfferID, *in.ReassignDriverID, StatusPendingDriver, t.AgreedPrice, t.AgreedCurrency).Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		newTripID = &id
+		_, err = tx.Exec(ctx, `
+UPDATE cargo SET status = 'ASSIGNED', updated_at = now() WHERE id = $1 AND deleted_at IS NULL AND status IN ('ASSIGNED', 'IN_PROGRESS')`, t.CargoID)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		_, err := tx.Exec(ctx, `
+UPDATE cargo SET status = COALESCE(last_search_status, 'SEARCHING_ALL'), updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL AND status IN ('ASSIGNED', 'IN_PROGRESS')`, t.CargoID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var currency *string
+	if penalty != nil {
+		currency = t.AgreedCurrency
+	}
+	x, err := scanCancellation(tx.QueryRow(ctx, `
+INSERT INTO trip_cancellations (trip_id, reason_code, comment, party, cancelled_by_type, cancelled_by_id, from_status,
+  penalty_percent, penalty_amount, penalty_currency, cargo_outcome, new_trip_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+RETURNING `+cancellationColumns,
+		t.ID, in.ReasonCode, in.Comment, in.Party, in.CancelledByType, in.CancelledByID, t.Status,
+		percent, penalty, currency, outcome, newTripID))
+	if err != nil {
+		return nil, err
+	}
+	return x, tx.Commit(ctx)
+}
+
+// Cancellation returns the cancellation record of the trip (nil if the trip was not cancelled through Cancel).
+func (r *Repo) Cancellation(ctx context.Context, tripID uuid.UUID) (*Cancellation, error) {
+	x, err := scanCancellation(r.pg.QueryRow(ctx, `SELECT `+cancellationColumns+` FROM trip_cancellations WHERE trip_id = $1`, tripID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return x, err
+}
+
+// PenaltyRules returns all cancellation penalty rules.
+func (r *Repo) PenaltyRules(ctx context.Context) ([]PenaltyRule, error) {
+	rows, err := r.pg.Query(ctx, `
+SELECT id, party, trip_status, percent, updated_by, created_at, updated_at FROM trip_cancel_penalty_rules ORDER BY party, trip_status`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []PenaltyRule
+	for rows.Next() {
+		var p PenaltyRule
+		if err := rows.Scan(&p.ID, &p.Party, &p.TripStatus, &p.Percent, &p.UpdatedBy, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, p)
+	}
+	return list, rows.Err()
+}
+
+// UpsertPenaltyRule задаёт процент штрафа для стороны и статуса рейса.
+func (r *Repo) UpsertPenaltyRule(ctx context.Context, party, tripStatus string, percent float64, updatedBy *uuid.UUID) (*PenaltyRule, error) {
+	var p PenaltyRule
+	err := r.pg.QueryRow(ctx, `
+INSERT INTO trip_cancel_penalty_rules (party, trip_status, percent, updated_by) VALUES ($1, $2, $3, $4)
+ON CONFLICT (party, trip_status) DO UPDATE SET percent = EXCLUDED.percent, updated_by = EXCLUDED.updated_by, updated_at = now()
+RETURNING id, party, trip_status, percent, updated_by, created_at, updated_at`, party, tripStatus, percent, updatedBy).
+		Scan(&p.ID, &p.Party, &p.TripStatus, &p.Percent, &p.UpdatedBy, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// DeletePenaltyRule удаляет правило; false — не найдено.
+func (r *Repo) DeletePenaltyRule(ctx context.Context, id uuid.UUID) (bool, error) {
+	tag, err := r.pg.Exec(ctx, `DELETE FROM trip_cancel_penalty_rules WHERE id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+const handoverColumns = `id, trip_id, from_driver_id, to_driver_id, trip_status, reason_code, comment, lat, lng,
+  initiated_by_type, initiated_by_id, created_at`
+
+func scanHandover(row pgx.Row) (*Handover, error) {
+	var h Handover
+	err := row.Scan(&h.ID, &h.TripID, &h.FromDriverID, &h.ToDriverID, &h.TripStatus, &h.ReasonCode, &h.Comment, &h.Lat, &h.Lng,
+		&h.InitiatedByType, &h.InitiatedByID, &h.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+// SwapDriver передаёт активный рейс (и присоединённые к нему рейсы) другому водителю и записывает передачу.
+// h.FromDriverID — водитель, которого меняют: если рейс уже у другого — ErrDriverMismatch.
+func (r *Repo) SwapDriver(ctx context.Context, h Handover) (*Handover, error) {
+	tx, err := r.pg.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	var status string
+	var driverID *uuid.UUID
+	err = tx.QueryRow(ctx, `SELECT status, driver_id FROM trips WHERE id = $1 FOR UPDATE`, h.TripID).Scan(&status, &driverID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !CanHandover(status) {
+		return nil, ErrInvalidTransition
+	}
+	if driverID == nil || *driverID != h.FromDriverID {
+		return nil, ErrDriverMismatch
+	}
+	if _, err := tx.Exec(ctx, `
+UPDATE trips SET driver_id = $2, updated_at = now() WHERE (id = $1 OR parent_trip_id = $1) AND driver_id = $3`,
+		h.TripID, h.ToDriverID, h.FromDriverID); err != nil {
+		return nil, err
+	}
+	out, err := scanHandover(tx.QueryRow(ctx, `
+INSERT INTO trip_handovers (trip_id, from_driver_id, to_driver_id, trip_status, reason_code, comment, lat, lng, initiated_by_type, initiated_by_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING `+handoverColumns,
+		h.TripID, h.FromDriverID, h.ToDriverID, status, h.ReasonCode, h.Comment, h.Lat, h.Lng, h.InitiatedByType, h.InitiatedByID))
+	if err != nil {
+		return nil, err
+	}
+	return out, tx.Commit(ctx)
+}
+
+// Handovers returns driver handovers of the trip (oldest first).
+func (r *Repo) Handovers(ctx context.Context, tripID uuid.UUID) ([]Handover, error) {
+	rows, err := r.pg.Query(ctx, `SELECT `+handoverColumns+` FROM trip_handovers WHERE trip_id = $1 ORDER BY created_at`, tripID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []Handover
+	for rows.Next() {
+		h, err := scanHandover(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *h)
+	}
+	return list, rows.Err()
+}
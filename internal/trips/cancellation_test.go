@@ -0,0 +1,31 @@
+package trips
+
+import "testing"
+
+func TestCancelPenalty(t *testing.T) {
+	price, pct, zero := 1250.0, 10.0, 0.0
+	if got := CancelPenalty(&pct, "VEHICLE_BREAKDOWN", &price); got == nil || *got != 125 {
+		t.Errorf("penalty: %v", got)
+	}
+	if got := CancelPenalty(&pct, CancelReasonForceMajeure, &price); got != nil {
+		t.Errorf("force majeure: %v", *got)
+	}
+	if CancelPenalty(nil, "OTHER", &price) != nil || CancelPenalty(&zero, "OTHER", &price) != nil || CancelPenalty(&pct, "OTHER", nil) != nil {
+		t.Error("no rule or price must give no penalty")
+	}
+}
+
+func TestCanCancel(t *testing.T) {
+	for status, want := range map[string]bool{
+		StatusPendingDriver: true,
+		StatusAssigned:      true,
+		StatusLoading:       true,
+		StatusEnRoute:       false,
+		StatusCompleted:     false,
+		StatusCancelled:     false,
+	} {
+		if got := CanCancel(status); got != want {
+			t.Errorf("%s: got %v", status, got)
+		}
+	}
+}
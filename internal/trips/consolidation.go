@@ -0,0 +1,259 @@
+package trips
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"sarbonNew/internal/routing"
+)
+
+var (
+	ErrNotConsolidatable = errors.New("trip cannot be consolidated")
+	ErrInvalidSequence   = errors.New("invalid merged stop sequence")
+)
+
+// SeqPoint — точка маршрута одного из грузов консолидированного рейса.
+type SeqPoint struct {
+	ID      uuid.UUID
+	CargoID uuid.UUID
+	Type    string // LOAD, UNLOAD, CUSTOMS, TRANSIT
+	Lat     float64
+	Lng     float64
+}
+
+// Load — вес (т) и объём (м³) груза.
+type Load struct {
+	Weight float64
+	Volume float64
+}
+
+// PeakLoad — максимальная загрузка машины на объединённом маршруте: груз в кузове от своей первой погрузки
+// до последней выгрузки (груз без погрузок — с начала маршрута, без выгрузок — до конца).
+func PeakLoad(seq []SeqPoint, loads map[uuid.UUID]Load) Load {
+	first, last := map[uuid.UUID]int{}, map[uuid.UUID]int{}
+	for i, p := range seq {
+		switch strings.ToUpper(p.Type) {
+		case "LOAD":
+			if _, ok := first[p.CargoID]; !ok {
+				first[p.CargoID] = i
+			}
+		case "UNLOAD":
+			last[p.CargoID] = i
+		}
+	}
+	var peak Load
+	for i := range seq {
+		var cur Load
+		for cargoID, l := range loads {
+			from, ok := first[cargoID]
+			if !ok {
+				from = 0
+			}
+			to, ok := last[cargoID]
+			if !ok {
+				to = len(seq)
+			}
+			if from <= i && i < to {
+				cur.Weight += l.Weight
+				cur.Volume += l.Volume
+			}
+		}
+		peak.Weight = math.Max(peak.Weight, cur.Weight)
+		peak.Volume = math.Max(peak.Volume, cur.Volume)
+	}
+	return peak
+}
+
+// MergeSequence вставляет точки нового груза в маршрут после пройденных visited точек методом дешёвой вставки:
+// каждая точка — туда, где она меньше всего удлиняет маршрут; порядок точек нового груза сохраняется.
+func MergeSequence(current []SeqPoint, visited int, add []SeqPoint) []SeqPoint {
+	out := append([]SeqPoint(nil), current...)
+	minPos := visited
+	for _, p := range add {
+		best, bestCost := len(out), math.Inf(1)
+		for pos := minPos; pos <= len(out); pos++ {
+			if cost := insertionCost(out, pos, p); cost < bestCost {
+				best, bestCost = pos, cost
+			}
+		}
+		out = append(out, SeqPoint{})
+		copy(out[best+1:], out[best:])
+		out[best] = p
+		minPos = best + 1
+	}
+	return out
+}
+
+// insertionCost — насколько удлинится маршрут, если вставить p на позицию pos.
+func insertionCost(seq []SeqPoint, pos int, p SeqPoint) float64 {
+	pt := routing.Point{Lat: p.Lat, Lng: p.Lng}
+	var prev, next *routing.Point
+	if pos > 0 {
+		prev = &routing.Point{Lat: seq[pos-1].Lat, Lng: seq[pos-1].Lng}
+	}
+	if pos < len(seq) {
+		next = &routing.Point{Lat: seq[pos].Lat, Lng: seq[pos].Lng}
+	}
+	switch {
+	case prev != nil && next != nil:
+		return routing.HaversineKm(*prev, pt) + routing.HaversineKm(pt, *next) - routing.HaversineKm(*prev, *next)
+	case prev != nil:
+		return routing.HaversineKm(*prev, pt)
+	case next != nil:
+		return routing.HaversineKm(pt, *next)
+	}
+	return 0
+}
+
+// ValidateSequence проверяет порядок, заданный диспетчером: все точки всех грузов ровно по разу,
+// пройденные visited точки остаются на своих местах, порядок точек каждого груза сохраняется.
+func ValidateSequence(current []SeqPoint, visited int, all []SeqPoint, order []uuid.UUID) ([]SeqPoint, error) {
+	if len(order) != len(all) {
+		return nil, ErrInvalidSequence
+	}
+	byID := make(map[uuid.UUID]SeqPoint, len(all))
+	for _, p := range all {
+		byID[p.ID] = p
+	}
+	// позиция точки в исходном порядке своего груза
+	rank := map[uuid.UUID]int{}
+	seen := map[uuid.UUID]int{}
+	for _, p := range all {
+		rank[p.ID] = seen[p.CargoID]
+		seen[p.CargoID]++
+	}
+	out := make([]SeqPoint, 0, len(order))
+	next := map[uuid.UUID]int{}
+	for i, id := range order {
+		p, ok := byID[id]
+		if !ok || (i < visited && current[i].ID != id) || rank[id] != next[p.CargoID] {
+			return nil, ErrInvalidSequence
+		}
+		next[p.CargoID]++
+		delete(byID, id)
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// Visited — сколько точек с начала маршрута уже пройдено (есть прибытие); новые точки вставляются только после них.
+func (p StopProgress) Visited() int {
+	n := 0
+	for i, s := range p.Stops {
+		if s != nil {
+			n = i + 1
+		}
+	}
+	return n
+}
+
+// Sequence returns merged route point order of the host trip (nil — рейс не консолидирован).
+func (r *Repo) Sequence(ctx context.Context, tripID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := r.pg.Query(ctx, `SELECT route_point_id FROM trip_route_sequence WHERE trip_id = $1 ORDER BY seq`, tripID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		list = append(list, id)
+	}
+	return list, rows.Err()
+}
+
+// Children returns trips consolidated into the host trip.
+func (r *Repo) Children(ctx context.Context, hostID uuid.UUID) ([]Trip, error) {
+	rows, err := r.pg.Query(ctx, `
+SELECT id, cargo_id, offer_id, driver_id, status, agreed_price, agreed_currency, parent_trip_id, created_at, updated_at
+FROM trips WHERE parent_trip_id = $1 ORDER BY created_at`, hostID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []Trip
+	for rows.Next() {
+		var t Trip
+		if err := rows.Scan(&t.ID, &t.CargoID, &t.OfferID, &t.DriverID, &t.Status, &t.AgreedPrice, &t.AgreedCurrency, &t.ParentTripID, &t.CreatedAt, &t.UpdatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, t)
+	}
+	return list, rows.Err()
+}
+
+// Consolidate присоединяет рейс childID (ASSIGNED, тот же водитель, ещё не консолидирован) к рейсу hostID
+// и сохраняет объединённый порядок точек. ErrNotConsolidatable — рейс изменился и уже не подходит.
+func (r *Repo) Consolidate(ctx context.Context, hostID, childID uuid.UUID, seq []uuid.UUID) error {
+	tx, err := r.pg.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	tag, err := tx.Exec(ctx, `
+UPDATE trips c SET parent_trip_id = $1, updated_at = now()
+FROM trips h
+WHERE c.id = $2 AND h.id = $1 AND c.status = $3 AND c.parent_trip_id IS NULL AND h.parent_trip_id IS NULL
+  AND c.driver_id = h.driver_id
+  AND NOT EXISTS (SELECT 1 FROM trips x WHERE x.parent_trip_id = c.id)
+  AND NOT EXISTS (SELECT 1 FROM trip_stops s WHERE s.trip_id = c.id)`, hostID, childID, StatusAssigned)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotConsolidatable
+	}
+	if err := replaceSequence(ctx, tx, hostID, seq); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Detach отсоединяет рейс от рейса-носителя (пока по его точкам нет отметок) и сохраняет новый порядок точек;
+// если присоединённых рейсов не осталось, порядок удаляется. false — рейс не присоединён к hostID.
+func (r *Repo) Detach(ctx context.Context, hostID, childID uuid.UUID, seq []uuid.UUID) (bool, error) {
+	tx, err := r.pg.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+	tag, err := tx.Exec(ctx, `UPDATE trips SET parent_trip_id = NULL, updated_at = now() WHERE id = $1 AND parent_trip_id = $2`, childID, hostID)
+	if err != nil {
+		return false, err
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+	var left bool
+	if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM trips WHERE parent_trip_id = $1)`, hostID).Scan(&left); err != nil {
+		return false, err
+	}
+	if !left {
+		seq = nil
+	}
+	if err := replaceSequence(ctx, tx, hostID, seq); err != nil {
+		return false, err
+	}
+	return true, tx.Commit(ctx)
+}
+
+func replaceSequence(ctx context.Context, tx pgx.Tx, hostID uuid.UUID, seq []uuid.UUID) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM trip_route_sequence WHERE trip_id = $1`, hostID); err != nil {
+		return err
+	}
+	if len(seq) == 0 {
+		return nil
+	}
+	_, err := tx.Exec(ctx, `
+INSERT INTO trip_route_sequence (trip_id, route_point_id, seq)
+SELECT $1, p.id, p.ord FROM unnest($2::uuid[]) WITH ORDINALITY AS p(id, ord)`, hostID, seq)
+	return err
+}
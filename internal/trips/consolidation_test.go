@@ -0,0 +1,81 @@
+package trips
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestPeakLoad(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	seq := []SeqPoint{
+		{CargoID: a, Type: "LOAD"},
+		{CargoID: b, Type: "LOAD"},
+		{CargoID: a, Type: "UNLOAD"},
+		{CargoID: b, Type: "UNLOAD"},
+	}
+	loads := map[uuid.UUID]Load{a: {Weight: 8, Volume: 30}, b: {Weight: 5, Volume: 40}}
+	if got := PeakLoad(seq, loads); got.Weight != 13 || got.Volume != 70 {
+		t.Errorf("overlapping: %+v", got)
+	}
+	// b грузится после выгрузки a — в кузове не одновременно
+	seq[1], seq[2] = seq[2], seq[1]
+	if got := PeakLoad(seq, loads); got.Weight != 8 || got.Volume != 40 {
+		t.Errorf("sequential: %+v", got)
+	}
+}
+
+func TestMergeSequence(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	current := []SeqPoint{
+		{ID: uuid.New(), CargoID: a, Type: "LOAD", Lat: 41.3, Lng: 69.2},   // Ташкент
+		{ID: uuid.New(), CargoID: a, Type: "UNLOAD", Lat: 39.6, Lng: 66.9}, // Самарканд
+	}
+	add := []SeqPoint{
+		{ID: uuid.New(), CargoID: b, Type: "LOAD", Lat: 40.5, Lng: 68.8},   // Гулистан
+		{ID: uuid.New(), CargoID: b, Type: "UNLOAD", Lat: 39.7, Lng: 67.0}, // рядом с Самаркандом
+	}
+	got := MergeSequence(current, 1, add)
+	want := []uuid.UUID{current[0].ID, add[0].ID, add[1].ID, current[1].ID}
+	if len(got) != len(want) {
+		t.Fatalf("len %d", len(got))
+	}
+	for i := range want {
+		if got[i].ID != want[i] {
+			t.Fatalf("pos %d: got %v", i, got[i])
+		}
+	}
+	// пройденные точки не сдвигаются
+	progress := NewStopProgress([]StopPoint{{ID: current[0].ID}, {ID: current[1].ID}}, []Stop{{RoutePointID: current[0].ID}, {RoutePointID: current[1].ID}})
+	if progress.Visited() != 2 {
+		t.Fatalf("visited %d", progress.Visited())
+	}
+	if got := MergeSequence(current, 2, add); got[0].ID != current[0].ID || got[1].ID != current[1].ID {
+		t.Errorf("visited prefix moved: %v", got)
+	}
+}
+
+func TestValidateSequence(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	a1, a2 := SeqPoint{ID: uuid.New(), CargoID: a}, SeqPoint{ID: uuid.New(), CargoID: a}
+	b1, b2 := SeqPoint{ID: uuid.New(), CargoID: b}, SeqPoint{ID: uuid.New(), CargoID: b}
+	current := []SeqPoint{a1, a2}
+	all := []SeqPoint{a1, a2, b1, b2}
+
+	if got, err := ValidateSequence(current, 1, all, []uuid.UUID{a1.ID, b1.ID, a2.ID, b2.ID}); err != nil || got[1].ID != b1.ID {
+		t.Errorf("valid: %v %v", got, err)
+	}
+	bad := [][]uuid.UUID{
+		{b1.ID, a1.ID, a2.ID, b2.ID},      // пройденная точка сдвинута
+		{a1.ID, b2.ID, b1.ID, a2.ID},      // порядок груза нарушен
+		{a1.ID, a2.ID, b1.ID},             // не все точки
+		{a1.ID, a2.ID, b1.ID, b1.ID},      // повтор
+		{a1.ID, a2.ID, b1.ID, uuid.New()}, // чужая точка
+	}
+	for i, order := range bad {
+		if _, err := ValidateSequence(current, 1, all, order); !errors.Is(err, ErrInvalidSequence) {
+			t.Errorf("case %d: %v", i, err)
+		}
+	}
+}
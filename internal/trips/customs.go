@@ -0,0 +1,311 @@
+package trips
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Статусы прохождения таможни на точке CUSTOMS.
+const (
+	CustomsQueued     = "QUEUED"
+	CustomsDeclared   = "DECLARATION_SUBMITTED"
+	CustomsInspection = "INSPECTION"
+	CustomsCleared    = "CLEARED"
+	CustomsHeld       = "HELD"
+)
+
+// Типы таможенных документов.
+const (
+	CustomsDocTIR      = "TIR"
+	CustomsDocT1       = "T1"
+	CustomsDocCMR      = "CMR"
+	CustomsDocPermit   = "PERMIT"
+	CustomsDocDeclared = "DECLARATION"
+	CustomsDocOther    = "OTHER"
+)
+
+var (
+	ErrCustomsTransition = errors.New("invalid customs status transition")
+	ErrCustomsNotStarted = errors.New("customs not started at this route point")
+)
+
+// customsTransitions — допустимые переходы; "" — таможня на точке ещё не начата.
+// HELD (задержка) возможна на любом этапе до выпуска; после неё процедура продолжается.
+var customsTransitions = map[string][]string{
+	"":                {CustomsQueued, CustomsDeclared},
+	CustomsQueued:     {CustomsDeclared, CustomsInspection, CustomsHeld, CustomsCleared},
+	CustomsDeclared:   {CustomsInspection, CustomsHeld, CustomsCleared},
+	CustomsInspection: {CustomsHeld, CustomsCleared},
+	CustomsHeld:       {CustomsDeclared, CustomsInspection, CustomsCleared},
+	CustomsCleared:    nil,
+}
+
+// CanCustomsTransition — можно ли перевести таможню из from в to.
+func CanCustomsTransition(from, to string) bool {
+	for _, s := range customsTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsCustomsDocType — поддерживаемый тип таможенного документа.
+func IsCustomsDocType(docType string) bool {
+	switch docType {
+	case CustomsDocTIR, CustomsDocT1, CustomsDocCMR, CustomsDocPermit, CustomsDocDeclared, CustomsDocOther:
+		return true
+	}
+	return false
+}
+
+// Customs — таможня рейса на точке маршрута CUSTOMS (trip_customs).
+type Customs struct {
+	ID            uuid.UUID
+	TripID        uuid.UUID
+	RoutePointID  uuid.UUID
+	Status        string
+	HoldReason    *string
+	StartedAt     time.Time
+	ClearedAt     *time.Time
+	UpdatedByType string
+	UpdatedByID   uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// CustomsEvent — смена статуса таможни (trip_customs_events).
+type CustomsEvent struct {
+	CustomsID  uuid.UUID
+	FromStatus *string
+	ToStatus   string
+	Comment    *string
+	ByType     string
+	ByID       uuid.UUID
+	CreatedAt  time.Time
+}
+
+// CustomsDocument — декларационный документ (книжка МДП/TIR, номер T1 и т.д.); скан читается через CustomsDocumentFile.
+type CustomsDocument struct {
+	ID             uuid.UUID
+	CustomsID      uuid.UUID
+	DocType        string
+	Number         string
+	ContentType    *string
+	UploadedByType string
+	UploadedByID   uuid.UUID
+	CreatedAt      time.Time
+}
+
+// HasFile — к документу приложен скан.
+func (d *CustomsDocument) HasFile() bool {
+	return d.ContentType != nil
+}
+
+// CustomsTime — время на таможне: всего (до выпуска или до now), в задержке и по статусам.
+type CustomsTime struct {
+	Total    time.Duration
+	Held     time.Duration
+	ByStatus map[string]time.Duration
+}
+
+// CustomsMetrics считает время по истории статусов одной таможни (события по возрастанию времени).
+func CustomsMetrics(events []CustomsEvent, now time.Time) CustomsTime {
+	m := CustomsTime{ByStatus: map[string]time.Duration{}}
+	if len(events) == 0 {
+		return m
+	}
+	end := now
+	for i, e := range events {
+		if e.ToStatus == CustomsCleared {
+			end = e.CreatedAt
+			break
+		}
+		next := now
+		if i+1 < len(events) {
+			next = events[i+1].CreatedAt
+		}
+		m.ByStatus[e.ToStatus] += next.Sub(e.CreatedAt)
+	}
+	m.Total = end.Sub(events[0].CreatedAt)
+	m.Held = m.ByStatus[CustomsHeld]
+	return m
+}
+
+// MissingCustomsDocuments — типы из required, по которым ещё нет документа.
+func MissingCustomsDocuments(required []string, docs []CustomsDocument) []string {
+	have := map[string]bool{}
+	for _, d := range docs {
+		have[d.DocType] = true
+	}
+	out := []string{}
+	for _, t := range required {
+		if !have[t] {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+const customsColumns = `id, trip_id, route_point_id, status, hold_reason, started_at, cleared_at, updated_by_type, updated_by_id, created_at, updated_at`
+
+func scanCustoms(row pgx.Row) (*Customs, error) {
+	var c Customs
+	err := row.Scan(&c.ID, &c.TripID, &c.RoutePointID, &c.Status, &c.HoldReason, &c.StartedAt, &c.ClearedAt,
+		&c.UpdatedByType, &c.UpdatedByID, &c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// CustomsList returns customs records of the trip.
+func (r *Repo) CustomsList(ctx context.Context, tripID uuid.UUID) ([]Customs, error) {
+	rows, err := r.pg.Query(ctx, `SELECT `+customsColumns+` FROM trip_customs WHERE trip_id = $1 ORDER BY started_at`, tripID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []Customs
+	for rows.Next() {
+		c, err := scanCustoms(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *c)
+	}
+	return list, rows.Err()
+}
+
+// CustomsEvents returns status history of all customs records of the trip, oldest first.
+func (r *Repo) CustomsEvents(ctx context.Context, tripID uuid.UUID) ([]CustomsEvent, error) {
+	rows, err := r.pg.Query(ctx, `
+SELECT e.customs_id, e.from_status, e.to_status, e.comment, e.by_type, e.by_id, e.created_at
+FROM trip_customs_events e JOIN trip_customs c ON c.id = e.customs_id
+WHERE c.trip_id = $1 ORDER BY e.created_at, e.id`, tripID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []CustomsEvent
+	for rows.Next() {
+		var e CustomsEvent
+		if err := rows.Scan(&e.CustomsID, &e.FromStatus, &e.ToStatus, &e.Comment, &e.ByType, &e.ByID, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+	}
+	return list, rows.Err()
+}
+
+// SetCustomsStatus меняет статус таможни на точке (первый статус создаёт запись) и пишет событие в историю.
+// comment для HELD сохраняется как причина задержки. ErrCustomsTransition — переход не допускается.
+func (r *Repo) SetCustomsStatus(ctx context.Context, tripID, routePointID uuid.UUID, status string, comment *string, byType string, byID uuid.UUID) (*Customs, error) {
+	tx, err := r.pg.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	var from string
+	err = tx.QueryRow(ctx, `SELECT status FROM trip_customs WHERE trip_id = $1 AND route_point_id = $2 FOR UPDATE`,
+		tripID, routePointID).Scan(&from)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	if !CanCustomsTransition(from, status) {
+		return nil, ErrCustomsTransition
+	}
+	var holdReason *string
+	if status == CustomsHeld {
+		holdReason = comment
+	}
+	c, err := scanCustoms(tx.QueryRow(ctx, `
+INSERT INTO trip_customs (trip_id, route_point_id, status, hold_reason, updated_by_type, updated_by_id)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (trip_id, route_point_id) DO UPDATE SET status = EXCLUDED.status, hold_reason = EXCLUDED.hold_reason,
+  cleared_at = CASE WHEN EXCLUDED.status = 'CLEARED' THEN now() ELSE NULL END,
+  updated_by_type = EXCLUDED.updated_by_type, updated_by_id = EXCLUDED.updated_by_id, updated_at = now()
+RETURNING `+customsColumns, tripID, routePointID, status, holdReason, byType, byID))
+	if err != nil {
+		return nil, err
+	}
+	var fromStatus *string
+	if from != "" {
+		fromStatus = &from
+	}
+	if _, err := tx.Exec(ctx, `
+INSERT INTO trip_customs_events (customs_id, from_status, to_status, comment, by_type, by_id) VALUES ($1, $2, $3, $4, $5, $6)`,
+		c.ID, fromStatus, status, comment, byType, byID); err != nil {
+		return nil, err
+	}
+	return c, tx.Commit(ctx)
+}
+
+// CustomsDocuments returns declaration documents of all customs records of the trip (без файлов).
+func (r *Repo) CustomsDocuments(ctx context.Context, tripID uuid.UUID) ([]CustomsDocument, error) {
+	rows, err := r.pg.Query(ctx, `
+SELECT d.id, d.customs_id, d.doc_type, d.number, d.content_type, d.uploaded_by_type, d.uploaded_by_id, d.created_at
+FROM trip_customs_documents d JOIN trip_customs c ON c.id = d.customs_id
+WHERE c.trip_id = $1 ORDER BY d.created_at`, tripID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []CustomsDocument
+	for rows.Next() {
+		var d CustomsDocument
+		if err := rows.Scan(&d.ID, &d.CustomsID, &d.DocType, &d.Number, &d.ContentType, &d.UploadedByType, &d.UploadedByID, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, d)
+	}
+	return list, rows.Err()
+}
+
+// AddCustomsDocument прикрепляет документ к таможне на точке; file — скан, может быть nil.
+// ErrCustomsNotStarted — таможня на точке ещё не начата.
+func (r *Repo) AddCustomsDocument(ctx context.Context, tripID, routePointID uuid.UUID, d CustomsDocument, file []byte) (*CustomsDocument, error) {
+	err := r.pg.QueryRow(ctx, `
+INSERT INTO trip_customs_documents (customs_id, doc_type, number, file_data, content_type, uploaded_by_type, uploaded_by_id)
+SELECT id, $3, $4, $5, $6, $7, $8 FROM trip_customs WHERE trip_id = $1 AND route_point_id = $2
+RETURNING id, customs_id, created_at`,
+		tripID, routePointID, d.DocType, d.Number, file, d.ContentType, d.UploadedByType, d.UploadedByID).Scan(&d.ID, &d.CustomsID, &d.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrCustomsNotStarted
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// DeleteCustomsDocument — загрузивший удаляет свой документ. false — документа нет или он чужой.
+func (r *Repo) DeleteCustomsDocument(ctx context.Context, tripID, docID uuid.UUID, byType string, byID uuid.UUID) (bool, error) {
+	tag, err := r.pg.Exec(ctx, `
+DELETE FROM trip_customs_documents d USING trip_customs c
+WHERE d.id = $2 AND c.id = d.customs_id AND c.trip_id = $1 AND d.uploaded_by_type = $3 AND d.uploaded_by_id = $4`,
+		tripID, docID, byType, byID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// CustomsDocumentFile returns scan of the document (nil — документа или скана нет).
+func (r *Repo) CustomsDocumentFile(ctx context.Context, tripID, docID uuid.UUID) (data []byte, contentType string, err error) {
+	var ct *string
+	err = r.pg.QueryRow(ctx, `
+SELECT d.file_data, d.content_type FROM trip_customs_documents d JOIN trip_customs c ON c.id = d.customs_id
+WHERE d.id = $2 AND c.trip_id = $1`, tripID, docID).Scan(&data, &ct)
+	if errors.Is(err, pgx.ErrNoRows) || ct == nil {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return data, *ct, nil
+}
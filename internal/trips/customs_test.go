@@ -0,0 +1,54 @@
+package trips
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCanCustomsTransition(t *testing.T) {
+	cases := []struct {
+		from, to string
+		want     bool
+	}{
+		{"", CustomsQueued, true},
+		{"", CustomsCleared, false},
+		{CustomsQueued, CustomsHeld, true},
+		{CustomsHeld, CustomsInspection, true},
+		{CustomsInspection, CustomsDeclared, false},
+		{CustomsCleared, CustomsHeld, false},
+	}
+	for _, c := range cases {
+		if got := CanCustomsTransition(c.from, c.to); got != c.want {
+			t.Errorf("%q -> %q: got %v, want %v", c.from, c.to, got, c.want)
+		}
+	}
+}
+
+func TestCustomsMetrics(t *testing.T) {
+	start := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	at := func(h int, status string) CustomsEvent {
+		return CustomsEvent{ToStatus: status, CreatedAt: start.Add(time.Duration(h) * time.Hour)}
+	}
+	events := []CustomsEvent{at(0, CustomsQueued), at(3, CustomsDeclared), at(4, CustomsHeld), at(10, CustomsInspection)}
+
+	m := CustomsMetrics(events, start.Add(12*time.Hour))
+	if m.Total != 12*time.Hour || m.Held != 6*time.Hour || m.ByStatus[CustomsQueued] != 3*time.Hour || m.ByStatus[CustomsInspection] != 2*time.Hour {
+		t.Errorf("in progress: %+v", m)
+	}
+
+	m = CustomsMetrics(append(events, at(11, CustomsCleared)), start.Add(30*time.Hour))
+	if m.Total != 11*time.Hour || m.ByStatus[CustomsInspection] != time.Hour {
+		t.Errorf("cleared: %+v", m)
+	}
+	if m := CustomsMetrics(nil, start); m.Total != 0 {
+		t.Errorf("empty: %+v", m)
+	}
+}
+
+func TestMissingCustomsDocuments(t *testing.T) {
+	docs := []CustomsDocument{{DocType: CustomsDocTIR}, {DocType: CustomsDocOther}}
+	if got := MissingCustomsDocuments([]string{CustomsDocTIR, CustomsDocT1}, docs); !reflect.DeepEqual(got, []string{CustomsDocT1}) {
+		t.Errorf("missing: %v", got)
+	}
+}
@@ -0,0 +1,183 @@
+package trips
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"sarbonNew/internal/util"
+)
+
+// DeliveryPINLength — длина PIN-кода получателя.
+const DeliveryPINLength = 6
+
+var (
+	ErrDeliveryPINRequired = errors.New("delivery pin required")
+	ErrDeliveryPINInvalid  = errors.New("invalid delivery pin")
+	ErrDeliveryPINLocked   = errors.New("delivery pin locked: too many attempts")
+	ErrDeliveryPINNotFound = errors.New("delivery pin not found")
+	ErrDeliveryPINVerified = errors.New("delivery pin already verified")
+)
+
+// DeliveryPIN — PIN-код получателя рейса (trip_delivery_pins). Выдаётся при переходе рейса в EN_ROUTE;
+// водитель завершает рейс только после ввода верного PIN.
+type DeliveryPIN struct {
+	TripID      uuid.UUID
+	PIN         string
+	Attempts    int
+	SentToPhone *string
+	SentAt      *time.Time
+	VerifiedAt  *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Locked — исчерпаны попытки ввода; разблокируется только перевыпуском PIN.
+func (p *DeliveryPIN) Locked(maxAttempts int) bool {
+	return p.VerifiedAt == nil && maxAttempts > 0 && p.Attempts >= maxAttempts
+}
+
+// AttemptsLeft — сколько попыток ввода осталось (0 — заблокирован).
+func (p *DeliveryPIN) AttemptsLeft(maxAttempts int) int {
+	if maxAttempts <= 0 {
+		return 0
+	}
+	if left := maxAttempts - p.Attempts; left > 0 {
+		return left
+	}
+	return 0
+}
+
+// check сверяет введённый PIN; не меняет счётчик попыток.
+func (p *DeliveryPIN) check(pin string, maxAttempts int) error {
+	if p.VerifiedAt != nil {
+		return nil
+	}
+	if p.Locked(maxAttempts) {
+		return ErrDeliveryPINLocked
+	}
+	if subtle.ConstantTimeCompare([]byte(p.PIN), []byte(pin)) != 1 {
+		return ErrDeliveryPINInvalid
+	}
+	return nil
+}
+
+const deliveryPINColumns = `trip_id, pin, attempts, sent_to_phone, sent_at, verified_at, created_at, updated_at`
+
+func scanDeliveryPIN(row pgx.Row) (*DeliveryPIN, error) {
+	var p DeliveryPIN
+	err := row.Scan(&p.TripID, &p.PIN, &p.Attempts, &p.SentToPhone, &p.SentAt, &p.VerifiedAt, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// issueDeliveryPIN выдаёт PIN рейсу в транзакции смены статуса, если его ещё нет (повторный EN_ROUTE не меняет код).
+func issueDeliveryPIN(ctx context.Context, tx pgx.Tx, tripID uuid.UUID) error {
+	pin, err := util.GenerateNumericOTP(DeliveryPINLength)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `INSERT INTO trip_delivery_pins (trip_id, pin) VALUES ($1, $2) ON CONFLICT (trip_id) DO NOTHING`, tripID, pin)
+	return err
+}
+
+// RegenerateDeliveryPIN выпускает новый PIN и сбрасывает попытки и отметку об отправке; подтверждённый PIN не меняется.
+// Рейсу в пути или на выгрузке без PIN (выданному до его введения) PIN выдаётся впервые — иначе его не завершить.
+func (r *Repo) RegenerateDeliveryPIN(ctx context.Context, tripID uuid.UUID) (*DeliveryPIN, error) {
+	pin, err := util.GenerateNumericOTP(DeliveryPINLength)
+	if err != nil {
+		return nil, err
+	}
+	p, err := scanDeliveryPIN(r.pg.QueryRow(ctx, `
+UPDATE trip_delivery_pins SET pin = $2, attempts = 0, sent_to_phone = NULL, sent_at = NULL, updated_at = now()
+WHERE trip_id = $1 AND verified_at IS NULL
+RETURNING `+deliveryPINColumns, tripID, pin))
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return p, err
+	}
+	cur, err := r.GetDeliveryPIN(ctx, tripID)
+	if err != nil {
+		return nil, err
+	}
+	if cur != nil {
+		return nil, ErrDeliveryPINVerified
+	}
+	p, err = scanDeliveryPIN(r.pg.QueryRow(ctx, `
+INSERT INTO trip_delivery_pins (trip_id, pin) SELECT id, $2 FROM trips WHERE id = $1 AND status IN ($3, $4)
+ON CONFLICT (trip_id) DO NOTHING
+RETURNING `+deliveryPINColumns, tripID, pin, StatusEnRoute, StatusUnloading))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrDeliveryPINNotFound
+	}
+	return p, err
+}
+
+// GetDeliveryPIN returns delivery PIN of the trip (nil — PIN не выдан: рейс ещё не в пути или выдан до введения PIN).
+func (r *Repo) GetDeliveryPIN(ctx context.Context, tripID uuid.UUID) (*DeliveryPIN, error) {
+	p, err := scanDeliveryPIN(r.pg.QueryRow(ctx, `SELECT `+deliveryPINColumns+` FROM trip_delivery_pins WHERE trip_id = $1`, tripID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return p, err
+}
+
+// MarkDeliveryPINSent запоминает, на какой номер получателя отправлен PIN.
+func (r *Repo) MarkDeliveryPINSent(ctx context.Context, tripID uuid.UUID, phone string) (*DeliveryPIN, error) {
+	p, err := scanDeliveryPIN(r.pg.QueryRow(ctx, `
+UPDATE trip_delivery_pins SET sent_to_phone = $2, sent_at = now(), updated_at = now() WHERE trip_id = $1
+RETURNING `+deliveryPINColumns, tripID, phone))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrDeliveryPINNotFound
+	}
+	return p, err
+}
+
+// VerifyDeliveryPIN проверяет PIN, введённый водителем. Неверный ввод увеличивает счётчик попыток;
+// после maxAttempts — ErrDeliveryPINLocked. Без PIN рейс не завершить — ErrDeliveryPINNotFound (выпуск — RegenerateDeliveryPIN).
+func (r *Repo) VerifyDeliveryPIN(ctx context.Context, tripID uuid.UUID, pin string, maxAttempts int) (*DeliveryPIN, error) {
+	tx, err := r.pg.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	p, err := scanDeliveryPIN(tx.QueryRow(ctx, `SELECT `+deliveryPINColumns+` FROM trip_delivery_pins WHERE trip_id = $1 FOR UPDATE`, tripID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrDeliveryPINNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if p.VerifiedAt != nil {
+		return p, nil
+	}
+	if pin == "" {
+		return p, ErrDeliveryPINRequired
+	}
+	switch err := p.check(pin, maxAttempts); {
+	case errors.Is(err, ErrDeliveryPINInvalid):
+		if err := tx.QueryRow(ctx, `UPDATE trip_delivery_pins SET attempts = attempts + 1, updated_at = now() WHERE trip_id = $1 RETURNING attempts`, tripID).
+			Scan(&p.Attempts); err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return nil, err
+		}
+		if p.Locked(maxAttempts) {
+			return p, ErrDeliveryPINLocked
+		}
+		return p, ErrDeliveryPINInvalid
+	case err != nil:
+		return p, err
+	}
+	if err := tx.QueryRow(ctx, `UPDATE trip_delivery_pins SET verified_at = now(), updated_at = now() WHERE trip_id = $1 RETURNING verified_at`, tripID).
+		Scan(&p.VerifiedAt); err != nil {
+		return nil, err
+	}
+	return p, tx.Commit(ctx)
+}
@@ -0,0 +1,40 @@
+package trips
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDeliveryPINCheck(t *testing.T) {
+	p := &DeliveryPIN{PIN: "123456"}
+	if err := p.check("123456", 5); err != nil {
+		t.Fatalf("correct pin: %v", err)
+	}
+	if err := p.check("654321", 5); !errors.Is(err, ErrDeliveryPINInvalid) {
+		t.Fatalf("wrong pin: got %v", err)
+	}
+	p.Attempts = 5
+	if err := p.check("123456", 5); !errors.Is(err, ErrDeliveryPINLocked) {
+		t.Fatalf("locked pin must reject even the correct code: got %v", err)
+	}
+	now := time.Now()
+	p.VerifiedAt = &now
+	if err := p.check("", 5); err != nil {
+		t.Fatalf("verified pin must not be checked again: %v", err)
+	}
+}
+
+func TestDeliveryPINAttemptsLeft(t *testing.T) {
+	p := &DeliveryPIN{Attempts: 3}
+	if got := p.AttemptsLeft(5); got != 2 {
+		t.Errorf("AttemptsLeft = %d, want 2", got)
+	}
+	if p.Locked(5) {
+		t.Error("3 of 5 attempts must not lock")
+	}
+	p.Attempts = 7
+	if got := p.AttemptsLeft(5); got != 0 || !p.Locked(5) {
+		t.Errorf("AttemptsLeft = %d, Locked = %v; want 0, true", got, p.Locked(5))
+	}
+}
@@ -0,0 +1,187 @@
+package trips
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Статусы расхода водителя.
+const (
+	ExpensePending  = "PENDING"
+	ExpenseApproved = "APPROVED"
+	ExpenseRejected = "REJECTED"
+)
+
+var (
+	ErrExpenseNotFound = errors.New("trip expense not found")
+	ErrExpenseReviewed = errors.New("trip expense already reviewed")
+)
+
+// Expense — расход водителя в рейсе (trip_expenses); чек читается через ExpenseReceipt.
+type Expense struct {
+	ID                 uuid.UUID
+	TripID             uuid.UUID
+	DriverID           uuid.UUID
+	Category           string
+	Amount             float64
+	Currency           string
+	Comment            *string
+	ReceiptContentType *string
+	Lat                *float64
+	Lng                *float64
+	SpentAt            time.Time
+	Status             string
+	ReviewedByType     *string
+	ReviewedByID       *uuid.UUID
+	ReviewedAt         *time.Time
+	RejectReason       *string
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+// HasReceipt — к расходу приложено фото чека.
+func (e *Expense) HasReceipt() bool {
+	return e.ReceiptContentType != nil
+}
+
+// ExpenseTotal — суммы расходов рейса в одной валюте по статусам.
+type ExpenseTotal struct {
+	Currency string
+	Pending  float64
+	Approved float64
+	Rejected float64
+}
+
+// ExpenseTotals группирует расходы по валюте.
+func ExpenseTotals(list []Expense) []ExpenseTotal {
+	byCurrency := map[string]*ExpenseTotal{}
+	for _, e := range list {
+		t := byCurrency[e.Currency]
+		if t == nil {
+			t = &ExpenseTotal{Currency: e.Currency}
+			byCurrency[e.Currency] = t
+		}
+		switch e.Status {
+		case ExpenseApproved:
+			t.Approved = round2(t.Approved + e.Amount)
+		case ExpenseRejected:
+			t.Rejected = round2(t.Rejected + e.Amount)
+		default:
+			t.Pending = round2(t.Pending + e.Amount)
+		}
+	}
+	out := make([]ExpenseTotal, 0, len(byCurrency))
+	for _, t := range byCurrency {
+		out = append(out, *t)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Currency < out[j].Currency })
+	return out
+}
+
+// Profit — прибыль рейса: цена минус подтверждённые расходы. approved — подтверждённые расходы по валютам,
+// convert пересчитывает сумму в валюту цены; ok=false — для какой-то валюты нет курса (expenses и profit не считаются).
+func Profit(price float64, cur string, approved map[string]float64, convert func(amount float64, from, to string) (float64, bool)) (expenses, profit float64, ok bool) {
+	for from, amount := range approved {
+		v, ok := convert(amount, from, cur)
+		if !ok {
+			return 0, 0, false
+		}
+		expenses += v
+	}
+	expenses = round2(expenses)
+	return expenses, round2(price - expenses), true
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+const expenseColumns = `id, trip_id, driver_id, category, amount, currency, comment, receipt_content_type, lat, lng, spent_at,
+  status, reviewed_by_type, reviewed_by_id, reviewed_at, reject_reason, created_at, updated_at`
+
+func scanExpense(row pgx.Row) (*Expense, error) {
+	var e Expense
+	err := row.Scan(&e.ID, &e.TripID, &e.DriverID, &e.Category, &e.Amount, &e.Currency, &e.Comment, &e.ReceiptContentType,
+		&e.Lat, &e.Lng, &e.SpentAt, &e.Status, &e.ReviewedByType, &e.ReviewedByID, &e.ReviewedAt, &e.RejectReason,
+		&e.CreatedAt, &e.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// AddExpense сохраняет расход водителя (статус PENDING); receipt — фото чека, может быть nil.
+func (r *Repo) AddExpense(ctx context.Context, e Expense, receipt []byte) (*Expense, error) {
+	return scanExpense(r.pg.QueryRow(ctx, `
+INSERT INTO trip_expenses (trip_id, driver_id, category, amount, currency, comment, receipt_data, receipt_content_type, lat, lng, spent_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING `+expenseColumns,
+		e.TripID, e.DriverID, e.Category, e.Amount, e.Currency, e.Comment, receipt, e.ReceiptContentType, e.Lat, e.Lng, e.SpentAt))
+}
+
+// Expenses returns expenses of the trip (без чеков), по времени расхода.
+func (r *Repo) Expenses(ctx context.Context, tripID uuid.UUID) ([]Expense, error) {
+	rows, err := r.pg.Query(ctx, `SELECT `+expenseColumns+` FROM trip_expenses WHERE trip_id = $1 ORDER BY spent_at, created_at`, tripID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []Expense
+	for rows.Next() {
+		e, err := scanExpense(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *e)
+	}
+	return list, rows.Err()
+}
+
+// ExpenseReceipt returns receipt image of the expense (nil — расхода или чека нет).
+func (r *Repo) ExpenseReceipt(ctx context.Context, tripID, expenseID uuid.UUID) (data []byte, contentType string, err error) {
+	var ct *string
+	err = r.pg.QueryRow(ctx, `SELECT receipt_data, receipt_content_type FROM trip_expenses WHERE id = $1 AND trip_id = $2`,
+		expenseID, tripID).Scan(&data, &ct)
+	if errors.Is(err, pgx.ErrNoRows) || ct == nil {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return data, *ct, nil
+}
+
+// DeleteExpense — водитель удаляет свой расход, пока он не проверен. false — расхода нет или он уже проверен.
+func (r *Repo) DeleteExpense(ctx context.Context, tripID, expenseID, driverID uuid.UUID) (bool, error) {
+	tag, err := r.pg.Exec(ctx, `
+DELETE FROM trip_expenses WHERE id = $1 AND trip_id = $2 AND driver_id = $3 AND status = 'PENDING'`, expenseID, tripID, driverID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ReviewExpense подтверждает (APPROVED) или отклоняет (REJECTED, с причиной) расход на проверке.
+func (r *Repo) ReviewExpense(ctx context.Context, tripID, expenseID uuid.UUID, status, byType string, byID uuid.UUID, reason *string) (*Expense, error) {
+	e, err := scanExpense(r.pg.QueryRow(ctx, `
+UPDATE trip_expenses SET status = $3, reviewed_by_type = $4, reviewed_by_id = $5, reviewed_at = now(), reject_reason = $6, updated_at = now()
+WHERE id = $1 AND trip_id = $2 AND status = 'PENDING'
+RETURNING `+expenseColumns, expenseID, tripID, status, byType, byID, reason))
+	if errors.Is(err, pgx.ErrNoRows) {
+		var exists bool
+		if err := r.pg.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM trip_expenses WHERE id = $1 AND trip_id = $2)`, expenseID, tripID).Scan(&exists); err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, ErrExpenseReviewed
+		}
+		return nil, ErrExpenseNotFound
+	}
+	return e, err
+}
@@ -0,0 +1,42 @@
+package trips
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExpenseTotals(t *testing.T) {
+	list := []Expense{
+		{Currency: "UZS", Amount: 300000, Status: ExpenseApproved},
+		{Currency: "USD", Amount: 40.5, Status: ExpenseApproved},
+		{Currency: "USD", Amount: 10.25, Status: ExpenseApproved},
+		{Currency: "USD", Amount: 15, Status: ExpensePending},
+		{Currency: "USD", Amount: 99, Status: ExpenseRejected},
+	}
+	got := ExpenseTotals(list)
+	if len(got) != 2 || got[0].Currency != "USD" || got[1].Currency != "UZS" {
+		t.Fatalf("totals: %+v", got)
+	}
+	if usd := got[0]; usd.Approved != 50.75 || usd.Pending != 15 || usd.Rejected != 99 {
+		t.Errorf("USD: %+v", usd)
+	}
+	if uzs := got[1]; uzs.Approved != 300000 || uzs.Pending != 0 {
+		t.Errorf("UZS: %+v", uzs)
+	}
+}
+
+func TestProfit(t *testing.T) {
+	sameCurrency := func(amount float64, from, to string) (float64, bool) {
+		return amount, strings.EqualFold(from, to)
+	}
+	expenses, profit, ok := Profit(1000, "USD", map[string]float64{"USD": 120.4}, sameCurrency)
+	if !ok || expenses != 120.4 || profit != 879.6 {
+		t.Errorf("same currency: %v %v %v", expenses, profit, ok)
+	}
+	if _, _, ok := Profit(1000, "USD", map[string]float64{"UZS": 500000}, sameCurrency); ok {
+		t.Error("expected no rate for UZS")
+	}
+	if expenses, profit, ok := Profit(1000, "USD", nil, sameCurrency); !ok || expenses != 0 || profit != 1000 {
+		t.Errorf("no expenses: %v %v %v", expenses, profit, ok)
+	}
+}
@@ -0,0 +1,174 @@
+package trips
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StatusChange — запись trip_status_history: рейс перешёл в ToStatus в момент ChangedAt.
+type StatusChange struct {
+	FromStatus *string
+	ToStatus   string
+	ChangedAt  time.Time
+}
+
+// IsFinal — рейс в конечном статусе (завершён или отменён).
+func IsFinal(status string) bool {
+	return status == StatusCompleted || status == StatusCancelled
+}
+
+// StatusDurations считает, сколько рейс провёл в каждом статусе по истории переходов (по возрастанию ChangedAt).
+// Последний незавершённый статус считается до now; конечные статусы длительности не имеют.
+func StatusDurations(history []StatusChange, now time.Time) map[string]time.Duration {
+	out := make(map[string]time.Duration)
+	for i, h := range history {
+		if IsFinal(h.ToStatus) {
+			continue
+		}
+		end := now
+		if i+1 < len(history) {
+			end = history[i+1].ChangedAt
+		}
+		if d := end.Sub(h.ChangedAt); d > 0 {
+			out[h.ToStatus] += d
+		}
+	}
+	return out
+}
+
+// History returns status changes of the trip, oldest first.
+func (r *Repo) History(ctx context.Context, tripID uuid.UUID) ([]StatusChange, error) {
+	rows, err := r.pg.Query(ctx,
+		`SELECT from_status, to_status, changed_at FROM trip_status_history WHERE trip_id = $1 ORDER BY changed_at, id`,
+		tripID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []StatusChange
+	for rows.Next() {
+		var h StatusChange
+		if err := rows.Scan(&h.FromStatus, &h.ToStatus, &h.ChangedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, h)
+	}
+	return list, rows.Err()
+}
+
+// ExportFilter — фильтр отчёта по рейсам.
+type ExportFilter struct {
+	Status      []string
+	DriverID    *uuid.UUID
+	CreatedFrom string // YYYY-MM-DD
+	CreatedTo   string
+}
+
+// ExportRow — строка отчёта по рейсам: рейс, история статусов, водитель и направление груза.
+type ExportRow struct {
+	Trip
+	History     []StatusChange
+	DriverName  *string
+	DriverPhone *string
+	LoadCity    *string
+	UnloadCity  *string
+	DistanceKm  *float64
+	CompletedAt *time.Time
+	// ApprovedExpenses — подтверждённые расходы водителя по валютам.
+	ApprovedExpenses map[string]float64
+}
+
+func exportWhere(f ExportFilter) (string, []any) {
+	conds := []string{"true"}
+	var args []any
+	add := func(cond string, v any) {
+		args = append(args, v)
+		conds = append(conds, strings.ReplaceAll(cond, "?", "$"+strconv.Itoa(len(args))))
+	}
+	if len(f.Status) > 0 {
+		add("t.status = ANY(?)", f.Status)
+	}
+	if f.DriverID != nil {
+		add("t.driver_id = ?", *f.DriverID)
+	}
+	if f.CreatedFrom != "" {
+		add("t.created_at::date >= ?", f.CreatedFrom)
+	}
+	if f.CreatedTo != "" {
+		add("t.created_at::date <= ?", f.CreatedTo)
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+// CountForExport returns number of trips matching the export filter.
+func (r *Repo) CountForExport(ctx context.Context, f ExportFilter) (int, error) {
+	where, args := exportWhere(f)
+	var n int
+	err := r.pg.QueryRow(ctx, "SELECT COUNT(*) FROM trips t WHERE "+where, args...).Scan(&n)
+	return n, err
+}
+
+// ExportRows проходит по рейсам фильтра (новые первыми) вместе с историей статусов и вызывает fn для каждой строки.
+func (r *Repo) ExportRows(ctx context.Context, f ExportFilter, fn func(*ExportRow) error) error {
+	where, args := exportWhere(f)
+	rows, err := r.pg.Query(ctx, `
+SELECT t.id, t.cargo_id, t.offer_id, t.driver_id, t.status, t.agreed_price, t.agreed_currency, t.parent_trip_id, t.created_at, t.updated_at,
+  d.name, d.phone, lp.city_code, up.city_code, c.distance_km::float8,
+  COALESCE(h.from_statuses, '{}'), COALESCE(h.to_statuses, '{}'), COALESCE(h.changed_ats, '{}'),
+  COALESCE(ex.currencies, '{}'), COALESCE(ex.amounts, '{}')
+FROM trips t
+JOIN cargo c ON c.id = t.cargo_id
+LEFT JOIN drivers d ON d.id = t.driver_id
+LEFT JOIN LATERAL (SELECT rp.city_code FROM route_points rp WHERE rp.cargo_id = t.cargo_id AND rp.is_main_load ORDER BY rp.point_order LIMIT 1) lp ON true
+LEFT JOIN LATERAL (SELECT rp.city_code FROM route_points rp WHERE rp.cargo_id = t.cargo_id AND rp.is_main_unload ORDER BY rp.point_order LIMIT 1) up ON true
+LEFT JOIN LATERAL (
+  SELECT array_agg(COALESCE(x.from_status, '') ORDER BY x.changed_at, x.id) AS from_statuses,
+    array_agg(x.to_status ORDER BY x.changed_at, x.id) AS to_statuses,
+    array_agg(x.changed_at ORDER BY x.changed_at, x.id) AS changed_ats
+  FROM trip_status_history x WHERE x.trip_id = t.id
+) h ON true
+LEFT JOIN LATERAL (
+  SELECT array_agg(s.currency) AS currencies, array_agg(s.total) AS amounts
+  FROM (SELECT x.currency, SUM(x.amount) AS total FROM trip_expenses x WHERE x.trip_id = t.id AND x.status = 'APPROVED' GROUP BY x.currency) s
+) ex ON true
+WHERE `+where+` ORDER BY t.created_at DESC`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var e ExportRow
+		t := &e.Trip
+		var from, to []string
+		var at []time.Time
+		var currencies []string
+		var amounts []float64
+		err := rows.Scan(&t.ID, &t.CargoID, &t.OfferID, &t.DriverID, &t.Status, &t.AgreedPrice, &t.AgreedCurrency, &t.ParentTripID, &t.CreatedAt, &t.UpdatedAt,
+			&e.DriverName, &e.DriverPhone, &e.LoadCity, &e.UnloadCity, &e.DistanceKm, &from, &to, &at, &currencies, &amounts)
+		if err != nil {
+			return err
+		}
+		e.ApprovedExpenses = make(map[string]float64, len(currencies))
+		for i := range currencies {
+			e.ApprovedExpenses[currencies[i]] = amounts[i]
+		}
+		for i := range to {
+			h := StatusChange{ToStatus: to[i], ChangedAt: at[i]}
+			if i < len(from) && from[i] != "" {
+				h.FromStatus = &from[i]
+			}
+			e.History = append(e.History, h)
+			if to[i] == StatusCompleted {
+				e.CompletedAt = &at[i]
+			}
+		}
+		if err := fn(&e); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,41 @@
+package trips
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStatusDurations(t *testing.T) {
+	t0 := time.Date(2026, 5, 1, 8, 0, 0, 0, time.UTC)
+	at := func(h int) time.Time { return t0.Add(time.Duration(h) * time.Hour) }
+	history := []StatusChange{
+		{ToStatus: StatusPendingDriver, ChangedAt: at(0)},
+		{ToStatus: StatusAssigned, ChangedAt: at(2)},
+		{ToStatus: StatusLoading, ChangedAt: at(5)},
+		{ToStatus: StatusEnRoute, ChangedAt: at(6)},
+		{ToStatus: StatusUnloading, ChangedAt: at(30)},
+		{ToStatus: StatusCompleted, ChangedAt: at(32)},
+	}
+	got := StatusDurations(history, at(100))
+	want := map[string]time.Duration{
+		StatusPendingDriver: 2 * time.Hour,
+		StatusAssigned:      3 * time.Hour,
+		StatusLoading:       time.Hour,
+		StatusEnRoute:       24 * time.Hour,
+		StatusUnloading:     2 * time.Hour,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for s, d := range want {
+		if got[s] != d {
+			t.Errorf("%s: got %v, want %v", s, got[s], d)
+		}
+	}
+
+	// незавершённый рейс: текущий статус считается до now
+	open := StatusDurations(history[:3], at(9))
+	if open[StatusLoading] != 4*time.Hour {
+		t.Errorf("open loading: got %v, want 4h", open[StatusLoading])
+	}
+}
@@ -0,0 +1,337 @@
+package trips
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Статусы инцидента в рейсе.
+const (
+	IncidentOpen         = "OPEN"
+	IncidentAcknowledged = "ACKNOWLEDGED"
+	IncidentResolved     = "RESOLVED"
+)
+
+// Серьёзность инцидента; SOS всегда CRITICAL.
+const (
+	SeverityLow      = "LOW"
+	SeverityMedium   = "MEDIUM"
+	SeverityHigh     = "HIGH"
+	SeverityCritical = "CRITICAL"
+)
+
+// IncidentTypeOther — тип SOS, если водитель его не указал.
+const IncidentTypeOther = "OTHER"
+
+// MaxIncidentPhotos — сколько фото можно приложить к одному инциденту.
+const MaxIncidentPhotos = 10
+
+var (
+	ErrIncidentNotFound   = errors.New("trip incident not found")
+	ErrIncidentTransition = errors.New("invalid incident status transition")
+	ErrIncidentPhotoLimit = errors.New("trip incident photo limit reached")
+	ErrIncidentResolved   = errors.New("trip incident already resolved")
+)
+
+// incidentTransitions — допустимые переходы статуса инцидента; решённый инцидент не меняется.
+var incidentTransitions = map[string][]string{
+	IncidentOpen:         {IncidentAcknowledged, IncidentResolved},
+	IncidentAcknowledged: {IncidentResolved},
+	IncidentResolved:     nil,
+}
+
+// CanIncidentTransition — можно ли перевести инцидент из from в to.
+func CanIncidentTransition(from, to string) bool {
+	for _, s := range incidentTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Incident — инцидент водителя в рейсе (trip_incidents); фото читаются через IncidentPhotos.
+type Incident struct {
+	ID                 uuid.UUID
+	TripID             uuid.UUID
+	DriverID           uuid.UUID
+	Type               string
+	Severity           string
+	IsSOS              bool
+	Description        *string
+	Lat                *float64
+	Lng                *float64
+	Status             string
+	AcknowledgedByType *string
+	AcknowledgedByID   *uuid.UUID
+	AcknowledgedAt     *time.Time
+	ResolvedByType     *string
+	ResolvedByID       *uuid.UUID
+	ResolvedAt         *time.Time
+	Resolution         *string
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+// IncidentEvent — смена статуса инцидента (trip_incident_events).
+type IncidentEvent struct {
+	IncidentID uuid.UUID
+	FromStatus *string
+	ToStatus   string
+	Comment    *string
+	ByType     string
+	ByID       uuid.UUID
+	CreatedAt  time.Time
+}
+
+// IncidentPhoto — фото инцидента (без данных; данные — IncidentPhotoData).
+type IncidentPhoto struct {
+	ID          uuid.UUID
+	IncidentID  uuid.UUID
+	ContentType string
+	CreatedAt   time.Time
+}
+
+// IncidentStats — сводка по истории инцидентов водителя или рейса.
+type IncidentStats struct {
+	Total  int
+	Open   int // OPEN и ACKNOWLEDGED
+	SOS    int
+	ByType map[string]int
+}
+
+// IncidentSummary считает сводку по списку инцидентов.
+func IncidentSummary(list []Incident) IncidentStats {
+	s := IncidentStats{ByType: map[string]int{}}
+	for _, in := range list {
+		s.Total++
+		if in.Status != IncidentResolved {
+			s.Open++
+		}
+		if in.IsSOS {
+			s.SOS++
+		}
+		s.ByType[in.Type]++
+	}
+	return s
+}
+
+const incidentColumns = `id, trip_id, driver_id, type, severity, is_sos, description, lat, lng, status,
+  acknowledged_by_type, acknowledged_by_id, acknowledged_at, resolved_by_type, resolved_by_id, resolved_at, resolution,
+  created_at, updated_at`
+
+func scanIncident(row pgx.Row) (*Incident, error) {
+	var in Incident
+	err := row.Scan(&in.ID, &in.TripID, &in.DriverID, &in.Type, &in.Severity, &in.IsSOS, &in.Description, &in.Lat, &in.Lng,
+		&in.Status, &in.AcknowledgedByType, &in.AcknowledgedByID, &in.AcknowledgedAt, &in.ResolvedByType, &in.ResolvedByID,
+		&in.ResolvedAt, &in.Resolution, &in.CreatedAt, &in.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &in, nil
+}
+
+func (r *Repo) queryIncidents(ctx context.Context, q string, args ...any) ([]Incident, error) {
+	rows, err := r.pg.Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []Incident
+	for rows.Next() {
+		in, err := scanIncident(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *in)
+	}
+	return list, rows.Err()
+}
+
+// AddIncident сохраняет инцидент водителя (статус OPEN) и первое событие истории; photo — фото, может быть nil.
+func (r *Repo) AddIncident(ctx context.Context, in Incident, photo []byte, photoContentType string) (*Incident, error) {
+	tx, err := r.pg.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	saved, err := scanIncident(tx.QueryRow(ctx, `
+INSERT INTO trip_incidents (trip_id, driver_id, type, severity, is_sos, description, lat, lng)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING `+incidentColumns,
+		in.TripID, in.DriverID, in.Type, in.Severity, in.IsSOS, in.Description, in.Lat, in.Lng))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, `
+INSERT INTO trip_incident_events (incident_id, to_status, comment, by_type, by_id) VALUES ($1, $2, $3, 'DRIVER', $4)`,
+		saved.ID, IncidentOpen, in.Description, in.DriverID); err != nil {
+		return nil, err
+	}
+	if photo != nil {
+		if _, err := tx.Exec(ctx, `INSERT INTO trip_incident_photos (incident_id, data, content_type) VALUES ($1, $2, $3)`,
+			saved.ID, photo, photoContentType); err != nil {
+			return nil, err
+		}
+	}
+	return saved, tx.Commit(ctx)
+}
+
+// GetIncident returns incident of the trip (nil — не найден).
+func (r *Repo) GetIncident(ctx context.Context, tripID, incidentID uuid.UUID) (*Incident, error) {
+	in, err := scanIncident(r.pg.QueryRow(ctx, `SELECT `+incidentColumns+` FROM trip_incidents WHERE id = $1 AND trip_id = $2`, incidentID, tripID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return in, err
+}
+
+// Incidents returns incidents of the trip, новые первыми.
+func (r *Repo) Incidents(ctx context.Context, tripID uuid.UUID) ([]Incident, error) {
+	return r.queryIncidents(ctx, `SELECT `+incidentColumns+` FROM trip_incidents WHERE trip_id = $1 ORDER BY created_at DESC`, tripID)
+}
+
+// DriverIncidents returns incident history of the driver across trips, новые первыми.
+func (r *Repo) DriverIncidents(ctx context.Context, driverID uuid.UUID, limit, offset int) ([]Incident, error) {
+	return r.queryIncidents(ctx, `
+SELECT `+incidentColumns+` FROM trip_incidents WHERE driver_id = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3`,
+		driverID, limit, offset)
+}
+
+// IncidentEvents returns status history of the trip incidents по возрастанию времени.
+func (r *Repo) IncidentEvents(ctx context.Context, tripID uuid.UUID) ([]IncidentEvent, error) {
+	rows, err := r.pg.Query(ctx, `
+SELECT e.incident_id, e.from_status, e.to_status, e.comment, e.by_type, e.by_id, e.created_at
+FROM trip_incident_events e JOIN trip_incidents i ON i.id = e.incident_id
+WHERE i.trip_id = $1 ORDER BY e.created_at`, tripID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []IncidentEvent
+	for rows.Next() {
+		var e IncidentEvent
+		if err := rows.Scan(&e.IncidentID, &e.FromStatus, &e.ToStatus, &e.Comment, &e.ByType, &e.ByID, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+	}
+	return list, rows.Err()
+}
+
+// SetIncidentStatus переводит инцидент в ACKNOWLEDGED или RESOLVED (comment — решение) и пишет событие в историю.
+// ErrIncidentNotFound — инцидента нет, ErrIncidentTransition — переход не допускается.
+func (r *Repo) SetIncidentStatus(ctx context.Context, tripID, incidentID uuid.UUID, status string, comment *string, byType string, byID uuid.UUID) (*Incident, error) {
+	tx, err := r.pg.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	var from string
+	err = tx.QueryRow(ctx, `SELECT status FROM trip_incidents WHERE id = $1 AND trip_id = $2 FOR UPDATE`, incidentID, tripID).Scan(&from)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrIncidentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !CanIncidentTransition(from, status) {
+		return nil, ErrIncidentTransition
+	}
+	q := `
+UPDATE trip_incidents SET status = $2, acknowledged_by_type = $3, acknowledged_by_id = $4, acknowledged_at = now(), updated_at = now()
+WHERE id = $1 RETURNING ` + incidentColumns
+	args := []any{incidentID, status, byType, byID}
+	if status == IncidentResolved {
+		q = `
+UPDATE trip_incidents SET status = $2, resolved_by_type = $3, resolved_by_id = $4, resolved_at = now(), resolution = $5, updated_at = now()
+WHERE id = $1 RETURNING ` + incidentColumns
+		args = append(args, comment)
+	}
+	in, err := scanIncident(tx.QueryRow(ctx, q, args...))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, `
+INSERT INTO trip_incident_events (incident_id, from_status, to_status, comment, by_type, by_id) VALUES ($1, $2, $3, $4, $5, $6)`,
+		incidentID, from, status, comment, byType, byID); err != nil {
+		return nil, err
+	}
+	return in, tx.Commit(ctx)
+}
+
+// AddIncidentPhoto прикладывает фото к нерешённому инциденту водителя.
+// ErrIncidentNotFound, ErrIncidentResolved, ErrIncidentPhotoLimit (больше MaxIncidentPhotos).
+func (r *Repo) AddIncidentPhoto(ctx context.Context, tripID, incidentID, driverID uuid.UUID, data []byte, contentType string) (*IncidentPhoto, error) {
+	tx, err := r.pg.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	var status string
+	err = tx.QueryRow(ctx, `SELECT status FROM trip_incidents WHERE id = $1 AND trip_id = $2 AND driver_id = $3 FOR UPDATE`,
+		incidentID, tripID, driverID).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrIncidentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if status == IncidentResolved {
+		return nil, ErrIncidentResolved
+	}
+	var n int
+	if err := tx.QueryRow(ctx, `SELECT count(*) FROM trip_incident_photos WHERE incident_id = $1`, incidentID).Scan(&n); err != nil {
+		return nil, err
+	}
+	if n >= MaxIncidentPhotos {
+		return nil, ErrIncidentPhotoLimit
+	}
+	p := IncidentPhoto{IncidentID: incidentID, ContentType: contentType}
+	if err := tx.QueryRow(ctx, `
+INSERT INTO trip_incident_photos (incident_id, data, content_type) VALUES ($1, $2, $3) RETURNING id, created_at`,
+		incidentID, data, contentType).Scan(&p.ID, &p.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &p, tx.Commit(ctx)
+}
+
+// IncidentPhotos returns photos (без данных) of the given incidents.
+func (r *Repo) IncidentPhotos(ctx context.Context, incidentIDs []uuid.UUID) ([]IncidentPhoto, error) {
+	if len(incidentIDs) == 0 {
+		return nil, nil
+	}
+	rows, err := r.pg.Query(ctx, `
+SELECT id, incident_id, content_type, created_at FROM trip_incident_photos WHERE incident_id = ANY($1) ORDER BY created_at`, incidentIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []IncidentPhoto
+	for rows.Next() {
+		var p IncidentPhoto
+		if err := rows.Scan(&p.ID, &p.IncidentID, &p.ContentType, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, p)
+	}
+	return list, rows.Err()
+}
+
+// IncidentPhotoData returns image of the incident photo (nil — фото нет).
+func (r *Repo) IncidentPhotoData(ctx context.Context, tripID, incidentID, photoID uuid.UUID) (data []byte, contentType string, err error) {
+	err = r.pg.QueryRow(ctx, `
+SELECT p.data, p.content_type FROM trip_incident_photos p JOIN trip_incidents i ON i.id = p.incident_id
+WHERE p.id = $3 AND p.incident_id = $2 AND i.trip_id = $1`, tripID, incidentID, photoID).Scan(&data, &contentType)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return data, contentType, nil
+}
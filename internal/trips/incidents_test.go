@@ -0,0 +1,37 @@
+package trips
+
+import "testing"
+
+func TestCanIncidentTransition(t *testing.T) {
+	cases := []struct {
+		from, to string
+		want     bool
+	}{
+		{IncidentOpen, IncidentAcknowledged, true},
+		{IncidentOpen, IncidentResolved, true},
+		{IncidentAcknowledged, IncidentResolved, true},
+		{IncidentAcknowledged, IncidentOpen, false},
+		{IncidentAcknowledged, IncidentAcknowledged, false},
+		{IncidentResolved, IncidentAcknowledged, false},
+	}
+	for _, c := range cases {
+		if got := CanIncidentTransition(c.from, c.to); got != c.want {
+			t.Errorf("%q -> %q: got %v, want %v", c.from, c.to, got, c.want)
+		}
+	}
+}
+
+func TestIncidentSummary(t *testing.T) {
+	list := []Incident{
+		{Type: "BREAKDOWN", Status: IncidentResolved},
+		{Type: "BREAKDOWN", Status: IncidentAcknowledged},
+		{Type: "ACCIDENT", Status: IncidentOpen, IsSOS: true},
+	}
+	s := IncidentSummary(list)
+	if s.Total != 3 || s.Open != 2 || s.SOS != 1 || s.ByType["BREAKDOWN"] != 2 || s.ByType["ACCIDENT"] != 1 {
+		t.Errorf("summary: %+v", s)
+	}
+	if s := IncidentSummary(nil); s.Total != 0 || len(s.ByType) != 0 {
+		t.Errorf("empty: %+v", s)
+	}
+}
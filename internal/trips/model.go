@@ -17,11 +17,16 @@ const (
 )
 
 type Trip struct {
-	ID        uuid.UUID
-	CargoID   uuid.UUID
-	OfferID   uuid.UUID
-	DriverID  *uuid.UUID
-	Status    string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID       uuid.UUID
+	CargoID  uuid.UUID
+	OfferID  uuid.UUID
+	DriverID *uuid.UUID
+	Status   string
+	// AgreedPrice/AgreedCurrency — цена принятого оффера (после торга).
+	AgreedPrice    *float64
+	AgreedCurrency *string
+	// ParentTripID — рейс-носитель, в который консолидирован этот рейс (несколько грузов в одной машине).
+	ParentTripID *uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
 }
@@ -0,0 +1,256 @@
+package trips
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Статусы подтверждения доставки (ePOD).
+const (
+	PODSubmitted = "SUBMITTED"
+	PODDisputed  = "DISPUTED"
+)
+
+// MaxPODPhotos — максимум фото доставленного груза на рейс.
+const MaxPODPhotos = 10
+
+var (
+	ErrPODRequired         = errors.New("trip can be completed only by submitting proof of delivery")
+	ErrPODNoPhotos         = errors.New("proof of delivery requires at least one photo")
+	ErrPODTooManyPhotos    = errors.New("too many proof of delivery photos")
+	ErrPODAlreadySubmitted = errors.New("proof of delivery already submitted")
+	ErrPODNotFound         = errors.New("proof of delivery not found")
+	ErrPODAlreadyDisputed  = errors.New("proof of delivery already disputed")
+	ErrPODDisputeClosed    = errors.New("proof of delivery dispute window closed")
+)
+
+// PODPhoto — фото доставленного груза (trip_pod_photos); само изображение читается через PODPhotoData.
+type PODPhoto struct {
+	ID          uuid.UUID
+	TripID      uuid.UUID
+	ContentType string
+	Lat         *float64
+	Lng         *float64
+	TakenAt     time.Time
+	CreatedAt   time.Time
+}
+
+// POD — подтверждение доставки (trip_pods); подпись читается через PODSignature.
+type POD struct {
+	TripID         uuid.UUID
+	DriverID       uuid.UUID
+	ConsigneeName  string
+	Lat            float64
+	Lng            float64
+	DeliveredAt    time.Time
+	DisputeUntil   time.Time
+	Status         string
+	DisputeReason  *string
+	DisputedByType *string
+	DisputedByID   *uuid.UUID
+	DisputedAt     *time.Time
+}
+
+// CanDispute — спор можно открыть один раз и только до DisputeUntil.
+func (p *POD) CanDispute(now time.Time) bool {
+	return p.Status == PODSubmitted && now.Before(p.DisputeUntil)
+}
+
+// SubmitPODParams — данные, которые водитель передаёт при сдаче груза.
+type SubmitPODParams struct {
+	TripID               uuid.UUID
+	DriverID             uuid.UUID
+	ConsigneeName        string
+	Signature            []byte
+	SignatureContentType string
+	Lat                  float64
+	Lng                  float64
+	DisputeWindow        time.Duration
+}
+
+const podColumns = `trip_id, driver_id, consignee_name, lat, lng, delivered_at, dispute_until, status,
+  dispute_reason, disputed_by_type, disputed_by_id, disputed_at`
+
+func scanPOD(row pgx.Row) (*POD, error) {
+	var p POD
+	err := row.Scan(&p.TripID, &p.DriverID, &p.ConsigneeName, &p.Lat, &p.Lng, &p.DeliveredAt, &p.DisputeUntil, &p.Status,
+		&p.DisputeReason, &p.DisputedByType, &p.DisputedByID, &p.DisputedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// AddPODPhoto сохраняет фото доставки; не больше MaxPODPhotos на рейс и только пока POD не сдан.
+func (r *Repo) AddPODPhoto(ctx context.Context, tripID uuid.UUID, data []byte, contentType string, lat, lng *float64, takenAt time.Time) (*PODPhoto, error) {
+	var p PODPhoto
+	err := r.pg.QueryRow(ctx, `
+INSERT INTO trip_pod_photos (trip_id, data, content_type, lat, lng, taken_at)
+SELECT $1, $2, $3, $4, $5, $6
+WHERE (SELECT count(*) FROM trip_pod_photos WHERE trip_id = $1) < $7
+  AND NOT EXISTS (SELECT 1 FROM trip_pods WHERE trip_id = $1)
+RETURNING id, trip_id, content_type, lat, lng, taken_at, created_at`,
+		tripID, data, contentType, lat, lng, takenAt, MaxPODPhotos).Scan(&p.ID, &p.TripID, &p.ContentType, &p.Lat, &p.Lng, &p.TakenAt, &p.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		pod, err := r.GetPOD(ctx, tripID)
+		if err != nil {
+			return nil, err
+		}
+		if pod != nil {
+			return nil, ErrPODAlreadySubmitted
+		}
+		return nil, ErrPODTooManyPhotos
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// DeletePODPhoto удаляет фото, пока POD не сдан. false — фото нет (или POD уже сдан).
+func (r *Repo) DeletePODPhoto(ctx context.Context, tripID, photoID uuid.UUID) (bool, error) {
+	tag, err := r.pg.Exec(ctx, `
+DELETE FROM trip_pod_photos WHERE id = $1 AND trip_id = $2 AND NOT EXISTS (SELECT 1 FROM trip_pods WHERE trip_id = $2)`,
+		photoID, tripID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// PODPhotos returns photos of the trip delivery (без данных), в порядке загрузки.
+func (r *Repo) PODPhotos(ctx context.Context, tripID uuid.UUID) ([]PODPhoto, error) {
+	rows, err := r.pg.Query(ctx, `
+SELECT id, trip_id, content_type, lat, lng, taken_at, created_at FROM trip_pod_photos WHERE trip_id = $1 ORDER BY created_at`, tripID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []PODPhoto
+	for rows.Next() {
+		var p PODPhoto
+		if err := rows.Scan(&p.ID, &p.TripID, &p.ContentType, &p.Lat, &p.Lng, &p.TakenAt, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, p)
+	}
+	return list, rows.Err()
+}
+
+// PODPhotoData returns image of a delivery photo; nil data — фото нет.
+func (r *Repo) PODPhotoData(ctx context.Context, tripID, photoID uuid.UUID) (data []byte, contentType string, err error) {
+	err = r.pg.QueryRow(ctx, `SELECT data, content_type FROM trip_pod_photos WHERE id = $1 AND trip_id = $2`, photoID, tripID).
+		Scan(&data, &contentType)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, "", nil
+	}
+	return data, contentType, err
+}
+
+// SubmitPOD сохраняет подтверждение доставки и завершает рейс (UNLOADING → COMPLETED) в одной транзакции.
+// PIN получателя должен быть уже подтверждён (VerifyDeliveryPIN); рейс без PIN — ErrDeliveryPINNotFound.
+func (r *Repo) SubmitPOD(ctx context.Context, p SubmitPODParams) (*POD, error) {
+	tx, err := r.pg.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	var status string
+	var driverID *uuid.UUID
+	err = tx.QueryRow(ctx, `SELECT status, driver_id FROM trips WHERE id = $1 FOR UPDATE`, p.TripID).Scan(&status, &driverID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if driverID == nil || *driverID != p.DriverID {
+		return nil, ErrNotFound
+	}
+	if status == StatusCompleted {
+		return nil, ErrPODAlreadySubmitted
+	}
+	if status != StatusUnloading {
+		return nil, ErrInvalidTransition
+	}
+	var photos int
+	if err := tx.QueryRow(ctx, `SELECT count(*) FROM trip_pod_photos WHERE trip_id = $1`, p.TripID).Scan(&photos); err != nil {
+		return nil, err
+	}
+	if photos == 0 {
+		return nil, ErrPODNoPhotos
+	}
+	var pinPending bool
+	err = tx.QueryRow(ctx, `SELECT verified_at IS NULL FROM trip_delivery_pins WHERE trip_id = $1`, p.TripID).Scan(&pinPending)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrDeliveryPINNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if pinPending {
+		return nil, ErrDeliveryPINRequired
+	}
+	pod, err := scanPOD(tx.QueryRow(ctx, `
+INSERT INTO trip_pods (trip_id, driver_id, consignee_name, signature_data, signature_content_type, lat, lng, dispute_until)
+VALUES ($1, $2, $3, $4, $5, $6, $7, now() + make_interval(secs => $8))
+RETURNING `+podColumns,
+		p.TripID, p.DriverID, p.ConsigneeName, p.Signature, p.SignatureContentType, p.Lat, p.Lng, p.DisputeWindow.Seconds()))
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.Exec(ctx, `
+WITH t AS (
+  UPDATE trips SET status = $1, updated_at = now() WHERE id = $2 RETURNING id
+)
+INSERT INTO trip_status_history (trip_id, from_status, to_status) SELECT id, $3, $1 FROM t`,
+		StatusCompleted, p.TripID, StatusUnloading)
+	if err != nil {
+		return nil, err
+	}
+	return pod, tx.Commit(ctx)
+}
+
+// GetPOD returns proof of delivery of the trip (nil — ещё не сдан).
+func (r *Repo) GetPOD(ctx context.Context, tripID uuid.UUID) (*POD, error) {
+	p, err := scanPOD(r.pg.QueryRow(ctx, `SELECT `+podColumns+` FROM trip_pods WHERE trip_id = $1`, tripID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return p, err
+}
+
+// PODSignature returns signature image of the consignee; nil data — POD не сдан.
+func (r *Repo) PODSignature(ctx context.Context, tripID uuid.UUID) (data []byte, contentType string, err error) {
+	err = r.pg.QueryRow(ctx, `SELECT signature_data, signature_content_type FROM trip_pods WHERE trip_id = $1`, tripID).
+		Scan(&data, &contentType)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, "", nil
+	}
+	return data, contentType, err
+}
+
+// DisputePOD открывает спор по доставке (один раз, до dispute_until). byType — DISPATCHER или COMPANY.
+func (r *Repo) DisputePOD(ctx context.Context, tripID uuid.UUID, byType string, byID uuid.UUID, reason string) (*POD, error) {
+	p, err := scanPOD(r.pg.QueryRow(ctx, `
+UPDATE trip_pods SET status = $2, dispute_reason = $3, disputed_by_type = $4, disputed_by_id = $5, disputed_at = now()
+WHERE trip_id = $1 AND status = $6 AND dispute_until > now()
+RETURNING `+podColumns, tripID, PODDisputed, reason, byType, byID, PODSubmitted))
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return p, err
+	}
+	cur, err := r.GetPOD(ctx, tripID)
+	switch {
+	case err != nil:
+		return nil, err
+	case cur == nil:
+		return nil, ErrPODNotFound
+	case cur.Status == PODDisputed:
+		return nil, ErrPODAlreadyDisputed
+	}
+	return nil, ErrPODDisputeClosed
+}
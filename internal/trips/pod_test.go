@@ -0,0 +1,21 @@
+package trips
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPODCanDispute(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	p := &POD{Status: PODSubmitted, DisputeUntil: now.Add(time.Hour)}
+	if !p.CanDispute(now) {
+		t.Error("submitted POD inside the window must be disputable")
+	}
+	if p.CanDispute(now.Add(time.Hour)) {
+		t.Error("window end is exclusive")
+	}
+	p.Status = PODDisputed
+	if p.CanDispute(now) {
+		t.Error("POD can be disputed only once")
+	}
+}
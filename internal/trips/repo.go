@@ -31,10 +31,18 @@ func NewRepo(pg *pgxpool.Pool) *Repo {
 }
 
 // Create creates trip with status pending_driver (after offer accepted).
+// The agreed price is the offer's latest (accepted) proposal.
 func (r *Repo) Create(ctx context.Context, cargoID, offerID uuid.UUID) (uuid.UUID, error) {
 	var id uuid.UUID
-	err := r.pg.QueryRow(ctx,
-		`INSERT INTO trips (cargo_id, offer_id, status) VALUES ($1, $2, $3) RETURNING id`,
+	err := r.pg.QueryRow(ctx, `
+WITH t AS (
+  INSERT INTO trips (cargo_id, offer_id, status, agreed_price, agreed_currency)
+  SELECT $1, $2, $3, o.price, o.currency FROM offers o WHERE o.id = $2
+  RETURNING id
+), h AS (
+  INSERT INTO trip_status_history (trip_id, to_status) SELECT id, $3 FROM t
+)
+SELECT id FROM t`,
 		cargoID, offerID, StatusPendingDriver).Scan(&id)
 	return id, err
 }
@@ -43,8 +51,8 @@ func (r *Repo) Create(ctx context.Context, cargoID, offerID uuid.UUID) (uuid.UUI
 func (r *Repo) GetByID(ctx context.Context, id uuid.UUID) (*Trip, error) {
 	var t Trip
 	err := r.pg.QueryRow(ctx,
-		`SELECT id, cargo_id, offer_id, driver_id, status, created_at, updated_at FROM trips WHERE id = $1`,
-		id).Scan(&t.ID, &t.CargoID, &t.OfferID, &t.DriverID, &t.Status, &t.CreatedAt, &t.UpdatedAt)
+		`SELECT id, cargo_id, offer_id, driver_id, status, agreed_price, agreed_currency, parent_trip_id, created_at, updated_at FROM trips WHERE id = $1`,
+		id).Scan(&t.ID, &t.CargoID, &t.OfferID, &t.DriverID, &t.Status, &t.AgreedPrice, &t.AgreedCurrency, &t.ParentTripID, &t.CreatedAt, &t.UpdatedAt)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
@@ -58,8 +66,8 @@ func (r *Repo) GetByID(ctx context.Context, id uuid.UUID) (*Trip, error) {
 func (r *Repo) GetByOfferID(ctx context.Context, offerID uuid.UUID) (*Trip, error) {
 	var t Trip
 	err := r.pg.QueryRow(ctx,
-		`SELECT id, cargo_id, offer_id, driver_id, status, created_at, updated_at FROM trips WHERE offer_id = $1`,
-		offerID).Scan(&t.ID, &t.CargoID, &t.OfferID, &t.DriverID, &t.Status, &t.CreatedAt, &t.UpdatedAt)
+		`SELECT id, cargo_id, offer_id, driver_id, status, agreed_price, agreed_currency, parent_trip_id, created_at, updated_at FROM trips WHERE offer_id = $1`,
+		offerID).Scan(&t.ID, &t.CargoID, &t.OfferID, &t.DriverID, &t.Status, &t.AgreedPrice, &t.AgreedCurrency, &t.ParentTripID, &t.CreatedAt, &t.UpdatedAt)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
@@ -73,8 +81,8 @@ func (r *Repo) GetByOfferID(ctx context.Context, offerID uuid.UUID) (*Trip, erro
 func (r *Repo) GetByCargoID(ctx context.Context, cargoID uuid.UUID) (*Trip, error) {
 	var t Trip
 	err := r.pg.QueryRow(ctx,
-		`SELECT id, cargo_id, offer_id, driver_id, status, created_at, updated_at FROM trips WHERE cargo_id = $1 ORDER BY created_at DESC LIMIT 1`,
-		cargoID).Scan(&t.ID, &t.CargoID, &t.OfferID, &t.DriverID, &t.Status, &t.CreatedAt, &t.UpdatedAt)
+		`SELECT id, cargo_id, offer_id, driver_id, status, agreed_price, agreed_currency, parent_trip_id, created_at, updated_at FROM trips WHERE cargo_id = $1 ORDER BY created_at DESC LIMIT 1`,
+		cargoID).Scan(&t.ID, &t.CargoID, &t.OfferID, &t.DriverID, &t.Status, &t.AgreedPrice, &t.AgreedCurrency, &t.ParentTripID, &t.CreatedAt, &t.UpdatedAt)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
@@ -101,8 +109,11 @@ func (r *Repo) AssignDriver(ctx context.Context, tripID, driverID uuid.UUID) err
 // DriverConfirm sets status to assigned (driver accepted the assignment). Trip must have driver_id = caller and stay pending_driver until driver confirms.
 // So actually: when dispatcher assigns driver, we only set driver_id. Driver then "confirms" and we set status = assigned.
 func (r *Repo) DriverConfirm(ctx context.Context, tripID, driverID uuid.UUID) error {
-	res, err := r.pg.Exec(ctx,
-		`UPDATE trips SET status = $2, updated_at = now() WHERE id = $1 AND driver_id = $3 AND status = $4`,
+	res, err := r.pg.Exec(ctx, `
+WITH t AS (
+  UPDATE trips SET status = $2, updated_at = now() WHERE id = $1 AND driver_id = $3 AND status = $4 RETURNING id
+)
+INSERT INTO trip_status_history (trip_id, from_status, to_status) SELECT id, $4, $2 FROM t`,
 		tripID, StatusAssigned, driverID, StatusPendingDriver)
 	if err != nil {
 		return err
@@ -127,8 +138,13 @@ func (r *Repo) DriverReject(ctx context.Context, tripID, driverID uuid.UUID) err
 	return nil
 }
 
-// SetStatus updates trip status (driver: loading -> en_route -> unloading -> completed).
+// SetStatus updates trip status (driver: loading -> en_route -> unloading).
+// COMPLETED ставится только через SubmitPOD (подтверждение доставки); при переходе в EN_ROUTE в той же транзакции
+// выдаётся PIN получателя — рейс в пути без PIN не остаётся.
 func (r *Repo) SetStatus(ctx context.Context, tripID uuid.UUID, newStatus string) error {
+	if newStatus == StatusCompleted {
+		return ErrPODRequired
+	}
 	t, err := r.GetByID(ctx, tripID)
 	if err != nil || t == nil {
 		return ErrNotFound
@@ -136,8 +152,30 @@ func (r *Repo) SetStatus(ctx context.Context, tripID uuid.UUID, newStatus string
 	allowed := allowedTransitions[t.Status]
 	for _, s := range allowed {
 		if s == newStatus {
-			_, err = r.pg.Exec(ctx, `UPDATE trips SET status = $1, updated_at = now() WHERE id = $2`, newStatus, tripID)
-			return err
+			tx, err := r.pg.Begin(ctx)
+			if err != nil {
+				return err
+			}
+			defer tx.Rollback(ctx)
+			tag, err := tx.Exec(ctx, `
+WITH t AS (
+  UPDATE trips SET status = $1, updated_at = now() WHERE id = $2 AND status = $3 RETURNING id
+)
+INSERT INTO trip_status_history (trip_id, from_status, to_status) SELECT id, $3, $1 FROM t`,
+				newStatus, tripID, t.Status)
+			if err != nil {
+				return err
+			}
+			if tag.RowsAffected() == 0 {
+				// статус успели сменить параллельно (отмена, другой запрос водителя)
+				return ErrInvalidTransition
+			}
+			if newStatus == StatusEnRoute {
+				if err := issueDeliveryPIN(ctx, tx, tripID); err != nil {
+					return err
+				}
+			}
+			return tx.Commit(ctx)
 		}
 	}
 	return ErrInvalidTransition
@@ -149,7 +187,7 @@ func (r *Repo) ListByDriver(ctx context.Context, driverID uuid.UUID, limit int)
 		limit = 50
 	}
 	rows, err := r.pg.Query(ctx,
-		`SELECT id, cargo_id, offer_id, driver_id, status, created_at, updated_at FROM trips WHERE driver_id = $1 ORDER BY created_at DESC LIMIT $2`,
+		`SELECT id, cargo_id, offer_id, driver_id, status, agreed_price, agreed_currency, parent_trip_id, created_at, updated_at FROM trips WHERE driver_id = $1 ORDER BY created_at DESC LIMIT $2`,
 		driverID, limit)
 	if err != nil {
 		return nil, err
@@ -158,7 +196,7 @@ func (r *Repo) ListByDriver(ctx context.Context, driverID uuid.UUID, limit int)
 	var list []Trip
 	for rows.Next() {
 		var t Trip
-		err := rows.Scan(&t.ID, &t.CargoID, &t.OfferID, &t.DriverID, &t.Status, &t.CreatedAt, &t.UpdatedAt)
+		err := rows.Scan(&t.ID, &t.CargoID, &t.OfferID, &t.DriverID, &t.Status, &t.AgreedPrice, &t.AgreedCurrency, &t.ParentTripID, &t.CreatedAt, &t.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -173,7 +211,7 @@ func (r *Repo) ListByCargoIDs(ctx context.Context, cargoIDs []uuid.UUID) ([]Trip
 		return nil, nil
 	}
 	rows, err := r.pg.Query(ctx,
-		`SELECT id, cargo_id, offer_id, driver_id, status, created_at, updated_at FROM trips WHERE cargo_id = ANY($1) ORDER BY created_at DESC`,
+		`SELECT id, cargo_id, offer_id, driver_id, status, agreed_price, agreed_currency, parent_trip_id, created_at, updated_at FROM trips WHERE cargo_id = ANY($1) ORDER BY created_at DESC`,
 		cargoIDs)
 	if err != nil {
 		return nil, err
@@ -182,7 +220,7 @@ func (r *Repo) ListByCargoIDs(ctx context.Context, cargoIDs []uuid.UUID) ([]Trip
 	var list []Trip
 	for rows.Next() {
 		var t Trip
-		err := rows.Scan(&t.ID, &t.CargoID, &t.OfferID, &t.DriverID, &t.Status, &t.CreatedAt, &t.UpdatedAt)
+		err := rows.Scan(&t.ID, &t.CargoID, &t.OfferID, &t.DriverID, &t.Status, &t.AgreedPrice, &t.AgreedCurrency, &t.ParentTripID, &t.CreatedAt, &t.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
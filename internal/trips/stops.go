@@ -0,0 +1,258 @@
+package trips
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrStopAlreadyArrived = errors.New("trip stop already arrived")
+	ErrStopNotArrived     = errors.New("trip stop not arrived or already departed")
+	ErrStopOutOfOrder     = errors.New("trip stop out of route order")
+	ErrStopNotDeparted    = errors.New("previous trip stop not departed")
+	ErrStopUnknownPoint   = errors.New("route point does not belong to the trip")
+)
+
+// StopPoint — точка маршрута груза в порядке point_order (из cargo.RoutePoint).
+type StopPoint struct {
+	ID           uuid.UUID
+	Type         string // LOAD, UNLOAD, CUSTOMS, TRANSIT
+	IsMainLoad   bool
+	IsMainUnload bool
+}
+
+// Stop — прибытие водителя на точку маршрута и отъезд с неё (trip_stops).
+type Stop struct {
+	ID           uuid.UUID
+	TripID       uuid.UUID
+	RoutePointID uuid.UUID
+	ArrivedAt    time.Time
+	ArrivedLat   *float64
+	ArrivedLng   *float64
+	DepartedAt   *time.Time
+	DepartedLat  *float64
+	DepartedLng  *float64
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// Dwell — время на точке; пока водитель не уехал — до now.
+func (s *Stop) Dwell(now time.Time) time.Duration {
+	if s.DepartedAt != nil {
+		return s.DepartedAt.Sub(s.ArrivedAt)
+	}
+	return now.Sub(s.ArrivedAt)
+}
+
+// StopProgress — прохождение маршрута: Stops[i] — запись по Points[i] (nil — водитель ещё не прибыл).
+type StopProgress struct {
+	Points []StopPoint
+	Stops  []*Stop
+}
+
+// NewStopProgress сопоставляет записи trip_stops точкам маршрута.
+func NewStopProgress(points []StopPoint, stops []Stop) StopProgress {
+	byPoint := make(map[uuid.UUID]*Stop, len(stops))
+	for i := range stops {
+		byPoint[stops[i].RoutePointID] = &stops[i]
+	}
+	p := StopProgress{Points: points, Stops: make([]*Stop, len(points))}
+	for i, pt := range points {
+		p.Stops[i] = byPoint[pt.ID]
+	}
+	return p
+}
+
+// Current — индекс точки, на которой водитель сейчас (прибыл, не уехал), или -1.
+func (p StopProgress) Current() int {
+	for i, s := range p.Stops {
+		if s != nil && s.DepartedAt == nil {
+			return i
+		}
+	}
+	return -1
+}
+
+// Next — индекс следующей точки, на которую водитель ещё не прибыл, или -1 (прибыл на все).
+func (p StopProgress) Next() int {
+	for i, s := range p.Stops {
+		if s == nil {
+			return i
+		}
+	}
+	return -1
+}
+
+// Done — сколько точек пройдено (водитель уехал).
+func (p StopProgress) Done() int {
+	n := 0
+	for _, s := range p.Stops {
+		if s != nil && s.DepartedAt != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// Index — индекс точки маршрута по id, или -1.
+func (p StopProgress) Index(pointID uuid.UUID) int {
+	for i, pt := range p.Points {
+		if pt.ID == pointID {
+			return i
+		}
+	}
+	return -1
+}
+
+// CanArrive — прибыть можно только на следующую по порядку точку и только уехав с предыдущей.
+func (p StopProgress) CanArrive(pointID uuid.UUID) error {
+	i := p.Index(pointID)
+	switch {
+	case i < 0:
+		return ErrStopUnknownPoint
+	case p.Stops[i] != nil:
+		return ErrStopAlreadyArrived
+	case p.Current() >= 0:
+		return ErrStopNotDeparted
+	case p.Next() != i:
+		return ErrStopOutOfOrder
+	}
+	return nil
+}
+
+// CanDepart — уехать можно только с точки, на которой водитель сейчас.
+func (p StopProgress) CanDepart(pointID uuid.UUID) error {
+	i := p.Index(pointID)
+	if i < 0 {
+		return ErrStopUnknownPoint
+	}
+	if i != p.Current() {
+		return ErrStopNotArrived
+	}
+	return nil
+}
+
+// Remaining — точки, на которые водитель ещё не прибыл, и точка, на которой он сейчас.
+func (p StopProgress) Remaining() []int {
+	var out []int
+	for i, s := range p.Stops {
+		if s == nil || s.DepartedAt == nil {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// Status — статус рейса по прохождению точек: прибыл на первую погрузку — LOADING, уехал с последней погрузки — EN_ROUTE,
+// прибыл на конечную выгрузку — UNLOADING; иначе ASSIGNED. COMPLETED ставится только подтверждением доставки.
+func (p StopProgress) Status() string {
+	firstLoad, lastLoad, finalUnload := -1, -1, -1
+	for i, pt := range p.Points {
+		switch strings.ToUpper(pt.Type) {
+		case "LOAD":
+			if firstLoad < 0 {
+				firstLoad = i
+			}
+			lastLoad = i
+		case "UNLOAD":
+			finalUnload = i
+		}
+	}
+	if len(p.Points) == 0 {
+		return StatusAssigned
+	}
+	if firstLoad < 0 {
+		firstLoad, lastLoad = 0, 0
+	}
+	if finalUnload < 0 {
+		finalUnload = len(p.Points) - 1
+	}
+	switch {
+	case p.Stops[finalUnload] != nil && finalUnload > lastLoad:
+		return StatusUnloading
+	case p.Stops[lastLoad] != nil && p.Stops[lastLoad].DepartedAt != nil:
+		return StatusEnRoute
+	case p.Stops[firstLoad] != nil:
+		return StatusLoading
+	}
+	return StatusAssigned
+}
+
+// statusPath — основной поток рейса; статус по точкам только продвигается вперёд по нему.
+var statusPath = []string{StatusAssigned, StatusLoading, StatusEnRoute, StatusUnloading}
+
+// StatusSteps — статусы, через которые рейс переходит из current в target (пусто, если target не впереди).
+func StatusSteps(current, target string) []string {
+	from, to := -1, -1
+	for i, s := range statusPath {
+		if s == current {
+			from = i
+		}
+		if s == target {
+			to = i
+		}
+	}
+	if from < 0 || to <= from {
+		return nil
+	}
+	return statusPath[from+1 : to+1]
+}
+
+const stopColumns = `id, trip_id, route_point_id, arrived_at, arrived_lat, arrived_lng, departed_at, departed_lat, departed_lng, created_at, updated_at`
+
+func scanStop(row pgx.Row) (*Stop, error) {
+	var s Stop
+	err := row.Scan(&s.ID, &s.TripID, &s.RoutePointID, &s.ArrivedAt, &s.ArrivedLat, &s.ArrivedLng, &s.DepartedAt,
+		&s.DepartedLat, &s.DepartedLng, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Stops returns arrival/departure records of the trip.
+func (r *Repo) Stops(ctx context.Context, tripID uuid.UUID) ([]Stop, error) {
+	rows, err := r.pg.Query(ctx, `SELECT `+stopColumns+` FROM trip_stops WHERE trip_id = $1 ORDER BY arrived_at`, tripID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []Stop
+	for rows.Next() {
+		s, err := scanStop(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *s)
+	}
+	return list, rows.Err()
+}
+
+// ArriveStop отмечает прибытие на точку маршрута; ErrStopAlreadyArrived — прибытие уже отмечено.
+func (r *Repo) ArriveStop(ctx context.Context, tripID, routePointID uuid.UUID, lat, lng *float64) (*Stop, error) {
+	s, err := scanStop(r.pg.QueryRow(ctx, `
+INSERT INTO trip_stops (trip_id, route_point_id, arrived_lat, arrived_lng) VALUES ($1, $2, $3, $4)
+ON CONFLICT (trip_id, route_point_id) DO NOTHING
+RETURNING `+stopColumns, tripID, routePointID, lat, lng))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrStopAlreadyArrived
+	}
+	return s, err
+}
+
+// DepartStop отмечает отъезд с точки маршрута; ErrStopNotArrived — прибытия нет или отъезд уже отмечен.
+func (r *Repo) DepartStop(ctx context.Context, tripID, routePointID uuid.UUID, lat, lng *float64) (*Stop, error) {
+	s, err := scanStop(r.pg.QueryRow(ctx, `
+UPDATE trip_stops SET departed_at = now(), departed_lat = $3, departed_lng = $4, updated_at = now()
+WHERE trip_id = $1 AND route_point_id = $2 AND departed_at IS NULL
+RETURNING `+stopColumns, tripID, routePointID, lat, lng))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrStopNotArrived
+	}
+	return s, err
+}
@@ -0,0 +1,88 @@
+package trips
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func multiDropRoute() []StopPoint {
+	return []StopPoint{
+		{ID: uuid.New(), Type: "LOAD", IsMainLoad: true},
+		{ID: uuid.New(), Type: "CUSTOMS"},
+		{ID: uuid.New(), Type: "UNLOAD"},
+		{ID: uuid.New(), Type: "UNLOAD", IsMainUnload: true},
+	}
+}
+
+func TestStopProgressOrder(t *testing.T) {
+	points := multiDropRoute()
+	now := time.Now()
+	p := NewStopProgress(points, nil)
+	if err := p.CanArrive(points[1].ID); err != ErrStopOutOfOrder {
+		t.Errorf("skip first stop: %v", err)
+	}
+	if err := p.CanArrive(points[0].ID); err != nil {
+		t.Errorf("first stop: %v", err)
+	}
+	if err := p.CanDepart(points[0].ID); err != ErrStopNotArrived {
+		t.Errorf("depart before arrival: %v", err)
+	}
+	if err := p.CanArrive(uuid.New()); err != ErrStopUnknownPoint {
+		t.Errorf("unknown point: %v", err)
+	}
+
+	p = NewStopProgress(points, []Stop{{RoutePointID: points[0].ID, ArrivedAt: now}})
+	if err := p.CanArrive(points[1].ID); err != ErrStopNotDeparted {
+		t.Errorf("arrive without departure: %v", err)
+	}
+	if err := p.CanArrive(points[0].ID); err != ErrStopAlreadyArrived {
+		t.Errorf("arrive twice: %v", err)
+	}
+	if p.Current() != 0 || p.Next() != 1 || p.Done() != 0 {
+		t.Errorf("progress: current %d next %d done %d", p.Current(), p.Next(), p.Done())
+	}
+	if got := p.Remaining(); !reflect.DeepEqual(got, []int{0, 1, 2, 3}) {
+		t.Errorf("remaining: %v", got)
+	}
+}
+
+func TestStopProgressStatus(t *testing.T) {
+	points := multiDropRoute()
+	arrived := time.Now().Add(-2 * time.Hour)
+	departed := arrived.Add(90 * time.Minute)
+	done := func(i int) Stop { return Stop{RoutePointID: points[i].ID, ArrivedAt: arrived, DepartedAt: &departed} }
+	at := func(i int) Stop { return Stop{RoutePointID: points[i].ID, ArrivedAt: arrived} }
+	cases := []struct {
+		stops []Stop
+		want  string
+	}{
+		{nil, StatusAssigned},
+		{[]Stop{at(0)}, StatusLoading},
+		{[]Stop{done(0)}, StatusEnRoute},
+		{[]Stop{done(0), done(1), at(2)}, StatusEnRoute},
+		{[]Stop{done(0), done(1), done(2), at(3)}, StatusUnloading},
+	}
+	for i, c := range cases {
+		if got := NewStopProgress(points, c.stops).Status(); got != c.want {
+			t.Errorf("case %d: got %s, want %s", i, got, c.want)
+		}
+	}
+	if s := done(0); s.Dwell(time.Now()) != 90*time.Minute {
+		t.Errorf("dwell: %v", s.Dwell(time.Now()))
+	}
+}
+
+func TestStatusSteps(t *testing.T) {
+	if got := StatusSteps(StatusAssigned, StatusEnRoute); !reflect.DeepEqual(got, []string{StatusLoading, StatusEnRoute}) {
+		t.Errorf("assigned -> en_route: %v", got)
+	}
+	if got := StatusSteps(StatusUnloading, StatusEnRoute); got != nil {
+		t.Errorf("backwards: %v", got)
+	}
+	if got := StatusSteps(StatusCompleted, StatusUnloading); got != nil {
+		t.Errorf("completed: %v", got)
+	}
+}
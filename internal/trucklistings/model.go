@@ -0,0 +1,62 @@
+package trucklistings
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Кто разместил объявление: сам водитель или его диспетчер (freelancer_id).
+const (
+	PostedByDriver     = "DRIVER"
+	PostedByDispatcher = "DISPATCHER"
+)
+
+// Статусы объявления: ACTIVE — видно в поиске, BOOKED — принято предложение груза, CLOSED — снято.
+const (
+	StatusActive = "ACTIVE"
+	StatusBooked = "BOOKED"
+	StatusClosed = "CLOSED"
+)
+
+// Destination — желаемое направление (город из справочника cities).
+type Destination struct {
+	CityCode string
+	Lat      float64
+	Lng      float64
+}
+
+// Listing — свободная машина водителя (table truck_listings).
+type Listing struct {
+	ID             uuid.UUID
+	DriverID       uuid.UUID
+	PostedByType   string
+	PostedByID     uuid.UUID
+	TruckType      string
+	CapacityWeight *float64 // т
+	CapacityVolume *float64 // м³
+	FromCityCode   *string
+	FromLat        float64
+	FromLng        float64
+	AvailableFrom  time.Time
+	AvailableTo    *time.Time // nil — без срока
+	Destinations   []Destination
+	Comment        *string
+	Status         string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+
+	// Из drivers — для выдачи в поиске.
+	DriverName   *string
+	DriverRating *float64
+}
+
+// Expired — срок доступности истёк (available_to в прошлом).
+func (l *Listing) Expired(now time.Time) bool {
+	return l.AvailableTo != nil && l.AvailableTo.Before(now)
+}
+
+// Searchable — объявление активно и не истекло.
+func (l *Listing) Searchable(now time.Time) bool {
+	return l.Status == StatusActive && !l.Expired(now)
+}
@@ -0,0 +1,239 @@
+package trucklistings
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"sarbonNew/internal/routing"
+)
+
+// maxSearchCandidates — сколько объявлений максимум отбирается в SQL до точной проверки радиуса.
+const maxSearchCandidates = 1000
+
+var ErrNotActive = errors.New("truck listing not found or not active")
+
+type Repo struct {
+	pg *pgxpool.Pool
+}
+
+func NewRepo(pg *pgxpool.Pool) *Repo {
+	return &Repo{pg: pg}
+}
+
+const listingColumns = `l.id, l.driver_id, l.posted_by_type, l.posted_by_id, l.truck_type, l.capacity_weight, l.capacity_volume,
+  l.from_city_code, l.from_lat, l.from_lng, l.available_from, l.available_to, l.comment, l.status, l.created_at, l.updated_at,
+  d.name, d.rating`
+
+const listingFrom = ` FROM truck_listings l JOIN drivers d ON d.id = l.driver_id`
+
+func scanListing(row pgx.Row) (*Listing, error) {
+	var l Listing
+	err := row.Scan(&l.ID, &l.DriverID, &l.PostedByType, &l.PostedByID, &l.TruckType, &l.CapacityWeight, &l.CapacityVolume,
+		&l.FromCityCode, &l.FromLat, &l.FromLng, &l.AvailableFrom, &l.AvailableTo, &l.Comment, &l.Status, &l.CreatedAt, &l.UpdatedAt,
+		&l.DriverName, &l.DriverRating)
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (r *Repo) query(ctx context.Context, q string, args ...any) ([]Listing, error) {
+	rows, err := r.pg.Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	var list []Listing
+	for rows.Next() {
+		l, err := scanListing(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		list = append(list, *l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return list, r.loadDestinations(ctx, list)
+}
+
+func (r *Repo) loadDestinations(ctx context.Context, list []Listing) error {
+	if len(list) == 0 {
+		return nil
+	}
+	idx := make(map[uuid.UUID]int, len(list))
+	ids := make([]uuid.UUID, 0, len(list))
+	for i := range list {
+		idx[list[i].ID] = i
+		ids = append(ids, list[i].ID)
+		list[i].Destinations = []Destination{}
+	}
+	rows, err := r.pg.Query(ctx, `
+SELECT listing_id, city_code, lat, lng FROM truck_listing_destinations WHERE listing_id = ANY($1) ORDER BY listing_id, position`, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id uuid.UUID
+		var d Destination
+		if err := rows.Scan(&id, &d.CityCode, &d.Lat, &d.Lng); err != nil {
+			return err
+		}
+		list[idx[id]].Destinations = append(list[idx[id]].Destinations, d)
+	}
+	return rows.Err()
+}
+
+// Create saves a listing (статус ACTIVE) with destinations.
+func (r *Repo) Create(ctx context.Context, l Listing) (uuid.UUID, error) {
+	tx, err := r.pg.Begin(ctx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	defer tx.Rollback(ctx)
+	var id uuid.UUID
+	err = tx.QueryRow(ctx, `
+INSERT INTO truck_listings (driver_id, posted_by_type, posted_by_id, truck_type, capacity_weight, capacity_volume,
+  from_city_code, from_lat, from_lng, available_from, available_to, comment)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id`,
+		l.DriverID, l.PostedByType, l.PostedByID, l.TruckType, l.CapacityWeight, l.CapacityVolume,
+		l.FromCityCode, l.FromLat, l.FromLng, l.AvailableFrom, l.AvailableTo, l.Comment).Scan(&id)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if err := insertDestinations(ctx, tx, id, l.Destinations); err != nil {
+		return uuid.Nil, err
+	}
+	return id, tx.Commit(ctx)
+}
+
+// Update заменяет машину, место, даты и направления активного объявления. ErrNotActive — объявления нет или оно не ACTIVE.
+func (r *Repo) Update(ctx context.Context, l Listing) error {
+	tx, err := r.pg.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	tag, err := tx.Exec(ctx, `
+UPDATE truck_listings SET truck_type = $2, capacity_weight = $3, capacity_volume = $4, from_city_code = $5, from_lat = $6,
+  from_lng = $7, available_from = $8, available_to = $9, comment = $10, updated_at = now()
+WHERE id = $1 AND status = 'ACTIVE'`,
+		l.ID, l.TruckType, l.CapacityWeight, l.CapacityVolume, l.FromCityCode, l.FromLat, l.FromLng,
+		l.AvailableFrom, l.AvailableTo, l.Comment)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotActive
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM truck_listing_destinations WHERE listing_id = $1`, l.ID); err != nil {
+		return err
+	}
+	if err := insertDestinations(ctx, tx, l.ID, l.Destinations); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func insertDestinations(ctx context.Context, tx pgx.Tx, listingID uuid.UUID, list []Destination) error {
+	for i, d := range list {
+		if _, err := tx.Exec(ctx, `
+INSERT INTO truck_listing_destinations (listing_id, position, city_code, lat, lng) VALUES ($1, $2, $3, $4, $5)`,
+			listingID, i+1, d.CityCode, d.Lat, d.Lng); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close снимает активное объявление. ErrNotActive — объявления нет или оно не ACTIVE.
+func (r *Repo) Close(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.pg.Exec(ctx, `UPDATE truck_listings SET status = 'CLOSED', updated_at = now() WHERE id = $1 AND status = 'ACTIVE'`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotActive
+	}
+	return nil
+}
+
+// Get returns a listing by id (nil — не найдено).
+func (r *Repo) Get(ctx context.Context, id uuid.UUID) (*Listing, error) {
+	list, err := r.query(ctx, `SELECT `+listingColumns+listingFrom+` WHERE l.id = $1`, id)
+	if err != nil || len(list) == 0 {
+		return nil, err
+	}
+	return &list[0], nil
+}
+
+// ListByDriver returns listings of the driver, новые первыми.
+func (r *Repo) ListByDriver(ctx context.Context, driverID uuid.UUID, limit int) ([]Listing, error) {
+	return r.query(ctx, `SELECT `+listingColumns+listingFrom+` WHERE l.driver_id = $1 ORDER BY l.created_at DESC LIMIT $2`, driverID, limit)
+}
+
+// ListByDispatcher returns listings of drivers working with the dispatcher (freelancer_id) and listings posted by him.
+func (r *Repo) ListByDispatcher(ctx context.Context, dispatcherID uuid.UUID, limit int) ([]Listing, error) {
+	return r.query(ctx, `SELECT `+listingColumns+listingFrom+`
+WHERE d.freelancer_id = $1 OR (l.posted_by_type = 'DISPATCHER' AND l.posted_by_id = $1)
+ORDER BY l.created_at DESC LIMIT $2`, dispatcherID, limit)
+}
+
+// Search отбирает активные неистёкшие объявления по типу кузова, дате, вместимости и прямоугольникам вокруг точек
+// фильтра; точный радиус, сортировка и страница — Filter. Возвращает страницу и общее число подходящих.
+func (r *Repo) Search(ctx context.Context, f SearchFilter, now time.Time) ([]Match, int, error) {
+	q := `SELECT ` + listingColumns + listingFrom + `
+WHERE l.status = 'ACTIVE' AND (l.available_to IS NULL OR l.available_to >= $1)`
+	args := []any{now}
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+	if f.TruckType != "" {
+		q += ` AND l.truck_type = ` + arg(f.TruckType)
+	}
+	if f.Date != nil {
+		day := time.Date(f.Date.Year(), f.Date.Month(), f.Date.Day(), 0, 0, 0, 0, time.UTC)
+		q += ` AND l.available_from < ` + arg(day.AddDate(0, 0, 1)) + ` AND (l.available_to IS NULL OR l.available_to >= ` + arg(day) + `)`
+	}
+	if f.MinWeight != nil {
+		q += ` AND (l.capacity_weight IS NULL OR l.capacity_weight >= ` + arg(*f.MinWeight) + `)`
+	}
+	if f.MinVolume != nil {
+		q += ` AND (l.capacity_volume IS NULL OR l.capacity_volume >= ` + arg(*f.MinVolume) + `)`
+	}
+	if f.From != nil {
+		b := routing.BoundingBox(*f.From, f.FromRadiusKm)
+		q += ` AND l.from_lat BETWEEN ` + arg(b.MinLat) + ` AND ` + arg(b.MaxLat) +
+			` AND l.from_lng BETWEEN ` + arg(b.MinLng) + ` AND ` + arg(b.MaxLng)
+	}
+	if f.To != nil {
+		b := routing.BoundingBox(*f.To, f.ToRadiusKm)
+		q += ` AND (NOT EXISTS (SELECT 1 FROM truck_listing_destinations x WHERE x.listing_id = l.id)
+  OR EXISTS (SELECT 1 FROM truck_listing_destinations x WHERE x.listing_id = l.id
+    AND x.lat BETWEEN ` + arg(b.MinLat) + ` AND ` + arg(b.MaxLat) + ` AND x.lng BETWEEN ` + arg(b.MinLng) + ` AND ` + arg(b.MaxLng) + `))`
+	}
+	q += ` ORDER BY l.available_from LIMIT ` + arg(maxSearchCandidates)
+	list, err := r.query(ctx, q, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	matches := Filter(list, f)
+	total := len(matches)
+	if f.Offset >= total {
+		return []Match{}, total, nil
+	}
+	end := total
+	if f.Limit > 0 && f.Offset+f.Limit < total {
+		end = f.Offset + f.Limit
+	}
+	return matches[f.Offset:end], total, nil
+}
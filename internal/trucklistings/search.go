@@ -0,0 +1,69 @@
+package trucklistings
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"sarbonNew/internal/routing"
+)
+
+// SearchFilter — поиск свободных машин грузоотправителем.
+type SearchFilter struct {
+	From         *routing.Point // где нужна машина (точка погрузки)
+	FromRadiusKm float64
+	To           *routing.Point // куда везти; объявления без направлений подходят к любому
+	ToRadiusKm   float64
+	TruckType    string
+	Date         *time.Time // машина свободна в этот день (UTC)
+	MinWeight    *float64   // вес груза, т — объявления с известной меньшей грузоподъёмностью отсеиваются
+	MinVolume    *float64
+	Limit        int
+	Offset       int
+}
+
+// Match — объявление в выдаче с расстояниями до точки погрузки и ближайшего желаемого направления.
+type Match struct {
+	Listing
+	FromDistanceKm *float64
+	ToDistanceKm   *float64
+}
+
+// Filter оставляет объявления в радиусах фильтра и сортирует: ближе к точке погрузки, затем раньше освобождается.
+func Filter(list []Listing, f SearchFilter) []Match {
+	out := make([]Match, 0, len(list))
+	for _, l := range list {
+		m := Match{Listing: l}
+		if f.From != nil {
+			d := round1(routing.HaversineKm(*f.From, routing.Point{Lat: l.FromLat, Lng: l.FromLng}))
+			if d > f.FromRadiusKm {
+				continue
+			}
+			m.FromDistanceKm = &d
+		}
+		if f.To != nil && len(l.Destinations) > 0 {
+			best := math.Inf(1)
+			for _, dst := range l.Destinations {
+				best = math.Min(best, routing.HaversineKm(*f.To, routing.Point{Lat: dst.Lat, Lng: dst.Lng}))
+			}
+			if best > f.ToRadiusKm {
+				continue
+			}
+			d := round1(best)
+			m.ToDistanceKm = &d
+		}
+		out = append(out, m)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.FromDistanceKm != nil && b.FromDistanceKm != nil && *a.FromDistanceKm != *b.FromDistanceKm {
+			return *a.FromDistanceKm < *b.FromDistanceKm
+		}
+		return a.AvailableFrom.Before(b.AvailableFrom)
+	})
+	return out
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
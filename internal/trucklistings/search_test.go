@@ -0,0 +1,50 @@
+package trucklistings
+
+import (
+	"testing"
+	"time"
+
+	"sarbonNew/internal/routing"
+)
+
+func TestFilter(t *testing.T) {
+	now := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	near := Listing{FromLat: 41.3, FromLng: 69.3, AvailableFrom: now.Add(48 * time.Hour)}     // Ташкент
+	nearer := Listing{FromLat: 41.31, FromLng: 69.28, AvailableFrom: now.Add(72 * time.Hour), // Ташкент, центр
+		Destinations: []Destination{{CityCode: "SAM", Lat: 39.65, Lng: 66.96}}}
+	far := Listing{FromLat: 39.65, FromLng: 66.96, AvailableFrom: now} // Самарканд
+	toAlmaty := Listing{FromLat: 41.3, FromLng: 69.25, AvailableFrom: now, Destinations: []Destination{{CityCode: "ALA", Lat: 43.24, Lng: 76.89}}}
+
+	from := routing.Point{Lat: 41.31, Lng: 69.28}
+	got := Filter([]Listing{near, nearer, far}, SearchFilter{From: &from, FromRadiusKm: 50})
+	if len(got) != 2 || got[0].FromLat != nearer.FromLat || *got[0].FromDistanceKm != 0 {
+		t.Fatalf("from radius: %+v", got)
+	}
+
+	to := routing.Point{Lat: 39.7, Lng: 67.0}
+	got = Filter([]Listing{near, nearer, toAlmaty}, SearchFilter{To: &to, ToRadiusKm: 30})
+	if len(got) != 2 {
+		t.Fatalf("to radius: %+v", got)
+	}
+	// без точки погрузки — по дате освобождения; объявление без направлений подходит к любому
+	if got[0].FromLat != near.FromLat || got[0].ToDistanceKm != nil || got[1].ToDistanceKm == nil {
+		t.Errorf("order/destinations: %+v", got)
+	}
+}
+
+func TestListingSearchable(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	l := Listing{Status: StatusActive}
+	if !l.Searchable(now) {
+		t.Error("active without end date")
+	}
+	l.AvailableTo = &past
+	if l.Searchable(now) || !l.Expired(now) {
+		t.Error("expired")
+	}
+	l.AvailableTo, l.Status = nil, StatusBooked
+	if l.Searchable(now) {
+		t.Error("booked")
+	}
+}
@@ -0,0 +1,6 @@
+ALTER TABLE companies DROP COLUMN IF EXISTS rating_count;
+ALTER TABLE deleted_freelance_dispatchers DROP COLUMN IF EXISTS rating_count;
+ALTER TABLE freelance_dispatchers DROP COLUMN IF EXISTS rating_count;
+ALTER TABLE deleted_drivers DROP COLUMN IF EXISTS rating_count;
+ALTER TABLE drivers DROP COLUMN IF EXISTS rating_count;
+DROP TABLE IF EXISTS trip_reviews;
@@ -0,0 +1,35 @@
+-- Post-trip reviews: after trip COMPLETED both sides rate each other (score 1..5, tags, optional comment).
+-- Aggregated rating is recomputed into drivers.rating, freelance_dispatchers.rating, companies.rating.
+-- Admin can hide abusive reviews (status HIDDEN + moderation_reason); hidden reviews are excluded from rating.
+
+CREATE TABLE IF NOT EXISTS trip_reviews (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  trip_id UUID NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+  author_type VARCHAR(20) NOT NULL,
+  author_id UUID NOT NULL,
+  target_type VARCHAR(20) NOT NULL,
+  target_id UUID NOT NULL,
+  score SMALLINT NOT NULL,
+  tags TEXT[] NULL,
+  comment TEXT NULL,
+  status VARCHAR(20) NOT NULL DEFAULT 'PUBLISHED',
+  moderation_reason TEXT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP NOT NULL DEFAULT now(),
+  CONSTRAINT trip_reviews_score_check CHECK (score BETWEEN 1 AND 5),
+  CONSTRAINT trip_reviews_author_type_check CHECK (author_type IN ('DRIVER', 'DISPATCHER', 'COMPANY')),
+  CONSTRAINT trip_reviews_target_type_check CHECK (target_type IN ('DRIVER', 'DISPATCHER', 'COMPANY')),
+  CONSTRAINT trip_reviews_status_check CHECK (status IN ('PUBLISHED', 'HIDDEN')),
+  UNIQUE (trip_id, author_type, target_type)
+);
+
+CREATE INDEX IF NOT EXISTS idx_trip_reviews_trip ON trip_reviews (trip_id);
+CREATE INDEX IF NOT EXISTS idx_trip_reviews_target ON trip_reviews (target_type, target_id, status);
+CREATE INDEX IF NOT EXISTS idx_trip_reviews_status ON trip_reviews (status);
+
+-- Number of published reviews behind the aggregated rating (deleted_* mirror columns for SELECT * archive).
+ALTER TABLE drivers ADD COLUMN IF NOT EXISTS rating_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE deleted_drivers ADD COLUMN IF NOT EXISTS rating_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE freelance_dispatchers ADD COLUMN IF NOT EXISTS rating_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE deleted_freelance_dispatchers ADD COLUMN IF NOT EXISTS rating_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS rating_count INTEGER NOT NULL DEFAULT 0;
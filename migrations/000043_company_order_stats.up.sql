@@ -0,0 +1,21 @@
+-- Company performance counters: per-company ledger of finished orders (one row per cargo and participating company).
+-- Rows are written on trip COMPLETED / CANCELLED and cargo CANCELLED; companies.completed_orders, cancelled_orders
+-- and total_revenue are recomputed from this table. Backfill: go run ./cmd/companystats.
+
+CREATE TABLE IF NOT EXISTS company_order_stats (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  company_id UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+  cargo_id UUID NOT NULL REFERENCES cargo(id) ON DELETE CASCADE,
+  trip_id UUID NULL REFERENCES trips(id) ON DELETE SET NULL,
+  role VARCHAR(20) NOT NULL,
+  outcome VARCHAR(20) NOT NULL,
+  amount NUMERIC(18,2) NOT NULL DEFAULT 0,
+  currency VARCHAR(10) NOT NULL,
+  occurred_at TIMESTAMP NOT NULL DEFAULT now(),
+  CONSTRAINT company_order_stats_role_check CHECK (role IN ('OWNER', 'CARRIER')),
+  CONSTRAINT company_order_stats_outcome_check CHECK (outcome IN ('COMPLETED', 'CANCELLED')),
+  CONSTRAINT company_order_stats_company_cargo_unique UNIQUE (company_id, cargo_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_company_order_stats_company_occurred ON company_order_stats (company_id, occurred_at);
+CREATE INDEX IF NOT EXISTS idx_company_order_stats_cargo ON company_order_stats (cargo_id);
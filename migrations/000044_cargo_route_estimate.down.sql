@@ -0,0 +1,2 @@
+ALTER TABLE cargo DROP COLUMN IF EXISTS duration_minutes;
+ALTER TABLE cargo DROP COLUMN IF EXISTS distance_km;
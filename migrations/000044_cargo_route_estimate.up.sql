@@ -0,0 +1,5 @@
+-- Route estimate per cargo: road distance (km) and estimated duration (minutes), computed by internal/routing
+-- from route_points on create/update (great-circle × road factor, truck speed, customs delays, daily rest).
+
+ALTER TABLE cargo ADD COLUMN IF NOT EXISTS distance_km NUMERIC(10,1) NULL;
+ALTER TABLE cargo ADD COLUMN IF NOT EXISTS duration_minutes INTEGER NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE company_order_stats DROP COLUMN IF EXISTS amount_base;
+DROP FUNCTION IF EXISTS currency_rate_to_base(TEXT, DATE);
+DROP TABLE IF EXISTS currency_rates;
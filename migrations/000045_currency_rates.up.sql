@@ -0,0 +1,36 @@
+-- Daily currency exchange rates. rate = units of the base currency (USD) for 1 unit of currency.
+-- Admin-editable and importable from CSV; currency_rate_to_base() is used for normalised reporting and price suggestions.
+
+CREATE TABLE IF NOT EXISTS currency_rates (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  currency VARCHAR(10) NOT NULL,
+  rate_date DATE NOT NULL,
+  rate NUMERIC(20,10) NOT NULL,
+  source VARCHAR(20) NOT NULL DEFAULT 'MANUAL',
+  updated_by UUID NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP NOT NULL DEFAULT now(),
+  CONSTRAINT currency_rates_rate_check CHECK (rate > 0),
+  CONSTRAINT currency_rates_source_check CHECK (source IN ('MANUAL', 'IMPORT')),
+  CONSTRAINT currency_rates_currency_date_unique UNIQUE (currency, rate_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_currency_rates_currency_date ON currency_rates (currency, rate_date DESC);
+
+-- Rate to base on a date: the latest rate on or before the date, otherwise the earliest one after it.
+-- NULL when there are no rates for the currency at all.
+CREATE OR REPLACE FUNCTION currency_rate_to_base(cur TEXT, on_date DATE) RETURNS NUMERIC AS $$
+  SELECT CASE WHEN UPPER(cur) = 'USD' THEN 1::numeric ELSE (
+    SELECT cr.rate FROM currency_rates cr
+    WHERE cr.currency = UPPER(cur)
+    ORDER BY (cr.rate_date > on_date), ABS(cr.rate_date - on_date)
+    LIMIT 1
+  ) END
+$$ LANGUAGE sql STABLE;
+
+-- Company order stats: amount normalised to the base currency at the order date.
+ALTER TABLE company_order_stats ADD COLUMN IF NOT EXISTS amount_base NUMERIC(18,2) NULL;
+UPDATE company_order_stats SET amount_base = ROUND(amount * currency_rate_to_base(currency, occurred_at::date), 2);
+UPDATE companies co SET total_revenue = COALESCE((
+  SELECT SUM(s.amount_base) FROM company_order_stats s WHERE s.company_id = co.id AND s.outcome = 'COMPLETED'
+), 0);
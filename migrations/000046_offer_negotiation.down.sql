@@ -0,0 +1,8 @@
+ALTER TABLE trips DROP COLUMN IF EXISTS agreed_currency;
+ALTER TABLE trips DROP COLUMN IF EXISTS agreed_price;
+ALTER TABLE payments DROP COLUMN IF EXISTS max_counter_rounds;
+ALTER TABLE offers DROP CONSTRAINT IF EXISTS offers_last_round_by_check;
+ALTER TABLE offers DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE offers DROP COLUMN IF EXISTS last_round_by;
+ALTER TABLE offers DROP COLUMN IF EXISTS rounds_count;
+DROP TABLE IF EXISTS offer_rounds;
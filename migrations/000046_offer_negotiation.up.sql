@@ -0,0 +1,42 @@
+-- Counter-offer negotiation: each offer has a thread of price proposals (rounds).
+-- Round 1 is the driver's initial offer; then shipper (dispatcher/company) and driver counter in turns.
+-- offers.price/currency always hold the latest proposal; offers.last_round_by — whose proposal is on the table.
+-- payments.max_counter_rounds — optional limit of counter-proposals per offer (NULL = unlimited).
+-- The accepted price is copied into trips.agreed_price/agreed_currency.
+
+CREATE TABLE IF NOT EXISTS offer_rounds (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  offer_id UUID NOT NULL REFERENCES offers(id) ON DELETE CASCADE,
+  round_no INT NOT NULL,
+  author_type VARCHAR(20) NOT NULL,
+  author_id UUID NULL,
+  price DOUBLE PRECISION NOT NULL,
+  currency VARCHAR NOT NULL,
+  comment TEXT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  CONSTRAINT offer_rounds_author_type_check CHECK (author_type IN ('DRIVER', 'DISPATCHER', 'COMPANY', 'ADMIN')),
+  UNIQUE (offer_id, round_no)
+);
+
+CREATE INDEX IF NOT EXISTS idx_offer_rounds_offer ON offer_rounds (offer_id);
+
+ALTER TABLE offers ADD COLUMN IF NOT EXISTS rounds_count INT NOT NULL DEFAULT 1;
+ALTER TABLE offers ADD COLUMN IF NOT EXISTS last_round_by VARCHAR(20) NOT NULL DEFAULT 'DRIVER';
+ALTER TABLE offers ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP NULL;
+ALTER TABLE offers DROP CONSTRAINT IF EXISTS offers_last_round_by_check;
+ALTER TABLE offers ADD CONSTRAINT offers_last_round_by_check CHECK (last_round_by IN ('DRIVER', 'SHIPPER'));
+
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS max_counter_rounds INT NULL;
+
+ALTER TABLE trips ADD COLUMN IF NOT EXISTS agreed_price DOUBLE PRECISION NULL;
+ALTER TABLE trips ADD COLUMN IF NOT EXISTS agreed_currency VARCHAR NULL;
+
+-- Existing offers: initial round from the offer itself; existing trips: price of the accepted offer.
+INSERT INTO offer_rounds (offer_id, round_no, author_type, author_id, price, currency, comment, created_at)
+SELECT o.id, 1, 'DRIVER', o.carrier_id, o.price, o.currency, o.comment, o.created_at
+FROM offers o
+ON CONFLICT (offer_id, round_no) DO NOTHING;
+
+UPDATE trips t SET agreed_price = o.price, agreed_currency = o.currency
+FROM offers o
+WHERE o.id = t.offer_id AND t.agreed_price IS NULL;
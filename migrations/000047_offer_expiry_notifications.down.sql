@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS notifications;
+DROP INDEX IF EXISTS idx_offers_carrier;
+DROP INDEX IF EXISTS idx_offers_expires_at;
+UPDATE offers SET status = 'REJECTED' WHERE status IN ('EXPIRED', 'WITHDRAWN');
+ALTER TABLE offers DROP CONSTRAINT IF EXISTS offers_status_check;
+ALTER TABLE offers ADD CONSTRAINT offers_status_check CHECK (status IN ('PENDING', 'ACCEPTED', 'REJECTED'));
+ALTER TABLE offers DROP COLUMN IF EXISTS expires_at;
@@ -0,0 +1,24 @@
+-- Offer validity and withdrawal: offers.expires_at (NULL = no expiry); a background job moves overdue
+-- PENDING offers to EXPIRED; a driver can withdraw own PENDING offer (WITHDRAWN).
+-- notifications: in-app notifications for drivers, dispatchers and companies (also pushed over the chat websocket).
+
+ALTER TABLE offers ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP NULL;
+ALTER TABLE offers DROP CONSTRAINT IF EXISTS offers_status_check;
+ALTER TABLE offers ADD CONSTRAINT offers_status_check CHECK (status IN ('PENDING', 'ACCEPTED', 'REJECTED', 'EXPIRED', 'WITHDRAWN'));
+
+CREATE INDEX IF NOT EXISTS idx_offers_expires_at ON offers (expires_at) WHERE status = 'PENDING' AND expires_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_offers_carrier ON offers (carrier_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS notifications (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  recipient_type VARCHAR(20) NOT NULL,
+  recipient_id UUID NOT NULL,
+  kind VARCHAR(50) NOT NULL,
+  payload JSONB NOT NULL DEFAULT '{}'::jsonb,
+  read_at TIMESTAMP NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  CONSTRAINT notifications_recipient_type_check CHECK (recipient_type IN ('DRIVER', 'DISPATCHER', 'COMPANY'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_recipient ON notifications (recipient_type, recipient_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications (recipient_type, recipient_id) WHERE read_at IS NULL;
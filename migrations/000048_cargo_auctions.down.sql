@@ -0,0 +1 @@
+DROP TABLE IF EXISTS cargo_auctions;
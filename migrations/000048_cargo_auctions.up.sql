@@ -0,0 +1,29 @@
+-- Timed reverse auction for cargo: drivers bid (bids are offers), the lowest bid wins at ends_at.
+-- reserve_price — max acceptable price (bids above it cannot win); sealed — bids hidden until close;
+-- anti_snipe_minutes — a bid placed in the last N minutes extends ends_at to now + N minutes.
+-- winner_selection AUTO — the best bid is accepted at close (offer → ACCEPTED, trip created); MANUAL — owner picks.
+
+CREATE TABLE IF NOT EXISTS cargo_auctions (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  cargo_id UUID NOT NULL UNIQUE REFERENCES cargo(id) ON DELETE CASCADE,
+  status VARCHAR(20) NOT NULL DEFAULT 'OPEN',
+  currency VARCHAR NOT NULL,
+  reserve_price DOUBLE PRECISION NULL,
+  sealed BOOLEAN NOT NULL DEFAULT false,
+  anti_snipe_minutes INT NOT NULL DEFAULT 0,
+  winner_selection VARCHAR(20) NOT NULL DEFAULT 'AUTO',
+  starts_at TIMESTAMP NOT NULL DEFAULT now(),
+  ends_at TIMESTAMP NOT NULL,
+  original_ends_at TIMESTAMP NOT NULL,
+  extensions_count INT NOT NULL DEFAULT 0,
+  winner_offer_id UUID NULL REFERENCES offers(id) ON DELETE SET NULL,
+  closed_at TIMESTAMP NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP NOT NULL DEFAULT now(),
+  CONSTRAINT cargo_auctions_status_check CHECK (status IN ('OPEN', 'CLOSED', 'AWARDED', 'NO_WINNER', 'CANCELLED')),
+  CONSTRAINT cargo_auctions_winner_selection_check CHECK (winner_selection IN ('AUTO', 'MANUAL')),
+  CONSTRAINT cargo_auctions_reserve_check CHECK (reserve_price IS NULL OR reserve_price > 0),
+  CONSTRAINT cargo_auctions_anti_snipe_check CHECK (anti_snipe_minutes >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_cargo_auctions_open_ends ON cargo_auctions (ends_at) WHERE status = 'OPEN';
@@ -0,0 +1,4 @@
+ALTER TABLE cargo DROP COLUMN IF EXISTS schedule_id;
+ALTER TABLE cargo DROP COLUMN IF EXISTS template_id;
+DROP TABLE IF EXISTS cargo_schedules;
+DROP TABLE IF EXISTS cargo_templates;
@@ -0,0 +1,45 @@
+-- Cargo templates (saved POST /api/cargo body) per company or freelance dispatcher, and recurring schedules
+-- that generate cargo from a template (each generated cargo goes through moderation as usual).
+-- Schedule time is Asia/Tashkent: weekdays 1=Mon..7=Sun, run_time HH:MM; next_run_at/last_run_at are stored in UTC.
+-- ready_offset_days — generated cargo gets ready_at = run + N days.
+
+CREATE TABLE IF NOT EXISTS cargo_templates (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  owner_type VARCHAR(20) NOT NULL,
+  owner_id UUID NOT NULL,
+  name VARCHAR(120) NOT NULL,
+  payload JSONB NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP NOT NULL DEFAULT now(),
+  deleted_at TIMESTAMP NULL,
+  CONSTRAINT cargo_templates_owner_type_check CHECK (owner_type IN ('COMPANY', 'DISPATCHER'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_cargo_templates_owner_name
+  ON cargo_templates (owner_type, owner_id, lower(name)) WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS cargo_schedules (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  template_id UUID NOT NULL REFERENCES cargo_templates(id) ON DELETE CASCADE,
+  weekdays INT[] NOT NULL,
+  run_time VARCHAR(5) NOT NULL,
+  ready_offset_days INT NULL,
+  active BOOLEAN NOT NULL DEFAULT true,
+  next_run_at TIMESTAMP NULL,
+  last_run_at TIMESTAMP NULL,
+  last_cargo_id UUID NULL REFERENCES cargo(id) ON DELETE SET NULL,
+  last_error TEXT NULL,
+  runs_count INT NOT NULL DEFAULT 0,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP NOT NULL DEFAULT now(),
+  CONSTRAINT cargo_schedules_weekdays_check CHECK (cardinality(weekdays) > 0 AND weekdays <@ ARRAY[1,2,3,4,5,6,7]),
+  CONSTRAINT cargo_schedules_run_time_check CHECK (run_time ~ '^([01][0-9]|2[0-3]):[0-5][0-9]$'),
+  CONSTRAINT cargo_schedules_ready_offset_check CHECK (ready_offset_days IS NULL OR ready_offset_days BETWEEN 0 AND 60)
+);
+
+CREATE INDEX IF NOT EXISTS idx_cargo_schedules_template ON cargo_schedules (template_id);
+CREATE INDEX IF NOT EXISTS idx_cargo_schedules_due ON cargo_schedules (next_run_at) WHERE active;
+
+-- Груз, созданный из шаблона (вручную или по расписанию).
+ALTER TABLE cargo ADD COLUMN IF NOT EXISTS template_id UUID NULL REFERENCES cargo_templates(id) ON DELETE SET NULL;
+ALTER TABLE cargo ADD COLUMN IF NOT EXISTS schedule_id UUID NULL REFERENCES cargo_schedules(id) ON DELETE SET NULL;
@@ -0,0 +1,40 @@
+-- Trip status history (for status durations in reports) and asynchronous report exports (admin).
+-- trip_status_history: one row per status change; existing trips get their current status as of updated_at.
+-- report_exports: big exports are generated in the background; the file is kept in data until expires_at.
+
+CREATE TABLE IF NOT EXISTS trip_status_history (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  trip_id UUID NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+  from_status VARCHAR(30) NULL,
+  to_status VARCHAR(30) NOT NULL,
+  changed_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_trip_status_history_trip ON trip_status_history (trip_id, changed_at);
+
+INSERT INTO trip_status_history (trip_id, from_status, to_status, changed_at)
+SELECT t.id, NULL, t.status, t.updated_at FROM trips t
+WHERE NOT EXISTS (SELECT 1 FROM trip_status_history h WHERE h.trip_id = t.id);
+
+CREATE TABLE IF NOT EXISTS report_exports (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  admin_id UUID NOT NULL,
+  kind VARCHAR(20) NOT NULL,
+  format VARCHAR(10) NOT NULL,
+  params JSONB NOT NULL,
+  status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+  rows_count INT NULL,
+  file_name VARCHAR(200) NULL,
+  data BYTEA NULL,
+  error TEXT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  started_at TIMESTAMP NULL,
+  finished_at TIMESTAMP NULL,
+  expires_at TIMESTAMP NULL,
+  CONSTRAINT report_exports_kind_check CHECK (kind IN ('CARGO', 'TRIPS', 'OFFERS')),
+  CONSTRAINT report_exports_format_check CHECK (format IN ('csv', 'xlsx')),
+  CONSTRAINT report_exports_status_check CHECK (status IN ('PENDING', 'PROCESSING', 'READY', 'FAILED'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_exports_admin ON report_exports (admin_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_report_exports_pending ON report_exports (created_at) WHERE status = 'PENDING';
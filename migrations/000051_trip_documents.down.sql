@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS trip_documents;
+DROP SEQUENCE IF EXISTS trip_document_number_seq;
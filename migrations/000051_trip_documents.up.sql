@@ -0,0 +1,18 @@
+-- Trip documents (PDF): CMR consignment note and trip sheet generated from cargo, route, payment, driver and vehicle data.
+-- One document of each type per trip; the number is assigned once and kept when the document is regenerated.
+
+CREATE SEQUENCE IF NOT EXISTS trip_document_number_seq;
+
+CREATE TABLE IF NOT EXISTS trip_documents (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  trip_id UUID NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+  doc_type VARCHAR(20) NOT NULL,
+  number VARCHAR(30) NOT NULL,
+  lang VARCHAR(5) NULL,
+  data BYTEA NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  generated_at TIMESTAMP NULL,
+  CONSTRAINT trip_documents_doc_type_check CHECK (doc_type IN ('CMR', 'TRIP_SHEET')),
+  CONSTRAINT trip_documents_number_key UNIQUE (number),
+  CONSTRAINT trip_documents_trip_type_key UNIQUE (trip_id, doc_type)
+);
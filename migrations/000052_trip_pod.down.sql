@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS trip_pods;
+DROP TABLE IF EXISTS trip_pod_photos;
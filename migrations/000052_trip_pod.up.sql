@@ -0,0 +1,34 @@
+-- Electronic proof of delivery (ePOD): between UNLOADING and COMPLETED the driver uploads photos of the delivered
+-- cargo and submits the consignee's name and signature (with geo and time); submitting completes the trip.
+-- The shipper can dispute the delivery until dispute_until (POD_DISPUTE_WINDOW_HOURS after submission).
+
+CREATE TABLE IF NOT EXISTS trip_pod_photos (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  trip_id UUID NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+  data BYTEA NOT NULL,
+  content_type VARCHAR(50) NOT NULL,
+  lat DOUBLE PRECISION NULL,
+  lng DOUBLE PRECISION NULL,
+  taken_at TIMESTAMP NOT NULL DEFAULT now(),
+  created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_trip_pod_photos_trip ON trip_pod_photos (trip_id, created_at);
+
+CREATE TABLE IF NOT EXISTS trip_pods (
+  trip_id UUID PRIMARY KEY REFERENCES trips(id) ON DELETE CASCADE,
+  driver_id UUID NOT NULL,
+  consignee_name VARCHAR(255) NOT NULL,
+  signature_data BYTEA NOT NULL,
+  signature_content_type VARCHAR(50) NOT NULL,
+  lat DOUBLE PRECISION NOT NULL,
+  lng DOUBLE PRECISION NOT NULL,
+  delivered_at TIMESTAMP NOT NULL DEFAULT now(),
+  dispute_until TIMESTAMP NOT NULL,
+  status VARCHAR(20) NOT NULL DEFAULT 'SUBMITTED',
+  dispute_reason TEXT NULL,
+  disputed_by_type VARCHAR(20) NULL,
+  disputed_by_id UUID NULL,
+  disputed_at TIMESTAMP NULL,
+  CONSTRAINT trip_pods_status_check CHECK (status IN ('SUBMITTED', 'DISPUTED'))
+);
@@ -0,0 +1,16 @@
+-- Delivery PIN: when a trip goes EN_ROUTE a numeric PIN is issued; the cargo creator shares it with the consignee
+-- (or sends it to the consignee's phone via Telegram Gateway) and the driver completes the trip only by entering it.
+-- The PIN is kept in plain text because the cargo creator must be able to look it up; wrong entries are counted
+-- and after DELIVERY_PIN_MAX_ATTEMPTS the PIN is locked until the creator regenerates it.
+
+CREATE TABLE IF NOT EXISTS trip_delivery_pins (
+  trip_id UUID PRIMARY KEY REFERENCES trips(id) ON DELETE CASCADE,
+  pin VARCHAR(8) NOT NULL,
+  attempts INT NOT NULL DEFAULT 0,
+  sent_to_phone VARCHAR(32) NULL,
+  sent_at TIMESTAMP NULL,
+  verified_at TIMESTAMP NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP NOT NULL DEFAULT now(),
+  CONSTRAINT trip_delivery_pins_attempts_check CHECK (attempts >= 0)
+);
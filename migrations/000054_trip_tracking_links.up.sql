@@ -0,0 +1,19 @@
+-- Public tracking links: the cargo owner shares a tokenized, expiring link to a trip with the consignee / end customer.
+-- The link opens a read-only view (status, last known position, ETA, route points) without driver PII or phones;
+-- it can be revoked at any time.
+
+CREATE TABLE IF NOT EXISTS trip_tracking_links (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  trip_id UUID NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+  token VARCHAR(64) NOT NULL UNIQUE,
+  created_by_type VARCHAR(20) NOT NULL,
+  created_by_id UUID NOT NULL,
+  expires_at TIMESTAMP NOT NULL,
+  revoked_at TIMESTAMP NULL,
+  views INT NOT NULL DEFAULT 0,
+  last_viewed_at TIMESTAMP NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  CONSTRAINT trip_tracking_links_created_by_type_check CHECK (created_by_type IN ('DISPATCHER', 'COMPANY'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_trip_tracking_links_trip ON trip_tracking_links (trip_id, created_at DESC);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS invoice_payments;
+DROP TABLE IF EXISTS invoices;
+DROP SEQUENCE IF EXISTS invoice_number_seq;
@@ -0,0 +1,56 @@
+-- Freight invoicing and settlement: one invoice per trip from the agreed offer price, split into prepayment and
+-- remaining parts by the cargo payment terms. The prepayment is due INVOICE_PREPAYMENT_DAYS after issue; the remaining
+-- part is due remaining_term_days after delivery (trip_pods.delivered_at), derived from payments.remaining_type.
+-- Payments (prepayment, remaining, partial) are recorded by the payer or, for cash, by the driver; overdue_since is set
+-- by the background job when a due part is not covered by paid_amount.
+
+CREATE SEQUENCE IF NOT EXISTS invoice_number_seq;
+
+CREATE TABLE IF NOT EXISTS invoices (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  trip_id UUID NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+  number VARCHAR(30) NOT NULL,
+  payer_type VARCHAR(20) NOT NULL,
+  payer_id UUID NOT NULL,
+  driver_id UUID NOT NULL,
+  carrier_company_id UUID NULL,
+  currency VARCHAR(10) NOT NULL,
+  amount DOUBLE PRECISION NOT NULL,
+  prepayment_amount DOUBLE PRECISION NOT NULL DEFAULT 0,
+  prepayment_due_at TIMESTAMP NULL,
+  remaining_type VARCHAR(30) NULL,
+  remaining_term_days INT NOT NULL DEFAULT 0,
+  paid_amount DOUBLE PRECISION NOT NULL DEFAULT 0,
+  status VARCHAR(20) NOT NULL DEFAULT 'ISSUED',
+  overdue_since TIMESTAMP NULL,
+  issued_at TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP NOT NULL DEFAULT now(),
+  CONSTRAINT invoices_trip_key UNIQUE (trip_id),
+  CONSTRAINT invoices_number_key UNIQUE (number),
+  CONSTRAINT invoices_payer_type_check CHECK (payer_type IN ('DISPATCHER', 'COMPANY')),
+  CONSTRAINT invoices_status_check CHECK (status IN ('ISSUED', 'PARTIALLY_PAID', 'PAID', 'CANCELLED')),
+  CONSTRAINT invoices_amounts_check CHECK (amount >= 0 AND prepayment_amount >= 0 AND prepayment_amount <= amount AND paid_amount >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_invoices_payer ON invoices (payer_type, payer_id, issued_at DESC);
+CREATE INDEX IF NOT EXISTS idx_invoices_driver ON invoices (driver_id, issued_at DESC);
+CREATE INDEX IF NOT EXISTS idx_invoices_carrier_company ON invoices (carrier_company_id, issued_at DESC) WHERE carrier_company_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_invoices_open ON invoices (status) WHERE status IN ('ISSUED', 'PARTIALLY_PAID');
+
+CREATE TABLE IF NOT EXISTS invoice_payments (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  invoice_id UUID NOT NULL REFERENCES invoices(id) ON DELETE CASCADE,
+  kind VARCHAR(20) NOT NULL,
+  amount DOUBLE PRECISION NOT NULL,
+  method VARCHAR(30) NULL,
+  reference VARCHAR(255) NULL,
+  paid_at TIMESTAMP NOT NULL DEFAULT now(),
+  recorded_by_type VARCHAR(20) NOT NULL,
+  recorded_by_id UUID NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  CONSTRAINT invoice_payments_kind_check CHECK (kind IN ('PREPAYMENT', 'REMAINING', 'PARTIAL')),
+  CONSTRAINT invoice_payments_amount_check CHECK (amount > 0),
+  CONSTRAINT invoice_payments_recorded_by_type_check CHECK (recorded_by_type IN ('DISPATCHER', 'COMPANY', 'DRIVER'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_invoice_payments_invoice ON invoice_payments (invoice_id, paid_at);
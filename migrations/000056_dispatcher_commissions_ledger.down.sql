@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS ledger_entries;
+DROP TABLE IF EXISTS ledger_transactions;
+DROP TABLE IF EXISTS dispatcher_commission_agreements;
@@ -0,0 +1,61 @@
+-- Driver earnings and freelance dispatcher commissions. A dispatcher sets a commission agreement with each linked
+-- driver (drivers.freelancer_id): a percent of the agreed trip price or a fixed amount per trip.
+-- When a trip is COMPLETED the background job posts one ledger transaction (double entry, debits = credits):
+--   FREIGHT_PAYABLE (payer: cargo creator)  DEBIT  price  -> DRIVER_EARNINGS (driver)          CREDIT price
+--   DRIVER_EARNINGS (driver)                DEBIT  commission -> DISPATCHER_COMMISSION (dispatcher) CREDIT commission
+-- Entries are never updated; statements are built from entries by period.
+
+CREATE TABLE IF NOT EXISTS dispatcher_commission_agreements (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  dispatcher_id UUID NOT NULL REFERENCES freelance_dispatchers(id) ON DELETE CASCADE,
+  driver_id UUID NOT NULL REFERENCES drivers(id) ON DELETE CASCADE,
+  type VARCHAR(20) NOT NULL,
+  percent DOUBLE PRECISION NULL,
+  fixed_amount DOUBLE PRECISION NULL,
+  fixed_currency VARCHAR(10) NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP NOT NULL DEFAULT now(),
+  CONSTRAINT dispatcher_commission_agreements_pair_key UNIQUE (dispatcher_id, driver_id),
+  CONSTRAINT dispatcher_commission_agreements_type_check CHECK (type IN ('PERCENT', 'FIXED')),
+  CONSTRAINT dispatcher_commission_agreements_value_check CHECK (
+    (type = 'PERCENT' AND percent > 0 AND percent <= 100)
+    OR (type = 'FIXED' AND fixed_amount > 0 AND fixed_currency IS NOT NULL)
+  )
+);
+
+CREATE INDEX IF NOT EXISTS idx_dispatcher_commission_agreements_driver ON dispatcher_commission_agreements (driver_id);
+
+CREATE TABLE IF NOT EXISTS ledger_transactions (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  trip_id UUID NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+  kind VARCHAR(30) NOT NULL,
+  currency VARCHAR(10) NOT NULL,
+  amount DOUBLE PRECISION NOT NULL,
+  commission DOUBLE PRECISION NOT NULL DEFAULT 0,
+  commission_type VARCHAR(20) NULL,
+  commission_percent DOUBLE PRECISION NULL,
+  dispatcher_id UUID NULL,
+  posted_at TIMESTAMP NOT NULL DEFAULT now(),
+  CONSTRAINT ledger_transactions_trip_kind_key UNIQUE (trip_id, kind),
+  CONSTRAINT ledger_transactions_kind_check CHECK (kind IN ('TRIP_COMPLETED')),
+  CONSTRAINT ledger_transactions_amounts_check CHECK (amount >= 0 AND commission >= 0 AND commission <= amount)
+);
+
+CREATE TABLE IF NOT EXISTS ledger_entries (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  transaction_id UUID NOT NULL REFERENCES ledger_transactions(id) ON DELETE CASCADE,
+  account VARCHAR(30) NOT NULL,
+  owner_type VARCHAR(20) NOT NULL,
+  owner_id UUID NOT NULL,
+  side VARCHAR(10) NOT NULL,
+  amount DOUBLE PRECISION NOT NULL,
+  currency VARCHAR(10) NOT NULL,
+  posted_at TIMESTAMP NOT NULL DEFAULT now(),
+  CONSTRAINT ledger_entries_account_check CHECK (account IN ('FREIGHT_PAYABLE', 'DRIVER_EARNINGS', 'DISPATCHER_COMMISSION')),
+  CONSTRAINT ledger_entries_owner_type_check CHECK (owner_type IN ('DRIVER', 'DISPATCHER', 'COMPANY')),
+  CONSTRAINT ledger_entries_side_check CHECK (side IN ('DEBIT', 'CREDIT')),
+  CONSTRAINT ledger_entries_amount_check CHECK (amount > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_owner ON ledger_entries (account, owner_type, owner_id, posted_at);
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_transaction ON ledger_entries (transaction_id);
@@ -0,0 +1,31 @@
+-- Trip expenses reported by the driver en route (fuel, tolls, parking, customs fees): amount, currency, receipt photo
+-- and location. The cargo creator (dispatcher or company) approves or rejects each expense; approved expenses are
+-- included in trip profitability (trip report export and GET .../trips/:id/expenses).
+
+CREATE TABLE IF NOT EXISTS trip_expenses (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  trip_id UUID NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+  driver_id UUID NOT NULL,
+  category VARCHAR(20) NOT NULL,
+  amount DOUBLE PRECISION NOT NULL,
+  currency VARCHAR(10) NOT NULL,
+  comment TEXT NULL,
+  receipt_data BYTEA NULL,
+  receipt_content_type VARCHAR(50) NULL,
+  lat DOUBLE PRECISION NULL,
+  lng DOUBLE PRECISION NULL,
+  spent_at TIMESTAMP NOT NULL DEFAULT now(),
+  status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+  reviewed_by_type VARCHAR(20) NULL,
+  reviewed_by_id UUID NULL,
+  reviewed_at TIMESTAMP NULL,
+  reject_reason TEXT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP NOT NULL DEFAULT now(),
+  CONSTRAINT trip_expenses_category_check CHECK (category IN ('FUEL', 'TOLL', 'PARKING', 'CUSTOMS', 'OTHER')),
+  CONSTRAINT trip_expenses_amount_check CHECK (amount > 0),
+  CONSTRAINT trip_expenses_status_check CHECK (status IN ('PENDING', 'APPROVED', 'REJECTED')),
+  CONSTRAINT trip_expenses_reviewed_by_type_check CHECK (reviewed_by_type IS NULL OR reviewed_by_type IN ('DISPATCHER', 'COMPANY'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_trip_expenses_trip ON trip_expenses (trip_id, spent_at);
@@ -0,0 +1,19 @@
+-- Per-stop checkpoints of a trip: the driver marks arrival at and departure from each route point in point_order,
+-- so multi-drop routes show which stops are done and dwell time at every stop is measured. Trip status
+-- (LOADING → EN_ROUTE → UNLOADING) is derived from stop progress.
+
+CREATE TABLE IF NOT EXISTS trip_stops (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  trip_id UUID NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+  route_point_id UUID NOT NULL REFERENCES route_points(id) ON DELETE CASCADE,
+  arrived_at TIMESTAMP NOT NULL DEFAULT now(),
+  arrived_lat DOUBLE PRECISION NULL,
+  arrived_lng DOUBLE PRECISION NULL,
+  departed_at TIMESTAMP NULL,
+  departed_lat DOUBLE PRECISION NULL,
+  departed_lng DOUBLE PRECISION NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP NOT NULL DEFAULT now(),
+  CONSTRAINT trip_stops_trip_point_key UNIQUE (trip_id, route_point_id),
+  CONSTRAINT trip_stops_departed_at_check CHECK (departed_at IS NULL OR departed_at >= arrived_at)
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS trip_customs_documents;
+DROP TABLE IF EXISTS trip_customs_events;
+DROP TABLE IF EXISTS trip_customs;
@@ -0,0 +1,48 @@
+-- Customs clearance on CUSTOMS route points of a trip: sub-status (QUEUED → DECLARATION_SUBMITTED → INSPECTION →
+-- CLEARED, or HELD with a reason) updated by the driver or the cargo creator, status history for time-in-customs
+-- metrics, and declaration documents (TIR carnet, T1 transit declaration numbers) with optional scans.
+
+CREATE TABLE IF NOT EXISTS trip_customs (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  trip_id UUID NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+  route_point_id UUID NOT NULL REFERENCES route_points(id) ON DELETE CASCADE,
+  status VARCHAR(30) NOT NULL,
+  hold_reason TEXT NULL,
+  started_at TIMESTAMP NOT NULL DEFAULT now(),
+  cleared_at TIMESTAMP NULL,
+  updated_by_type VARCHAR(20) NOT NULL,
+  updated_by_id UUID NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP NOT NULL DEFAULT now(),
+  CONSTRAINT trip_customs_trip_point_key UNIQUE (trip_id, route_point_id),
+  CONSTRAINT trip_customs_status_check CHECK (status IN ('QUEUED', 'DECLARATION_SUBMITTED', 'INSPECTION', 'CLEARED', 'HELD')),
+  CONSTRAINT trip_customs_updated_by_type_check CHECK (updated_by_type IN ('DRIVER', 'DISPATCHER', 'COMPANY'))
+);
+
+CREATE TABLE IF NOT EXISTS trip_customs_events (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  customs_id UUID NOT NULL REFERENCES trip_customs(id) ON DELETE CASCADE,
+  from_status VARCHAR(30) NULL,
+  to_status VARCHAR(30) NOT NULL,
+  comment TEXT NULL,
+  by_type VARCHAR(20) NOT NULL,
+  by_id UUID NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_trip_customs_events_customs ON trip_customs_events (customs_id, created_at);
+
+CREATE TABLE IF NOT EXISTS trip_customs_documents (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  customs_id UUID NOT NULL REFERENCES trip_customs(id) ON DELETE CASCADE,
+  doc_type VARCHAR(20) NOT NULL,
+  number VARCHAR(100) NOT NULL,
+  file_data BYTEA NULL,
+  content_type VARCHAR(50) NULL,
+  uploaded_by_type VARCHAR(20) NOT NULL,
+  uploaded_by_id UUID NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  CONSTRAINT trip_customs_documents_doc_type_check CHECK (doc_type IN ('TIR', 'T1', 'CMR', 'PERMIT', 'DECLARATION', 'OTHER'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_trip_customs_documents_customs ON trip_customs_documents (customs_id);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS trip_route_sequence;
+DROP INDEX IF EXISTS idx_trips_parent_trip_id;
+ALTER TABLE trips DROP COLUMN IF EXISTS parent_trip_id;
+ALTER TABLE driver_trailers DROP COLUMN IF EXISTS capacity_volume;
+ALTER TABLE driver_trailers DROP COLUMN IF EXISTS capacity_weight;
@@ -0,0 +1,19 @@
+-- Consolidated trips: a driver carries several cargos (LTL partial loads) in one vehicle. Every cargo keeps its own
+-- trip (offer, agreed price, invoice, proof of delivery); additional trips are attached to a host trip via
+-- parent_trip_id and the host keeps the merged stop sequence over route points of all cargos. Stop checkpoints are
+-- recorded on the host trip and drive the status of every trip in the group. Trailer capacity (tons, m3) is used to
+-- check the peak load along the merged sequence.
+
+ALTER TABLE driver_trailers ADD COLUMN IF NOT EXISTS capacity_weight DOUBLE PRECISION NULL;
+ALTER TABLE driver_trailers ADD COLUMN IF NOT EXISTS capacity_volume DOUBLE PRECISION NULL;
+
+ALTER TABLE trips ADD COLUMN IF NOT EXISTS parent_trip_id UUID NULL REFERENCES trips(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_trips_parent_trip_id ON trips (parent_trip_id) WHERE parent_trip_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS trip_route_sequence (
+  trip_id UUID NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+  route_point_id UUID NOT NULL REFERENCES route_points(id) ON DELETE CASCADE,
+  seq INTEGER NOT NULL,
+  PRIMARY KEY (trip_id, route_point_id),
+  CONSTRAINT trip_route_sequence_seq_key UNIQUE (trip_id, seq)
+);
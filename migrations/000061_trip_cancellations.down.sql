@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS trip_handovers;
+DROP TABLE IF EXISTS trip_cancellations;
+DROP TABLE IF EXISTS trip_cancel_penalty_rules;
+ALTER TABLE cargo DROP COLUMN IF EXISTS last_search_status;
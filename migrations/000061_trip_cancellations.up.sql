@@ -0,0 +1,55 @@
+-- Trip cancellation: mandatory reason code (reference cargo.trip_cancel_reason), who cancelled, the penalty by stage and
+-- what happened to the cargo (back to search or reassigned to a chosen driver). Penalty rules: percent of the agreed
+-- price by the cancelling party (DRIVER / SHIPPER) and the trip status at cancellation. A driver swap on an active trip
+-- leaves a handover record. cargo.last_search_status remembers SEARCHING_ALL / SEARCHING_COMPANY before assignment.
+
+ALTER TABLE cargo ADD COLUMN IF NOT EXISTS last_search_status VARCHAR(50) NULL;
+
+CREATE TABLE IF NOT EXISTS trip_cancel_penalty_rules (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  party VARCHAR(20) NOT NULL,
+  trip_status VARCHAR(50) NOT NULL,
+  percent DOUBLE PRECISION NOT NULL,
+  updated_by UUID NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP NOT NULL DEFAULT now(),
+  CONSTRAINT trip_cancel_penalty_rules_party_check CHECK (party IN ('DRIVER', 'SHIPPER')),
+  CONSTRAINT trip_cancel_penalty_rules_percent_check CHECK (percent >= 0 AND percent <= 100),
+  CONSTRAINT trip_cancel_penalty_rules_party_trip_status_key UNIQUE (party, trip_status)
+);
+
+CREATE TABLE IF NOT EXISTS trip_cancellations (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  trip_id UUID NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+  reason_code VARCHAR(50) NOT NULL,
+  comment TEXT NULL,
+  party VARCHAR(20) NOT NULL,
+  cancelled_by_type VARCHAR(20) NOT NULL,
+  cancelled_by_id UUID NOT NULL,
+  from_status VARCHAR(50) NOT NULL,
+  penalty_percent DOUBLE PRECISION NULL,
+  penalty_amount DOUBLE PRECISION NULL,
+  penalty_currency VARCHAR(3) NULL,
+  cargo_outcome VARCHAR(20) NOT NULL,
+  new_trip_id UUID NULL REFERENCES trips(id) ON DELETE SET NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  CONSTRAINT trip_cancellations_trip_id_key UNIQUE (trip_id),
+  CONSTRAINT trip_cancellations_party_check CHECK (party IN ('DRIVER', 'SHIPPER')),
+  CONSTRAINT trip_cancellations_cargo_outcome_check CHECK (cargo_outcome IN ('SEARCHING', 'REASSIGNED'))
+);
+
+CREATE TABLE IF NOT EXISTS trip_handovers (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  trip_id UUID NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+  from_driver_id UUID NOT NULL,
+  to_driver_id UUID NOT NULL,
+  trip_status VARCHAR(50) NOT NULL,
+  reason_code VARCHAR(50) NOT NULL,
+  comment TEXT NULL,
+  lat DOUBLE PRECISION NULL,
+  lng DOUBLE PRECISION NULL,
+  initiated_by_type VARCHAR(20) NOT NULL,
+  initiated_by_id UUID NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_trip_handovers_trip_id ON trip_handovers (trip_id, created_at);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS trip_incident_photos;
+DROP TABLE IF EXISTS trip_incident_events;
+DROP TABLE IF EXISTS trip_incidents;
@@ -0,0 +1,55 @@
+-- Incidents raised by the driver on an active trip (breakdown, accident, police stop, ...): type, severity,
+-- description, location and photos. SOS incidents are CRITICAL and immediately alert the driver's dispatcher and the
+-- cargo owner (dispatcher or company users) over WebSocket. Status OPEN → ACKNOWLEDGED → RESOLVED with history.
+
+CREATE TABLE IF NOT EXISTS trip_incidents (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  trip_id UUID NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+  driver_id UUID NOT NULL,
+  type VARCHAR(30) NOT NULL,
+  severity VARCHAR(20) NOT NULL,
+  is_sos BOOLEAN NOT NULL DEFAULT false,
+  description TEXT NULL,
+  lat DOUBLE PRECISION NULL,
+  lng DOUBLE PRECISION NULL,
+  status VARCHAR(20) NOT NULL DEFAULT 'OPEN',
+  acknowledged_by_type VARCHAR(20) NULL,
+  acknowledged_by_id UUID NULL,
+  acknowledged_at TIMESTAMP NULL,
+  resolved_by_type VARCHAR(20) NULL,
+  resolved_by_id UUID NULL,
+  resolved_at TIMESTAMP NULL,
+  resolution TEXT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP NOT NULL DEFAULT now(),
+  CONSTRAINT trip_incidents_type_check CHECK (type IN ('BREAKDOWN', 'ACCIDENT', 'POLICE_STOP', 'CARGO_DAMAGE', 'THEFT', 'MEDICAL', 'ROAD_BLOCKED', 'OTHER')),
+  CONSTRAINT trip_incidents_severity_check CHECK (severity IN ('LOW', 'MEDIUM', 'HIGH', 'CRITICAL')),
+  CONSTRAINT trip_incidents_status_check CHECK (status IN ('OPEN', 'ACKNOWLEDGED', 'RESOLVED'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_trip_incidents_trip ON trip_incidents (trip_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_trip_incidents_driver ON trip_incidents (driver_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS trip_incident_events (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  incident_id UUID NOT NULL REFERENCES trip_incidents(id) ON DELETE CASCADE,
+  from_status VARCHAR(20) NULL,
+  to_status VARCHAR(20) NOT NULL,
+  comment TEXT NULL,
+  by_type VARCHAR(20) NOT NULL,
+  by_id UUID NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  CONSTRAINT trip_incident_events_by_type_check CHECK (by_type IN ('DRIVER', 'DISPATCHER', 'COMPANY'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_trip_incident_events_incident ON trip_incident_events (incident_id, created_at);
+
+CREATE TABLE IF NOT EXISTS trip_incident_photos (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  incident_id UUID NOT NULL REFERENCES trip_incidents(id) ON DELETE CASCADE,
+  data BYTEA NOT NULL,
+  content_type VARCHAR(50) NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_trip_incident_photos_incident ON trip_incident_photos (incident_id);
@@ -0,0 +1,3 @@
+ALTER TABLE offers DROP COLUMN IF EXISTS listing_id;
+DROP TABLE IF EXISTS truck_listing_destinations;
+DROP TABLE IF EXISTS truck_listings;
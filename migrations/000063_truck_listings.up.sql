@@ -0,0 +1,42 @@
+-- Truck availability listings: a driver (or the driver's freelance dispatcher) advertises an empty truck — truck type,
+-- capacity, current or planned empty location, available-from/to dates and preferred destinations. Shippers search
+-- listings by route and radius and propose a cargo directly: the proposal is an offer from the shipper side
+-- (offers.listing_id), which the driver accepts or counters in the usual offer/trip flow; on acceptance the listing
+-- becomes BOOKED.
+
+CREATE TABLE IF NOT EXISTS truck_listings (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  driver_id UUID NOT NULL REFERENCES drivers(id) ON DELETE CASCADE,
+  posted_by_type VARCHAR(20) NOT NULL,
+  posted_by_id UUID NOT NULL,
+  truck_type VARCHAR(50) NOT NULL,
+  capacity_weight DOUBLE PRECISION NULL,
+  capacity_volume DOUBLE PRECISION NULL,
+  from_city_code VARCHAR(20) NULL,
+  from_lat DOUBLE PRECISION NOT NULL,
+  from_lng DOUBLE PRECISION NOT NULL,
+  available_from TIMESTAMP NOT NULL,
+  available_to TIMESTAMP NULL,
+  comment TEXT NULL,
+  status VARCHAR(20) NOT NULL DEFAULT 'ACTIVE',
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP NOT NULL DEFAULT now(),
+  CONSTRAINT truck_listings_posted_by_type_check CHECK (posted_by_type IN ('DRIVER', 'DISPATCHER')),
+  CONSTRAINT truck_listings_status_check CHECK (status IN ('ACTIVE', 'BOOKED', 'CLOSED')),
+  CONSTRAINT truck_listings_capacity_check CHECK ((capacity_weight IS NULL OR capacity_weight > 0) AND (capacity_volume IS NULL OR capacity_volume > 0)),
+  CONSTRAINT truck_listings_dates_check CHECK (available_to IS NULL OR available_to >= available_from)
+);
+
+CREATE INDEX IF NOT EXISTS idx_truck_listings_driver ON truck_listings (driver_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_truck_listings_search ON truck_listings (status, from_lat, from_lng);
+
+CREATE TABLE IF NOT EXISTS truck_listing_destinations (
+  listing_id UUID NOT NULL REFERENCES truck_listings(id) ON DELETE CASCADE,
+  position INT NOT NULL,
+  city_code VARCHAR(20) NOT NULL,
+  lat DOUBLE PRECISION NOT NULL,
+  lng DOUBLE PRECISION NOT NULL,
+  PRIMARY KEY (listing_id, position)
+);
+
+ALTER TABLE offers ADD COLUMN IF NOT EXISTS listing_id UUID NULL REFERENCES truck_listings(id) ON DELETE SET NULL;
@@ -0,0 +1,14 @@
+-- Failed ledger postings. When posting a completed trip fails (e.g. no currency rate for a fixed commission),
+-- the background job records the attempt and retries the trip only after next_attempt_at (exponential backoff),
+-- so trips that keep failing do not block posting of the others. The row is removed once the trip is posted.
+
+CREATE TABLE IF NOT EXISTS ledger_post_failures (
+  trip_id UUID PRIMARY KEY REFERENCES trips(id) ON DELETE CASCADE,
+  attempts INTEGER NOT NULL DEFAULT 1,
+  last_error TEXT NOT NULL,
+  next_attempt_at TIMESTAMP NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT now(),
+  updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_post_failures_next_attempt ON ledger_post_failures (next_attempt_at);
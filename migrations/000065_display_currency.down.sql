@@ -0,0 +1,5 @@
+ALTER TABLE companies DROP COLUMN IF EXISTS display_currency;
+ALTER TABLE deleted_freelance_dispatchers DROP COLUMN IF EXISTS display_currency;
+ALTER TABLE freelance_dispatchers DROP COLUMN IF EXISTS display_currency;
+ALTER TABLE deleted_drivers DROP COLUMN IF EXISTS display_currency;
+ALTER TABLE drivers DROP COLUMN IF EXISTS display_currency;
@@ -0,0 +1,10 @@
+-- Display currency preference: cargo and offer lists convert prices to it when the request has no
+-- display_currency query parameter (the parameter overrides it). Drivers and freelance dispatchers keep
+-- their own preference; company users get the preference of the company selected in their token.
+-- deleted_* mirror columns for SELECT * archive.
+
+ALTER TABLE drivers ADD COLUMN IF NOT EXISTS display_currency VARCHAR(10) NULL;
+ALTER TABLE deleted_drivers ADD COLUMN IF NOT EXISTS display_currency VARCHAR(10) NULL;
+ALTER TABLE freelance_dispatchers ADD COLUMN IF NOT EXISTS display_currency VARCHAR(10) NULL;
+ALTER TABLE deleted_freelance_dispatchers ADD COLUMN IF NOT EXISTS display_currency VARCHAR(10) NULL;
+ALTER TABLE companies ADD COLUMN IF NOT EXISTS display_currency VARCHAR(10) NULL;